@@ -28,7 +28,10 @@ func (add) Add(app *web.App, cfg mux.Config) {
 	// Construct the business domain packages we need here so we are using the
 	// sames instances for the different set of domain apis.
 	delegate := delegate.New(cfg.Log)
-	userBus := userbus.NewBusiness(cfg.Log, delegate, usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB), time.Hour))
+	userBus := userbus.NewBusiness(cfg.Log, delegate, usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB), time.Hour),
+		userbus.WithHasher(cfg.Hasher),
+		userbus.WithLockout(cfg.Lockout.MaxAttempts, cfg.Lockout.Duration),
+	)
 
 	checkapi.Routes(app, checkapi.Config{
 		Build: cfg.Build,
@@ -39,5 +42,9 @@ func (add) Add(app *web.App, cfg mux.Config) {
 	authapi.Routes(app, authapi.Config{
 		UserBus: userBus,
 		Auth:    cfg.Auth,
+		Throttle: authapi.Throttle{
+			MaxFailures: cfg.Throttle.MaxFailures,
+			Window:      cfg.Throttle.Window,
+		},
 	})
 }
@@ -17,8 +17,11 @@ import (
 	"github.com/ardanlabs/service/api/sdk/http/debug"
 	"github.com/ardanlabs/service/api/sdk/http/mux"
 	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/sdk/hasher"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/keystore"
+	"github.com/ardanlabs/service/foundation/lifecycle"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/tracer"
 	"github.com/ardanlabs/service/foundation/web"
@@ -68,9 +71,16 @@ func run(ctx context.Context, log *logger.Logger) error {
 			WriteTimeout       time.Duration `conf:"default:10s"`
 			IdleTimeout        time.Duration `conf:"default:120s"`
 			ShutdownTimeout    time.Duration `conf:"default:20s"`
+			HookTimeout        time.Duration `conf:"default:5s"`
 			APIHost            string        `conf:"default:0.0.0.0:6000"`
 			DebugHost          string        `conf:"default:0.0.0.0:6100"`
 			CORSAllowedOrigins []string      `conf:"default:*"`
+			TrustedProxies     []string
+			MaxHeaderBytes     int `conf:"default:1048576"`
+			MaxHeaderCount     int `conf:"default:100"`
+			TLSCertFile        string
+			TLSKeyFile         string
+			TLSClientCAFile    string
 		}
 		Auth struct {
 			KeysFolder string `conf:"default:zarf/keys/"`
@@ -85,6 +95,24 @@ func run(ctx context.Context, log *logger.Logger) error {
 			MaxIdleConns int    `conf:"default:0"`
 			MaxOpenConns int    `conf:"default:0"`
 			DisableTLS   bool   `conf:"default:true"`
+			// Statements running longer than this are canceled.
+			StatementTimeout time.Duration `conf:"default:30s"`
+			// Connections are recycled once idle or open for this long.
+			ConnMaxIdleTime time.Duration `conf:"default:5m"`
+			ConnMaxLifetime time.Duration `conf:"default:1h"`
+		}
+		Password struct {
+			Algorithm        string `conf:"default:bcrypt"`
+			BcryptCost       int    `conf:"default:10"`
+			PBKDF2Iterations int    `conf:"default:600000"`
+		}
+		Lockout struct {
+			MaxAttempts int           `conf:"default:5"`
+			Duration    time.Duration `conf:"default:15m"`
+		}
+		Throttle struct {
+			MaxFailures int           `conf:"default:10"`
+			Window      time.Duration `conf:"default:5m"`
 		}
 		Tempo struct {
 			Host        string  `conf:"default:tempo.sales-system.svc.cluster.local:4317"`
@@ -122,25 +150,56 @@ func run(ctx context.Context, log *logger.Logger) error {
 
 	expvar.NewString("build").Set(cfg.Build)
 
+	// -------------------------------------------------------------------------
+	// Shutdown Hooks
+
+	// Components register their cleanup as they are started. The hooks run in
+	// reverse order when the service stops.
+	hooks := lifecycle.New(cfg.Web.HookTimeout)
+
+	defer func() {
+		if err := hooks.Shutdown(context.Background()); err != nil {
+			log.Error(ctx, "shutdown", "status", "running shutdown hooks", "err", err)
+		}
+	}()
+
 	// -------------------------------------------------------------------------
 	// Database Support
 
 	log.Info(ctx, "startup", "status", "initializing database support", "hostport", cfg.DB.Host)
 
 	db, err := sqldb.Open(sqldb.Config{
-		User:         cfg.DB.User,
-		Password:     cfg.DB.Password,
-		Host:         cfg.DB.Host,
-		Name:         cfg.DB.Name,
-		MaxIdleConns: cfg.DB.MaxIdleConns,
-		MaxOpenConns: cfg.DB.MaxOpenConns,
-		DisableTLS:   cfg.DB.DisableTLS,
+		User:             cfg.DB.User,
+		Password:         cfg.DB.Password,
+		Host:             cfg.DB.Host,
+		Name:             cfg.DB.Name,
+		MaxIdleConns:     cfg.DB.MaxIdleConns,
+		MaxOpenConns:     cfg.DB.MaxOpenConns,
+		DisableTLS:       cfg.DB.DisableTLS,
+		StatementTimeout: cfg.DB.StatementTimeout,
+		ConnMaxIdleTime:  cfg.DB.ConnMaxIdleTime,
+		ConnMaxLifetime:  cfg.DB.ConnMaxLifetime,
+		Log:              log,
 	})
 	if err != nil {
 		return fmt.Errorf("connecting to db: %w", err)
 	}
 
-	defer db.Close()
+	hooks.Register("database", func(context.Context) error {
+		return db.Close()
+	})
+
+	// -------------------------------------------------------------------------
+	// Password Hashing Support
+
+	passwordHasher, err := hasher.New(hasher.Config{
+		Algorithm:        cfg.Password.Algorithm,
+		BcryptCost:       cfg.Password.BcryptCost,
+		PBKDF2Iterations: cfg.Password.PBKDF2Iterations,
+	})
+	if err != nil {
+		return fmt.Errorf("constructing password hasher: %w", err)
+	}
 
 	// -------------------------------------------------------------------------
 	// Initialize authentication support
@@ -185,7 +244,7 @@ func run(ctx context.Context, log *logger.Logger) error {
 		return fmt.Errorf("starting tracing: %w", err)
 	}
 
-	defer traceProvider.Shutdown(context.Background())
+	hooks.Register("tracer", traceProvider.Shutdown)
 
 	tracer := traceProvider.Tracer(cfg.Tempo.ServiceName)
 
@@ -214,15 +273,52 @@ func run(ctx context.Context, log *logger.Logger) error {
 		Auth:   ath,
 		DB:     db,
 		Tracer: tracer,
+		Hasher: passwordHasher,
+		Lockout: mux.Lockout{
+			MaxAttempts: cfg.Lockout.MaxAttempts,
+			Duration:    cfg.Lockout.Duration,
+		},
+		Throttle: mux.Throttle{
+			MaxFailures: cfg.Throttle.MaxFailures,
+			Window:      cfg.Throttle.Window,
+		},
+	}
+
+	proxies, err := mid.ParseTrustedProxies(cfg.Web.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("parsing trusted proxies: %w", err)
+	}
+
+	muxOptions := []func(opts *mux.Options){
+		mux.WithCORS(cfg.Web.CORSAllowedOrigins),
+		mux.WithTrustedProxies(proxies),
+		mux.WithMaxHeaderCount(cfg.Web.MaxHeaderCount),
 	}
 
 	api := http.Server{
-		Addr:         cfg.Web.APIHost,
-		Handler:      mux.WebAPI(cfgMux, all.Routes(), mux.WithCORS(cfg.Web.CORSAllowedOrigins)),
-		ReadTimeout:  cfg.Web.ReadTimeout,
-		WriteTimeout: cfg.Web.WriteTimeout,
-		IdleTimeout:  cfg.Web.IdleTimeout,
-		ErrorLog:     logger.NewStdLogger(log, logger.LevelError),
+		Addr:           cfg.Web.APIHost,
+		Handler:        mux.WebAPI(cfgMux, all.Routes(), muxOptions...),
+		ReadTimeout:    cfg.Web.ReadTimeout,
+		WriteTimeout:   cfg.Web.WriteTimeout,
+		IdleTimeout:    cfg.Web.IdleTimeout,
+		MaxHeaderBytes: cfg.Web.MaxHeaderBytes,
+		ErrorLog:       logger.NewStdLogger(log, logger.LevelError),
+	}
+
+	if cfg.Web.TLSClientCAFile != "" {
+		if cfg.Web.TLSCertFile == "" {
+			return errors.New("verifying client certificates requires a TLS certificate")
+		}
+
+		caPEM, err := os.ReadFile(cfg.Web.TLSClientCAFile)
+		if err != nil {
+			return fmt.Errorf("reading client CA: %w", err)
+		}
+
+		api.TLSConfig, err = web.MutualTLS(caPEM)
+		if err != nil {
+			return fmt.Errorf("configuring mutual tls: %w", err)
+		}
 	}
 
 	serverErrors := make(chan error, 1)
@@ -230,6 +326,11 @@ func run(ctx context.Context, log *logger.Logger) error {
 	go func() {
 		log.Info(ctx, "startup", "status", "api router started", "host", api.Addr)
 
+		if cfg.Web.TLSCertFile != "" {
+			serverErrors <- api.ListenAndServeTLS(cfg.Web.TLSCertFile, cfg.Web.TLSKeyFile)
+			return
+		}
+
 		serverErrors <- api.ListenAndServe()
 	}()
 
@@ -4,7 +4,9 @@ package all
 import (
 	"time"
 
+	"github.com/ardanlabs/service/api/domain/http/adminapi"
 	"github.com/ardanlabs/service/api/domain/http/checkapi"
+	"github.com/ardanlabs/service/api/domain/http/eventapi"
 	"github.com/ardanlabs/service/api/domain/http/homeapi"
 	"github.com/ardanlabs/service/api/domain/http/productapi"
 	"github.com/ardanlabs/service/api/domain/http/rawapi"
@@ -19,9 +21,10 @@ import (
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/usercache"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermetrics"
 	"github.com/ardanlabs/service/business/domain/vproductbus"
 	"github.com/ardanlabs/service/business/domain/vproductbus/stores/vproductdb"
-	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
@@ -38,23 +41,53 @@ func (add) Add(app *web.App, cfg mux.Config) {
 
 	// Construct the business domain packages we need here so we are using the
 	// sames instances for the different set of domain apis.
-	delegate := delegate.New(cfg.Log)
-	userBus := userbus.NewBusiness(cfg.Log, delegate, usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB), time.Hour))
+	delegate := cfg.Delegate
+
+	var userStorer userbus.Storer = usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB, userdb.WithReplica(cfg.Replica.DB, cfg.Replica.MaxLag)), time.Hour)
+	if cfg.OTelMetrics != nil {
+		userStorer = usermetrics.NewStore(usermetrics.NewMetrics(cfg.OTelMetrics), userStorer)
+	}
+
+	userBus := userbus.NewBusiness(cfg.Log, delegate, userStorer,
+		userbus.WithHasher(cfg.Hasher),
+		userbus.WithPasswordResetTTL(cfg.PasswordReset.TTL),
+	)
 	productBus := productbus.NewBusiness(cfg.Log, userBus, delegate, productdb.NewStore(cfg.Log, cfg.DB))
 	homeBus := homebus.NewBusiness(cfg.Log, userBus, delegate, homedb.NewStore(cfg.Log, cfg.DB))
 	vproductBus := vproductbus.NewBusiness(vproductdb.NewStore(cfg.Log, cfg.DB))
 
+	// Transactions that fail with a serialization failure or a deadlock are
+	// run again, so every domain api retries them the same way.
+	transactor := sqldb.NewRetryTransactor(cfg.Log, sqldb.NewTransactor(cfg.Log, sqldb.NewBeginner(cfg.DB)), cfg.TxRetry.MaxAttempts, cfg.TxRetry.Backoff, cfg.RetryBudget)
+
+	if cfg.RuntimeConfig != nil {
+		adminapi.Routes(app, adminapi.Config{
+			Log:           cfg.Log,
+			AuthClient:    cfg.AuthClient,
+			RuntimeConfig: cfg.RuntimeConfig,
+			Quotas:        cfg.Quotas,
+		})
+	}
+
 	checkapi.Routes(app, checkapi.Config{
 		Build: cfg.Build,
 		Log:   cfg.Log,
 		DB:    cfg.DB,
 	})
 
+	eventapi.Routes(app, eventapi.Config{
+		Log:        cfg.Log,
+		AuthClient: cfg.AuthClient,
+		Delegate:   delegate,
+		Quotas:     cfg.Quotas,
+	})
+
 	homeapi.Routes(app, homeapi.Config{
 		Log:        cfg.Log,
 		UserBus:    userBus,
 		HomeBus:    homeBus,
 		AuthClient: cfg.AuthClient,
+		Quotas:     cfg.Quotas,
 	})
 
 	productapi.Routes(app, productapi.Config{
@@ -62,22 +95,28 @@ func (add) Add(app *web.App, cfg mux.Config) {
 		UserBus:    userBus,
 		ProductBus: productBus,
 		AuthClient: cfg.AuthClient,
+		Quotas:     cfg.Quotas,
 	})
 
 	rawapi.Routes(app)
 
 	tranapi.Routes(app, tranapi.Config{
 		Log:        cfg.Log,
-		DB:         cfg.DB,
+		Transactor: transactor,
 		UserBus:    userBus,
 		ProductBus: productBus,
 		AuthClient: cfg.AuthClient,
+		Quotas:     cfg.Quotas,
 	})
 
 	userapi.Routes(app, userapi.Config{
 		Log:        cfg.Log,
+		Transactor: transactor,
 		UserBus:    userBus,
 		AuthClient: cfg.AuthClient,
+		Verifier:   cfg.EmailVerifier,
+		Notifier:   cfg.Notifier,
+		Quotas:     cfg.Quotas,
 	})
 
 	vproductapi.Routes(app, vproductapi.Config{
@@ -85,5 +124,6 @@ func (add) Add(app *web.App, cfg mux.Config) {
 		UserBus:     userBus,
 		VProductBus: vproductBus,
 		AuthClient:  cfg.AuthClient,
+		Quotas:      cfg.Quotas,
 	})
 }
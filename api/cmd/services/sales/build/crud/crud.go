@@ -17,7 +17,8 @@ import (
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/usercache"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
-	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermetrics"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
@@ -34,11 +35,24 @@ func (add) Add(app *web.App, cfg mux.Config) {
 
 	// Construct the business domain packages we need here so we are using the
 	// sames instances for the different set of domain apis.
-	delegate := delegate.New(cfg.Log)
-	userBus := userbus.NewBusiness(cfg.Log, delegate, usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB), time.Hour))
+	delegate := cfg.Delegate
+
+	var userStorer userbus.Storer = usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB, userdb.WithReplica(cfg.Replica.DB, cfg.Replica.MaxLag)), time.Hour)
+	if cfg.OTelMetrics != nil {
+		userStorer = usermetrics.NewStore(usermetrics.NewMetrics(cfg.OTelMetrics), userStorer)
+	}
+
+	userBus := userbus.NewBusiness(cfg.Log, delegate, userStorer,
+		userbus.WithHasher(cfg.Hasher),
+		userbus.WithPasswordResetTTL(cfg.PasswordReset.TTL),
+	)
 	productBus := productbus.NewBusiness(cfg.Log, userBus, delegate, productdb.NewStore(cfg.Log, cfg.DB))
 	homeBus := homebus.NewBusiness(cfg.Log, userBus, delegate, homedb.NewStore(cfg.Log, cfg.DB))
 
+	// Transactions that fail with a serialization failure or a deadlock are
+	// run again, so every domain api retries them the same way.
+	transactor := sqldb.NewRetryTransactor(cfg.Log, sqldb.NewTransactor(cfg.Log, sqldb.NewBeginner(cfg.DB)), cfg.TxRetry.MaxAttempts, cfg.TxRetry.Backoff, cfg.RetryBudget)
+
 	checkapi.Routes(app, checkapi.Config{
 		Build: cfg.Build,
 		Log:   cfg.Log,
@@ -49,12 +63,14 @@ func (add) Add(app *web.App, cfg mux.Config) {
 		UserBus:    userBus,
 		HomeBus:    homeBus,
 		AuthClient: cfg.AuthClient,
+		Quotas:     cfg.Quotas,
 	})
 
 	productapi.Routes(app, productapi.Config{
 		UserBus:    userBus,
 		ProductBus: productBus,
 		AuthClient: cfg.AuthClient,
+		Quotas:     cfg.Quotas,
 	})
 
 	tranapi.Routes(app, tranapi.Config{
@@ -62,11 +78,17 @@ func (add) Add(app *web.App, cfg mux.Config) {
 		ProductBus: productBus,
 		Log:        cfg.Log,
 		AuthClient: cfg.AuthClient,
-		DB:         cfg.DB,
+		Transactor: transactor,
+		Quotas:     cfg.Quotas,
 	})
 
 	userapi.Routes(app, userapi.Config{
+		Log:        cfg.Log,
+		Transactor: transactor,
 		UserBus:    userBus,
 		AuthClient: cfg.AuthClient,
+		Verifier:   cfg.EmailVerifier,
+		Notifier:   cfg.Notifier,
+		Quotas:     cfg.Quotas,
 	})
 }
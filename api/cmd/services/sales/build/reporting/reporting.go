@@ -10,9 +10,9 @@ import (
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/usercache"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermetrics"
 	"github.com/ardanlabs/service/business/domain/vproductbus"
 	"github.com/ardanlabs/service/business/domain/vproductbus/stores/vproductdb"
-	"github.com/ardanlabs/service/business/sdk/delegate"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
@@ -29,8 +29,14 @@ func (add) Add(app *web.App, cfg mux.Config) {
 
 	// Construct the business domain packages we need here so we are using the
 	// sames instances for the different set of domain apis.
-	delegate := delegate.New(cfg.Log)
-	userBus := userbus.NewBusiness(cfg.Log, delegate, usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB), time.Hour))
+	delegate := cfg.Delegate
+
+	var userStorer userbus.Storer = usercache.NewStore(cfg.Log, userdb.NewStore(cfg.Log, cfg.DB, userdb.WithReplica(cfg.Replica.DB, cfg.Replica.MaxLag)), time.Hour)
+	if cfg.OTelMetrics != nil {
+		userStorer = usermetrics.NewStore(usermetrics.NewMetrics(cfg.OTelMetrics), userStorer)
+	}
+
+	userBus := userbus.NewBusiness(cfg.Log, delegate, userStorer, userbus.WithHasher(cfg.Hasher))
 	vproductBus := vproductbus.NewBusiness(vproductdb.NewStore(cfg.Log, cfg.DB))
 
 	checkapi.Routes(app, checkapi.Config{
@@ -43,5 +49,6 @@ func (add) Add(app *web.App, cfg mux.Config) {
 		UserBus:     userBus,
 		VProductBus: vproductBus,
 		AuthClient:  cfg.AuthClient,
+		Quotas:      cfg.Quotas,
 	})
 }
@@ -19,10 +19,28 @@ import (
 	"github.com/ardanlabs/service/api/sdk/http/debug"
 	"github.com/ardanlabs/service/api/sdk/http/mux"
 	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/emailverify"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/notify"
+	"github.com/ardanlabs/service/app/sdk/quota"
+	"github.com/ardanlabs/service/app/sdk/runtimecfg"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/business/sdk/featureflag"
+	"github.com/ardanlabs/service/business/sdk/hasher"
+	"github.com/ardanlabs/service/business/sdk/money"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/purge"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/sdk/webhook"
+	"github.com/ardanlabs/service/foundation/lifecycle"
 	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/ardanlabs/service/foundation/retry"
+	"github.com/ardanlabs/service/foundation/scheduler"
 	"github.com/ardanlabs/service/foundation/tracer"
 	"github.com/ardanlabs/service/foundation/web"
+	"github.com/jmoiron/sqlx"
 )
 
 /*
@@ -74,9 +92,42 @@ func run(ctx context.Context, log *logger.Logger) error {
 			WriteTimeout       time.Duration `conf:"default:10s"`
 			IdleTimeout        time.Duration `conf:"default:120s"`
 			ShutdownTimeout    time.Duration `conf:"default:20s"`
+			HookTimeout        time.Duration `conf:"default:5s"`
 			APIHost            string        `conf:"default:0.0.0.0:3000"`
 			DebugHost          string        `conf:"default:0.0.0.0:3010"`
 			CORSAllowedOrigins []string      `conf:"default:*"`
+			DebugLog           bool          `conf:"default:false"`
+			DebugLogRedact     []string      `conf:"default:password;passwordConfirm;token"`
+			DebugLogMaxSize    int           `conf:"default:65536"`
+			MaxInFlight        int           `conf:"default:0"`
+			RetryAfter         time.Duration `conf:"default:1s"`
+			TrustedProxies     []string
+			MaxHeaderBytes     int `conf:"default:1048576"`
+			MaxHeaderCount     int `conf:"default:100"`
+			TLSCertFile        string
+			TLSKeyFile         string
+			TLSClientCAFile    string
+			SPADir             string
+			SPAMaxAge          time.Duration `conf:"default:8760h"`
+			DefaultVersion     string        `conf:"default:v1"`
+			CSP                string        `conf:"default:default-src 'self'; frame-ancestors 'none'"`
+			HSTSMaxAge         time.Duration `conf:"default:8760h"`
+		}
+		Log struct {
+			SampleFirst    int           `conf:"default:0"`
+			SampleInterval time.Duration `conf:"default:1s"`
+		}
+		Page struct {
+			DefaultRows int  `conf:"default:10"`
+			MaxRows     int  `conf:"default:100"`
+			Reject      bool `conf:"default:false"`
+		}
+		Money struct {
+			JSONAsString bool `conf:"default:false"`
+		}
+		Maintenance struct {
+			Enabled    bool `conf:"default:false"`
+			AllowReads bool `conf:"default:true"`
 		}
 		Auth struct {
 			Host string `conf:"default:http://auth-service.sales-system.svc.cluster.local:6000"`
@@ -89,6 +140,34 @@ func run(ctx context.Context, log *logger.Logger) error {
 			MaxIdleConns int    `conf:"default:0"`
 			MaxOpenConns int    `conf:"default:0"`
 			DisableTLS   bool   `conf:"default:true"`
+			// Statements running longer than this are canceled.
+			StatementTimeout time.Duration `conf:"default:30s"`
+			// Connections are recycled once idle or open for this long.
+			ConnMaxIdleTime time.Duration `conf:"default:5m"`
+			ConnMaxLifetime time.Duration `conf:"default:1h"`
+			// Reads are sent to the replica when a host is set.
+			ReplicaHost   string
+			ReplicaMaxLag time.Duration `conf:"default:5s"`
+			// Transactions failing with a serialization failure or a
+			// deadlock are retried up to the max attempts.
+			TxMaxAttempts int           `conf:"default:3"`
+			TxBackoff     time.Duration `conf:"default:10ms"`
+		}
+		Password struct {
+			Algorithm        string `conf:"default:bcrypt"`
+			BcryptCost       int    `conf:"default:10"`
+			PBKDF2Iterations int    `conf:"default:600000"`
+		}
+		EmailVerify struct {
+			// Email verification is only enabled when a secret is provided.
+			Secret string        `conf:"mask"`
+			TTL    time.Duration `conf:"default:24h"`
+		}
+		PasswordReset struct {
+			// There is no mail delivery yet, so password reset is only
+			// enabled when tokens are written to the log for development.
+			LogTokens bool          `conf:"default:false"`
+			TTL       time.Duration `conf:"default:1h"`
 		}
 		Tempo struct {
 			Host        string  `conf:"default:tempo.sales-system.svc.cluster.local:4317"`
@@ -97,6 +176,50 @@ func run(ctx context.Context, log *logger.Logger) error {
 			// Shouldn't use a high Probability value in non-developer systems.
 			// 0.05 should be enough for most systems. Some might want to have
 			// this even lower.
+
+			// Routes override the probability in the form route=probability,
+			// like /v1/orders=0.5. Requests that fail are always sampled.
+			Routes []string
+		}
+		Quota struct {
+			// Limits are in the form requests/window, like 1000/1h, and
+			// tenant limits in the form tenant=requests/window. Tenants
+			// aren't limited when they have no limit. Requests without a
+			// tenant are limited per client address by the anonymous
+			// limit, which falls back to the default limit.
+			Default   string
+			Anonymous string
+			Tenants   []string
+		}
+		FeatureFlags struct {
+			// A JSON file of flag definitions keyed by the name of the flag.
+			// Every flag is disabled when this isn't set.
+			File string
+		}
+		Purge struct {
+			Schedule               string        `conf:"default:@hourly"`
+			BatchSize              int           `conf:"default:1000"`
+			PasswordResetRetention time.Duration `conf:"default:24h"`
+		}
+		Webhook struct {
+			// The endpoints notified of events, which are signed with the
+			// secret. Nothing is delivered when no endpoint is set.
+			URLs        []string
+			Secret      string        `conf:"mask"`
+			MaxAttempts int           `conf:"default:5"`
+			Backoff     time.Duration `conf:"default:1s"`
+		}
+		Retry struct {
+			// Every component that retries shares this budget, so during an
+			// outage the service retries no faster than the rate.
+			Rate  float64 `conf:"default:10"`
+			Burst int     `conf:"default:20"`
+		}
+		Metrics struct {
+			// The OTLP HTTP endpoint of the collector, like http://collector:4318.
+			// Metrics are only exported when this is set.
+			OTLPHost string
+			Interval time.Duration `conf:"default:10s"`
 		}
 	}{
 		Version: conf.Version{
@@ -129,25 +252,116 @@ func run(ctx context.Context, log *logger.Logger) error {
 
 	expvar.NewString("build").Set(cfg.Build)
 
+	// -------------------------------------------------------------------------
+	// Shutdown Hooks
+
+	// Components register their cleanup as they are started. The hooks run in
+	// reverse order when the service stops.
+	hooks := lifecycle.New(cfg.Web.HookTimeout)
+
+	defer func() {
+		if err := hooks.Shutdown(context.Background()); err != nil {
+			log.Error(ctx, "shutdown", "status", "running shutdown hooks", "err", err)
+		}
+	}()
+
+	// -------------------------------------------------------------------------
+	// Log Sampling
+
+	if cfg.Log.SampleFirst > 0 {
+		log = log.Sampled(logger.SampleConfig{
+			First:    cfg.Log.SampleFirst,
+			Interval: cfg.Log.SampleInterval,
+		})
+
+		expvar.Publish("logs_dropped", expvar.Func(func() any { return log.Dropped() }))
+	}
+
+	// -------------------------------------------------------------------------
+	// Paging
+
+	err = page.SetLimits(page.Limits{
+		DefaultRows: cfg.Page.DefaultRows,
+		MaxRows:     cfg.Page.MaxRows,
+		Reject:      cfg.Page.Reject,
+	})
+	if err != nil {
+		return fmt.Errorf("setting page limits: %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+	// Money
+
+	if cfg.Money.JSONAsString {
+		if err := money.SetJSONFormat(money.FormatString); err != nil {
+			return fmt.Errorf("setting money format: %w", err)
+		}
+	}
+
 	// -------------------------------------------------------------------------
 	// Database Support
 
 	log.Info(ctx, "startup", "status", "initializing database support", "hostport", cfg.DB.Host)
 
 	db, err := sqldb.Open(sqldb.Config{
-		User:         cfg.DB.User,
-		Password:     cfg.DB.Password,
-		Host:         cfg.DB.Host,
-		Name:         cfg.DB.Name,
-		MaxIdleConns: cfg.DB.MaxIdleConns,
-		MaxOpenConns: cfg.DB.MaxOpenConns,
-		DisableTLS:   cfg.DB.DisableTLS,
+		User:             cfg.DB.User,
+		Password:         cfg.DB.Password,
+		Host:             cfg.DB.Host,
+		Name:             cfg.DB.Name,
+		MaxIdleConns:     cfg.DB.MaxIdleConns,
+		MaxOpenConns:     cfg.DB.MaxOpenConns,
+		DisableTLS:       cfg.DB.DisableTLS,
+		StatementTimeout: cfg.DB.StatementTimeout,
+		ConnMaxIdleTime:  cfg.DB.ConnMaxIdleTime,
+		ConnMaxLifetime:  cfg.DB.ConnMaxLifetime,
+		Log:              log,
 	})
 	if err != nil {
 		return fmt.Errorf("connecting to db: %w", err)
 	}
 
-	defer db.Close()
+	hooks.Register("database", func(context.Context) error {
+		return db.Close()
+	})
+
+	var replica *sqlx.DB
+
+	if cfg.DB.ReplicaHost != "" {
+		log.Info(ctx, "startup", "status", "initializing database replica support", "hostport", cfg.DB.ReplicaHost)
+
+		replica, err = sqldb.Open(sqldb.Config{
+			User:             cfg.DB.User,
+			Password:         cfg.DB.Password,
+			Host:             cfg.DB.ReplicaHost,
+			Name:             cfg.DB.Name,
+			MaxIdleConns:     cfg.DB.MaxIdleConns,
+			MaxOpenConns:     cfg.DB.MaxOpenConns,
+			DisableTLS:       cfg.DB.DisableTLS,
+			StatementTimeout: cfg.DB.StatementTimeout,
+			ConnMaxIdleTime:  cfg.DB.ConnMaxIdleTime,
+			ConnMaxLifetime:  cfg.DB.ConnMaxLifetime,
+			Log:              log,
+		})
+		if err != nil {
+			return fmt.Errorf("connecting to db replica: %w", err)
+		}
+
+		hooks.Register("database replica", func(context.Context) error {
+			return replica.Close()
+		})
+	}
+
+	// -------------------------------------------------------------------------
+	// Password Hashing Support
+
+	passwordHasher, err := hasher.New(hasher.Config{
+		Algorithm:        cfg.Password.Algorithm,
+		BcryptCost:       cfg.Password.BcryptCost,
+		PBKDF2Iterations: cfg.Password.PBKDF2Iterations,
+	})
+	if err != nil {
+		return fmt.Errorf("constructing password hasher: %w", err)
+	}
 
 	// -------------------------------------------------------------------------
 	// Initialize authentication support
@@ -156,11 +370,75 @@ func run(ctx context.Context, log *logger.Logger) error {
 
 	authClient := authclient.New(log, cfg.Auth.Host)
 
+	// -------------------------------------------------------------------------
+	// Email Verification Support
+
+	var emailVerifier *emailverify.Verifier
+
+	if cfg.EmailVerify.Secret != "" {
+		log.Info(ctx, "startup", "status", "initializing email verification support")
+
+		emailVerifier, err = emailverify.New(cfg.EmailVerify.Secret, cfg.EmailVerify.TTL)
+		if err != nil {
+			return fmt.Errorf("constructing email verifier: %w", err)
+		}
+	}
+
+	// -------------------------------------------------------------------------
+	// Feature Flag Support
+
+	flagProvider := featureflag.Static{}
+
+	if cfg.FeatureFlags.File != "" {
+		log.Info(ctx, "startup", "status", "initializing feature flag support", "file", cfg.FeatureFlags.File)
+
+		f, err := os.Open(cfg.FeatureFlags.File)
+		if err != nil {
+			return fmt.Errorf("opening feature flags: %w", err)
+		}
+
+		flagProvider, err = featureflag.ParseStatic(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("parsing feature flags: %w", err)
+		}
+	}
+
+	flags := featureflag.New(flagProvider, featureflag.WithUserID(mid.FlagUserID), featureflag.WithTenantID(mid.GetTenantID))
+
+	// -------------------------------------------------------------------------
+	// Password Reset Support
+
+	var notifier notify.Sender
+
+	if cfg.PasswordReset.LogTokens {
+		log.Warn(ctx, "startup", "status", "initializing password reset support, reset tokens are written to the log")
+
+		notifier = notify.NewLogSender(log)
+	}
+
+	// -------------------------------------------------------------------------
+	// Runtime Configuration Support
+
+	log.Info(ctx, "startup", "status", "initializing runtime configuration support")
+
+	maintenance := mid.NewMaintenanceMode(cfg.Maintenance.Enabled, cfg.Maintenance.AllowReads, "/v1/admin/")
+
+	rtCfg, err := runtimecfg.New(log, cfg.Tempo.Probability, maintenance)
+	if err != nil {
+		return fmt.Errorf("constructing runtime config: %w", err)
+	}
+
 	// -------------------------------------------------------------------------
 	// Start Tracing Support
 
 	log.Info(ctx, "startup", "status", "initializing tracing support")
 
+	routeProbability, err := tracer.ParseRouteProbability(cfg.Tempo.Routes)
+	if err != nil {
+		return fmt.Errorf("parsing route probability: %w", err)
+	}
+
 	traceProvider, err := tracer.InitTracing(tracer.Config{
 		Log:         log,
 		ServiceName: cfg.Tempo.ServiceName,
@@ -169,16 +447,100 @@ func run(ctx context.Context, log *logger.Logger) error {
 			"/v1/liveness":  {},
 			"/v1/readiness": {},
 		},
-		Probability: cfg.Tempo.Probability,
+		Probability:      cfg.Tempo.Probability,
+		ProbabilityFn:    rtCfg.SampleRate,
+		RouteProbability: routeProbability,
 	})
 	if err != nil {
 		return fmt.Errorf("starting tracing: %w", err)
 	}
 
-	defer traceProvider.Shutdown(context.Background())
+	hooks.Register("tracer", traceProvider.Shutdown)
 
 	tracer := traceProvider.Tracer(cfg.Tempo.ServiceName)
 
+	// -------------------------------------------------------------------------
+	// Start Metrics Support
+
+	var metricsExp *otel.Exporter
+
+	if cfg.Metrics.OTLPHost != "" {
+		log.Info(ctx, "startup", "status", "initializing otel metrics support", "host", cfg.Metrics.OTLPHost)
+
+		metricsExp, err = otel.NewExporter(otel.Config{
+			Log:         log,
+			Endpoint:    cfg.Metrics.OTLPHost,
+			ServiceName: cfg.Tempo.ServiceName,
+			Interval:    cfg.Metrics.Interval,
+		})
+		if err != nil {
+			return fmt.Errorf("starting metrics: %w", err)
+		}
+
+		hooks.Register("metrics", metricsExp.Shutdown)
+	}
+
+	// -------------------------------------------------------------------------
+	// Start Webhook Support
+
+	retryBudget := retry.NewBudget(cfg.Retry.Rate, cfg.Retry.Burst)
+
+	// The delegate is shared with the routes, so the webhook is notified of
+	// the events the business layer raises.
+	dlg := delegate.New(log)
+
+	if len(cfg.Webhook.URLs) > 0 {
+		log.Info(ctx, "startup", "status", "initializing webhook support", "urls", cfg.Webhook.URLs)
+
+		if cfg.Webhook.Secret == "" {
+			return errors.New("webhook secret is required to sign the deliveries")
+		}
+
+		wh := webhook.New(webhook.Config{
+			Log:         log,
+			MaxAttempts: cfg.Webhook.MaxAttempts,
+			Backoff:     cfg.Webhook.Backoff,
+			RetryBudget: retryBudget,
+		})
+
+		for _, url := range cfg.Webhook.URLs {
+			wh.Register(webhook.Endpoint{URL: url, Secret: cfg.Webhook.Secret})
+		}
+
+		wh.Subscribe(dlg, userbus.DomainName, userbus.ActionUpdated)
+
+		hooks.Register("webhook", wh.Shutdown)
+	}
+
+	// -------------------------------------------------------------------------
+	// Start Scheduled Jobs
+
+	log.Info(ctx, "startup", "status", "initializing scheduled jobs")
+
+	sched := scheduler.New(log, tracer)
+
+	passwordResets, err := purge.NewTable(log, db, "user_password_resets", "expires_at")
+	if err != nil {
+		return fmt.Errorf("constructing purge table: %w", err)
+	}
+
+	purgeJob, err := purge.NewJob(log, cfg.Purge.BatchSize,
+		purge.Target{Name: "password resets", Purger: passwordResets, Retention: cfg.Purge.PasswordResetRetention},
+	)
+	if err != nil {
+		return fmt.Errorf("constructing purge job: %w", err)
+	}
+
+	if err := sched.Register("purge", cfg.Purge.Schedule, purgeJob.Run); err != nil {
+		return fmt.Errorf("registering jobs: %w", err)
+	}
+
+	if err := sched.Start(); err != nil {
+		return fmt.Errorf("starting scheduler: %w", err)
+	}
+
+	hooks.Register("scheduler", sched.Shutdown)
+
 	// -------------------------------------------------------------------------
 	// Start Debug Service
 
@@ -198,21 +560,127 @@ func run(ctx context.Context, log *logger.Logger) error {
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
+	var quotas *quota.Quotas
+	if cfg.Quota.Default != "" || cfg.Quota.Anonymous != "" || len(cfg.Quota.Tenants) > 0 {
+		var def quota.Limit
+		if cfg.Quota.Default != "" {
+			def, err = quota.ParseLimit(cfg.Quota.Default)
+			if err != nil {
+				return fmt.Errorf("parsing default quota: %w", err)
+			}
+		}
+
+		tenants, err := quota.ParseTenantLimits(cfg.Quota.Tenants)
+		if err != nil {
+			return fmt.Errorf("parsing tenant quotas: %w", err)
+		}
+
+		var options []func(q *quota.Quotas)
+		if cfg.Quota.Anonymous != "" {
+			anonymous, err := quota.ParseLimit(cfg.Quota.Anonymous)
+			if err != nil {
+				return fmt.Errorf("parsing anonymous quota: %w", err)
+			}
+
+			options = append(options, quota.WithAnonymous(anonymous))
+		}
+
+		quotas = quota.New(quota.NewMemory(), def, tenants, options...)
+	}
+
 	cfgMux := mux.Config{
 		Build:      build,
 		Log:        log,
 		AuthClient: authClient,
 		DB:         db,
-		Tracer:     tracer,
+		Replica: mux.Replica{
+			DB:     replica,
+			MaxLag: cfg.DB.ReplicaMaxLag,
+		},
+		Tracer:        tracer,
+		RuntimeConfig: rtCfg,
+		Hasher:        passwordHasher,
+		EmailVerifier: emailVerifier,
+		Notifier:      notifier,
+		PasswordReset: mux.PasswordReset{
+			TTL: cfg.PasswordReset.TTL,
+		},
+		Flags:       flags,
+		OTelMetrics: metricsExp,
+		Quotas:      quotas,
+		TxRetry: mux.TxRetry{
+			MaxAttempts: cfg.DB.TxMaxAttempts,
+			Backoff:     cfg.DB.TxBackoff,
+		},
+		RetryBudget: retryBudget,
+		Delegate:    dlg,
+	}
+
+	proxies, err := mid.ParseTrustedProxies(cfg.Web.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("parsing trusted proxies: %w", err)
+	}
+
+	muxOptions := []func(opts *mux.Options){
+		mux.WithCORS(cfg.Web.CORSAllowedOrigins),
+		mux.WithTrustedProxies(proxies),
+		mux.WithMaxHeaderCount(cfg.Web.MaxHeaderCount),
+		mux.WithSecurityHeaders(mid.SecurityHeadersConfig{
+			ContentSecurityPolicy: cfg.Web.CSP,
+			HSTSMaxAge:            cfg.Web.HSTSMaxAge,
+		}),
+	}
+
+	if metricsExp != nil {
+		muxOptions = append(muxOptions, mux.WithOTelMetrics(metricsExp))
+	}
+
+	if cfg.Web.DefaultVersion != "" {
+		muxOptions = append(muxOptions, mux.WithVersionNegotiation(cfg.Web.DefaultVersion))
+	}
+
+	if cfg.Web.MaxInFlight > 0 {
+		muxOptions = append(muxOptions, mux.WithConcurrencyLimit(cfg.Web.MaxInFlight, cfg.Web.RetryAfter))
+	}
+
+	if cfg.Web.SPADir != "" {
+		muxOptions = append(muxOptions, mux.WithSPA(web.Static{
+			FS:     os.DirFS(cfg.Web.SPADir),
+			MaxAge: cfg.Web.SPAMaxAge,
+		}, "/v1/"))
+	}
+
+	if cfg.Web.DebugLog {
+		muxOptions = append(muxOptions, mux.WithDebugLog(mid.DebugLogConfig{
+			Redact:  cfg.Web.DebugLogRedact,
+			MaxSize: cfg.Web.DebugLogMaxSize,
+		}))
 	}
 
 	api := http.Server{
-		Addr:         cfg.Web.APIHost,
-		Handler:      mux.WebAPI(cfgMux, buildRoutes(), mux.WithCORS(cfg.Web.CORSAllowedOrigins)),
-		ReadTimeout:  cfg.Web.ReadTimeout,
-		WriteTimeout: cfg.Web.WriteTimeout,
-		IdleTimeout:  cfg.Web.IdleTimeout,
-		ErrorLog:     logger.NewStdLogger(log, logger.LevelError),
+		Addr:           cfg.Web.APIHost,
+		Handler:        mux.WebAPI(cfgMux, buildRoutes(), muxOptions...),
+		ReadTimeout:    cfg.Web.ReadTimeout,
+		WriteTimeout:   cfg.Web.WriteTimeout,
+		IdleTimeout:    cfg.Web.IdleTimeout,
+		MaxHeaderBytes: cfg.Web.MaxHeaderBytes,
+		ErrorLog:       logger.NewStdLogger(log, logger.LevelError),
+	}
+
+	if cfg.Web.TLSClientCAFile != "" {
+		if cfg.Web.TLSCertFile == "" {
+			return errors.New("verifying client certificates requires a TLS certificate")
+		}
+
+		caPEM, err := os.ReadFile(cfg.Web.TLSClientCAFile)
+		if err != nil {
+			return fmt.Errorf("reading client CA: %w", err)
+		}
+
+		api.TLSConfig, err = web.MutualTLS(caPEM)
+		if err != nil {
+			return fmt.Errorf("configuring mutual tls: %w", err)
+		}
 	}
 
 	serverErrors := make(chan error, 1)
@@ -220,6 +688,11 @@ func run(ctx context.Context, log *logger.Logger) error {
 	go func() {
 		log.Info(ctx, "startup", "status", "api router started", "host", api.Addr)
 
+		if cfg.Web.TLSCertFile != "" {
+			serverErrors <- api.ListenAndServeTLS(cfg.Web.TLSCertFile, cfg.Web.TLSKeyFile)
+			return
+		}
+
 		serverErrors <- api.ListenAndServe()
 	}()
 
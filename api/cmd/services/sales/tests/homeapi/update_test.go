@@ -99,7 +99,7 @@ func update400(sd apitest.SeedData) []apitest.Table {
 				Address: &homeapp.UpdateAddress{},
 			},
 			GotResp: &errs.Error{},
-			ExpResp: errs.Newf(errs.InvalidArgument, "parse: invalid type \"BAD TYPE\""),
+			ExpResp: errs.Newf(errs.InvalidArgument, "parse: invalid type \"BAD TYPE\", must be one of SINGLE FAMILY, CONDO"),
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
 			},
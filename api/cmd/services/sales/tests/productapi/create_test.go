@@ -6,6 +6,7 @@ import (
 	"github.com/ardanlabs/service/api/sdk/http/apitest"
 	"github.com/ardanlabs/service/app/domain/productapp"
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/sdk/money"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -19,14 +20,14 @@ func create200(sd apitest.SeedData) []apitest.Table {
 			StatusCode: http.StatusOK,
 			Input: &productapp.NewProduct{
 				Name:     "Guitar",
-				Cost:     10.34,
+				Cost:     money.MustParse("10.34"),
 				Quantity: 10,
 			},
 			GotResp: &productapp.Product{},
 			ExpResp: &productapp.Product{
 				Name:     "Guitar",
 				UserID:   sd.Users[0].ID.String(),
-				Cost:     10.34,
+				Cost:     money.MustParse("10.34"),
 				Quantity: 10,
 			},
 			CmpFunc: func(got any, exp any) string {
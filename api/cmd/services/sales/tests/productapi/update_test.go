@@ -9,6 +9,7 @@ import (
 	"github.com/ardanlabs/service/app/domain/productapp"
 	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/money"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -22,7 +23,7 @@ func update200(sd apitest.SeedData) []apitest.Table {
 			StatusCode: http.StatusOK,
 			Input: &productapp.UpdateProduct{
 				Name:     dbtest.StringPointer("Guitar"),
-				Cost:     dbtest.FloatPointer(10.34),
+				Cost:     dbtest.MoneyPointer("10.34"),
 				Quantity: dbtest.IntPointer(10),
 			},
 			GotResp: &productapp.Product{},
@@ -30,7 +31,7 @@ func update200(sd apitest.SeedData) []apitest.Table {
 				ID:          sd.Users[0].Products[0].ID.String(),
 				UserID:      sd.Users[0].ID.String(),
 				Name:        "Guitar",
-				Cost:        10.34,
+				Cost:        money.MustParse("10.34"),
 				Quantity:    10,
 				DateCreated: sd.Users[0].Products[0].DateCreated.Format(time.RFC3339),
 				DateUpdated: sd.Users[0].Products[0].DateCreated.Format(time.RFC3339),
@@ -61,7 +62,7 @@ func update400(sd apitest.SeedData) []apitest.Table {
 			Method:     http.MethodPut,
 			StatusCode: http.StatusBadRequest,
 			Input: &productapp.UpdateProduct{
-				Cost:     dbtest.FloatPointer(-1.0),
+				Cost:     dbtest.MoneyPointer("-1.0"),
 				Quantity: dbtest.IntPointer(0),
 			},
 			GotResp: &errs.Error{},
@@ -109,7 +110,7 @@ func update401(sd apitest.SeedData) []apitest.Table {
 			StatusCode: http.StatusUnauthorized,
 			Input: &productapp.UpdateProduct{
 				Name:     dbtest.StringPointer("Guitar"),
-				Cost:     dbtest.FloatPointer(10.34),
+				Cost:     dbtest.MoneyPointer("10.34"),
 				Quantity: dbtest.IntPointer(10),
 			},
 			GotResp: &errs.Error{},
@@ -0,0 +1,231 @@
+package tran_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/mail"
+	"testing"
+
+	"github.com/ardanlabs/service/api/sdk/http/apitest"
+	"github.com/ardanlabs/service/app/domain/tranapp"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+func batchOp(ref string, op string, id string, data any) tranapp.BatchOperation {
+	raw, _ := json.Marshal(data)
+
+	return tranapp.BatchOperation{
+		Ref:  ref,
+		Op:   op,
+		ID:   id,
+		Data: raw,
+	}
+}
+
+func batch200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/tranexample/batch",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input: &tranapp.Batch{
+				Operations: []tranapp.BatchOperation{
+					batchOp("usr", tranapp.OpCreateUser, "", tranapp.NewUser{
+						Name:            "Jill Kennedy",
+						Email:           "jill@ardanlabs.com",
+						Roles:           []string{"USER"},
+						Department:      "IT",
+						Password:        "123",
+						PasswordConfirm: "123",
+					}),
+					batchOp("prd", tranapp.OpCreateProduct, "", map[string]any{
+						"userID":   "${usr}",
+						"name":     "Piano",
+						"cost":     100.50,
+						"quantity": 2,
+					}),
+					batchOp("", tranapp.OpUpdateProduct, "${prd}", map[string]any{
+						"quantity": 5,
+					}),
+				},
+			},
+			GotResp: &tranapp.BatchResult{},
+			ExpResp: &tranapp.BatchResult{},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*tranapp.BatchResult)
+				if !exists {
+					return "error occurred"
+				}
+
+				if len(gotResp.Results) != 3 {
+					return "expected 3 results"
+				}
+
+				var usr tranapp.User
+				if err := json.Unmarshal(gotResp.Results[0].Result, &usr); err != nil {
+					return err.Error()
+				}
+
+				var prd tranapp.Product
+				if err := json.Unmarshal(gotResp.Results[1].Result, &prd); err != nil {
+					return err.Error()
+				}
+
+				var updPrd tranapp.Product
+				if err := json.Unmarshal(gotResp.Results[2].Result, &updPrd); err != nil {
+					return err.Error()
+				}
+
+				if diff := cmp.Diff(usr.Email, "jill@ardanlabs.com"); diff != "" {
+					return diff
+				}
+
+				if diff := cmp.Diff(prd.UserID, usr.ID); diff != "" {
+					return diff
+				}
+
+				if diff := cmp.Diff(updPrd.ID, prd.ID); diff != "" {
+					return diff
+				}
+
+				return cmp.Diff(updPrd.Quantity, 5)
+			},
+		},
+	}
+
+	return table
+}
+
+func batch400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "unknown-ref",
+			URL:        "/v1/tranexample/batch",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input: &tranapp.Batch{
+				Operations: []tranapp.BatchOperation{
+					batchOp("", tranapp.OpUpdateProduct, "${prd}", map[string]any{
+						"quantity": 5,
+					}),
+				},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.InvalidArgument, "operation[0]: unknown ref \"prd\""),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func batch409(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "rollback",
+			URL:        "/v1/tranexample/batch",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusConflict,
+			Input: &tranapp.Batch{
+				Operations: []tranapp.BatchOperation{
+					batchOp("usr", tranapp.OpCreateUser, "", tranapp.NewUser{
+						Name:            "Rollback User",
+						Email:           "rollback@ardanlabs.com",
+						Roles:           []string{"USER"},
+						Department:      "IT",
+						Password:        "123",
+						PasswordConfirm: "123",
+					}),
+					batchOp("", tranapp.OpCreateUser, "", tranapp.NewUser{
+						Name:            "Duplicate User",
+						Email:           sd.Users[0].Email.Address,
+						Roles:           []string{"USER"},
+						Department:      "IT",
+						Password:        "123",
+						PasswordConfirm: "123",
+					}),
+				},
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Aborted, "operation[1]: %s", userbus.ErrUniqueEmail),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func batchRolledBack(t *testing.T, userBus *userbus.Business) {
+	addr, _ := mail.ParseAddress("rollback@ardanlabs.com")
+
+	_, err := userBus.QueryByEmail(context.Background(), *addr)
+	if !errors.Is(err, userbus.ErrNotFound) {
+		t.Fatalf("Should not find the user created by the failed batch : %v", err)
+	}
+}
+
+func batch207(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "independent",
+			URL:        "/v1/tranexample/batch/independent",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusMultiStatus,
+			Input: &tranapp.Batch{
+				Operations: []tranapp.BatchOperation{
+					batchOp("usr", tranapp.OpCreateUser, "", tranapp.NewUser{
+						Name:            "Jack Kennedy",
+						Email:           "jack@ardanlabs.com",
+						Roles:           []string{"USER"},
+						Department:      "IT",
+						Password:        "123",
+						PasswordConfirm: "123",
+					}),
+					batchOp("", tranapp.OpCreateProduct, "", map[string]any{
+						"userID":   "${usr}",
+						"name":     "",
+						"cost":     100.50,
+						"quantity": 2,
+					}),
+					batchOp("", tranapp.OpUpdateProduct, uuid.NewString(), map[string]any{
+						"quantity": 5,
+					}),
+					batchOp("", tranapp.OpUpdateUser, "${usr}", map[string]any{
+						"department": "Sales",
+					}),
+				},
+			},
+			GotResp: &tranapp.MultiStatus{},
+			ExpResp: &tranapp.MultiStatus{},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*tranapp.MultiStatus)
+				if !exists {
+					return "error occurred"
+				}
+
+				var statuses []int
+				for _, res := range gotResp.Results {
+					statuses = append(statuses, res.Status)
+				}
+
+				return cmp.Diff(statuses, []int{http.StatusCreated, http.StatusBadRequest, http.StatusNotFound, http.StatusOK})
+			},
+		},
+	}
+
+	return table
+}
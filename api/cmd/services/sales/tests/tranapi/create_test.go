@@ -6,6 +6,7 @@ import (
 	"github.com/ardanlabs/service/api/sdk/http/apitest"
 	"github.com/ardanlabs/service/app/domain/tranapp"
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/sdk/money"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -20,7 +21,7 @@ func create200(sd apitest.SeedData) []apitest.Table {
 			Input: &tranapp.NewTran{
 				Product: tranapp.NewProduct{
 					Name:     "Guitar",
-					Cost:     10.34,
+					Cost:     money.MustParse("10.34"),
 					Quantity: 10,
 				},
 				User: tranapp.NewUser{
@@ -35,7 +36,7 @@ func create200(sd apitest.SeedData) []apitest.Table {
 			GotResp: &tranapp.Product{},
 			ExpResp: &tranapp.Product{
 				Name:     "Guitar",
-				Cost:     10.34,
+				Cost:     money.MustParse("10.34"),
 				Quantity: 10,
 			},
 			CmpFunc: func(got any, exp any) string {
@@ -83,7 +84,7 @@ func create400(sd apitest.SeedData) []apitest.Table {
 			Input: &tranapp.NewTran{
 				Product: tranapp.NewProduct{
 					Name:     "Gu",
-					Cost:     10.34,
+					Cost:     money.MustParse("10.34"),
 					Quantity: 10,
 				},
 				User: tranapp.NewUser{
@@ -22,4 +22,10 @@ func Test_Tran(t *testing.T) {
 
 	test.Run(t, create200(sd), "create-200")
 	test.Run(t, create400(sd), "create-400")
+
+	test.Run(t, batch200(sd), "batch-200")
+	test.Run(t, batch400(sd), "batch-400")
+	test.Run(t, batch409(sd), "batch-409")
+	batchRolledBack(t, test.DB.BusDomain.User)
+	test.Run(t, batch207(sd), "batch-207")
 }
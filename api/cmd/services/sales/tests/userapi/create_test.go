@@ -24,6 +24,8 @@ func create200(sd apitest.SeedData) []apitest.Table {
 				Department:      "IT",
 				Password:        "123",
 				PasswordConfirm: "123",
+				Metadata:        map[string]any{"team": "red", "level": 3},
+				Tags:            []string{"go", "sql"},
 			},
 			GotResp: &userapp.User{},
 			ExpResp: &userapp.User{
@@ -32,6 +34,8 @@ func create200(sd apitest.SeedData) []apitest.Table {
 				Roles:      []string{"ADMIN"},
 				Department: "IT",
 				Enabled:    true,
+				Metadata:   map[string]any{"team": "red", "level": float64(3)},
+				Tags:       []string{"go", "sql"},
 			},
 			CmpFunc: func(got any, exp any) string {
 				gotResp, exists := got.(*userapp.User)
@@ -83,7 +87,7 @@ func create400(sd apitest.SeedData) []apitest.Table {
 				PasswordConfirm: "123",
 			},
 			GotResp: &errs.Error{},
-			ExpResp: errs.Newf(errs.InvalidArgument, "parse: invalid role \"SUPER\""),
+			ExpResp: errs.Newf(errs.InvalidArgument, "parse: invalid role \"SUPER\", must be one of ADMIN, USER"),
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
 			},
@@ -167,3 +171,36 @@ func create401(sd apitest.SeedData) []apitest.Table {
 
 	return table
 }
+
+func create409(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "duplicate-email",
+			URL:        "/v1/users",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusConflict,
+			Input: &userapp.NewUser{
+				Name:            "Bill Kennedy",
+				Email:           sd.Admins[0].Email.Address,
+				Roles:           []string{"USER"},
+				Department:      "IT",
+				Password:        "123",
+				PasswordConfirm: "123",
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.Aborted, "email is not unique"),
+			CmpFunc: func(got any, exp any) string {
+				if diff := cmp.Diff(got, exp); diff != "" {
+					return diff
+				}
+
+				expDetails := []any{map[string]any{"field": "email", "error": "email already exists"}}
+
+				return cmp.Diff(got.(*errs.Error).Details, expDetails)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,126 @@
+package user_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/api/sdk/http/apitest"
+	"github.com/ardanlabs/service/app/domain/userapp"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func importCSV(data string) apitest.Raw {
+	return apitest.Raw{
+		ContentType: "text/csv",
+		Data:        []byte(data),
+	}
+}
+
+func import200(sd apitest.SeedData) []apitest.Table {
+	clean := `name,email,roles,department,password
+Import One,import1@ardanlabs.com,"ADMIN,USER",IT,gophers
+Import Two,import2@ardanlabs.com,USER,"Sales, ""West""",gophers
+Import Three,import3@ardanlabs.com,USER,,gophers
+`
+
+	bad := fmt.Sprintf(`name,email,roles,password
+Import Four,import4@ardanlabs.com,USER,gophers
+Import Five,%s,USER,gophers
+Import Six,not-an-email,USER,gophers
+Import Seven,import7@ardanlabs.com,SUPER,gophers
+Import Eight,import4@ardanlabs.com,USER,gophers
+Import Nine,import9@ardanlabs.com,USER,gophers
+`, sd.Users[0].Email.Address)
+
+	failures := []userapp.ImportFailure{
+		{Row: 3, Reason: "email is not unique"},
+		{Row: 4, Reason: "email must be a valid email address"},
+		{Row: 5, Reason: `parse: invalid role "SUPER", must be one of ADMIN, USER`},
+		{Row: 6, Reason: "email is not unique"},
+	}
+
+	table := []apitest.Table{
+		{
+			Name:       "clean",
+			URL:        "/v1/users/import",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input:      importCSV(clean),
+			GotResp:    &userapp.ImportReport{},
+			ExpResp:    &userapp.ImportReport{Imported: 3},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-rows-strict",
+			URL:        "/v1/users/import?mode=strict",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input:      importCSV(bad),
+			GotResp:    &userapp.ImportReport{},
+			ExpResp: &userapp.ImportReport{
+				Imported: 0,
+				Failed: []userapp.ImportFailure{
+					{Row: 3, Reason: "email is not unique"},
+					{Row: 4, Reason: "email must be a valid email address"},
+					{Row: 5, Reason: `parse: invalid role "SUPER", must be one of ADMIN, USER`},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-rows",
+			URL:        "/v1/users/import",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			Input:      importCSV(bad),
+			GotResp:    &userapp.ImportReport{},
+			ExpResp:    &userapp.ImportReport{Imported: 2, Failed: failures},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func import400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "bad-header",
+			URL:        "/v1/users/import",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input:      importCSV("name,email,roles,password,salary\n"),
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, `header: unknown column "salary"`),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-mode",
+			URL:        "/v1/users/import?mode=partial",
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input:      importCSV("name,email,roles,password\n"),
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, `invalid mode "partial", expecting strict or lenient`),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
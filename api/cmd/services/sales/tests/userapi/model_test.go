@@ -9,15 +9,16 @@ import (
 
 func toAppUser(bus userbus.User) userapp.User {
 	return userapp.User{
-		ID:           bus.ID.String(),
-		Name:         bus.Name.String(),
-		Email:        bus.Email.Address,
-		Roles:        userbus.ParseRolesToString(bus.Roles),
-		PasswordHash: nil, // This field is not marshalled.
-		Department:   bus.Department,
-		Enabled:      bus.Enabled,
-		DateCreated:  bus.DateCreated.Format(time.RFC3339),
-		DateUpdated:  bus.DateUpdated.Format(time.RFC3339),
+		ID:            bus.ID.String(),
+		Name:          bus.Name.String(),
+		Email:         bus.Email.Address,
+		Roles:         userbus.ParseRolesToString(bus.Roles),
+		PasswordHash:  nil, // This field is not marshalled.
+		Department:    bus.Department,
+		Enabled:       bus.Enabled,
+		EmailVerified: bus.EmailVerified,
+		DateCreated:   bus.DateCreated.Format(time.RFC3339),
+		DateUpdated:   bus.DateUpdated.Format(time.RFC3339),
 	}
 }
 
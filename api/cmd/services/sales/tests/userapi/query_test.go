@@ -3,10 +3,12 @@ package user_test
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"sort"
 
 	"github.com/ardanlabs/service/api/sdk/http/apitest"
 	"github.com/ardanlabs/service/app/domain/userapp"
+	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/app/sdk/query"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/google/go-cmp/cmp"
@@ -50,6 +52,65 @@ func query200(sd apitest.SeedData) []apitest.Table {
 	return table
 }
 
+func queryFilter200(sd apitest.SeedData) []apitest.Table {
+	filter := url.Values{
+		"filter": {"email:eq:" + sd.Users[0].Email.Address, "name:like:Name"},
+	}
+
+	table := []apitest.Table{
+		{
+			Name:       "filter",
+			URL:        "/v1/users?" + filter.Encode(),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[userapp.User]{},
+			ExpResp: &query.Result[userapp.User]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       1,
+				Items:       toAppUsers([]userbus.User{sd.Users[0].User}),
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func queryFilter400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "field",
+			URL:        "/v1/users?filter=password_hash:eq:secret",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, `[{"field":"filter","error":"unknown filter field: password_hash"}]`),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "operator",
+			URL:        "/v1/users?filter=enabled:like:true",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, `[{"field":"filter","error":"operator \"like\" not allowed for field enabled"}]`),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
 func queryByID200(sd apitest.SeedData) []apitest.Table {
 	table := []apitest.Table{
 		{
@@ -68,3 +129,75 @@ func queryByID200(sd apitest.SeedData) []apitest.Table {
 
 	return table
 }
+
+func queryMetadata200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "metadata",
+			URL:        "/v1/users?metadata=" + url.QueryEscape(`{"team":"red"}`),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[userapp.User]{},
+			ExpResp: &query.Result[userapp.User]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       1,
+				Items: []userapp.User{
+					{Email: "bill@ardanlabs.com", Metadata: map[string]any{"team": "red", "level": float64(3)}},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*query.Result[userapp.User])
+				if !exists {
+					return "error occurred"
+				}
+
+				// Only the fields set by the create test are compared.
+				for i, usr := range gotResp.Items {
+					gotResp.Items[i] = userapp.User{Email: usr.Email, Metadata: usr.Metadata}
+				}
+
+				return cmp.Diff(gotResp, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func queryTags200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "tags",
+			URL:        "/v1/users?tags=sql&role=ADMIN",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[userapp.User]{},
+			ExpResp: &query.Result[userapp.User]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       1,
+				Items: []userapp.User{
+					{Email: "bill@ardanlabs.com", Tags: []string{"go", "sql"}},
+				},
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*query.Result[userapp.User])
+				if !exists {
+					return "error occurred"
+				}
+
+				// Only the fields set by the create test are compared.
+				for i, usr := range gotResp.Items {
+					gotResp.Items[i] = userapp.User{Email: usr.Email, Tags: usr.Tags}
+				}
+
+				return cmp.Diff(gotResp, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,62 @@
+package user_test
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/api/sdk/http/apitest"
+	"github.com/ardanlabs/service/app/domain/userapp"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func forgotPassword204(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "known",
+			URL:        "/v1/users/password/forgot",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusNoContent,
+			Input:      &userapp.ForgotPassword{Email: sd.Users[0].Email.Address},
+		},
+		{
+			Name:       "unknown",
+			URL:        "/v1/users/password/forgot",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusNoContent,
+			Input:      &userapp.ForgotPassword{Email: "nobody@example.com"},
+		},
+	}
+
+	return table
+}
+
+func resetPassword400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "bad-token",
+			URL:        "/v1/users/password/reset",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input:      &userapp.ResetPassword{Token: "bad", Password: "newpass", PasswordConfirm: "newpass"},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "invalid password reset token"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "password-mismatch",
+			URL:        "/v1/users/password/reset",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input:      &userapp.ResetPassword{Token: "bad", Password: "newpass", PasswordConfirm: "other"},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"passwordConfirm\",\"error\":\"passwordConfirm must be equal to Password\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
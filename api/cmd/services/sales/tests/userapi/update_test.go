@@ -18,6 +18,7 @@ func update200(sd apitest.SeedData) []apitest.Table {
 			Name:       "basic",
 			URL:        fmt.Sprintf("/v1/users/%s", sd.Users[0].ID),
 			Token:      sd.Users[0].Token,
+			Headers:    map[string]string{"If-Match": userapp.ETag(sd.Users[0].User)},
 			Method:     http.MethodPut,
 			StatusCode: http.StatusOK,
 			Input: &userapp.UpdateUser{
@@ -35,6 +36,8 @@ func update200(sd apitest.SeedData) []apitest.Table {
 				Roles:       []string{"USER"},
 				Department:  "IT",
 				Enabled:     true,
+				Metadata:    map[string]any{},
+				Tags:        []string{},
 				DateCreated: sd.Users[0].DateCreated.Format(time.RFC3339),
 				DateUpdated: sd.Users[0].DateUpdated.Format(time.RFC3339),
 			},
@@ -67,6 +70,8 @@ func update200(sd apitest.SeedData) []apitest.Table {
 				Roles:       []string{"USER"},
 				Department:  sd.Admins[0].Department,
 				Enabled:     true,
+				Metadata:    map[string]any{},
+				Tags:        []string{},
 				DateCreated: sd.Admins[0].DateCreated.Format(time.RFC3339),
 				DateUpdated: sd.Admins[0].DateUpdated.Format(time.RFC3339),
 			},
@@ -93,6 +98,7 @@ func update400(sd apitest.SeedData) []apitest.Table {
 			Name:       "bad-input",
 			URL:        fmt.Sprintf("/v1/users/%s", sd.Users[0].ID),
 			Token:      sd.Users[0].Token,
+			Headers:    map[string]string{"If-Match": "*"},
 			Method:     http.MethodPut,
 			StatusCode: http.StatusBadRequest,
 			Input: &userapp.UpdateUser{
@@ -115,7 +121,7 @@ func update400(sd apitest.SeedData) []apitest.Table {
 				Roles: []string{"BAD ROLE"},
 			},
 			GotResp: &errs.Error{},
-			ExpResp: errs.Newf(errs.InvalidArgument, "parse: invalid role \"BAD ROLE\""),
+			ExpResp: errs.Newf(errs.InvalidArgument, "parse: invalid role \"BAD ROLE\", must be one of ADMIN, USER"),
 			CmpFunc: func(got any, exp any) string {
 				return cmp.Diff(got, exp)
 			},
@@ -189,3 +195,41 @@ func update401(sd apitest.SeedData) []apitest.Table {
 
 	return table
 }
+
+func update412(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "stale-etag",
+			URL:        fmt.Sprintf("/v1/users/%s", sd.Users[0].ID),
+			Token:      sd.Users[0].Token,
+			Headers:    map[string]string{"If-Match": userapp.ETag(sd.Users[0].User)},
+			Method:     http.MethodPut,
+			StatusCode: http.StatusPreconditionFailed,
+			Input: &userapp.UpdateUser{
+				Department: dbtest.StringPointer("Sales"),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.PreconditionFailed, "resource has been modified"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "missing-etag",
+			URL:        fmt.Sprintf("/v1/users/%s", sd.Users[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPut,
+			StatusCode: http.StatusPreconditionRequired,
+			Input: &userapp.UpdateUser{
+				Department: dbtest.StringPointer("Sales"),
+			},
+			GotResp: &errs.Error{},
+			ExpResp: errs.Newf(errs.PreconditionRequired, "If-Match header is required"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
@@ -21,15 +21,32 @@ func Test_User(t *testing.T) {
 	// -------------------------------------------------------------------------
 
 	test.Run(t, query200(sd), "query-200")
+	test.Run(t, queryFilter200(sd), "queryfilter-200")
+	test.Run(t, queryFilter400(sd), "queryfilter-400")
 	test.Run(t, queryByID200(sd), "querybyid-200")
 
 	test.Run(t, create200(sd), "create-200")
 	test.Run(t, create401(sd), "create-401")
 	test.Run(t, create400(sd), "create-400")
+	test.Run(t, create409(sd), "create-409")
+
+	test.Run(t, queryMetadata200(sd), "querymetadata-200")
+	test.Run(t, queryTags200(sd), "querytags-200")
+
+	test.Run(t, import200(sd), "import-200")
+	test.Run(t, import400(sd), "import-400")
 
 	test.Run(t, update200(sd), "update-200")
 	test.Run(t, update401(sd), "update-401")
 	test.Run(t, update400(sd), "update-400")
+	test.Run(t, update412(sd), "update-412")
+
+	test.Run(t, verify200(sd), "verify-200")
+	test.Run(t, verify400(sd), "verify-400")
+	test.Run(t, verify401(sd), "verify-401")
+
+	test.Run(t, forgotPassword204(sd), "forgotpassword-204")
+	test.Run(t, resetPassword400(sd), "resetpassword-400")
 
 	test.Run(t, delete200(sd), "delete-200")
 	test.Run(t, delete401(sd), "delete-401")
@@ -0,0 +1,89 @@
+package user_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/api/sdk/http/apitest"
+	"github.com/ardanlabs/service/app/domain/userapp"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/google/go-cmp/cmp"
+)
+
+func verify200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "token",
+			URL:        fmt.Sprintf("/v1/users/verify/%s", sd.Users[0].ID),
+			Token:      sd.Admins[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusOK,
+			GotResp:    &userapp.VerificationToken{},
+			ExpResp:    &userapp.VerificationToken{},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*userapp.VerificationToken)
+				if !exists {
+					return "error occurred"
+				}
+
+				if gotResp.Token == "" || gotResp.ExpiresAt == "" {
+					return "expected a token and an expiration"
+				}
+
+				return ""
+			},
+		},
+	}
+
+	return table
+}
+
+func verify400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "missing-input",
+			URL:        "/v1/users/verify",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input:      &userapp.VerifyEmail{},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "validate: [{\"field\":\"token\",\"error\":\"token is a required field\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-token",
+			URL:        "/v1/users/verify",
+			Method:     http.MethodPost,
+			StatusCode: http.StatusBadRequest,
+			Input:      &userapp.VerifyEmail{Token: "bad"},
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.InvalidArgument, "invalid verification token: token contains an invalid number of segments"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func verify401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "notadmin",
+			URL:        fmt.Sprintf("/v1/users/verify/%s", sd.Users[0].ID),
+			Token:      sd.Users[0].Token,
+			Method:     http.MethodPost,
+			StatusCode: http.StatusUnauthorized,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_only]: rego evaluation failed : bindings results[[{[true] map[x:false]}]] ok[true]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
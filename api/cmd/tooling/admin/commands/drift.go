@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/migrate"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+)
+
+// Drift reports the differences between the schema in the database and the
+// schema produced by the migrations applied to it. It fails when there are
+// differences so it can be used in scripts.
+func Drift(cfg sqldb.Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	diffs, err := migrate.Drift(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("detect drift: %w", err)
+	}
+
+	if len(diffs) == 0 {
+		fmt.Println("no drift detected")
+		return nil
+	}
+
+	for _, diff := range diffs {
+		fmt.Println(diff)
+	}
+
+	return fmt.Errorf("schema has drifted from the migrations: %d differences", len(diffs))
+}
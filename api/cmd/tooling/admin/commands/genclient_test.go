@@ -0,0 +1,31 @@
+package commands_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/api/cmd/tooling/admin/commands"
+)
+
+func Test_GenClient(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "salesclient")
+
+	// Generation fails when a bound route has no client description, so
+	// this also checks the client covers every route.
+	if err := commands.GenClient(dir); err != nil {
+		t.Fatalf("Should be able to generate the client : %s", err)
+	}
+
+	src, err := os.ReadFile(filepath.Join(dir, "client.go"))
+	if err != nil {
+		t.Fatalf("Should be able to read the client : %s", err)
+	}
+
+	for _, name := range []string{"UserPatch", "UserExport", "UserImport", "TranBatch", "EventStream", "UserVerifyEmail", "UserResetPassword"} {
+		if !strings.Contains(string(src), "func (c *Client) "+name+"(") {
+			t.Errorf("Should generate the %s method", name)
+		}
+	}
+}
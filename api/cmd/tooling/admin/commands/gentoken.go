@@ -16,10 +16,10 @@ import (
 	"github.com/google/uuid"
 )
 
-// GenToken generates a JWT for the specified user.
-func GenToken(log *logger.Logger, dbConfig sqldb.Config, keyPath string, userID uuid.UUID, kid string) error {
+// GenToken generates a JWT for the specified user. The tenant is optional.
+func GenToken(log *logger.Logger, dbConfig sqldb.Config, keyPath string, userID uuid.UUID, kid string, tenant string) error {
 	if kid == "" {
-		fmt.Println("help: gentoken <user_id> <kid>")
+		fmt.Println("help: gentoken <user_id> <kid> [tenant]")
 		return ErrHelp
 	}
 
@@ -74,7 +74,8 @@ func GenToken(log *logger.Logger, dbConfig sqldb.Config, keyPath string, userID
 			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(8760 * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
 		},
-		Roles: userbus.ParseRolesToString(usr.Roles),
+		Roles:  userbus.ParseRolesToString(usr.Roles),
+		Tenant: tenant,
 	}
 
 	// This will generate a JWT with the claims embedded in them. The database
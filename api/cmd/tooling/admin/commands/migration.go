@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ardanlabs/darwin/v3"
+)
+
+// Migration adds a new migration with the description to the end of the
+// migration document. The version is the next version after the latest one
+// unless a version is provided, which must be later than every existing
+// version. The statements for the migration are added to the document by
+// hand.
+func Migration(file string, description string, version string) error {
+	description = strings.TrimSpace(description)
+	switch {
+	case description == "":
+		return errors.New("a description is required")
+	case strings.ContainsAny(description, ":\r\n"):
+		return errors.New("the description must be a single line without colons")
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("reading migrations: %w", err)
+	}
+
+	doc := string(data)
+
+	migs := darwin.ParseMigrations(doc)
+	if migs == nil && strings.TrimSpace(doc) != "" {
+		return fmt.Errorf("parsing migrations: %s has an invalid version or description line", file)
+	}
+
+	var latest float64
+	for _, mig := range migs {
+		latest = max(latest, mig.Version)
+	}
+
+	if version == "" {
+		version = nextVersion(latest)
+	}
+
+	v, err := strconv.ParseFloat(version, 64)
+	if err != nil {
+		return fmt.Errorf("parsing version %q: %w", version, err)
+	}
+
+	for _, mig := range migs {
+		if mig.Version == v {
+			return fmt.Errorf("version %s already exists", version)
+		}
+	}
+
+	if v <= latest {
+		return fmt.Errorf("version %s must be later than the latest version %.2f", version, latest)
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(doc, "\n"))
+	if doc != "" {
+		b.WriteString("\n\n")
+	}
+	fmt.Fprintf(&b, "-- Version: %s\n", version)
+	fmt.Fprintf(&b, "-- Description: %s\n", description)
+	b.WriteString("-- Add the statements for this migration here.\n")
+
+	if got := len(darwin.ParseMigrations(b.String())); got != len(migs)+1 {
+		return fmt.Errorf("generated migrations can't be parsed: got %d migrations", got)
+	}
+
+	if err := os.WriteFile(file, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("writing migrations: %w", err)
+	}
+
+	fmt.Println("migration added:", version, description)
+
+	return nil
+}
+
+// nextVersion returns the version after the latest version. Versions have
+// a two digit minor number and are compared as numbers, so the major number
+// is bumped after .99.
+func nextVersion(latest float64) string {
+	major, minor := int(latest), int(math.Round((latest-math.Trunc(latest))*100))
+	if latest == 0 {
+		major = 1
+	}
+
+	minor++
+	if minor > 99 {
+		major++
+		minor = 1
+	}
+
+	return fmt.Sprintf("%d.%02d", major, minor)
+}
@@ -0,0 +1,126 @@
+package commands_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/darwin/v3"
+	"github.com/ardanlabs/service/api/cmd/tooling/admin/commands"
+)
+
+const migrations = `-- Version: 1.01
+-- Description: Create table users
+CREATE TABLE users (user_id UUID NOT NULL);
+
+-- Version: 1.02
+-- Description: Create table products
+CREATE TABLE products (product_id UUID NOT NULL);
+`
+
+func Test_Migration(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "migrate.sql")
+	if err := os.WriteFile(file, []byte(migrations), 0644); err != nil {
+		t.Fatalf("Should be able to write the migrations : %s", err)
+	}
+
+	if err := commands.Migration(file, "Create table orders", ""); err != nil {
+		t.Fatalf("Should be able to add a migration : %s", err)
+	}
+
+	if err := commands.Migration(file, "Add column status", "1.10"); err != nil {
+		t.Fatalf("Should be able to add a migration with a version : %s", err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Should be able to read the migrations : %s", err)
+	}
+
+	if !strings.HasPrefix(string(data), migrations) {
+		t.Errorf("Should keep the existing migrations :\n%s", data)
+	}
+
+	migs := darwin.ParseMigrations(string(data))
+
+	exp := []struct {
+		version     float64
+		description string
+	}{
+		{1.01, "Create table users"},
+		{1.02, "Create table products"},
+		{1.03, "Create table orders"},
+		{1.10, "Add column status"},
+	}
+
+	if len(migs) != len(exp) {
+		t.Fatalf("Should get %d migrations : got %d", len(exp), len(migs))
+	}
+
+	for i, mig := range migs {
+		if mig.Version != exp[i].version || mig.Description != exp[i].description {
+			t.Errorf("Should get migration %v %q : got %v %q", exp[i].version, exp[i].description, mig.Version, mig.Description)
+		}
+	}
+}
+
+func Test_MigrationRejected(t *testing.T) {
+	table := []struct {
+		name        string
+		description string
+		version     string
+	}{
+		{name: "existing", description: "Create table orders", version: "1.02"},
+		{name: "equal", description: "Create table orders", version: "1.020"},
+		{name: "older", description: "Create table orders", version: "1.01"},
+		{name: "invalid", description: "Create table orders", version: "one"},
+		{name: "empty", description: " ", version: ""},
+		{name: "colon", description: "Orders: create table", version: ""},
+		{name: "lines", description: "Create table\norders", version: ""},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "migrate.sql")
+			if err := os.WriteFile(file, []byte(migrations), 0644); err != nil {
+				t.Fatalf("Should be able to write the migrations : %s", err)
+			}
+
+			if err := commands.Migration(file, tt.description, tt.version); err == nil {
+				t.Fatalf("Should fail to add the migration")
+			}
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("Should be able to read the migrations : %s", err)
+			}
+
+			if string(data) != migrations {
+				t.Errorf("Should leave the migrations unchanged :\n%s", data)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
+
+func Test_MigrationNext(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "migrate.sql")
+	if err := os.WriteFile(file, []byte("-- Version: 1.99\n-- Description: Last minor\nSELECT 1;\n"), 0644); err != nil {
+		t.Fatalf("Should be able to write the migrations : %s", err)
+	}
+
+	if err := commands.Migration(file, "Next major", ""); err != nil {
+		t.Fatalf("Should be able to add a migration : %s", err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Should be able to read the migrations : %s", err)
+	}
+
+	if !strings.Contains(string(data), "-- Version: 2.01\n") {
+		t.Errorf("Should bump the major version :\n%s", data)
+	}
+}
@@ -29,6 +29,9 @@ type config struct {
 		MaxOpenConns int    `conf:"default:0"`
 		DisableTLS   bool   `conf:"default:true"`
 	}
+	Migrate struct {
+		File string `conf:"default:business/sdk/migrate/sql/migrate.sql"`
+	}
 	Auth struct {
 		KeysFolder string `conf:"default:zarf/keys/"`
 		DefaultKID string `conf:"default:54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"`
@@ -93,6 +96,18 @@ func processCommands(args conf.Args, log *logger.Logger, cfg config) error {
 			return fmt.Errorf("migrating database: %w", err)
 		}
 
+	case "migration":
+		description := args.Num(1)
+		version := args.Num(2)
+		if err := commands.Migration(cfg.Migrate.File, description, version); err != nil {
+			return fmt.Errorf("adding migration: %w", err)
+		}
+
+	case "drift":
+		if err := commands.Drift(dbConfig); err != nil {
+			return fmt.Errorf("checking schema drift: %w", err)
+		}
+
 	case "seed":
 		if err := commands.Seed(dbConfig); err != nil {
 			return fmt.Errorf("seeding database: %w", err)
@@ -135,17 +150,25 @@ func processCommands(args conf.Args, log *logger.Logger, cfg config) error {
 		if kid == "" {
 			kid = cfg.Auth.DefaultKID
 		}
-		if err := commands.GenToken(log, dbConfig, cfg.Auth.KeysFolder, userID, kid); err != nil {
+		if err := commands.GenToken(log, dbConfig, cfg.Auth.KeysFolder, userID, kid, args.Num(3)); err != nil {
 			return fmt.Errorf("generating token: %w", err)
 		}
 
+	case "genclient":
+		if err := commands.GenClient(args.Num(1)); err != nil {
+			return fmt.Errorf("generating client: %w", err)
+		}
+
 	default:
 		fmt.Println("migrate:    create the schema in the database")
+		fmt.Println("migration:  add a new migration to the schema")
+		fmt.Println("drift:      compare the schema in the database to the migrations")
 		fmt.Println("seed:       add data to the database")
 		fmt.Println("useradd:    add a new user to the database")
 		fmt.Println("users:      get a list of users from the database")
 		fmt.Println("genkey:     generate a set of private/public key files")
 		fmt.Println("gentoken:   generate a JWT for a user with claims")
+		fmt.Println("genclient:  generate a typed go client for the service")
 		fmt.Println("provide a command to get more help.")
 		return commands.ErrHelp
 	}
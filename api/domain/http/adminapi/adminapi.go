@@ -0,0 +1,53 @@
+// Package adminapi maintains the web based api for administering the
+// service at runtime.
+package adminapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/adminapp"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type api struct {
+	adminApp *adminapp.App
+}
+
+func newAPI(adminApp *adminapp.App) *api {
+	return &api{
+		adminApp: adminApp,
+	}
+}
+
+func (api *api) queryConfig(ctx context.Context, r *http.Request) (web.Encoder, error) {
+	return api.adminApp.QueryConfig(ctx), nil
+}
+
+func (api *api) updateConfig(ctx context.Context, r *http.Request) (web.Encoder, error) {
+	var app adminapp.UpdateRuntimeConfig
+	if err := web.Decode(r, &app, web.RejectUnknownMembers()); err != nil {
+		return nil, errs.New(errs.InvalidArgument, err)
+	}
+
+	cfg, err := api.adminApp.UpdateConfig(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (api *api) queryMaintenance(ctx context.Context, r *http.Request) (web.Encoder, error) {
+	return api.adminApp.QueryMaintenance(ctx), nil
+}
+
+func (api *api) updateMaintenance(ctx context.Context, r *http.Request) (web.Encoder, error) {
+	var app adminapp.Maintenance
+	if err := web.Decode(r, &app, web.RejectUnknownMembers()); err != nil {
+		return nil, errs.New(errs.InvalidArgument, err)
+	}
+
+	return api.adminApp.UpdateMaintenance(ctx, app), nil
+}
@@ -0,0 +1,40 @@
+package adminapi
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/app/domain/adminapp"
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/quota"
+	"github.com/ardanlabs/service/app/sdk/runtimecfg"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log           *logger.Logger
+	AuthClient    *authclient.Client
+	RuntimeConfig *runtimecfg.Config
+	Quotas        *quota.Quotas
+}
+
+// Routes adds specific routes for this group. These routes must be exempt
+// from maintenance mode so it can be turned off.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.Log, cfg.AuthClient)
+	quotas := mid.Quota(cfg.Log, cfg.Quotas)
+	ruleAdmin := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAdminOnly)
+
+	admin := app.Group(version, "/admin", authen, quotas, ruleAdmin)
+
+	api := newAPI(adminapp.NewApp(cfg.RuntimeConfig))
+	admin.HandlerFunc(http.MethodGet, "/config", api.queryConfig)
+	admin.HandlerFunc(http.MethodPut, "/config", api.updateConfig)
+	admin.HandlerFunc(http.MethodGet, "/maintenance", api.queryMaintenance)
+	admin.HandlerFunc(http.MethodPut, "/maintenance", api.updateMaintenance)
+}
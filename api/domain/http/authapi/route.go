@@ -2,6 +2,7 @@ package authapi
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/ardanlabs/service/api/sdk/http/mid"
 	"github.com/ardanlabs/service/app/sdk/auth"
@@ -11,8 +12,16 @@ import (
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
-	UserBus *userbus.Business
-	Auth    *auth.Auth
+	UserBus  *userbus.Business
+	Auth     *auth.Auth
+	Throttle Throttle
+}
+
+// Throttle contains the settings for slowing down repeated failed Basic
+// auth attempts for the same identity. A zero value disables throttling.
+type Throttle struct {
+	MaxFailures int
+	Window      time.Duration
 }
 
 // Routes adds specific routes for this group.
@@ -20,7 +29,7 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	bearer := mid.Bearer(cfg.Auth)
-	basic := mid.Basic(cfg.UserBus, cfg.Auth)
+	basic := mid.Basic(cfg.UserBus, cfg.Auth, cfg.Throttle.MaxFailures, cfg.Throttle.Window)
 
 	api := newAPI(cfg.Auth)
 	app.HandlerFunc(http.MethodGet, version, "/auth/token/{kid}", api.token, basic)
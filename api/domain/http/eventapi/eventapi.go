@@ -0,0 +1,46 @@
+// Package eventapi maintains the web based api for streaming domain events.
+package eventapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/service/app/domain/eventapp"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type api struct {
+	eventApp  *eventapp.App
+	keepAlive time.Duration
+}
+
+func newAPI(eventApp *eventapp.App, keepAlive time.Duration) *api {
+	return &api{
+		eventApp:  eventApp,
+		keepAlive: keepAlive,
+	}
+}
+
+func (api *api) stream(ctx context.Context, r *http.Request) (web.Encoder, error) {
+	qp := eventapp.QueryParams{
+		Domain: r.URL.Query().Get("domain"),
+		Action: r.URL.Query().Get("action"),
+	}
+
+	sub, err := api.eventApp.Subscribe(mid.GetClaims(ctx), qp)
+	if err != nil {
+		return nil, err
+	}
+
+	// The subscription ends when the client disconnects.
+	context.AfterFunc(r.Context(), sub.Close)
+
+	sse := web.SSE[eventapp.Event]{
+		Events:    sub.Events(),
+		KeepAlive: api.keepAlive,
+	}
+
+	return sse, nil
+}
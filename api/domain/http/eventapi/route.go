@@ -0,0 +1,47 @@
+package eventapi
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/service/api/sdk/http/clientgen"
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/app/domain/eventapp"
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/quota"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	AuthClient *authclient.Client
+	Delegate   *delegate.Delegate
+	Buffer     int
+	KeepAlive  time.Duration
+	Quotas     *quota.Quotas
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.Log, cfg.AuthClient)
+	quotas := mid.Quota(cfg.Log, cfg.Quotas)
+
+	eventApp := eventapp.NewApp(cfg.Log, cfg.Buffer)
+	eventApp.Register(cfg.Delegate, userbus.DomainName, eventapp.UserAuthorizer, userbus.ActionUpdated)
+
+	api := newAPI(eventApp, cfg.KeepAlive)
+	app.HandlerFunc(http.MethodGet, version, "/events", api.stream, authen, quotas)
+}
+
+// Endpoints describes the routes in this group for the generated client.
+func Endpoints() []clientgen.Endpoint {
+	return []clientgen.Endpoint{
+		{Name: "EventStream", Method: http.MethodGet, Path: "/v1/events", Response: clientgen.Stream{}, Auth: true},
+	}
+}
@@ -3,10 +3,13 @@ package homeapi
 import (
 	"net/http"
 
+	"github.com/ardanlabs/service/api/sdk/http/clientgen"
 	"github.com/ardanlabs/service/api/sdk/http/mid"
 	"github.com/ardanlabs/service/app/domain/homeapp"
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/app/sdk/quota"
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/foundation/logger"
@@ -19,6 +22,7 @@ type Config struct {
 	UserBus    *userbus.Business
 	HomeBus    *homebus.Business
 	AuthClient *authclient.Client
+	Quotas     *quota.Quotas
 }
 
 // Routes adds specific routes for this group.
@@ -26,14 +30,26 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authen := mid.Authenticate(cfg.Log, cfg.AuthClient)
+	quotas := mid.Quota(cfg.Log, cfg.Quotas)
 	ruleAny := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAny)
 	ruleUserOnly := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleUserOnly)
 	ruleAuthorizeHome := mid.AuthorizeHome(cfg.Log, cfg.AuthClient, cfg.HomeBus)
 
 	api := newAPI(homeapp.NewApp(cfg.HomeBus))
-	app.HandlerFunc(http.MethodGet, version, "/homes", api.query, authen, ruleAny)
-	app.HandlerFunc(http.MethodGet, version, "/homes/{home_id}", api.queryByID, authen, ruleAuthorizeHome)
-	app.HandlerFunc(http.MethodPost, version, "/homes", api.create, authen, ruleUserOnly)
-	app.HandlerFunc(http.MethodPut, version, "/homes/{home_id}", api.update, authen, ruleAuthorizeHome)
-	app.HandlerFunc(http.MethodDelete, version, "/homes/{home_id}", api.delete, authen, ruleAuthorizeHome)
+	app.HandlerFunc(http.MethodGet, version, "/homes", api.query, authen, quotas, ruleAny)
+	app.HandlerFunc(http.MethodGet, version, "/homes/{home_id}", api.queryByID, authen, quotas, ruleAuthorizeHome)
+	app.HandlerFunc(http.MethodPost, version, "/homes", api.create, authen, quotas, ruleUserOnly)
+	app.HandlerFunc(http.MethodPut, version, "/homes/{home_id}", api.update, authen, quotas, ruleAuthorizeHome)
+	app.HandlerFunc(http.MethodDelete, version, "/homes/{home_id}", api.delete, authen, quotas, ruleAuthorizeHome)
+}
+
+// Endpoints describes the routes in this group for the generated client.
+func Endpoints() []clientgen.Endpoint {
+	return []clientgen.Endpoint{
+		{Name: "HomeQuery", Method: http.MethodGet, Path: "/v1/homes", Response: query.Result[homeapp.Home]{}, Query: true, Auth: true},
+		{Name: "HomeQueryByID", Method: http.MethodGet, Path: "/v1/homes/{home_id}", Response: homeapp.Home{}, Auth: true},
+		{Name: "HomeCreate", Method: http.MethodPost, Path: "/v1/homes", Request: homeapp.NewHome{}, Response: homeapp.Home{}, Auth: true},
+		{Name: "HomeUpdate", Method: http.MethodPut, Path: "/v1/homes/{home_id}", Request: homeapp.UpdateHome{}, Response: homeapp.Home{}, Auth: true},
+		{Name: "HomeDelete", Method: http.MethodDelete, Path: "/v1/homes/{home_id}", Auth: true},
+	}
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ardanlabs/service/app/domain/productapp"
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
@@ -67,7 +68,7 @@ func (api *api) query(ctx context.Context, r *http.Request) (web.Encoder, error)
 		return nil, err
 	}
 
-	return prd, nil
+	return query.NewPageResult(prd, r.URL), nil
 }
 
 func (api *api) queryByID(ctx context.Context, r *http.Request) (web.Encoder, error) {
@@ -3,10 +3,13 @@ package productapi
 import (
 	"net/http"
 
+	"github.com/ardanlabs/service/api/sdk/http/clientgen"
 	"github.com/ardanlabs/service/api/sdk/http/mid"
 	"github.com/ardanlabs/service/app/domain/productapp"
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/app/sdk/quota"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/foundation/logger"
@@ -19,6 +22,7 @@ type Config struct {
 	UserBus    *userbus.Business
 	ProductBus *productbus.Business
 	AuthClient *authclient.Client
+	Quotas     *quota.Quotas
 }
 
 // Routes adds specific routes for this group.
@@ -26,14 +30,26 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authen := mid.Authenticate(cfg.Log, cfg.AuthClient)
+	quotas := mid.Quota(cfg.Log, cfg.Quotas)
 	ruleAny := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAny)
 	ruleUserOnly := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleUserOnly)
 	ruleAuthorizeProduct := mid.AuthorizeProduct(cfg.Log, cfg.AuthClient, cfg.ProductBus)
 
 	api := newAPI(productapp.NewApp(cfg.ProductBus))
-	app.HandlerFunc(http.MethodGet, version, "/products", api.query, authen, ruleAny)
-	app.HandlerFunc(http.MethodGet, version, "/products/{product_id}", api.queryByID, authen, ruleAuthorizeProduct)
-	app.HandlerFunc(http.MethodPost, version, "/products", api.create, authen, ruleUserOnly)
-	app.HandlerFunc(http.MethodPut, version, "/products/{product_id}", api.update, authen, ruleAuthorizeProduct)
-	app.HandlerFunc(http.MethodDelete, version, "/products/{product_id}", api.delete, authen, ruleAuthorizeProduct)
+	app.HandlerFunc(http.MethodGet, version, "/products", api.query, authen, quotas, ruleAny)
+	app.HandlerFunc(http.MethodGet, version, "/products/{product_id}", api.queryByID, authen, quotas, ruleAuthorizeProduct)
+	app.HandlerFunc(http.MethodPost, version, "/products", api.create, authen, quotas, ruleUserOnly)
+	app.HandlerFunc(http.MethodPut, version, "/products/{product_id}", api.update, authen, quotas, ruleAuthorizeProduct)
+	app.HandlerFunc(http.MethodDelete, version, "/products/{product_id}", api.delete, authen, quotas, ruleAuthorizeProduct)
+}
+
+// Endpoints describes the routes in this group for the generated client.
+func Endpoints() []clientgen.Endpoint {
+	return []clientgen.Endpoint{
+		{Name: "ProductQuery", Method: http.MethodGet, Path: "/v1/products", Response: query.Result[productapp.Product]{}, Query: true, Auth: true},
+		{Name: "ProductQueryByID", Method: http.MethodGet, Path: "/v1/products/{product_id}", Response: productapp.Product{}, Auth: true},
+		{Name: "ProductCreate", Method: http.MethodPost, Path: "/v1/products", Request: productapp.NewProduct{}, Response: productapp.Product{}, Auth: true},
+		{Name: "ProductUpdate", Method: http.MethodPut, Path: "/v1/products/{product_id}", Request: productapp.UpdateProduct{}, Response: productapp.Product{}, Auth: true},
+		{Name: "ProductDelete", Method: http.MethodDelete, Path: "/v1/products/{product_id}", Auth: true},
+	}
 }
@@ -3,35 +3,53 @@ package tranapi
 import (
 	"net/http"
 
+	"github.com/ardanlabs/service/api/sdk/http/clientgen"
 	"github.com/ardanlabs/service/api/sdk/http/mid"
 	"github.com/ardanlabs/service/app/domain/tranapp"
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/quota"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
-	"github.com/jmoiron/sqlx"
 )
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
 	Log        *logger.Logger
-	DB         *sqlx.DB
+	Transactor sqldb.Executor
 	UserBus    *userbus.Business
 	ProductBus *productbus.Business
 	AuthClient *authclient.Client
+	Quotas     *quota.Quotas
 }
 
+// maxBody is the largest request body a transaction buffers so it can be
+// sent again when the transaction is retried.
+const maxBody = 1 << 20
+
 // Routes adds specific routes for this group.
 func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authen := mid.Authenticate(cfg.Log, cfg.AuthClient)
-	transaction := mid.BeginCommitRollback(cfg.Log, sqldb.NewBeginner(cfg.DB))
+	quotas := mid.Quota(cfg.Log, cfg.Quotas)
+	transaction := mid.BeginCommitRollback(cfg.Transactor, maxBody)
 	ruleAdmin := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAdminOnly)
 
 	api := newAPI(tranapp.NewApp(cfg.UserBus, cfg.ProductBus))
-	app.HandlerFunc(http.MethodPost, version, "/tranexample", api.create, authen, ruleAdmin, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/tranexample", api.create, authen, quotas, ruleAdmin, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/tranexample/batch", api.batch, mid.RequireJSON(), authen, quotas, ruleAdmin, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/tranexample/batch/independent", api.batchIndependent, mid.RequireJSON(), authen, quotas, ruleAdmin)
+}
+
+// Endpoints describes the routes in this group for the generated client.
+func Endpoints() []clientgen.Endpoint {
+	return []clientgen.Endpoint{
+		{Name: "TranCreate", Method: http.MethodPost, Path: "/v1/tranexample", Request: tranapp.NewTran{}, Response: tranapp.Product{}, Auth: true},
+		{Name: "TranBatch", Method: http.MethodPost, Path: "/v1/tranexample/batch", Request: tranapp.Batch{}, Response: tranapp.BatchResult{}, Auth: true},
+		{Name: "TranBatchIndependent", Method: http.MethodPost, Path: "/v1/tranexample/batch/independent", Request: tranapp.Batch{}, Response: tranapp.MultiStatus{}, Auth: true},
+	}
 }
@@ -33,3 +33,26 @@ func (api *api) create(ctx context.Context, r *http.Request) (web.Encoder, error
 
 	return prd, nil
 }
+
+func (api *api) batch(ctx context.Context, r *http.Request) (web.Encoder, error) {
+	var app tranapp.Batch
+	if err := web.Decode(r, &app); err != nil {
+		return nil, errs.New(errs.InvalidArgument, err)
+	}
+
+	res, err := api.tranApp.Batch(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (api *api) batchIndependent(ctx context.Context, r *http.Request) (web.Encoder, error) {
+	var app tranapp.Batch
+	if err := web.Decode(r, &app); err != nil {
+		return nil, errs.New(errs.InvalidArgument, err)
+	}
+
+	return api.tranApp.BatchIndependent(ctx, app), nil
+}
@@ -18,6 +18,11 @@ func parseQueryParams(r *http.Request) (userapp.QueryParams, error) {
 		Email:            values.Get("email"),
 		StartCreatedDate: values.Get("start_created_date"),
 		EndCreatedDate:   values.Get("end_created_date"),
+		Metadata:         values.Get("metadata"),
+		Role:             values.Get("role"),
+		Tags:             values.Get("tags"),
+		Filter:           values["filter"],
+		Fields:           values.Get("fields"),
 	}
 
 	return filter, nil
@@ -3,20 +3,34 @@ package userapi
 import (
 	"net/http"
 
+	"github.com/ardanlabs/service/api/sdk/http/clientgen"
 	"github.com/ardanlabs/service/api/sdk/http/mid"
 	"github.com/ardanlabs/service/app/domain/userapp"
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/emailverify"
+	"github.com/ardanlabs/service/app/sdk/notify"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/app/sdk/quota"
 	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
+// importBatchSize is the number of rows inserted under each transaction when
+// importing users.
+const importBatchSize = 500
+
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
 	Log        *logger.Logger
+	Transactor sqldb.Executor
 	UserBus    *userbus.Business
 	AuthClient *authclient.Client
+	Verifier   *emailverify.Verifier
+	Notifier   notify.Sender
+	Quotas     *quota.Quotas
 }
 
 // Routes adds specific routes for this group.
@@ -24,15 +38,54 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authen := mid.Authenticate(cfg.Log, cfg.AuthClient)
+	quotas := mid.Quota(cfg.Log, cfg.Quotas)
 	ruleAdmin := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAdminOnly)
 	ruleAuthorizeUser := mid.AuthorizeUser(cfg.Log, cfg.AuthClient, cfg.UserBus, auth.RuleAdminOrSubject)
 	ruleAuthorizeAdmin := mid.AuthorizeUser(cfg.Log, cfg.AuthClient, cfg.UserBus, auth.RuleAdminOnly)
+	ifMatch := mid.IfMatch(userapp.CurrentETag)
+	ifModifiedSince := mid.IfModifiedSince(userapp.CurrentLastModified)
+
+	importer := userapp.NewImporter(cfg.UserBus, cfg.Transactor, importBatchSize)
+
+	api := newAPI(userapp.NewAppWithAccountSupport(cfg.UserBus, cfg.Verifier, cfg.Notifier), importer)
+	app.HandlerFunc(http.MethodGet, version, "/users", api.query, authen, quotas, ruleAdmin)
+	app.HandlerFunc(http.MethodGet, version, "/users/export", api.export, authen, quotas, ruleAdmin)
+	app.HandlerFunc(http.MethodGet, version, "/users/{user_id}", api.queryByID, authen, quotas, ruleAuthorizeUser, ifModifiedSince)
+	app.HandlerFunc(http.MethodPost, version, "/users", api.create, authen, quotas, ruleAdmin)
+	app.HandlerFunc(http.MethodPost, version, "/users/import", api.importUsers, mid.ContentType("text/csv"), authen, quotas, ruleAdmin)
+	app.HandlerFunc(http.MethodPut, version, "/users/role/{user_id}", api.updateRole, authen, quotas, ruleAuthorizeAdmin)
+	app.HandlerFunc(http.MethodPut, version, "/users/{user_id}", api.update, authen, quotas, ruleAuthorizeUser, ifMatch)
+	app.HandlerFunc(http.MethodPatch, version, "/users/{user_id}", api.patch, mid.ContentType(web.PatchContentType, web.MergePatchContentType), authen, quotas, ruleAuthorizeUser, ifMatch)
+	app.HandlerFunc(http.MethodDelete, version, "/users/{user_id}", api.delete, authen, quotas, ruleAuthorizeUser)
+
+	// Email verification is only available when a verifier is configured.
+	if cfg.Verifier != nil {
+		app.HandlerFunc(http.MethodPost, version, "/users/verify/{user_id}", api.verificationToken, authen, quotas, ruleAuthorizeAdmin)
+		app.HandlerFunc(http.MethodPost, version, "/users/verify", api.verifyEmail, mid.RequireJSON(), quotas)
+	}
+
+	// Password reset is only available when the tokens can be delivered.
+	if cfg.Notifier != nil {
+		app.HandlerFunc(http.MethodPost, version, "/users/password/forgot", api.forgotPassword, mid.RequireJSON(), quotas)
+		app.HandlerFunc(http.MethodPost, version, "/users/password/reset", api.resetPassword, mid.RequireJSON(), quotas)
+	}
+}
 
-	api := newAPI(userapp.NewApp(cfg.UserBus))
-	app.HandlerFunc(http.MethodGet, version, "/users", api.query, authen, ruleAdmin)
-	app.HandlerFunc(http.MethodGet, version, "/users/{user_id}", api.queryByID, authen, ruleAuthorizeUser)
-	app.HandlerFunc(http.MethodPost, version, "/users", api.create, authen, ruleAdmin)
-	app.HandlerFunc(http.MethodPut, version, "/users/role/{user_id}", api.updateRole, authen, ruleAuthorizeAdmin)
-	app.HandlerFunc(http.MethodPut, version, "/users/{user_id}", api.update, authen, ruleAuthorizeUser)
-	app.HandlerFunc(http.MethodDelete, version, "/users/{user_id}", api.delete, authen, ruleAuthorizeUser)
+// Endpoints describes the routes in this group for the generated client.
+func Endpoints() []clientgen.Endpoint {
+	return []clientgen.Endpoint{
+		{Name: "UserQuery", Method: http.MethodGet, Path: "/v1/users", Response: query.Result[userapp.User]{}, Query: true, Auth: true},
+		{Name: "UserExport", Method: http.MethodGet, Path: "/v1/users/export", Response: clientgen.Stream{}, Query: true, Auth: true},
+		{Name: "UserQueryByID", Method: http.MethodGet, Path: "/v1/users/{user_id}", Response: userapp.User{}, Auth: true},
+		{Name: "UserCreate", Method: http.MethodPost, Path: "/v1/users", Request: userapp.NewUser{}, Response: userapp.User{}, Auth: true},
+		{Name: "UserImport", Method: http.MethodPost, Path: "/v1/users/import", Request: clientgen.Stream{}, ContentType: "text/csv", Response: userapp.ImportReport{}, Query: true, Auth: true},
+		{Name: "UserUpdateRole", Method: http.MethodPut, Path: "/v1/users/role/{user_id}", Request: userapp.UpdateUserRole{}, Response: userapp.User{}, Auth: true},
+		{Name: "UserUpdate", Method: http.MethodPut, Path: "/v1/users/{user_id}", Request: userapp.UpdateUser{}, Response: userapp.User{}, Auth: true},
+		{Name: "UserPatch", Method: http.MethodPatch, Path: "/v1/users/{user_id}", Request: clientgen.Stream{}, ContentType: web.MergePatchContentType, Response: userapp.User{}, Auth: true},
+		{Name: "UserDelete", Method: http.MethodDelete, Path: "/v1/users/{user_id}", Auth: true},
+		{Name: "UserVerificationToken", Method: http.MethodPost, Path: "/v1/users/verify/{user_id}", Response: userapp.VerificationToken{}, Auth: true},
+		{Name: "UserVerifyEmail", Method: http.MethodPost, Path: "/v1/users/verify", Request: userapp.VerifyEmail{}},
+		{Name: "UserForgotPassword", Method: http.MethodPost, Path: "/v1/users/password/forgot", Request: userapp.ForgotPassword{}},
+		{Name: "UserResetPassword", Method: http.MethodPost, Path: "/v1/users/password/reset", Request: userapp.ResetPassword{}},
+	}
 }
@@ -3,20 +3,24 @@ package userapi
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 
 	"github.com/ardanlabs/service/app/domain/userapp"
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
 type api struct {
-	userApp *userapp.App
+	userApp  *userapp.App
+	importer *userapp.Importer
 }
 
-func newAPI(userApp *userapp.App) *api {
+func newAPI(userApp *userapp.App, importer *userapp.Importer) *api {
 	return &api{
-		userApp: userApp,
+		userApp:  userApp,
+		importer: importer,
 	}
 }
 
@@ -48,6 +52,44 @@ func (api *api) update(ctx context.Context, r *http.Request) (web.Encoder, error
 	return usr, nil
 }
 
+func (api *api) patch(ctx context.Context, r *http.Request) (web.Encoder, error) {
+	patch, err := web.DecodePatch(r)
+	if err != nil {
+		return nil, errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, err := api.userApp.QueryByID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := json.Marshal(userapp.NewUpdateUser(usr))
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, "marshal: %s", err)
+	}
+
+	doc, err = patch.Apply(doc)
+	if err != nil {
+		return nil, errs.New(errs.InvalidArgument, err)
+	}
+
+	var app userapp.UpdateUser
+	if err := app.Decode(doc); err != nil {
+		return nil, errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := app.Validate(); err != nil {
+		return nil, err
+	}
+
+	usr, err = api.userApp.Update(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	return usr, nil
+}
+
 func (api *api) updateRole(ctx context.Context, r *http.Request) (web.Encoder, error) {
 	var app userapp.UpdateUserRole
 	if err := web.Decode(r, &app); err != nil {
@@ -81,7 +123,48 @@ func (api *api) query(ctx context.Context, r *http.Request) (web.Encoder, error)
 		return nil, err
 	}
 
-	return usr, nil
+	return query.NewPageResult(usr, r.URL), nil
+}
+
+func (api *api) export(ctx context.Context, r *http.Request) (web.Encoder, error) {
+	qp, err := parseQueryParams(r)
+	if err != nil {
+		return nil, errs.New(errs.InvalidArgument, err)
+	}
+
+	exp, err := api.userApp.Export(qp)
+	if err != nil {
+		return nil, err
+	}
+
+	switch web.Negotiate(r, "application/x-ndjson", "text/csv") {
+	case "application/x-ndjson":
+		return web.NDJSON[userapp.ExportRow]{Stream: exp.Stream}, nil
+
+	case "text/csv":
+		return web.CSV[userapp.ExportRow]{Header: exp.Fields, Stream: exp.Stream}, nil
+	}
+
+	return nil, errs.Newf(errs.NotAcceptable, "export is available as application/x-ndjson or text/csv")
+}
+
+func (api *api) importUsers(ctx context.Context, r *http.Request) (web.Encoder, error) {
+	var strict bool
+
+	switch mode := r.URL.Query().Get("mode"); mode {
+	case "", "lenient":
+	case "strict":
+		strict = true
+	default:
+		return nil, errs.Newf(errs.InvalidArgument, "invalid mode %q, expecting strict or lenient", mode)
+	}
+
+	report, err := api.importer.Import(ctx, r.Body, strict)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
 }
 
 func (api *api) queryByID(ctx context.Context, r *http.Request) (web.Encoder, error) {
@@ -92,3 +175,51 @@ func (api *api) queryByID(ctx context.Context, r *http.Request) (web.Encoder, er
 
 	return usr, nil
 }
+
+func (api *api) verificationToken(ctx context.Context, r *http.Request) (web.Encoder, error) {
+	vt, err := api.userApp.VerificationToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return vt, nil
+}
+
+func (api *api) verifyEmail(ctx context.Context, r *http.Request) (web.Encoder, error) {
+	var app userapp.VerifyEmail
+	if err := web.Decode(r, &app); err != nil {
+		return nil, errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := api.userApp.VerifyEmail(ctx, app); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (api *api) forgotPassword(ctx context.Context, r *http.Request) (web.Encoder, error) {
+	var app userapp.ForgotPassword
+	if err := web.Decode(r, &app); err != nil {
+		return nil, errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := api.userApp.ForgotPassword(ctx, app); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (api *api) resetPassword(ctx context.Context, r *http.Request) (web.Encoder, error) {
+	var app userapp.ResetPassword
+	if err := web.Decode(r, &app); err != nil {
+		return nil, errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := api.userApp.ResetPassword(ctx, app); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
@@ -3,10 +3,13 @@ package vproductapi
 import (
 	"net/http"
 
+	"github.com/ardanlabs/service/api/sdk/http/clientgen"
 	"github.com/ardanlabs/service/api/sdk/http/mid"
 	"github.com/ardanlabs/service/app/domain/vproductapp"
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/app/sdk/quota"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/vproductbus"
 	"github.com/ardanlabs/service/foundation/logger"
@@ -19,6 +22,7 @@ type Config struct {
 	UserBus     *userbus.Business
 	VProductBus *vproductbus.Business
 	AuthClient  *authclient.Client
+	Quotas      *quota.Quotas
 }
 
 // Routes adds specific routes for this group.
@@ -26,8 +30,16 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authen := mid.Authenticate(cfg.Log, cfg.AuthClient)
+	quotas := mid.Quota(cfg.Log, cfg.Quotas)
 	ruleAdmin := mid.Authorize(cfg.Log, cfg.AuthClient, auth.RuleAdminOnly)
 
 	api := newAPI(vproductapp.NewApp(cfg.VProductBus))
-	app.HandlerFunc(http.MethodGet, version, "/vproducts", api.query, authen, ruleAdmin)
+	app.HandlerFunc(http.MethodGet, version, "/vproducts", api.query, authen, quotas, ruleAdmin)
+}
+
+// Endpoints describes the routes in this group for the generated client.
+func Endpoints() []clientgen.Endpoint {
+	return []clientgen.Endpoint{
+		{Name: "VProductQuery", Method: http.MethodGet, Path: "/v1/vproducts", Response: query.Result[vproductapp.Product]{}, Query: true, Auth: true},
+	}
 }
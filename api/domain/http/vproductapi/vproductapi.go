@@ -7,6 +7,7 @@ import (
 
 	"github.com/ardanlabs/service/app/domain/vproductapp"
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
@@ -31,5 +32,5 @@ func (api *api) query(ctx context.Context, r *http.Request) (web.Encoder, error)
 		return nil, err
 	}
 
-	return prd, nil
+	return query.NewPageResult(prd, r.URL), nil
 }
@@ -40,16 +40,25 @@ func (at *Test) Run(t *testing.T, table []Table, testName string) {
 			r := httptest.NewRequest(tt.Method, tt.URL, nil)
 			w := httptest.NewRecorder()
 
-			if tt.Input != nil {
+			switch input := tt.Input.(type) {
+			case nil:
+			case Raw:
+				r = httptest.NewRequest(tt.Method, tt.URL, bytes.NewReader(input.Data))
+				r.Header.Set("Content-Type", input.ContentType)
+			default:
 				d, err := json.Marshal(tt.Input)
 				if err != nil {
 					t.Fatalf("Should be able to marshal the model : %s", err)
 				}
 
 				r = httptest.NewRequest(tt.Method, tt.URL, bytes.NewBuffer(d))
+				r.Header.Set("Content-Type", "application/json")
 			}
 
 			r.Header.Set("Authorization", "Bearer "+tt.Token)
+			for key, value := range tt.Headers {
+				r.Header.Set(key, value)
+			}
 			at.mux.ServeHTTP(w, r)
 
 			if w.Code != tt.StatusCode {
@@ -60,6 +69,11 @@ func (at *Test) Run(t *testing.T, table []Table, testName string) {
 				return
 			}
 
+			if tt.Golden != "" {
+				Golden(t, tt.Golden, w.Body.Bytes(), tt.Scrubbers...)
+				return
+			}
+
 			if err := json.Unmarshal(w.Body.Bytes(), tt.GotResp); err != nil {
 				t.Fatalf("Should be able to unmarshal the response : %s", err)
 			}
@@ -0,0 +1,125 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// update rewrites the golden files with the responses the tests get.
+var update = flag.Bool("update", false, "update the golden files")
+
+// Scrubber replaces the volatile values of a response, like ids and
+// timestamps, so the response can be compared to a golden file. It's called
+// for every field of every object with the name and value of the field and
+// returns the value to compare.
+type Scrubber func(field string, value any) any
+
+// ScrubFields returns a scrubber that replaces the values of the named
+// fields, at any depth, with a placeholder naming the field. A null value is
+// kept, so a field that should be set but isn't still fails the comparison.
+func ScrubFields(fields ...string) Scrubber {
+	scrub := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		scrub[field] = struct{}{}
+	}
+
+	return func(field string, value any) any {
+		if _, exists := scrub[field]; !exists || value == nil {
+			return value
+		}
+
+		return "<" + field + ">"
+	}
+}
+
+// Golden compares the JSON response to the golden file with the name in the
+// testdata directory, after scrubbing it. The response is formatted before
+// it's compared, so the golden file is easy to review. Running the tests
+// with the -update flag writes the responses to the golden files instead.
+func Golden(t *testing.T, name string, data []byte, scrubbers ...Scrubber) {
+	t.Helper()
+
+	got, err := scrubJSON(data, scrubbers)
+	if err != nil {
+		t.Fatalf("Should be able to scrub the response : %s : %s", err, data)
+	}
+
+	path := filepath.Join("testdata", name+".golden.json")
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Should be able to create the testdata directory : %s", err)
+		}
+
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("Should be able to write the golden file : %s", err)
+		}
+
+		return
+	}
+
+	exp, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			t.Fatalf("Should have the golden file %s, run the test with -update to create it", path)
+		}
+		t.Fatalf("Should be able to read the golden file : %s", err)
+	}
+
+	if diff := cmp.Diff(string(exp), string(got)); diff != "" {
+		t.Fatalf("Should match the golden file %s, run the test with -update if the change is expected :\n%s", path, diff)
+	}
+}
+
+// scrubJSON scrubs the fields of the JSON document and formats it.
+func scrubJSON(data []byte, scrubbers []Scrubber) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var doc any
+	if err := d.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	doc = scrub(doc, scrubbers)
+
+	var buf bytes.Buffer
+
+	e := json.NewEncoder(&buf)
+	e.SetEscapeHTML(false)
+	e.SetIndent("", "  ")
+
+	if err := e.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func scrub(value any, scrubbers []Scrubber) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for field, fv := range v {
+			fv = scrub(fv, scrubbers)
+			for _, scrubber := range scrubbers {
+				fv = scrubber(field, fv)
+			}
+			v[field] = fv
+		}
+
+	case []any:
+		for i := range v {
+			v[i] = scrub(v[i], scrubbers)
+		}
+	}
+
+	return value
+}
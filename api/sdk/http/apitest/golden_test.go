@@ -0,0 +1,64 @@
+package apitest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/api/sdk/http/apitest"
+	"github.com/ardanlabs/service/api/sdk/http/mux"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
+)
+
+type order struct {
+	ID          string      `json:"id"`
+	Customer    string      `json:"customer"`
+	Items       []orderItem `json:"items"`
+	DateCreated string      `json:"dateCreated"`
+	DateShipped *string     `json:"dateShipped"`
+}
+
+type orderItem struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
+
+func (o order) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(o)
+	return data, "application/json", err
+}
+
+func createOrder(ctx context.Context, r *http.Request) (web.Encoder, error) {
+	o := order{
+		ID:       uuid.NewString(),
+		Customer: "Bill Kennedy",
+		Items: []orderItem{
+			{ID: uuid.NewString(), Name: "Gopher Plush", Quantity: 2},
+			{ID: uuid.NewString(), Name: "Go Mug", Quantity: 1},
+		},
+		DateCreated: time.Now().Format(time.RFC3339Nano),
+	}
+
+	return o, nil
+}
+
+func Test_Golden(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodPost, "/orders", nil)
+
+	w := apitest.CallHandler(mux.Config{}, "/orders", createOrder, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should create the order : %d : %s", w.Code, w.Body)
+	}
+
+	// The ids and the creation date change on every call. The ship date
+	// isn't scrubbed since it's expected to be null.
+	apitest.Golden(t, "order", w.Body.Bytes(), apitest.ScrubFields("id", "dateCreated", "dateShipped"))
+}
@@ -0,0 +1,49 @@
+package apitest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/ardanlabs/service/api/sdk/http/mux"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// CallHandler calls the handler with the request and returns the recorded
+// response, without starting a server or binding the routes of the service.
+// The handler is bound to the path, which can have wildcards like a route,
+// behind the same middleware, in the same order, as the routes of the
+// service, followed by the route middleware. The logs are discarded when the
+// config has no logger.
+func CallHandler(cfg mux.Config, path string, handler web.HandlerFunc, r *http.Request, mw ...web.MidFunc) *httptest.ResponseRecorder {
+	if cfg.Log == nil {
+		cfg.Log = logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	}
+
+	route := handlerRoute{
+		method:  r.Method,
+		path:    path,
+		handler: handler,
+		mw:      mw,
+	}
+
+	w := httptest.NewRecorder()
+	mux.WebAPI(cfg, route).ServeHTTP(w, r)
+
+	return w
+}
+
+// handlerRoute binds a single handler in place of the routes of the service.
+type handlerRoute struct {
+	method  string
+	path    string
+	handler web.HandlerFunc
+	mw      []web.MidFunc
+}
+
+// Add implements the RouteAdder interface.
+func (hr handlerRoute) Add(app *web.App, cfg mux.Config) {
+	app.HandlerFunc(hr.method, "", hr.path, hr.handler, hr.mw...)
+}
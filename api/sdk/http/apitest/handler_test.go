@@ -0,0 +1,99 @@
+package apitest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/api/sdk/http/apitest"
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/api/sdk/http/mux"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	appmid "github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/go-cmp/cmp"
+)
+
+type widget struct {
+	ID        string `json:"id"`
+	RequestID string `json:"requestID"`
+}
+
+func (w widget) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(w)
+	return data, "application/json", err
+}
+
+func queryByID(ctx context.Context, r *http.Request) (web.Encoder, error) {
+	id := r.PathValue("widget_id")
+	if id != "1234" {
+		return nil, errs.Newf(errs.NotFound, "widget %s not found", id)
+	}
+
+	return widget{ID: id, RequestID: appmid.GetRequestID(ctx)}, nil
+}
+
+func Test_CallHandler(t *testing.T) {
+	t.Parallel()
+
+	// -------------------------------------------------------------------------
+	// Success
+
+	r := httptest.NewRequest(http.MethodGet, "/widgets/1234", nil)
+	r.Header.Set(mid.RequestIDHeader, "req-1")
+
+	w := apitest.CallHandler(mux.Config{}, "/widgets/{widget_id}", queryByID, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should get the widget : %d : %s", w.Code, w.Body)
+	}
+
+	var got widget
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Should be able to unmarshal the response : %s", err)
+	}
+
+	// The request id is set by the middleware the service runs first.
+	exp := widget{ID: "1234", RequestID: "req-1"}
+	if diff := cmp.Diff(got, exp); diff != "" {
+		t.Errorf("Should get the expected response :\n%s", diff)
+	}
+
+	if got := w.Header().Get(mid.RequestIDHeader); got != "req-1" {
+		t.Errorf("Should return the request id : %q", got)
+	}
+
+	// -------------------------------------------------------------------------
+	// Error
+
+	r = httptest.NewRequest(http.MethodGet, "/widgets/5678", nil)
+
+	w = apitest.CallHandler(mux.Config{}, "/widgets/{widget_id}", queryByID, r)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Should not find the widget : %d : %s", w.Code, w.Body)
+	}
+
+	var gotErr errs.Error
+	if err := json.Unmarshal(w.Body.Bytes(), &gotErr); err != nil {
+		t.Fatalf("Should be able to unmarshal the error : %s", err)
+	}
+
+	expErr := errs.Newf(errs.NotFound, "widget 5678 not found")
+	if gotErr.Code != expErr.Code || gotErr.Message != expErr.Message {
+		t.Errorf("Should get the error from the errors middleware : got[%s: %s] exp[%s: %s]", gotErr.Code, gotErr.Message, expErr.Code, expErr.Message)
+	}
+
+	// -------------------------------------------------------------------------
+	// Route middleware
+
+	r = httptest.NewRequest(http.MethodGet, "/widgets/1234", nil)
+
+	w = apitest.CallHandler(mux.Config{}, "/widgets/{widget_id}", queryByID, r, mid.ContentSecurityPolicy("default-src 'none'"))
+
+	if got := w.Header().Get(appmid.HeaderContentSecurityPolicy); got != "default-src 'none'" {
+		t.Errorf("Should run the route middleware : %q", got)
+	}
+}
@@ -20,15 +20,26 @@ type SeedData struct {
 	Admins []User
 }
 
-// Table represent fields needed for running an api test.
+// Table represent fields needed for running an api test. When a golden file
+// is named, the response is compared to it instead of the expected response.
 type Table struct {
 	Name       string
 	URL        string
 	Token      string
+	Headers    map[string]string
 	Method     string
 	StatusCode int
 	Input      any
 	GotResp    any
 	ExpResp    any
 	CmpFunc    func(got any, exp any) string
+	Golden     string
+	Scrubbers  []Scrubber
+}
+
+// Raw represents an input that is sent as is with the content type instead
+// of being marshaled as JSON.
+type Raw struct {
+	ContentType string
+	Data        []byte
 }
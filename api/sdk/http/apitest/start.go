@@ -3,13 +3,17 @@ package apitest
 import (
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	authbuild "github.com/ardanlabs/service/api/cmd/services/auth/build/all"
 	salesbuild "github.com/ardanlabs/service/api/cmd/services/sales/build/all"
 	"github.com/ardanlabs/service/api/sdk/http/mux"
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/emailverify"
+	"github.com/ardanlabs/service/app/sdk/notify"
 	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/delegate"
 )
 
 // StartTest initialized the system to run a test.
@@ -39,10 +43,18 @@ func StartTest(t *testing.T, testName string) *Test {
 
 	// -------------------------------------------------------------------------
 
+	verifier, err := emailverify.New("apitest-email-verification-secret", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	mux := mux.WebAPI(mux.Config{
-		Log:        db.Log,
-		AuthClient: authClient,
-		DB:         db.DB,
+		Log:           db.Log,
+		AuthClient:    authClient,
+		DB:            db.DB,
+		Delegate:      delegate.New(db.Log),
+		EmailVerifier: verifier,
+		Notifier:      notify.NewLogSender(db.Log),
 	}, salesbuild.Routes())
 
 	return New(db, auth, mux)
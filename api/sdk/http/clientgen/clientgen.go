@@ -0,0 +1,427 @@
+// Package clientgen generates a typed Go client for the service from a set
+// of endpoint descriptions. The generated client reuses the app layer model
+// types so callers send and receive the same structs the handlers use.
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Endpoint describes a single route the client should be able to call.
+type Endpoint struct {
+	// Name is the method name on the generated client, like UserCreate.
+	Name string
+
+	// Method is the http method used by the route.
+	Method string
+
+	// Path is the full path for the route including the version, like
+	// /v1/users/{user_id}. Path parameters become string arguments.
+	Path string
+
+	// Request is a value of the type sent as the request body. Leave this
+	// nil when the route doesn't accept a body.
+	Request any
+
+	// ContentType is the media type of the request body. It defaults to
+	// application/json.
+	ContentType string
+
+	// Response is a value of the type returned in the response body. Leave
+	// this nil when the route doesn't return content.
+	Response any
+
+	// Query indicates the route accepts query string parameters.
+	Query bool
+
+	// Auth indicates the route requires an authorization token.
+	Auth bool
+}
+
+// Stream marks a request or response body that's sent or returned as is
+// instead of being encoded as JSON, like a CSV file or an event stream. A
+// streamed request body is an io.Reader and a streamed response body is an
+// io.ReadCloser the caller must close.
+type Stream struct{}
+
+// Generate renders the source code for a client package with the specified
+// name that provides a method for every endpoint.
+func Generate(pkgName string, endpoints []Endpoint) ([]byte, error) {
+	if !token(pkgName) {
+		return nil, fmt.Errorf("invalid package name %q", pkgName)
+	}
+
+	imps := imports{
+		"bytes":         "bytes",
+		"context":       "context",
+		"encoding/json": "json",
+		"fmt":           "fmt",
+		"io":            "io",
+		"net/http":      "http",
+		"net/url":       "url",
+		"github.com/ardanlabs/service/app/sdk/errs": "errs",
+	}
+
+	methods := make([]method, len(endpoints))
+	names := make(map[string]struct{})
+
+	for i, ep := range endpoints {
+		if _, exists := names[ep.Name]; exists {
+			return nil, fmt.Errorf("endpoint[%s]: duplicate name", ep.Name)
+		}
+		names[ep.Name] = struct{}{}
+
+		m, err := newMethod(ep, imps)
+		if err != nil {
+			return nil, fmt.Errorf("endpoint[%s]: %w", ep.Name, err)
+		}
+		methods[i] = m
+	}
+
+	std, ext := imps.list()
+
+	data := struct {
+		Package    string
+		Imports    []imp
+		ExtImports []imp
+		Methods    []method
+	}{
+		Package:    pkgName,
+		Imports:    std,
+		ExtImports: ext,
+		Methods:    methods,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("execute: %w", err)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format: %w", err)
+	}
+
+	return src, nil
+}
+
+// =============================================================================
+
+type imports map[string]string
+
+type imp struct {
+	Alias string
+	Path  string
+}
+
+func (imps imports) add(pkgPath string) string {
+	if alias, exists := imps[pkgPath]; exists {
+		return alias
+	}
+
+	alias := path.Base(pkgPath)
+	for n := 2; imps.used(alias); n++ {
+		alias = fmt.Sprintf("%s%d", path.Base(pkgPath), n)
+	}
+
+	imps[pkgPath] = alias
+
+	return alias
+}
+
+func (imps imports) used(alias string) bool {
+	for _, a := range imps {
+		if a == alias {
+			return true
+		}
+	}
+
+	return false
+}
+
+// list returns the standard library and the external imports in sorted
+// order so they can be rendered as separate groups.
+func (imps imports) list() (std []imp, ext []imp) {
+	for p, a := range imps {
+		i := imp{Path: p}
+		if a != path.Base(p) {
+			i.Alias = a
+		}
+
+		// Only paths outside the standard library have a dot in the
+		// first element.
+		first, _, _ := strings.Cut(p, "/")
+		if strings.Contains(first, ".") {
+			ext = append(ext, i)
+			continue
+		}
+		std = append(std, i)
+	}
+
+	sort.Slice(std, func(i, j int) bool { return std[i].Path < std[j].Path })
+	sort.Slice(ext, func(i, j int) bool { return ext[i].Path < ext[j].Path })
+
+	return std, ext
+}
+
+// =============================================================================
+
+// reserved are the identifiers used by the generated methods that path
+// parameters can't use.
+var reserved = map[string]struct{}{
+	"c": {}, "ctx": {}, "values": {}, "req": {}, "resp": {}, "err": {},
+	"opts": {}, "path": {}, "url": {}, "http": {}, "errs": {},
+}
+
+type param struct {
+	Name string
+	Key  string
+}
+
+type method struct {
+	Name        string
+	Method      string
+	Path        string
+	Segments    []string
+	Params      []param
+	Request     string
+	ContentType string
+	Response    string
+	Stream      bool
+	Query       bool
+	Auth        bool
+}
+
+func newMethod(ep Endpoint, imps imports) (method, error) {
+	if !token(ep.Name) {
+		return method{}, fmt.Errorf("invalid name %q", ep.Name)
+	}
+
+	switch ep.Method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return method{}, fmt.Errorf("unsupported method %q", ep.Method)
+	}
+
+	if !strings.HasPrefix(ep.Path, "/") {
+		return method{}, fmt.Errorf("path %q must start with a /", ep.Path)
+	}
+
+	m := method{
+		Name:   ep.Name,
+		Method: ep.Method,
+		Path:   ep.Path,
+		Query:  ep.Query,
+		Auth:   ep.Auth,
+	}
+
+	// Break the path into a set of go expressions that are concatenated to
+	// build the path at runtime.
+	var literal strings.Builder
+	for _, seg := range strings.Split(ep.Path[1:], "/") {
+		literal.WriteString("/")
+
+		if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+			literal.WriteString(seg)
+			continue
+		}
+
+		key := seg[1 : len(seg)-1]
+		p := param{
+			Name: paramName(key),
+			Key:  key,
+		}
+		if _, exists := reserved[p.Name]; exists || !token(p.Name) {
+			return method{}, fmt.Errorf("invalid path parameter %q", key)
+		}
+
+		m.Params = append(m.Params, p)
+		m.Segments = append(m.Segments, fmt.Sprintf("%q", literal.String()), fmt.Sprintf("url.PathEscape(%s)", p.Name))
+		literal.Reset()
+	}
+
+	if literal.Len() > 0 {
+		m.Segments = append(m.Segments, fmt.Sprintf("%q", literal.String()))
+	}
+
+	switch ep.Request.(type) {
+	case nil:
+
+	case Stream:
+		m.Request = "io.Reader"
+
+	default:
+		expr, err := typeExpr(reflect.TypeOf(ep.Request), imps)
+		if err != nil {
+			return method{}, fmt.Errorf("request: %w", err)
+		}
+		m.Request = expr
+	}
+
+	if m.Request != "" {
+		m.ContentType = ep.ContentType
+		if m.ContentType == "" {
+			m.ContentType = "application/json"
+		}
+	}
+
+	switch ep.Response.(type) {
+	case nil:
+
+	case Stream:
+		m.Response = "io.ReadCloser"
+		m.Stream = true
+
+	default:
+		expr, err := typeExpr(reflect.TypeOf(ep.Response), imps)
+		if err != nil {
+			return method{}, fmt.Errorf("response: %w", err)
+		}
+		m.Response = expr
+	}
+
+	return m, nil
+}
+
+// paramName converts a path parameter like user_id into a go identifier
+// like userID.
+func paramName(key string) string {
+	parts := strings.Split(key, "_")
+
+	var b strings.Builder
+	for i, part := range parts {
+		switch {
+		case part == "":
+			continue
+		case i == 0:
+			b.WriteString(part)
+		case strings.EqualFold(part, "id"), strings.EqualFold(part, "url"):
+			b.WriteString(strings.ToUpper(part))
+		default:
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+
+	return b.String()
+}
+
+// typeExpr returns the go expression for the type, adding any packages
+// needed to reference the type to the set of imports.
+func typeExpr(t reflect.Type, imps imports) (string, error) {
+	if t.Name() != "" {
+		return namedExpr(t, imps)
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		elem, err := typeExpr(t.Elem(), imps)
+		return "*" + elem, err
+
+	case reflect.Slice:
+		elem, err := typeExpr(t.Elem(), imps)
+		return "[]" + elem, err
+
+	case reflect.Map:
+		key, err := typeExpr(t.Key(), imps)
+		if err != nil {
+			return "", err
+		}
+		elem, err := typeExpr(t.Elem(), imps)
+		return "map[" + key + "]" + elem, err
+	}
+
+	return "", fmt.Errorf("unnamed type %s is not supported", t)
+}
+
+func namedExpr(t reflect.Type, imps imports) (string, error) {
+	if t.PkgPath() == "" {
+		return t.Name(), nil
+	}
+
+	if t.PkgPath() == "main" || strings.Contains(t.PkgPath(), "/internal/") {
+		return "", fmt.Errorf("type %s can't be imported", t)
+	}
+
+	return qualify(t.PkgPath()+"."+t.Name(), imps), nil
+}
+
+// qualify converts a fully qualified type name, as reported by reflect, into
+// a go expression. Generic types report their type arguments with the full
+// package path, like query.Result[github.com/x/userapp.User].
+func qualify(name string, imps imports) string {
+	if name == "" {
+		return ""
+	}
+
+	switch {
+	case strings.HasPrefix(name, "*"):
+		return "*" + qualify(name[1:], imps)
+	case strings.HasPrefix(name, "[]"):
+		return "[]" + qualify(name[2:], imps)
+	}
+
+	base, args, generic := strings.Cut(name, "[")
+	if generic {
+		args = strings.TrimSuffix(args, "]")
+	}
+
+	if idx := strings.LastIndex(base, "."); idx != -1 {
+		base = imps.add(base[:idx]) + base[idx:]
+	}
+
+	if !generic {
+		return base
+	}
+
+	list := splitArgs(args)
+	for i := range list {
+		list[i] = qualify(list[i], imps)
+	}
+
+	return base + "[" + strings.Join(list, ", ") + "]"
+}
+
+// splitArgs splits a list of type arguments on the commas that are not
+// nested inside other type arguments.
+func splitArgs(args string) []string {
+	var list []string
+	var depth, start int
+
+	for i, r := range args {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				list = append(list, strings.TrimSpace(args[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	return append(list, strings.TrimSpace(args[start:]))
+}
+
+func token(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i, r := range s {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,278 @@
+package clientgen_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ardanlabs/service/api/sdk/http/clientgen"
+	"github.com/ardanlabs/service/app/domain/userapp"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/google/go-cmp/cmp"
+)
+
+func sampleEndpoints() []clientgen.Endpoint {
+	return []clientgen.Endpoint{
+		{Name: "UserQuery", Method: http.MethodGet, Path: "/v1/users", Response: query.Result[userapp.User]{}, Query: true, Auth: true},
+		{Name: "UserQueryByID", Method: http.MethodGet, Path: "/v1/users/{user_id}", Response: userapp.User{}, Auth: true},
+		{Name: "UserCreate", Method: http.MethodPost, Path: "/v1/users", Request: userapp.NewUser{}, Response: userapp.User{}, Auth: true},
+		{Name: "UserUpdate", Method: http.MethodPut, Path: "/v1/users/{user_id}", Request: userapp.UpdateUser{}, Response: userapp.User{}, Auth: true},
+		{Name: "UserPatch", Method: http.MethodPatch, Path: "/v1/users/{user_id}", Request: clientgen.Stream{}, ContentType: "application/merge-patch+json", Response: userapp.User{}, Auth: true},
+		{Name: "UserDelete", Method: http.MethodDelete, Path: "/v1/users/{user_id}", Auth: true},
+		{Name: "UserExport", Method: http.MethodGet, Path: "/v1/users/export", Response: clientgen.Stream{}, Query: true, Auth: true},
+		{Name: "UserImport", Method: http.MethodPost, Path: "/v1/users/import", Request: clientgen.Stream{}, ContentType: "text/csv", Response: userapp.ImportReport{}, Query: true, Auth: true},
+		{Name: "Liveness", Method: http.MethodGet, Path: "/v1/liveness"},
+	}
+}
+
+// program exercises the generated client against the url provided as the
+// first argument. The client is generated into the same main package.
+const program = `package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/ardanlabs/service/app/domain/userapp"
+	"github.com/ardanlabs/service/app/sdk/errs"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	ctx := context.Background()
+	c := New(os.Args[1], nil).WithToken("TOKEN")
+
+	if err := New(os.Args[1], nil).Liveness(ctx); err != nil {
+		return fmt.Errorf("liveness: %w", err)
+	}
+
+	res, err := c.UserQuery(ctx, url.Values{"page": {"2"}})
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+	if res.Total != 1 || len(res.Items) != 1 || res.Items[0].Name != "Bill Kennedy" {
+		return fmt.Errorf("query: unexpected result: %+v", res)
+	}
+
+	usr, err := c.UserCreate(ctx, userapp.NewUser{Name: "Bill Kennedy"})
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+	if usr.ID != "45b5fbd3-755f-4379-8f07-a58d4a30fa2f" {
+		return fmt.Errorf("create: unexpected id: %s", usr.ID)
+	}
+
+	name := "Jack Kennedy"
+	if _, err := c.UserUpdate(ctx, usr.ID, userapp.UpdateUser{Name: &name}, WithHeader("If-Match", "*")); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	if _, err := c.UserPatch(ctx, usr.ID, strings.NewReader(` + "`" + `{"name":"Jack Kennedy"}` + "`" + `)); err != nil {
+		return fmt.Errorf("patch: %w", err)
+	}
+
+	if err := c.UserDelete(ctx, usr.ID); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	body, err := c.UserExport(ctx, nil, WithHeader("Accept", "text/csv"))
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	if string(data) != "name\nBill Kennedy\n" {
+		return fmt.Errorf("export: unexpected body: %q", data)
+	}
+
+	report, err := c.UserImport(ctx, url.Values{"mode": {"strict"}}, strings.NewReader("name\nBill Kennedy\n"))
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	if report.Imported != 1 {
+		return fmt.Errorf("import: unexpected report: %+v", report)
+	}
+
+	_, err = c.UserQueryByID(ctx, "missing")
+
+	var appErr *errs.Error
+	if !errors.As(err, &appErr) || appErr.Code != errs.NotFound {
+		return fmt.Errorf("querybyid: expected not found error, got: %v", err)
+	}
+
+	return nil
+}
+`
+
+func Test_Generate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires building the generated client")
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("requires the go toolchain")
+	}
+
+	src, err := clientgen.Generate("main", sampleEndpoints())
+	if err != nil {
+		t.Fatalf("Should be able to generate the client : %s", err)
+	}
+
+	// The generated code must live inside the module so the model types can
+	// be imported. Directories starting with an underscore are ignored by the
+	// go tool when matching package patterns.
+	dir, err := os.MkdirTemp(".", "_clientgen")
+	if err != nil {
+		t.Fatalf("Should be able to create the directory : %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.WriteFile(filepath.Join(dir, "client.go"), src, 0644); err != nil {
+		t.Fatalf("Should be able to write the client : %s", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(program), 0644); err != nil {
+		t.Fatalf("Should be able to write the program : %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	var mu sync.Mutex
+	var calls []string
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		call := r.Method + " " + r.URL.Path
+		if r.URL.RawQuery != "" {
+			call += "?" + r.URL.RawQuery
+		}
+		if v := r.Header.Get("Authorization"); v != "" {
+			call += " " + v
+		}
+		if v := r.Header.Get("If-Match"); v != "" {
+			call += " If-Match:" + v
+		}
+		if v := r.Header.Get("Content-Type"); v != "" {
+			call += " " + v
+		}
+
+		mu.Lock()
+		calls = append(calls, call)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.URL.Path == "/v1/liveness":
+			w.Write([]byte(`{"status":"up"}`))
+
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/users":
+			json.NewEncoder(w).Encode(query.Result[userapp.User]{
+				Items: []userapp.User{{ID: "45b5fbd3-755f-4379-8f07-a58d4a30fa2f", Name: "Bill Kennedy"}},
+				Total: 1,
+			})
+
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+
+		case r.URL.Path == "/v1/users/export":
+			w.Header().Set("Content-Type", "text/csv")
+			w.Write([]byte("name\nBill Kennedy\n"))
+
+		case r.URL.Path == "/v1/users/import":
+			json.NewEncoder(w).Encode(userapp.ImportReport{Imported: 1})
+
+		case strings.HasSuffix(r.URL.Path, "/missing"):
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"not_found","message":"user not found"}`))
+
+		default:
+			json.NewEncoder(w).Encode(userapp.User{ID: "45b5fbd3-755f-4379-8f07-a58d4a30fa2f", Name: "Bill Kennedy"})
+		}
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(h))
+	defer srv.Close()
+
+	cmd := exec.Command("go", "run", "./"+filepath.Base(dir), srv.URL)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Should be able to build and run the generated client : %s\n%s", err, out)
+	}
+
+	exp := []string{
+		"GET /v1/liveness",
+		"GET /v1/users?page=2 Bearer TOKEN",
+		"POST /v1/users Bearer TOKEN application/json",
+		"PUT /v1/users/45b5fbd3-755f-4379-8f07-a58d4a30fa2f Bearer TOKEN If-Match:* application/json",
+		"PATCH /v1/users/45b5fbd3-755f-4379-8f07-a58d4a30fa2f Bearer TOKEN application/merge-patch+json",
+		"DELETE /v1/users/45b5fbd3-755f-4379-8f07-a58d4a30fa2f Bearer TOKEN",
+		"GET /v1/users/export Bearer TOKEN",
+		"POST /v1/users/import?mode=strict Bearer TOKEN text/csv",
+		"GET /v1/users/missing Bearer TOKEN",
+	}
+
+	if diff := cmp.Diff(calls, exp); diff != "" {
+		t.Errorf("Should call the expected routes:\n%s", diff)
+	}
+}
+
+func Test_GenerateErrors(t *testing.T) {
+	type table struct {
+		name     string
+		endpoint clientgen.Endpoint
+	}
+
+	tt := []table{
+		{
+			name:     "method",
+			endpoint: clientgen.Endpoint{Name: "UserCreate", Method: "TRACE", Path: "/v1/users"},
+		},
+		{
+			name:     "name",
+			endpoint: clientgen.Endpoint{Name: "User-Create", Method: http.MethodPost, Path: "/v1/users"},
+		},
+		{
+			name:     "path",
+			endpoint: clientgen.Endpoint{Name: "UserCreate", Method: http.MethodPost, Path: "v1/users"},
+		},
+		{
+			name:     "reserved",
+			endpoint: clientgen.Endpoint{Name: "UserQueryByID", Method: http.MethodGet, Path: "/v1/users/{ctx}"},
+		},
+		{
+			name:     "unnamed",
+			endpoint: clientgen.Endpoint{Name: "UserCreate", Method: http.MethodPost, Path: "/v1/users", Request: struct{ Name string }{}},
+		},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			if _, err := clientgen.Generate("client", []clientgen.Endpoint{tst.endpoint}); err == nil {
+				t.Errorf("Should not be able to generate the client")
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
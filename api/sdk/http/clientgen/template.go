@@ -0,0 +1,177 @@
+package clientgen
+
+import (
+	"strings"
+	"text/template"
+)
+
+var methodConsts = map[string]string{
+	"GET":    "http.MethodGet",
+	"POST":   "http.MethodPost",
+	"PUT":    "http.MethodPut",
+	"PATCH":  "http.MethodPatch",
+	"DELETE": "http.MethodDelete",
+}
+
+var tmpl = template.Must(template.New("client").Funcs(template.FuncMap{
+	"join":   strings.Join,
+	"method": func(m string) string { return methodConsts[m] },
+}).Parse(`// Code generated by clientgen. DO NOT EDIT.
+
+// Package {{.Package}} provides a typed client for the service.
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{if .Alias}}{{.Alias}} {{end}}"{{.Path}}"
+{{- end}}
+{{range .ExtImports}}
+	{{if .Alias}}{{.Alias}} {{end}}"{{.Path}}"
+{{- end}}
+)
+
+// RequestOption can modify a request before it's sent.
+type RequestOption func(r *http.Request)
+
+// WithHeader sets the header on the request.
+func WithHeader(key string, value string) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Set(key, value)
+	}
+}
+
+// Client provides typed access to the service.
+type Client struct {
+	url   string
+	token string
+	http  *http.Client
+}
+
+// New constructs a client for the service at the specified url. If client is
+// nil, the http.DefaultClient is used.
+func New(url string, client *http.Client) *Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &Client{
+		url:  url,
+		http: client,
+	}
+}
+
+// WithToken returns a copy of the client that uses the token to authorize
+// calls.
+func (c *Client) WithToken(token string) *Client {
+	c2 := *c
+	c2.token = token
+
+	return &c2
+}
+{{range .Methods}}
+// {{.Name}} calls {{.Method}} {{.Path}}.{{if .Stream}} The caller must close the
+// response body.{{end}}
+func (c *Client) {{.Name}}(ctx context.Context{{range .Params}}, {{.Name}} string{{end}}{{if .Query}}, values url.Values{{end}}{{if .Request}}, req {{.Request}}{{end}}, opts ...RequestOption) {{if .Response}}({{.Response}}, error){{else}}error{{end}} {
+	path := {{join .Segments " + "}}
+{{if .Stream}}
+	res, err := c.send(ctx, {{method .Method}}, path, {{if .Query}}values{{else}}nil{{end}}, {{if .Request}}req{{else}}nil{{end}}, {{printf "%q" .ContentType}}, {{.Auth}}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body, nil
+{{- else if .Response}}
+	var resp {{.Response}}
+	err := c.do(ctx, {{method .Method}}, path, {{if .Query}}values{{else}}nil{{end}}, {{if .Request}}req{{else}}nil{{end}}, {{printf "%q" .ContentType}}, &resp, {{.Auth}}, opts)
+
+	return resp, err
+{{- else}}
+	return c.do(ctx, {{method .Method}}, path, {{if .Query}}values{{else}}nil{{end}}, {{if .Request}}req{{else}}nil{{end}}, {{printf "%q" .ContentType}}, nil, {{.Auth}}, opts)
+{{- end}}
+}
+{{end}}
+func (c *Client) do(ctx context.Context, method string, path string, values url.Values, req any, contentType string, resp any, auth bool, opts []RequestOption) error {
+	res, err := c.send(ctx, method, path, values, req, contentType, auth, opts)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if resp == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(resp); err != nil {
+		return fmt.Errorf("decoding: %w", err)
+	}
+
+	return nil
+}
+
+// send makes the call and returns the response when it succeeds. A request
+// body that's an io.Reader is sent as is, anything else is encoded as JSON.
+// The caller must close the response body.
+func (c *Client) send(ctx context.Context, method string, path string, values url.Values, req any, contentType string, auth bool, opts []RequestOption) (*http.Response, error) {
+	endpoint := c.url + path
+	if len(values) > 0 {
+		endpoint += "?" + values.Encode()
+	}
+
+	var body io.Reader
+	switch req := req.(type) {
+	case nil:
+
+	case io.Reader:
+		body = req
+
+	default:
+		data, err := json.Marshal(req)
+		if err != nil {
+			return nil, fmt.Errorf("encoding: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	if body != nil {
+		r.Header.Set("Content-Type", contentType)
+	}
+
+	if auth {
+		if c.token == "" {
+			return nil, fmt.Errorf("%s %s: authorization token required", method, path)
+		}
+		r.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	res, err := c.http.Do(r)
+	if err != nil {
+		return nil, fmt.Errorf("do: %w", err)
+	}
+
+	if res.StatusCode < http.StatusBadRequest {
+		return res, nil
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: status %d: reading error: %w", method, path, res.StatusCode, err)
+	}
+
+	var appErr errs.Error
+	if err := json.Unmarshal(data, &appErr); err != nil || appErr.Message == "" {
+		return nil, fmt.Errorf("%s %s: status %d: %s", method, path, res.StatusCode, data)
+	}
+
+	return nil, &appErr
+}
+`))
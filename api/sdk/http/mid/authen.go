@@ -3,6 +3,7 @@ package mid
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
@@ -30,10 +31,17 @@ func Bearer(ath *auth.Auth) web.MidFunc {
 	return addMidFunc(midFunc)
 }
 
-// Basic processes basic authentication logic.
-func Basic(userBus *userbus.Business, ath *auth.Auth) web.MidFunc {
+// Basic processes basic authentication logic. An identity is throttled after
+// maxFailures failed attempts within the window. A zero maxFailures disables
+// the throttling.
+func Basic(userBus *userbus.Business, ath *auth.Auth, maxFailures int, window time.Duration) web.MidFunc {
+	var throttle *mid.Throttle
+	if maxFailures > 0 && window > 0 {
+		throttle = mid.NewThrottle(maxFailures, window)
+	}
+
 	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
-		return mid.Basic(ctx, ath, userBus, r.Header.Get("authorization"), next)
+		return mid.Basic(ctx, ath, userBus, throttle, r.Header.Get("authorization"), next)
 	}
 
 	return addMidFunc(midFunc)
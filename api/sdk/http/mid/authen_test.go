@@ -0,0 +1,146 @@
+package mid_test
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/sdk/hasher"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_BasicUniform(t *testing.T) {
+	t.Parallel()
+
+	app, usr := newBasicApp(t, 0, 0)
+
+	unknown := basicRequest(app, "nobody@example.com", "gophers")
+	wrong := basicRequest(app, usr.Email.Address, "wrong")
+
+	if unknown.Code != http.StatusUnauthorized || wrong.Code != http.StatusUnauthorized {
+		t.Fatalf("Should get a 401 for both : unknown[%d] wrong[%d]", unknown.Code, wrong.Code)
+	}
+
+	if unknown.Body.String() != wrong.Body.String() {
+		t.Errorf("Should not reveal the user exists : unknown[%s] wrong[%s]", unknown.Body, wrong.Body)
+	}
+
+	if w := basicRequest(app, usr.Email.Address, "gophers"); w.Code != http.StatusNoContent {
+		t.Errorf("Should authenticate with the right password : %d : %s", w.Code, w.Body)
+	}
+}
+
+func Test_BasicThrottle(t *testing.T) {
+	t.Parallel()
+
+	const maxFailures = 3
+	const window = 200 * time.Millisecond
+
+	app, usr := newBasicApp(t, maxFailures, window)
+
+	// A success clears the failures for the identity.
+	for i := 0; i < maxFailures-1; i++ {
+		if w := basicRequest(app, usr.Email.Address, "wrong"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("Should get a 401 for the wrong password : %d", w.Code)
+		}
+	}
+
+	if w := basicRequest(app, usr.Email.Address, "gophers"); w.Code != http.StatusNoContent {
+		t.Fatalf("Should authenticate below the limit : %d : %s", w.Code, w.Body)
+	}
+
+	// -------------------------------------------------------------------------
+
+	for i := 0; i < maxFailures; i++ {
+		if w := basicRequest(app, usr.Email.Address, "wrong"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("Should get a 401 for the wrong password : %d", w.Code)
+		}
+	}
+
+	w := basicRequest(app, usr.Email.Address, "gophers")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Should throttle the identity even with the right password : %d : %s", w.Code, w.Body)
+	}
+
+	if w.Header().Get("Retry-After") == "" {
+		t.Errorf("Should tell the client when to retry")
+	}
+
+	// Identities are matched without regard to case.
+	if w := basicRequest(app, "BILL@example.com", "gophers"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Should throttle the identity in any case : %d", w.Code)
+	}
+
+	// Unknown identities are throttled the same as known ones.
+	for i := 0; i < maxFailures; i++ {
+		basicRequest(app, "nobody@example.com", "wrong")
+	}
+
+	if w := basicRequest(app, "nobody@example.com", "wrong"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Should throttle an unknown identity : %d", w.Code)
+	}
+
+	// -------------------------------------------------------------------------
+
+	time.Sleep(window + 50*time.Millisecond)
+
+	if w := basicRequest(app, usr.Email.Address, "gophers"); w.Code != http.StatusNoContent {
+		t.Errorf("Should authenticate once the window passed : %d : %s", w.Code, w.Body)
+	}
+}
+
+func newBasicApp(t *testing.T, maxFailures int, window time.Duration) (*web.App, userbus.User) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	h, err := hasher.New(hasher.Config{Algorithm: hasher.AlgBcrypt, BcryptCost: 4})
+	if err != nil {
+		t.Fatalf("Should be able to construct the hasher : %s", err)
+	}
+
+	userBus := userbus.NewBusiness(log, nil, usermem.NewStore(), userbus.WithHasher(h))
+
+	usr, err := userBus.Create(context.Background(), userbus.NewUser{
+		Name:     userbus.MustParseName("Bill Kennedy"),
+		Email:    mail.Address{Address: "bill@example.com"},
+		Roles:    []userbus.Role{userbus.Roles.User},
+		Password: "gophers",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a user : %s", err)
+	}
+
+	ath, err := auth.New(auth.Config{Log: log, Issuer: "test"})
+	if err != nil {
+		t.Fatalf("Should be able to construct auth : %s", err)
+	}
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return nil, nil
+	}
+
+	app := web.NewApp(webLog, nil, mid.Errors(log))
+	app.HandlerFunc(http.MethodGet, "", "/test", handler, mid.Basic(userBus, ath, maxFailures, window))
+
+	return app, usr
+}
+
+func basicRequest(app *web.App, email string, password string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/test", nil)
+	r.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(email+":"+password)))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	return w
+}
@@ -0,0 +1,22 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/tracer"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Baggage extracts the OpenTelemetry baggage for the specified keys from the
+// request so the values are available to handlers and are propagated to
+// downstream services.
+func Baggage(keys ...string) web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		ctx = tracer.ExtractBaggage(ctx, r.Header, keys)
+		return next(ctx)
+	}
+
+	return addMidFunc(midFunc)
+}
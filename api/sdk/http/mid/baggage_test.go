@@ -0,0 +1,64 @@
+package mid_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/tracer"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_Baggage(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	var tenantID, other string
+
+	// The downstream service only accepts the tenant id from the baggage.
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		tenantID = tracer.GetBaggage(ctx, tracer.BaggageTenantID)
+		other = tracer.GetBaggage(ctx, "other")
+		return authclient.AuthenticateResp{}, nil
+	}
+
+	app := web.NewApp(webLog, nil, mid.Baggage(tracer.BaggageTenantID))
+	app.HandlerFunc(http.MethodGet, "v1", "/auth/authenticate", handler)
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	// The upstream service sets the baggage and calls the downstream service.
+
+	ctx, err := tracer.SetBaggage(context.Background(), tracer.BaggageTenantID, "acme")
+	if err != nil {
+		t.Fatalf("Should be able to set the tenant id : %s", err)
+	}
+
+	ctx, err = tracer.SetBaggage(ctx, "other", "value")
+	if err != nil {
+		t.Fatalf("Should be able to set the other value : %s", err)
+	}
+
+	cln := authclient.New(log, srv.URL)
+
+	if _, err := cln.Authenticate(ctx, "Bearer token"); err != nil {
+		t.Fatalf("Should be able to call the downstream service : %s", err)
+	}
+
+	if tenantID != "acme" {
+		t.Errorf("Should read the tenant id downstream : %q", tenantID)
+	}
+
+	if other != "" {
+		t.Errorf("Should not accept baggage that is not allowed : %q", other)
+	}
+}
@@ -0,0 +1,20 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// ClientCert authenticates the caller with the client certificate verified
+// during the TLS handshake. The server must be configured to verify client
+// certificates, see web.MutualTLS.
+func ClientCert() web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		return mid.ClientCert(ctx, r.TLS, next)
+	}
+
+	return addMidFunc(midFunc)
+}
@@ -0,0 +1,173 @@
+package mid_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	stdlog "log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	appmid "github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_ClientCert(t *testing.T) {
+	t.Parallel()
+
+	ca, caKey := newCertificate(t, pkix.Name{CommonName: "test ca"}, nil, nil)
+	rogue, rogueKey := newCertificate(t, pkix.Name{CommonName: "rogue ca"}, nil, nil)
+
+	sales := pkix.Name{CommonName: "sales", OrganizationalUnit: []string{"ADMIN"}}
+
+	valid, validKey := newCertificate(t, sales, ca, caKey)
+	invalid, invalidKey := newCertificate(t, sales, rogue, rogueKey)
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	var got pkix.Name
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		subject, err := appmid.GetClientCert(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		claims := appmid.GetClaims(ctx)
+		if claims.Subject != subject.CommonName || len(claims.Roles) != 1 || claims.Roles[0] != "ADMIN" {
+			t.Errorf("Should map the certificate subject to the claims : %+v", claims)
+		}
+
+		got = subject
+		return nil, nil
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil, mid.Errors(log))
+	app.HandlerFunc(http.MethodGet, "", "/test", handler, mid.ClientCert())
+
+	tlsCfg, err := web.MutualTLS(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}))
+	if err != nil {
+		t.Fatalf("Should be able to construct the tls config : %s", err)
+	}
+
+	srv := httptest.NewUnstartedServer(app)
+	srv.TLS = tlsCfg
+	srv.Config.ErrorLog = stdlog.New(io.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	// -------------------------------------------------------------------------
+
+	client := clientWithCert(srv, tls.Certificate{Certificate: [][]byte{valid.Raw}, PrivateKey: validKey})
+
+	resp, err := client.Get(srv.URL + "/test")
+	if err != nil {
+		t.Fatalf("Should be able to make the request with a valid certificate : %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Should accept a valid certificate : %d", resp.StatusCode)
+	}
+
+	if got.CommonName != "sales" {
+		t.Errorf("Should put the certificate subject in the context : %+v", got)
+	}
+
+	// -------------------------------------------------------------------------
+
+	table := []struct {
+		name   string
+		client *http.Client
+	}{
+		{name: "missing", client: clientWithCert(srv)},
+		{name: "invalid", client: clientWithCert(srv, tls.Certificate{Certificate: [][]byte{invalid.Raw}, PrivateKey: invalidKey})},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.client.Get(srv.URL + "/test")
+			if err == nil {
+				resp.Body.Close()
+				t.Fatalf("Should reject the connection : %d", resp.StatusCode)
+			}
+		})
+	}
+
+	// -------------------------------------------------------------------------
+
+	// The middleware rejects requests that didn't verify a certificate,
+	// such as those received over plain http.
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Should reject a request without a certificate : %d : %s", w.Code, w.Body)
+	}
+}
+
+// newCertificate creates a certificate for the subject signed by the parent.
+// When parent is nil, the certificate is a self-signed certificate authority.
+func newCertificate(t *testing.T, subject pkix.Name, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Should be able to generate a key : %s", err)
+	}
+
+	tmpl := x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+		parent = &tmpl
+		parentKey = key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("Should be able to create the certificate : %s", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Should be able to parse the certificate : %s", err)
+	}
+
+	return cert, key
+}
+
+func clientWithCert(srv *httptest.Server, certs ...tls.Certificate) *http.Client {
+	client := srv.Client()
+
+	transport := client.Transport.(*http.Transport).Clone()
+
+	// Always present the certificate, even when it isn't signed by an
+	// authority the server asked for.
+	transport.TLSClientConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		if len(certs) == 0 {
+			return &tls.Certificate{}, nil
+		}
+		return &certs[0], nil
+	}
+	client.Transport = transport
+
+	return client
+}
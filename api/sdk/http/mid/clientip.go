@@ -0,0 +1,19 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// ClientIP executes the client ip middleware functionality. Without trusted
+// proxies, the address the request came from is the client.
+func ClientIP(tp *mid.TrustedProxies) web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		return mid.ClientIP(ctx, tp, r.RemoteAddr, r.Header.Values("Forwarded"), r.Header.Values("X-Forwarded-For"), next)
+	}
+
+	return addMidFunc(midFunc)
+}
@@ -0,0 +1,148 @@
+package mid_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	appmid "github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_ClientIP(t *testing.T) {
+	t.Parallel()
+
+	tp, err := appmid.ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1", "2001:db8::/32"})
+	if err != nil {
+		t.Fatalf("Should be able to parse the trusted proxies : %s", err)
+	}
+
+	table := []struct {
+		name         string
+		proxies      *appmid.TrustedProxies
+		remoteAddr   string
+		forwarded    []string
+		forwardedFor []string
+		exp          string
+	}{
+		{
+			name:       "direct",
+			proxies:    tp,
+			remoteAddr: "203.0.113.7:5000",
+			exp:        "203.0.113.7",
+		},
+		{
+			name:         "direct-spoofed",
+			proxies:      tp,
+			remoteAddr:   "203.0.113.7:5000",
+			forwardedFor: []string{"198.51.100.1"},
+			forwarded:    []string{"for=198.51.100.1"},
+			exp:          "203.0.113.7",
+		},
+		{
+			name:         "no-trusted-proxies",
+			remoteAddr:   "10.0.0.1:5000",
+			forwardedFor: []string{"198.51.100.1"},
+			exp:          "10.0.0.1",
+		},
+		{
+			name:         "single-proxy",
+			proxies:      tp,
+			remoteAddr:   "10.0.0.1:5000",
+			forwardedFor: []string{"203.0.113.7"},
+			exp:          "203.0.113.7",
+		},
+		{
+			name:       "single-proxy-no-header",
+			proxies:    tp,
+			remoteAddr: "10.0.0.1:5000",
+			exp:        "10.0.0.1",
+		},
+		{
+			name:         "multi-hop",
+			proxies:      tp,
+			remoteAddr:   "10.0.0.1:5000",
+			forwardedFor: []string{"203.0.113.7, 192.168.1.1", "10.1.2.3"},
+			exp:          "203.0.113.7",
+		},
+		{
+			name:         "multi-hop-spoofed",
+			proxies:      tp,
+			remoteAddr:   "10.0.0.1:5000",
+			forwardedFor: []string{"198.51.100.1, 203.0.113.7, 10.1.2.3"},
+			exp:          "203.0.113.7",
+		},
+		{
+			name:         "multi-hop-untrusted-middle",
+			proxies:      tp,
+			remoteAddr:   "10.0.0.1:5000",
+			forwardedFor: []string{"203.0.113.7, 198.51.100.1"},
+			exp:          "198.51.100.1",
+		},
+		{
+			name:         "garbage-hop",
+			proxies:      tp,
+			remoteAddr:   "10.0.0.1:5000",
+			forwardedFor: []string{"203.0.113.7, unknown, 10.1.2.3"},
+			exp:          "10.1.2.3",
+		},
+		{
+			name:         "forwarded",
+			proxies:      tp,
+			remoteAddr:   "10.0.0.1:5000",
+			forwarded:    []string{`for=203.0.113.7;proto=https, for="10.1.2.3:8080";by=10.0.0.1`},
+			forwardedFor: []string{"198.51.100.1"},
+			exp:          "203.0.113.7",
+		},
+		{
+			name:       "forwarded-ipv6",
+			proxies:    tp,
+			remoteAddr: "[2001:db8::1]:5000",
+			forwarded:  []string{`For="[2001:db9::17]:4711"`},
+			exp:        "2001:db9::17",
+		},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			var got string
+
+			handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+				got = appmid.GetClientIP(ctx)
+				return nil, nil
+			}
+
+			app := web.NewApp(func(context.Context, string, ...any) {}, nil, mid.ClientIP(tt.proxies))
+			app.HandlerFunc(http.MethodGet, "", "/test", handler)
+
+			r := httptest.NewRequest(http.MethodGet, "/test", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.forwarded {
+				r.Header.Add("Forwarded", v)
+			}
+			for _, v := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+
+			app.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.exp {
+				t.Errorf("Should resolve the client ip, got %q, exp %q", got, tt.exp)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
+
+func Test_ParseTrustedProxies(t *testing.T) {
+	t.Parallel()
+
+	for _, cidr := range []string{"10.0.0.0/33", "not-an-ip"} {
+		if _, err := appmid.ParseTrustedProxies([]string{cidr}); err == nil {
+			t.Errorf("Should not accept %q", cidr)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// ConcurrencyLimit executes the concurrency limit middleware functionality.
+func ConcurrencyLimit(max int, retryAfter time.Duration) web.MidFunc {
+	l := mid.NewLimiter(max, retryAfter)
+
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		return mid.ConcurrencyLimit(ctx, l, next)
+	}
+
+	return addMidFunc(midFunc)
+}
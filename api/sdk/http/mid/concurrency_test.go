@@ -0,0 +1,118 @@
+package mid_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_ConcurrencyLimit(t *testing.T) {
+	t.Parallel()
+
+	const limit = 2
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	entered := make(chan struct{}, limit)
+	release := make(chan struct{})
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		entered <- struct{}{}
+		<-release
+		return nil, nil
+	}
+
+	app := web.NewApp(webLog, nil, mid.Errors(log), mid.ConcurrencyLimit(limit, 2*time.Second))
+	app.HandlerFunc(http.MethodGet, "", "/test", handler)
+
+	var wg sync.WaitGroup
+	codes := make([]int, limit)
+
+	for i := range limit {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+			codes[i] = w.Code
+		}()
+	}
+
+	for range limit {
+		<-entered
+	}
+
+	// All the slots are taken so the next requests must be rejected.
+
+	for range 3 {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Should get a 503 over the limit : %d", w.Code)
+		}
+
+		if got := w.Header().Get("Retry-After"); got != "2" {
+			t.Errorf("Should get a Retry-After header : %q", got)
+		}
+	}
+
+	close(release)
+	wg.Wait()
+
+	for _, code := range codes {
+		if code != http.StatusNoContent {
+			t.Errorf("Should process the requests under the limit : %d", code)
+		}
+	}
+
+	// The slots are free again.
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Should process the request after the slots are released : %d", w.Code)
+	}
+}
+
+func Test_ConcurrencyLimitPanic(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	app := web.NewApp(webLog, nil, mid.Errors(log), mid.ConcurrencyLimit(1, time.Second), mid.Panics())
+
+	app.HandlerFunc(http.MethodGet, "", "/panic", func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		panic("boom")
+	})
+
+	app.HandlerFunc(http.MethodGet, "", "/test", func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return nil, nil
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Should get a 500 from the panic : %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Should release the slot after a panic : %d", w.Code)
+	}
+}
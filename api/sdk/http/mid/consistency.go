@@ -0,0 +1,27 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// ConsistencyHeader is the header used to return a consistency token on
+// writes and receive it on reads.
+const ConsistencyHeader = "X-Consistency-Token"
+
+// Consistency gives clients read-your-writes consistency when reads are
+// served by replicas.
+func Consistency() web.MidFunc {
+	setToken := func(ctx context.Context, token string) {
+		web.SetHeader(ctx, ConsistencyHeader, token)
+	}
+
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		return mid.Consistency(ctx, r.Method, r.Header.Get(ConsistencyHeader), setToken, next)
+	}
+
+	return addMidFunc(midFunc)
+}
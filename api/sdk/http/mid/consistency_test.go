@@ -0,0 +1,86 @@
+package mid_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/jmoiron/sqlx"
+)
+
+func Test_Consistency(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	primary := &sqlx.DB{}
+	replica := &sqlx.DB{}
+
+	var usedPrimary bool
+
+	write := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return nil, nil
+	}
+
+	read := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		usedPrimary = sqldb.Reader(ctx, primary, replica, time.Second) == primary
+		return nil, nil
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil, mid.Errors(log), mid.Consistency())
+	app.HandlerFunc(http.MethodPost, "v1", "/users", write)
+	app.HandlerFunc(http.MethodGet, "v1", "/users", read)
+	app.HandlerFunc(http.MethodPut, "v1", "/users", read)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/users", nil))
+
+	token := w.Header().Get(mid.ConsistencyHeader)
+	if token == "" {
+		t.Fatalf("Should get a consistency token for a write")
+	}
+
+	// -------------------------------------------------------------------------
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+	r.Header.Set(mid.ConsistencyHeader, token)
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if !usedPrimary {
+		t.Errorf("Should read from the primary with a fresh write token")
+	}
+
+	if w.Header().Get(mid.ConsistencyHeader) != "" {
+		t.Errorf("Should not get a consistency token for a read")
+	}
+
+	// -------------------------------------------------------------------------
+
+	for _, token := range []string{"", "garbage"} {
+		r := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+		r.Header.Set(mid.ConsistencyHeader, token)
+
+		app.ServeHTTP(httptest.NewRecorder(), r)
+
+		if usedPrimary {
+			t.Errorf("Should read from the replica with token %q", token)
+		}
+	}
+
+	// -------------------------------------------------------------------------
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/v1/users", nil))
+
+	if !usedPrimary {
+		t.Errorf("Should read from the primary for a write without a token")
+	}
+}
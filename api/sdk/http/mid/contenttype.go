@@ -0,0 +1,25 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// ContentType executes the content type middleware functionality. It's
+// added to the routes that require a specific content type, like the JSON
+// endpoints that decode a request body.
+func ContentType(mediaTypes ...string) web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		return mid.ContentType(ctx, r.Header.Get("Content-Type"), mediaTypes, next)
+	}
+
+	return addMidFunc(midFunc)
+}
+
+// RequireJSON rejects requests that don't have a JSON content type.
+func RequireJSON() web.MidFunc {
+	return ContentType("application/json")
+}
@@ -0,0 +1,61 @@
+package mid_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_ContentType(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return nil, nil
+	}
+
+	app := web.NewApp(webLog, nil, mid.Errors(log))
+	app.HandlerFunc(http.MethodPost, "", "/strict", handler, mid.RequireJSON())
+	app.HandlerFunc(http.MethodPost, "", "/lenient", handler)
+
+	table := []struct {
+		name        string
+		path        string
+		contentType string
+		status      int
+	}{
+		{name: "json", path: "/strict", contentType: "application/json", status: http.StatusNoContent},
+		{name: "charset", path: "/strict", contentType: "application/json; charset=UTF-8", status: http.StatusNoContent},
+		{name: "wrong-type", path: "/strict", contentType: "text/plain", status: http.StatusUnsupportedMediaType},
+		{name: "wrong-charset", path: "/strict", contentType: "application/json; charset=latin1", status: http.StatusUnsupportedMediaType},
+		{name: "missing", path: "/strict", contentType: "", status: http.StatusUnsupportedMediaType},
+		{name: "lenient", path: "/lenient", contentType: "", status: http.StatusNoContent},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(`{}`))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Errorf("Should get status %d : got %d : %s", tt.status, w.Code, w.Body.String())
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
@@ -0,0 +1,50 @@
+package mid
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// DebugLog executes the debug log middleware functionality.
+func DebugLog(log *logger.Logger, cfg mid.DebugLogConfig) web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		// Don't read the body when the bodies won't be logged.
+		if log.Level() > logger.LevelDebug {
+			return next(ctx)
+		}
+
+		path := r.URL.Path
+		if r.URL.RawQuery != "" {
+			path = path + "?" + r.URL.RawQuery
+		}
+
+		// Only read enough of the body to know if it's over the limit, then
+		// put back what was read so the handler can still decode all of it.
+		var body []byte
+		if r.Body != nil {
+			var err error
+			body, err = io.ReadAll(io.LimitReader(r.Body, int64(cfg.Limit())+1))
+			if err != nil {
+				return nil, err
+			}
+
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{
+				Reader: io.MultiReader(bytes.NewReader(body), r.Body),
+				Closer: r.Body,
+			}
+		}
+
+		return mid.DebugLog(ctx, log, cfg, path, r.Method, body, web.CaptureResponse, next)
+	}
+
+	return addMidFunc(midFunc)
+}
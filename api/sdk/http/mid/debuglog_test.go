@@ -0,0 +1,150 @@
+package mid_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	appmid "github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type response struct {
+	Name  string `json:"name"`
+	Token string `json:"token"`
+}
+
+func (r response) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(r)
+	return data, "application/json", err
+}
+
+func Test_DebugLog(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelDebug, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	reqBody := `{"name":"Bill","password":"gophers","nested":{"Password":"gophers"}}`
+
+	var handlerBody string
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		handlerBody = string(data)
+
+		return response{Name: "Bill", Token: "secret-token"}, nil
+	}
+
+	cfg := appmid.DebugLogConfig{
+		Redact: []string{"password", "token"},
+	}
+
+	app := web.NewApp(webLog, nil, mid.DebugLog(log, cfg))
+	app.HandlerFunc(http.MethodPost, "v1", "/users", handler)
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/users", strings.NewReader(reqBody))
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if handlerBody != reqBody {
+		t.Errorf("Should read the full body in the handler : %s", handlerBody)
+	}
+
+	out := buf.String()
+
+	if strings.Contains(out, "gophers") || strings.Contains(out, "secret-token") {
+		t.Errorf("Should not log redacted fields : %s", out)
+	}
+
+	if strings.Count(out, "***") != 3 {
+		t.Errorf("Should mask the redacted fields : %s", out)
+	}
+
+	if !strings.Contains(out, "Bill") {
+		t.Errorf("Should log the fields that are not redacted : %s", out)
+	}
+}
+
+func Test_DebugLogMaxSize(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelDebug, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	reqBody := `{"name":"` + strings.Repeat("a", 100) + `"}`
+
+	var handlerBody string
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		handlerBody = string(data)
+
+		return nil, nil
+	}
+
+	cfg := appmid.DebugLogConfig{
+		MaxSize: 10,
+	}
+
+	app := web.NewApp(webLog, nil, mid.DebugLog(log, cfg))
+	app.HandlerFunc(http.MethodPost, "v1", "/users", handler)
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/users", strings.NewReader(reqBody))
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if handlerBody != reqBody {
+		t.Errorf("Should read the full body in the handler : %s", handlerBody)
+	}
+
+	out := buf.String()
+
+	if strings.Contains(out, "aaaa") {
+		t.Errorf("Should not log a body over the max size : %s", out)
+	}
+
+	if !strings.Contains(out, "body exceeds 10 bytes") {
+		t.Errorf("Should note the body was over the max size : %s", out)
+	}
+}
+
+func Test_DebugLogLevel(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return response{Name: "Bill"}, nil
+	}
+
+	app := web.NewApp(webLog, nil, mid.DebugLog(log, appmid.DebugLogConfig{}))
+	app.HandlerFunc(http.MethodPost, "v1", "/users", handler)
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/users", strings.NewReader(`{"name":"Bill"}`))
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should be able to call the handler : %d", w.Code)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("Should not log bodies above the debug level : %s", buf.String())
+	}
+}
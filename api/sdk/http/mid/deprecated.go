@@ -0,0 +1,21 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Deprecated executes the deprecated endpoint middleware functionality. The
+// usage is counted against the route, so the middleware must run after the
+// metrics middleware.
+func Deprecated(log *logger.Logger, route string, dep mid.Deprecation) web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		return mid.Deprecated(ctx, log, dep, route, web.SetHeader, next)
+	}
+
+	return addMidFunc(midFunc)
+}
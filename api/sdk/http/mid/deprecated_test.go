@@ -0,0 +1,98 @@
+package mid_test
+
+import (
+	"context"
+	"expvar"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	appmid "github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_Deprecated(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return nil, nil
+	}
+
+	dep := appmid.Deprecation{
+		Date:      time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:    time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC),
+		Successor: "/v2/widgets",
+	}
+
+	const route = "GET /v1/widgets"
+
+	app := web.NewApp(webLog, nil, mid.Metrics())
+	app.HandlerFunc(http.MethodGet, "v1", "/widgets", handler, mid.Deprecated(log, route, dep))
+	app.HandlerFunc(http.MethodGet, "v2", "/widgets", handler)
+
+	call := func(path string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	calls := func() int64 {
+		n, ok := expvar.Get("deprecated").(*expvar.Map).Get(route).(*expvar.Int)
+		if !ok {
+			return 0
+		}
+		return n.Value()
+	}
+
+	// -------------------------------------------------------------------------
+	// Deprecated
+
+	before := calls()
+
+	for range 2 {
+		w := call("/v1/widgets")
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Should still serve the deprecated endpoint : %d : %s", w.Code, w.Body)
+		}
+
+		if got, exp := w.Header().Get(appmid.HeaderDeprecation), "@1767225600"; got != exp {
+			t.Errorf("Should report when the endpoint was deprecated, got %q, exp %q", got, exp)
+		}
+
+		if got, exp := w.Header().Get(appmid.HeaderSunset), "Wed, 01 Jul 2026 00:00:00 GMT"; got != exp {
+			t.Errorf("Should report when the endpoint will be removed, got %q, exp %q", got, exp)
+		}
+
+		if got, exp := w.Header().Get(appmid.HeaderLink), `</v2/widgets>; rel="successor-version"`; got != exp {
+			t.Errorf("Should link to the successor, got %q, exp %q", got, exp)
+		}
+	}
+
+	if got := calls() - before; got != 2 {
+		t.Errorf("Should count the calls to the deprecated endpoint, got %d, exp 2", got)
+	}
+
+	// -------------------------------------------------------------------------
+	// Successor
+
+	w := call("/v2/widgets")
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Should serve the successor : %d : %s", w.Code, w.Body)
+	}
+
+	for _, key := range []string{appmid.HeaderDeprecation, appmid.HeaderSunset, appmid.HeaderLink} {
+		if got := w.Header().Get(key); got != "" {
+			t.Errorf("Should not mark the successor with %s : %q", key, got)
+		}
+	}
+}
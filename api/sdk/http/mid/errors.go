@@ -2,8 +2,10 @@ package mid
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
+	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/app/sdk/mid"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
@@ -12,8 +14,25 @@ import (
 // Errors executes the errors middleware functionality.
 func Errors(log *logger.Logger) web.MidFunc {
 	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
-		return mid.Errors(ctx, log, next)
+		return mid.Errors(ctx, log, func(ctx context.Context) (mid.Encoder, error) {
+			resp, err := next(ctx)
+			return resp, toAppError(err)
+		})
 	}
 
 	return addMidFunc(midFunc)
 }
+
+// toAppError converts errors from the web package that have a specific
+// http meaning into app errors.
+func toAppError(err error) error {
+	switch {
+	case errors.Is(err, web.ErrFileTooLarge):
+		return errs.New(errs.PayloadTooLarge, err)
+
+	case errors.Is(err, web.ErrUnsupportedType):
+		return errs.New(errs.UnsupportedMediaType, err)
+	}
+
+	return err
+}
@@ -0,0 +1,204 @@
+package mid_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/go-cmp/cmp"
+)
+
+type discardStore struct{}
+
+func (discardStore) Store(ctx context.Context, filename string, contentType string, r io.Reader) (string, error) {
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return "", err
+	}
+	return "discard/" + filename, nil
+}
+
+func (discardStore) Delete(ctx context.Context, location string) error {
+	return nil
+}
+
+func Test_ErrorsUpload(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	cfg := web.UploadConfig{
+		Storer:       discardStore{},
+		MaxSize:      1024,
+		AllowedTypes: []string{"image/png"},
+	}
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		if _, err := web.DecodeFiles(ctx, r, cfg); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	app := web.NewApp(webLog, nil, mid.Errors(log))
+	app.HandlerFunc(http.MethodPost, "", "/upload", handler)
+
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 100)...)
+
+	table := []struct {
+		name   string
+		data   []byte
+		status int
+	}{
+		{name: "valid", data: png, status: http.StatusNoContent},
+		{name: "too-large", data: append(png, bytes.Repeat([]byte{0}, 2048)...), status: http.StatusRequestEntityTooLarge},
+		{name: "unsupported", data: []byte("plain text"), status: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			var body bytes.Buffer
+			mw := multipart.NewWriter(&body)
+
+			fw, err := mw.CreateFormFile("avatar", "me.png")
+			if err != nil {
+				t.Fatalf("Should be able to create the file : %s", err)
+			}
+			fw.Write(tt.data)
+			mw.Close()
+
+			r := httptest.NewRequest(http.MethodPost, "/upload", &body)
+			r.Header.Set("Content-Type", mw.FormDataContentType())
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Errorf("Should get the expected status : got[%d] exp[%d] body[%s]", w.Code, tt.status, w.Body.String())
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
+
+func Test_ErrorsParam(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		if _, err := web.ParamUUID(r, "user_id"); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	app := web.NewApp(webLog, nil, mid.Errors(log))
+	app.HandlerFunc(http.MethodGet, "", "/users/{user_id}", handler)
+
+	table := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{name: "valid", id: "45b5fbd3-755f-4379-8f07-a58d4a30fa2f", status: http.StatusNoContent},
+		{name: "malformed", id: "not-a-uuid", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/users/"+tt.id, nil)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("Should get the expected status : got[%d] exp[%d] body[%s]", w.Code, tt.status, w.Body.String())
+			}
+
+			if tt.status == http.StatusBadRequest && !strings.Contains(w.Body.String(), `\"user_id\"`) {
+				t.Errorf("Should name the param in the response : %s", w.Body.String())
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
+
+func Test_ErrorsConflict(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		err := &sqldb.ConflictError{Table: "users", Constraint: "users_email_key", Fields: []string{"email"}}
+		return nil, fmt.Errorf("create: %w", err)
+	}
+
+	app := web.NewApp(webLog, nil, mid.Errors(log))
+	app.HandlerFunc(http.MethodPost, "", "/users", handler)
+
+	r := httptest.NewRequest(http.MethodPost, "/users", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Should get a conflict : got[%d] exp[%d]", w.Code, http.StatusConflict)
+	}
+
+	var got errs.Error
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Should be able to decode the error : %s", err)
+	}
+
+	exp := []any{map[string]any{"field": "email", "error": "email already exists"}}
+	if diff := cmp.Diff(got.Details, exp); diff != "" {
+		t.Errorf("Should name the conflicting field : %s", diff)
+	}
+}
+
+func Test_ErrorsConflictUnregistered(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		err := &sqldb.ConflictError{Table: "users", Constraint: "users_secret_key"}
+		return nil, fmt.Errorf("create: %w", err)
+	}
+
+	app := web.NewApp(webLog, nil, mid.Errors(log))
+	app.HandlerFunc(http.MethodPost, "", "/users", handler)
+
+	r := httptest.NewRequest(http.MethodPost, "/users", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Should get a conflict : got[%d] exp[%d]", w.Code, http.StatusConflict)
+	}
+
+	if strings.Contains(w.Body.String(), "users_secret_key") {
+		t.Errorf("Should not send the constraint to the client : %s", w.Body)
+	}
+
+	if !strings.Contains(buf.String(), "users_secret_key") {
+		t.Errorf("Should log the constraint on the server : %s", buf.String())
+	}
+}
@@ -0,0 +1,19 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/sdk/featureflag"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// FeatureFlags executes the feature flag middleware functionality.
+func FeatureFlags(flags *featureflag.Flags) web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		return mid.FeatureFlags(ctx, flags, next)
+	}
+
+	return addMidFunc(midFunc)
+}
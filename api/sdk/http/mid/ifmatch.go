@@ -0,0 +1,19 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// IfMatch executes the conditional update middleware functionality. It must
+// come after the middleware that loads the resource into the context.
+func IfMatch(current mid.ETagFunc) web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		return mid.IfMatch(ctx, r.Header.Get("If-Match"), current, next)
+	}
+
+	return addMidFunc(midFunc)
+}
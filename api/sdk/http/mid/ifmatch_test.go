@@ -0,0 +1,64 @@
+package mid_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/app/sdk/etag"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_IfMatch(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	current := etag.New("user", "2")
+	currentFn := func(ctx context.Context) (string, error) {
+		return current, nil
+	}
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return nil, nil
+	}
+
+	app := web.NewApp(webLog, nil, mid.Errors(log))
+	app.HandlerFunc(http.MethodPut, "", "/test", handler, mid.IfMatch(currentFn))
+
+	table := []struct {
+		name    string
+		ifMatch string
+		status  int
+	}{
+		{name: "match", ifMatch: current, status: http.StatusNoContent},
+		{name: "list", ifMatch: etag.New("user", "1") + ", " + current, status: http.StatusNoContent},
+		{name: "any", ifMatch: "*", status: http.StatusNoContent},
+		{name: "stale", ifMatch: etag.New("user", "1"), status: http.StatusPreconditionFailed},
+		{name: "weak", ifMatch: "W/" + current, status: http.StatusPreconditionFailed},
+		{name: "missing", ifMatch: "", status: http.StatusPreconditionRequired},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/test", nil)
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Errorf("Should get status %d : got %d : %s", tt.status, w.Code, w.Body.String())
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
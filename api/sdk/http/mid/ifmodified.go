@@ -0,0 +1,25 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// IfModifiedSince executes the conditional read middleware functionality. It
+// must come after the middleware that loads the resource into the context.
+// The header is ignored when the request has an If-None-Match header.
+func IfModifiedSince(current mid.LastModifiedFunc) web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		ifModifiedSince := r.Header.Get("If-Modified-Since")
+		if r.Header.Get("If-None-Match") != "" {
+			ifModifiedSince = ""
+		}
+
+		return mid.IfModifiedSince(ctx, ifModifiedSince, current, next)
+	}
+
+	return addMidFunc(midFunc)
+}
@@ -0,0 +1,84 @@
+package mid_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type lastModifiedResp struct{}
+
+func (lastModifiedResp) Encode() ([]byte, string, error) {
+	return []byte(`{"status":"ok"}`), "application/json", nil
+}
+
+func Test_IfModifiedSince(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	lastModified := time.Date(2024, time.March, 10, 12, 30, 15, 500_000_000, time.UTC)
+	currentFn := func(ctx context.Context) (time.Time, error) {
+		return lastModified, nil
+	}
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return lastModifiedResp{}, nil
+	}
+
+	app := web.NewApp(webLog, nil, mid.Errors(log))
+	app.HandlerFunc(http.MethodGet, "", "/test", handler, mid.IfModifiedSince(currentFn))
+
+	table := []struct {
+		name        string
+		since       string
+		ifNoneMatch string
+		status      int
+	}{
+		{name: "older", since: lastModified.Add(-time.Second).Format(http.TimeFormat), status: http.StatusOK},
+		{name: "equal", since: lastModified.Format(http.TimeFormat), status: http.StatusNotModified},
+		{name: "newer", since: lastModified.Add(time.Hour).Format(http.TimeFormat), status: http.StatusNotModified},
+		{name: "invalid", since: "yesterday", status: http.StatusOK},
+		{name: "missing", since: "", status: http.StatusOK},
+		{name: "ifnonematch", since: lastModified.Format(http.TimeFormat), ifNoneMatch: `"abc"`, status: http.StatusOK},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.since != "" {
+				r.Header.Set("If-Modified-Since", tt.since)
+			}
+			if tt.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("Should get status %d : got %d : %s", tt.status, w.Code, w.Body.String())
+			}
+
+			if tt.status == http.StatusNotModified {
+				if w.Body.Len() != 0 {
+					t.Errorf("Should get an empty body : got %q", w.Body.String())
+				}
+
+				if got, exp := w.Header().Get("Last-Modified"), lastModified.Format(http.TimeFormat); got != exp {
+					t.Errorf("Should get Last-Modified %q : got %q", exp, got)
+				}
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
@@ -0,0 +1,134 @@
+package mid_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ardanlabs/service/api/sdk/http/apitest"
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/tracer"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) lines(t *testing.T) []map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	defer b.buf.Reset()
+
+	if b.buf.Len() == 0 {
+		return nil
+	}
+
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Should be able to decode the log line : %s : %s", err, line)
+		}
+		lines = append(lines, entry)
+	}
+
+	return lines
+}
+
+func Test_LoggerFields(t *testing.T) {
+	t.Parallel()
+
+	var buf syncBuffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	ath, err := auth.New(auth.Config{Log: log, KeyLookup: &apitest.KeyStore{}, Issuer: "test"})
+	if err != nil {
+		t.Fatalf("Should be able to construct auth : %s", err)
+	}
+
+	userID := uuid.NewString()
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		log.Info(ctx, "handler")
+		return nil, nil
+	}
+
+	app := web.NewApp(webLog, nil, mid.Logger(log), mid.Errors(log))
+	app.HandlerFunc(http.MethodGet, "", "/private", handler, mid.Bearer(ath))
+	app.HandlerFunc(http.MethodGet, "", "/public", handler)
+
+	// -------------------------------------------------------------------------
+	// Authenticated
+
+	r := httptest.NewRequest(http.MethodGet, "/private", nil)
+	r.Header.Set("Authorization", "Bearer "+bearerToken(t, ath, userID, "acme"))
+	r.Header.Set("baggage", tracer.BaggageTenantID+"=globex")
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Should authenticate : %d : %s", w.Code, w.Body)
+	}
+
+	lines := buf.lines(t)
+	if len(lines) != 3 {
+		t.Fatalf("Should log the start, the handler and the completion : %d", len(lines))
+	}
+
+	for _, entry := range lines[1:] {
+		if entry["user_id"] != userID {
+			t.Errorf("Should log the user on %q : %v", entry["msg"], entry["user_id"])
+		}
+
+		if entry["tenant_id"] != "acme" {
+			t.Errorf("Should log the tenant of the claims on %q : %v", entry["msg"], entry["tenant_id"])
+		}
+	}
+
+	// -------------------------------------------------------------------------
+	// Anonymous
+
+	r = httptest.NewRequest(http.MethodGet, "/public", nil)
+	r.Header.Set("baggage", tracer.BaggageTenantID+"=acme")
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Should serve the anonymous request : %d : %s", w.Code, w.Body)
+	}
+
+	lines = buf.lines(t)
+	if len(lines) != 3 {
+		t.Fatalf("Should log the start, the handler and the completion : %d", len(lines))
+	}
+
+	for _, entry := range lines {
+		if _, exists := entry["user_id"]; exists {
+			t.Errorf("Should not log a user on %q", entry["msg"])
+		}
+
+		if _, exists := entry["tenant_id"]; exists {
+			t.Errorf("Should not log a tenant on %q", entry["msg"])
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Maintenance executes the maintenance mode middleware functionality.
+func Maintenance(m *mid.MaintenanceMode) web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		return mid.Maintenance(ctx, m, r.Method, r.URL.Path, next)
+	}
+
+	return addMidFunc(midFunc)
+}
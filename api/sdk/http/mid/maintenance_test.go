@@ -0,0 +1,86 @@
+package mid_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	appmid "github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_Maintenance(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return nil, nil
+	}
+
+	m := appmid.NewMaintenanceMode(false, false, "/v1/admin/")
+
+	app := web.NewApp(webLog, nil, mid.Errors(log), mid.Maintenance(m))
+	app.HandlerFunc(http.MethodGet, "v1", "/users", handler)
+	app.HandlerFunc(http.MethodPost, "v1", "/users", handler)
+	app.HandlerFunc(http.MethodPut, "v1", "/admin/maintenance", handler)
+	app.HandlerFuncNoMid(http.MethodGet, "v1", "/readiness", handler)
+
+	call := func(method string, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	table := []struct {
+		name       string
+		enabled    bool
+		allowReads bool
+		method     string
+		path       string
+		status     int
+	}{
+		{name: "off-write", enabled: false, method: http.MethodPost, path: "/v1/users", status: http.StatusNoContent},
+		{name: "on-write", enabled: true, method: http.MethodPost, path: "/v1/users", status: http.StatusServiceUnavailable},
+		{name: "on-read", enabled: true, method: http.MethodGet, path: "/v1/users", status: http.StatusServiceUnavailable},
+		{name: "on-read-allowed", enabled: true, allowReads: true, method: http.MethodGet, path: "/v1/users", status: http.StatusNoContent},
+		{name: "on-write-reads-allowed", enabled: true, allowReads: true, method: http.MethodPost, path: "/v1/users", status: http.StatusServiceUnavailable},
+		{name: "on-exempt", enabled: true, method: http.MethodPut, path: "/v1/admin/maintenance", status: http.StatusNoContent},
+		{name: "on-health", enabled: true, method: http.MethodGet, path: "/v1/readiness", status: http.StatusNoContent},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			m.Set(tt.enabled, tt.allowReads)
+
+			w := call(tt.method, tt.path)
+			if w.Code != tt.status {
+				t.Fatalf("Should get the expected status : got[%d] exp[%d]", w.Code, tt.status)
+			}
+
+			if w.Code != http.StatusServiceUnavailable {
+				return
+			}
+
+			var env struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &env); err != nil {
+				t.Fatalf("Should get a JSON body : %s", err)
+			}
+
+			if env.Code != appmid.ReasonMaintenance.String() {
+				t.Errorf("Should get the maintenance reason : %s", env.Code)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
@@ -0,0 +1,22 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// OTelMetrics records request metrics with the OpenTelemetry exporter using
+// the middleware functionality.
+func OTelMetrics(exp *otel.Exporter) web.MidFunc {
+	rm := mid.NewRequestMetrics(exp)
+
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		return mid.OTelMetrics(ctx, rm, r.Method, next)
+	}
+
+	return addMidFunc(midFunc)
+}
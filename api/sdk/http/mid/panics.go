@@ -8,10 +8,13 @@ import (
 	"github.com/ardanlabs/service/foundation/web"
 )
 
-// Panics executes the panic middleware functionality.
-func Panics() web.MidFunc {
+// Panics executes the panic middleware functionality. The mappers can convert
+// known panic values into specific errors, otherwise a panic is reported as
+// an internal error. It can be added to a single handler to map the panics
+// raised by that handler.
+func Panics(mappers ...mid.PanicMapper) web.MidFunc {
 	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
-		return mid.Panics(ctx, next)
+		return mid.Panics(ctx, mappers, next)
 	}
 
 	return addMidFunc(midFunc)
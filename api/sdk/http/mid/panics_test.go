@@ -0,0 +1,91 @@
+package mid_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// validationPanic is raised by a handler to signal bad input.
+type validationPanic struct {
+	field string
+}
+
+func mapValidation(rec any) *errs.Error {
+	vp, ok := rec.(validationPanic)
+	if !ok {
+		return nil
+	}
+
+	return errs.Newf(errs.InvalidArgument, "invalid field: %s", vp.field)
+}
+
+func Test_PanicsMapper(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	unrecognized := func(rec any) *errs.Error {
+		return nil
+	}
+
+	app := web.NewApp(webLog, nil, mid.Errors(log), mid.Panics())
+
+	app.HandlerFunc(http.MethodGet, "", "/validation", func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		panic(validationPanic{field: "name"})
+	}, mid.Panics(unrecognized, mapValidation))
+
+	app.HandlerFunc(http.MethodGet, "", "/unknown", func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		panic("boom")
+	}, mid.Panics(mapValidation))
+
+	app.HandlerFunc(http.MethodGet, "", "/unmapped", func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		panic(validationPanic{field: "name"})
+	})
+
+	table := []struct {
+		name   string
+		url    string
+		status int
+		msg    string
+	}{
+		{name: "mapped", url: "/validation", status: http.StatusBadRequest, msg: "invalid field: name"},
+		{name: "unknown", url: "/unknown", status: http.StatusInternalServerError},
+		{name: "unmapped", url: "/unmapped", status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("Should get status %d : got %d : %s", tt.status, w.Code, w.Body)
+			}
+
+			if tt.msg == "" {
+				return
+			}
+
+			var got errs.Error
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("Should be able to decode the error : %s", err)
+			}
+
+			if got.Message != tt.msg {
+				t.Errorf("Should get the mapped message, got %q, exp %q", got.Message, tt.msg)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
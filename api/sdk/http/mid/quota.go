@@ -0,0 +1,26 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/quota"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Quota executes the quota middleware functionality. The tenant is read
+// from the claims, so the middleware must be added to a route after it's
+// authenticated. Nothing is enforced when there are no quotas.
+func Quota(log *logger.Logger, q *quota.Quotas) web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		if q == nil {
+			return next(ctx)
+		}
+
+		return mid.Quota(ctx, log, q, web.SetHeader, next)
+	}
+
+	return addMidFunc(midFunc)
+}
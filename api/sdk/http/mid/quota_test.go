@@ -0,0 +1,156 @@
+package mid_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/api/sdk/http/apitest"
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/app/sdk/auth"
+	appmid "github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/quota"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/tracer"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
+)
+
+func Test_Quota(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return nil, nil
+	}
+
+	ath, err := auth.New(auth.Config{Log: log, KeyLookup: &apitest.KeyStore{}, Issuer: "test"})
+	if err != nil {
+		t.Fatalf("Should be able to construct auth : %s", err)
+	}
+
+	tenants := map[string]quota.Limit{
+		"acme": {Requests: 3, Window: time.Hour},
+	}
+	anonymous := quota.Limit{Requests: 1, Window: time.Hour}
+	q := quota.New(quota.NewMemory(), quota.Limit{}, tenants, quota.WithAnonymous(anonymous))
+
+	// The baggage is trusted here to show the quota isn't taken from what
+	// the client sent.
+	app := web.NewApp(webLog, nil, mid.Baggage(tracer.BaggageTenantID), mid.ClientIP(nil), mid.Errors(log))
+	app.HandlerFunc(http.MethodGet, "v1", "/users", handler, mid.Bearer(ath), mid.Quota(log, q))
+	app.HandlerFunc(http.MethodGet, "v1", "/public", handler, mid.Quota(log, q))
+
+	call := func(tenantID string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+		r.Header.Set("Authorization", "Bearer "+bearerToken(t, ath, uuid.NewString(), tenantID))
+		r.Header.Set("baggage", tracer.BaggageTenantID+"=acme")
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	callPublic := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/public", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("baggage", tracer.BaggageTenantID+"=globex")
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	// -------------------------------------------------------------------------
+	// Within quota
+
+	for i := range 3 {
+		w := call("acme")
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Should allow request %d within the quota : %d : %s", i, w.Code, w.Body)
+		}
+
+		if got := w.Header().Get(appmid.HeaderRateLimitLimit); got != "3" {
+			t.Errorf("Should report the limit : %q", got)
+		}
+
+		if got := w.Header().Get(appmid.HeaderRateLimitRemaining); got != strconv.Itoa(2-i) {
+			t.Errorf("Should report the remaining requests, got %q, exp %d", got, 2-i)
+		}
+
+		reset, err := strconv.Atoi(w.Header().Get(appmid.HeaderRateLimitReset))
+		if err != nil || reset <= 0 || reset > 3600 {
+			t.Errorf("Should report the seconds until the window resets : %q", w.Header().Get(appmid.HeaderRateLimitReset))
+		}
+	}
+
+	// -------------------------------------------------------------------------
+	// Over quota
+
+	w := call("acme")
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Should reject the request over the quota : %d : %s", w.Code, w.Body)
+	}
+
+	if got := w.Header().Get(appmid.HeaderRateLimitRemaining); got != "0" {
+		t.Errorf("Should report no remaining requests : %q", got)
+	}
+
+	reset := w.Header().Get(appmid.HeaderRateLimitReset)
+	if n, err := strconv.Atoi(reset); err != nil || n <= 0 {
+		t.Errorf("Should report the seconds until the next request is allowed : %q", reset)
+	}
+
+	if got := w.Header().Get("Retry-After"); got != reset {
+		t.Errorf("Should tell the client when to retry, got %q, exp %q", got, reset)
+	}
+
+	// -------------------------------------------------------------------------
+	// Unlimited
+
+	w = call("globex")
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Should not limit a tenant without a limit : %d : %s", w.Code, w.Body)
+	}
+
+	if got := w.Header().Get(appmid.HeaderRateLimitLimit); got != "" {
+		t.Errorf("Should not report a quota for a tenant without a limit : %q", got)
+	}
+
+	// -------------------------------------------------------------------------
+	// Anonymous
+
+	for _, tt := range []struct {
+		remoteAddr string
+		code       int
+	}{
+		{remoteAddr: "192.0.2.1:1234", code: http.StatusNoContent},
+		{remoteAddr: "192.0.2.1:1234", code: http.StatusTooManyRequests},
+		{remoteAddr: "192.0.2.2:1234", code: http.StatusNoContent},
+	} {
+		w := callPublic(tt.remoteAddr)
+
+		if w.Code != tt.code {
+			t.Errorf("Should limit the requests without a tenant by client %s, got %d, exp %d", tt.remoteAddr, w.Code, tt.code)
+		}
+
+		if got := w.Header().Get(appmid.HeaderRateLimitLimit); got != "1" {
+			t.Errorf("Should report the anonymous limit : %q", got)
+		}
+	}
+
+	w = call("")
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("Should count an authenticated request without a tenant against its client : %d : %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,25 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// RequestIDHeader is the header used to receive and return the request id.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID executes the request id middleware functionality. The request id
+// is echoed in the response headers.
+func RequestID() web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		return mid.RequestID(ctx, r.Header.Get(RequestIDHeader), func(ctx context.Context) (mid.Encoder, error) {
+			web.SetHeader(ctx, RequestIDHeader, mid.GetRequestID(ctx))
+			return next(ctx)
+		})
+	}
+
+	return addMidFunc(midFunc)
+}
@@ -0,0 +1,86 @@
+package mid_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	appmid "github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
+)
+
+func Test_RequestID(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name      string
+		requestID string
+		preserved bool
+	}{
+		{name: "generated", requestID: "", preserved: false},
+		{name: "provided", requestID: "ticket-1234.abc", preserved: true},
+		{name: "invalid", requestID: "bad id\r\nX-Injected: 1", preserved: false},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			var buf bytes.Buffer
+			log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+			webLog := func(ctx context.Context, msg string, args ...any) {}
+
+			var handlerID string
+			handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+				handlerID = appmid.GetRequestID(ctx)
+				log.Info(ctx, "handler")
+				return nil, nil
+			}
+
+			app := web.NewApp(webLog, nil, mid.RequestID(), mid.Logger(log))
+			app.HandlerFunc(http.MethodGet, "", "/test", handler)
+
+			r := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.requestID != "" {
+				r.Header.Set(mid.RequestIDHeader, tt.requestID)
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			got := w.Header().Get(mid.RequestIDHeader)
+			if got != handlerID {
+				t.Fatalf("Should echo the request id in the response : got[%s] exp[%s]", got, handlerID)
+			}
+
+			switch tt.preserved {
+			case true:
+				if got != tt.requestID {
+					t.Fatalf("Should preserve the client request id : got[%s] exp[%s]", got, tt.requestID)
+				}
+
+			case false:
+				if _, err := uuid.Parse(got); err != nil {
+					t.Fatalf("Should generate a uuid request id : %s", got)
+				}
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != 3 {
+				t.Fatalf("Should get three log lines : %d", len(lines))
+			}
+
+			for _, line := range lines {
+				if !strings.Contains(line, `"request_id":"`+got+`"`) {
+					t.Errorf("Should include the request id in the log line : %s", line)
+				}
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
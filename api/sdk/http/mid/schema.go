@@ -0,0 +1,35 @@
+package mid
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/jsonschema"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Schema executes the schema validation middleware functionality. It's added
+// to the routes that register a JSON Schema for their request body.
+func Schema(schema *jsonschema.Schema) web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		var body []byte
+		if r.Body != nil {
+			var err error
+			body, err = io.ReadAll(r.Body)
+			if err != nil {
+				return nil, errs.Newf(errs.InvalidArgument, "request: unable to read payload: %s", err)
+			}
+
+			// Put the body back so the handler can decode it.
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		return mid.Schema(ctx, schema, body, next)
+	}
+
+	return addMidFunc(midFunc)
+}
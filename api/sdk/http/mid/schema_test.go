@@ -0,0 +1,135 @@
+package mid_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/foundation/jsonschema"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/go-cmp/cmp"
+)
+
+type product struct {
+	Name     string  `json:"name"`
+	Cost     float64 `json:"cost"`
+	Quantity int     `json:"quantity"`
+}
+
+func (p *product) Decode(data []byte) error {
+	return json.Unmarshal(data, p)
+}
+
+func (p product) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(p)
+	return data, "application/json", err
+}
+
+func Test_Schema(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	schema := jsonschema.MustCompile([]byte(`{
+		"type": "object",
+		"required": ["name", "cost", "quantity"],
+		"additionalProperties": false,
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"cost": {"type": "number", "exclusiveMinimum": 0},
+			"quantity": {"type": "integer", "minimum": 1}
+		}
+	}`))
+
+	// The handler decodes the body the schema middleware already read.
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		var p product
+		if err := web.Decode(r, &p); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+
+	app := web.NewApp(webLog, nil, mid.Errors(log))
+	app.HandlerFunc(http.MethodPost, "", "/products", handler, mid.Schema(schema))
+
+	type details []struct {
+		Field string `json:"field"`
+		Error string `json:"error"`
+	}
+
+	table := []struct {
+		name    string
+		body    string
+		status  int
+		details details
+	}{
+		{
+			name:   "valid",
+			body:   `{"name":"Comic Books","cost":10.5,"quantity":2}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "violations",
+			body:   `{"name":"","cost":0,"quantity":1.5,"color":"red"}`,
+			status: http.StatusBadRequest,
+			details: details{
+				{Field: "/color", Error: "is not allowed"},
+				{Field: "/cost", Error: "must be greater than 0"},
+				{Field: "/name", Error: "must be at least 1 characters"},
+				{Field: "/quantity", Error: "must be of type integer"},
+			},
+		},
+		{
+			name:   "malformed",
+			body:   `{"name":`,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("Should get status %d : got %d : %s", tt.status, w.Code, w.Body.String())
+			}
+
+			if tt.status == http.StatusOK {
+				var got product
+				if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+					t.Fatalf("Should be able to decode the response : %s", err)
+				}
+
+				if got.Name != "Comic Books" || got.Quantity != 2 {
+					t.Errorf("Should let the handler decode the body : %+v", got)
+				}
+				return
+			}
+
+			var got struct {
+				Details details `json:"details"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("Should be able to decode the response : %s", err)
+			}
+
+			if diff := cmp.Diff(got.Details, tt.details); diff != "" {
+				t.Errorf("Should report every violation, diff:\n%s", diff)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
@@ -0,0 +1,28 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// SecurityHeaders executes the security headers middleware functionality.
+func SecurityHeaders(cfg mid.SecurityHeadersConfig) web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		return mid.SecurityHeaders(ctx, cfg, r.TLS != nil, web.SetHeader, next)
+	}
+
+	return addMidFunc(midFunc)
+}
+
+// ContentSecurityPolicy executes the route content security policy
+// middleware functionality.
+func ContentSecurityPolicy(policy string) web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		return mid.ContentSecurityPolicy(ctx, policy, web.SetHeader, next)
+	}
+
+	return addMidFunc(midFunc)
+}
@@ -0,0 +1,89 @@
+package mid_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	appmid "github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_SecurityHeaders(t *testing.T) {
+	t.Parallel()
+
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return nil, nil
+	}
+
+	cfg := appmid.SecurityHeadersConfig{
+		ContentSecurityPolicy: "default-src 'none'",
+		HSTSMaxAge:            time.Hour,
+	}
+
+	app := web.NewApp(webLog, nil, mid.SecurityHeaders(cfg))
+	app.HandlerFunc(http.MethodGet, "v1", "/users", handler)
+	app.HandlerFunc(http.MethodGet, "v1", "/docs", handler, mid.ContentSecurityPolicy("default-src 'self'; script-src 'self' 'unsafe-inline'"))
+
+	call := func(target string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Should serve %s : %d : %s", target, w.Code, w.Body)
+		}
+
+		return w
+	}
+
+	// -------------------------------------------------------------------------
+	// Defaults
+
+	w := call("https://example.com/v1/users")
+
+	exp := map[string]string{
+		appmid.HeaderContentTypeOptions:      "nosniff",
+		appmid.HeaderFrameOptions:            "DENY",
+		appmid.HeaderReferrerPolicy:          "strict-origin-when-cross-origin",
+		appmid.HeaderContentSecurityPolicy:   "default-src 'none'",
+		appmid.HeaderStrictTransportSecurity: "max-age=3600; includeSubDomains",
+	}
+
+	for key, value := range exp {
+		if got := w.Header().Get(key); got != value {
+			t.Errorf("Should set %s, got %q, exp %q", key, got, value)
+		}
+	}
+
+	// -------------------------------------------------------------------------
+	// Plain HTTP
+
+	w = call("http://example.com/v1/users")
+
+	if got := w.Header().Get(appmid.HeaderStrictTransportSecurity); got != "" {
+		t.Errorf("Should not set HSTS over plain HTTP : %q", got)
+	}
+
+	if got := w.Header().Get(appmid.HeaderContentTypeOptions); got != "nosniff" {
+		t.Errorf("Should still set the other headers over plain HTTP : %q", got)
+	}
+
+	// -------------------------------------------------------------------------
+	// Route override
+
+	w = call("https://example.com/v1/docs")
+
+	if got, exp := w.Header().Get(appmid.HeaderContentSecurityPolicy), "default-src 'self'; script-src 'self' 'unsafe-inline'"; got != exp {
+		t.Errorf("Should use the policy of the route, got %q, exp %q", got, exp)
+	}
+
+	if got := w.Header().Get(appmid.HeaderFrameOptions); got != "DENY" {
+		t.Errorf("Should keep the other headers on the route : %q", got)
+	}
+}
@@ -0,0 +1,96 @@
+package mid_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/api/sdk/http/apitest"
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/app/sdk/auth"
+	appmid "github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/tracer"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+func Test_TenantFromClaims(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	ath, err := auth.New(auth.Config{Log: log, KeyLookup: &apitest.KeyStore{}, Issuer: "test"})
+	if err != nil {
+		t.Fatalf("Should be able to construct auth : %s", err)
+	}
+
+	var baggageTenant, claimsTenant string
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		baggageTenant = tracer.GetBaggage(ctx, tracer.BaggageTenantID)
+		claimsTenant = appmid.GetTenantID(ctx)
+		return nil, nil
+	}
+
+	// The baggage is trusted here to show the claims replace whatever the
+	// client sent.
+	app := web.NewApp(webLog, nil, mid.Baggage(tracer.BaggageTenantID))
+	app.HandlerFunc(http.MethodGet, "", "/test", handler, mid.Bearer(ath))
+
+	table := []struct {
+		name   string
+		tenant string
+	}{
+		{name: "tenant", tenant: "acme"},
+		{name: "no-tenant", tenant: ""},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/test", nil)
+			r.Header.Set("Authorization", "Bearer "+bearerToken(t, ath, uuid.NewString(), tt.tenant))
+			r.Header.Set("baggage", tracer.BaggageTenantID+"=globex")
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("Should authenticate : %d : %s", w.Code, w.Body)
+			}
+
+			if claimsTenant != tt.tenant {
+				t.Errorf("Should get the tenant of the claims, got %q, exp %q", claimsTenant, tt.tenant)
+			}
+
+			if baggageTenant != tt.tenant {
+				t.Errorf("Should replace the tenant sent by the client, got %q, exp %q", baggageTenant, tt.tenant)
+			}
+		})
+	}
+}
+
+func bearerToken(t *testing.T, ath *auth.Auth, subject string, tenant string) string {
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			Issuer:    ath.Issuer(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+		Roles:  []string{"USER"},
+		Tenant: tenant,
+	}
+
+	tkn, err := ath.GenerateToken("kid", claims)
+	if err != nil {
+		t.Fatalf("Should be able to generate a token : %s", err)
+	}
+
+	return tkn
+}
@@ -1,19 +1,38 @@
 package mid
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"net/http"
 
+	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/app/sdk/mid"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
-	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
-// BeginCommitRollback executes the transaction middleware functionality.
-func BeginCommitRollback(log *logger.Logger, bgn sqldb.Beginner) web.MidFunc {
+// BeginCommitRollback executes the transaction middleware functionality. The
+// body of the request is buffered, so every attempt of an executor that
+// retries reads it from the start. A body larger than maxBody bytes is
+// rejected before it's held in memory.
+func BeginCommitRollback(exec sqldb.Executor, maxBody int64) web.MidFunc {
 	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
-		return mid.BeginCommitRollback(ctx, log, bgn, next)
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBody+1))
+		if err != nil {
+			return nil, errs.Newf(errs.InvalidArgument, "unable to read payload: %s", err)
+		}
+
+		if int64(len(body)) > maxBody {
+			return nil, errs.Newf(errs.PayloadTooLarge, "payload exceeds %d bytes", maxBody)
+		}
+
+		attempt := func(ctx context.Context) (mid.Encoder, error) {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			return next(ctx)
+		}
+
+		return mid.BeginCommitRollback(ctx, exec, attempt)
 	}
 
 	return addMidFunc(midFunc)
@@ -0,0 +1,144 @@
+package mid_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	appmid "github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type tran struct {
+	commits   int
+	rollbacks int
+}
+
+func (t *tran) Commit() error {
+	t.commits++
+	return nil
+}
+
+func (t *tran) Rollback() error {
+	t.rollbacks++
+	return nil
+}
+
+type beginner struct {
+	tx *tran
+}
+
+func (b *beginner) Begin() (sqldb.CommitRollbacker, error) {
+	return b.tx, nil
+}
+
+func Test_BeginCommitRollback(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	var tx tran
+	exec := sqldb.NewRetryTransactor(log, sqldb.NewTransactor(log, &beginner{tx: &tx}), 3, time.Millisecond, nil)
+
+	var bodies []string
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		if _, err := appmid.GetTran(ctx); err != nil {
+			return nil, errs.New(errs.Internal, err)
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, errs.New(errs.Internal, err)
+		}
+
+		bodies = append(bodies, string(body))
+
+		if len(bodies) == 1 {
+			return nil, errs.Newf(errs.Internal, "create: %s", &pgconn.PgError{Code: "40001", Message: "could not serialize access"})
+		}
+
+		return response{Name: string(body)}, nil
+	}
+
+	app := web.NewApp(webLog, nil, mid.Errors(log))
+	app.HandlerFunc(http.MethodPost, "", "/test", handler, mid.BeginCommitRollback(exec, 1024))
+
+	r := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader("bill"))
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should succeed once the transaction is retried : %d : %s", w.Code, w.Body)
+	}
+
+	if len(bodies) != 2 {
+		t.Fatalf("Should call the handler again for the retry : got[%d]", len(bodies))
+	}
+
+	for i, body := range bodies {
+		if body != "bill" {
+			t.Errorf("Should read the whole body on attempt %d : got[%q]", i+1, body)
+		}
+	}
+
+	if tx.commits != 1 || tx.rollbacks != 1 {
+		t.Errorf("Should rollback the failed attempt and commit the retry : commits[%d] rollbacks[%d]", tx.commits, tx.rollbacks)
+	}
+}
+
+func Test_BeginCommitRollbackLimit(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	var tx tran
+	exec := sqldb.NewTransactor(log, &beginner{tx: &tx})
+
+	var called bool
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		called = true
+		return response{Name: "bill"}, nil
+	}
+
+	app := web.NewApp(webLog, nil, mid.Errors(log))
+	app.HandlerFunc(http.MethodPost, "", "/test", handler, mid.BeginCommitRollback(exec, 4))
+
+	table := []struct {
+		name string
+		body string
+		code int
+	}{
+		{name: "limit", body: "bill", code: http.StatusOK},
+		{name: "over", body: "billy", code: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			called = false
+
+			r := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("Should get the expected status : got[%d] exp[%d] : %s", w.Code, tt.code, w.Body)
+			}
+
+			if exp := tt.code == http.StatusOK; called != exp {
+				t.Errorf("Should only call the handler for a body within the limit : called[%v]", called)
+			}
+		})
+	}
+}
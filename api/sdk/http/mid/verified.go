@@ -0,0 +1,20 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// EmailVerified restricts the route to authenticated users that have
+// verified their email address.
+func EmailVerified(userBus *userbus.Business) web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		return mid.EmailVerified(ctx, userBus, next)
+	}
+
+	return addMidFunc(midFunc)
+}
@@ -0,0 +1,113 @@
+package mid_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"testing"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+func Test_EmailVerified(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	userBus := userbus.NewBusiness(log, nil, usermem.NewStore())
+
+	unverified, err := userBus.Create(ctx, userbus.NewUser{
+		Name:     userbus.MustParseName("Bill Kennedy"),
+		Email:    mail.Address{Address: "bill@example.com"},
+		Roles:    []userbus.Role{userbus.Roles.User},
+		Password: "gophers",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a user : %s", err)
+	}
+
+	verified, err := userBus.Create(ctx, userbus.NewUser{
+		Name:     userbus.MustParseName("Jack Kennedy"),
+		Email:    mail.Address{Address: "jack@example.com"},
+		Roles:    []userbus.Role{userbus.Roles.User},
+		Password: "gophers",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a user : %s", err)
+	}
+
+	if _, err := userBus.VerifyEmail(ctx, verified); err != nil {
+		t.Fatalf("Should be able to verify the user : %s", err)
+	}
+
+	// The auth service authenticates the user id provided as the token.
+
+	authHandler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		userID, err := uuid.Parse(r.Header.Get("authorization")[len("Bearer "):])
+		if err != nil {
+			return nil, err
+		}
+
+		resp := authclient.AuthenticateResp{
+			UserID: userID,
+			Claims: auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: userID.String()}},
+		}
+
+		return resp, nil
+	}
+
+	authApp := web.NewApp(webLog, nil)
+	authApp.HandlerFunc(http.MethodGet, "v1", "/auth/authenticate", authHandler)
+
+	srv := httptest.NewServer(authApp)
+	defer srv.Close()
+
+	// -------------------------------------------------------------------------
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return nil, nil
+	}
+
+	authen := mid.Authenticate(log, authclient.New(log, srv.URL))
+
+	app := web.NewApp(webLog, nil, mid.Errors(log))
+	app.HandlerFunc(http.MethodGet, "", "/test", handler, authen, mid.EmailVerified(userBus))
+
+	table := []struct {
+		name   string
+		userID uuid.UUID
+		status int
+	}{
+		{name: "verified", userID: verified.ID, status: http.StatusNoContent},
+		{name: "unverified", userID: unverified.ID, status: http.StatusForbidden},
+		{name: "unknown", userID: uuid.New(), status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/test", nil)
+			r.Header.Set("Authorization", "Bearer "+tt.userID.String())
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Errorf("Should get status %d : got %d : %s", tt.status, w.Code, w.Body.String())
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
@@ -0,0 +1,32 @@
+package mid
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Webhook executes the webhook verification middleware functionality. The
+// body is read to verify the signature and put back for the handler.
+func Webhook(cfg mid.WebhookConfig) web.MidFunc {
+	midFunc := func(ctx context.Context, r *http.Request, next mid.HandlerFunc) (mid.Encoder, error) {
+		var body []byte
+		if r.Body != nil {
+			var err error
+			body, err = io.ReadAll(io.LimitReader(r.Body, int64(cfg.Limit())+1))
+			if err != nil {
+				return nil, err
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		return mid.Webhook(ctx, cfg, r.Header, body, next)
+	}
+
+	return addMidFunc(midFunc)
+}
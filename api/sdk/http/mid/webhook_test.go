@@ -0,0 +1,137 @@
+package mid_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/api/sdk/http/mid"
+	appmid "github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/sdk/webhook"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_Webhook(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	const secret = "shh"
+
+	var (
+		received [][]byte
+		fail     bool
+	)
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		received = append(received, body)
+
+		if fail {
+			return nil, errors.New("processing failed")
+		}
+
+		return nil, nil
+	}
+
+	cfg := appmid.WebhookConfig{
+		Secret:    secret,
+		Tolerance: 5 * time.Minute,
+		Replays:   webhook.NewReplays(5 * time.Minute),
+	}
+
+	app := web.NewApp(webLog, nil, mid.Errors(log))
+	app.HandlerFunc(http.MethodPost, "v1", "/hooks", handler, mid.Webhook(cfg))
+
+	call := func(h http.Header, body []byte) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/hooks", bytes.NewReader(body))
+		for key, values := range h {
+			r.Header[key] = values
+		}
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	body := []byte(`{"id":"1234"}`)
+
+	// -------------------------------------------------------------------------
+	// Valid
+
+	h := make(http.Header)
+	webhook.Sign(h, secret, time.Now(), body)
+
+	w := call(h, body)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Should accept a signed delivery : %d : %s", w.Code, w.Body)
+	}
+
+	if len(received) != 1 || !bytes.Equal(received[0], body) {
+		t.Fatalf("Should pass the body to the handler : %q", received)
+	}
+
+	// -------------------------------------------------------------------------
+	// Replayed
+
+	w = call(h, body)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Should reject a replayed signature : %d : %s", w.Code, w.Body)
+	}
+
+	if len(received) != 1 {
+		t.Errorf("Should not pass a replayed delivery to the handler : %d", len(received))
+	}
+
+	// -------------------------------------------------------------------------
+	// Stale timestamp
+
+	stale := make(http.Header)
+	webhook.Sign(stale, secret, time.Now().Add(-10*time.Minute), body)
+
+	if w := call(stale, body); w.Code != http.StatusUnauthorized {
+		t.Errorf("Should reject a timestamp outside the tolerance : %d : %s", w.Code, w.Body)
+	}
+
+	skewed := make(http.Header)
+	webhook.Sign(skewed, secret, time.Now().Add(2*time.Minute), body)
+
+	if w := call(skewed, body); w.Code != http.StatusNoContent {
+		t.Errorf("Should accept a timestamp within the clock skew tolerance : %d : %s", w.Code, w.Body)
+	}
+
+	// -------------------------------------------------------------------------
+	// Tampered
+
+	if w := call(h, []byte(`{"id":"5678"}`)); w.Code != http.StatusUnauthorized {
+		t.Errorf("Should reject a body that doesn't match the signature : %d : %s", w.Code, w.Body)
+	}
+
+	// -------------------------------------------------------------------------
+	// Retry after a failure
+
+	retry := make(http.Header)
+	webhook.Sign(retry, secret, time.Now().Add(-time.Minute), body)
+
+	fail = true
+	if w := call(retry, body); w.Code != http.StatusInternalServerError {
+		t.Fatalf("Should fail the delivery : %d : %s", w.Code, w.Body)
+	}
+
+	fail = false
+	if w := call(retry, body); w.Code != http.StatusNoContent {
+		t.Errorf("Should accept the retry of a delivery that failed : %d : %s", w.Code, w.Body)
+	}
+}
@@ -5,11 +5,23 @@ package mux
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/ardanlabs/service/api/sdk/http/mid"
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/emailverify"
+	appmid "github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/notify"
+	"github.com/ardanlabs/service/app/sdk/quota"
+	"github.com/ardanlabs/service/app/sdk/runtimecfg"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/business/sdk/featureflag"
+	"github.com/ardanlabs/service/business/sdk/hasher"
 	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/ardanlabs/service/foundation/retry"
+	"github.com/ardanlabs/service/foundation/tracer"
 	"github.com/ardanlabs/service/foundation/web"
 	"github.com/jmoiron/sqlx"
 	"go.opentelemetry.io/otel/trace"
@@ -17,7 +29,18 @@ import (
 
 // Options represent optional parameters.
 type Options struct {
-	corsOrigin []string
+	corsOrigin  []string
+	debugLog    *appmid.DebugLogConfig
+	maxInFlight int
+	retryAfter  time.Duration
+	otelMetrics *otel.Exporter
+	panicMapper []appmid.PanicMapper
+	proxies     *appmid.TrustedProxies
+	maxHeaders  int
+	spa         *web.Static
+	spaExcluded []string
+	version     string
+	security    *appmid.SecurityHeadersConfig
 }
 
 // WithCORS provides configuration options for CORS.
@@ -27,14 +50,137 @@ func WithCORS(origins []string) func(opts *Options) {
 	}
 }
 
+// WithDebugLog enables logging of the request and response bodies.
+func WithDebugLog(cfg appmid.DebugLogConfig) func(opts *Options) {
+	return func(opts *Options) {
+		opts.debugLog = &cfg
+	}
+}
+
+// WithConcurrencyLimit caps the number of requests that can be in-flight at
+// the same time. Requests over the limit are told to retry after the
+// specified duration.
+func WithConcurrencyLimit(max int, retryAfter time.Duration) func(opts *Options) {
+	return func(opts *Options) {
+		opts.maxInFlight = max
+		opts.retryAfter = retryAfter
+	}
+}
+
+// WithTrustedProxies sets the proxies whose forwarding headers are trusted
+// to report the address of the client.
+func WithTrustedProxies(tp *appmid.TrustedProxies) func(opts *Options) {
+	return func(opts *Options) {
+		opts.proxies = tp
+	}
+}
+
+// WithMaxHeaderCount caps the number of header fields a request can have.
+// Requests with more are rejected with a 431.
+func WithMaxHeaderCount(max int) func(opts *Options) {
+	return func(opts *Options) {
+		opts.maxHeaders = max
+	}
+}
+
+// WithSPA serves the single-page app for GET requests that don't match any
+// route. Paths starting with one of the excluded prefixes, like the prefix
+// of the API routes, still respond with a 404.
+func WithSPA(static web.Static, excluded ...string) func(opts *Options) {
+	return func(opts *Options) {
+		opts.spa = &static
+		opts.spaExcluded = excluded
+	}
+}
+
+// WithSecurityHeaders sets the security headers on every response.
+func WithSecurityHeaders(cfg appmid.SecurityHeadersConfig) func(opts *Options) {
+	return func(opts *Options) {
+		opts.security = &cfg
+	}
+}
+
+// WithVersionNegotiation lets clients select the version of the routes with
+// the version parameter of the Accept header. Requests whose path and Accept
+// header don't name a version are routed to the default version.
+func WithVersionNegotiation(defaultVersion string) func(opts *Options) {
+	return func(opts *Options) {
+		opts.version = defaultVersion
+	}
+}
+
+// WithPanicMapper adds a mapper that converts known panic values into
+// specific errors for every route. Unknown panics are internal errors.
+func WithPanicMapper(mapper appmid.PanicMapper) func(opts *Options) {
+	return func(opts *Options) {
+		opts.panicMapper = append(opts.panicMapper, mapper)
+	}
+}
+
+// WithOTelMetrics records request metrics with the OpenTelemetry exporter.
+func WithOTelMetrics(exp *otel.Exporter) func(opts *Options) {
+	return func(opts *Options) {
+		opts.otelMetrics = exp
+	}
+}
+
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
-	Build      string
-	Log        *logger.Logger
-	Auth       *auth.Auth
-	AuthClient *authclient.Client
-	DB         *sqlx.DB
-	Tracer     trace.Tracer
+	Build         string
+	Log           *logger.Logger
+	Auth          *auth.Auth
+	AuthClient    *authclient.Client
+	DB            *sqlx.DB
+	Replica       Replica
+	Tracer        trace.Tracer
+	RuntimeConfig *runtimecfg.Config
+	Hasher        hasher.Hasher
+	Lockout       Lockout
+	Throttle      Throttle
+	EmailVerifier *emailverify.Verifier
+	Notifier      notify.Sender
+	PasswordReset PasswordReset
+	Flags         *featureflag.Flags
+	OTelMetrics   *otel.Exporter
+	Quotas        *quota.Quotas
+	TxRetry       TxRetry
+	RetryBudget   *retry.Budget
+	Delegate      *delegate.Delegate
+}
+
+// Replica contains the settings for sending reads to a read replica. A nil
+// DB sends every read to the primary.
+type Replica struct {
+	DB     *sqlx.DB
+	MaxLag time.Duration
+}
+
+// Lockout contains the settings for locking accounts after repeated failed
+// logins. A zero value disables the lockout.
+type Lockout struct {
+	MaxAttempts int
+	Duration    time.Duration
+}
+
+// Throttle contains the settings for slowing down repeated failed Basic auth
+// attempts for the same identity. A zero value disables throttling.
+type Throttle struct {
+	MaxFailures int
+	Window      time.Duration
+}
+
+// TxRetry contains the settings for running transactions again when they
+// fail with a serialization failure or a deadlock. A zero value doesn't
+// retry.
+type TxRetry struct {
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+// PasswordReset contains the settings for password reset tokens. A zero
+// value uses the defaults.
+type PasswordReset struct {
+	TTL time.Duration
 }
 
 // RouteAdder defines behavior that sets the routes to bind for an instance
@@ -49,24 +195,72 @@ func WebAPI(cfg Config, routeAdder RouteAdder, options ...func(opts *Options)) h
 		cfg.Log.Info(ctx, msg, args...)
 	}
 
-	app := web.NewApp(
-		logger,
-		cfg.Tracer,
+	var opts Options
+	for _, option := range options {
+		option(&opts)
+	}
+
+	mw := []web.MidFunc{
+		mid.Baggage(tracer.BaggageRequestID),
+		mid.RequestID(),
+		mid.ClientIP(opts.proxies),
 		mid.Logger(cfg.Log),
 		mid.Errors(cfg.Log),
 		mid.Metrics(),
-		mid.Panics(),
-	)
+	}
 
-	var opts Options
-	for _, option := range options {
-		option(&opts)
+	if opts.security != nil {
+		mw = append(mw, mid.SecurityHeaders(*opts.security))
+	}
+
+	if opts.otelMetrics != nil {
+		mw = append(mw, mid.OTelMetrics(opts.otelMetrics))
 	}
 
+	if cfg.RuntimeConfig != nil {
+		mw = append(mw, mid.Maintenance(cfg.RuntimeConfig.Maintenance()))
+	}
+
+	if cfg.Replica.DB != nil {
+		mw = append(mw, mid.Consistency())
+	}
+
+	if cfg.Flags != nil {
+		mw = append(mw, mid.FeatureFlags(cfg.Flags))
+	}
+
+	if opts.maxInFlight > 0 {
+		mw = append(mw, mid.ConcurrencyLimit(opts.maxInFlight, opts.retryAfter))
+	}
+
+	mw = append(mw, mid.Panics(opts.panicMapper...))
+
+	if opts.debugLog != nil {
+		mw = append(mw, mid.DebugLog(cfg.Log, *opts.debugLog))
+	}
+
+	app := web.NewApp(logger, cfg.Tracer, mw...)
+
+	app.SetDebugLogger(func(ctx context.Context, msg string, args ...any) {
+		cfg.Log.Debug(ctx, msg, args...)
+	})
+
 	if len(opts.corsOrigin) > 0 {
 		app.EnableCORS(opts.corsOrigin)
 	}
 
+	if opts.maxHeaders > 0 {
+		app.SetMaxHeaderCount(opts.maxHeaders)
+	}
+
+	if opts.version != "" {
+		app.SetVersionNegotiation(opts.version)
+	}
+
+	if opts.spa != nil {
+		app.SetFallback(opts.spa, opts.spaExcluded...)
+	}
+
 	routeAdder.Add(app, cfg)
 
 	return app
@@ -0,0 +1,63 @@
+// Package adminapp maintains the app layer api for the admin domain.
+package adminapp
+
+import (
+	"context"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/runtimecfg"
+)
+
+// App manages the set of app layer api functions for the admin domain.
+type App struct {
+	rtCfg *runtimecfg.Config
+}
+
+// NewApp constructs an admin app API for use.
+func NewApp(rtCfg *runtimecfg.Config) *App {
+	return &App{
+		rtCfg: rtCfg,
+	}
+}
+
+// QueryConfig returns the current runtime settings.
+func (a *App) QueryConfig(ctx context.Context) RuntimeConfig {
+	return toAppRuntimeConfig(a.rtCfg)
+}
+
+// UpdateConfig changes the runtime settings that are provided. The settings
+// are validated before any are changed.
+func (a *App) UpdateConfig(ctx context.Context, app UpdateRuntimeConfig) (RuntimeConfig, error) {
+	level, err := app.parseLogLevel()
+	if err != nil {
+		return RuntimeConfig{}, errs.New(errs.InvalidArgument, err)
+	}
+
+	if app.SampleRate != nil {
+		if err := a.rtCfg.SetSampleRate(*app.SampleRate); err != nil {
+			return RuntimeConfig{}, errs.New(errs.InvalidArgument, err)
+		}
+	}
+
+	if level != nil {
+		a.rtCfg.SetLogLevel(*level)
+	}
+
+	if app.Maintenance != nil {
+		a.rtCfg.Maintenance().Set(app.Maintenance.Enabled, app.Maintenance.AllowReads)
+	}
+
+	return toAppRuntimeConfig(a.rtCfg), nil
+}
+
+// QueryMaintenance returns the current maintenance mode state.
+func (a *App) QueryMaintenance(ctx context.Context) Maintenance {
+	return toAppMaintenance(a.rtCfg.Maintenance())
+}
+
+// UpdateMaintenance changes the maintenance mode state.
+func (a *App) UpdateMaintenance(ctx context.Context, app Maintenance) Maintenance {
+	a.rtCfg.Maintenance().Set(app.Enabled, app.AllowReads)
+
+	return toAppMaintenance(a.rtCfg.Maintenance())
+}
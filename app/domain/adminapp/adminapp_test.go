@@ -0,0 +1,82 @@
+package adminapp_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/app/domain/adminapp"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/runtimecfg"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_UpdateLogLevel(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	rtCfg, err := runtimecfg.New(log, 0.05, mid.NewMaintenanceMode(false, true))
+	if err != nil {
+		t.Fatalf("Should be able to construct the runtime config : %s", err)
+	}
+
+	adminApp := adminapp.NewApp(rtCfg)
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		log.Debug(ctx, "debug line")
+		return nil, nil
+	}
+
+	app := web.NewApp(func(ctx context.Context, msg string, args ...any) {}, nil)
+	app.HandlerFunc(http.MethodGet, "", "/test", handler)
+
+	call := func() {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+	}
+
+	call()
+
+	if strings.Contains(buf.String(), "debug line") {
+		t.Fatalf("Should not log debug lines at the info level")
+	}
+
+	// -------------------------------------------------------------------------
+
+	level := "DEBUG"
+	cfg, err := adminApp.UpdateConfig(context.Background(), adminapp.UpdateRuntimeConfig{LogLevel: &level})
+	if err != nil {
+		t.Fatalf("Should be able to update the config : %s", err)
+	}
+
+	if cfg.LogLevel != "DEBUG" {
+		t.Errorf("Should get the new log level : %s", cfg.LogLevel)
+	}
+
+	call()
+
+	if !strings.Contains(buf.String(), "debug line") {
+		t.Errorf("Should log debug lines after changing the level")
+	}
+
+	// -------------------------------------------------------------------------
+
+	bad := "LOUD"
+	if _, err := adminApp.UpdateConfig(context.Background(), adminapp.UpdateRuntimeConfig{LogLevel: &bad}); err == nil {
+		t.Errorf("Should not accept an unknown log level")
+	}
+
+	rate := 2.0
+	if _, err := adminApp.UpdateConfig(context.Background(), adminapp.UpdateRuntimeConfig{SampleRate: &rate}); err == nil {
+		t.Errorf("Should not accept a sample rate over 1")
+	}
+
+	if got := adminApp.QueryConfig(context.Background()); got.LogLevel != "DEBUG" || got.SampleRate != 0.05 {
+		t.Errorf("Should not change the config on a failed update : %+v", got)
+	}
+}
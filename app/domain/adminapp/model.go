@@ -0,0 +1,90 @@
+package adminapp
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/ardanlabs/service/app/sdk/decode"
+	"github.com/ardanlabs/service/app/sdk/encode"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/runtimecfg"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// Maintenance represents the maintenance mode state of the service.
+type Maintenance struct {
+	Enabled    bool `json:"enabled"`
+	AllowReads bool `json:"allowReads"`
+}
+
+// Decode implements the decoder interface.
+func (app *Maintenance) Decode(data []byte) error {
+	return decode.JSON(data, &app)
+}
+
+// Encode implements the encoder interface.
+func (app Maintenance) Encode() ([]byte, string, error) {
+	data, err := encode.JSON(app)
+	return data, "application/json", err
+}
+
+func toAppMaintenance(m *mid.MaintenanceMode) Maintenance {
+	return Maintenance{
+		Enabled:    m.Enabled(),
+		AllowReads: m.AllowReads(),
+	}
+}
+
+// =============================================================================
+
+// RuntimeConfig represents the settings that can be changed while the
+// service is running.
+type RuntimeConfig struct {
+	LogLevel    string      `json:"logLevel"`
+	SampleRate  float64     `json:"sampleRate"`
+	Maintenance Maintenance `json:"maintenance"`
+}
+
+// Encode implements the encoder interface.
+func (app RuntimeConfig) Encode() ([]byte, string, error) {
+	data, err := encode.JSON(app)
+	return data, "application/json", err
+}
+
+func toAppRuntimeConfig(cfg *runtimecfg.Config) RuntimeConfig {
+	return RuntimeConfig{
+		LogLevel:    slog.Level(cfg.LogLevel()).String(),
+		SampleRate:  cfg.SampleRate(),
+		Maintenance: toAppMaintenance(cfg.Maintenance()),
+	}
+}
+
+// =============================================================================
+
+// UpdateRuntimeConfig defines the settings that can be changed. Only the
+// settings that are provided are changed.
+type UpdateRuntimeConfig struct {
+	LogLevel    *string      `json:"logLevel"`
+	SampleRate  *float64     `json:"sampleRate"`
+	Maintenance *Maintenance `json:"maintenance"`
+}
+
+// Decode implements the decoder interface.
+func (app *UpdateRuntimeConfig) Decode(data []byte) error {
+	return decode.JSON(data, &app)
+}
+
+func (app UpdateRuntimeConfig) parseLogLevel() (*logger.Level, error) {
+	if app.LogLevel == nil {
+		return nil, nil
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*app.LogLevel)); err != nil {
+		return nil, fmt.Errorf("parse: log level: %w", err)
+	}
+
+	l := logger.Level(level)
+
+	return &l, nil
+}
@@ -0,0 +1,172 @@
+// Package eventapp maintains the app layer api for the event domain.
+package eventapp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// Authorizer reports whether the claims allow a subscriber to see the event.
+type Authorizer func(claims auth.Claims, data delegate.Data) bool
+
+// App manages the set of app layer api functions for the event domain.
+type App struct {
+	log         *logger.Logger
+	buffer      int
+	authorizers map[string]Authorizer
+	mu          sync.Mutex
+	subs        map[*Subscription]struct{}
+}
+
+// NewApp constructs an event app API for use. The buffer is the number of
+// events that can be queued for a subscriber before it's considered too
+// slow and disconnected.
+func NewApp(log *logger.Logger, buffer int) *App {
+	if buffer <= 0 {
+		buffer = 16
+	}
+
+	return &App{
+		log:         log,
+		buffer:      buffer,
+		authorizers: make(map[string]Authorizer),
+		subs:        make(map[*Subscription]struct{}),
+	}
+}
+
+// Register adds the domain actions to the set of events that can be
+// subscribed to. The authorizer decides which subscribers see an event.
+// This must be called before the service starts taking requests.
+func (a *App) Register(dlg *delegate.Delegate, domain string, authorize Authorizer, actions ...string) {
+	a.authorizers[domain] = authorize
+
+	for _, action := range actions {
+		dlg.Register(domain, action, a.publish)
+	}
+}
+
+// Subscribe starts receiving the events that match the query parameters
+// and that the claims are authorized to see.
+func (a *App) Subscribe(claims auth.Claims, qp QueryParams) (*Subscription, error) {
+	filter, err := a.parseFilter(qp)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := Subscription{
+		app:    a,
+		claims: claims,
+		filter: filter,
+		events: make(chan Event, a.buffer),
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.subs[&sub] = struct{}{}
+
+	return &sub, nil
+}
+
+// publish sends the event to the subscribers. A subscriber that can't keep
+// up is disconnected so it doesn't hold up the domain making the call.
+func (a *App) publish(ctx context.Context, data delegate.Data) error {
+	authorize, exists := a.authorizers[data.Domain]
+	if !exists {
+		return nil
+	}
+
+	evt := toAppEvent(data)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for sub := range a.subs {
+		if !sub.filter.match(data) || !authorize(sub.claims, data) {
+			continue
+		}
+
+		select {
+		case sub.events <- evt:
+		default:
+			a.log.Warn(ctx, "event subscription", "status", "slow consumer disconnected", "subject", sub.claims.Subject)
+			a.remove(sub)
+		}
+	}
+
+	return nil
+}
+
+// remove must be called with the lock held.
+func (a *App) remove(sub *Subscription) {
+	if _, exists := a.subs[sub]; !exists {
+		return
+	}
+
+	delete(a.subs, sub)
+	close(sub.events)
+}
+
+// =============================================================================
+
+// Subscription represents a client receiving events.
+type Subscription struct {
+	app    *App
+	claims auth.Claims
+	filter Filter
+	events chan Event
+}
+
+// Events returns the channel the events are received on. The channel is
+// closed when the subscription is closed or the subscriber is too slow.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close stops the subscription from receiving events.
+func (s *Subscription) Close() {
+	s.app.mu.Lock()
+	defer s.app.mu.Unlock()
+
+	s.app.remove(s)
+}
+
+// =============================================================================
+
+// UserAuthorizer allows admins to see every user event and users to see
+// the events for themselves.
+func UserAuthorizer(claims auth.Claims, data delegate.Data) bool {
+	if isAdmin(claims) {
+		return true
+	}
+
+	var params struct {
+		UserID string
+	}
+	if err := json.Unmarshal(data.RawParams, &params); err != nil {
+		return false
+	}
+
+	return params.UserID != "" && params.UserID == claims.Subject
+}
+
+// AdminAuthorizer only allows admins to see the events.
+func AdminAuthorizer(claims auth.Claims, data delegate.Data) bool {
+	return isAdmin(claims)
+}
+
+func isAdmin(claims auth.Claims) bool {
+	for _, role := range claims.Roles {
+		if role == userbus.Roles.Admin.String() {
+			return true
+		}
+	}
+
+	return false
+}
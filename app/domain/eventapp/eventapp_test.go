@@ -0,0 +1,95 @@
+package eventapp_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/app/domain/eventapp"
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+func Test_Subscribe(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	dlg := delegate.New(log)
+
+	app := eventapp.NewApp(log, 1)
+	app.Register(dlg, userbus.DomainName, eventapp.UserAuthorizer, userbus.ActionUpdated)
+
+	userID := uuid.New()
+
+	owner, err := app.Subscribe(claims(userID, "USER"), eventapp.QueryParams{Domain: userbus.DomainName})
+	if err != nil {
+		t.Fatalf("Should be able to subscribe : %s", err)
+	}
+	defer owner.Close()
+
+	other, err := app.Subscribe(claims(uuid.New(), "USER"), eventapp.QueryParams{})
+	if err != nil {
+		t.Fatalf("Should be able to subscribe : %s", err)
+	}
+	defer other.Close()
+
+	// -------------------------------------------------------------------------
+
+	enabled := false
+	dlg.Call(context.Background(), userbus.ActionUpdatedData(userbus.UpdateUser{Enabled: &enabled}, userID))
+
+	select {
+	case evt := <-owner.Events():
+		if evt.EventName() != "user.updated" {
+			t.Errorf("Should receive the user updated event : %s", evt.EventName())
+		}
+
+	case <-time.After(time.Second):
+		t.Fatalf("Should receive the event as the authorized subscriber")
+	}
+
+	select {
+	case evt := <-other.Events():
+		t.Fatalf("Should not receive the event as an unauthorized subscriber : %s", evt.EventName())
+
+	default:
+	}
+
+	// -------------------------------------------------------------------------
+
+	dlg.Call(context.Background(), userbus.ActionUpdatedData(userbus.UpdateUser{Enabled: &enabled}, userID))
+	dlg.Call(context.Background(), userbus.ActionUpdatedData(userbus.UpdateUser{Enabled: &enabled}, userID))
+
+	<-owner.Events()
+
+	if _, ok := <-owner.Events(); ok {
+		t.Fatalf("Should disconnect a subscriber that can't keep up")
+	}
+}
+
+func Test_SubscribeFilter(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	app := eventapp.NewApp(log, 1)
+	app.Register(delegate.New(log), userbus.DomainName, eventapp.UserAuthorizer, userbus.ActionUpdated)
+
+	if _, err := app.Subscribe(claims(uuid.New(), "ADMIN"), eventapp.QueryParams{Domain: "unknown"}); err == nil {
+		t.Errorf("Should not be able to subscribe to an unknown domain")
+	}
+
+	if _, err := app.Subscribe(claims(uuid.New(), "ADMIN"), eventapp.QueryParams{Action: userbus.ActionUpdated}); err == nil {
+		t.Errorf("Should not be able to filter on action without a domain")
+	}
+}
+
+func claims(userID uuid.UUID, role string) auth.Claims {
+	return auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: userID.String(),
+		},
+		Roles: []string{role},
+	}
+}
@@ -0,0 +1,27 @@
+package eventapp
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+)
+
+func (a *App) parseFilter(qp QueryParams) (Filter, error) {
+	if qp.Domain != "" {
+		if _, exists := a.authorizers[qp.Domain]; !exists {
+			return Filter{}, errs.NewFieldsError("domain", fmt.Errorf("unknown domain %q", qp.Domain))
+		}
+	}
+
+	if qp.Action != "" && qp.Domain == "" {
+		return Filter{}, errs.NewFieldsError("action", errors.New("domain is required with action"))
+	}
+
+	filter := Filter{
+		Domain: qp.Domain,
+		Action: qp.Action,
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,66 @@
+package eventapp
+
+import (
+	"encoding/json"
+
+	"github.com/ardanlabs/service/app/sdk/encode"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+)
+
+// Event represents a domain event sent to a subscriber.
+type Event struct {
+	Domain string          `json:"domain"`
+	Action string          `json:"action"`
+	Params json.RawMessage `json:"params"`
+}
+
+// Encode implements the encoder interface.
+func (app Event) Encode() ([]byte, string, error) {
+	data, err := encode.JSON(app)
+	return data, "application/json", err
+}
+
+// EventName returns the name used for the event in the stream.
+func (app Event) EventName() string {
+	return app.Domain + "." + app.Action
+}
+
+func toAppEvent(data delegate.Data) Event {
+	params := json.RawMessage(data.RawParams)
+	if !json.Valid(params) {
+		params = json.RawMessage("null")
+	}
+
+	return Event{
+		Domain: data.Domain,
+		Action: data.Action,
+		Params: params,
+	}
+}
+
+// =============================================================================
+
+// Filter represents the events a client wants to receive. An empty field
+// matches every value.
+type Filter struct {
+	Domain string
+	Action string
+}
+
+func (f Filter) match(data delegate.Data) bool {
+	if f.Domain != "" && f.Domain != data.Domain {
+		return false
+	}
+
+	if f.Action != "" && f.Action != data.Action {
+		return false
+	}
+
+	return true
+}
+
+// QueryParams represents the set of possible query strings.
+type QueryParams struct {
+	Domain string
+	Action string
+}
@@ -12,6 +12,9 @@ import (
 	"github.com/ardanlabs/service/business/sdk/page"
 )
 
+// ReasonHomeNotFound indicates the specified home doesn't exist.
+var ReasonHomeNotFound = errs.NewReason("home.not_found", errs.NotFound)
+
 // App manages the set of app layer api functions for the home domain.
 type App struct {
 	homeBus *homebus.Business
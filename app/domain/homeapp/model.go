@@ -2,10 +2,11 @@ package homeapp
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/ardanlabs/service/app/sdk/decode"
+	"github.com/ardanlabs/service/app/sdk/encode"
 	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/app/sdk/mid"
 	"github.com/ardanlabs/service/business/domain/homebus"
@@ -47,7 +48,7 @@ type Home struct {
 
 // Encode implements the encoder interface.
 func (app Home) Encode() ([]byte, string, error) {
-	data, err := json.Marshal(app)
+	data, err := encode.JSON(app)
 	return data, "application/json", err
 }
 
@@ -98,13 +99,13 @@ type NewHome struct {
 
 // Decode implements the decoder interface.
 func (app *NewHome) Decode(data []byte) error {
-	return json.Unmarshal(data, &app)
+	return decode.JSON(data, &app)
 }
 
 // Validate checks if the data in the model is considered clean.
 func (app NewHome) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
@@ -157,13 +158,13 @@ type UpdateHome struct {
 
 // Decode implements the decoder interface.
 func (app *UpdateHome) Decode(data []byte) error {
-	return json.Unmarshal(data, &app)
+	return decode.JSON(data, &app)
 }
 
 // Validate checks the data in the model is considered clean.
 func (app UpdateHome) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
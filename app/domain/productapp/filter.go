@@ -5,6 +5,7 @@ import (
 
 	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/money"
 	"github.com/google/uuid"
 )
 
@@ -28,7 +29,7 @@ func parseFilter(qp QueryParams) (productbus.QueryFilter, error) {
 	}
 
 	if qp.Cost != "" {
-		cst, err := strconv.ParseFloat(qp.Cost, 64)
+		cst, err := money.Parse(qp.Cost)
 		if err != nil {
 			return productbus.QueryFilter{}, errs.NewFieldsError("cost", err)
 		}
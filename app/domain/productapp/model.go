@@ -2,13 +2,15 @@ package productapp
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/ardanlabs/service/app/sdk/decode"
+	"github.com/ardanlabs/service/app/sdk/encode"
 	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/app/sdk/mid"
 	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/money"
 )
 
 // QueryParams represents the set of possible query strings.
@@ -26,18 +28,18 @@ type QueryParams struct {
 
 // Product represents information about an individual product.
 type Product struct {
-	ID          string  `json:"id"`
-	UserID      string  `json:"userID"`
-	Name        string  `json:"name"`
-	Cost        float64 `json:"cost"`
-	Quantity    int     `json:"quantity"`
-	DateCreated string  `json:"dateCreated"`
-	DateUpdated string  `json:"dateUpdated"`
+	ID          string      `json:"id"`
+	UserID      string      `json:"userID"`
+	Name        string      `json:"name"`
+	Cost        money.Money `json:"cost"`
+	Quantity    int         `json:"quantity"`
+	DateCreated string      `json:"dateCreated"`
+	DateUpdated string      `json:"dateUpdated"`
 }
 
 // Encode implements the encoder interface.
 func (app Product) Encode() ([]byte, string, error) {
-	data, err := json.Marshal(app)
+	data, err := encode.JSON(app)
 	return data, "application/json", err
 }
 
@@ -66,20 +68,20 @@ func toAppProducts(prds []productbus.Product) []Product {
 
 // NewProduct defines the data needed to add a new product.
 type NewProduct struct {
-	Name     string  `json:"name" validate:"required"`
-	Cost     float64 `json:"cost" validate:"required,gte=0"`
-	Quantity int     `json:"quantity" validate:"required,gte=1"`
+	Name     string      `json:"name" validate:"required"`
+	Cost     money.Money `json:"cost" validate:"required,gte=0"`
+	Quantity int         `json:"quantity" validate:"required,gte=1"`
 }
 
 // Decode implements the decoder interface.
 func (app *NewProduct) Decode(data []byte) error {
-	return json.Unmarshal(data, &app)
+	return decode.JSON(data, &app)
 }
 
 // Validate checks the data in the model is considered clean.
 func (app NewProduct) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
@@ -110,20 +112,20 @@ func toBusNewProduct(ctx context.Context, app NewProduct) (productbus.NewProduct
 
 // UpdateProduct defines the data needed to update a product.
 type UpdateProduct struct {
-	Name     *string  `json:"name"`
-	Cost     *float64 `json:"cost" validate:"omitempty,gte=0"`
-	Quantity *int     `json:"quantity" validate:"omitempty,gte=1"`
+	Name     *string      `json:"name"`
+	Cost     *money.Money `json:"cost" validate:"omitempty,gte=0"`
+	Quantity *int         `json:"quantity" validate:"omitempty,gte=1"`
 }
 
 // Decode implements the decoder interface.
 func (app *UpdateProduct) Decode(data []byte) error {
-	return json.Unmarshal(data, &app)
+	return decode.JSON(data, &app)
 }
 
 // Validate checks the data in the model is considered clean.
 func (app UpdateProduct) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
@@ -12,6 +12,9 @@ import (
 	"github.com/ardanlabs/service/business/sdk/page"
 )
 
+// ReasonProductNotFound indicates the specified product doesn't exist.
+var ReasonProductNotFound = errs.NewReason("product.not_found", errs.NotFound)
+
 // App manages the set of app layer api functions for the product domain.
 type App struct {
 	productBus *productbus.Business
@@ -0,0 +1,355 @@
+package tranapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ardanlabs/service/app/sdk/decode"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/google/uuid"
+)
+
+// Batch executes the operations in order under a single transaction. If any
+// operation fails, the error is returned and the transaction is rolled back.
+func (a *App) Batch(ctx context.Context, batch Batch) (BatchResult, error) {
+	a, err := a.newWithTx(ctx)
+	if err != nil {
+		return BatchResult{}, errs.New(errs.Internal, err)
+	}
+
+	refs := make(map[string]string)
+	results := make([]OperationResult, len(batch.Operations))
+
+	for i, op := range batch.Operations {
+		result, err := a.execute(ctx, op, refs)
+		if err != nil {
+			return BatchResult{}, operationError(i, err)
+		}
+
+		results[i] = result
+	}
+
+	return BatchResult{Results: results}, nil
+}
+
+// BatchIndependent executes the operations in order without a transaction.
+// Each operation succeeds or fails on its own, so a failure doesn't stop or
+// undo the other operations. The status of every operation is reported. An
+// operation that references a failed operation fails with an unknown ref.
+func (a *App) BatchIndependent(ctx context.Context, batch Batch) MultiStatus {
+	refs := make(map[string]string)
+	results := make([]OperationStatus, len(batch.Operations))
+
+	for i, op := range batch.Operations {
+		result, err := a.execute(ctx, op, refs)
+		if err != nil {
+			appErr := operationError(i, err)
+
+			results[i] = OperationStatus{
+				Ref:    op.Ref,
+				Op:     op.Op,
+				Status: appErr.HTTPStatus(),
+				Error:  appErr,
+			}
+			continue
+		}
+
+		status := http.StatusOK
+		if op.Op == OpCreateUser || op.Op == OpCreateProduct {
+			status = http.StatusCreated
+		}
+
+		results[i] = OperationStatus{
+			Ref:    result.Ref,
+			Op:     result.Op,
+			Status: status,
+			ID:     result.ID,
+			Result: result.Result,
+		}
+	}
+
+	return MultiStatus{Results: results}
+}
+
+// execute performs a single operation and records the id of the entity
+// under the ref of the operation.
+func (a *App) execute(ctx context.Context, op BatchOperation, refs map[string]string) (OperationResult, error) {
+	if op.Ref != "" {
+		if _, exists := refs[op.Ref]; exists {
+			return OperationResult{}, errs.Newf(errs.InvalidArgument, "duplicate ref %q", op.Ref)
+		}
+	}
+
+	id, data, err := resolveRefs(op, refs)
+	if err != nil {
+		return OperationResult{}, errs.New(errs.InvalidArgument, err)
+	}
+
+	var result any
+	switch op.Op {
+	case OpCreateUser:
+		result, id, err = a.batchCreateUser(ctx, data)
+	case OpUpdateUser:
+		result, id, err = a.batchUpdateUser(ctx, id, data)
+	case OpCreateProduct:
+		result, id, err = a.batchCreateProduct(ctx, data)
+	case OpUpdateProduct:
+		result, id, err = a.batchUpdateProduct(ctx, id, data)
+	default:
+		err = errs.Newf(errs.InvalidArgument, "unknown op %q", op.Op)
+	}
+
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return OperationResult{}, errs.Newf(errs.Internal, "marshal: %s", err)
+	}
+
+	if op.Ref != "" {
+		refs[op.Ref] = id
+	}
+
+	opResult := OperationResult{
+		Ref:    op.Ref,
+		Op:     op.Op,
+		ID:     id,
+		Result: raw,
+	}
+
+	return opResult, nil
+}
+
+func (a *App) batchCreateUser(ctx context.Context, data []byte) (User, string, error) {
+	var app NewUser
+	if err := decodeOperation(data, &app); err != nil {
+		return User{}, "", err
+	}
+
+	if err := app.Validate(); err != nil {
+		return User{}, "", err
+	}
+
+	nu, err := toBusNewUser(app)
+	if err != nil {
+		return User{}, "", errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, err := a.userBus.Create(ctx, nu)
+	if err != nil {
+		if errors.Is(err, userbus.ErrUniqueEmail) {
+			return User{}, "", errs.New(errs.Aborted, userbus.ErrUniqueEmail)
+		}
+		return User{}, "", errs.Newf(errs.Internal, "create: usr[%+v]: %s", usr, err)
+	}
+
+	return toAppUser(usr), usr.ID.String(), nil
+}
+
+func (a *App) batchUpdateUser(ctx context.Context, id string, data []byte) (User, string, error) {
+	var app UpdateUser
+	if err := decodeOperation(data, &app); err != nil {
+		return User{}, "", err
+	}
+
+	if err := app.Validate(); err != nil {
+		return User{}, "", err
+	}
+
+	uu, err := toBusUpdateUser(app)
+	if err != nil {
+		return User{}, "", errs.New(errs.InvalidArgument, err)
+	}
+
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return User{}, "", errs.Newf(errs.InvalidArgument, "parse: id: %s", err)
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, userbus.ErrNotFound) {
+			return User{}, "", errs.New(errs.NotFound, err)
+		}
+		return User{}, "", errs.Newf(errs.Internal, "querybyid: userID[%s]: %s", userID, err)
+	}
+
+	updUsr, err := a.userBus.Update(ctx, usr, uu)
+	if err != nil {
+		if errors.Is(err, userbus.ErrUniqueEmail) {
+			return User{}, "", errs.New(errs.Aborted, userbus.ErrUniqueEmail)
+		}
+		if errors.Is(err, userbus.ErrModified) {
+			return User{}, "", errs.New(errs.Aborted, userbus.ErrModified)
+		}
+		return User{}, "", errs.Newf(errs.Internal, "update: userID[%s] uu[%+v]: %s", userID, uu, err)
+	}
+
+	return toAppUser(updUsr), updUsr.ID.String(), nil
+}
+
+func (a *App) batchCreateProduct(ctx context.Context, data []byte) (Product, string, error) {
+	var app BatchNewProduct
+	if err := decodeOperation(data, &app); err != nil {
+		return Product{}, "", err
+	}
+
+	if err := errs.Check(app); err != nil {
+		return Product{}, "", errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
+	}
+
+	np, err := toBusNewProduct(app.NewProduct)
+	if err != nil {
+		return Product{}, "", errs.New(errs.InvalidArgument, err)
+	}
+
+	np.UserID, err = uuid.Parse(app.UserID)
+	if err != nil {
+		return Product{}, "", errs.Newf(errs.InvalidArgument, "parse: userID: %s", err)
+	}
+
+	prd, err := a.productBus.Create(ctx, np)
+	if err != nil {
+		if errors.Is(err, productbus.ErrUserDisabled) {
+			return Product{}, "", errs.New(errs.Aborted, err)
+		}
+		return Product{}, "", errs.Newf(errs.Internal, "create: prd[%+v]: %s", prd, err)
+	}
+
+	return toAppProduct(prd), prd.ID.String(), nil
+}
+
+func (a *App) batchUpdateProduct(ctx context.Context, id string, data []byte) (Product, string, error) {
+	var app UpdateProduct
+	if err := decodeOperation(data, &app); err != nil {
+		return Product{}, "", err
+	}
+
+	if err := app.Validate(); err != nil {
+		return Product{}, "", err
+	}
+
+	up, err := toBusUpdateProduct(app)
+	if err != nil {
+		return Product{}, "", errs.New(errs.InvalidArgument, err)
+	}
+
+	productID, err := uuid.Parse(id)
+	if err != nil {
+		return Product{}, "", errs.Newf(errs.InvalidArgument, "parse: id: %s", err)
+	}
+
+	prd, err := a.productBus.QueryByID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, productbus.ErrNotFound) {
+			return Product{}, "", errs.New(errs.NotFound, err)
+		}
+		return Product{}, "", errs.Newf(errs.Internal, "querybyid: productID[%s]: %s", productID, err)
+	}
+
+	updPrd, err := a.productBus.Update(ctx, prd, up)
+	if err != nil {
+		return Product{}, "", errs.Newf(errs.Internal, "update: productID[%s] up[%+v]: %s", productID, up, err)
+	}
+
+	return toAppProduct(updPrd), updPrd.ID.String(), nil
+}
+
+// =============================================================================
+
+func decodeOperation(data []byte, v any) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return errs.Newf(errs.InvalidArgument, "decode: %s", err)
+	}
+
+	return nil
+}
+
+// operationError identifies the operation that failed while preserving
+// the code of the original error.
+func operationError(i int, err error) *errs.Error {
+	var appErr *errs.Error
+	if errors.As(err, &appErr) {
+		return errs.Newf(appErr.Code, "operation[%d]: %s", i, appErr.Message)
+	}
+
+	return errs.Newf(errs.Internal, "operation[%d]: %s", i, err)
+}
+
+// resolveRefs replaces the "${ref}" values in the id and data of the
+// operation with the ids of the previous operations.
+func resolveRefs(op BatchOperation, refs map[string]string) (string, []byte, error) {
+	id, err := resolveRef(op.ID, refs)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The numbers are kept as they were sent so large integers aren't
+	// rounded when the data is encoded again.
+	var data any
+	if err := decode.JSON(op.Data, &data, decode.UseNumber()); err != nil {
+		return "", nil, fmt.Errorf("decode: %w", err)
+	}
+
+	data, err = resolveValue(data, refs)
+	if err != nil {
+		return "", nil, err
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("encode: %w", err)
+	}
+
+	return id, raw, nil
+}
+
+func resolveValue(v any, refs map[string]string) (any, error) {
+	switch v := v.(type) {
+	case string:
+		return resolveRef(v, refs)
+
+	case map[string]any:
+		for key, val := range v {
+			rv, err := resolveValue(val, refs)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = rv
+		}
+
+	case []any:
+		for i, val := range v {
+			rv, err := resolveValue(val, refs)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = rv
+		}
+	}
+
+	return v, nil
+}
+
+func resolveRef(s string, refs map[string]string) (string, error) {
+	if !strings.HasPrefix(s, "${") || !strings.HasSuffix(s, "}") {
+		return s, nil
+	}
+
+	ref := s[2 : len(s)-1]
+
+	id, exists := refs[ref]
+	if !exists {
+		return "", fmt.Errorf("unknown ref %q", ref)
+	}
+
+	return id, nil
+}
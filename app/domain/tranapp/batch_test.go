@@ -0,0 +1,102 @@
+package tranapp_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/mail"
+	"testing"
+
+	"github.com/ardanlabs/service/app/domain/tranapp"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+)
+
+func Test_BatchIndependent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	userBus := userbus.NewBusiness(log, delegate.New(log), usermem.NewStore())
+	app := tranapp.NewApp(userBus, nil)
+
+	op := func(ref string, name string, id string, data any) tranapp.BatchOperation {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			t.Fatalf("Should be able to marshal the data : %s", err)
+		}
+		return tranapp.BatchOperation{Ref: ref, Op: name, ID: id, Data: raw}
+	}
+
+	newUser := func(name string, email string) tranapp.NewUser {
+		return tranapp.NewUser{
+			Name:            name,
+			Email:           email,
+			Roles:           []string{"USER"},
+			Department:      "IT",
+			Password:        "123",
+			PasswordConfirm: "123",
+		}
+	}
+
+	batch := tranapp.Batch{
+		Operations: []tranapp.BatchOperation{
+			op("jill", tranapp.OpCreateUser, "", newUser("Jill Kennedy", "jill@ardanlabs.com")),
+			op("bad", tranapp.OpCreateUser, "", newUser("Bad Email", "not-an-email")),
+			op("", tranapp.OpUpdateUser, "${jill}", map[string]any{"department": "Sales"}),
+			op("", tranapp.OpUpdateUser, uuid.NewString(), map[string]any{"department": "Sales"}),
+			op("", tranapp.OpUpdateUser, "${bad}", map[string]any{"department": "Sales"}),
+			op("dup", tranapp.OpCreateUser, "", newUser("Jill Kennedy", "jill@ardanlabs.com")),
+		},
+	}
+
+	ms := app.BatchIndependent(ctx, batch)
+
+	if ms.HTTPStatus() != http.StatusMultiStatus {
+		t.Errorf("Should respond with a multi-status : %d", ms.HTTPStatus())
+	}
+
+	exp := []int{
+		http.StatusCreated,
+		http.StatusBadRequest,
+		http.StatusOK,
+		http.StatusNotFound,
+		http.StatusBadRequest,
+		http.StatusConflict,
+	}
+
+	if len(ms.Results) != len(exp) {
+		t.Fatalf("Should get a result per operation, got %d, exp %d", len(ms.Results), len(exp))
+	}
+
+	for i, status := range exp {
+		res := ms.Results[i]
+
+		if res.Status != status {
+			t.Errorf("Should get status %d for operation %d, got %d : %v", status, i, res.Status, res.Error)
+		}
+
+		switch {
+		case status < 300 && (res.Error != nil || res.ID == "" || res.Result == nil):
+			t.Errorf("Should get the result of operation %d : %+v", i, res)
+		case status >= 300 && (res.Error == nil || res.Result != nil):
+			t.Errorf("Should get the error of operation %d : %+v", i, res)
+		}
+	}
+
+	// The failures didn't undo the successful operations.
+
+	usr, err := userBus.QueryByEmail(ctx, mail.Address{Address: "jill@ardanlabs.com"})
+	if err != nil {
+		t.Fatalf("Should be able to query the created user : %s", err)
+	}
+
+	if usr.Department != "Sales" {
+		t.Errorf("Should have updated the created user : %s", usr.Department)
+	}
+}
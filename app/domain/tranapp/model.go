@@ -3,28 +3,32 @@ package tranapp
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/mail"
 	"time"
 
+	"github.com/ardanlabs/service/app/sdk/decode"
+	"github.com/ardanlabs/service/app/sdk/encode"
 	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/money"
 )
 
 // Product represents an individual product.
 type Product struct {
-	ID          string  `json:"id"`
-	UserID      string  `json:"userID"`
-	Name        string  `json:"name"`
-	Cost        float64 `json:"cost"`
-	Quantity    int     `json:"quantity"`
-	DateCreated string  `json:"dateCreated"`
-	DateUpdated string  `json:"dateUpdated"`
+	ID          string      `json:"id"`
+	UserID      string      `json:"userID"`
+	Name        string      `json:"name"`
+	Cost        money.Money `json:"cost"`
+	Quantity    int         `json:"quantity"`
+	DateCreated string      `json:"dateCreated"`
+	DateUpdated string      `json:"dateUpdated"`
 }
 
 // Encode implements the encoder interface.
 func (app Product) Encode() ([]byte, string, error) {
-	data, err := json.Marshal(app)
+	data, err := encode.JSON(app)
 	return data, "application/json", err
 }
 
@@ -52,7 +56,7 @@ type NewTran struct {
 // Validate checks the data in the model is considered clean.
 func (app NewTran) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
@@ -60,7 +64,7 @@ func (app NewTran) Validate() error {
 
 // Decode implements the decoder interface.
 func (app *NewTran) Decode(data []byte) error {
-	return json.Unmarshal(data, &app)
+	return decode.JSON(data, &app)
 }
 
 // =============================================================================
@@ -78,7 +82,7 @@ type NewUser struct {
 // Validate checks the data in the model is considered clean.
 func (app NewUser) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
@@ -115,15 +119,15 @@ func toBusNewUser(app NewUser) (userbus.NewUser, error) {
 
 // NewProduct is what we require from clients when adding a Product.
 type NewProduct struct {
-	Name     string  `json:"name" validate:"required"`
-	Cost     float64 `json:"cost" validate:"required,gte=0"`
-	Quantity int     `json:"quantity" validate:"required,gte=1"`
+	Name     string      `json:"name" validate:"required"`
+	Cost     money.Money `json:"cost" validate:"required,gte=0"`
+	Quantity int         `json:"quantity" validate:"required,gte=1"`
 }
 
 // Validate checks the data in the model is considered clean.
 func (app NewProduct) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
@@ -143,3 +147,216 @@ func toBusNewProduct(app NewProduct) (productbus.NewProduct, error) {
 
 	return bus, nil
 }
+
+// =============================================================================
+
+// User represents information about an individual user.
+type User struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Email       string   `json:"email"`
+	Roles       []string `json:"roles"`
+	Department  string   `json:"department"`
+	Enabled     bool     `json:"enabled"`
+	DateCreated string   `json:"dateCreated"`
+	DateUpdated string   `json:"dateUpdated"`
+}
+
+func toAppUser(bus userbus.User) User {
+	return User{
+		ID:          bus.ID.String(),
+		Name:        bus.Name.String(),
+		Email:       bus.Email.Address,
+		Roles:       userbus.ParseRolesToString(bus.Roles),
+		Department:  bus.Department,
+		Enabled:     bus.Enabled,
+		DateCreated: bus.DateCreated.Format(time.RFC3339),
+		DateUpdated: bus.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+// UpdateUser contains information needed to update a user.
+type UpdateUser struct {
+	Name            *string `json:"name"`
+	Email           *string `json:"email" validate:"omitempty,email"`
+	Department      *string `json:"department"`
+	Password        *string `json:"password"`
+	PasswordConfirm *string `json:"passwordConfirm" validate:"omitempty,eqfield=Password"`
+	Enabled         *bool   `json:"enabled"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app UpdateUser) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
+
+func toBusUpdateUser(app UpdateUser) (userbus.UpdateUser, error) {
+	var addr *mail.Address
+	if app.Email != nil {
+		var err error
+		addr, err = mail.ParseAddress(*app.Email)
+		if err != nil {
+			return userbus.UpdateUser{}, fmt.Errorf("parse: %w", err)
+		}
+	}
+
+	var name *userbus.Name
+	if app.Name != nil {
+		nm, err := userbus.ParseName(*app.Name)
+		if err != nil {
+			return userbus.UpdateUser{}, fmt.Errorf("parse: %w", err)
+		}
+		name = &nm
+	}
+
+	bus := userbus.UpdateUser{
+		Name:       name,
+		Email:      addr,
+		Department: app.Department,
+		Password:   app.Password,
+		Enabled:    app.Enabled,
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+// BatchNewProduct is what we require from clients when adding a Product
+// as part of a batch.
+type BatchNewProduct struct {
+	UserID string `json:"userID" validate:"required"`
+	NewProduct
+}
+
+// UpdateProduct defines what information may be provided to modify an
+// existing Product.
+type UpdateProduct struct {
+	Name     *string      `json:"name"`
+	Cost     *money.Money `json:"cost" validate:"omitempty,gte=0"`
+	Quantity *int         `json:"quantity" validate:"omitempty,gte=1"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app UpdateProduct) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
+
+func toBusUpdateProduct(app UpdateProduct) (productbus.UpdateProduct, error) {
+	var name *productbus.Name
+	if app.Name != nil {
+		nm, err := productbus.ParseName(*app.Name)
+		if err != nil {
+			return productbus.UpdateProduct{}, fmt.Errorf("parse: %w", err)
+		}
+		name = &nm
+	}
+
+	bus := productbus.UpdateProduct{
+		Name:     name,
+		Cost:     app.Cost,
+		Quantity: app.Quantity,
+	}
+
+	return bus, nil
+}
+
+// =============================================================================
+
+// Set of operations that can be performed in a batch.
+const (
+	OpCreateUser    = "createUser"
+	OpUpdateUser    = "updateUser"
+	OpCreateProduct = "createProduct"
+	OpUpdateProduct = "updateProduct"
+)
+
+// BatchOperation represents a single operation in a batch. The Ref is an
+// optional name that later operations can use to reference the id of the
+// entity this operation created or updated. A string value in the form of
+// "${ref}" in the ID or Data is replaced with that id.
+type BatchOperation struct {
+	Ref  string          `json:"ref"`
+	Op   string          `json:"op" validate:"required,oneof=createUser updateUser createProduct updateProduct"`
+	ID   string          `json:"id"`
+	Data json.RawMessage `json:"data" validate:"required"`
+}
+
+// Batch represents a set of operations that are executed in order, under a
+// single transaction unless they are executed independently.
+type Batch struct {
+	Operations []BatchOperation `json:"operations" validate:"required,min=1,dive"`
+}
+
+// Validate checks the data in the model is considered clean.
+func (app Batch) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
+
+// Decode implements the decoder interface.
+func (app *Batch) Decode(data []byte) error {
+	return decode.JSON(data, &app)
+}
+
+// OperationResult represents the result of a single operation in a batch.
+type OperationResult struct {
+	Ref    string          `json:"ref,omitempty"`
+	Op     string          `json:"op"`
+	ID     string          `json:"id"`
+	Result json.RawMessage `json:"result"`
+}
+
+// BatchResult represents the results of the operations in a batch in the
+// order they were executed.
+type BatchResult struct {
+	Results []OperationResult `json:"results"`
+}
+
+// Encode implements the encoder interface.
+func (app BatchResult) Encode() ([]byte, string, error) {
+	data, err := encode.JSON(app)
+	return data, "application/json", err
+}
+
+// OperationStatus represents the outcome of a single operation in a batch
+// where the operations are executed independently. The status is the http
+// status the operation would have on its own.
+type OperationStatus struct {
+	Ref    string          `json:"ref,omitempty"`
+	Op     string          `json:"op"`
+	Status int             `json:"status"`
+	ID     string          `json:"id,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *errs.Error     `json:"error,omitempty"`
+}
+
+// MultiStatus represents the outcome of every operation in a batch where
+// the operations are executed independently, in the order they were
+// executed.
+type MultiStatus struct {
+	Results []OperationStatus `json:"results"`
+}
+
+// Encode implements the encoder interface.
+func (app MultiStatus) Encode() ([]byte, string, error) {
+	data, err := encode.JSON(app)
+	return data, "application/json", err
+}
+
+// HTTPStatus implements the web package httpStatus interface so the
+// response is sent as a multi-status.
+func (app MultiStatus) HTTPStatus() int {
+	return http.StatusMultiStatus
+}
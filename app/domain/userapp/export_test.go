@@ -0,0 +1,256 @@
+package userapp_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/app/domain/userapp"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_Export(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	userBus := userbus.NewBusiness(log, delegate.New(log), usermem.NewStore())
+	app := userapp.NewApp(userBus)
+
+	users := []userbus.NewUser{
+		{
+			Name:       userbus.MustParseName("Bill Kennedy"),
+			Email:      mail.Address{Address: "bill@example.com"},
+			Roles:      []userbus.Role{userbus.Roles.Admin, userbus.Roles.User},
+			Department: "Sales, \"West\"\nRegion",
+			Password:   "gophers",
+		},
+		{
+			Name:     userbus.MustParseName("Ale Kennedy"),
+			Email:    mail.Address{Address: "ale@example.com"},
+			Roles:    []userbus.Role{userbus.Roles.User},
+			Password: "gophers",
+		},
+	}
+
+	for _, nu := range users {
+		if _, err := userBus.Create(ctx, nu); err != nil {
+			t.Fatalf("Should be able to create a user : %s", err)
+		}
+	}
+
+	// -------------------------------------------------------------------------
+
+	fields := []string{"email", "roles", "department", "enabled"}
+
+	exp, err := app.Export(userapp.QueryParams{OrderBy: "email", Fields: strings.Join(fields, ",")})
+	if err != nil {
+		t.Fatalf("Should be able to export the users : %s", err)
+	}
+
+	if !slices.Equal(exp.Fields, fields) {
+		t.Fatalf("Should get the selected fields, got %v, exp %v", exp.Fields, fields)
+	}
+
+	wa := web.NewApp(func(context.Context, string, ...any) {}, nil)
+	wa.HandlerFunc(http.MethodGet, "v1", "/export", func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		if web.Negotiate(r, "application/x-ndjson", "text/csv") == "text/csv" {
+			return web.CSV[userapp.ExportRow]{Header: exp.Fields, Stream: exp.Stream}, nil
+		}
+		return web.NDJSON[userapp.ExportRow]{Stream: exp.Stream}, nil
+	})
+
+	srv := httptest.NewServer(wa)
+	defer srv.Close()
+
+	// The same rows are expected in both formats, in the order of the
+	// selected fields.
+	want := [][]string{
+		{"ale@example.com", "USER", "", "true"},
+		{"bill@example.com", "ADMIN,USER", "Sales, \"West\"\nRegion", "true"},
+	}
+
+	// -------------------------------------------------------------------------
+
+	resp := get(t, srv.URL+"/v1/export", "application/x-ndjson")
+	defer resp.Body.Close()
+
+	var got [][]string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		keys, err := objectKeys(scanner.Bytes())
+		if err != nil {
+			t.Fatalf("Should be able to parse the line : %s : %q", err, scanner.Text())
+		}
+
+		if !slices.Equal(keys, fields) {
+			t.Fatalf("Should only get the selected fields in order, got %v, exp %v", keys, fields)
+		}
+
+		var row struct {
+			Email      string   `json:"email"`
+			Roles      []string `json:"roles"`
+			Department string   `json:"department"`
+			Enabled    bool     `json:"enabled"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			t.Fatalf("Should be able to unmarshal the line : %s", err)
+		}
+
+		got = append(got, []string{row.Email, strings.Join(row.Roles, ","), row.Department, strconv.FormatBool(row.Enabled)})
+	}
+
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Should be able to read the ndjson export : %s", err)
+	}
+
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("Should get the users as ndjson, got %q, exp %q", got, want)
+	}
+
+	// -------------------------------------------------------------------------
+
+	resp = get(t, srv.URL+"/v1/export", "text/csv")
+	defer resp.Body.Close()
+
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Should be able to parse the csv export : %s", err)
+	}
+
+	if len(records) == 0 || !slices.Equal(records[0], fields) {
+		t.Fatalf("Should get the selected fields as the header row : %q", records)
+	}
+
+	if !slices.EqualFunc(records[1:], want, slices.Equal) {
+		t.Errorf("Should get the users as csv, got %q, exp %q", records[1:], want)
+	}
+
+	// -------------------------------------------------------------------------
+
+	for _, fields := range []string{"password", "email,email"} {
+		if _, err := app.Export(userapp.QueryParams{Fields: fields}); err == nil {
+			t.Errorf("Should not be able to export the fields %q", fields)
+		}
+	}
+}
+
+func Test_ExportFormula(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	userBus := userbus.NewBusiness(log, delegate.New(log), usermem.NewStore())
+	app := userapp.NewApp(userBus)
+
+	departments := map[string]string{
+		"=HYPERLINK(\"http://example.com\")": "'=HYPERLINK(\"http://example.com\")",
+		"+1":                                 "'+1",
+		"-1":                                 "'-1",
+		"@SUM(A1)":                           "'@SUM(A1)",
+		"Sales":                              "Sales",
+	}
+
+	var i int
+	for department := range departments {
+		i++
+		nu := userbus.NewUser{
+			Name:       userbus.MustParseName("Bill Kennedy"),
+			Email:      mail.Address{Address: fmt.Sprintf("bill%d@example.com", i)},
+			Roles:      []userbus.Role{userbus.Roles.User},
+			Department: department,
+			Password:   "gophers",
+		}
+
+		if _, err := userBus.Create(ctx, nu); err != nil {
+			t.Fatalf("Should be able to create a user : %s", err)
+		}
+	}
+
+	exp, err := app.Export(userapp.QueryParams{Fields: "department"})
+	if err != nil {
+		t.Fatalf("Should be able to export the users : %s", err)
+	}
+
+	var got []string
+	send := func(row userapp.ExportRow) error {
+		got = append(got, row.CSVRecord()[0])
+		return nil
+	}
+
+	if err := exp.Stream(ctx, send); err != nil {
+		t.Fatalf("Should be able to stream the users : %s", err)
+	}
+
+	var want []string
+	for _, escaped := range departments {
+		want = append(want, escaped)
+	}
+
+	slices.Sort(got)
+	slices.Sort(want)
+
+	if !slices.Equal(got, want) {
+		t.Errorf("Should escape the values that start like a formula, got %q, exp %q", got, want)
+	}
+}
+
+func get(t *testing.T, url string, accept string) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("Should be able to create the request : %s", err)
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Should be able to make the request : %s", err)
+	}
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, accept) {
+		resp.Body.Close()
+		t.Fatalf("Should get the %s content type : %s", accept, ct)
+	}
+
+	return resp
+}
+
+// objectKeys returns the names of the JSON object in the order they appear.
+func objectKeys(data []byte) ([]string, error) {
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, tok.(string))
+
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
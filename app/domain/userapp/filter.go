@@ -1,14 +1,26 @@
 package userapp
 
 import (
+	"encoding/json"
 	"net/mail"
+	"strings"
 	"time"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/business/domain/userbus"
+	sdkfilter "github.com/ardanlabs/service/business/sdk/filter"
 	"github.com/google/uuid"
 )
 
+var filterFields = map[string]sdkfilter.Field{
+	"name":         {Name: userbus.FilterByName, Type: sdkfilter.String},
+	"email":        {Name: userbus.FilterByEmail, Type: sdkfilter.String},
+	"department":   {Name: userbus.FilterByDepartment, Type: sdkfilter.String},
+	"enabled":      {Name: userbus.FilterByEnabled, Type: sdkfilter.Bool},
+	"date_created": {Name: userbus.FilterByDateCreated, Type: sdkfilter.Time},
+	"date_updated": {Name: userbus.FilterByDateUpdated, Type: sdkfilter.Time},
+}
+
 func parseFilter(qp QueryParams) (userbus.QueryFilter, error) {
 	var filter userbus.QueryFilter
 
@@ -52,5 +64,33 @@ func parseFilter(qp QueryParams) (userbus.QueryFilter, error) {
 		filter.EndCreatedDate = &t
 	}
 
+	if qp.Metadata != "" {
+		var md userbus.Metadata
+		if err := json.Unmarshal([]byte(qp.Metadata), &md); err != nil {
+			return userbus.QueryFilter{}, errs.NewFieldsError("metadata", err)
+		}
+		filter.Metadata = md
+	}
+
+	if qp.Role != "" {
+		role, err := userbus.ParseRole(qp.Role)
+		if err != nil {
+			return userbus.QueryFilter{}, errs.NewFieldsError("role", err)
+		}
+		filter.Role = &role
+	}
+
+	if qp.Tags != "" {
+		filter.Tags = strings.Split(qp.Tags, ",")
+	}
+
+	if len(qp.Filter) > 0 {
+		expr, err := sdkfilter.Parse(filterFields, qp.Filter)
+		if err != nil {
+			return userbus.QueryFilter{}, errs.NewFieldsError("filter", err)
+		}
+		filter.Expr = expr
+	}
+
 	return filter, nil
 }
@@ -0,0 +1,417 @@
+package userapp
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/ardanlabs/service/app/sdk/encode"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+)
+
+// importColumns lists the columns of an import file. The name, email, roles
+// and password columns are required and roles are comma-separated.
+var importColumns = []string{"name", "email", "roles", "department", "password"}
+
+// errImportFailed is used to roll back a strict import when a row fails.
+var errImportFailed = errors.New("import failed")
+
+// ImportFailure represents a row of an import that wasn't imported. The
+// header is row 1.
+type ImportFailure struct {
+	Row    int    `json:"row"`
+	Reason string `json:"reason"`
+}
+
+// ImportReport represents the outcome of an import.
+type ImportReport struct {
+	Imported int             `json:"imported"`
+	Failed   []ImportFailure `json:"failed"`
+}
+
+// Encode implements the encoder interface.
+func (app ImportReport) Encode() ([]byte, string, error) {
+	data, err := encode.JSON(app)
+	return data, "application/json", err
+}
+
+// =============================================================================
+
+// Importer manages the set of app layer api functions for bulk loading users.
+type Importer struct {
+	userBus   *userbus.Business
+	trn       sqldb.Executor
+	batchSize int
+}
+
+// NewImporter constructs an importer that inserts users in transactions of
+// batchSize rows. The transactions may be run again by an executor that
+// retries them.
+func NewImporter(userBus *userbus.Business, trn sqldb.Executor, batchSize int) *Importer {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	return &Importer{
+		userBus:   userBus,
+		trn:       trn,
+		batchSize: batchSize,
+	}
+}
+
+// Import reads users from the CSV data and inserts them, reporting the rows
+// that failed. The first row must be a header naming the columns. In strict
+// mode, nothing is imported when any row fails. Otherwise, the valid rows
+// are imported in batches and a failure only affects its own row.
+func (imp *Importer) Import(ctx context.Context, r io.Reader, strict bool) (ImportReport, error) {
+	rr, err := newRowReader(r)
+	if err != nil {
+		return ImportReport{}, errs.New(errs.InvalidArgument, err)
+	}
+
+	if strict {
+		return imp.importStrict(ctx, rr)
+	}
+
+	return imp.importLenient(ctx, rr)
+}
+
+func (imp *Importer) importStrict(ctx context.Context, rr *rowReader) (ImportReport, error) {
+	// The rows are read before the transaction starts, so the transaction
+	// can be run again when it's retried.
+	var rows []importRow
+	for {
+		row, err := rr.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return ImportReport{}, errs.Newf(errs.Internal, "import: %s", err)
+		}
+
+		rows = append(rows, row)
+	}
+
+	var report ImportReport
+
+	f := func(ctx context.Context, tx sqldb.CommitRollbacker) error {
+		report = ImportReport{}
+
+		userBus, err := imp.userBus.NewWithTx(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if row.reason != "" {
+				report.Failed = append(report.Failed, ImportFailure{Row: row.row, Reason: row.reason})
+				continue
+			}
+
+			// Nothing is committed once a row fails, so the remaining rows
+			// are only validated for the report.
+			if len(report.Failed) > 0 {
+				continue
+			}
+
+			if err := imp.create(ctx, userBus, row, &report); err != nil {
+				return err
+			}
+		}
+
+		if len(report.Failed) > 0 {
+			return errImportFailed
+		}
+
+		return nil
+	}
+
+	if err := imp.trn.Execute(ctx, f); err != nil {
+		if errors.Is(err, errImportFailed) {
+			report.Imported = 0
+			return report, nil
+		}
+		return ImportReport{}, errs.Newf(errs.Internal, "import: %s", err)
+	}
+
+	return report, nil
+}
+
+func (imp *Importer) importLenient(ctx context.Context, rr *rowReader) (ImportReport, error) {
+	var report ImportReport
+
+	batch := make([]importRow, 0, imp.batchSize)
+
+	for {
+		row, err := rr.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return ImportReport{}, errs.Newf(errs.Internal, "import: %s", err)
+		}
+
+		if row.reason != "" {
+			report.Failed = append(report.Failed, ImportFailure{Row: row.row, Reason: row.reason})
+			continue
+		}
+
+		batch = append(batch, row)
+		if len(batch) < imp.batchSize {
+			continue
+		}
+
+		if err := imp.insertBatch(ctx, batch, &report); err != nil {
+			return ImportReport{}, errs.Newf(errs.Internal, "import: %s", err)
+		}
+
+		batch = batch[:0]
+	}
+
+	if err := imp.insertBatch(ctx, batch, &report); err != nil {
+		return ImportReport{}, errs.Newf(errs.Internal, "import: %s", err)
+	}
+
+	// Rows that failed to insert are found after the invalid rows that
+	// follow them in the file.
+	slices.SortFunc(report.Failed, func(a, b ImportFailure) int {
+		return a.Row - b.Row
+	})
+
+	return report, nil
+}
+
+// insertBatch inserts the rows under a single transaction. When a row fails,
+// the transaction is rolled back and every row is retried on its own so the
+// failure doesn't affect the valid rows.
+func (imp *Importer) insertBatch(ctx context.Context, batch []importRow, report *ImportReport) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	var batchReport ImportReport
+
+	f := func(ctx context.Context, tx sqldb.CommitRollbacker) error {
+		batchReport = ImportReport{}
+
+		userBus, err := imp.userBus.NewWithTx(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range batch {
+			if err := imp.create(ctx, userBus, row, &batchReport); err != nil {
+				return err
+			}
+
+			if len(batchReport.Failed) > 0 {
+				return errImportFailed
+			}
+		}
+
+		return nil
+	}
+
+	err := imp.trn.Execute(ctx, f)
+	if err == nil {
+		report.Imported += batchReport.Imported
+		return nil
+	}
+
+	if !errors.Is(err, errImportFailed) {
+		return err
+	}
+
+	for _, row := range batch {
+		var rowReport ImportReport
+
+		f := func(ctx context.Context, tx sqldb.CommitRollbacker) error {
+			rowReport = ImportReport{}
+
+			userBus, err := imp.userBus.NewWithTx(tx)
+			if err != nil {
+				return err
+			}
+
+			return imp.create(ctx, userBus, row, &rowReport)
+		}
+
+		if err := imp.trn.Execute(ctx, f); err != nil {
+			return err
+		}
+
+		report.Imported += rowReport.Imported
+		report.Failed = append(report.Failed, rowReport.Failed...)
+	}
+
+	return nil
+}
+
+// create inserts the user for the row. A row the business layer rejects is
+// recorded as a failure and any other error is returned.
+func (imp *Importer) create(ctx context.Context, userBus *userbus.Business, row importRow, report *ImportReport) error {
+	if _, err := userBus.Create(ctx, row.nu); err != nil {
+		if errors.Is(err, userbus.ErrUniqueEmail) {
+			report.Failed = append(report.Failed, ImportFailure{Row: row.row, Reason: userbus.ErrUniqueEmail.Error()})
+			return nil
+		}
+		return fmt.Errorf("create: row[%d]: %w", row.row, err)
+	}
+
+	report.Imported++
+
+	return nil
+}
+
+// =============================================================================
+
+// importRow represents a row of an import file. The reason is set when the
+// row is invalid.
+type importRow struct {
+	row    int
+	nu     userbus.NewUser
+	reason string
+}
+
+// rowReader reads and validates the rows of an import file one at a time.
+type rowReader struct {
+	cr      *csv.Reader
+	columns map[string]int
+	row     int
+	done    bool
+}
+
+func newRowReader(r io.Reader) (*rowReader, error) {
+	cr := csv.NewReader(r)
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("missing header row")
+		}
+		return nil, fmt.Errorf("header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+
+		if !isImportColumn(name) {
+			return nil, fmt.Errorf("header: unknown column %q", name)
+		}
+
+		if _, exists := columns[name]; exists {
+			return nil, fmt.Errorf("header: duplicate column %q", name)
+		}
+
+		columns[name] = i
+	}
+
+	for _, name := range []string{"name", "email", "roles", "password"} {
+		if _, exists := columns[name]; !exists {
+			return nil, fmt.Errorf("header: missing column %q", name)
+		}
+	}
+
+	rr := rowReader{
+		cr:      cr,
+		columns: columns,
+		row:     1,
+	}
+
+	return &rr, nil
+}
+
+// next returns the next row of the file or io.EOF when there are no more.
+// A row with the wrong number of fields is reported as invalid. Other
+// malformed data stops the import at the row it was found on.
+func (rr *rowReader) next() (importRow, error) {
+	if rr.done {
+		return importRow{}, io.EOF
+	}
+
+	record, err := rr.cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return importRow{}, io.EOF
+		}
+
+		var perr *csv.ParseError
+		if !errors.As(err, &perr) {
+			return importRow{}, fmt.Errorf("read: %w", err)
+		}
+
+		rr.row++
+
+		if !errors.Is(err, csv.ErrFieldCount) {
+			rr.done = true
+		}
+
+		return importRow{row: rr.row, reason: err.Error()}, nil
+	}
+
+	rr.row++
+
+	app := NewUser{
+		Name:       rr.field(record, "name"),
+		Email:      rr.field(record, "email"),
+		Department: rr.field(record, "department"),
+		Password:   rr.field(record, "password"),
+	}
+	app.PasswordConfirm = app.Password
+
+	if roles := rr.field(record, "roles"); roles != "" {
+		app.Roles = strings.Split(roles, ",")
+	}
+
+	if err := errs.Check(app); err != nil {
+		return importRow{row: rr.row, reason: importReason(err)}, nil
+	}
+
+	nu, err := toBusNewUser(app)
+	if err != nil {
+		return importRow{row: rr.row, reason: err.Error()}, nil
+	}
+
+	return importRow{row: rr.row, nu: nu}, nil
+}
+
+func (rr *rowReader) field(record []string, name string) string {
+	i, exists := rr.columns[name]
+	if !exists {
+		return ""
+	}
+
+	return strings.TrimSpace(record[i])
+}
+
+func isImportColumn(name string) bool {
+	for _, column := range importColumns {
+		if column == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// importReason converts a validation error into a readable reason.
+func importReason(err error) string {
+	fields := errs.GetFieldErrors(err)
+	if fields == nil {
+		return err.Error()
+	}
+
+	reasons := make([]string, len(fields))
+	for i, fld := range fields {
+		reasons[i] = fld.Err
+	}
+
+	return strings.Join(reasons, "; ")
+}
@@ -0,0 +1,128 @@
+package userapp_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/app/domain/userapp"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/go-cmp/cmp"
+)
+
+// nopBeginner begins transactions for the memory store, which doesn't
+// support them.
+type nopBeginner struct{}
+
+func (nopBeginner) Begin() (sqldb.CommitRollbacker, error) { return nopTx{}, nil }
+
+type nopTx struct{}
+
+func (nopTx) Commit() error   { return nil }
+func (nopTx) Rollback() error { return nil }
+
+func newImporter() (*userapp.Importer, *userbus.Business) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	userBus := userbus.NewBusiness(log, delegate.New(log), usermem.NewStore())
+	importer := userapp.NewImporter(userBus, sqldb.NewTransactor(log, nopBeginner{}), 2)
+
+	return importer, userBus
+}
+
+func Test_Import(t *testing.T) {
+	t.Parallel()
+
+	const clean = `name,email,roles,department,password
+Bill Kennedy,bill@example.com,"ADMIN,USER",IT,gophers
+Ale Kennedy,ale@example.com,USER,,gophers
+Jack Kennedy,jack@example.com,USER,"Sales, ""West""",gophers
+Lisa Kennedy,lisa@example.com,USER,,gophers
+Mike Kennedy,mike@example.com,ADMIN,,gophers
+`
+
+	const bad = `email,name,password,roles
+bill@example.com,Bill Kennedy,gophers,USER
+not-an-email,Ale Kennedy,gophers,USER
+jack@example.com,Jack Kennedy,,USER
+lisa@example.com,Lisa Kennedy,gophers,SUPER
+mike@example.com,Mike Kennedy,gophers
+sam@example.com,Sam Kennedy,gophers,USER
+`
+
+	badFailures := []userapp.ImportFailure{
+		{Row: 3, Reason: "email must be a valid email address"},
+		{Row: 4, Reason: "password is a required field"},
+		{Row: 5, Reason: `parse: invalid role "SUPER", must be one of ADMIN, USER`},
+		{Row: 6, Reason: "record on line 6: wrong number of fields"},
+	}
+
+	table := []struct {
+		name   string
+		data   string
+		strict bool
+		exp    userapp.ImportReport
+		users  int
+	}{
+		{name: "clean", data: clean, exp: userapp.ImportReport{Imported: 5}, users: 5},
+		{name: "clean-strict", data: clean, strict: true, exp: userapp.ImportReport{Imported: 5}, users: 5},
+		{name: "bad-rows", data: bad, exp: userapp.ImportReport{Imported: 2, Failed: badFailures}, users: 2},
+		{name: "bad-rows-strict", data: bad, strict: true, exp: userapp.ImportReport{Imported: 0, Failed: badFailures}},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			importer, userBus := newImporter()
+
+			report, err := importer.Import(context.Background(), strings.NewReader(tt.data), tt.strict)
+			if err != nil {
+				t.Fatalf("Should be able to import the users : %s", err)
+			}
+
+			if diff := cmp.Diff(report, tt.exp); diff != "" {
+				t.Fatalf("Should get the expected report : %s", diff)
+			}
+
+			// The memory store can't roll back, so only imports that
+			// committed are checked.
+			if tt.users == 0 {
+				return
+			}
+
+			n, err := userBus.Count(context.Background(), userbus.QueryFilter{})
+			if err != nil {
+				t.Fatalf("Should be able to count the users : %s", err)
+			}
+
+			if n != tt.users {
+				t.Errorf("Should have imported the valid users, got %d, exp %d", n, tt.users)
+			}
+		})
+	}
+}
+
+func Test_ImportHeader(t *testing.T) {
+	t.Parallel()
+
+	table := map[string]string{
+		"empty":     "",
+		"missing":   "name,email,roles\n",
+		"unknown":   "name,email,roles,password,salary\n",
+		"duplicate": "name,email,email,roles,password\n",
+	}
+
+	for name, data := range table {
+		t.Run(name, func(t *testing.T) {
+			importer, _ := newImporter()
+
+			if _, err := importer.Import(context.Background(), strings.NewReader(data), false); err == nil {
+				t.Errorf("Should not be able to import a file with an invalid header")
+			}
+		})
+	}
+}
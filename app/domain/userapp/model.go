@@ -1,12 +1,16 @@
 package userapp
 
 import (
-	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/mail"
+	"strconv"
 	"time"
 
+	"github.com/ardanlabs/service/app/sdk/decode"
+	"github.com/ardanlabs/service/app/sdk/encode"
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/etag"
 	"github.com/ardanlabs/service/business/domain/userbus"
 )
 
@@ -20,41 +24,100 @@ type QueryParams struct {
 	Email            string
 	StartCreatedDate string
 	EndCreatedDate   string
+	Metadata         string
+	Role             string
+	Tags             string
+	Filter           []string
+	Fields           string
 }
 
 // =============================================================================
 
 // User represents information about an individual user.
 type User struct {
-	ID           string   `json:"id"`
-	Name         string   `json:"name"`
-	Email        string   `json:"email"`
-	Roles        []string `json:"roles"`
-	PasswordHash []byte   `json:"-"`
-	Department   string   `json:"department"`
-	Enabled      bool     `json:"enabled"`
-	DateCreated  string   `json:"dateCreated"`
-	DateUpdated  string   `json:"dateUpdated"`
+	ID            string         `json:"id"`
+	Name          string         `json:"name"`
+	Email         string         `json:"email"`
+	Roles         []string       `json:"roles"`
+	PasswordHash  []byte         `json:"-"`
+	Department    string         `json:"department"`
+	Enabled       bool           `json:"enabled"`
+	EmailVerified bool           `json:"emailVerified"`
+	Metadata      map[string]any `json:"metadata"`
+	Tags          []string       `json:"tags"`
+	DateCreated   string         `json:"dateCreated"`
+	DateUpdated   string         `json:"dateUpdated"`
+	ETag          string         `json:"-"`
+	LastModified  time.Time      `json:"-"`
 }
 
 // Encode implements the encoder interface.
 func (app User) Encode() ([]byte, string, error) {
-	data, err := json.Marshal(app)
+	data, err := encode.JSON(app)
 	return data, "application/json", err
 }
 
+// HTTPHeader returns the entity tag and last modified time of the user so
+// clients can make conditional requests.
+func (app User) HTTPHeader() http.Header {
+	h := make(http.Header)
+
+	if app.ETag != "" {
+		h.Set("ETag", app.ETag)
+	}
+
+	if !app.LastModified.IsZero() {
+		h.Set("Last-Modified", app.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	return h
+}
+
+// ETag returns the entity tag for the current version of the user.
+func ETag(bus userbus.User) string {
+
+	// The database rounds the update time to microsecond precision so the
+	// tag is the same for a user read from the cache or the database.
+	return etag.New(bus.ID.String(), strconv.FormatInt(bus.DateUpdated.Round(time.Microsecond).UnixMicro(), 10))
+}
+
 func toAppUser(bus userbus.User) User {
 	return User{
-		ID:           bus.ID.String(),
-		Name:         bus.Name.String(),
-		Email:        bus.Email.Address,
-		Roles:        userbus.ParseRolesToString(bus.Roles),
-		PasswordHash: bus.PasswordHash,
-		Department:   bus.Department,
-		Enabled:      bus.Enabled,
-		DateCreated:  bus.DateCreated.Format(time.RFC3339),
-		DateUpdated:  bus.DateUpdated.Format(time.RFC3339),
+		ID:            bus.ID.String(),
+		Name:          bus.Name.String(),
+		Email:         bus.Email.Address,
+		Roles:         userbus.ParseRolesToString(bus.Roles),
+		PasswordHash:  bus.PasswordHash,
+		Department:    bus.Department,
+		Enabled:       bus.Enabled,
+		EmailVerified: bus.EmailVerified,
+		Metadata:      toAppMetadata(bus.Metadata),
+		Tags:          toAppTags(bus.Tags),
+		DateCreated:   bus.DateCreated.Format(time.RFC3339),
+		DateUpdated:   bus.DateUpdated.Format(time.RFC3339),
+		ETag:          ETag(bus),
+		LastModified:  bus.DateUpdated,
+	}
+}
+
+// toAppMetadata returns the metadata as an object so a user without metadata
+// has an empty object instead of null.
+func toAppMetadata(md userbus.Metadata) map[string]any {
+	if md == nil {
+		return map[string]any{}
+	}
+
+	return md
+}
+
+// toAppTags returns the tags as an array so a user without tags has an empty
+// array instead of null.
+func toAppTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
 	}
+
+	return tags
 }
 
 func toAppUsers(users []userbus.User) []User {
@@ -70,23 +133,25 @@ func toAppUsers(users []userbus.User) []User {
 
 // NewUser defines the data needed to add a new user.
 type NewUser struct {
-	Name            string   `json:"name" validate:"required"`
-	Email           string   `json:"email" validate:"required,email"`
-	Roles           []string `json:"roles" validate:"required"`
-	Department      string   `json:"department"`
-	Password        string   `json:"password" validate:"required"`
-	PasswordConfirm string   `json:"passwordConfirm" validate:"eqfield=Password"`
+	Name            string         `json:"name" validate:"required"`
+	Email           string         `json:"email" validate:"required,email"`
+	Roles           []string       `json:"roles" validate:"required"`
+	Department      string         `json:"department"`
+	Password        string         `json:"password" validate:"required"`
+	PasswordConfirm string         `json:"passwordConfirm" validate:"eqfield=Password"`
+	Metadata        map[string]any `json:"metadata"`
+	Tags            []string       `json:"tags" validate:"dive,required"`
 }
 
 // Decode implements the decoder interface.
 func (app *NewUser) Decode(data []byte) error {
-	return json.Unmarshal(data, &app)
+	return decode.JSON(data, &app)
 }
 
 // Validate checks the data in the model is considered clean.
 func (app NewUser) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
@@ -114,6 +179,8 @@ func toBusNewUser(app NewUser) (userbus.NewUser, error) {
 		Roles:      roles,
 		Department: app.Department,
 		Password:   app.Password,
+		Metadata:   userbus.Metadata(app.Metadata),
+		Tags:       app.Tags,
 	}
 
 	return bus, nil
@@ -128,13 +195,13 @@ type UpdateUserRole struct {
 
 // Decode implements the decoder interface.
 func (app *UpdateUserRole) Decode(data []byte) error {
-	return json.Unmarshal(data, &app)
+	return decode.JSON(data, &app)
 }
 
 // Validate checks the data in the model is considered clean.
 func (app UpdateUserRole) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
@@ -161,23 +228,38 @@ func toBusUpdateUserRole(app UpdateUserRole) (userbus.UpdateUser, error) {
 
 // UpdateUser defines the data needed to update a user.
 type UpdateUser struct {
-	Name            *string `json:"name"`
-	Email           *string `json:"email" validate:"omitempty,email"`
-	Department      *string `json:"department"`
-	Password        *string `json:"password"`
-	PasswordConfirm *string `json:"passwordConfirm" validate:"omitempty,eqfield=Password"`
-	Enabled         *bool   `json:"enabled"`
+	Name            *string        `json:"name"`
+	Email           *string        `json:"email" validate:"omitempty,email"`
+	Department      *string        `json:"department"`
+	Password        *string        `json:"password"`
+	PasswordConfirm *string        `json:"passwordConfirm" validate:"omitempty,eqfield=Password"`
+	Enabled         *bool          `json:"enabled"`
+	Metadata        map[string]any `json:"metadata"`
+	Tags            []string       `json:"tags" validate:"omitempty,dive,required"`
+}
+
+// NewUpdateUser constructs the update representation of the user. It's the
+// document a JSON Patch is applied to.
+func NewUpdateUser(usr User) UpdateUser {
+	return UpdateUser{
+		Name:       &usr.Name,
+		Email:      &usr.Email,
+		Department: &usr.Department,
+		Enabled:    &usr.Enabled,
+		Metadata:   usr.Metadata,
+		Tags:       usr.Tags,
+	}
 }
 
 // Decode implements the decoder interface.
 func (app *UpdateUser) Decode(data []byte) error {
-	return json.Unmarshal(data, &app)
+	return decode.JSON(data, &app)
 }
 
 // Validate checks the data in the model is considered clean.
 func (app UpdateUser) Validate() error {
 	if err := errs.Check(app); err != nil {
-		return errs.Newf(errs.InvalidArgument, "validate: %s", err)
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
 	}
 
 	return nil
@@ -208,7 +290,85 @@ func toBusUpdateUser(app UpdateUser) (userbus.UpdateUser, error) {
 		Department: app.Department,
 		Password:   app.Password,
 		Enabled:    app.Enabled,
+		Metadata:   userbus.Metadata(app.Metadata),
+		Tags:       app.Tags,
 	}
 
 	return bus, nil
 }
+
+// =============================================================================
+
+// VerificationToken represents a token that can be sent to a user to verify
+// their email address.
+type VerificationToken struct {
+	Token     string `json:"token"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// Encode implements the encoder interface.
+func (app VerificationToken) Encode() ([]byte, string, error) {
+	data, err := encode.JSON(app)
+	return data, "application/json", err
+}
+
+// VerifyEmail defines the data needed to verify an email address.
+type VerifyEmail struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Decode implements the decoder interface.
+func (app *VerifyEmail) Decode(data []byte) error {
+	return decode.JSON(data, &app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app VerifyEmail) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// ForgotPassword defines the data needed to request a password reset.
+type ForgotPassword struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// Decode implements the decoder interface.
+func (app *ForgotPassword) Decode(data []byte) error {
+	return decode.JSON(data, &app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app ForgotPassword) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
+
+// ResetPassword defines the data needed to reset a password.
+type ResetPassword struct {
+	Token           string `json:"token" validate:"required"`
+	Password        string `json:"password" validate:"required"`
+	PasswordConfirm string `json:"passwordConfirm" validate:"eqfield=Password"`
+}
+
+// Decode implements the decoder interface.
+func (app *ResetPassword) Decode(data []byte) error {
+	return decode.JSON(data, &app)
+}
+
+// Validate checks the data in the model is considered clean.
+func (app ResetPassword) Validate() error {
+	if err := errs.Check(app); err != nil {
+		return errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", err))
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package userapp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/notify"
+	"github.com/ardanlabs/service/business/domain/userbus"
+)
+
+// ForgotPassword sends a password reset token to the email address. To not
+// leak which email addresses belong to users, the same response is given
+// whether or not a user exists for the email address.
+func (a *App) ForgotPassword(ctx context.Context, app ForgotPassword) error {
+	if a.notifier == nil {
+		return errs.Newf(errs.Unimplemented, "password reset is not configured")
+	}
+
+	addr, err := mail.ParseAddress(app.Email)
+	if err != nil {
+		return errs.Newf(errs.InvalidArgument, "parse email: %s", err)
+	}
+
+	token, pr, err := a.userBus.RequestPasswordReset(ctx, *addr)
+	if err != nil {
+		if errors.Is(err, userbus.ErrNotFound) {
+			return nil
+		}
+		return errs.Newf(errs.Internal, "requestpasswordreset: %s", err)
+	}
+
+	msg := notify.Message{
+		To:      *addr,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("Use this token to reset your password: %s\nThe token expires at %s.", token, pr.ExpiresAt.UTC().Format(time.RFC3339)),
+	}
+
+	if err := a.notifier.Send(ctx, msg); err != nil {
+		return errs.Newf(errs.Internal, "send: userID[%s]: %s", pr.UserID, err)
+	}
+
+	return nil
+}
+
+// ResetPassword consumes a password reset token and sets the new password.
+func (a *App) ResetPassword(ctx context.Context, app ResetPassword) error {
+	if a.notifier == nil {
+		return errs.Newf(errs.Unimplemented, "password reset is not configured")
+	}
+
+	if _, err := a.userBus.ResetPassword(ctx, app.Token, app.Password); err != nil {
+		if errors.Is(err, userbus.ErrInvalidResetToken) || errors.Is(err, userbus.ErrResetTokenExpired) {
+			return errs.New(errs.InvalidArgument, err)
+		}
+		return errs.Newf(errs.Internal, "resetpassword: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package userapp_test
+
+import (
+	"context"
+	"io"
+	"net/mail"
+	"regexp"
+	"testing"
+
+	"github.com/ardanlabs/service/app/domain/userapp"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/notify"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// sender records the messages it's asked to deliver.
+type sender struct {
+	msgs []notify.Message
+}
+
+func (s *sender) Send(ctx context.Context, msg notify.Message) error {
+	s.msgs = append(s.msgs, msg)
+	return nil
+}
+
+func Test_PasswordReset(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	var snd sender
+	userBus := userbus.NewBusiness(log, delegate.New(log), usermem.NewStore())
+	app := userapp.NewAppWithAccountSupport(userBus, nil, &snd)
+
+	usr, err := userBus.Create(ctx, userbus.NewUser{
+		Name:     userbus.MustParseName("Bill Kennedy"),
+		Email:    mail.Address{Address: "bill@example.com"},
+		Roles:    []userbus.Role{userbus.Roles.User},
+		Password: "gophers",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a user : %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	if err := app.ForgotPassword(ctx, userapp.ForgotPassword{Email: "nobody@example.com"}); err != nil {
+		t.Fatalf("Should respond the same for an unknown email : %s", err)
+	}
+
+	if len(snd.msgs) != 0 {
+		t.Fatalf("Should not send anything for an unknown email : %+v", snd.msgs)
+	}
+
+	if err := app.ForgotPassword(ctx, userapp.ForgotPassword{Email: usr.Email.Address}); err != nil {
+		t.Fatalf("Should be able to request a password reset : %s", err)
+	}
+
+	if len(snd.msgs) != 1 || snd.msgs[0].To.Address != usr.Email.Address {
+		t.Fatalf("Should send the token to the user : %+v", snd.msgs)
+	}
+
+	token := regexp.MustCompile(`token to reset your password: (\S+)`).FindStringSubmatch(snd.msgs[0].Body)
+	if token == nil {
+		t.Fatalf("Should find the token in the message : %s", snd.msgs[0].Body)
+	}
+
+	// -------------------------------------------------------------------------
+
+	reset := userapp.ResetPassword{
+		Token:           token[1],
+		Password:        "newpass",
+		PasswordConfirm: "newpass",
+	}
+
+	if err := app.ResetPassword(ctx, reset); err != nil {
+		t.Fatalf("Should be able to reset the password : %s", err)
+	}
+
+	err = app.ResetPassword(ctx, reset)
+	if appErr := checkCode(t, err, errs.InvalidArgument); appErr.Message != userbus.ErrInvalidResetToken.Error() {
+		t.Errorf("Should reject the token once used : %s", appErr.Message)
+	}
+
+	if _, err := userBus.Authenticate(ctx, usr.Email, "newpass"); err != nil {
+		t.Errorf("Should authenticate with the new password : %s", err)
+	}
+}
+
+func Test_PasswordResetDisabled(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	app := userapp.NewApp(userbus.NewBusiness(log, delegate.New(log), usermem.NewStore()))
+
+	err := app.ForgotPassword(ctx, userapp.ForgotPassword{Email: "bill@example.com"})
+	checkCode(t, err, errs.Unimplemented)
+
+	err = app.ResetPassword(ctx, userapp.ResetPassword{Token: "token", Password: "newpass", PasswordConfirm: "newpass"})
+	checkCode(t, err, errs.Unimplemented)
+}
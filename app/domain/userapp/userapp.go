@@ -4,20 +4,30 @@ package userapp
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/emailverify"
 	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/notify"
 	"github.com/ardanlabs/service/app/sdk/query"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/order"
 	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
 )
 
+// ReasonUserNotFound indicates the specified user doesn't exist.
+var ReasonUserNotFound = errs.NewReason("user.not_found", errs.NotFound)
+
 // App manages the set of app layer api functions for the user domain.
 type App struct {
-	userBus *userbus.Business
-	auth    *auth.Auth
+	userBus  *userbus.Business
+	auth     *auth.Auth
+	verifier *emailverify.Verifier
+	notifier notify.Sender
 }
 
 // NewApp constructs a user app API for use.
@@ -27,6 +37,17 @@ func NewApp(userBus *userbus.Business) *App {
 	}
 }
 
+// NewAppWithAccountSupport constructs a user app API for use with email
+// verification and password reset support. Either feature is unavailable if
+// its dependency is nil.
+func NewAppWithAccountSupport(userBus *userbus.Business, verifier *emailverify.Verifier, notifier notify.Sender) *App {
+	return &App{
+		userBus:  userBus,
+		verifier: verifier,
+		notifier: notifier,
+	}
+}
+
 // NewAppWithAuth constructs a user app API for use with auth support.
 func NewAppWithAuth(userBus *userbus.Business, ath *auth.Auth) *App {
 	return &App{
@@ -45,7 +66,7 @@ func (a *App) Create(ctx context.Context, app NewUser) (User, error) {
 	usr, err := a.userBus.Create(ctx, nc)
 	if err != nil {
 		if errors.Is(err, userbus.ErrUniqueEmail) {
-			return User{}, errs.New(errs.Aborted, userbus.ErrUniqueEmail)
+			return User{}, errs.NewConflict(userbus.ErrUniqueEmail, sqldb.ConflictFields(err))
 		}
 		return User{}, errs.Newf(errs.Internal, "create: usr[%+v]: %s", usr, err)
 	}
@@ -67,12 +88,39 @@ func (a *App) Update(ctx context.Context, app UpdateUser) (User, error) {
 
 	updUsr, err := a.userBus.Update(ctx, usr, uu)
 	if err != nil {
+		if errors.Is(err, userbus.ErrUniqueEmail) {
+			return User{}, errs.NewConflict(userbus.ErrUniqueEmail, sqldb.ConflictFields(err))
+		}
+		if errors.Is(err, userbus.ErrModified) {
+			return User{}, errs.New(errs.PreconditionFailed, userbus.ErrModified)
+		}
 		return User{}, errs.Newf(errs.Internal, "update: userID[%s] uu[%+v]: %s", usr.ID, uu, err)
 	}
 
 	return toAppUser(updUsr), nil
 }
 
+// CurrentETag returns the entity tag of the user the request is for.
+func CurrentETag(ctx context.Context) (string, error) {
+	usr, err := mid.GetUser(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return ETag(usr), nil
+}
+
+// CurrentLastModified returns the time the user the request is for was last
+// updated.
+func CurrentLastModified(ctx context.Context) (time.Time, error) {
+	usr, err := mid.GetUser(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return usr.DateUpdated, nil
+}
+
 // UpdateRole updates an existing user's role.
 func (a *App) UpdateRole(ctx context.Context, app UpdateUserRole) (User, error) {
 	uu, err := toBusUpdateUserRole(app)
@@ -87,6 +135,9 @@ func (a *App) UpdateRole(ctx context.Context, app UpdateUserRole) (User, error)
 
 	updUsr, err := a.userBus.Update(ctx, usr, uu)
 	if err != nil {
+		if errors.Is(err, userbus.ErrModified) {
+			return User{}, errs.New(errs.PreconditionFailed, userbus.ErrModified)
+		}
 		return User{}, errs.Newf(errs.Internal, "updaterole: userID[%s] uu[%+v]: %s", usr.ID, uu, err)
 	}
 
@@ -124,17 +175,51 @@ func (a *App) Query(ctx context.Context, qp QueryParams) (query.Result[User], er
 		return query.Result[User]{}, errs.NewFieldsError("order", err)
 	}
 
-	usrs, err := a.userBus.Query(ctx, filter, orderBy, page)
+	usrs, total, err := a.userBus.QueryWithCount(ctx, filter, orderBy, page)
 	if err != nil {
 		return query.Result[User]{}, errs.Newf(errs.Internal, "query: %s", err)
 	}
 
-	total, err := a.userBus.Count(ctx, filter)
+	return query.NewResult(toAppUsers(usrs), total, page), nil
+}
+
+// Export validates the query parameters and returns the selected fields
+// and a function that streams every user matching them. Paging parameters
+// are ignored since the export contains the full result set.
+func (a *App) Export(qp QueryParams) (Export, error) {
+	filter, err := parseFilter(qp)
 	if err != nil {
-		return query.Result[User]{}, errs.Newf(errs.Internal, "count: %s", err)
+		return Export{}, err
 	}
 
-	return query.NewResult(toAppUsers(usrs), total, page), nil
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, defaultOrderBy)
+	if err != nil {
+		return Export{}, errs.NewFieldsError("order", err)
+	}
+
+	fields, err := parseFields(qp.Fields)
+	if err != nil {
+		return Export{}, errs.NewFieldsError("fields", err)
+	}
+
+	f := func(ctx context.Context, send func(ExportRow) error) error {
+		fn := func(usr userbus.User) error {
+			return send(ExportRow{fields: fields, usr: toAppUser(usr)})
+		}
+
+		if err := a.userBus.QueryEach(ctx, filter, orderBy, fn); err != nil {
+			return fmt.Errorf("queryeach: %w", err)
+		}
+
+		return nil
+	}
+
+	exp := Export{
+		Fields: fields,
+		Stream: f,
+	}
+
+	return exp, nil
 }
 
 // QueryByID returns a user by its Ia.
@@ -0,0 +1,73 @@
+package userapp
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/emailverify"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/userbus"
+)
+
+// VerificationToken generates a token the user can use to verify their email
+// address. The token is expected to be delivered to the email address.
+func (a *App) VerificationToken(ctx context.Context) (VerificationToken, error) {
+	if a.verifier == nil {
+		return VerificationToken{}, errs.Newf(errs.Unimplemented, "email verification is not configured")
+	}
+
+	usr, err := mid.GetUser(ctx)
+	if err != nil {
+		return VerificationToken{}, errs.Newf(errs.Internal, "user missing in context: %s", err)
+	}
+
+	if usr.EmailVerified {
+		return VerificationToken{}, errs.Newf(errs.FailedPrecondition, "email address is already verified")
+	}
+
+	token, claims, err := a.verifier.GenerateToken(usr.ID, usr.Email.Address)
+	if err != nil {
+		return VerificationToken{}, errs.Newf(errs.Internal, "generatetoken: userID[%s]: %s", usr.ID, err)
+	}
+
+	vt := VerificationToken{
+		Token:     token,
+		ExpiresAt: claims.ExpiresAt.Format(time.RFC3339),
+	}
+
+	return vt, nil
+}
+
+// VerifyEmail consumes a verification token and marks the email address of
+// the user as verified.
+func (a *App) VerifyEmail(ctx context.Context, app VerifyEmail) error {
+	if a.verifier == nil {
+		return errs.Newf(errs.Unimplemented, "email verification is not configured")
+	}
+
+	claims, err := a.verifier.ParseToken(app.Token)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usr, err := a.userBus.QueryByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, userbus.ErrNotFound) {
+			return errs.New(errs.InvalidArgument, emailverify.ErrInvalidToken)
+		}
+		return errs.Newf(errs.Internal, "querybyid: userID[%s]: %s", claims.UserID, err)
+	}
+
+	// The token is only good for the email address it was generated for.
+	if usr.Email.Address != claims.Email {
+		return errs.New(errs.InvalidArgument, emailverify.ErrInvalidToken)
+	}
+
+	if _, err := a.userBus.VerifyEmail(ctx, usr); err != nil {
+		return errs.Newf(errs.Internal, "verifyemail: userID[%s]: %s", usr.ID, err)
+	}
+
+	return nil
+}
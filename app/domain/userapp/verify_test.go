@@ -0,0 +1,125 @@
+package userapp_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/app/domain/userapp"
+	"github.com/ardanlabs/service/app/sdk/emailverify"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+func Test_VerifyEmail(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	verifier, err := emailverify.New(secret, time.Hour)
+	if err != nil {
+		t.Fatalf("Should be able to construct a verifier : %s", err)
+	}
+
+	userBus := userbus.NewBusiness(log, delegate.New(log), usermem.NewStore())
+	app := userapp.NewAppWithAccountSupport(userBus, verifier, nil)
+
+	usr, err := userBus.Create(ctx, userbus.NewUser{
+		Name:     userbus.MustParseName("Bill Kennedy"),
+		Email:    mail.Address{Address: "bill@example.com"},
+		Roles:    []userbus.Role{userbus.Roles.User},
+		Password: "gophers",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a user : %s", err)
+	}
+
+	if usr.EmailVerified {
+		t.Fatalf("Should create new users unverified")
+	}
+
+	// -------------------------------------------------------------------------
+
+	expired, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   usr.ID.String(),
+		"aud":   "email-verification",
+		"email": usr.Email.Address,
+		"exp":   time.Now().Add(-time.Minute).Unix(),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("Should be able to sign the token : %s", err)
+	}
+
+	err = app.VerifyEmail(ctx, userapp.VerifyEmail{Token: expired})
+	if appErr := checkCode(t, err, errs.InvalidArgument); appErr.Message != emailverify.ErrExpiredToken.Error() {
+		t.Errorf("Should reject the token as expired : %s", appErr.Message)
+	}
+
+	// -------------------------------------------------------------------------
+
+	token, _, err := verifier.GenerateToken(usr.ID, "other@example.com")
+	if err != nil {
+		t.Fatalf("Should be able to generate a token : %s", err)
+	}
+
+	err = app.VerifyEmail(ctx, userapp.VerifyEmail{Token: token})
+	checkCode(t, err, errs.InvalidArgument)
+
+	// -------------------------------------------------------------------------
+
+	token, _, err = verifier.GenerateToken(usr.ID, usr.Email.Address)
+	if err != nil {
+		t.Fatalf("Should be able to generate a token : %s", err)
+	}
+
+	if err := app.VerifyEmail(ctx, userapp.VerifyEmail{Token: token}); err != nil {
+		t.Fatalf("Should be able to verify the email : %s", err)
+	}
+
+	usr, err = userBus.QueryByID(ctx, usr.ID)
+	if err != nil {
+		t.Fatalf("Should be able to query the user : %s", err)
+	}
+
+	if !usr.EmailVerified {
+		t.Fatalf("Should have marked the email as verified")
+	}
+
+	// -------------------------------------------------------------------------
+
+	email := mail.Address{Address: "bill@other.com"}
+
+	usr, err = userBus.Update(ctx, usr, userbus.UpdateUser{Email: &email})
+	if err != nil {
+		t.Fatalf("Should be able to update the user : %s", err)
+	}
+
+	if usr.EmailVerified {
+		t.Errorf("Should require verification again after changing the email")
+	}
+}
+
+func checkCode(t *testing.T, err error, code errs.ErrCode) *errs.Error {
+	t.Helper()
+
+	var appErr *errs.Error
+	if !errors.As(err, &appErr) {
+		t.Fatalf("Should get an app error : %v", err)
+	}
+
+	if appErr.Code != code {
+		t.Fatalf("Should get code %s, got %s : %s", code, appErr.Code, appErr.Message)
+	}
+
+	return appErr
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/vproductbus"
+	"github.com/ardanlabs/service/business/sdk/money"
 	"github.com/google/uuid"
 )
 
@@ -30,7 +31,7 @@ func parseFilter(qp QueryParams) (vproductbus.QueryFilter, error) {
 	}
 
 	if qp.Cost != "" {
-		cst, err := strconv.ParseFloat(qp.Cost, 64)
+		cst, err := money.Parse(qp.Cost)
 		if err != nil {
 			return vproductbus.QueryFilter{}, errs.NewFieldsError("cost", err)
 		}
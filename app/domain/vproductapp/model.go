@@ -1,10 +1,11 @@
 package vproductapp
 
 import (
-	"encoding/json"
 	"time"
 
+	"github.com/ardanlabs/service/app/sdk/encode"
 	"github.com/ardanlabs/service/business/domain/vproductbus"
+	"github.com/ardanlabs/service/business/sdk/money"
 )
 
 // QueryParams represents the set of possible query strings.
@@ -24,19 +25,19 @@ type QueryParams struct {
 // Product represents information about an individual product with
 // extended information.
 type Product struct {
-	ID          string  `json:"id"`
-	UserID      string  `json:"userID"`
-	Name        string  `json:"name"`
-	Cost        float64 `json:"cost"`
-	Quantity    int     `json:"quantity"`
-	DateCreated string  `json:"dateCreated"`
-	DateUpdated string  `json:"dateUpdated"`
-	UserName    string  `json:"userName"`
+	ID          string      `json:"id"`
+	UserID      string      `json:"userID"`
+	Name        string      `json:"name"`
+	Cost        money.Money `json:"cost"`
+	Quantity    int         `json:"quantity"`
+	DateCreated string      `json:"dateCreated"`
+	DateUpdated string      `json:"dateUpdated"`
+	UserName    string      `json:"userName"`
 }
 
 // Encode implements the encoder interface.
 func (app Product) Encode() ([]byte, string, error) {
-	data, err := json.Marshal(app)
+	data, err := encode.JSON(app)
 	return data, "application/json", err
 }
 
@@ -23,10 +23,13 @@ import (
 // ErrForbidden is returned when a auth issue is identified.
 var ErrForbidden = errors.New("attempted action is not allowed")
 
-// Claims represents the authorization claims transmitted via a JWT.
+// Claims represents the authorization claims transmitted via a JWT. The
+// tenant is only known from the claims, since any value a client sends can't
+// be trusted.
 type Claims struct {
 	jwt.RegisteredClaims
-	Roles []string `json:"roles"`
+	Roles  []string `json:"roles"`
+	Tenant string   `json:"tenant,omitempty"`
 }
 
 // KeyLookup declares a method set of behavior for looking up
@@ -199,8 +202,9 @@ func (a *Auth) opaPolicyEvaluation(ctx context.Context, regoScript string, rule
 	return nil
 }
 
-// isUserEnabled hits the database and checks the user is not disabled. If the
-// no database connection was provided, this check is skipped.
+// isUserEnabled hits the database and checks the user is not disabled and the
+// token wasn't issued before the password was changed. If the no database
+// connection was provided, this check is skipped.
 func (a *Auth) isUserEnabled(ctx context.Context, claims Claims) error {
 	if a.userBus == nil {
 		return nil
@@ -220,5 +224,13 @@ func (a *Auth) isUserEnabled(ctx context.Context, claims Claims) error {
 		return fmt.Errorf("user disabled")
 	}
 
+	// Tokens issued before the password was last changed are no longer
+	// valid. The issued at time only has second precision.
+	if !usr.DatePasswordChanged.IsZero() {
+		if claims.IssuedAt == nil || claims.IssuedAt.Time.Before(usr.DatePasswordChanged.Truncate(time.Second)) {
+			return fmt.Errorf("token issued before the password was changed")
+		}
+	}
+
 	return nil
 }
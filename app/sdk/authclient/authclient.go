@@ -17,6 +17,8 @@ import (
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/tracer"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 )
 
 // This provides a default client configuration, but it's recommended
@@ -38,19 +40,26 @@ var defaultClient = http.Client{
 	},
 }
 
+// defaultTimeout is the maximum amount of time a call to the auth service is
+// allowed to take when the caller's context provides a longer deadline or
+// no deadline at all.
+const defaultTimeout = 5 * time.Second
+
 // Client represents a client that can talk to the auth service.
 type Client struct {
-	log  *logger.Logger
-	url  string
-	http *http.Client
+	log     *logger.Logger
+	url     string
+	http    *http.Client
+	timeout time.Duration
 }
 
 // New constructs an Auth that can be used to talk with the auth service.
 func New(log *logger.Logger, url string, options ...func(cln *Client)) *Client {
 	cln := Client{
-		log:  log,
-		url:  url,
-		http: &defaultClient,
+		log:     log,
+		url:     url,
+		http:    &defaultClient,
+		timeout: defaultTimeout,
 	}
 
 	for _, option := range options {
@@ -68,6 +77,14 @@ func WithClient(http *http.Client) func(cln *Client) {
 	}
 }
 
+// WithTimeout sets the maximum amount of time a call to the auth service
+// can take. The caller's context deadline is used when it is shorter.
+func WithTimeout(timeout time.Duration) func(cln *Client) {
+	return func(cln *Client) {
+		cln.timeout = timeout
+	}
+}
+
 // Authenticate calls the auth service to authenticate the user.
 func (cln *Client) Authenticate(ctx context.Context, authorization string) (AuthenticateResp, error) {
 	endpoint := fmt.Sprintf("%s/v1/auth/authenticate", cln.url)
@@ -95,7 +112,7 @@ func (cln *Client) Authorize(ctx context.Context, auth Authorize) error {
 	return nil
 }
 
-func (cln *Client) do(ctx context.Context, method string, endpoint string, headers map[string]string, body any, v any) error {
+func (cln *Client) do(ctx context.Context, method string, endpoint string, headers map[string]string, body any, v any) (err error) {
 	var statusCode int
 
 	u, err := url.Parse(endpoint)
@@ -109,12 +126,33 @@ func (cln *Client) do(ctx context.Context, method string, endpoint string, heade
 		cln.log.Info(ctx, "authclient: rawRequest: completed", "status", statusCode)
 	}()
 
-	ctx, span := tracer.AddSpan(ctx, fmt.Sprintf("app.api.authclient.%s", base), attribute.String("endpoint", endpoint))
+	start := time.Now()
+
+	ctx, span := tracer.AddClientSpan(ctx, fmt.Sprintf("app.api.authclient.%s", base),
+		attribute.String("endpoint", endpoint),
+		semconv.HTTPMethodKey.String(method),
+		semconv.HTTPURLKey.String(endpoint),
+	)
 	defer func() {
-		span.SetAttributes(attribute.Int("status", statusCode))
+		span.SetAttributes(
+			attribute.Int("status", statusCode),
+			semconv.HTTPStatusCodeKey.Int(statusCode),
+			attribute.String("latency", time.Since(start).String()),
+		)
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
 		span.End()
 	}()
 
+	// The request inherits the caller's cancellation and deadline. The
+	// timeout only caps the call when the caller's deadline is longer.
+	ctx, cancel := context.WithTimeout(ctx, cln.timeout)
+	defer cancel()
+
 	var b bytes.Buffer
 	if body != nil {
 		if err := json.NewEncoder(&b).Encode(body); err != nil {
@@ -135,6 +173,10 @@ func (cln *Client) do(ctx context.Context, method string, endpoint string, heade
 		req.Header.Set(key, value)
 	}
 
+	// Propagate the trace and baggage so the auth service can continue the
+	// trace and read values like the tenant id.
+	tracer.InjectHeaders(ctx, req.Header)
+
 	resp, err := cln.http.Do(req)
 	if err != nil {
 		return fmt.Errorf("do: error: %w", err)
@@ -0,0 +1,82 @@
+package authclient_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_CallerCancel(t *testing.T) {
+	srv := newBlockingServer(t)
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	cln := authclient.New(log, srv.URL, authclient.WithTimeout(time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := cln.Authenticate(ctx, "Bearer token")
+	if err == nil {
+		t.Fatalf("Should receive an error when the caller cancels")
+	}
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Should receive a context canceled error : %s", err)
+	}
+
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Should abort the outbound call promptly : %s", d)
+	}
+}
+
+func Test_MaxTimeout(t *testing.T) {
+	srv := newBlockingServer(t)
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	cln := authclient.New(log, srv.URL, authclient.WithTimeout(50*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	_, err := cln.Authenticate(ctx, "Bearer token")
+	if err == nil {
+		t.Fatalf("Should receive an error when the max timeout is reached")
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Should receive a deadline exceeded error : %s", err)
+	}
+
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Should cap the outbound call at the max timeout : %s", d)
+	}
+}
+
+// newBlockingServer returns a server whose handlers block until the
+// request is abandoned by the client.
+func newBlockingServer(t *testing.T) *httptest.Server {
+	done := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+
+	t.Cleanup(func() {
+		close(done)
+		srv.Close()
+	})
+
+	return srv
+}
@@ -0,0 +1,103 @@
+package authclient_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// spanRecorder keeps the spans that have ended.
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (sr *spanRecorder) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {}
+func (sr *spanRecorder) Shutdown(ctx context.Context) error                       { return nil }
+func (sr *spanRecorder) ForceFlush(ctx context.Context) error                     { return nil }
+
+func (sr *spanRecorder) OnEnd(s sdktrace.ReadOnlySpan) {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.spans = append(sr.spans, s)
+}
+
+func (sr *spanRecorder) ended() []sdktrace.ReadOnlySpan {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	return sr.spans
+}
+
+func Test_ClientSpan(t *testing.T) {
+	var traceparent string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"userID":"` + uuid.NewString() + `"}`))
+	}))
+	defer srv.Close()
+
+	recorder := spanRecorder{}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(&recorder))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "caller")
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	cln := authclient.New(log, srv.URL)
+
+	if _, err := cln.Authenticate(ctx, "Bearer token"); err != nil {
+		t.Fatalf("Should be able to authenticate : %s", err)
+	}
+
+	parent.End()
+
+	var span sdktrace.ReadOnlySpan
+	for _, s := range recorder.ended() {
+		if s.Name() == "app.api.authclient.authenticate" {
+			span = s
+		}
+	}
+
+	if span == nil {
+		t.Fatalf("Should record a span for the outbound call")
+	}
+
+	if span.SpanKind() != trace.SpanKindClient {
+		t.Errorf("Should record a client span : %s", span.SpanKind())
+	}
+
+	if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("Should be a child of the caller's span")
+	}
+
+	exp := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+	if traceparent != exp {
+		t.Errorf("Should inject the client span in the traceparent header, got %q, exp %q", traceparent, exp)
+	}
+
+	attrs := attribute.NewSet(span.Attributes()...)
+
+	if v, _ := attrs.Value("http.status_code"); v.AsInt64() != http.StatusOK {
+		t.Errorf("Should record the status code : %v", v.Emit())
+	}
+
+	if v, _ := attrs.Value("http.method"); v.AsString() != http.MethodGet {
+		t.Errorf("Should record the method : %v", v.Emit())
+	}
+
+	if !attrs.HasValue("latency") {
+		t.Errorf("Should record the latency")
+	}
+}
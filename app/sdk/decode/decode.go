@@ -0,0 +1,87 @@
+// Package decode provides support for decoding JSON documents in the app
+// layer with options the standard library doesn't provide.
+package decode
+
+import (
+	stdjson "encoding/json"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/jsontypes"
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+// Option represents an optional decoding behavior.
+type Option func(cfg *config)
+
+type config struct {
+	unmarshalers []*json.Unmarshalers
+}
+
+// UseNumber stores JSON numbers decoded into values of type any as a
+// json.Number from the standard library instead of a float64. A float64
+// can't represent every 64-bit integer, so large ids and amounts would
+// otherwise be silently rounded. A json.Number encodes back to the original
+// number.
+func UseNumber() Option {
+	return func(cfg *config) {
+		cfg.unmarshalers = append(cfg.unmarshalers, json.UnmarshalFuncV2(unmarshalNumber))
+	}
+}
+
+// UTC converts time values to UTC once they're decoded, so a time sent with
+// any offset is stored as the same instant in UTC. The layout of a time
+// field can be set with the format tag option, like
+// `json:"date,format:RFC1123Z"`, and defaults to RFC 3339.
+func UTC() Option {
+	return func(cfg *config) {
+		cfg.unmarshalers = append(cfg.unmarshalers, json.UnmarshalFuncV2(unmarshalUTC))
+	}
+}
+
+// JSON unmarshals the JSON document into v. The custom types in the
+// jsontypes registry are unmarshaled with the registered functions. Object
+// names are matched case-insensitively and duplicate names and invalid UTF-8
+// are accepted to follow the behavior of the standard library.
+func JSON(data []byte, v any, options ...Option) error {
+	var cfg config
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	jsonOpts := []json.Options{
+		json.MatchCaseInsensitiveNames(true),
+		jsontext.AllowDuplicateNames(true),
+		jsontext.AllowInvalidUTF8(true),
+	}
+
+	unmarshalers := append(cfg.unmarshalers, jsontypes.Unmarshalers())
+	jsonOpts = append(jsonOpts, json.WithUnmarshalers(json.NewUnmarshalers(unmarshalers...)))
+
+	return json.Unmarshal(data, v, jsonOpts...)
+}
+
+func unmarshalNumber(dec *jsontext.Decoder, v *any, opts json.Options) error {
+	if dec.PeekKind() != '0' {
+		return json.SkipFunc
+	}
+
+	val, err := dec.ReadValue()
+	if err != nil {
+		return err
+	}
+
+	*v = stdjson.Number(val)
+
+	return nil
+}
+
+func unmarshalUTC(dec *jsontext.Decoder, v *time.Time, opts json.Options) error {
+	if err := json.UnmarshalDecode(dec, v, opts, json.WithUnmarshalers(nil)); err != nil {
+		return err
+	}
+
+	*v = v.UTC()
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package decode_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/decode"
+)
+
+func Test_UseNumber(t *testing.T) {
+	const doc = `{"id":9007199254740993,"nested":{"max":18446744073709551615,"list":[-9223372036854775808,1.5]},"name":"bill"}`
+
+	var v struct {
+		ID     any
+		Nested any
+		Name   string
+	}
+
+	if err := decode.JSON([]byte(doc), &v, decode.UseNumber()); err != nil {
+		t.Fatalf("Should be able to decode the document : %s", err)
+	}
+
+	if _, ok := v.ID.(json.Number); !ok {
+		t.Fatalf("Should decode the number as a json.Number : %T", v.ID)
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Should be able to encode the document : %s", err)
+	}
+
+	exp := `{"ID":9007199254740993,"Nested":{"list":[-9223372036854775808,1.5],"max":18446744073709551615},"Name":"bill"}`
+	if string(data) != exp {
+		t.Errorf("Should round trip the numbers without losing precision")
+		t.Errorf("GOT: %s", data)
+		t.Errorf("EXP: %s", exp)
+	}
+}
+
+func Test_Float(t *testing.T) {
+	var v any
+	if err := decode.JSON([]byte(`{"id":9007199254740993}`), &v); err != nil {
+		t.Fatalf("Should be able to decode the document : %s", err)
+	}
+
+	if _, ok := v.(map[string]any)["id"].(float64); !ok {
+		t.Fatalf("Should decode the number as a float64 by default : %T", v)
+	}
+}
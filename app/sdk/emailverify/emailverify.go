@@ -0,0 +1,130 @@
+// Package emailverify provides support for signed, expiring tokens that prove
+// a user has access to their email address.
+package emailverify
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// audience identifies the token as an email verification token so it can't
+// be confused with any other token signed by the same secret.
+const audience = "email-verification"
+
+// minSecretLen is the minimum number of bytes required for the secret.
+const minSecretLen = 32
+
+// Set of error variables for verification tokens.
+var (
+	ErrInvalidToken = errors.New("invalid verification token")
+	ErrExpiredToken = errors.New("verification token has expired")
+)
+
+// Claims represents the information carried by a verification token.
+type Claims struct {
+	UserID    uuid.UUID
+	Email     string
+	ExpiresAt time.Time
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	Email string `json:"email"`
+}
+
+// Verifier generates and parses verification tokens.
+type Verifier struct {
+	secret []byte
+	ttl    time.Duration
+	parser *jwt.Parser
+}
+
+// New constructs a verifier that signs tokens with the secret. Tokens are
+// valid for the specified duration.
+func New(secret string, ttl time.Duration) (*Verifier, error) {
+	if len(secret) < minSecretLen {
+		return nil, fmt.Errorf("secret must be at least %d bytes", minSecretLen)
+	}
+
+	if ttl <= 0 {
+		return nil, errors.New("ttl must be greater than zero")
+	}
+
+	v := Verifier{
+		secret: []byte(secret),
+		ttl:    ttl,
+		parser: jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name})),
+	}
+
+	return &v, nil
+}
+
+// GenerateToken generates a token for the user and the email address to be
+// verified.
+func (v *Verifier) GenerateToken(userID uuid.UUID, email string) (string, Claims, error) {
+	now := time.Now().UTC()
+
+	c := claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(v.ttl)),
+		},
+		Email: email,
+	}
+
+	str, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString(v.secret)
+	if err != nil {
+		return "", Claims{}, fmt.Errorf("signing token: %w", err)
+	}
+
+	return str, toClaims(c), nil
+}
+
+// ParseToken validates the token and returns the claims it carries.
+func (v *Verifier) ParseToken(token string) (Claims, error) {
+	var c claims
+
+	keyFunc := func(*jwt.Token) (any, error) {
+		return v.secret, nil
+	}
+
+	if _, err := v.parser.ParseWithClaims(token, &c, keyFunc); err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return Claims{}, ErrExpiredToken
+		}
+		return Claims{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if !c.VerifyAudience(audience, true) || c.ExpiresAt == nil {
+		return Claims{}, ErrInvalidToken
+	}
+
+	out := toClaims(c)
+	if out.UserID == uuid.Nil || out.Email == "" {
+		return Claims{}, ErrInvalidToken
+	}
+
+	return out, nil
+}
+
+func toClaims(c claims) Claims {
+	out := Claims{
+		Email: c.Email,
+	}
+
+	if id, err := uuid.Parse(c.Subject); err == nil {
+		out.UserID = id
+	}
+
+	if c.ExpiresAt != nil {
+		out.ExpiresAt = c.ExpiresAt.Time
+	}
+
+	return out
+}
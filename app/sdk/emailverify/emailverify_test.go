@@ -0,0 +1,154 @@
+package emailverify_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/emailverify"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+const secret = "0123456789abcdef0123456789abcdef"
+
+func Test_Token(t *testing.T) {
+	v, err := emailverify.New(secret, time.Hour)
+	if err != nil {
+		t.Fatalf("Should be able to construct a verifier : %s", err)
+	}
+
+	userID := uuid.New()
+
+	token, gen, err := v.GenerateToken(userID, "bill@example.com")
+	if err != nil {
+		t.Fatalf("Should be able to generate a token : %s", err)
+	}
+
+	if d := time.Until(gen.ExpiresAt); d <= 59*time.Minute || d > time.Hour {
+		t.Errorf("Should expire in an hour : %s", d)
+	}
+
+	claims, err := v.ParseToken(token)
+	if err != nil {
+		t.Fatalf("Should be able to parse the token : %s", err)
+	}
+
+	if claims.UserID != userID {
+		t.Errorf("Should get the user id back, got %s, exp %s", claims.UserID, userID)
+	}
+
+	if claims.Email != "bill@example.com" {
+		t.Errorf("Should get the email back, got %s", claims.Email)
+	}
+
+	if !claims.ExpiresAt.Equal(gen.ExpiresAt) {
+		t.Errorf("Should get the expiration back, got %s, exp %s", claims.ExpiresAt, gen.ExpiresAt)
+	}
+}
+
+func Test_TokenExpired(t *testing.T) {
+	v, err := emailverify.New(secret, time.Hour)
+	if err != nil {
+		t.Fatalf("Should be able to construct a verifier : %s", err)
+	}
+
+	token := sign(t, jwt.MapClaims{
+		"sub":   uuid.NewString(),
+		"aud":   "email-verification",
+		"email": "bill@example.com",
+		"exp":   time.Now().Add(-time.Minute).Unix(),
+	})
+
+	if _, err := v.ParseToken(token); !errors.Is(err, emailverify.ErrExpiredToken) {
+		t.Fatalf("Should reject an expired token : %v", err)
+	}
+}
+
+func Test_TokenInvalid(t *testing.T) {
+	v, err := emailverify.New(secret, time.Hour)
+	if err != nil {
+		t.Fatalf("Should be able to construct a verifier : %s", err)
+	}
+
+	other, err := emailverify.New("abcdef0123456789abcdef0123456789", time.Hour)
+	if err != nil {
+		t.Fatalf("Should be able to construct a verifier : %s", err)
+	}
+
+	otherToken, _, err := other.GenerateToken(uuid.New(), "bill@example.com")
+	if err != nil {
+		t.Fatalf("Should be able to generate a token : %s", err)
+	}
+
+	type table struct {
+		name  string
+		token string
+	}
+
+	tt := []table{
+		{
+			name:  "secret",
+			token: otherToken,
+		},
+		{
+			name:  "malformed",
+			token: "not-a-token",
+		},
+		{
+			name: "audience",
+			token: sign(t, jwt.MapClaims{
+				"sub":   uuid.NewString(),
+				"aud":   "other",
+				"email": "bill@example.com",
+				"exp":   time.Now().Add(time.Hour).Unix(),
+			}),
+		},
+		{
+			name: "no-expiration",
+			token: sign(t, jwt.MapClaims{
+				"sub":   uuid.NewString(),
+				"aud":   "email-verification",
+				"email": "bill@example.com",
+			}),
+		},
+		{
+			name: "subject",
+			token: sign(t, jwt.MapClaims{
+				"sub":   "bill",
+				"aud":   "email-verification",
+				"email": "bill@example.com",
+				"exp":   time.Now().Add(time.Hour).Unix(),
+			}),
+		},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			if _, err := v.ParseToken(tst.token); !errors.Is(err, emailverify.ErrInvalidToken) {
+				t.Errorf("Should reject the token as invalid : %v", err)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_New(t *testing.T) {
+	if _, err := emailverify.New("short", time.Hour); err == nil {
+		t.Errorf("Should not accept a short secret")
+	}
+
+	if _, err := emailverify.New(secret, 0); err == nil {
+		t.Errorf("Should not accept a zero ttl")
+	}
+}
+
+func sign(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("Should be able to sign the token : %s", err)
+	}
+
+	return token
+}
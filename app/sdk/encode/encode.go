@@ -0,0 +1,64 @@
+// Package encode provides support for encoding JSON documents in the app
+// layer with options the standard library doesn't provide.
+package encode
+
+import (
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/jsontypes"
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+// Option represents an optional encoding behavior.
+type Option func(cfg *config)
+
+type config struct {
+	loc *time.Location
+}
+
+// InLocation marshals time values in the location instead of UTC, so
+// clients see times in their own timezone with the matching offset. The
+// instant is the same either way.
+func InLocation(loc *time.Location) Option {
+	return func(cfg *config) {
+		cfg.loc = loc
+	}
+}
+
+// JSON marshals v into a JSON document. Time values are marshaled in UTC
+// unless a location is provided. The layout of a time field can be set with
+// the format tag option, like `json:"date,format:RFC1123Z"`, and defaults
+// to RFC 3339. The custom types in the jsontypes registry are marshaled
+// with the registered functions. Otherwise, the output follows the behavior
+// of the standard library, like nil slices and maps marshaled as null and
+// sorted map keys.
+func JSON(v any, options ...Option) ([]byte, error) {
+	cfg := config{
+		loc: time.UTC,
+	}
+
+	for _, option := range options {
+		option(&cfg)
+	}
+
+	if cfg.loc == nil {
+		cfg.loc = time.UTC
+	}
+
+	marshalTime := func(enc *jsontext.Encoder, t time.Time, opts json.Options) error {
+		return json.MarshalEncode(enc, t.In(cfg.loc), opts, json.WithMarshalers(nil))
+	}
+
+	jsonOpts := []json.Options{
+		json.Deterministic(true),
+		json.FormatNilSliceAsNull(true),
+		json.FormatNilMapAsNull(true),
+		jsontext.AllowInvalidUTF8(true),
+		jsontext.EscapeForHTML(true),
+		jsontext.EscapeForJS(true),
+		json.WithMarshalers(json.NewMarshalers(json.MarshalFuncV2(marshalTime), jsontypes.Marshalers())),
+	}
+
+	return json.Marshal(v, jsonOpts...)
+}
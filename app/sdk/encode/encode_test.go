@@ -0,0 +1,70 @@
+package encode_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/decode"
+	"github.com/ardanlabs/service/app/sdk/encode"
+)
+
+type event struct {
+	Name      string    `json:"name"`
+	StartsAt  time.Time `json:"startsAt"`
+	UpdatedAt time.Time `json:"updatedAt,format:RFC1123Z"`
+}
+
+func Test_InLocation(t *testing.T) {
+	eastern := time.FixedZone("EST", -5*60*60)
+
+	in := event{
+		Name:      "launch",
+		StartsAt:  time.Date(2024, time.March, 1, 15, 30, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC),
+	}
+
+	data, err := encode.JSON(in, encode.InLocation(eastern))
+	if err != nil {
+		t.Fatalf("Should be able to encode the event : %s", err)
+	}
+
+	exp := `{"name":"launch","startsAt":"2024-03-01T10:30:00-05:00","updatedAt":"Fri, 01 Mar 2024 04:00:00 -0500"}`
+	if string(data) != exp {
+		t.Errorf("Should encode the times in the location")
+		t.Errorf("GOT: %s", data)
+		t.Errorf("EXP: %s", exp)
+	}
+
+	var out event
+	if err := decode.JSON(data, &out, decode.UTC()); err != nil {
+		t.Fatalf("Should be able to decode the event : %s", err)
+	}
+
+	if !out.StartsAt.Equal(in.StartsAt) || !out.UpdatedAt.Equal(in.UpdatedAt) {
+		t.Errorf("Should decode the same instants : got %s and %s", out.StartsAt, out.UpdatedAt)
+	}
+
+	if out.StartsAt.Location() != time.UTC || out.UpdatedAt.Location() != time.UTC {
+		t.Errorf("Should decode the times in UTC : got %s and %s", out.StartsAt.Location(), out.UpdatedAt.Location())
+	}
+}
+
+func Test_UTC(t *testing.T) {
+	in := event{
+		Name:      "launch",
+		StartsAt:  time.Date(2024, time.March, 1, 10, 30, 0, 0, time.FixedZone("EST", -5*60*60)),
+		UpdatedAt: time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC),
+	}
+
+	data, err := encode.JSON(in)
+	if err != nil {
+		t.Fatalf("Should be able to encode the event : %s", err)
+	}
+
+	exp := `{"name":"launch","startsAt":"2024-03-01T15:30:00Z","updatedAt":"Fri, 01 Mar 2024 09:00:00 +0000"}`
+	if string(data) != exp {
+		t.Errorf("Should encode the times in UTC by default")
+		t.Errorf("GOT: %s", data)
+		t.Errorf("EXP: %s", exp)
+	}
+}
@@ -111,70 +111,109 @@ var (
 	// exceeded their rate limit and/or quota and must wait before making
 	// futhur requests.
 	TooManyRequests = ErrCode{value: 18}
+
+	// PayloadTooLarge indicates the data sent by the client is larger than
+	// the server is willing to process.
+	PayloadTooLarge = ErrCode{value: 19}
+
+	// UnsupportedMediaType indicates the data sent by the client is in a
+	// format the server does not accept.
+	UnsupportedMediaType = ErrCode{value: 20}
+
+	// PreconditionFailed indicates the condition provided by the client, like
+	// an If-Match header, doesn't match the current state of the resource.
+	PreconditionFailed = ErrCode{value: 21}
+
+	// PreconditionRequired indicates the operation requires the client to
+	// provide a condition, like an If-Match header.
+	PreconditionRequired = ErrCode{value: 22}
+
+	// NotAcceptable indicates the server can't produce a response in any of
+	// the formats the client accepts.
+	NotAcceptable = ErrCode{value: 23}
 )
 
 var codeNumbers = map[string]ErrCode{
-	"ok":                  OK,
-	"no_content":          NoContent,
-	"canceled":            Canceled,
-	"unknown":             Unknown,
-	"invalid_argument":    InvalidArgument,
-	"deadline_exceeded":   DeadlineExceeded,
-	"not_found":           NotFound,
-	"already_exists":      AlreadyExists,
-	"permission_denied":   PermissionDenied,
-	"resource_exhausted":  ResourceExhausted,
-	"failed_precondition": FailedPrecondition,
-	"aborted":             Aborted,
-	"out_of_range":        OutOfRange,
-	"unimplemented":       Unimplemented,
-	"internal":            Internal,
-	"unavailable":         Unavailable,
-	"data_loss":           DataLoss,
-	"unauthenticated":     Unauthenticated,
-	"too_many_requests":   TooManyRequests,
+	"ok":                     OK,
+	"no_content":             NoContent,
+	"canceled":               Canceled,
+	"unknown":                Unknown,
+	"invalid_argument":       InvalidArgument,
+	"deadline_exceeded":      DeadlineExceeded,
+	"not_found":              NotFound,
+	"already_exists":         AlreadyExists,
+	"permission_denied":      PermissionDenied,
+	"resource_exhausted":     ResourceExhausted,
+	"failed_precondition":    FailedPrecondition,
+	"aborted":                Aborted,
+	"out_of_range":           OutOfRange,
+	"unimplemented":          Unimplemented,
+	"internal":               Internal,
+	"unavailable":            Unavailable,
+	"data_loss":              DataLoss,
+	"unauthenticated":        Unauthenticated,
+	"too_many_requests":      TooManyRequests,
+	"payload_too_large":      PayloadTooLarge,
+	"unsupported_media_type": UnsupportedMediaType,
+	"precondition_failed":    PreconditionFailed,
+	"precondition_required":  PreconditionRequired,
+	"not_acceptable":         NotAcceptable,
 }
 
 var codeNames = map[ErrCode]string{
-	OK:                 "ok",
-	NoContent:          "ok_no_content",
-	Canceled:           "canceled",
-	Unknown:            "unknown",
-	InvalidArgument:    "invalid_argument",
-	DeadlineExceeded:   "deadline_exceeded",
-	NotFound:           "not_found",
-	AlreadyExists:      "already_exists",
-	PermissionDenied:   "permission_denied",
-	ResourceExhausted:  "resource_exhausted",
-	FailedPrecondition: "failed_precondition",
-	Aborted:            "aborted",
-	OutOfRange:         "out_of_range",
-	Unimplemented:      "unimplemented",
-	Internal:           "internal",
-	Unavailable:        "unavailable",
-	DataLoss:           "data_loss",
-	Unauthenticated:    "unauthenticated",
-	TooManyRequests:    "too_many_requests",
+	OK:                   "ok",
+	NoContent:            "ok_no_content",
+	Canceled:             "canceled",
+	Unknown:              "unknown",
+	InvalidArgument:      "invalid_argument",
+	DeadlineExceeded:     "deadline_exceeded",
+	NotFound:             "not_found",
+	AlreadyExists:        "already_exists",
+	PermissionDenied:     "permission_denied",
+	ResourceExhausted:    "resource_exhausted",
+	FailedPrecondition:   "failed_precondition",
+	Aborted:              "aborted",
+	OutOfRange:           "out_of_range",
+	Unimplemented:        "unimplemented",
+	Internal:             "internal",
+	Unavailable:          "unavailable",
+	DataLoss:             "data_loss",
+	Unauthenticated:      "unauthenticated",
+	TooManyRequests:      "too_many_requests",
+	PayloadTooLarge:      "payload_too_large",
+	UnsupportedMediaType: "unsupported_media_type",
+	PreconditionFailed:   "precondition_failed",
+	PreconditionRequired: "precondition_required",
+	NotAcceptable:        "not_acceptable",
 }
 
+// statusClientClosedRequest is the non-standard status used by nginx for a
+// request the client abandoned before the response was sent.
+const statusClientClosedRequest = 499
+
 var httpStatus = map[ErrCode]int{
-	OK:                 http.StatusOK,
-	NoContent:          http.StatusNoContent,
-	Canceled:           http.StatusGatewayTimeout,
-	Unknown:            http.StatusInternalServerError,
-	InvalidArgument:    http.StatusBadRequest,
-	DeadlineExceeded:   http.StatusGatewayTimeout,
-	NotFound:           http.StatusNotFound,
-	AlreadyExists:      http.StatusConflict,
-	PermissionDenied:   http.StatusForbidden,
-	ResourceExhausted:  http.StatusTooManyRequests,
-	FailedPrecondition: http.StatusBadRequest,
-	Aborted:            http.StatusConflict,
-	OutOfRange:         http.StatusBadRequest,
-	Unimplemented:      http.StatusNotImplemented,
-	Internal:           http.StatusInternalServerError,
-	Unavailable:        http.StatusServiceUnavailable,
-	DataLoss:           http.StatusInternalServerError,
-	Unauthenticated:    http.StatusUnauthorized,
-	TooManyRequests:    http.StatusTooManyRequests,
+	OK:                   http.StatusOK,
+	NoContent:            http.StatusNoContent,
+	Canceled:             statusClientClosedRequest,
+	Unknown:              http.StatusInternalServerError,
+	InvalidArgument:      http.StatusBadRequest,
+	DeadlineExceeded:     http.StatusGatewayTimeout,
+	NotFound:             http.StatusNotFound,
+	AlreadyExists:        http.StatusConflict,
+	PermissionDenied:     http.StatusForbidden,
+	ResourceExhausted:    http.StatusTooManyRequests,
+	FailedPrecondition:   http.StatusBadRequest,
+	Aborted:              http.StatusConflict,
+	OutOfRange:           http.StatusBadRequest,
+	Unimplemented:        http.StatusNotImplemented,
+	Internal:             http.StatusInternalServerError,
+	Unavailable:          http.StatusServiceUnavailable,
+	DataLoss:             http.StatusInternalServerError,
+	Unauthenticated:      http.StatusUnauthorized,
+	TooManyRequests:      http.StatusTooManyRequests,
+	PayloadTooLarge:      http.StatusRequestEntityTooLarge,
+	UnsupportedMediaType: http.StatusUnsupportedMediaType,
+	PreconditionFailed:   http.StatusPreconditionFailed,
+	PreconditionRequired: http.StatusPreconditionRequired,
+	NotAcceptable:        http.StatusNotAcceptable,
 }
@@ -5,7 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"net/http"
 	"runtime"
+	"strconv"
+	"time"
 )
 
 // ErrCode represents an error code in the system.
@@ -49,27 +53,89 @@ func (ec ErrCode) Equal(ec2 ErrCode) bool {
 
 // =============================================================================
 
+// Reason represents a stable, machine-readable code that allows clients to
+// programmatically distinguish error types, like "user.not_found". Every
+// reason is bound to the error code that provides its http status.
+type Reason struct {
+	value string
+	code  ErrCode
+}
+
+// NewReason constructs and registers a reason bound to the specified error
+// code. Reasons should be constructed once as package level variables.
+func NewReason(value string, code ErrCode) Reason {
+	r := Reason{
+		value: value,
+		code:  code,
+	}
+
+	reasons[value] = r
+
+	return r
+}
+
+// String returns the string representation of the reason.
+func (r Reason) String() string {
+	return r.value
+}
+
+// Code returns the error code the reason is bound to.
+func (r Reason) Code() ErrCode {
+	return r.code
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (r Reason) Equal(r2 Reason) bool {
+	return r.value == r2.value
+}
+
+// =============================================================================
+
 // Error represents an error in the system.
 type Error struct {
-	Code     ErrCode `json:"code"`
-	Message  string  `json:"message"`
-	FuncName string  `json:"-"`
-	FileName string  `json:"-"`
+	Code       ErrCode
+	Reason     Reason
+	Message    string
+	Details    any
+	RetryAfter time.Duration
+	FuncName   string
+	FileName   string
+	err        error
 }
 
-// New constructs an error based on an app error.
+// envelope represents the JSON document used to send an error to a client.
+type envelope struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+// New constructs an error based on an app error. If the error contains field
+// errors, they are provided as details and an InvalidArgument error is
+// reported with the validation failed reason.
 func New(code ErrCode, err error) *Error {
 	pc, filename, line, _ := runtime.Caller(1)
 
-	return &Error{
+	e := Error{
 		Code:     code,
 		Message:  err.Error(),
 		FuncName: runtime.FuncForPC(pc).Name(),
 		FileName: fmt.Sprintf("%s:%d", filename, line),
+		err:      err,
 	}
+
+	if fe := GetFieldErrors(err); fe != nil {
+		e.Details = fe
+		if code == InvalidArgument {
+			e.Reason = ReasonValidationFailed
+		}
+	}
+
+	return &e
 }
 
-// Newf constructs an error based on a error message.
+// Newf constructs an error based on a error message. The first error in the
+// arguments is kept as the cause of the error.
 func Newf(code ErrCode, format string, v ...any) *Error {
 	pc, filename, line, _ := runtime.Caller(1)
 
@@ -78,7 +144,69 @@ func Newf(code ErrCode, format string, v ...any) *Error {
 		Message:  fmt.Sprintf(format, v...),
 		FuncName: runtime.FuncForPC(pc).Name(),
 		FileName: fmt.Sprintf("%s:%d", filename, line),
+		err:      cause(v),
+	}
+}
+
+// NewWithReason constructs an error based on an app error using the error
+// code the specified reason is bound to.
+func NewWithReason(reason Reason, err error) *Error {
+	pc, filename, line, _ := runtime.Caller(1)
+
+	e := Error{
+		Code:     reason.code,
+		Reason:   reason,
+		Message:  err.Error(),
+		FuncName: runtime.FuncForPC(pc).Name(),
+		FileName: fmt.Sprintf("%s:%d", filename, line),
+		err:      err,
+	}
+
+	if fe := GetFieldErrors(err); fe != nil {
+		e.Details = fe
+	}
+
+	return &e
+}
+
+// NewfWithReason constructs an error based on a error message using the
+// error code the specified reason is bound to. The first error in the
+// arguments is kept as the cause of the error.
+func NewfWithReason(reason Reason, format string, v ...any) *Error {
+	pc, filename, line, _ := runtime.Caller(1)
+
+	return &Error{
+		Code:     reason.code,
+		Reason:   reason,
+		Message:  fmt.Sprintf(format, v...),
+		FuncName: runtime.FuncForPC(pc).Name(),
+		FileName: fmt.Sprintf("%s:%d", filename, line),
+		err:      cause(v),
+	}
+}
+
+// NewConflict constructs an Aborted error for a change that conflicts with
+// existing data. The fields in conflict are provided as details.
+func NewConflict(err error, fields []string) *Error {
+	pc, filename, line, _ := runtime.Caller(1)
+
+	e := Error{
+		Code:     Aborted,
+		Message:  err.Error(),
+		FuncName: runtime.FuncForPC(pc).Name(),
+		FileName: fmt.Sprintf("%s:%d", filename, line),
+		err:      err,
+	}
+
+	if len(fields) > 0 {
+		fe := make(FieldErrors, len(fields))
+		for i, field := range fields {
+			fe[i] = FieldError{Field: field, Err: field + " already exists"}
+		}
+		e.Details = fe
 	}
+
+	return &e
 }
 
 // Error implements the error interface.
@@ -86,6 +214,66 @@ func (e *Error) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the error the app error was constructed from, so the cause
+// can be inspected with errors.Is and errors.As.
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Kind returns the stable string code for the error. If no reason was
+// provided, the name of the error code is used.
+func (e *Error) Kind() string {
+	if e.Reason.value != "" {
+		return e.Reason.value
+	}
+
+	return e.Code.String()
+}
+
+// MarshalJSON implements the json marshaler interface so the error is
+// always rendered using the error envelope.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	env := envelope{
+		Code:    e.Kind(),
+		Message: e.Message,
+		Details: e.Details,
+	}
+
+	return json.Marshal(env)
+}
+
+// UnmarshalJSON implements the json unmarshaler interface so an error
+// envelope can be converted back into an error value.
+func (e *Error) UnmarshalJSON(data []byte) error {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return err
+	}
+
+	var code ErrCode
+	var reason Reason
+
+	if r, exists := reasons[env.Code]; exists {
+		code = r.code
+		reason = r
+	} else if err := code.UnmarshalText([]byte(env.Code)); err != nil {
+
+		// The code is not known to this program. Keep the code as the
+		// reason so the information is not lost.
+		code = Unknown
+		reason = Reason{value: env.Code, code: Unknown}
+	}
+
+	*e = Error{
+		Code:    code,
+		Reason:  reason,
+		Message: env.Message,
+		Details: env.Details,
+	}
+
+	return nil
+}
+
 // Encode implements the encoder interface.
 func (e *Error) Encode() ([]byte, string, error) {
 	data, err := json.Marshal(e)
@@ -98,11 +286,37 @@ func (e *Error) HTTPStatus() int {
 	return httpStatus[e.Code]
 }
 
+// HTTPHeader implements the web package httpHeader interface so the
+// web framework can tell the client when to retry the request.
+func (e *Error) HTTPHeader() http.Header {
+	if e.RetryAfter <= 0 {
+		return nil
+	}
+
+	secs := int(math.Ceil(e.RetryAfter.Seconds()))
+
+	h := make(http.Header)
+	h.Set("Retry-After", strconv.Itoa(secs))
+
+	return h
+}
+
 // Equal provides support for the go-cmp package and testing.
 func (e *Error) Equal(e2 *Error) bool {
 	return e.Code == e2.Code && e.Message == e2.Message
 }
 
+// cause returns the first error in the arguments of a formatted error.
+func cause(v []any) error {
+	for _, arg := range v {
+		if err, ok := arg.(error); ok {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // =============================================================================
 
 // FieldError is used to indicate an error with a specific request field.
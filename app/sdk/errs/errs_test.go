@@ -0,0 +1,326 @@
+package errs_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/app/domain/homeapp"
+	"github.com/ardanlabs/service/app/domain/userapp"
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_NotFound(t *testing.T) {
+	err := errs.NewWithReason(userapp.ReasonUserNotFound, userbus.ErrNotFound)
+
+	env := encode(t, err)
+
+	if env.Code != "user.not_found" {
+		t.Errorf("Should get the user not found code : %s", env.Code)
+	}
+
+	if env.Message != userbus.ErrNotFound.Error() {
+		t.Errorf("Should get the error message : %s", env.Message)
+	}
+
+	if status := err.HTTPStatus(); status != http.StatusNotFound {
+		t.Errorf("Should get a not found status : %d", status)
+	}
+}
+
+func Test_Validation(t *testing.T) {
+	var app struct {
+		Name  string `json:"name" validate:"required"`
+		Email string `json:"email" validate:"required,email"`
+	}
+	app.Email = "bad"
+
+	err := errs.New(errs.InvalidArgument, fmt.Errorf("validate: %w", errs.Check(app)))
+
+	env := encode(t, err)
+
+	if env.Code != "validation.failed" {
+		t.Errorf("Should get the validation failed code : %s", env.Code)
+	}
+
+	if status := err.HTTPStatus(); status != http.StatusBadRequest {
+		t.Errorf("Should get a bad request status : %d", status)
+	}
+
+	var details errs.FieldErrors
+	if err := json.Unmarshal(env.Details, &details); err != nil {
+		t.Fatalf("Should be able to unmarshal the details : %s", err)
+	}
+
+	fields := details.Fields()
+	if _, exists := fields["name"]; !exists {
+		t.Errorf("Should get a detail for the name field : %v", fields)
+	}
+	if _, exists := fields["email"]; !exists {
+		t.Errorf("Should get a detail for the email field : %v", fields)
+	}
+}
+
+func Test_NestedValidation(t *testing.T) {
+	type item struct {
+		Name  string  `json:"name" validate:"required"`
+		Price float64 `json:"price" validate:"gt=0"`
+	}
+
+	type address struct {
+		City string `json:"city" validate:"required"`
+	}
+
+	type order struct {
+		Customer string  `json:"customer" validate:"required"`
+		Address  address `json:"address"`
+		Items    []item  `json:"items" validate:"required"`
+		Tagged   []item  `json:"tagged" validate:"dive"`
+		Shipping struct {
+			Parcels []*item `json:"parcels"`
+		} `json:"shipping"`
+	}
+
+	app := order{
+		Customer: "Bill",
+		Items: []item{
+			{Name: "apple", Price: 1},
+			{Name: "pear", Price: 2},
+			{Name: "plum", Price: 0},
+		},
+		Tagged: []item{
+			{Price: 1},
+		},
+	}
+	app.Shipping.Parcels = []*item{nil, {Price: 1}}
+
+	fields := errs.GetFieldErrors(errs.Check(&app)).Fields()
+
+	exp := []string{
+		"address.city",
+		"items[2].price",
+		"tagged[0].name",
+		"shipping.parcels[1].name",
+	}
+
+	if len(fields) != len(exp) {
+		t.Errorf("Should get %d field errors : %v", len(exp), fields)
+	}
+
+	for _, field := range exp {
+		if _, exists := fields[field]; !exists {
+			t.Errorf("Should get a detail for the %s field : %v", field, fields)
+		}
+	}
+
+	app.Address.City = "Miami"
+	app.Items[2].Price = 3
+	app.Tagged[0].Name = "fig"
+	app.Shipping.Parcels[1].Name = "box"
+
+	if err := errs.Check(app); err != nil {
+		t.Errorf("Should validate the fixed order : %s", err)
+	}
+}
+
+func Test_QueryValidation(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	next := func(ctx context.Context) (mid.Encoder, error) {
+		return nil, errs.NewFieldsError("page", fmt.Errorf("page value too small"))
+	}
+
+	_, err := mid.Errors(context.Background(), log, next)
+
+	appErr, ok := err.(*errs.Error)
+	if !ok {
+		t.Fatalf("Should get an app error : %T", err)
+	}
+
+	if kind := appErr.Kind(); kind != "validation.failed" {
+		t.Errorf("Should get the validation failed code : %s", kind)
+	}
+}
+
+func Test_AuthFailure(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	ath, err := auth.New(auth.Config{
+		Log:    log,
+		Issuer: "service project",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create an authenticator : %s", err)
+	}
+
+	next := func(ctx context.Context) (mid.Encoder, error) {
+		return nil, nil
+	}
+
+	_, err = mid.Bearer(context.Background(), ath, "Bearer bad-token", next)
+
+	appErr, ok := err.(*errs.Error)
+	if !ok {
+		t.Fatalf("Should get an app error : %T", err)
+	}
+
+	env := encode(t, appErr)
+
+	if env.Code != "auth.authentication_failed" {
+		t.Errorf("Should get the authentication failed code : %s", env.Code)
+	}
+
+	if status := appErr.HTTPStatus(); status != http.StatusUnauthorized {
+		t.Errorf("Should get an unauthorized status : %d", status)
+	}
+}
+
+func Test_Timeout(t *testing.T) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	// The app layer wraps a failed business call as an internal error.
+	next := func(ctx context.Context) (mid.Encoder, error) {
+		err := fmt.Errorf("query: %w", &sqldb.TimeoutError{Timeout: time.Second})
+		return nil, errs.Newf(errs.Internal, "query: %s", err)
+	}
+
+	_, err := mid.Errors(context.Background(), log, next)
+
+	appErr, ok := err.(*errs.Error)
+	if !ok {
+		t.Fatalf("Should get an app error : %T", err)
+	}
+
+	if !appErr.Code.Equal(errs.DeadlineExceeded) {
+		t.Errorf("Should get the deadline exceeded code : %s", appErr.Code)
+	}
+
+	if status := appErr.HTTPStatus(); status != http.StatusGatewayTimeout {
+		t.Errorf("Should get a gateway timeout status : %d", status)
+	}
+
+	if !errors.Is(appErr, sqldb.ErrDBTimeout) {
+		t.Errorf("Should keep the timeout as the cause : %v", appErr)
+	}
+}
+
+func Test_Context(t *testing.T) {
+	table := []struct {
+		name   string
+		err    error
+		code   errs.ErrCode
+		status int
+		level  string
+	}{
+		{name: "deadline", err: context.DeadlineExceeded, code: errs.DeadlineExceeded, status: http.StatusGatewayTimeout, level: "ERROR"},
+		{name: "canceled", err: context.Canceled, code: errs.Canceled, status: 499, level: "INFO"},
+		{name: "wrapped-deadline", err: errs.Newf(errs.Internal, "query: %s", fmt.Errorf("query: %w", context.DeadlineExceeded)), code: errs.DeadlineExceeded, status: http.StatusGatewayTimeout, level: "ERROR"},
+		{name: "wrapped-canceled", err: errs.Newf(errs.Internal, "query: %s", fmt.Errorf("query: %w", context.Canceled)), code: errs.Canceled, status: 499, level: "INFO"},
+		{name: "internal", err: errors.New("boom"), code: errs.Internal, status: http.StatusInternalServerError, level: "ERROR"},
+		{name: "client", err: errs.New(errs.NotFound, errors.New("missing")), code: errs.NotFound, status: http.StatusNotFound, level: "INFO"},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			var buf bytes.Buffer
+			log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+			next := func(ctx context.Context) (mid.Encoder, error) {
+				return nil, tt.err
+			}
+
+			_, err := mid.Errors(context.Background(), log, next)
+
+			appErr, ok := err.(*errs.Error)
+			if !ok {
+				t.Fatalf("Should get an app error : %T", err)
+			}
+
+			if !appErr.Code.Equal(tt.code) {
+				t.Errorf("Should get the %s code : %s", tt.code, appErr.Code)
+			}
+
+			if status := appErr.HTTPStatus(); status != tt.status {
+				t.Errorf("Should get the %d status : %d", tt.status, status)
+			}
+
+			var entry struct {
+				Level string `json:"level"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("Should be able to decode the log entry : %s : %s", err, buf.String())
+			}
+
+			if entry.Level != tt.level {
+				t.Errorf("Should log at the %s level : %s", tt.level, entry.Level)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
+
+func Test_RoundTrip(t *testing.T) {
+	exp := errs.NewfWithReason(homeapp.ReasonHomeNotFound, "home not found")
+
+	data, _, err := exp.Encode()
+	if err != nil {
+		t.Fatalf("Should be able to encode the error : %s", err)
+	}
+
+	var got errs.Error
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Should be able to unmarshal the error : %s", err)
+	}
+
+	if !got.Equal(exp) {
+		t.Errorf("Should get back the same error : got[%v] exp[%v]", got, exp)
+	}
+
+	if !got.Reason.Equal(exp.Reason) {
+		t.Errorf("Should get back the same reason : got[%s] exp[%s]", got.Reason, exp.Reason)
+	}
+
+	if !got.Code.Equal(errs.NotFound) {
+		t.Errorf("Should get back the not found code : %s", got.Code)
+	}
+}
+
+// =============================================================================
+
+type envelope struct {
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Details json.RawMessage `json:"details"`
+}
+
+func encode(t *testing.T, err *errs.Error) envelope {
+	t.Helper()
+
+	data, contentType, encErr := err.Encode()
+	if encErr != nil {
+		t.Fatalf("Should be able to encode the error : %s", encErr)
+	}
+
+	if contentType != "application/json" {
+		t.Errorf("Should get a json content type : %s", contentType)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		t.Fatalf("Should be able to unmarshal the envelope : %s", err)
+	}
+
+	return env
+}
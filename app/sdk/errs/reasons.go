@@ -0,0 +1,20 @@
+package errs
+
+// reasons holds the set of registered reasons so an error envelope can be
+// converted back into an error value.
+var reasons = make(map[string]Reason)
+
+// Set of reasons shared across the different domains.
+var (
+	// ReasonValidationFailed indicates the data provided by the client
+	// failed validation. The failing fields are provided as details.
+	ReasonValidationFailed = NewReason("validation.failed", InvalidArgument)
+
+	// ReasonAuthenticationFailed indicates the client could not be
+	// identified with the credentials that were provided.
+	ReasonAuthenticationFailed = NewReason("auth.authentication_failed", Unauthenticated)
+
+	// ReasonAuthorizationFailed indicates the client was identified but is
+	// not allowed to perform the requested action.
+	ReasonAuthorizationFailed = NewReason("auth.authorization_failed", Unauthenticated)
+)
@@ -1,9 +1,11 @@
 package errs
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 
+	"github.com/ardanlabs/service/business/sdk/money"
 	"github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
@@ -29,33 +31,133 @@ func init() {
 	en_translations.RegisterDefaultTranslations(validate, translator)
 
 	// Use JSON tag names for errors instead of Go struct names.
-	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
-		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
-		if name == "-" {
-			return ""
-		}
-		return name
-	})
+	validate.RegisterTagNameFunc(jsonName)
+
+	// Validate amounts of money by their number of cents.
+	validate.RegisterCustomTypeFunc(moneyValue, money.Money{})
+}
+
+// moneyValue returns the value of an amount of money for validation.
+func moneyValue(v reflect.Value) any {
+	if m, ok := v.Interface().(money.Money); ok {
+		return m.Cents()
+	}
+
+	return nil
+}
+
+// jsonName returns the JSON tag name of the field.
+func jsonName(fld reflect.StructField) string {
+	name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	return name
 }
 
-// Check validates the provided model against it's declared tags.
+// Check validates the provided model against it's declared tags. Nested
+// structs and the struct elements of slices and arrays are validated as
+// well, with errors reported using the dotted path to the field, like
+// items[2].price.
 func Check(val any) error {
-	if err := validate.Struct(val); err != nil {
+	v := reflect.Indirect(reflect.ValueOf(val))
+	if v.Kind() != reflect.Struct {
+		return validate.Struct(val)
+	}
+
+	fields, err := check(v, "")
+	if err != nil {
+		return err
+	}
+
+	if len(fields) > 0 {
+		return fields
+	}
+
+	return nil
+}
+
+// check validates the struct and the slices it holds, prefixing the field
+// names with the path to the struct.
+func check(v reflect.Value, path string) (FieldErrors, error) {
+	var fields FieldErrors
+
+	if err := validate.Struct(v.Interface()); err != nil {
 		verrors, ok := err.(validator.ValidationErrors)
 		if !ok {
-			return err
+			return nil, err
+		}
+
+		// The namespace starts with the name of the struct type, which
+		// isn't part of the path.
+		prefix := v.Type().Name()
+		if prefix != "" {
+			prefix += "."
 		}
 
-		var fields FieldErrors
 		for _, verror := range verrors {
 			fields = append(fields, FieldError{
-				Field: verror.Field(),
+				Field: path + strings.TrimPrefix(verror.Namespace(), prefix),
 				Err:   verror.Translate(translator),
 			})
 		}
+	}
 
-		return fields
+	elems, err := checkSlices(v, path)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return append(fields, elems...), nil
+}
+
+// checkSlices finds the slices and arrays of structs held by the struct and
+// its nested structs and validates each element. Slices with the dive tag
+// are skipped since the validator already validates their elements.
+func checkSlices(v reflect.Value, path string) (FieldErrors, error) {
+	var fields FieldErrors
+
+	typ := v.Type()
+	for i := range typ.NumField() {
+		fld := typ.Field(i)
+		if !fld.IsExported() {
+			continue
+		}
+
+		name := jsonName(fld)
+		if name == "" {
+			name = fld.Name
+		}
+
+		fv := reflect.Indirect(v.Field(i))
+
+		switch fv.Kind() {
+		case reflect.Struct:
+			fe, err := checkSlices(fv, path+name+".")
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, fe...)
+
+		case reflect.Slice, reflect.Array:
+			if strings.Contains(fld.Tag.Get("validate"), "dive") {
+				continue
+			}
+
+			for j := range fv.Len() {
+				ev := reflect.Indirect(fv.Index(j))
+				if ev.Kind() != reflect.Struct {
+					continue
+				}
+
+				fe, err := check(ev, fmt.Sprintf("%s%s[%d].", path, name, j))
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, fe...)
+			}
+		}
+	}
+
+	return fields, nil
 }
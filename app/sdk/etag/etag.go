@@ -0,0 +1,35 @@
+// Package etag provides support for computing and matching entity tags used
+// for conditional requests.
+package etag
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// New computes a strong entity tag from the values that identify the
+// version of a resource, like its id and the time it was last updated.
+func New(version ...string) string {
+	h := sha256.New()
+	for _, v := range version {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// Match reports whether the If-Match header value matches the entity tag.
+// Weak tags never match since If-Match requires a strong comparison.
+func Match(ifMatch string, etag string) bool {
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,141 @@
+// Package jsontypes provides a registry of the JSON marshalers and
+// unmarshalers for custom types used across the app layer. The encode and
+// decode packages use the registry for every request and response, so a type
+// only needs to be registered once.
+package jsontypes
+
+import (
+	"errors"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ardanlabs/service/business/sdk/money"
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+var registry struct {
+	mu           sync.Mutex
+	marshalers   []*json.Marshalers
+	unmarshalers []*json.Unmarshalers
+}
+
+var (
+	marshalers   atomic.Pointer[json.Marshalers]
+	unmarshalers atomic.Pointer[json.Unmarshalers]
+)
+
+// The domain IDs implement the json.MarshalerV2 and json.UnmarshalerV2
+// interfaces themselves, so they don't need to be registered.
+func init() {
+	Register(json.MarshalFuncV2(marshalAddr), json.UnmarshalFuncV2(unmarshalAddr))
+	Register(json.MarshalFuncV2(marshalMoney), json.UnmarshalFuncV2(unmarshalMoney))
+}
+
+// Register adds the marshalers and unmarshalers for custom types to the
+// registry, where either can be nil. Domains call it from an init function.
+// When more than one function handles a type, the first registered is used.
+func Register(m *json.Marshalers, u *json.Unmarshalers) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if m != nil {
+		registry.marshalers = append(registry.marshalers, m)
+		marshalers.Store(json.NewMarshalers(registry.marshalers...))
+	}
+
+	if u != nil {
+		registry.unmarshalers = append(registry.unmarshalers, u)
+		unmarshalers.Store(json.NewUnmarshalers(registry.unmarshalers...))
+	}
+}
+
+// Marshalers returns the registered marshalers for use with the
+// json.WithMarshalers option.
+func Marshalers() *json.Marshalers {
+	return marshalers.Load()
+}
+
+// Unmarshalers returns the registered unmarshalers for use with the
+// json.WithUnmarshalers option.
+func Unmarshalers() *json.Unmarshalers {
+	return unmarshalers.Load()
+}
+
+// =============================================================================
+
+// marshalAddr writes an IP address as a string and the zero address as null.
+func marshalAddr(enc *jsontext.Encoder, addr netip.Addr, opts json.Options) error {
+	if !addr.IsValid() {
+		return enc.WriteToken(jsontext.Null)
+	}
+
+	return enc.WriteToken(jsontext.String(addr.String()))
+}
+
+// unmarshalAddr reads an IP address from a string. A null or empty string
+// is the zero address.
+func unmarshalAddr(dec *jsontext.Decoder, addr *netip.Addr, opts json.Options) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+
+	switch tok.Kind() {
+	case 'n':
+		*addr = netip.Addr{}
+		return nil
+
+	case '"':
+		if tok.String() == "" {
+			*addr = netip.Addr{}
+			return nil
+		}
+
+		v, err := netip.ParseAddr(tok.String())
+		if err != nil {
+			return &json.SemanticError{JSONKind: tok.Kind(), Err: err}
+		}
+
+		*addr = v
+		return nil
+	}
+
+	return &json.SemanticError{JSONKind: tok.Kind(), Err: errors.New("ip address must be a string")}
+}
+
+// marshalMoney writes an amount as a number or a string, depending on the
+// format set in the money package.
+func marshalMoney(enc *jsontext.Encoder, m money.Money, opts json.Options) error {
+	if money.JSONFormat() == money.FormatString {
+		return enc.WriteToken(jsontext.String(m.String()))
+	}
+
+	return enc.WriteValue(jsontext.Value(m.String()))
+}
+
+// unmarshalMoney reads an amount from a number or a string. A null leaves
+// the amount unchanged.
+func unmarshalMoney(dec *jsontext.Decoder, m *money.Money, opts json.Options) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+
+	switch tok.Kind() {
+	case 'n':
+		return nil
+
+	case '"', '0':
+		v, err := money.Parse(tok.String())
+		if err != nil {
+			return &json.SemanticError{JSONKind: tok.Kind(), Err: err}
+		}
+
+		*m = v
+		return nil
+	}
+
+	return &json.SemanticError{JSONKind: tok.Kind(), Err: errors.New("amount must be a number or a string")}
+}
@@ -0,0 +1,117 @@
+package jsontypes_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/decode"
+	"github.com/ardanlabs/service/app/sdk/encode"
+	"github.com/ardanlabs/service/app/sdk/jsontypes"
+	"github.com/ardanlabs/service/business/sdk/money"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+// celsius is registered by the test the way a domain would at init.
+type celsius float64
+
+func init() {
+	marshal := func(enc *jsontext.Encoder, c celsius, opts json.Options) error {
+		return enc.WriteToken(jsontext.String(strconv.FormatFloat(float64(c), 'f', 1, 64) + "C"))
+	}
+
+	unmarshal := func(dec *jsontext.Decoder, c *celsius, opts json.Options) error {
+		tok, err := dec.ReadToken()
+		if err != nil {
+			return err
+		}
+
+		f, err := strconv.ParseFloat(strings.TrimSuffix(tok.String(), "C"), 64)
+		if err != nil {
+			return &json.SemanticError{JSONKind: tok.Kind(), Err: err}
+		}
+
+		*c = celsius(f)
+
+		return nil
+	}
+
+	jsontypes.Register(json.MarshalFuncV2(marshal), json.UnmarshalFuncV2(unmarshal))
+}
+
+type reading struct {
+	Temp   celsius     `json:"temp"`
+	Cost   money.Money `json:"cost"`
+	Sensor netip.Addr  `json:"sensor"`
+	Backup netip.Addr  `json:"backup"`
+}
+
+func (app *reading) Decode(data []byte) error {
+	return decode.JSON(data, app)
+}
+
+func (app reading) Encode() ([]byte, string, error) {
+	data, err := encode.JSON(app)
+	return data, "application/json", err
+}
+
+func Test_Respond(t *testing.T) {
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		var app reading
+		if err := web.Decode(r, &app); err != nil {
+			return nil, err
+		}
+
+		return app, nil
+	}
+
+	webLog := func(ctx context.Context, msg string, args ...any) {}
+
+	app := web.NewApp(webLog, nil)
+	app.HandlerFunc(http.MethodPost, "", "/test", handler)
+
+	const doc = `{"temp":"21.5C","cost":"10.34","sensor":"10.0.0.1","backup":null}`
+
+	r := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBufferString(doc))
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should get a 200 : got %d : %s", w.Code, w.Body)
+	}
+
+	exp := `{"temp":"21.5C","cost":10.34,"sensor":"10.0.0.1","backup":null}`
+	if got := w.Body.String(); got != exp {
+		t.Errorf("Should marshal the custom types with the registered functions")
+		t.Errorf("GOT: %s", got)
+		t.Errorf("EXP: %s", exp)
+	}
+}
+
+func Test_Reject(t *testing.T) {
+	table := []struct {
+		name string
+		doc  string
+	}{
+		{name: "temp", doc: `{"temp":"warm"}`},
+		{name: "cost", doc: `{"cost":"1.234"}`},
+		{name: "sensor", doc: `{"sensor":"10.0.0"}`},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			var app reading
+			err := app.Decode([]byte(tt.doc))
+			if err == nil {
+				t.Fatalf("Should fail to decode %s", tt.doc)
+			}
+		})
+	}
+}
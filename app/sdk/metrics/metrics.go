@@ -20,6 +20,7 @@ type metrics struct {
 	requests   *expvar.Int
 	errors     *expvar.Int
 	panics     *expvar.Int
+	deprecated *expvar.Map
 }
 
 // init constructs the metrics value that will be used to capture metrics.
@@ -32,6 +33,7 @@ func init() {
 		requests:   expvar.NewInt("requests"),
 		errors:     expvar.NewInt("errors"),
 		panics:     expvar.NewInt("panics"),
+		deprecated: expvar.NewMap("deprecated"),
 	}
 }
 
@@ -85,3 +87,15 @@ func AddPanics(ctx context.Context) int64 {
 
 	return 0
 }
+
+// AddDeprecated increments the calls made to the deprecated route by 1.
+func AddDeprecated(ctx context.Context, route string) int64 {
+	if v, ok := ctx.Value(key).(*metrics); ok {
+		v.deprecated.Add(route, 1)
+		if n, ok := v.deprecated.Get(route).(*expvar.Int); ok {
+			return n.Value()
+		}
+	}
+
+	return 0
+}
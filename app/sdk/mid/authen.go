@@ -3,6 +3,7 @@ package mid
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"net/mail"
 	"strings"
 	"time"
@@ -16,14 +17,15 @@ import (
 	"github.com/google/uuid"
 )
 
+// ReasonTooManyAttempts is used when an identity has failed to authenticate
+// too many times and must wait before trying again.
+var ReasonTooManyAttempts = errs.NewReason("auth.too_many_attempts", errs.TooManyRequests)
+
 // Authenticate validates authentication via the auth service.
 func Authenticate(ctx context.Context, log *logger.Logger, client *authclient.Client, authorization string, next HandlerFunc) (Encoder, error) {
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
 	resp, err := client.Authenticate(ctx, authorization)
 	if err != nil {
-		return nil, errs.New(errs.Unauthenticated, err)
+		return nil, errs.NewWithReason(errs.ReasonAuthenticationFailed, err)
 	}
 
 	ctx = setUserID(ctx, resp.UserID)
@@ -36,16 +38,16 @@ func Authenticate(ctx context.Context, log *logger.Logger, client *authclient.Cl
 func Bearer(ctx context.Context, ath *auth.Auth, authorization string, next HandlerFunc) (Encoder, error) {
 	claims, err := ath.Authenticate(ctx, authorization)
 	if err != nil {
-		return nil, errs.New(errs.Unauthenticated, err)
+		return nil, errs.NewWithReason(errs.ReasonAuthenticationFailed, err)
 	}
 
 	if claims.Subject == "" {
-		return nil, errs.Newf(errs.Unauthenticated, "authorize: you are not authorized for that action, no claims")
+		return nil, errs.NewfWithReason(errs.ReasonAuthenticationFailed, "authorize: you are not authorized for that action, no claims")
 	}
 
 	subjectID, err := uuid.Parse(claims.Subject)
 	if err != nil {
-		return nil, errs.Newf(errs.Unauthenticated, "parsing subject: %s", err)
+		return nil, errs.NewfWithReason(errs.ReasonAuthenticationFailed, "parsing subject: %s", err)
 	}
 
 	ctx = setUserID(ctx, subjectID)
@@ -54,21 +56,33 @@ func Bearer(ctx context.Context, ath *auth.Auth, authorization string, next Hand
 	return next(ctx)
 }
 
-// Basic processes basic authentication logic.
-func Basic(ctx context.Context, ath *auth.Auth, userBus *userbus.Business, authorization string, next HandlerFunc) (Encoder, error) {
+// Basic processes basic authentication logic. Every credential failure
+// produces the same error so the response doesn't reveal whether the user
+// exists. When a throttle is provided, an identity with too many recent
+// failures is rejected without checking the password.
+func Basic(ctx context.Context, ath *auth.Auth, userBus *userbus.Business, throttle *Throttle, authorization string, next HandlerFunc) (Encoder, error) {
 	email, pass, ok := parseBasicAuth(authorization)
 	if !ok {
-		return nil, errs.Newf(errs.Unauthenticated, "invalid Basic auth")
+		return nil, errs.NewfWithReason(errs.ReasonAuthenticationFailed, "invalid Basic auth")
 	}
 
-	addr, err := mail.ParseAddress(email)
-	if err != nil {
-		return nil, errs.New(errs.Unauthenticated, err)
+	identity := strings.ToLower(strings.TrimSpace(email))
+
+	if throttle != nil {
+		if retryAfter, ok := throttle.attempt(identity, time.Now()); !ok {
+			err := errs.NewfWithReason(ReasonTooManyAttempts, "too many failed attempts, try again later")
+			err.RetryAfter = retryAfter
+			return nil, err
+		}
 	}
 
-	usr, err := userBus.Authenticate(ctx, *addr, pass)
+	usr, err := authenticateBasic(ctx, userBus, email, pass)
 	if err != nil {
-		return nil, errs.New(errs.Unauthenticated, err)
+		return nil, err
+	}
+
+	if throttle != nil {
+		throttle.succeed(identity)
 	}
 
 	claims := auth.Claims{
@@ -83,7 +97,7 @@ func Basic(ctx context.Context, ath *auth.Auth, userBus *userbus.Business, autho
 
 	subjectID, err := uuid.Parse(claims.Subject)
 	if err != nil {
-		return nil, errs.Newf(errs.Unauthenticated, "parsing subject: %s", err)
+		return nil, errs.NewfWithReason(errs.ReasonAuthenticationFailed, "parsing subject: %s", err)
 	}
 
 	ctx = setUserID(ctx, subjectID)
@@ -92,6 +106,28 @@ func Basic(ctx context.Context, ath *auth.Auth, userBus *userbus.Business, autho
 	return next(ctx)
 }
 
+// authenticateBasic verifies the credentials. Any failure caused by the
+// credentials is reported with the same message.
+func authenticateBasic(ctx context.Context, userBus *userbus.Business, email string, pass string) (userbus.User, error) {
+	addr, err := mail.ParseAddress(email)
+	if err != nil {
+		return userbus.User{}, errs.NewfWithReason(errs.ReasonAuthenticationFailed, "authentication failed")
+	}
+
+	usr, err := userBus.Authenticate(ctx, *addr, pass)
+	if err != nil {
+		switch {
+		case errors.Is(err, userbus.ErrNotFound),
+			errors.Is(err, userbus.ErrAuthenticationFailure),
+			errors.Is(err, userbus.ErrAccountLocked):
+			return userbus.User{}, errs.NewfWithReason(errs.ReasonAuthenticationFailed, "authentication failed")
+		}
+		return userbus.User{}, errs.Newf(errs.Internal, "authenticate: %s", err)
+	}
+
+	return usr, nil
+}
+
 func parseBasicAuth(auth string) (string, string, bool) {
 	parts := strings.Split(auth, " ")
 	if len(parts) != 2 || parts[0] != "Basic" {
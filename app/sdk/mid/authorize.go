@@ -3,7 +3,6 @@ package mid
 import (
 	"context"
 	"errors"
-	"time"
 
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
@@ -22,7 +21,7 @@ var ErrInvalidID = errors.New("ID is not in its proper form")
 func Authorize(ctx context.Context, log *logger.Logger, client *authclient.Client, rule string, next HandlerFunc) (Encoder, error) {
 	userID, err := GetUserID(ctx)
 	if err != nil {
-		return nil, errs.New(errs.Unauthenticated, err)
+		return nil, errs.NewWithReason(errs.ReasonAuthenticationFailed, err)
 	}
 
 	auth := authclient.Authorize{
@@ -31,11 +30,8 @@ func Authorize(ctx context.Context, log *logger.Logger, client *authclient.Clien
 		Rule:   rule,
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
 	if err := client.Authorize(ctx, auth); err != nil {
-		return nil, errs.New(errs.Unauthenticated, err)
+		return nil, errs.NewWithReason(errs.ReasonAuthorizationFailed, err)
 	}
 
 	return next(ctx)
@@ -58,8 +54,10 @@ func AuthorizeUser(ctx context.Context, log *logger.Logger, client *authclient.C
 		usr, err := userBus.QueryByID(ctx, userID)
 		if err != nil {
 			switch {
+			// A user that doesn't exist fails like any other authorization
+			// so callers can't use this to learn which ids exist.
 			case errors.Is(err, userbus.ErrNotFound):
-				return nil, errs.New(errs.Unauthenticated, err)
+				return nil, errs.NewWithReason(errs.ReasonAuthorizationFailed, err)
 			default:
 				return nil, errs.Newf(errs.Unauthenticated, "querybyid: userID[%s]: %s", userID, err)
 			}
@@ -68,9 +66,6 @@ func AuthorizeUser(ctx context.Context, log *logger.Logger, client *authclient.C
 		ctx = setUser(ctx, usr)
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
 	auth := authclient.Authorize{
 		Claims: GetClaims(ctx),
 		UserID: userID,
@@ -78,7 +73,7 @@ func AuthorizeUser(ctx context.Context, log *logger.Logger, client *authclient.C
 	}
 
 	if err := client.Authorize(ctx, auth); err != nil {
-		return nil, errs.New(errs.Unauthenticated, err)
+		return nil, errs.NewWithReason(errs.ReasonAuthorizationFailed, err)
 	}
 
 	return next(ctx)
@@ -102,7 +97,7 @@ func AuthorizeProduct(ctx context.Context, log *logger.Logger, client *authclien
 		if err != nil {
 			switch {
 			case errors.Is(err, productbus.ErrNotFound):
-				return nil, errs.New(errs.Unauthenticated, err)
+				return nil, errs.NewWithReason(errs.ReasonAuthorizationFailed, err)
 			default:
 				return nil, errs.Newf(errs.Internal, "querybyid: productID[%s]: %s", productID, err)
 			}
@@ -112,9 +107,6 @@ func AuthorizeProduct(ctx context.Context, log *logger.Logger, client *authclien
 		ctx = setProduct(ctx, prd)
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
 	auth := authclient.Authorize{
 		UserID: userID,
 		Claims: GetClaims(ctx),
@@ -122,7 +114,7 @@ func AuthorizeProduct(ctx context.Context, log *logger.Logger, client *authclien
 	}
 
 	if err := client.Authorize(ctx, auth); err != nil {
-		return nil, errs.New(errs.Unauthenticated, err)
+		return nil, errs.NewWithReason(errs.ReasonAuthorizationFailed, err)
 	}
 
 	return next(ctx)
@@ -146,7 +138,7 @@ func AuthorizeHome(ctx context.Context, log *logger.Logger, client *authclient.C
 		if err != nil {
 			switch {
 			case errors.Is(err, homebus.ErrNotFound):
-				return nil, errs.New(errs.Unauthenticated, err)
+				return nil, errs.NewWithReason(errs.ReasonAuthorizationFailed, err)
 			default:
 				return nil, errs.Newf(errs.Unauthenticated, "querybyid: homeID[%s]: %s", homeID, err)
 			}
@@ -156,9 +148,6 @@ func AuthorizeHome(ctx context.Context, log *logger.Logger, client *authclient.C
 		ctx = setHome(ctx, hme)
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
 	auth := authclient.Authorize{
 		Claims: GetClaims(ctx),
 		UserID: userID,
@@ -166,7 +155,7 @@ func AuthorizeHome(ctx context.Context, log *logger.Logger, client *authclient.C
 	}
 
 	if err := client.Authorize(ctx, auth); err != nil {
-		return nil, errs.New(errs.Unauthenticated, err)
+		return nil, errs.NewWithReason(errs.ReasonAuthorizationFailed, err)
 	}
 
 	return next(ctx)
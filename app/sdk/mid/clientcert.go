@@ -0,0 +1,45 @@
+package mid
+
+import (
+	"context"
+	"crypto/tls"
+
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// ClientCert authenticates the caller with the client certificate verified
+// during the TLS handshake. The common name of the certificate subject becomes
+// the subject of the claims and its organizational units become the roles, so
+// the identity can be checked by Authorize the same as a token. The user id is
+// only set when the common name is a uuid.
+func ClientCert(ctx context.Context, state *tls.ConnectionState, next HandlerFunc) (Encoder, error) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, errs.NewfWithReason(errs.ReasonAuthenticationFailed, "client certificate required")
+	}
+
+	subject := state.VerifiedChains[0][0].Subject
+	if subject.CommonName == "" {
+		return nil, errs.NewfWithReason(errs.ReasonAuthenticationFailed, "client certificate has no common name")
+	}
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: subject.CommonName,
+		},
+		Roles: subject.OrganizationalUnit,
+	}
+
+	userID, err := uuid.Parse(subject.CommonName)
+	if err != nil {
+		userID = uuid.Nil
+	}
+
+	ctx = setClientCert(ctx, subject)
+	ctx = setUserID(ctx, userID)
+	ctx = setClaims(ctx, claims)
+
+	return next(ctx)
+}
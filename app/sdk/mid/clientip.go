@@ -0,0 +1,154 @@
+package mid
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// TrustedProxies is the set of networks whose forwarding headers are trusted
+// to report the address of the client.
+type TrustedProxies struct {
+	prefixes []netip.Prefix
+}
+
+// ParseTrustedProxies parses the list of CIDRs for the trusted proxies. A
+// single address is treated as a network containing only that address.
+func ParseTrustedProxies(cidrs []string) (*TrustedProxies, error) {
+	var tp TrustedProxies
+
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		if !strings.Contains(cidr, "/") {
+			addr, err := netip.ParseAddr(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("parse proxy[%s]: %w", cidr, err)
+			}
+
+			tp.prefixes = append(tp.prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxy[%s]: %w", cidr, err)
+		}
+
+		tp.prefixes = append(tp.prefixes, prefix.Masked())
+	}
+
+	return &tp, nil
+}
+
+// trusted reports whether the address belongs to a trusted proxy.
+func (tp *TrustedProxies) trusted(addr netip.Addr) bool {
+	if tp == nil {
+		return false
+	}
+
+	for _, prefix := range tp.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientIP resolves the address of the client and stores it in the context.
+// The forwarding headers are only consulted when the request came from a
+// trusted proxy, and are walked from the closest hop back until an address
+// that isn't a trusted proxy is found. This way addresses a client adds to
+// the headers itself are never used. The Forwarded header takes precedence
+// over X-Forwarded-For.
+func ClientIP(ctx context.Context, tp *TrustedProxies, remoteAddr string, forwarded []string, forwardedFor []string, next HandlerFunc) (Encoder, error) {
+	client, ok := parseHost(remoteAddr)
+	if !ok {
+		return next(ctx)
+	}
+
+	if tp.trusted(client) {
+		hops := parseForwarded(forwarded)
+		if len(forwarded) == 0 {
+			hops = parseForwardedFor(forwardedFor)
+		}
+
+		for i := len(hops) - 1; i >= 0; i-- {
+
+			// An address that can't be parsed can't be checked, so the last
+			// trusted proxy is the best that is known about the client.
+			if !hops[i].IsValid() {
+				break
+			}
+
+			client = hops[i]
+			if !tp.trusted(client) {
+				break
+			}
+		}
+	}
+
+	ctx = setClientIP(ctx, client.String())
+
+	return next(ctx)
+}
+
+// parseForwardedFor parses the X-Forwarded-For header values into the list
+// of hops in the order they were added.
+func parseForwardedFor(values []string) []netip.Addr {
+	var hops []netip.Addr
+
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			addr, _ := parseHost(strings.TrimSpace(hop))
+			hops = append(hops, addr)
+		}
+	}
+
+	return hops
+}
+
+// parseForwarded parses the for parameter of the Forwarded header values
+// defined in RFC 7239 into the list of hops in the order they were added.
+func parseForwarded(values []string) []netip.Addr {
+	var hops []netip.Addr
+
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			var addr netip.Addr
+
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok || !strings.EqualFold(key, "for") {
+					continue
+				}
+
+				addr, _ = parseHost(strings.Trim(val, `"`))
+			}
+
+			hops = append(hops, addr)
+		}
+	}
+
+	return hops
+}
+
+// parseHost parses an address that may have a port and may have the host in
+// brackets.
+func parseHost(host string) (netip.Addr, bool) {
+	if addrPort, err := netip.ParseAddrPort(host); err == nil {
+		return addrPort.Addr().Unmap(), true
+	}
+
+	addr, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]"))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return addr.Unmap(), true
+}
@@ -0,0 +1,39 @@
+package mid
+
+import (
+	"context"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+)
+
+// Limiter caps the number of requests that can be in-flight at the same time.
+type Limiter struct {
+	sem        chan struct{}
+	retryAfter time.Duration
+}
+
+// NewLimiter constructs a limiter that allows max requests to be in-flight.
+// Clients that are rejected are told to retry after the specified duration.
+func NewLimiter(max int, retryAfter time.Duration) *Limiter {
+	return &Limiter{
+		sem:        make(chan struct{}, max),
+		retryAfter: retryAfter,
+	}
+}
+
+// ConcurrencyLimit rejects the request with an Unavailable error when the
+// limiter has no slots available. The slot is released when the call chain
+// returns, even if it panics.
+func ConcurrencyLimit(ctx context.Context, l *Limiter, next HandlerFunc) (Encoder, error) {
+	select {
+	case l.sem <- struct{}{}:
+	default:
+		err := errs.Newf(errs.Unavailable, "too many requests in-flight, try again later")
+		err.RetryAfter = l.retryAfter
+		return nil, err
+	}
+	defer func() { <-l.sem }()
+
+	return next(ctx)
+}
@@ -0,0 +1,40 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+)
+
+// Consistency gives clients read-your-writes consistency when reads are
+// served by replicas. A successful write returns a consistency token through
+// the setToken function and a read that presents one sees the write. Tokens
+// that can't be parsed are ignored. Every read made by a request that isn't
+// safe goes to the primary, since the rows it loads may be written back.
+func Consistency(ctx context.Context, method string, token string, setToken func(ctx context.Context, token string), next HandlerFunc) (Encoder, error) {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		ctx = sqldb.WithPrimary(ctx)
+	}
+
+	if token != "" {
+		if written, err := sqldb.ParseConsistencyToken(token); err == nil {
+			ctx = sqldb.WithConsistencyToken(ctx, written)
+		}
+	}
+
+	resp, err := next(ctx)
+	if err != nil {
+		return resp, err
+	}
+
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		setToken(ctx, sqldb.NewConsistencyToken(time.Now()))
+	}
+
+	return resp, nil
+}
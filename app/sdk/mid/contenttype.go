@@ -0,0 +1,36 @@
+package mid
+
+import (
+	"context"
+	"mime"
+	"strings"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+)
+
+// ContentType rejects requests whose content type doesn't match one of the
+// media types. The only parameter that is tolerated is a utf-8 charset.
+func ContentType(ctx context.Context, contentType string, mediaTypes []string, next HandlerFunc) (Encoder, error) {
+	if contentType == "" {
+		return nil, errs.Newf(errs.UnsupportedMediaType, "missing content type, expecting %s", strings.Join(mediaTypes, ", "))
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, errs.Newf(errs.UnsupportedMediaType, "invalid content type %q", contentType)
+	}
+
+	for key, value := range params {
+		if key != "charset" || !strings.EqualFold(value, "utf-8") {
+			return nil, errs.Newf(errs.UnsupportedMediaType, "unsupported content type parameter %s=%s", key, value)
+		}
+	}
+
+	for _, mt := range mediaTypes {
+		if mediaType == mt {
+			return next(ctx)
+		}
+	}
+
+	return nil, errs.Newf(errs.UnsupportedMediaType, "unsupported content type %q, expecting %s", mediaType, strings.Join(mediaTypes, ", "))
+}
@@ -0,0 +1,108 @@
+package mid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ardanlabs/service/app/sdk/decode"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// defaultDebugLogMaxSize is the largest body logged when a size is not
+// configured.
+const defaultDebugLogMaxSize = 64 * 1024
+
+// DebugLogConfig represents the settings for logging request and response
+// bodies.
+type DebugLogConfig struct {
+	Redact  []string
+	MaxSize int
+}
+
+// Limit returns the largest body that will be logged.
+func (cfg DebugLogConfig) Limit() int {
+	if cfg.MaxSize <= 0 {
+		return defaultDebugLogMaxSize
+	}
+
+	return cfg.MaxSize
+}
+
+// DebugLog writes the request and response bodies to the logs at the debug
+// level. Fields named in the redact list are masked and bodies larger than
+// the max size are not logged. The request body must be read by the caller
+// and can be at most one byte over the max size so the caller doesn't need to
+// buffer it all. The response body is logged from what the capture function
+// reports was written to the client once the response has been sent.
+func DebugLog(ctx context.Context, log *logger.Logger, cfg DebugLogConfig, path string, method string, reqBody []byte, capture func(ctx context.Context, max int, fn func(body []byte)), next HandlerFunc) (Encoder, error) {
+	log.Debug(ctx, "debug request", "method", method, "path", path, "body", debugBody(cfg, reqBody))
+
+	// Capture one byte over the max size so a larger body is reported as
+	// too big to log.
+	capture(ctx, cfg.Limit()+1, func(body []byte) {
+		log.Debug(ctx, "debug response", "method", method, "path", path, "body", debugBody(cfg, body))
+	})
+
+	return next(ctx)
+}
+
+// debugBody returns the body as it should be logged.
+func debugBody(cfg DebugLogConfig, body []byte) string {
+	maxSize := cfg.Limit()
+
+	switch {
+	case len(body) == 0:
+		return ""
+
+	case len(body) > maxSize:
+		return fmt.Sprintf("[body exceeds %d bytes]", maxSize)
+	}
+
+	// A body that isn't JSON can't be redacted so it's never logged.
+	var v any
+	if err := decode.JSON(body, &v, decode.UseNumber()); err != nil {
+		return fmt.Sprintf("[non-json body of %d bytes]", len(body))
+	}
+
+	data, err := json.Marshal(redact(v, cfg.Redact))
+	if err != nil {
+		return fmt.Sprintf("[unable to redact body: %s]", err)
+	}
+
+	return string(data)
+}
+
+// redact walks the decoded JSON document and masks the value of any field
+// that matches one of the specified names regardless of case.
+func redact(v any, fields []string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if isRedacted(key, fields) {
+				v[key] = "***"
+				continue
+			}
+
+			v[key] = redact(value, fields)
+		}
+
+	case []any:
+		for i, value := range v {
+			v[i] = redact(value, fields)
+		}
+	}
+
+	return v
+}
+
+func isRedacted(key string, fields []string) bool {
+	for _, field := range fields {
+		if strings.EqualFold(key, field) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,55 @@
+package mid
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/metrics"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// Set of headers used to signal that an endpoint is deprecated.
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+	HeaderLink        = "Link"
+)
+
+// Deprecation describes an endpoint that is being retired. The date is when
+// the endpoint was deprecated and the sunset is when it will stop working.
+// The successor is the link to the endpoint that replaces it. A zero sunset
+// or an empty successor is left out of the response.
+type Deprecation struct {
+	Date      time.Time
+	Sunset    time.Time
+	Successor string
+}
+
+// Deprecated marks the response of a deprecated endpoint with the Deprecation
+// (RFC 9745) and Sunset (RFC 8594) headers, and a Link to the successor. Every
+// call is counted against the route and logged with the caller, so the
+// clients still using the endpoint can be found before it's removed.
+func Deprecated(ctx context.Context, log *logger.Logger, dep Deprecation, route string, setHeader func(ctx context.Context, key string, value string), next HandlerFunc) (Encoder, error) {
+	setHeader(ctx, HeaderDeprecation, fmt.Sprintf("@%d", dep.Date.Unix()))
+
+	if !dep.Sunset.IsZero() {
+		setHeader(ctx, HeaderSunset, dep.Sunset.UTC().Format(http.TimeFormat))
+	}
+
+	if dep.Successor != "" {
+		setHeader(ctx, HeaderLink, fmt.Sprintf("<%s>; rel=\"successor-version\"", dep.Successor))
+	}
+
+	calls := metrics.AddDeprecated(ctx, route)
+
+	args := []any{"route", route, "calls", calls, "client_ip", GetClientIP(ctx)}
+	if userID, err := GetUserID(ctx); err == nil {
+		args = append(args, "user_id", userID)
+	}
+
+	log.Info(ctx, "deprecated endpoint", args...)
+
+	return next(ctx)
+}
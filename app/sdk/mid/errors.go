@@ -2,11 +2,15 @@ package mid
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"path"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/tracer"
+	"github.com/ardanlabs/service/foundation/web"
 )
 
 // Errors handles errors coming out of the call chain.
@@ -20,12 +24,58 @@ func Errors(ctx context.Context, log *logger.Logger, next HandlerFunc) (Encoder,
 	span.RecordError(err)
 	defer span.End()
 
+	var timeoutErr *sqldb.TimeoutError
+
 	appErr, ok := err.(*errs.Error)
-	if !ok {
-		appErr = errs.Newf(errs.Internal, "Internal Server Error")
+	switch {
+	case (!ok || appErr.Code == errs.Internal) && errors.As(err, &timeoutErr):
+
+		// The app layer reports a failed call into the business layer as an
+		// internal error, but a statement that ran out of time is a timeout.
+		appErr = errs.New(errs.DeadlineExceeded, timeoutErr)
+
+	case (!ok || appErr.Code == errs.Internal) && errors.Is(err, context.DeadlineExceeded):
+
+		// A request that ran out of time is a timeout, while a request the
+		// client abandoned is reported as canceled.
+		appErr = errs.New(errs.DeadlineExceeded, context.DeadlineExceeded)
+
+	case (!ok || appErr.Code == errs.Internal) && errors.Is(err, context.Canceled):
+		appErr = errs.New(errs.Canceled, context.Canceled)
+
+	case !ok:
+		var paramErr *web.ParamError
+		var conflictErr *sqldb.ConflictError
+
+		switch {
+		case errs.IsFieldErrors(err):
+			appErr = errs.New(errs.InvalidArgument, err)
+
+		// The table and constraint of a constraint that isn't registered are
+		// only logged with the error, never sent to the client.
+		case errors.As(err, &conflictErr) && len(conflictErr.Fields) == 0:
+			appErr = errs.NewConflict(sqldb.ErrDBDuplicatedEntry, nil)
+
+		case errors.As(err, &conflictErr):
+			appErr = errs.NewConflict(conflictErr, conflictErr.Fields)
+
+		case errors.As(err, &paramErr):
+			appErr = errs.New(errs.InvalidArgument, paramErr)
+
+		default:
+			appErr = errs.Newf(errs.Internal, "Internal Server Error")
+		}
 	}
 
-	log.Info(ctx, "handled error during request", "err", err, "source_err_file", path.Base(appErr.FileName), "source_err_func", path.Base(appErr.FuncName))
+	args := []any{"err", err, "source_err_file", path.Base(appErr.FileName), "source_err_func", path.Base(appErr.FuncName)}
+
+	// A client that gives up on the request isn't a failure of the service,
+	// so only the errors the service is responsible for are logged as errors.
+	if appErr.HTTPStatus() >= http.StatusInternalServerError {
+		log.Error(ctx, "handled error during request", args...)
+	} else {
+		log.Info(ctx, "handled error during request", args...)
+	}
 
 	// Send the error to the transport package so the error can be
 	// used as the response.
@@ -0,0 +1,21 @@
+package mid
+
+import "time"
+
+// Attempt exposes attempt for testing.
+func (t *Throttle) Attempt(key string, now time.Time) (time.Duration, bool) {
+	return t.attempt(key, now)
+}
+
+// SetMaxEntries changes the number of identities tracked for testing.
+func (t *Throttle) SetMaxEntries(maxEntries int) {
+	t.maxEntries = maxEntries
+}
+
+// Entries returns the number of identities being tracked.
+func (t *Throttle) Entries() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.entries)
+}
@@ -0,0 +1,27 @@
+package mid
+
+import (
+	"context"
+
+	"github.com/ardanlabs/service/business/sdk/featureflag"
+	"github.com/google/uuid"
+)
+
+// FeatureFlags makes the flags available to the handlers and the business
+// logic they call through the context.
+func FeatureFlags(ctx context.Context, flags *featureflag.Flags, next HandlerFunc) (Encoder, error) {
+	ctx = featureflag.Set(ctx, flags)
+
+	return next(ctx)
+}
+
+// FlagUserID returns the id of the authenticated user for evaluating
+// feature flags. It's empty when the request isn't authenticated.
+func FlagUserID(ctx context.Context) string {
+	userID, err := GetUserID(ctx)
+	if err != nil || userID == uuid.Nil {
+		return ""
+	}
+
+	return userID.String()
+}
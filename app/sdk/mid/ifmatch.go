@@ -0,0 +1,32 @@
+package mid
+
+import (
+	"context"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/etag"
+)
+
+// ETagFunc returns the entity tag for the current state of the resource
+// the request is for.
+type ETagFunc func(ctx context.Context) (string, error)
+
+// IfMatch rejects requests that don't provide an If-Match header matching
+// the current entity tag of the resource, which prevents a client from
+// overwriting changes it hasn't seen.
+func IfMatch(ctx context.Context, ifMatch string, current ETagFunc, next HandlerFunc) (Encoder, error) {
+	if ifMatch == "" {
+		return nil, errs.Newf(errs.PreconditionRequired, "If-Match header is required")
+	}
+
+	tag, err := current(ctx)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, "etag: %s", err)
+	}
+
+	if !etag.Match(ifMatch, tag) {
+		return nil, errs.Newf(errs.PreconditionFailed, "resource has been modified")
+	}
+
+	return next(ctx)
+}
@@ -0,0 +1,56 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+)
+
+// LastModifiedFunc returns the time the resource the request is for was
+// last modified.
+type LastModifiedFunc func(ctx context.Context) (time.Time, error)
+
+// IfModifiedSince responds with a 304 when the resource hasn't been modified
+// since the time in the If-Modified-Since header, so the client can use its
+// cached copy. A missing or invalid header is ignored.
+func IfModifiedSince(ctx context.Context, ifModifiedSince string, current LastModifiedFunc, next HandlerFunc) (Encoder, error) {
+	if ifModifiedSince == "" {
+		return next(ctx)
+	}
+
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return next(ctx)
+	}
+
+	lastModified, err := current(ctx)
+	if err != nil {
+		return nil, errs.Newf(errs.Internal, "last modified: %s", err)
+	}
+
+	// The header only has second precision.
+	lastModified = lastModified.Truncate(time.Second)
+	if lastModified.After(since) {
+		return next(ctx)
+	}
+
+	return notModified{lastModified: lastModified}, nil
+}
+
+type notModified struct {
+	lastModified time.Time
+}
+
+func (nm notModified) Encode() ([]byte, string, error) {
+	return nil, "", nil
+}
+
+func (nm notModified) HTTPStatus() int {
+	return http.StatusNotModified
+}
+
+func (nm notModified) HTTPHeader() http.Header {
+	return http.Header{"Last-Modified": []string{nm.lastModified.UTC().Format(http.TimeFormat)}}
+}
@@ -3,21 +3,23 @@ package mid
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
 )
 
-// Logger writes information about the request to the logs.
+// Logger writes information about the request to the logs. The fields added
+// to the logs once the request is authenticated are also on the lines logged
+// after it, including the completion of the request.
 func Logger(ctx context.Context, log *logger.Logger, path string, rawQuery string, method string, remoteAddr string, next HandlerFunc) (Encoder, error) {
-	now := time.Now()
+	ctx = logger.WithFields(ctx)
 
 	if rawQuery != "" {
 		path = fmt.Sprintf("%s?%s", path, rawQuery)
 	}
 
-	log.Info(ctx, "request started", "method", method, "path", path, "remoteaddr", remoteAddr)
+	log.Info(ctx, "request started", "method", method, "path", path, "remoteaddr", remoteAddr, "clientip", GetClientIP(ctx))
 
 	resp, err := next(ctx)
 
@@ -31,8 +33,8 @@ func Logger(ctx context.Context, log *logger.Logger, path string, rawQuery strin
 		}
 	}
 
-	log.Info(ctx, "request completed", "method", method, "path", path, "remoteaddr", remoteAddr,
-		"statuscode", statusCode, "since", time.Since(now).String())
+	log.Info(ctx, "request completed", "method", method, "path", path, "remoteaddr", remoteAddr, "clientip", GetClientIP(ctx),
+		"statuscode", statusCode, "since", web.Elapsed(ctx).String())
 
 	return resp, err
 }
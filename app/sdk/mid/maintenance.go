@@ -0,0 +1,74 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+)
+
+// ReasonMaintenance indicates the service is in maintenance mode and can't
+// process the request right now.
+var ReasonMaintenance = errs.NewReason("service.maintenance", errs.Unavailable)
+
+// MaintenanceMode maintains the state of maintenance mode so it can be
+// changed at runtime without a restart.
+type MaintenanceMode struct {
+	enabled    atomic.Bool
+	allowReads atomic.Bool
+	exempt     []string
+}
+
+// NewMaintenanceMode constructs the maintenance mode state. Requests for paths
+// that start with one of the exempt prefixes are always processed.
+func NewMaintenanceMode(enabled bool, allowReads bool, exempt ...string) *MaintenanceMode {
+	var m MaintenanceMode
+	m.enabled.Store(enabled)
+	m.allowReads.Store(allowReads)
+	m.exempt = exempt
+
+	return &m
+}
+
+// Set changes the maintenance mode state.
+func (m *MaintenanceMode) Set(enabled bool, allowReads bool) {
+	m.enabled.Store(enabled)
+	m.allowReads.Store(allowReads)
+}
+
+// Enabled reports if the service is in maintenance mode.
+func (m *MaintenanceMode) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// AllowReads reports if read-only requests are processed while the service
+// is in maintenance mode.
+func (m *MaintenanceMode) AllowReads() bool {
+	return m.allowReads.Load()
+}
+
+func (m *MaintenanceMode) isExempt(method string, path string) bool {
+	if m.AllowReads() && (method == http.MethodGet || method == http.MethodHead) {
+		return true
+	}
+
+	for _, prefix := range m.exempt {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Maintenance rejects requests with an Unavailable error while the service
+// is in maintenance mode unless the request is exempt.
+func Maintenance(ctx context.Context, m *MaintenanceMode, method string, path string, next HandlerFunc) (Encoder, error) {
+	if m.Enabled() && !m.isExempt(method, path) {
+		return nil, errs.NewfWithReason(ReasonMaintenance, "service is in maintenance mode, try again later")
+	}
+
+	return next(ctx)
+}
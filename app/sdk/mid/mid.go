@@ -3,6 +3,7 @@ package mid
 
 import (
 	"context"
+	"crypto/x509/pkix"
 	"errors"
 
 	"github.com/ardanlabs/service/app/sdk/auth"
@@ -10,6 +11,9 @@ import (
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/tracer"
+	"github.com/ardanlabs/service/foundation/web"
 	"github.com/google/uuid"
 )
 
@@ -24,37 +28,60 @@ type HandlerFunc func(ctx context.Context) (Encoder, error)
 
 // =============================================================================
 
-type ctxKey int
-
-const (
-	claimKey ctxKey = iota + 1
-	userIDKey
-	userKey
-	productKey
-	homeKey
-	trKey
+// The set of values middleware stores in the context for handlers.
+var (
+	claimKey     = web.NewContextKey[auth.Claims]("claims")
+	userIDKey    = web.NewContextKey[uuid.UUID]("user_id")
+	userKey      = web.NewContextKey[userbus.User]("user")
+	productKey   = web.NewContextKey[productbus.Product]("product")
+	homeKey      = web.NewContextKey[homebus.Home]("home")
+	trKey        = web.NewContextKey[sqldb.CommitRollbacker]("tran")
+	requestIDKey = web.NewContextKey[string]("request_id")
+	clientIPKey  = web.NewContextKey[string]("client_ip")
+	certKey      = web.NewContextKey[pkix.Name]("client_cert")
 )
 
+// setClaims stores the authenticated claims in the context. The tenant of
+// the claims replaces any tenant in the baggage, so only the authenticated
+// tenant is propagated to downstream services, and it's added to the log
+// lines of the request from then on.
 func setClaims(ctx context.Context, claims auth.Claims) context.Context {
-	return context.WithValue(ctx, claimKey, claims)
+	ctx = tracer.DeleteBaggage(ctx, tracer.BaggageTenantID)
+
+	if claims.Tenant != "" {
+		ctx = logger.AddFields(ctx, "tenant_id", claims.Tenant)
+
+		if bCtx, err := tracer.SetBaggage(ctx, tracer.BaggageTenantID, claims.Tenant); err == nil {
+			ctx = bCtx
+		}
+	}
+
+	return claimKey.Set(ctx, claims)
 }
 
 // GetClaims returns the claims from the context.
 func GetClaims(ctx context.Context) auth.Claims {
-	v, ok := ctx.Value(claimKey).(auth.Claims)
-	if !ok {
-		return auth.Claims{}
-	}
+	v, _ := claimKey.Get(ctx)
 	return v
 }
 
+// GetTenantID returns the tenant of the authenticated claims. It's empty when
+// the request isn't authenticated or the claims have no tenant.
+func GetTenantID(ctx context.Context) string {
+	return GetClaims(ctx).Tenant
+}
+
+// setUserID stores the authenticated user in the context. The user is added
+// to the log lines of the request from then on.
 func setUserID(ctx context.Context, userID uuid.UUID) context.Context {
-	return context.WithValue(ctx, userIDKey, userID)
+	ctx = logger.AddFields(ctx, "user_id", userID.String())
+
+	return userIDKey.Set(ctx, userID)
 }
 
 // GetUserID returns the user id from the context.
 func GetUserID(ctx context.Context) (uuid.UUID, error) {
-	v, ok := ctx.Value(userIDKey).(uuid.UUID)
+	v, ok := userIDKey.Get(ctx)
 	if !ok {
 		return uuid.UUID{}, errors.New("user id not found in context")
 	}
@@ -63,12 +90,12 @@ func GetUserID(ctx context.Context) (uuid.UUID, error) {
 }
 
 func setUser(ctx context.Context, usr userbus.User) context.Context {
-	return context.WithValue(ctx, userKey, usr)
+	return userKey.Set(ctx, usr)
 }
 
 // GetUser returns the user from the context.
 func GetUser(ctx context.Context) (userbus.User, error) {
-	v, ok := ctx.Value(userKey).(userbus.User)
+	v, ok := userKey.Get(ctx)
 	if !ok {
 		return userbus.User{}, errors.New("user not found in context")
 	}
@@ -77,12 +104,12 @@ func GetUser(ctx context.Context) (userbus.User, error) {
 }
 
 func setProduct(ctx context.Context, prd productbus.Product) context.Context {
-	return context.WithValue(ctx, productKey, prd)
+	return productKey.Set(ctx, prd)
 }
 
 // GetProduct returns the product from the context.
 func GetProduct(ctx context.Context) (productbus.Product, error) {
-	v, ok := ctx.Value(productKey).(productbus.Product)
+	v, ok := productKey.Get(ctx)
 	if !ok {
 		return productbus.Product{}, errors.New("product not found in context")
 	}
@@ -91,12 +118,12 @@ func GetProduct(ctx context.Context) (productbus.Product, error) {
 }
 
 func setHome(ctx context.Context, hme homebus.Home) context.Context {
-	return context.WithValue(ctx, homeKey, hme)
+	return homeKey.Set(ctx, hme)
 }
 
 // GetHome returns the home from the context.
 func GetHome(ctx context.Context) (homebus.Home, error) {
-	v, ok := ctx.Value(homeKey).(homebus.Home)
+	v, ok := homeKey.Get(ctx)
 	if !ok {
 		return homebus.Home{}, errors.New("home not found in context")
 	}
@@ -105,15 +132,51 @@ func GetHome(ctx context.Context) (homebus.Home, error) {
 }
 
 func setTran(ctx context.Context, tx sqldb.CommitRollbacker) context.Context {
-	return context.WithValue(ctx, trKey, tx)
+	return trKey.Set(ctx, tx)
 }
 
 // GetTran retrieves the value that can manage a transaction.
 func GetTran(ctx context.Context) (sqldb.CommitRollbacker, error) {
-	v, ok := ctx.Value(trKey).(sqldb.CommitRollbacker)
+	v, ok := trKey.Get(ctx)
 	if !ok {
 		return nil, errors.New("transaction not found in context")
 	}
 
 	return v, nil
 }
+
+func setRequestID(ctx context.Context, requestID string) context.Context {
+	return requestIDKey.Set(ctx, requestID)
+}
+
+// GetRequestID returns the request id from the context.
+func GetRequestID(ctx context.Context) string {
+	v, _ := requestIDKey.Get(ctx)
+	return v
+}
+
+func setClientIP(ctx context.Context, clientIP string) context.Context {
+	return clientIPKey.Set(ctx, clientIP)
+}
+
+// GetClientIP returns the address of the client from the context. It's empty
+// when the address couldn't be resolved.
+func GetClientIP(ctx context.Context) string {
+	v, _ := clientIPKey.Get(ctx)
+	return v
+}
+
+func setClientCert(ctx context.Context, subject pkix.Name) context.Context {
+	return certKey.Set(ctx, subject)
+}
+
+// GetClientCert returns the subject of the verified client certificate from
+// the context.
+func GetClientCert(ctx context.Context) (pkix.Name, error) {
+	v, ok := certKey.Get(ctx)
+	if !ok {
+		return pkix.Name{}, errors.New("client certificate not found in context")
+	}
+
+	return v, nil
+}
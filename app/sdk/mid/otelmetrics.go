@@ -0,0 +1,62 @@
+package mid
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// RequestMetrics represents the set of OpenTelemetry metrics recorded for
+// every request.
+type RequestMetrics struct {
+	requests *otel.Counter
+	duration *otel.Histogram
+}
+
+// NewRequestMetrics registers the request metrics with the exporter.
+func NewRequestMetrics(exp *otel.Exporter) *RequestMetrics {
+	return &RequestMetrics{
+		requests: exp.Counter("http.server.requests", "Number of requests handled.", "{request}"),
+		duration: exp.Histogram("http.server.request.duration", "Duration of requests.", "s",
+			[]float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}),
+	}
+}
+
+// OTelMetrics records the number of requests and their latency.
+func OTelMetrics(ctx context.Context, rm *RequestMetrics, method string, next HandlerFunc) (Encoder, error) {
+	resp, err := next(ctx)
+
+	attrs := []otel.Attr{
+		{Key: "http.request.method", Value: method},
+		{Key: "http.response.status_code", Value: strconv.Itoa(statusCode(resp, err))},
+	}
+
+	rm.requests.Add(1, attrs...)
+	rm.duration.Record(web.Elapsed(ctx).Seconds(), attrs...)
+
+	return resp, err
+}
+
+// statusCode returns the http status code that will be sent for the response.
+func statusCode(resp Encoder, err error) int {
+	if err != nil {
+		if v, ok := err.(*errs.Error); ok {
+			return v.HTTPStatus()
+		}
+		return http.StatusInternalServerError
+	}
+
+	switch v := resp.(type) {
+	case interface{ HTTPStatus() int }:
+		return v.HTTPStatus()
+
+	case nil:
+		return http.StatusNoContent
+	}
+
+	return http.StatusOK
+}
@@ -8,18 +8,32 @@ import (
 	"github.com/ardanlabs/service/app/sdk/metrics"
 )
 
+// PanicMapper converts a recovered panic value into an error. It returns nil
+// when it doesn't recognize the value, leaving it to the next mapper or the
+// default internal error.
+type PanicMapper func(rec any) *errs.Error
+
 // Panics recovers from panics and converts the panic to an error so it is
-// reported in Metrics and handled in Errors.
-func Panics(ctx context.Context, next HandlerFunc) (resp Encoder, err error) {
+// reported in Metrics and handled in Errors. The mappers are tried in order
+// so a domain can signal intent with a typed panic. A panic none of them
+// recognize is reported as an internal error with the stack trace.
+func Panics(ctx context.Context, mappers []PanicMapper, next HandlerFunc) (resp Encoder, err error) {
 
 	// Defer a function to recover from a panic and set the err return
 	// variable after the fact.
 	defer func() {
 		if rec := recover(); rec != nil {
+			metrics.AddPanics(ctx)
+
+			for _, mapper := range mappers {
+				if appErr := mapper(rec); appErr != nil {
+					err = appErr
+					return
+				}
+			}
+
 			trace := debug.Stack()
 			err = errs.Newf(errs.Internal, "PANIC [%v] TRACE[%s]", rec, string(trace))
-
-			metrics.AddPanics(ctx)
 		}
 	}()
 
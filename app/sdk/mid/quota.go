@@ -0,0 +1,78 @@
+package mid
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/quota"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// ReasonQuotaExceeded indicates the tenant, or the client of a request without
+// a tenant, has made all the requests its quota allows within the window.
+var ReasonQuotaExceeded = errs.NewReason("tenant.quota_exceeded", errs.TooManyRequests)
+
+// Set of headers used to report the quota of a tenant.
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// Quota counts the request against the quota of the authenticated tenant
+// and rejects it when the quota is exhausted. Requests without a tenant are
+// counted against the anonymous quota of the client's address, so the
+// middleware must run after the request is authenticated. The state of the
+// quota is reported in the rate limit headers, with the reset as the number
+// of seconds until it happens. Tenants without a limit aren't restricted.
+// The request is allowed if the quota can't be checked, so an outage of the
+// store doesn't take down the service.
+func Quota(ctx context.Context, log *logger.Logger, q *quota.Quotas, setHeader func(ctx context.Context, key string, value string), next HandlerFunc) (Encoder, error) {
+	owner := GetTenantID(ctx)
+
+	var usage quota.Usage
+	var limited bool
+	var err error
+
+	switch owner {
+	case "":
+		owner = GetClientIP(ctx)
+		usage, limited, err = q.TakeAnonymous(ctx, owner)
+	default:
+		usage, limited, err = q.Take(ctx, owner)
+	}
+
+	if err != nil {
+		log.Error(ctx, "quota", "owner", owner, "ERROR", err)
+		return next(ctx)
+	}
+
+	if !limited {
+		return next(ctx)
+	}
+
+	reset := time.Until(usage.Reset)
+
+	setHeader(ctx, HeaderRateLimitLimit, strconv.Itoa(usage.Limit))
+	setHeader(ctx, HeaderRateLimitRemaining, strconv.Itoa(usage.Remaining))
+	setHeader(ctx, HeaderRateLimitReset, strconv.Itoa(ceilSeconds(reset)))
+
+	if !usage.Allowed {
+		err := errs.NewfWithReason(ReasonQuotaExceeded, "%s has exceeded its quota of %d requests", owner, usage.Limit)
+		err.RetryAfter = reset
+		return nil, err
+	}
+
+	return next(ctx)
+}
+
+func ceilSeconds(d time.Duration) int {
+	secs := int(d / time.Second)
+	if d%time.Second > 0 {
+		secs++
+	}
+
+	return max(0, secs)
+}
@@ -0,0 +1,53 @@
+package mid
+
+import (
+	"context"
+
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/tracer"
+	"github.com/google/uuid"
+)
+
+// maxRequestIDLen limits the size of a request id provided by a client.
+const maxRequestIDLen = 128
+
+// RequestID makes sure every request has an id clients can quote in support
+// tickets. The id provided by the client is used when it's valid, followed
+// by the id propagated in the baggage by an upstream service. Otherwise a
+// new id is generated. The id is added to every log line for the request
+// and propagated to downstream services.
+func RequestID(ctx context.Context, requestID string, next HandlerFunc) (Encoder, error) {
+	if !validRequestID(requestID) {
+		requestID = tracer.GetBaggage(ctx, tracer.BaggageRequestID)
+	}
+
+	if !validRequestID(requestID) {
+		requestID = uuid.NewString()
+	}
+
+	ctx = setRequestID(ctx, requestID)
+	ctx = logger.WithValues(ctx, "request_id", requestID)
+
+	if bCtx, err := tracer.SetBaggage(ctx, tracer.BaggageRequestID, requestID); err == nil {
+		ctx = bCtx
+	}
+
+	return next(ctx)
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLen {
+		return false
+	}
+
+	for _, c := range requestID {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
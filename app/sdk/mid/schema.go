@@ -0,0 +1,39 @@
+package mid
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/foundation/jsonschema"
+)
+
+// Schema validates the raw request body against the JSON Schema registered
+// for the route before the handler decodes it. Every violation is reported
+// as a field error identified by its JSON pointer.
+func Schema(ctx context.Context, schema *jsonschema.Schema, body []byte, next HandlerFunc) (Encoder, error) {
+	err := schema.Validate(body)
+	if err == nil {
+		return next(ctx)
+	}
+
+	var violations jsonschema.Violations
+	if !errors.As(err, &violations) {
+		return nil, errs.Newf(errs.InvalidArgument, "request: %s", err)
+	}
+
+	fields := make(errs.FieldErrors, len(violations))
+	for i, v := range violations {
+		field := v.Pointer
+		if field == "" {
+			field = "/"
+		}
+
+		fields[i] = errs.FieldError{
+			Field: field,
+			Err:   v.Message,
+		}
+	}
+
+	return nil, errs.New(errs.InvalidArgument, fields)
+}
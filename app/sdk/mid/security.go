@@ -0,0 +1,81 @@
+package mid
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Set of headers used to harden the responses of the service.
+const (
+	HeaderContentTypeOptions      = "X-Content-Type-Options"
+	HeaderFrameOptions            = "X-Frame-Options"
+	HeaderStrictTransportSecurity = "Strict-Transport-Security"
+	HeaderReferrerPolicy          = "Referrer-Policy"
+	HeaderContentSecurityPolicy   = "Content-Security-Policy"
+)
+
+// Set of defaults for the security headers.
+const (
+	defaultContentSecurityPolicy = "default-src 'self'; frame-ancestors 'none'"
+	defaultFrameOptions          = "DENY"
+	defaultReferrerPolicy        = "strict-origin-when-cross-origin"
+	defaultHSTSMaxAge            = 365 * 24 * time.Hour
+)
+
+// SecurityHeadersConfig represents the values of the security headers. A
+// zero value is replaced with its default.
+type SecurityHeadersConfig struct {
+	ContentSecurityPolicy string
+	FrameOptions          string
+	ReferrerPolicy        string
+	HSTSMaxAge            time.Duration
+}
+
+// withDefaults returns the config with the zero values replaced by their
+// defaults.
+func (cfg SecurityHeadersConfig) withDefaults() SecurityHeadersConfig {
+	if cfg.ContentSecurityPolicy == "" {
+		cfg.ContentSecurityPolicy = defaultContentSecurityPolicy
+	}
+
+	if cfg.FrameOptions == "" {
+		cfg.FrameOptions = defaultFrameOptions
+	}
+
+	if cfg.ReferrerPolicy == "" {
+		cfg.ReferrerPolicy = defaultReferrerPolicy
+	}
+
+	if cfg.HSTSMaxAge <= 0 {
+		cfg.HSTSMaxAge = defaultHSTSMaxAge
+	}
+
+	return cfg
+}
+
+// SecurityHeaders sets the security headers on every response. The headers
+// are set before the handler runs, so a route can override them. HSTS is only
+// sent over TLS, since browsers ignore it on plain HTTP responses.
+func SecurityHeaders(ctx context.Context, cfg SecurityHeadersConfig, tls bool, setHeader func(ctx context.Context, key string, value string), next HandlerFunc) (Encoder, error) {
+	cfg = cfg.withDefaults()
+
+	setHeader(ctx, HeaderContentTypeOptions, "nosniff")
+	setHeader(ctx, HeaderFrameOptions, cfg.FrameOptions)
+	setHeader(ctx, HeaderReferrerPolicy, cfg.ReferrerPolicy)
+	setHeader(ctx, HeaderContentSecurityPolicy, cfg.ContentSecurityPolicy)
+
+	if tls {
+		setHeader(ctx, HeaderStrictTransportSecurity, fmt.Sprintf("max-age=%d; includeSubDomains", int64(cfg.HSTSMaxAge/time.Second)))
+	}
+
+	return next(ctx)
+}
+
+// ContentSecurityPolicy replaces the Content-Security-Policy of the route.
+// It must run after the SecurityHeaders middleware.
+func ContentSecurityPolicy(ctx context.Context, policy string, setHeader func(ctx context.Context, key string, value string), next HandlerFunc) (Encoder, error) {
+	setHeader(ctx, HeaderContentSecurityPolicy, policy)
+
+	return next(ctx)
+}
@@ -0,0 +1,92 @@
+package mid
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// maxThrottleEntries is the number of identities tracked at a time so a
+// flood of different identities can't grow the memory without limit. The
+// identity with the oldest attempt is dropped first.
+const maxThrottleEntries = 10_000
+
+// Throttle tracks failed authentication attempts per identity and rejects
+// further attempts once too many have failed within a window. This slows
+// down brute force attacks against a single identity. Unknown identities are
+// tracked the same as known ones so throttling doesn't reveal which exist.
+type Throttle struct {
+	mu          sync.Mutex
+	maxFailures int
+	window      time.Duration
+	maxEntries  int
+	entries     map[string]*list.Element
+	order       *list.List
+}
+
+type throttleEntry struct {
+	key   string
+	count int
+	reset time.Time
+}
+
+// NewThrottle constructs a throttle that allows maxFailures failed attempts
+// per identity within the window.
+func NewThrottle(maxFailures int, window time.Duration) *Throttle {
+	return &Throttle{
+		maxFailures: maxFailures,
+		window:      window,
+		maxEntries:  maxThrottleEntries,
+		entries:     make(map[string]*list.Element),
+		order:       list.New(),
+	}
+}
+
+// attempt reports whether an attempt can be made for the identity. An
+// allowed attempt is counted as a failure until succeed is called, so
+// checking and counting happen together and a burst of parallel attempts
+// can't get past the limit. If not allowed, the duration until the next
+// attempt is allowed is returned.
+func (t *Throttle) attempt(key string, now time.Time) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	elem, exists := t.entries[key]
+	if !exists {
+		elem = t.order.PushFront(&throttleEntry{key: key})
+		t.entries[key] = elem
+
+		if t.order.Len() > t.maxEntries {
+			oldest := t.order.Back()
+			t.order.Remove(oldest)
+			delete(t.entries, oldest.Value.(*throttleEntry).key)
+		}
+	}
+
+	t.order.MoveToFront(elem)
+
+	e := elem.Value.(*throttleEntry)
+	if !now.Before(e.reset) {
+		e.count = 0
+		e.reset = now.Add(t.window)
+	}
+
+	if e.count >= t.maxFailures {
+		return e.reset.Sub(now), false
+	}
+
+	e.count++
+
+	return 0, true
+}
+
+// succeed clears the failed attempts for the identity.
+func (t *Throttle) succeed(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if elem, exists := t.entries[key]; exists {
+		t.order.Remove(elem)
+		delete(t.entries, key)
+	}
+}
@@ -0,0 +1,71 @@
+package mid_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+)
+
+func Test_ThrottleAttempt(t *testing.T) {
+	t.Parallel()
+
+	const maxFailures = 5
+
+	throttle := mid.NewThrottle(maxFailures, time.Minute)
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var allowed int
+
+	for range maxFailures * 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if _, ok := throttle.Attempt("bill@example.com", now); ok {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != maxFailures {
+		t.Errorf("Should only allow the max failures in a parallel burst : got[%d] exp[%d]", allowed, maxFailures)
+	}
+
+	if _, ok := throttle.Attempt("bill@example.com", now.Add(time.Minute)); !ok {
+		t.Errorf("Should allow an attempt once the window passed")
+	}
+}
+
+func Test_ThrottleMaxEntries(t *testing.T) {
+	t.Parallel()
+
+	const maxEntries = 3
+
+	throttle := mid.NewThrottle(1, time.Minute)
+	throttle.SetMaxEntries(maxEntries)
+	now := time.Now()
+
+	for i := range maxEntries * 2 {
+		throttle.Attempt(fmt.Sprintf("user%d@example.com", i), now)
+	}
+
+	if n := throttle.Entries(); n != maxEntries {
+		t.Errorf("Should cap the identities tracked : got[%d] exp[%d]", n, maxEntries)
+	}
+
+	if _, ok := throttle.Attempt(fmt.Sprintf("user%d@example.com", maxEntries*2-1), now); ok {
+		t.Errorf("Should still throttle the most recent identity")
+	}
+
+	if _, ok := throttle.Attempt("user0@example.com", now); !ok {
+		t.Errorf("Should have dropped the oldest identity")
+	}
+}
@@ -2,50 +2,26 @@ package mid
 
 import (
 	"context"
-	"database/sql"
-	"errors"
 
-	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
-	"github.com/ardanlabs/service/foundation/logger"
 )
 
-// BeginCommitRollback starts a transaction for the domain call.
-func BeginCommitRollback(ctx context.Context, log *logger.Logger, bgn sqldb.Beginner, next HandlerFunc) (Encoder, error) {
-	hasCommitted := false
+// BeginCommitRollback runs the domain call under a transaction started by
+// the executor. The transaction is committed when the call succeeds and
+// rolled back when it fails. An executor that retries runs the call again,
+// so it must be safe to call more than once.
+func BeginCommitRollback(ctx context.Context, exec sqldb.Executor, next HandlerFunc) (Encoder, error) {
+	var resp Encoder
 
-	log.Info(ctx, "BEGIN TRANSACTION")
-	tx, err := bgn.Begin()
-	if err != nil {
-		return nil, errs.Newf(errs.Internal, "BEGIN TRANSACTION: %s", err)
-	}
-
-	defer func() {
-		if !hasCommitted {
-			log.Info(ctx, "ROLLBACK TRANSACTION")
-		}
-
-		if err := tx.Rollback(); err != nil {
-			if errors.Is(err, sql.ErrTxDone) {
-				return
-			}
-			log.Info(ctx, "ROLLBACK TRANSACTION", "ERROR", err)
-		}
-	}()
+	err := exec.Execute(ctx, func(ctx context.Context, tx sqldb.CommitRollbacker) error {
+		var err error
+		resp, err = next(setTran(ctx, tx))
+		return err
+	})
 
-	ctx = setTran(ctx, tx)
-
-	resp, err := next(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Info(ctx, "COMMIT TRANSACTION")
-	if err := tx.Commit(); err != nil {
-		return nil, errs.Newf(errs.Internal, "COMMIT TRANSACTION: %s", err)
-	}
-
-	hasCommitted = true
-
-	return resp, err
+	return resp, nil
 }
@@ -0,0 +1,38 @@
+package mid
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/domain/userbus"
+)
+
+// ReasonEmailNotVerified is used when an authenticated user that hasn't
+// verified their email address attempts a restricted call.
+var ReasonEmailNotVerified = errs.NewReason("user.email_not_verified", errs.PermissionDenied)
+
+// EmailVerified restricts the call to authenticated users that have verified
+// their email address. It must run after authentication.
+func EmailVerified(ctx context.Context, userBus *userbus.Business, next HandlerFunc) (Encoder, error) {
+	userID, err := GetUserID(ctx)
+	if err != nil {
+		return nil, errs.NewWithReason(errs.ReasonAuthenticationFailed, err)
+	}
+
+	usr, err := userBus.QueryByID(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, userbus.ErrNotFound):
+			return nil, errs.NewWithReason(errs.ReasonAuthenticationFailed, err)
+		default:
+			return nil, errs.Newf(errs.Internal, "querybyid: userID[%s]: %s", userID, err)
+		}
+	}
+
+	if !usr.EmailVerified {
+		return nil, errs.NewfWithReason(ReasonEmailNotVerified, "email address has not been verified")
+	}
+
+	return next(ctx)
+}
@@ -0,0 +1,65 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/sdk/webhook"
+)
+
+// defaultWebhookMaxSize is the largest body accepted when a size is not
+// configured.
+const defaultWebhookMaxSize = 1 << 20
+
+// WebhookConfig represents the settings for verifying the webhooks the
+// service receives. The tolerance is the clock skew allowed between the
+// timestamp of a delivery and the time it's received.
+type WebhookConfig struct {
+	Secret    string
+	Tolerance time.Duration
+	MaxSize   int
+	Replays   *webhook.Replays
+}
+
+// Limit returns the largest body that will be accepted.
+func (cfg WebhookConfig) Limit() int {
+	if cfg.MaxSize <= 0 {
+		return defaultWebhookMaxSize
+	}
+
+	return cfg.MaxSize
+}
+
+// Webhook verifies the signature and timestamp of a webhook delivery and
+// rejects a delivery whose signature was already received. A delivery that
+// fails to be processed is forgotten, so the sender can retry it.
+func Webhook(ctx context.Context, cfg WebhookConfig, h http.Header, body []byte, next HandlerFunc) (Encoder, error) {
+	if len(body) > cfg.Limit() {
+		return nil, errs.Newf(errs.InvalidArgument, "webhook body exceeds %d bytes", cfg.Limit())
+	}
+
+	now := time.Now()
+
+	if err := webhook.Verify(h, cfg.Secret, now, cfg.Tolerance, body); err != nil {
+		return nil, errs.New(errs.Unauthenticated, err)
+	}
+
+	if cfg.Replays != nil {
+		if err := cfg.Replays.Check(h, now); err != nil {
+			if errors.Is(err, webhook.ErrReplayed) {
+				return nil, errs.New(errs.AlreadyExists, err)
+			}
+			return nil, errs.New(errs.Unauthenticated, err)
+		}
+	}
+
+	resp, err := next(ctx)
+	if err != nil && cfg.Replays != nil {
+		cfg.Replays.Forget(h)
+	}
+
+	return resp, err
+}
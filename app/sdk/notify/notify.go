@@ -0,0 +1,42 @@
+// Package notify provides support for delivering messages to users.
+package notify
+
+import (
+	"context"
+	"net/mail"
+
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// Message represents a message to be delivered to a user.
+type Message struct {
+	To      mail.Address
+	Subject string
+	Body    string
+}
+
+// Sender declares the behavior required to deliver a message.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSender delivers messages by writing them to the log. It's intended for
+// development where no mail server is available, since the messages can
+// contain secrets.
+type LogSender struct {
+	log *logger.Logger
+}
+
+// NewLogSender constructs a sender that writes messages to the log.
+func NewLogSender(log *logger.Logger) *LogSender {
+	return &LogSender{
+		log: log,
+	}
+}
+
+// Send writes the message to the log.
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	s.log.Info(ctx, "notify", "to", msg.To.Address, "subject", msg.Subject, "body", msg.Body)
+
+	return nil
+}
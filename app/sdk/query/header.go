@@ -0,0 +1,66 @@
+package query
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PageResult wraps a result so the paging information is also provided
+// as http headers. This allows clients to page through the results without
+// having to parse the response body.
+type PageResult[T any] struct {
+	Result[T]
+	url *url.URL
+}
+
+// NewPageResult constructs a page result for the specified result. The url
+// is the url of the request and is used to construct the next and prev links.
+func NewPageResult[T any](result Result[T], u *url.URL) PageResult[T] {
+	return PageResult[T]{
+		Result: result,
+		url:    u,
+	}
+}
+
+// HTTPHeader implements the web package httpHeader interface so the web
+// framework can add the paging headers to the response.
+func (r PageResult[T]) HTTPHeader() http.Header {
+	h := make(http.Header)
+	h.Set("X-Total-Count", strconv.Itoa(r.Total))
+
+	if r.url == nil || r.RowsPerPage <= 0 {
+		return h
+	}
+
+	lastPage := (r.Total + r.RowsPerPage - 1) / r.RowsPerPage
+
+	var links []string
+
+	if r.Page < lastPage {
+		links = append(links, r.link(r.Page+1, "next"))
+	}
+
+	if r.Page > 1 {
+		prev := min(r.Page-1, max(lastPage, 1))
+		links = append(links, r.link(prev, "prev"))
+	}
+
+	if len(links) > 0 {
+		h.Set("Link", strings.Join(links, ", "))
+	}
+
+	return h
+}
+
+func (r PageResult[T]) link(page int, rel string) string {
+	u := *r.url
+
+	q := u.Query()
+	q.Set("page", strconv.Itoa(page))
+	u.RawQuery = q.Encode()
+
+	return fmt.Sprintf("<%s>; rel=%q", u.String(), rel)
+}
@@ -2,8 +2,7 @@
 package query
 
 import (
-	"encoding/json"
-
+	"github.com/ardanlabs/service/app/sdk/encode"
 	"github.com/ardanlabs/service/business/sdk/page"
 )
 
@@ -27,6 +26,6 @@ func NewResult[T any](items []T, total int, page page.Page) Result[T] {
 
 // Encode implements the encoder interface.
 func (r Result[T]) Encode() ([]byte, string, error) {
-	data, err := json.Marshal(r)
+	data, err := encode.JSON(r)
 	return data, "application/json", err
 }
@@ -0,0 +1,69 @@
+package query_test
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/sdk/page"
+)
+
+func Test_PageHeaders(t *testing.T) {
+	u, err := url.Parse("/v1/users?page=1&rows=10&orderBy=name")
+	if err != nil {
+		t.Fatalf("Should be able to parse the url : %s", err)
+	}
+
+	table := []struct {
+		name  string
+		page  string
+		total int
+		link  string
+	}{
+		{
+			name:  "first",
+			page:  "1",
+			total: 25,
+			link:  `</v1/users?orderBy=name&page=2&rows=10>; rel="next"`,
+		},
+		{
+			name:  "middle",
+			page:  "2",
+			total: 25,
+			link:  `</v1/users?orderBy=name&page=3&rows=10>; rel="next", </v1/users?orderBy=name&page=1&rows=10>; rel="prev"`,
+		},
+		{
+			name:  "last",
+			page:  "3",
+			total: 25,
+			link:  `</v1/users?orderBy=name&page=2&rows=10>; rel="prev"`,
+		},
+		{
+			name:  "single",
+			page:  "1",
+			total: 5,
+			link:  "",
+		},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			result := query.NewResult([]string{}, tt.total, page.MustParse(tt.page, "10"))
+
+			h := query.NewPageResult(result, u).HTTPHeader()
+
+			if got := h.Get("X-Total-Count"); got != strconv.Itoa(tt.total) {
+				t.Errorf("Should get the total count : %s", got)
+			}
+
+			if got := h.Get("Link"); got != tt.link {
+				t.Logf("got: %s", got)
+				t.Logf("exp: %s", tt.link)
+				t.Errorf("Should get the expected link header")
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
@@ -0,0 +1,118 @@
+package quota
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Memory counts requests in memory, so each instance of the service enforces
+// the quotas on its own. The rolling window is approximated by weighting the
+// count of the previous fixed window by how much of it still overlaps the
+// rolling window, which only needs two counters per tenant.
+type Memory struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	lastPrune time.Time
+}
+
+type memoryEntry struct {
+	window   time.Duration
+	start    time.Time
+	previous int
+	current  int
+}
+
+// NewMemory constructs a memory storer.
+func NewMemory() *Memory {
+	return &Memory{
+		entries: make(map[string]memoryEntry),
+	}
+}
+
+// Take implements the Storer interface.
+func (m *Memory) Take(ctx context.Context, key string, limit Limit, now time.Time) (Usage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.prune(now, limit.Window)
+
+	e := m.entries[key]
+	start := now.Truncate(limit.Window)
+
+	switch {
+	case e.window != limit.Window || start.Sub(e.start) > limit.Window:
+		e = memoryEntry{window: limit.Window, start: start}
+
+	case start.Sub(e.start) == limit.Window:
+		e = memoryEntry{window: limit.Window, start: start, previous: e.current}
+	}
+
+	usage := Usage{
+		Limit: limit.Requests,
+		Reset: start.Add(limit.Window),
+	}
+
+	if e.count(now)+1 <= float64(limit.Requests) {
+		e.current++
+		usage.Allowed = true
+	} else {
+		usage.Reset = e.next(now, limit.Requests)
+	}
+
+	usage.Remaining = max(0, int(math.Floor(float64(limit.Requests)-e.count(now))))
+
+	m.entries[key] = e
+
+	return usage, nil
+}
+
+// count returns the number of requests made in the rolling window ending
+// at now.
+func (e memoryEntry) count(now time.Time) float64 {
+	return float64(e.previous)*e.weight(now) + float64(e.current)
+}
+
+// weight returns how much of the previous window overlaps the rolling
+// window ending at now.
+func (e memoryEntry) weight(now time.Time) float64 {
+	return 1 - float64(now.Sub(e.start))/float64(e.window)
+}
+
+// next returns when the count drops enough for another request, either later
+// in the current window as the previous window stops overlapping or in the
+// next window as the current one does.
+func (e memoryEntry) next(now time.Time, limit int) time.Time {
+	if e.previous > 0 && e.current < limit {
+		overlap := float64(limit-e.current-1) / float64(e.previous)
+		at := e.start.Add(time.Duration(float64(e.window) * (1 - overlap)))
+		if at.After(now) {
+			return at
+		}
+	}
+
+	end := e.start.Add(e.window)
+	if e.current == 0 {
+		return end
+	}
+
+	overlap := max(0, float64(limit-1)/float64(e.current))
+
+	return end.Add(time.Duration(math.Ceil(float64(e.window) * (1 - overlap))))
+}
+
+// prune removes the entries that no longer count towards any window, at
+// most once per window. The caller must hold the lock.
+func (m *Memory) prune(now time.Time, window time.Duration) {
+	if now.Sub(m.lastPrune) < window {
+		return
+	}
+	m.lastPrune = now
+
+	for key, e := range m.entries {
+		if now.Sub(e.start) >= 2*e.window {
+			delete(m.entries, key)
+		}
+	}
+}
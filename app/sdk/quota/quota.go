@@ -0,0 +1,155 @@
+// Package quota provides support for enforcing the number of requests each
+// tenant is contracted to make within a rolling window. Requests that don't
+// belong to a tenant are counted against the address of the client.
+package quota
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit represents the number of requests allowed within the window.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// ParseLimit parses a limit in the form of requests/window, like 1000/1h.
+func ParseLimit(value string) (Limit, error) {
+	requests, window, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("limit %q must be in the form requests/window", value)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("limit %q must have a positive number of requests", value)
+	}
+
+	d, err := time.ParseDuration(window)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("limit %q must have a positive window", value)
+	}
+
+	return Limit{Requests: n, Window: d}, nil
+}
+
+// ParseTenantLimits parses limits for tenants in the form of
+// tenant=requests/window, like acme=1000/1h.
+func ParseTenantLimits(values []string) (map[string]Limit, error) {
+	limits := make(map[string]Limit, len(values))
+
+	for _, value := range values {
+		tenantID, limit, ok := strings.Cut(value, "=")
+		if !ok || tenantID == "" {
+			return nil, fmt.Errorf("tenant limit %q must be in the form tenant=requests/window", value)
+		}
+
+		l, err := ParseLimit(limit)
+		if err != nil {
+			return nil, err
+		}
+
+		limits[tenantID] = l
+	}
+
+	return limits, nil
+}
+
+// =============================================================================
+
+// Usage represents the state of a tenant's quota after a request is counted.
+// Reset is when the next request will be allowed if the quota is exhausted
+// and otherwise when the current window ends.
+type Usage struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// Storer represents behavior for counting the requests made by a tenant
+// within a rolling window. It can be implemented in memory for a single
+// instance or with a shared store for every instance of the service.
+type Storer interface {
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Usage, error)
+}
+
+// Quotas enforces the limits for each tenant.
+type Quotas struct {
+	storer    Storer
+	def       Limit
+	anonymous Limit
+	tenants   map[string]Limit
+	now       func() time.Time
+}
+
+// WithAnonymous sets the limit of each client making requests without a
+// tenant. The default limit is used when this isn't set.
+func WithAnonymous(limit Limit) func(q *Quotas) {
+	return func(q *Quotas) {
+		q.anonymous = limit
+	}
+}
+
+// New constructs quotas that count requests with the storer. Tenants without
+// a specific limit get the default limit. A zero default limit doesn't
+// restrict those tenants.
+func New(storer Storer, def Limit, tenants map[string]Limit, options ...func(q *Quotas)) *Quotas {
+	q := Quotas{
+		storer:    storer,
+		def:       def,
+		anonymous: def,
+		tenants:   tenants,
+		now:       time.Now,
+	}
+
+	for _, option := range options {
+		option(&q)
+	}
+
+	return &q
+}
+
+// Take counts a request for the tenant. It reports false when the tenant
+// has no limit.
+func (q *Quotas) Take(ctx context.Context, tenantID string) (Usage, bool, error) {
+	limit, exists := q.tenants[tenantID]
+	if !exists {
+		limit = q.def
+	}
+
+	usage, limited, err := q.take(ctx, "tenant:"+tenantID, limit)
+	if err != nil {
+		return Usage{}, true, fmt.Errorf("take: tenant[%s]: %w", tenantID, err)
+	}
+
+	return usage, limited, nil
+}
+
+// TakeAnonymous counts a request made without a tenant against the address
+// of the client. It reports false when there is no anonymous limit.
+func (q *Quotas) TakeAnonymous(ctx context.Context, clientIP string) (Usage, bool, error) {
+	usage, limited, err := q.take(ctx, "ip:"+clientIP, q.anonymous)
+	if err != nil {
+		return Usage{}, true, fmt.Errorf("take: client[%s]: %w", clientIP, err)
+	}
+
+	return usage, limited, nil
+}
+
+func (q *Quotas) take(ctx context.Context, key string, limit Limit) (Usage, bool, error) {
+	if limit.Requests <= 0 || limit.Window <= 0 {
+		return Usage{}, false, nil
+	}
+
+	usage, err := q.storer.Take(ctx, key, limit, q.now())
+	if err != nil {
+		return Usage{}, true, err
+	}
+
+	return usage, true, nil
+}
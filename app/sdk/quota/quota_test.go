@@ -0,0 +1,86 @@
+package quota_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/quota"
+)
+
+func Test_ParseTenantLimits(t *testing.T) {
+	t.Parallel()
+
+	limits, err := quota.ParseTenantLimits([]string{"acme=1000/1h", "globex=10/1s"})
+	if err != nil {
+		t.Fatalf("Should be able to parse the limits : %s", err)
+	}
+
+	if got := limits["acme"]; got != (quota.Limit{Requests: 1000, Window: time.Hour}) {
+		t.Errorf("Should parse the limit for acme : %+v", got)
+	}
+
+	if got := limits["globex"]; got != (quota.Limit{Requests: 10, Window: time.Second}) {
+		t.Errorf("Should parse the limit for globex : %+v", got)
+	}
+
+	for _, value := range []string{"acme", "=10/1h", "acme=10", "acme=0/1h", "acme=10/0s", "acme=ten/1h"} {
+		if _, err := quota.ParseTenantLimits([]string{value}); err == nil {
+			t.Errorf("Should not be able to parse %q", value)
+		}
+	}
+}
+
+func Test_Memory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := quota.NewMemory()
+	limit := quota.Limit{Requests: 4, Window: time.Minute}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	take := func(offset time.Duration) quota.Usage {
+		usage, err := m.Take(ctx, "acme", limit, start.Add(offset))
+		if err != nil {
+			t.Fatalf("Should be able to take from the quota : %s", err)
+		}
+		return usage
+	}
+
+	for i := range 4 {
+		if usage := take(30 * time.Second); !usage.Allowed || usage.Remaining != 3-i {
+			t.Fatalf("Should allow request %d : %+v", i, usage)
+		}
+	}
+
+	usage := take(30 * time.Second)
+	if usage.Allowed {
+		t.Fatalf("Should not allow a request over the limit : %+v", usage)
+	}
+
+	// The requests from the previous window still count towards the rolling
+	// window until it no longer overlaps them.
+	if exp := start.Add(time.Minute + 15*time.Second); !usage.Reset.Equal(exp) {
+		t.Errorf("Should report when the next request is allowed, got %s, exp %s", usage.Reset, exp)
+	}
+
+	if usage := take(time.Minute + 10*time.Second); usage.Allowed {
+		t.Errorf("Should count the previous window in the rolling window : %+v", usage)
+	}
+
+	if usage := take(time.Minute + 15*time.Second); !usage.Allowed {
+		t.Errorf("Should allow a request once the previous window stops overlapping : %+v", usage)
+	}
+
+	// Other tenants have their own count.
+	usage, err := m.Take(ctx, "globex", limit, start.Add(30*time.Second))
+	if err != nil || !usage.Allowed || usage.Remaining != 3 {
+		t.Errorf("Should count each tenant on its own : %+v : %v", usage, err)
+	}
+
+	// Nothing from two windows ago counts.
+	if usage := take(3 * time.Minute); !usage.Allowed || usage.Remaining != 3 {
+		t.Errorf("Should start a new window : %+v", usage)
+	}
+}
@@ -0,0 +1,67 @@
+// Package runtimecfg maintains the small set of settings that can be changed
+// while the service is running without a restart.
+package runtimecfg
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// Config holds the runtime adjustable settings. The values are stored
+// atomically so they can be read by any goroutine on every request.
+type Config struct {
+	log         *logger.Logger
+	sampleRate  atomic.Uint64
+	maintenance *mid.MaintenanceMode
+}
+
+// New constructs the runtime config with the starting values. The log level
+// is read from and applied to the specified logger.
+func New(log *logger.Logger, sampleRate float64, maintenance *mid.MaintenanceMode) (*Config, error) {
+	cfg := Config{
+		log:         log,
+		maintenance: maintenance,
+	}
+
+	if err := cfg.SetSampleRate(sampleRate); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// LogLevel returns the minimum level being logged.
+func (cfg *Config) LogLevel() logger.Level {
+	return cfg.log.Level()
+}
+
+// SetLogLevel changes the minimum level being logged.
+func (cfg *Config) SetLogLevel(level logger.Level) {
+	cfg.log.SetLevel(level)
+}
+
+// SampleRate returns the probability a trace is sampled.
+func (cfg *Config) SampleRate() float64 {
+	return math.Float64frombits(cfg.sampleRate.Load())
+}
+
+// SetSampleRate changes the probability a trace is sampled. The rate must be
+// between 0 and 1.
+func (cfg *Config) SetSampleRate(rate float64) error {
+	if rate < 0 || rate > 1 || math.IsNaN(rate) {
+		return fmt.Errorf("sample rate %v must be between 0 and 1", rate)
+	}
+
+	cfg.sampleRate.Store(math.Float64bits(rate))
+
+	return nil
+}
+
+// Maintenance returns the maintenance mode state.
+func (cfg *Config) Maintenance() *mid.MaintenanceMode {
+	return cfg.maintenance
+}
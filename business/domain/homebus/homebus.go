@@ -26,8 +26,8 @@ var (
 // retrieve data.
 type Storer interface {
 	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
-	Create(ctx context.Context, hme Home) error
-	Update(ctx context.Context, hme Home) error
+	Create(ctx context.Context, hme Home) (Home, error)
+	Update(ctx context.Context, hme Home) (Home, error)
 	Delete(ctx context.Context, hme Home) error
 	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Home, error)
 	Count(ctx context.Context, filter QueryFilter) (int, error)
@@ -105,7 +105,8 @@ func (b *Business) Create(ctx context.Context, nh NewHome) (Home, error) {
 		DateUpdated: now,
 	}
 
-	if err := b.storer.Create(ctx, hme); err != nil {
+	hme, err = b.storer.Create(ctx, hme)
+	if err != nil {
 		return Home{}, fmt.Errorf("create: %w", err)
 	}
 
@@ -146,7 +147,8 @@ func (b *Business) Update(ctx context.Context, hme Home, uh UpdateHome) (Home, e
 
 	hme.DateUpdated = time.Now()
 
-	if err := b.storer.Update(ctx, hme); err != nil {
+	hme, err := b.storer.Update(ctx, hme)
+	if err != nil {
 		return Home{}, fmt.Errorf("update: %w", err)
 	}
 
@@ -46,19 +46,23 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (homebus.Storer, error) {
 	return &store, nil
 }
 
-// Create inserts a new home into the database.
-func (s *Store) Create(ctx context.Context, hme homebus.Home) error {
+// Create inserts a new home into the database and returns the home as it
+// was stored.
+func (s *Store) Create(ctx context.Context, hme homebus.Home) (homebus.Home, error) {
 	const q = `
     INSERT INTO homes
         (home_id, user_id, type, address_1, address_2, zip_code, city, state, country, date_created, date_updated)
     VALUES
-        (:home_id, :user_id, :type, :address_1, :address_2, :zip_code, :city, :state, :country, :date_created, :date_updated)`
+        (:home_id, :user_id, :type, :address_1, :address_2, :zip_code, :city, :state, :country, :date_created, :date_updated)
+    RETURNING
+        home_id, user_id, type, address_1, address_2, zip_code, city, state, country, date_created, date_updated`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBHome(hme)); err != nil {
-		return fmt.Errorf("namedexeccontext: %w", err)
+	var dbHme home
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBHome(hme), &dbHme); err != nil {
+		return homebus.Home{}, fmt.Errorf("db: %w", err)
 	}
 
-	return nil
+	return toBusHome(dbHme)
 }
 
 // Delete removes a home from the database.
@@ -82,8 +86,9 @@ func (s *Store) Delete(ctx context.Context, hme homebus.Home) error {
 	return nil
 }
 
-// Update replaces a home document in the database.
-func (s *Store) Update(ctx context.Context, hme homebus.Home) error {
+// Update replaces a home document in the database and returns the home as
+// it was stored.
+func (s *Store) Update(ctx context.Context, hme homebus.Home) (homebus.Home, error) {
 	const q = `
     UPDATE
         homes
@@ -97,13 +102,19 @@ func (s *Store) Update(ctx context.Context, hme homebus.Home) error {
         "type"          = :type,
         "date_updated"  = :date_updated
     WHERE
-        home_id = :home_id`
+        home_id = :home_id
+    RETURNING
+        home_id, user_id, type, address_1, address_2, zip_code, city, state, country, date_created, date_updated`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBHome(hme)); err != nil {
-		return fmt.Errorf("namedexeccontext: %w", err)
+	var dbHme home
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBHome(hme), &dbHme); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return homebus.Home{}, fmt.Errorf("db: %w", homebus.ErrNotFound)
+		}
+		return homebus.Home{}, fmt.Errorf("db: %w", err)
 	}
 
-	return nil
+	return toBusHome(dbHme)
 }
 
 // Query retrieves a list of existing homes from the database.
@@ -1,6 +1,6 @@
 package homebus
 
-import "fmt"
+import "github.com/ardanlabs/service/business/sdk/enum"
 
 type typeSet struct {
 	Single Type
@@ -16,7 +16,7 @@ var Types = typeSet{
 // =============================================================================
 
 // Set of known housing types.
-var types = make(map[string]Type)
+var types = enum.NewSet[Type]("type")
 
 // Type represents a type in the system.
 type Type struct {
@@ -24,9 +24,7 @@ type Type struct {
 }
 
 func newType(typ string) Type {
-	t := Type{typ}
-	types[typ] = t
-	return t
+	return types.Add(Type{typ})
 }
 
 // String returns the name of the type.
@@ -39,16 +37,22 @@ func (t Type) Equal(t2 Type) bool {
 	return t.name == t2.name
 }
 
+// MarshalText implements the encoding.TextMarshaler interface.
+func (t Type) MarshalText() ([]byte, error) {
+	return types.MarshalText(t)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. Only a
+// known type can be unmarshaled.
+func (t *Type) UnmarshalText(data []byte) error {
+	return types.UnmarshalText(data, t)
+}
+
 // =============================================================================
 
 // ParseType parses the string value and returns a type if one exists.
 func ParseType(value string) (Type, error) {
-	typ, exists := types[value]
-	if !exists {
-		return Type{}, fmt.Errorf("invalid type %q", value)
-	}
-
-	return typ, nil
+	return types.Parse(value)
 }
 
 // MustParseType parses the string value and returns a type if one exists. If
@@ -1,6 +1,7 @@
 package productbus
 
 import (
+	"github.com/ardanlabs/service/business/sdk/money"
 	"github.com/google/uuid"
 )
 
@@ -9,6 +10,6 @@ import (
 type QueryFilter struct {
 	ID       *uuid.UUID
 	Name     *Name
-	Cost     *float64
+	Cost     *money.Money
 	Quantity *int
 }
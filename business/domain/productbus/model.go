@@ -3,6 +3,7 @@ package productbus
 import (
 	"time"
 
+	"github.com/ardanlabs/service/business/sdk/money"
 	"github.com/google/uuid"
 )
 
@@ -11,7 +12,7 @@ type Product struct {
 	ID          uuid.UUID
 	UserID      uuid.UUID
 	Name        Name
-	Cost        float64
+	Cost        money.Money
 	Quantity    int
 	DateCreated time.Time
 	DateUpdated time.Time
@@ -21,7 +22,7 @@ type Product struct {
 type NewProduct struct {
 	UserID   uuid.UUID
 	Name     Name
-	Cost     float64
+	Cost     money.Money
 	Quantity int
 }
 
@@ -33,6 +34,6 @@ type NewProduct struct {
 // we make exceptions around marshalling/unmarshalling.
 type UpdateProduct struct {
 	Name     *Name
-	Cost     *float64
+	Cost     *money.Money
 	Quantity *int
 }
@@ -27,8 +27,8 @@ var (
 // retrieve data.
 type Storer interface {
 	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
-	Create(ctx context.Context, prd Product) error
-	Update(ctx context.Context, prd Product) error
+	Create(ctx context.Context, prd Product) (Product, error)
+	Update(ctx context.Context, prd Product) (Product, error)
 	Delete(ctx context.Context, prd Product) error
 	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Product, error)
 	Count(ctx context.Context, filter QueryFilter) (int, error)
@@ -88,7 +88,7 @@ func (b *Business) Create(ctx context.Context, np NewProduct) (Product, error) {
 		return Product{}, fmt.Errorf("user.querybyid: %s: %w", np.UserID, err)
 	}
 
-	if np.Cost < 0 {
+	if np.Cost.IsNegative() {
 		return Product{}, ErrInvalidCost
 	}
 
@@ -108,7 +108,8 @@ func (b *Business) Create(ctx context.Context, np NewProduct) (Product, error) {
 		DateUpdated: now,
 	}
 
-	if err := b.storer.Create(ctx, prd); err != nil {
+	prd, err = b.storer.Create(ctx, prd)
+	if err != nil {
 		return Product{}, fmt.Errorf("create: %w", err)
 	}
 
@@ -131,7 +132,8 @@ func (b *Business) Update(ctx context.Context, prd Product, up UpdateProduct) (P
 
 	prd.DateUpdated = time.Now()
 
-	if err := b.storer.Update(ctx, prd); err != nil {
+	prd, err := b.storer.Update(ctx, prd)
+	if err != nil {
 		return Product{}, fmt.Errorf("update: %w", err)
 	}
 
@@ -10,6 +10,7 @@ import (
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/money"
 	"github.com/ardanlabs/service/business/sdk/page"
 	"github.com/ardanlabs/service/business/sdk/unitest"
 	"github.com/google/go-cmp/cmp"
@@ -170,14 +171,14 @@ func create(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 			ExpResp: productbus.Product{
 				UserID:   sd.Users[0].ID,
 				Name:     productbus.MustParseName("Guitar"),
-				Cost:     10.34,
+				Cost:     money.MustParse("10.34"),
 				Quantity: 10,
 			},
 			ExcFunc: func(ctx context.Context) any {
 				np := productbus.NewProduct{
 					UserID:   sd.Users[0].ID,
 					Name:     productbus.MustParseName("Guitar"),
-					Cost:     10.34,
+					Cost:     money.MustParse("10.34"),
 					Quantity: 10,
 				}
 
@@ -216,7 +217,7 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 				ID:          sd.Users[0].Products[0].ID,
 				UserID:      sd.Users[0].ID,
 				Name:        productbus.MustParseName("Guitar"),
-				Cost:        10.34,
+				Cost:        money.MustParse("10.34"),
 				Quantity:    10,
 				DateCreated: sd.Users[0].Products[0].DateCreated,
 				DateUpdated: sd.Users[0].Products[0].DateCreated,
@@ -224,7 +225,7 @@ func update(busDomain dbtest.BusDomain, sd unitest.SeedData) []unitest.Table {
 			ExcFunc: func(ctx context.Context) any {
 				up := productbus.UpdateProduct{
 					Name:     dbtest.ProductNamePointer("Guitar"),
-					Cost:     dbtest.FloatPointer(10.34),
+					Cost:     dbtest.MoneyPointer("10.34"),
 					Quantity: dbtest.IntPointer(10),
 				}
 
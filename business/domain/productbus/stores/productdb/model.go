@@ -5,17 +5,18 @@ import (
 	"time"
 
 	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/money"
 	"github.com/google/uuid"
 )
 
 type product struct {
-	ID          uuid.UUID `db:"product_id"`
-	UserID      uuid.UUID `db:"user_id"`
-	Name        string    `db:"name"`
-	Cost        float64   `db:"cost"`
-	Quantity    int       `db:"quantity"`
-	DateCreated time.Time `db:"date_created"`
-	DateUpdated time.Time `db:"date_updated"`
+	ID          uuid.UUID   `db:"product_id"`
+	UserID      uuid.UUID   `db:"user_id"`
+	Name        string      `db:"name"`
+	Cost        money.Money `db:"cost"`
+	Quantity    int         `db:"quantity"`
+	DateCreated time.Time   `db:"date_created"`
+	DateUpdated time.Time   `db:"date_updated"`
 }
 
 func toDBProduct(bus productbus.Product) product {
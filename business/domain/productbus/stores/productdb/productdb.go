@@ -48,23 +48,26 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (productbus.Storer, error)
 
 // Create adds a Product to the sqldb. It returns the created Product with
 // fields like ID and DateCreated populated.
-func (s *Store) Create(ctx context.Context, prd productbus.Product) error {
+func (s *Store) Create(ctx context.Context, prd productbus.Product) (productbus.Product, error) {
 	const q = `
 	INSERT INTO products
 		(product_id, user_id, name, cost, quantity, date_created, date_updated)
 	VALUES
-		(:product_id, :user_id, :name, :cost, :quantity, :date_created, :date_updated)`
+		(:product_id, :user_id, :name, :cost, :quantity, :date_created, :date_updated)
+	RETURNING
+		product_id, user_id, name, cost, quantity, date_created, date_updated`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBProduct(prd)); err != nil {
-		return fmt.Errorf("namedexeccontext: %w", err)
+	var dbPrd product
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBProduct(prd), &dbPrd); err != nil {
+		return productbus.Product{}, fmt.Errorf("db: %w", err)
 	}
 
-	return nil
+	return toBusProduct(dbPrd)
 }
 
 // Update modifies data about a productbus. It will error if the specified ID is
 // invalid or does not reference an existing productbus.
-func (s *Store) Update(ctx context.Context, prd productbus.Product) error {
+func (s *Store) Update(ctx context.Context, prd productbus.Product) (productbus.Product, error) {
 	const q = `
 	UPDATE
 		products
@@ -74,13 +77,19 @@ func (s *Store) Update(ctx context.Context, prd productbus.Product) error {
 		"quantity" = :quantity,
 		"date_updated" = :date_updated
 	WHERE
-		product_id = :product_id`
+		product_id = :product_id
+	RETURNING
+		product_id, user_id, name, cost, quantity, date_created, date_updated`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBProduct(prd)); err != nil {
-		return fmt.Errorf("namedexeccontext: %w", err)
+	var dbPrd product
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBProduct(prd), &dbPrd); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return productbus.Product{}, fmt.Errorf("db: %w", productbus.ErrNotFound)
+		}
+		return productbus.Product{}, fmt.Errorf("db: %w", err)
 	}
 
-	return nil
+	return toBusProduct(dbPrd)
 }
 
 // Delete removes the product identified by a given ID.
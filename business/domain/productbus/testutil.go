@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math/rand"
 
+	"github.com/ardanlabs/service/business/sdk/money"
 	"github.com/google/uuid"
 )
 
@@ -18,7 +19,7 @@ func TestGenerateNewProducts(n int, userID uuid.UUID) []NewProduct {
 
 		np := NewProduct{
 			Name:     MustParseName(fmt.Sprintf("Name%d", idx)),
-			Cost:     float64(rand.Intn(500)),
+			Cost:     money.FromCents(int64(rand.Intn(500)) * 100),
 			Quantity: rand.Intn(50),
 			UserID:   userID,
 		}
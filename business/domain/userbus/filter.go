@@ -4,15 +4,32 @@ import (
 	"net/mail"
 	"time"
 
+	"github.com/ardanlabs/service/business/sdk/filter"
 	"github.com/google/uuid"
 )
 
+// Set of fields that filter expressions can use.
+const (
+	FilterByName        = "name"
+	FilterByEmail       = "email"
+	FilterByDepartment  = "department"
+	FilterByEnabled     = "enabled"
+	FilterByDateCreated = "date_created"
+	FilterByDateUpdated = "date_updated"
+)
+
 // QueryFilter holds the available fields a query can be filtered on.
 // We are using pointer semantics because the With API mutates the value.
+// Expr holds the parsed filter expressions, which can only use the
+// FilterBy fields.
 type QueryFilter struct {
 	ID               *uuid.UUID
 	Name             *Name
 	Email            *mail.Address
 	StartCreatedDate *time.Time
 	EndCreatedDate   *time.Time
+	Metadata         Metadata
+	Role             *Role
+	Tags             []string
+	Expr             filter.Filter
 }
@@ -0,0 +1,151 @@
+package userbus_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/mail"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/sdk/hasher"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_RehashOnLogin(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	store := usermem.NewStore()
+
+	oldHasher, err := hasher.New(hasher.Config{Algorithm: hasher.AlgBcrypt, BcryptCost: 4})
+	if err != nil {
+		t.Fatalf("Should be able to construct the old hasher : %s", err)
+	}
+
+	newHasher, err := hasher.New(hasher.Config{Algorithm: hasher.AlgPBKDF2, PBKDF2Iterations: 1000})
+	if err != nil {
+		t.Fatalf("Should be able to construct the new hasher : %s", err)
+	}
+
+	// Create the user with the outdated settings.
+	oldBus := userbus.NewBusiness(log, nil, store, userbus.WithHasher(oldHasher))
+
+	usr, err := oldBus.Create(ctx, userbus.NewUser{
+		Name:       userbus.MustParseName("Bill Kennedy"),
+		Email:      mail.Address{Address: "bill@example.com"},
+		Roles:      []userbus.Role{userbus.Roles.User},
+		Department: "IT",
+		Password:   "gophers",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create the user : %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	newBus := userbus.NewBusiness(log, nil, store, userbus.WithHasher(newHasher))
+
+	if _, err := newBus.Authenticate(ctx, usr.Email, "wrong"); !errors.Is(err, userbus.ErrAuthenticationFailure) {
+		t.Fatalf("Should fail to authenticate with the wrong password : %v", err)
+	}
+
+	stored, err := newBus.QueryByID(ctx, usr.ID)
+	if err != nil {
+		t.Fatalf("Should be able to query the user : %s", err)
+	}
+
+	if alg, _ := hasher.Algorithm(stored.PasswordHash); alg != hasher.AlgBcrypt {
+		t.Fatalf("Should not rehash after a failed login, got %q", alg)
+	}
+
+	if _, err := newBus.Authenticate(ctx, usr.Email, "gophers"); err != nil {
+		t.Fatalf("Should be able to authenticate with the old hash : %s", err)
+	}
+
+	stored, err = newBus.QueryByID(ctx, usr.ID)
+	if err != nil {
+		t.Fatalf("Should be able to query the user : %s", err)
+	}
+
+	if alg, _ := hasher.Algorithm(stored.PasswordHash); alg != hasher.AlgPBKDF2 {
+		t.Errorf("Should have rehashed the password with the new algorithm, got %q", alg)
+	}
+
+	if newHasher.NeedsRehash(stored.PasswordHash) {
+		t.Errorf("Should not need another rehash")
+	}
+
+	if !stored.DateUpdated.Equal(usr.DateUpdated) {
+		t.Errorf("Should not change the date updated on a rehash")
+	}
+
+	if _, err := newBus.Authenticate(ctx, usr.Email, "gophers"); err != nil {
+		t.Errorf("Should be able to authenticate with the upgraded hash : %s", err)
+	}
+}
+
+// countingHasher counts the number of passwords verified.
+type countingHasher struct {
+	hasher.Hasher
+	verified atomic.Int32
+}
+
+func (h *countingHasher) Verify(hash []byte, password string) error {
+	h.verified.Add(1)
+	return h.Hasher.Verify(hash, password)
+}
+
+func Test_AuthenticateVerifiesUnknown(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	h, err := hasher.New(hasher.Config{Algorithm: hasher.AlgBcrypt, BcryptCost: 4})
+	if err != nil {
+		t.Fatalf("Should be able to construct the hasher : %s", err)
+	}
+
+	ch := countingHasher{Hasher: h}
+	bus := userbus.NewBusiness(log, nil, usermem.NewStore(), userbus.WithHasher(&ch), userbus.WithLockout(1, time.Hour))
+
+	usr, err := bus.Create(ctx, userbus.NewUser{
+		Name:       userbus.MustParseName("Bill Kennedy"),
+		Email:      mail.Address{Address: "bill@example.com"},
+		Roles:      []userbus.Role{userbus.Roles.User},
+		Department: "IT",
+		Password:   "gophers",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create the user : %s", err)
+	}
+
+	// Every failure must verify a password so the time taken is the same
+	// whether or not the user exists or the account is locked.
+	table := []struct {
+		name  string
+		email mail.Address
+		exp   error
+	}{
+		{name: "unknown", email: mail.Address{Address: "nobody@example.com"}, exp: userbus.ErrNotFound},
+		{name: "wrong", email: usr.Email, exp: userbus.ErrAuthenticationFailure},
+		{name: "locked", email: usr.Email, exp: userbus.ErrAccountLocked},
+	}
+
+	for _, tt := range table {
+		before := ch.verified.Load()
+
+		if _, err := bus.Authenticate(ctx, tt.email, "wrong"); !errors.Is(err, tt.exp) {
+			t.Fatalf("%s: Should fail to authenticate with %v : %v", tt.name, tt.exp, err)
+		}
+
+		if n := ch.verified.Load() - before; n != 1 {
+			t.Errorf("%s: Should verify one password, got %d", tt.name, n)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package userbus_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/mail"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/sdk/hasher"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_Lockout(t *testing.T) {
+	t.Parallel()
+
+	const maxAttempts = 3
+	const duration = 200 * time.Millisecond
+
+	ctx := context.Background()
+	bus, usr := newLockoutBus(t, maxAttempts, duration)
+
+	// Failures below the limit don't lock the account and a successful
+	// login resets the count.
+	for i := 0; i < maxAttempts-1; i++ {
+		if _, err := bus.Authenticate(ctx, usr.Email, "wrong"); !errors.Is(err, userbus.ErrAuthenticationFailure) {
+			t.Fatalf("Should fail to authenticate with the wrong password : %v", err)
+		}
+	}
+
+	if _, err := bus.Authenticate(ctx, usr.Email, "gophers"); err != nil {
+		t.Fatalf("Should be able to authenticate below the limit : %s", err)
+	}
+
+	for i := 0; i < maxAttempts-1; i++ {
+		if _, err := bus.Authenticate(ctx, usr.Email, "wrong"); !errors.Is(err, userbus.ErrAuthenticationFailure) {
+			t.Fatalf("Should fail to authenticate with the wrong password : %v", err)
+		}
+	}
+
+	if _, err := bus.Authenticate(ctx, usr.Email, "gophers"); err != nil {
+		t.Fatalf("Should be able to authenticate since the count was reset : %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	for i := 0; i < maxAttempts; i++ {
+		if _, err := bus.Authenticate(ctx, usr.Email, "wrong"); !errors.Is(err, userbus.ErrAuthenticationFailure) {
+			t.Fatalf("Should fail to authenticate with the wrong password : %v", err)
+		}
+	}
+
+	if _, err := bus.Authenticate(ctx, usr.Email, "gophers"); !errors.Is(err, userbus.ErrAccountLocked) {
+		t.Fatalf("Should reject the correct password while locked : %v", err)
+	}
+
+	time.Sleep(duration + 50*time.Millisecond)
+
+	if _, err := bus.Authenticate(ctx, usr.Email, "gophers"); err != nil {
+		t.Fatalf("Should be able to authenticate after the lock expired : %s", err)
+	}
+}
+
+func Test_LockoutConcurrent(t *testing.T) {
+	t.Parallel()
+
+	const maxAttempts = 5
+
+	ctx := context.Background()
+	bus, usr := newLockoutBus(t, maxAttempts, time.Hour)
+
+	var wg sync.WaitGroup
+	wg.Add(maxAttempts)
+
+	for i := 0; i < maxAttempts; i++ {
+		go func() {
+			defer wg.Done()
+			bus.Authenticate(ctx, usr.Email, "wrong")
+		}()
+	}
+
+	wg.Wait()
+
+	if _, err := bus.Authenticate(ctx, usr.Email, "gophers"); !errors.Is(err, userbus.ErrAccountLocked) {
+		t.Fatalf("Should count every concurrent failure and lock the account : %v", err)
+	}
+}
+
+func newLockoutBus(t *testing.T, maxAttempts int, duration time.Duration) (*userbus.Business, userbus.User) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	h, err := hasher.New(hasher.Config{Algorithm: hasher.AlgBcrypt, BcryptCost: 4})
+	if err != nil {
+		t.Fatalf("Should be able to construct the hasher : %s", err)
+	}
+
+	bus := userbus.NewBusiness(log, nil, usermem.NewStore(), userbus.WithHasher(h), userbus.WithLockout(maxAttempts, duration))
+
+	usr, err := bus.Create(context.Background(), userbus.NewUser{
+		Name:       userbus.MustParseName("Bill Kennedy"),
+		Email:      mail.Address{Address: "bill@example.com"},
+		Roles:      []userbus.Role{userbus.Roles.User},
+		Department: "IT",
+		Password:   "gophers",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create the user : %s", err)
+	}
+
+	return bus, usr
+}
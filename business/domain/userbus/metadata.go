@@ -0,0 +1,89 @@
+package userbus
+
+import "slices"
+
+// Metadata represents flexible information about a user as a JSON object.
+// Values are the types produced by decoding JSON, so numbers are float64.
+type Metadata map[string]any
+
+// Contains reports whether the metadata contains the other metadata, using
+// the containment rules of a JSONB column. Every key of the other metadata
+// must be present with a contained value. Objects contain objects the same
+// way, an array contains an array when every element of the other array is
+// contained by one of its elements, and other values must be equal.
+func (m Metadata) Contains(other Metadata) bool {
+	return contains(map[string]any(m), map[string]any(other))
+}
+
+// Clone returns a copy of the metadata that doesn't share memory with the
+// original.
+func (m Metadata) Clone() Metadata {
+	if m == nil {
+		return nil
+	}
+
+	return cloneValue(map[string]any(m)).(map[string]any)
+}
+
+func contains(value any, other any) bool {
+	switch other := other.(type) {
+	case map[string]any:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return false
+		}
+
+		for k, v := range other {
+			ov, exists := obj[k]
+			if !exists || !contains(ov, v) {
+				return false
+			}
+		}
+
+		return true
+
+	case Metadata:
+		return contains(value, map[string]any(other))
+
+	case []any:
+		arr, ok := value.([]any)
+		if !ok {
+			return false
+		}
+
+		for _, v := range other {
+			found := slices.ContainsFunc(arr, func(av any) bool {
+				return contains(av, v)
+			})
+			if !found {
+				return false
+			}
+		}
+
+		return true
+
+	default:
+		return value == other
+	}
+}
+
+func cloneValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(value))
+		for k, v := range value {
+			m[k] = cloneValue(v)
+		}
+		return m
+
+	case []any:
+		arr := make([]any, len(value))
+		for i, v := range value {
+			arr[i] = cloneValue(v)
+		}
+		return arr
+
+	default:
+		return value
+	}
+}
@@ -9,15 +9,19 @@ import (
 
 // User represents information about an individual user.
 type User struct {
-	ID           uuid.UUID
-	Name         Name
-	Email        mail.Address
-	Roles        []Role
-	PasswordHash []byte
-	Department   string
-	Enabled      bool
-	DateCreated  time.Time
-	DateUpdated  time.Time
+	ID                  uuid.UUID
+	Name                Name
+	Email               mail.Address
+	Roles               []Role
+	PasswordHash        []byte
+	Department          string
+	Enabled             bool
+	EmailVerified       bool
+	Metadata            Metadata
+	Tags                []string
+	DatePasswordChanged time.Time
+	DateCreated         time.Time
+	DateUpdated         time.Time
 }
 
 // NewUser contains information needed to create a new user.
@@ -27,6 +31,8 @@ type NewUser struct {
 	Roles      []Role
 	Department string
 	Password   string
+	Metadata   Metadata
+	Tags       []string
 }
 
 // UpdateUser contains information needed to update a user.
@@ -37,4 +43,29 @@ type UpdateUser struct {
 	Department *string
 	Password   *string
 	Enabled    *bool
+	Metadata   Metadata
+	Tags       []string
+}
+
+// LoginAttempts represents the consecutive failed logins for a user and
+// when the account is locked until, if it's locked.
+type LoginAttempts struct {
+	UserID      uuid.UUID
+	Failed      int
+	LockedUntil time.Time
+}
+
+// Locked reports whether the account is locked at the specified time.
+func (la LoginAttempts) Locked(now time.Time) bool {
+	return la.LockedUntil.After(now)
+}
+
+// PasswordReset represents an outstanding request to reset the password of a
+// user. Only the hash of the token is kept so a leaked copy of the store
+// can't be used to reset passwords.
+type PasswordReset struct {
+	TokenHash   string
+	UserID      uuid.UUID
+	ExpiresAt   time.Time
+	DateCreated time.Time
 }
@@ -0,0 +1,183 @@
+package userbus_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/business/sdk/hasher"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// resetStore records the password resets written to the store.
+type resetStore struct {
+	*usermem.Store
+	resets []userbus.PasswordReset
+}
+
+func (s *resetStore) CreatePasswordReset(ctx context.Context, pr userbus.PasswordReset) error {
+	s.resets = append(s.resets, pr)
+	return s.Store.CreatePasswordReset(ctx, pr)
+}
+
+func Test_PasswordReset(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := resetStore{Store: usermem.NewStore()}
+	bus, usr := newResetBus(t, &store, time.Hour)
+
+	token, pr, err := bus.RequestPasswordReset(ctx, usr.Email)
+	if err != nil {
+		t.Fatalf("Should be able to request a password reset : %s", err)
+	}
+
+	if pr.UserID != usr.ID {
+		t.Errorf("Should issue the token for the user, got %s, exp %s", pr.UserID, usr.ID)
+	}
+
+	if d := time.Until(pr.ExpiresAt); d <= 59*time.Minute || d > time.Hour {
+		t.Errorf("Should expire in an hour : %s", d)
+	}
+
+	if len(store.resets) != 1 || store.resets[0].TokenHash == "" || strings.Contains(store.resets[0].TokenHash, token) {
+		t.Fatalf("Should only store a hash of the token : %+v", store.resets)
+	}
+
+	// -------------------------------------------------------------------------
+
+	if _, err := bus.ResetPassword(ctx, "not-a-token", "newpass"); !errors.Is(err, userbus.ErrInvalidResetToken) {
+		t.Fatalf("Should reject an unknown token : %v", err)
+	}
+
+	got, err := bus.ResetPassword(ctx, token, "newpass")
+	if err != nil {
+		t.Fatalf("Should be able to reset the password : %s", err)
+	}
+
+	if got.DatePasswordChanged.IsZero() {
+		t.Errorf("Should record when the password changed")
+	}
+
+	if _, err := bus.Authenticate(ctx, usr.Email, "gophers"); !errors.Is(err, userbus.ErrAuthenticationFailure) {
+		t.Errorf("Should not authenticate with the old password : %v", err)
+	}
+
+	if _, err := bus.Authenticate(ctx, usr.Email, "newpass"); err != nil {
+		t.Errorf("Should authenticate with the new password : %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	if _, err := bus.ResetPassword(ctx, token, "again"); !errors.Is(err, userbus.ErrInvalidResetToken) {
+		t.Fatalf("Should reject a token that was already used : %v", err)
+	}
+
+	if _, err := bus.Authenticate(ctx, usr.Email, "newpass"); err != nil {
+		t.Errorf("Should keep the password from the first reset : %s", err)
+	}
+}
+
+func Test_PasswordResetReplaced(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bus, usr := newResetBus(t, usermem.NewStore(), time.Hour)
+
+	first, _, err := bus.RequestPasswordReset(ctx, usr.Email)
+	if err != nil {
+		t.Fatalf("Should be able to request a password reset : %s", err)
+	}
+
+	second, _, err := bus.RequestPasswordReset(ctx, usr.Email)
+	if err != nil {
+		t.Fatalf("Should be able to request a password reset : %s", err)
+	}
+
+	if _, err := bus.ResetPassword(ctx, first, "newpass"); !errors.Is(err, userbus.ErrInvalidResetToken) {
+		t.Fatalf("Should reject a token that was replaced : %v", err)
+	}
+
+	if _, err := bus.ResetPassword(ctx, second, "newpass"); err != nil {
+		t.Fatalf("Should be able to reset the password with the latest token : %s", err)
+	}
+}
+
+func Test_PasswordResetExpired(t *testing.T) {
+	t.Parallel()
+
+	const ttl = 50 * time.Millisecond
+
+	ctx := context.Background()
+	bus, usr := newResetBus(t, usermem.NewStore(), ttl)
+
+	token, _, err := bus.RequestPasswordReset(ctx, usr.Email)
+	if err != nil {
+		t.Fatalf("Should be able to request a password reset : %s", err)
+	}
+
+	time.Sleep(ttl + 20*time.Millisecond)
+
+	if _, err := bus.ResetPassword(ctx, token, "newpass"); !errors.Is(err, userbus.ErrResetTokenExpired) {
+		t.Fatalf("Should reject an expired token : %v", err)
+	}
+
+	if _, err := bus.ResetPassword(ctx, token, "newpass"); !errors.Is(err, userbus.ErrInvalidResetToken) {
+		t.Fatalf("Should not accept an expired token once it was presented : %v", err)
+	}
+
+	if _, err := bus.Authenticate(ctx, usr.Email, "gophers"); err != nil {
+		t.Errorf("Should keep the old password : %s", err)
+	}
+}
+
+func Test_PasswordResetUnknown(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bus, usr := newResetBus(t, usermem.NewStore(), time.Hour)
+
+	if _, _, err := bus.RequestPasswordReset(ctx, mail.Address{Address: "nobody@example.com"}); !errors.Is(err, userbus.ErrNotFound) {
+		t.Fatalf("Should not issue a token for an unknown email : %v", err)
+	}
+
+	enabled := false
+	if _, err := bus.Update(ctx, usr, userbus.UpdateUser{Enabled: &enabled}); err != nil {
+		t.Fatalf("Should be able to disable the user : %s", err)
+	}
+
+	if _, _, err := bus.RequestPasswordReset(ctx, usr.Email); !errors.Is(err, userbus.ErrNotFound) {
+		t.Fatalf("Should not issue a token for a disabled user : %v", err)
+	}
+}
+
+func newResetBus(t *testing.T, storer userbus.Storer, ttl time.Duration) (*userbus.Business, userbus.User) {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	h, err := hasher.New(hasher.Config{Algorithm: hasher.AlgBcrypt, BcryptCost: 4})
+	if err != nil {
+		t.Fatalf("Should be able to construct the hasher : %s", err)
+	}
+
+	bus := userbus.NewBusiness(log, delegate.New(log), storer, userbus.WithHasher(h), userbus.WithPasswordResetTTL(ttl))
+
+	usr, err := bus.Create(context.Background(), userbus.NewUser{
+		Name:       userbus.MustParseName("Bill Kennedy"),
+		Email:      mail.Address{Address: "bill@example.com"},
+		Roles:      []userbus.Role{userbus.Roles.User},
+		Department: "IT",
+		Password:   "gophers",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create the user : %s", err)
+	}
+
+	return bus, usr
+}
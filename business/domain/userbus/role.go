@@ -1,6 +1,6 @@
 package userbus
 
-import "fmt"
+import "github.com/ardanlabs/service/business/sdk/enum"
 
 type roleSet struct {
 	Admin Role
@@ -16,7 +16,7 @@ var Roles = roleSet{
 // =============================================================================
 
 // Set of known roles.
-var roles = make(map[string]Role)
+var roles = enum.NewSet[Role]("role")
 
 // Role represents a role in the system.
 type Role struct {
@@ -24,9 +24,7 @@ type Role struct {
 }
 
 func newRole(role string) Role {
-	r := Role{role}
-	roles[role] = r
-	return r
+	return roles.Add(Role{role})
 }
 
 // String returns the name of the role.
@@ -39,16 +37,22 @@ func (r Role) Equal(r2 Role) bool {
 	return r.name == r2.name
 }
 
+// MarshalText implements the encoding.TextMarshaler interface.
+func (r Role) MarshalText() ([]byte, error) {
+	return roles.MarshalText(r)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. Only a
+// known role can be unmarshaled.
+func (r *Role) UnmarshalText(data []byte) error {
+	return roles.UnmarshalText(data, r)
+}
+
 // =============================================================================
 
 // ParseRole parses the string value and returns a role if one exists.
 func ParseRole(value string) (Role, error) {
-	role, exists := roles[value]
-	if !exists {
-		return Role{}, fmt.Errorf("invalid role %q", value)
-	}
-
-	return role, nil
+	return roles.Parse(value)
 }
 
 // MustParseRole parses the string value and returns a role if one exists. If
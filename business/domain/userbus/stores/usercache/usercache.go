@@ -42,25 +42,39 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (userbus.Storer, error) {
 }
 
 // Create inserts a new user into the database.
-func (s *Store) Create(ctx context.Context, usr userbus.User) error {
-	if err := s.storer.Create(ctx, usr); err != nil {
-		return err
+func (s *Store) Create(ctx context.Context, usr userbus.User) (userbus.User, error) {
+	usr, err := s.storer.Create(ctx, usr)
+	if err != nil {
+		return userbus.User{}, err
 	}
 
 	s.writeCache(usr)
 
-	return nil
+	return usr, nil
 }
 
 // Update replaces a user document in the database.
-func (s *Store) Update(ctx context.Context, usr userbus.User) error {
-	if err := s.storer.Update(ctx, usr); err != nil {
-		return err
+func (s *Store) Update(ctx context.Context, usr userbus.User, lastUpdated time.Time) (userbus.User, error) {
+	usr, err := s.storer.Update(ctx, usr, lastUpdated)
+	if err != nil {
+		return userbus.User{}, err
 	}
 
 	s.writeCache(usr)
 
-	return nil
+	return usr, nil
+}
+
+// Upsert inserts or updates a user in the database.
+func (s *Store) Upsert(ctx context.Context, usr userbus.User) (userbus.User, bool, error) {
+	usr, inserted, err := s.storer.Upsert(ctx, usr)
+	if err != nil {
+		return userbus.User{}, false, err
+	}
+
+	s.writeCache(usr)
+
+	return usr, inserted, nil
 }
 
 // Delete removes a user from the database.
@@ -79,43 +93,105 @@ func (s *Store) Query(ctx context.Context, filter userbus.QueryFilter, orderBy o
 	return s.storer.Query(ctx, filter, orderBy, page)
 }
 
+// QueryWithCount retrieves a page of users along with the total number of
+// users matching the filter from the database.
+func (s *Store) QueryWithCount(ctx context.Context, filter userbus.QueryFilter, orderBy order.By, page page.Page) ([]userbus.User, int, error) {
+	return s.storer.QueryWithCount(ctx, filter, orderBy, page)
+}
+
+// QueryEach passes every user matching the filter to fn in the specified
+// order. The export bypasses the cache.
+func (s *Store) QueryEach(ctx context.Context, filter userbus.QueryFilter, orderBy order.By, fn func(userbus.User) error) error {
+	return s.storer.QueryEach(ctx, filter, orderBy, fn)
+}
+
 // Count returns the total number of cards in the DB.
 func (s *Store) Count(ctx context.Context, filter userbus.QueryFilter) (int, error) {
 	return s.storer.Count(ctx, filter)
 }
 
-// QueryByID gets the specified user from the database.
+// QueryByID gets the specified user from the database. Concurrent calls for
+// a user that isn't cached share a single query to the database.
 func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
-	cachedUsr, ok := s.readCache(userID.String())
-	if ok {
-		return cachedUsr, nil
+	fetch := func(ctx context.Context) (userbus.User, error) {
+		return s.storer.QueryByID(ctx, userID)
 	}
 
-	usr, err := s.storer.QueryByID(ctx, userID)
-	if err != nil {
-		return userbus.User{}, err
-	}
+	return s.fetch(ctx, userID.String(), fetch)
+}
 
-	s.writeCache(usr)
+// Exists reports whether the specified user exists, only asking the database
+// when the user is not in the cache.
+func (s *Store) Exists(ctx context.Context, userID uuid.UUID) (bool, error) {
+	if _, ok := s.readCache(userID.String()); ok {
+		return true, nil
+	}
 
-	return usr, nil
+	return s.storer.Exists(ctx, userID)
 }
 
 // QueryByEmail gets the specified user from the database by email.
+// Concurrent calls for a user that isn't cached share a single query to the
+// database.
 func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.User, error) {
-	cachedUsr, ok := s.readCache(email.Address)
-	if ok {
-		return cachedUsr, nil
+	fetch := func(ctx context.Context) (userbus.User, error) {
+		return s.storer.QueryByEmail(ctx, email)
 	}
 
-	usr, err := s.storer.QueryByEmail(ctx, email)
-	if err != nil {
-		return userbus.User{}, err
-	}
+	return s.fetch(ctx, email.Address, fetch)
+}
 
-	s.writeCache(usr)
+// QueryLoginAttempts gets the failed login state for the user. It's never
+// cached since it must be shared across instances.
+func (s *Store) QueryLoginAttempts(ctx context.Context, userID uuid.UUID) (userbus.LoginAttempts, error) {
+	return s.storer.QueryLoginAttempts(ctx, userID)
+}
 
-	return usr, nil
+// RecordLoginFailure counts a failed login for the user.
+func (s *Store) RecordLoginFailure(ctx context.Context, userID uuid.UUID, maxAttempts int, lockUntil time.Time, now time.Time) (userbus.LoginAttempts, error) {
+	return s.storer.RecordLoginFailure(ctx, userID, maxAttempts, lockUntil, now)
+}
+
+// ResetLoginAttempts clears the failed login state for the user.
+func (s *Store) ResetLoginAttempts(ctx context.Context, userID uuid.UUID) error {
+	return s.storer.ResetLoginAttempts(ctx, userID)
+}
+
+// CreatePasswordReset inserts a password reset. Password resets are never
+// cached since they must be shared across instances.
+func (s *Store) CreatePasswordReset(ctx context.Context, pr userbus.PasswordReset) error {
+	return s.storer.CreatePasswordReset(ctx, pr)
+}
+
+// ConsumePasswordReset removes the password reset for the token hash and
+// returns it.
+func (s *Store) ConsumePasswordReset(ctx context.Context, tokenHash string) (userbus.PasswordReset, error) {
+	return s.storer.ConsumePasswordReset(ctx, tokenHash)
+}
+
+// DeletePasswordResets removes all the password resets for the user.
+func (s *Store) DeletePasswordResets(ctx context.Context, userID uuid.UUID) error {
+	return s.storer.DeletePasswordResets(ctx, userID)
+}
+
+// fetch returns the user cached under the key or loads it with fn, sharing
+// the load with any concurrent calls for the same key. A loaded user is
+// cached under both of its keys. The load isn't cancelled with the context
+// of the caller that started it, so the others waiting on it don't fail when
+// that caller goes away.
+func (s *Store) fetch(ctx context.Context, key string, fn sturdyc.FetchFn[userbus.User]) (userbus.User, error) {
+	load := func(ctx context.Context) (userbus.User, error) {
+		usr, err := fn(ctx)
+		if err != nil {
+			return userbus.User{}, err
+		}
+
+		s.writeCache(usr)
+
+		return usr, nil
+	}
+
+	return s.cache.GetOrFetch(context.WithoutCancel(ctx), key, load)
 }
 
 // readCache performs a safe search in the cache for the specified key.
@@ -0,0 +1,106 @@
+package usercache_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/mail"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usercache"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// slowStore counts the queries by id that reach it and holds them until
+// release is closed so concurrent calls overlap.
+type slowStore struct {
+	userbus.Storer
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (s *slowStore) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
+	s.calls.Add(1)
+	<-s.release
+
+	return s.Storer.QueryByID(ctx, userID)
+}
+
+func Test_QueryByIDCoalesced(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	mem := usermem.NewStore()
+
+	usr := userbus.User{
+		ID:           uuid.New(),
+		Name:         userbus.MustParseName("Bill Kennedy"),
+		Email:        mail.Address{Address: "bill@example.com"},
+		Roles:        []userbus.Role{userbus.Roles.User},
+		PasswordHash: []byte("hash"),
+		Enabled:      true,
+	}
+
+	if _, err := mem.Create(context.Background(), usr); err != nil {
+		t.Fatalf("Should be able to create the user : %s", err)
+	}
+
+	table := []struct {
+		name   string
+		userID uuid.UUID
+		err    error
+	}{
+		{name: "found", userID: usr.ID},
+		{name: "not-found", userID: uuid.New(), err: userbus.ErrNotFound},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			slow := slowStore{Storer: mem, release: make(chan struct{})}
+			store := usercache.NewStore(log, &slow, time.Hour)
+
+			const readers = 50
+
+			var wg sync.WaitGroup
+			errs := make([]error, readers)
+			usrs := make([]userbus.User, readers)
+
+			for i := range readers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					usrs[i], errs[i] = store.QueryByID(context.Background(), tt.userID)
+				}()
+			}
+
+			// Give the readers time to reach the store before the first query
+			// is allowed to finish.
+			time.Sleep(100 * time.Millisecond)
+			close(slow.release)
+			wg.Wait()
+
+			if calls := slow.calls.Load(); calls != 1 {
+				t.Errorf("Should make a single query for all the readers : got[%d]", calls)
+			}
+
+			for i := range readers {
+				if !errors.Is(errs[i], tt.err) {
+					t.Fatalf("Should get the expected error for reader %d : got[%v] exp[%v]", i, errs[i], tt.err)
+				}
+
+				if tt.err == nil && usrs[i].ID != tt.userID {
+					t.Fatalf("Should get the user for reader %d : got[%s] exp[%s]", i, usrs[i].ID, tt.userID)
+				}
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
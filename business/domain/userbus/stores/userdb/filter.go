@@ -6,9 +6,21 @@ import (
 	"strings"
 
 	"github.com/ardanlabs/service/business/domain/userbus"
+	sdkfilter "github.com/ardanlabs/service/business/sdk/filter"
+	"github.com/ardanlabs/service/business/sdk/sqldb/dbarray"
+	"github.com/ardanlabs/service/business/sdk/sqldb/dbjson"
 )
 
-func applyFilter(filter userbus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+var filterColumns = map[string]string{
+	userbus.FilterByName:        "name",
+	userbus.FilterByEmail:       "email",
+	userbus.FilterByDepartment:  "department",
+	userbus.FilterByEnabled:     "enabled",
+	userbus.FilterByDateCreated: "date_created",
+	userbus.FilterByDateUpdated: "date_updated",
+}
+
+func applyFilter(filter userbus.QueryFilter, data map[string]any, buf *bytes.Buffer) error {
 	var wc []string
 
 	if filter.ID != nil {
@@ -17,8 +29,8 @@ func applyFilter(filter userbus.QueryFilter, data map[string]any, buf *bytes.Buf
 	}
 
 	if filter.Name != nil {
-		data["name"] = fmt.Sprintf("%%%s%%", *filter.Name)
-		wc = append(wc, "name LIKE :name")
+		data["name"] = sdkfilter.Contains(filter.Name.String())
+		wc = append(wc, `name LIKE :name ESCAPE '\'`)
 	}
 
 	if filter.Email != nil {
@@ -36,8 +48,33 @@ func applyFilter(filter userbus.QueryFilter, data map[string]any, buf *bytes.Buf
 		wc = append(wc, "date_created <= :end_date_created")
 	}
 
+	if filter.Metadata != nil {
+		data["metadata"] = dbjson.Object(filter.Metadata)
+		wc = append(wc, "metadata @> :metadata")
+	}
+
+	if filter.Role != nil {
+		data["role"] = filter.Role.String()
+		wc = append(wc, ":role = ANY(roles)")
+	}
+
+	if filter.Tags != nil {
+		data["tags"] = dbarray.String(filter.Tags)
+		wc = append(wc, "tags @> :tags")
+	}
+
+	if len(filter.Expr.Conditions) > 0 {
+		expr, err := sdkfilter.WhereClause(filter.Expr, filterColumns, data)
+		if err != nil {
+			return fmt.Errorf("filter: %w", err)
+		}
+		wc = append(wc, expr)
+	}
+
 	if len(wc) > 0 {
 		buf.WriteString(" WHERE ")
 		buf.WriteString(strings.Join(wc, " AND "))
 	}
+
+	return nil
 }
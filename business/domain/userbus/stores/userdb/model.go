@@ -8,19 +8,45 @@ import (
 
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/sqldb/dbarray"
+	"github.com/ardanlabs/service/business/sdk/sqldb/dbjson"
 	"github.com/google/uuid"
 )
 
 type user struct {
-	ID           uuid.UUID      `db:"user_id"`
-	Name         string         `db:"name"`
-	Email        string         `db:"email"`
-	Roles        dbarray.String `db:"roles"`
-	PasswordHash []byte         `db:"password_hash"`
-	Department   sql.NullString `db:"department"`
-	Enabled      bool           `db:"enabled"`
-	DateCreated  time.Time      `db:"date_created"`
-	DateUpdated  time.Time      `db:"date_updated"`
+	ID                  uuid.UUID      `db:"user_id"`
+	Name                string         `db:"name"`
+	Email               string         `db:"email"`
+	Roles               dbarray.String `db:"roles"`
+	PasswordHash        []byte         `db:"password_hash"`
+	Department          sql.NullString `db:"department"`
+	Enabled             bool           `db:"enabled"`
+	EmailVerified       bool           `db:"email_verified"`
+	Metadata            dbjson.Object  `db:"metadata"`
+	Tags                dbarray.String `db:"tags"`
+	DatePasswordChanged sql.NullTime   `db:"date_password_changed"`
+	DateCreated         time.Time      `db:"date_created"`
+	DateUpdated         time.Time      `db:"date_updated"`
+}
+
+// userWithTotal is a user row that also carries the total number of rows
+// matching the query.
+type userWithTotal struct {
+	user
+	Total int `db:"total"`
+}
+
+// userWithInserted is a user row returned by an upsert that also carries
+// whether the row was inserted rather than updated.
+type userWithInserted struct {
+	user
+	Inserted bool `db:"inserted"`
+}
+
+// userUpdate is a user row along with the update time the row must still
+// have for the update to apply.
+type userUpdate struct {
+	user
+	LastUpdated time.Time `db:"last_updated"`
 }
 
 func toDBUser(bus userbus.User) user {
@@ -34,7 +60,14 @@ func toDBUser(bus userbus.User) user {
 			String: bus.Department,
 			Valid:  bus.Department != "",
 		},
-		Enabled:     bus.Enabled,
+		Enabled:       bus.Enabled,
+		EmailVerified: bus.EmailVerified,
+		Metadata:      dbjson.Object(bus.Metadata),
+		Tags:          toDBTags(bus.Tags),
+		DatePasswordChanged: sql.NullTime{
+			Time:  bus.DatePasswordChanged.UTC(),
+			Valid: !bus.DatePasswordChanged.IsZero(),
+		},
 		DateCreated: bus.DateCreated.UTC(),
 		DateUpdated: bus.DateUpdated.UTC(),
 	}
@@ -56,20 +89,45 @@ func toBusUser(db user) (userbus.User, error) {
 	}
 
 	bus := userbus.User{
-		ID:           db.ID,
-		Name:         name,
-		Email:        addr,
-		Roles:        roles,
-		PasswordHash: db.PasswordHash,
-		Enabled:      db.Enabled,
-		Department:   db.Department.String,
-		DateCreated:  db.DateCreated.In(time.Local),
-		DateUpdated:  db.DateUpdated.In(time.Local),
+		ID:            db.ID,
+		Name:          name,
+		Email:         addr,
+		Roles:         roles,
+		PasswordHash:  db.PasswordHash,
+		Enabled:       db.Enabled,
+		EmailVerified: db.EmailVerified,
+		Department:    db.Department.String,
+		DateCreated:   db.DateCreated.In(time.Local),
+		DateUpdated:   db.DateUpdated.In(time.Local),
+	}
+
+	if db.DatePasswordChanged.Valid {
+		bus.DatePasswordChanged = db.DatePasswordChanged.Time.In(time.Local)
+	}
+
+	// A user without metadata or tags is stored with an empty object and
+	// an empty array.
+	if len(db.Metadata) > 0 {
+		bus.Metadata = userbus.Metadata(db.Metadata)
+	}
+
+	if len(db.Tags) > 0 {
+		bus.Tags = db.Tags
 	}
 
 	return bus, nil
 }
 
+// toDBTags returns the tags so a user without tags is stored with an empty
+// array since the column can't be null.
+func toDBTags(tags []string) dbarray.String {
+	if tags == nil {
+		return dbarray.String{}
+	}
+
+	return tags
+}
+
 func toBusUsers(dbs []user) ([]userbus.User, error) {
 	bus := make([]userbus.User, len(dbs))
 
@@ -83,3 +141,51 @@ func toBusUsers(dbs []user) ([]userbus.User, error) {
 
 	return bus, nil
 }
+
+// =============================================================================
+
+type loginAttempts struct {
+	UserID      uuid.UUID    `db:"user_id"`
+	Failed      int          `db:"failed_attempts"`
+	LockedUntil sql.NullTime `db:"locked_until"`
+}
+
+func toBusLoginAttempts(db loginAttempts) userbus.LoginAttempts {
+	la := userbus.LoginAttempts{
+		UserID: db.UserID,
+		Failed: db.Failed,
+	}
+
+	if db.LockedUntil.Valid {
+		la.LockedUntil = db.LockedUntil.Time.In(time.Local)
+	}
+
+	return la
+}
+
+// =============================================================================
+
+type passwordReset struct {
+	TokenHash   string    `db:"token_hash"`
+	UserID      uuid.UUID `db:"user_id"`
+	ExpiresAt   time.Time `db:"expires_at"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBPasswordReset(bus userbus.PasswordReset) passwordReset {
+	return passwordReset{
+		TokenHash:   bus.TokenHash,
+		UserID:      bus.UserID,
+		ExpiresAt:   bus.ExpiresAt.UTC(),
+		DateCreated: bus.DateCreated.UTC(),
+	}
+}
+
+func toBusPasswordReset(db passwordReset) userbus.PasswordReset {
+	return userbus.PasswordReset{
+		TokenHash:   db.TokenHash,
+		UserID:      db.UserID,
+		ExpiresAt:   db.ExpiresAt.In(time.Local),
+		DateCreated: db.DateCreated.In(time.Local),
+	}
+}
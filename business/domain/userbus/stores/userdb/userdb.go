@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/mail"
+	"time"
 
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/order"
@@ -17,18 +18,44 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// The users table has a unique constraint on the email, so a conflict
+// reports that field.
+func init() {
+	sqldb.RegisterUnique("users", "users_email_key", "email")
+}
+
 // Store manages the set of APIs for user database access.
 type Store struct {
-	log *logger.Logger
-	db  sqlx.ExtContext
+	log     *logger.Logger
+	db      sqlx.ExtContext
+	replica sqlx.ExtContext
+	maxLag  time.Duration
+}
+
+// WithReplica sends the queries for users to a read replica, unless the
+// caller has a consistency token for a write made within the maximum
+// replication lag. A nil replica is ignored.
+func WithReplica(replica *sqlx.DB, maxLag time.Duration) func(s *Store) {
+	return func(s *Store) {
+		if replica != nil {
+			s.replica = replica
+			s.maxLag = maxLag
+		}
+	}
 }
 
 // NewStore constructs the api for data access.
-func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
-	return &Store{
+func NewStore(log *logger.Logger, db *sqlx.DB, options ...func(s *Store)) *Store {
+	s := Store{
 		log: log,
 		db:  db,
 	}
+
+	for _, option := range options {
+		option(&s)
+	}
+
+	return &s
 }
 
 // NewWithTx constructs a new Store value replacing the sqlx DB
@@ -47,26 +74,32 @@ func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (userbus.Storer, error) {
 	return &store, nil
 }
 
-// Create inserts a new user into the database.
-func (s *Store) Create(ctx context.Context, usr userbus.User) error {
+// Create inserts a new user into the database and returns the user as it
+// was stored.
+func (s *Store) Create(ctx context.Context, usr userbus.User) (userbus.User, error) {
 	const q = `
 	INSERT INTO users
-		(user_id, name, email, password_hash, roles, department, enabled, date_created, date_updated)
+		(user_id, name, email, password_hash, roles, department, enabled, email_verified, metadata, tags, date_password_changed, date_created, date_updated)
 	VALUES
-		(:user_id, :name, :email, :password_hash, :roles, :department, :enabled, :date_created, :date_updated)`
+		(:user_id, :name, :email, :password_hash, :roles, :department, :enabled, :email_verified, :metadata, :tags, :date_password_changed, :date_created, :date_updated)
+	RETURNING
+		user_id, name, email, password_hash, roles, department, enabled, email_verified, metadata, tags, date_password_changed, date_created, date_updated`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
+	var dbUsr user
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBUser(usr), &dbUsr); err != nil {
 		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
-			return fmt.Errorf("namedexeccontext: %w", userbus.ErrUniqueEmail)
+			return userbus.User{}, fmt.Errorf("db: %w: %w", userbus.ErrUniqueEmail, err)
 		}
-		return fmt.Errorf("namedexeccontext: %w", err)
+		return userbus.User{}, fmt.Errorf("db: %w", err)
 	}
 
-	return nil
+	return toBusUser(dbUsr)
 }
 
-// Update replaces a user document in the database.
-func (s *Store) Update(ctx context.Context, usr userbus.User) error {
+// Update replaces a user document in the database when it hasn't been
+// updated since lastUpdated, so a writer can't overwrite changes it hasn't
+// seen. It returns the user as it was stored.
+func (s *Store) Update(ctx context.Context, usr userbus.User, lastUpdated time.Time) (userbus.User, error) {
 	const q = `
 	UPDATE
 		users
@@ -77,18 +110,105 @@ func (s *Store) Update(ctx context.Context, usr userbus.User) error {
 		"password_hash" = :password_hash,
 		"department" = :department,
 		"enabled" = :enabled,
+		"email_verified" = :email_verified,
+		"metadata" = :metadata,
+		"tags" = :tags,
+		"date_password_changed" = :date_password_changed,
 		"date_updated" = :date_updated
+	WHERE
+		user_id = :user_id AND
+		date_updated = :last_updated
+	RETURNING
+		user_id, name, email, password_hash, roles, department, enabled, email_verified, metadata, tags, date_password_changed, date_created, date_updated`
+
+	// Postgres stores timestamps with microsecond precision, so the time
+	// must be rounded the same way to match the stored value.
+	data := userUpdate{
+		user:        toDBUser(usr),
+		LastUpdated: lastUpdated.UTC().Round(time.Microsecond),
+	}
+
+	var dbUsr user
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUsr); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return userbus.User{}, s.updateMissed(ctx, usr.ID)
+		}
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return userbus.User{}, fmt.Errorf("db: %w: %w", userbus.ErrUniqueEmail, err)
+		}
+		return userbus.User{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusUser(dbUsr)
+}
+
+// updateMissed reports why an update matched no row. The user either doesn't
+// exist or was updated since the last update time the caller had.
+func (s *Store) updateMissed(ctx context.Context, userID uuid.UUID) error {
+	data := struct {
+		ID string `db:"user_id"`
+	}{
+		ID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		users
 	WHERE
 		user_id = :user_id`
 
-	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &count); err != nil {
+		return fmt.Errorf("db: %w", err)
+	}
+
+	if count.Count == 0 {
+		return fmt.Errorf("db: %w", userbus.ErrNotFound)
+	}
+
+	return fmt.Errorf("db: %w", userbus.ErrModified)
+}
+
+// Upsert inserts a new user into the database or, when the email is already
+// taken, updates the columns listed in the SET clause of the existing user.
+// It returns the stored user and reports whether it was inserted.
+func (s *Store) Upsert(ctx context.Context, usr userbus.User) (userbus.User, bool, error) {
+	const q = `
+	INSERT INTO users
+		(user_id, name, email, password_hash, roles, department, enabled, email_verified, metadata, tags, date_password_changed, date_created, date_updated)
+	VALUES
+		(:user_id, :name, :email, :password_hash, :roles, :department, :enabled, :email_verified, :metadata, :tags, :date_password_changed, :date_created, :date_updated)
+	ON CONFLICT (email) DO UPDATE SET
+		"name" = EXCLUDED.name,
+		"roles" = EXCLUDED.roles,
+		"department" = EXCLUDED.department,
+		"metadata" = EXCLUDED.metadata,
+		"tags" = EXCLUDED.tags,
+		"date_updated" = EXCLUDED.date_updated
+	RETURNING
+		user_id, name, email, password_hash, roles, department, enabled, email_verified, metadata, tags, date_password_changed, date_created, date_updated,
+		(xmax = 0) AS inserted`
+
+	// A row that was just inserted has no deleting transaction, so xmax is
+	// only set when the conflicting row was updated.
+	var dbUsr userWithInserted
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBUser(usr), &dbUsr); err != nil {
 		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
-			return userbus.ErrUniqueEmail
+			return userbus.User{}, false, fmt.Errorf("db: %w: %w", userbus.ErrUniqueEmail, err)
 		}
-		return fmt.Errorf("namedexeccontext: %w", err)
+		return userbus.User{}, false, fmt.Errorf("db: %w", err)
 	}
 
-	return nil
+	bus, err := toBusUser(dbUsr.user)
+	if err != nil {
+		return userbus.User{}, false, err
+	}
+
+	return bus, dbUsr.Inserted, nil
 }
 
 // Delete removes a user from the database.
@@ -115,12 +235,14 @@ func (s *Store) Query(ctx context.Context, filter userbus.QueryFilter, orderBy o
 
 	const q = `
 	SELECT
-		user_id, name, email, password_hash, roles, department, enabled, date_created, date_updated
+		user_id, name, email, password_hash, roles, department, enabled, email_verified, metadata, tags, date_password_changed, date_created, date_updated
 	FROM
 		users`
 
 	buf := bytes.NewBufferString(q)
-	applyFilter(filter, data, buf)
+	if err := applyFilter(filter, data, buf); err != nil {
+		return nil, err
+	}
 
 	orderByClause, err := orderByClause(orderBy)
 	if err != nil {
@@ -131,13 +253,113 @@ func (s *Store) Query(ctx context.Context, filter userbus.QueryFilter, orderBy o
 	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
 
 	var dbUsrs []user
-	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbUsrs); err != nil {
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.reader(ctx), buf.String(), data, &dbUsrs); err != nil {
 		return nil, fmt.Errorf("namedqueryslice: %w", err)
 	}
 
 	return toBusUsers(dbUsrs)
 }
 
+// QueryWithCount retrieves a page of users along with the total number of
+// users matching the filter. The total is computed with a window function in
+// the same query, so it's only missing when the page is past the last row
+// and then a separate count is run.
+func (s *Store) QueryWithCount(ctx context.Context, filter userbus.QueryFilter, orderBy order.By, page page.Page) ([]userbus.User, int, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+		user_id, name, email, password_hash, roles, department, enabled, email_verified, metadata, tags, date_password_changed, date_created, date_updated,
+		count(1) OVER() AS total
+	FROM
+		users`
+
+	buf := bytes.NewBufferString(q)
+	if err := applyFilter(filter, data, buf); err != nil {
+		return nil, 0, err
+	}
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbUsrs []userWithTotal
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.reader(ctx), buf.String(), data, &dbUsrs); err != nil {
+		return nil, 0, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	if len(dbUsrs) == 0 {
+		if page.Number() == 1 {
+			return nil, 0, nil
+		}
+
+		total, err := s.Count(ctx, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return nil, total, nil
+	}
+
+	usrs := make([]user, len(dbUsrs))
+	for i, dbUsr := range dbUsrs {
+		usrs[i] = dbUsr.user
+	}
+
+	busUsrs, err := toBusUsers(usrs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return busUsrs, dbUsrs[0].Total, nil
+}
+
+// QueryEach retrieves every user matching the filter in the specified order
+// and passes each one to fn as it's read from the database.
+func (s *Store) QueryEach(ctx context.Context, filter userbus.QueryFilter, orderBy order.By, fn func(userbus.User) error) error {
+	data := map[string]any{}
+
+	const q = `
+	SELECT
+		user_id, name, email, password_hash, roles, department, enabled, email_verified, metadata, tags, date_password_changed, date_created, date_updated
+	FROM
+		users`
+
+	buf := bytes.NewBufferString(q)
+	if err := applyFilter(filter, data, buf); err != nil {
+		return err
+	}
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return err
+	}
+
+	buf.WriteString(orderByClause)
+
+	f := func(dbUsr user) error {
+		usr, err := toBusUser(dbUsr)
+		if err != nil {
+			return err
+		}
+
+		return fn(usr)
+	}
+
+	if err := sqldb.NamedQueryEach(ctx, s.log, s.reader(ctx), buf.String(), data, f); err != nil {
+		return fmt.Errorf("namedqueryeach: %w", err)
+	}
+
+	return nil
+}
+
 // Count returns the total number of users in the DB.
 func (s *Store) Count(ctx context.Context, filter userbus.QueryFilter) (int, error) {
 	data := map[string]any{}
@@ -149,12 +371,14 @@ func (s *Store) Count(ctx context.Context, filter userbus.QueryFilter) (int, err
 		users`
 
 	buf := bytes.NewBufferString(q)
-	applyFilter(filter, data, buf)
+	if err := applyFilter(filter, data, buf); err != nil {
+		return 0, err
+	}
 
 	var count struct {
 		Count int `db:"count"`
 	}
-	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.reader(ctx), buf.String(), data, &count); err != nil {
 		return 0, fmt.Errorf("db: %w", err)
 	}
 
@@ -171,14 +395,14 @@ func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User,
 
 	const q = `
 	SELECT
-        user_id, name, email, password_hash, roles, department, enabled, date_created, date_updated
+        user_id, name, email, password_hash, roles, department, enabled, email_verified, metadata, tags, date_password_changed, date_created, date_updated
 	FROM
 		users
 	WHERE 
 		user_id = :user_id`
 
 	var dbUsr user
-	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUsr); err != nil {
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.reader(ctx), q, data, &dbUsr); err != nil {
 		if errors.Is(err, sqldb.ErrDBNotFound) {
 			return userbus.User{}, fmt.Errorf("db: %w", userbus.ErrNotFound)
 		}
@@ -188,6 +412,29 @@ func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User,
 	return toBusUser(dbUsr)
 }
 
+// Exists reports whether the specified user is in the database.
+func (s *Store) Exists(ctx context.Context, userID uuid.UUID) (bool, error) {
+	data := struct {
+		ID string `db:"user_id"`
+	}{
+		ID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		EXISTS (SELECT 1 FROM users WHERE user_id = :user_id) AS exists`
+
+	var dest struct {
+		Exists bool `db:"exists"`
+	}
+
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.reader(ctx), q, data, &dest); err != nil {
+		return false, fmt.Errorf("db: %w", err)
+	}
+
+	return dest.Exists, nil
+}
+
 // QueryByEmail gets the specified user from the database by email.
 func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.User, error) {
 	data := struct {
@@ -198,14 +445,14 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.U
 
 	const q = `
 	SELECT
-        user_id, name, email, password_hash, roles, department, enabled, date_created, date_updated
+        user_id, name, email, password_hash, roles, department, enabled, email_verified, metadata, tags, date_password_changed, date_created, date_updated
 	FROM
 		users
 	WHERE
 		email = :email`
 
 	var dbUsr user
-	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbUsr); err != nil {
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.reader(ctx), q, data, &dbUsr); err != nil {
 		if errors.Is(err, sqldb.ErrDBNotFound) {
 			return userbus.User{}, fmt.Errorf("db: %w", userbus.ErrNotFound)
 		}
@@ -214,3 +461,163 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.U
 
 	return toBusUser(dbUsr)
 }
+
+// QueryLoginAttempts gets the failed login state for the user from the
+// database.
+func (s *Store) QueryLoginAttempts(ctx context.Context, userID uuid.UUID) (userbus.LoginAttempts, error) {
+	data := struct {
+		ID string `db:"user_id"`
+	}{
+		ID: userID.String(),
+	}
+
+	const q = `
+	SELECT
+		user_id, failed_attempts, locked_until
+	FROM
+		user_login_attempts
+	WHERE
+		user_id = :user_id`
+
+	var dbLA loginAttempts
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbLA); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return userbus.LoginAttempts{UserID: userID}, nil
+		}
+		return userbus.LoginAttempts{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusLoginAttempts(dbLA), nil
+}
+
+// RecordLoginFailure atomically counts a failed login for the user and locks
+// the account once the maximum number of attempts is reached. The count
+// starts over once a previous lock has expired.
+func (s *Store) RecordLoginFailure(ctx context.Context, userID uuid.UUID, maxAttempts int, lockUntil time.Time, now time.Time) (userbus.LoginAttempts, error) {
+	data := struct {
+		ID          string    `db:"user_id"`
+		MaxAttempts int       `db:"max_attempts"`
+		LockUntil   time.Time `db:"lock_until"`
+		Now         time.Time `db:"now"`
+	}{
+		ID:          userID.String(),
+		MaxAttempts: maxAttempts,
+		LockUntil:   lockUntil.UTC(),
+		Now:         now.UTC(),
+	}
+
+	const q = `
+	INSERT INTO user_login_attempts AS a
+		(user_id, failed_attempts, locked_until, date_updated)
+	VALUES
+		(:user_id, 1, CASE WHEN 1 >= :max_attempts THEN CAST(:lock_until AS TIMESTAMP) END, :now)
+	ON CONFLICT (user_id) DO UPDATE SET
+		failed_attempts = CASE WHEN a.locked_until <= :now THEN 1 ELSE a.failed_attempts + 1 END,
+		locked_until = CASE
+			WHEN (CASE WHEN a.locked_until <= :now THEN 1 ELSE a.failed_attempts + 1 END) >= :max_attempts
+			THEN CAST(:lock_until AS TIMESTAMP)
+		END,
+		date_updated = :now
+	RETURNING
+		user_id, failed_attempts, locked_until`
+
+	var dbLA loginAttempts
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbLA); err != nil {
+		return userbus.LoginAttempts{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusLoginAttempts(dbLA), nil
+}
+
+// ResetLoginAttempts clears the failed login state for the user in the
+// database.
+func (s *Store) ResetLoginAttempts(ctx context.Context, userID uuid.UUID) error {
+	data := struct {
+		ID string `db:"user_id"`
+	}{
+		ID: userID.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		user_login_attempts
+	WHERE
+		user_id = :user_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// CreatePasswordReset inserts a password reset into the database.
+func (s *Store) CreatePasswordReset(ctx context.Context, pr userbus.PasswordReset) error {
+	const q = `
+	INSERT INTO user_password_resets
+		(token_hash, user_id, expires_at, date_created)
+	VALUES
+		(:token_hash, :user_id, :expires_at, :date_created)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBPasswordReset(pr)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumePasswordReset removes the password reset for the token hash from
+// the database and returns it. Since the row is deleted as it's read, only
+// one caller can ever consume a token.
+func (s *Store) ConsumePasswordReset(ctx context.Context, tokenHash string) (userbus.PasswordReset, error) {
+	data := struct {
+		TokenHash string `db:"token_hash"`
+	}{
+		TokenHash: tokenHash,
+	}
+
+	const q = `
+	DELETE FROM
+		user_password_resets
+	WHERE
+		token_hash = :token_hash
+	RETURNING
+		token_hash, user_id, expires_at, date_created`
+
+	var dbPR passwordReset
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbPR); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return userbus.PasswordReset{}, fmt.Errorf("db: %w", userbus.ErrNotFound)
+		}
+		return userbus.PasswordReset{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusPasswordReset(dbPR), nil
+}
+
+// DeletePasswordResets removes all the password resets for the user from the
+// database.
+func (s *Store) DeletePasswordResets(ctx context.Context, userID uuid.UUID) error {
+	data := struct {
+		ID string `db:"user_id"`
+	}{
+		ID: userID.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		user_password_resets
+	WHERE
+		user_id = :user_id`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// reader returns the database the queries for users are sent to.
+func (s *Store) reader(ctx context.Context) sqlx.ExtContext {
+	return sqldb.Reader(ctx, s.db, s.replica, s.maxLag)
+}
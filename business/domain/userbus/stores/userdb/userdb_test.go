@@ -0,0 +1,44 @@
+package userdb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/page"
+)
+
+func Benchmark_QueryWithCount(b *testing.B) {
+	db := dbtest.NewDatabase(b, "Benchmark_QueryWithCount")
+
+	ctx := context.Background()
+
+	if _, err := userbus.TestSeedUsers(ctx, 100, userbus.Roles.User, db.BusDomain.User); err != nil {
+		b.Fatalf("Seeding error: %s", err)
+	}
+
+	store := userdb.NewStore(db.Log, db.DB)
+	pg := page.MustParse("2", "10")
+
+	b.Run("window", func(b *testing.B) {
+		for range b.N {
+			if _, _, err := store.QueryWithCount(ctx, userbus.QueryFilter{}, userbus.DefaultOrderBy, pg); err != nil {
+				b.Fatalf("Should be able to query users with the count : %s", err)
+			}
+		}
+	})
+
+	b.Run("separate", func(b *testing.B) {
+		for range b.N {
+			if _, err := store.Query(ctx, userbus.QueryFilter{}, userbus.DefaultOrderBy, pg); err != nil {
+				b.Fatalf("Should be able to query users : %s", err)
+			}
+
+			if _, err := store.Count(ctx, userbus.QueryFilter{}); err != nil {
+				b.Fatalf("Should be able to count users : %s", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,515 @@
+// Package usermem contains user related CRUD functionality backed by memory.
+// It is intended for unit testing business logic without a database.
+package usermem
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	sdkfilter "github.com/ardanlabs/service/business/sdk/filter"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/google/uuid"
+)
+
+// errConflict matches the error the database store reports for a unique
+// email violation.
+var errConflict = &sqldb.ConflictError{Table: "users", Constraint: "users_email_key", Fields: []string{"email"}}
+
+// Store manages the set of APIs for user memory access.
+type Store struct {
+	mu       *sync.RWMutex
+	users    map[uuid.UUID]userbus.User
+	attempts map[uuid.UUID]userbus.LoginAttempts
+	resets   map[string]userbus.PasswordReset
+}
+
+// NewStore constructs the api for data access.
+func NewStore() *Store {
+	return &Store{
+		mu:       &sync.RWMutex{},
+		users:    make(map[uuid.UUID]userbus.User),
+		attempts: make(map[uuid.UUID]userbus.LoginAttempts),
+		resets:   make(map[string]userbus.PasswordReset),
+	}
+}
+
+// NewWithTx returns the same store since memory access has no support for
+// transactions. Changes are applied immediately and are not rolled back.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (userbus.Storer, error) {
+	return s, nil
+}
+
+// Create inserts a new user into memory.
+func (s *Store) Create(ctx context.Context, usr userbus.User) (userbus.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The database store reports any unique violation as a unique email
+	// error, so the same is done here.
+	if _, exists := s.users[usr.ID]; exists || s.emailTaken(usr) {
+		return userbus.User{}, fmt.Errorf("create: %w: %w", userbus.ErrUniqueEmail, errConflict)
+	}
+
+	s.users[usr.ID] = clone(usr)
+
+	return clone(usr), nil
+}
+
+// Update replaces a user in memory when it hasn't been updated since
+// lastUpdated. Like the database store, updating a user that does not exist
+// is reported as not found.
+func (s *Store) Update(ctx context.Context, usr userbus.User, lastUpdated time.Time) (userbus.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, exists := s.users[usr.ID]
+	if !exists {
+		return userbus.User{}, fmt.Errorf("update: %w", userbus.ErrNotFound)
+	}
+
+	if !stored.DateUpdated.Round(time.Microsecond).Equal(lastUpdated.Round(time.Microsecond)) {
+		return userbus.User{}, fmt.Errorf("update: %w", userbus.ErrModified)
+	}
+
+	if s.emailTaken(usr) {
+		return userbus.User{}, fmt.Errorf("update: %w: %w", userbus.ErrUniqueEmail, errConflict)
+	}
+
+	s.users[usr.ID] = clone(usr)
+
+	return clone(usr), nil
+}
+
+// Upsert inserts a user into memory or, when the email is already taken,
+// updates the same fields of the existing user as the database store does.
+func (s *Store) Upsert(ctx context.Context, usr userbus.User) (userbus.User, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, cur := range s.users {
+		if cur.Email.Address != usr.Email.Address {
+			continue
+		}
+
+		cur.Name = usr.Name
+		cur.Roles = usr.Roles
+		cur.Department = usr.Department
+		cur.Metadata = usr.Metadata
+		cur.Tags = usr.Tags
+		cur.DateUpdated = usr.DateUpdated
+
+		s.users[id] = clone(cur)
+
+		return clone(cur), false, nil
+	}
+
+	if _, exists := s.users[usr.ID]; exists {
+		return userbus.User{}, false, fmt.Errorf("upsert: %w: %w", userbus.ErrUniqueEmail, errConflict)
+	}
+
+	s.users[usr.ID] = clone(usr)
+
+	return clone(usr), true, nil
+}
+
+// Delete removes a user from memory.
+func (s *Store) Delete(ctx context.Context, usr userbus.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.users, usr.ID)
+	delete(s.attempts, usr.ID)
+	s.deletePasswordResets(usr.ID)
+
+	return nil
+}
+
+// Query retrieves a list of existing users from memory.
+func (s *Store) Query(ctx context.Context, filter userbus.QueryFilter, orderBy order.By, page page.Page) ([]userbus.User, error) {
+	less, err := lessFunc(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	usrs, err := s.filter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(usrs, func(i, j int) bool {
+		return less(usrs[i], usrs[j])
+	})
+
+	offset := (page.Number() - 1) * page.RowsPerPage()
+	if offset >= len(usrs) {
+		return nil, nil
+	}
+
+	end := min(offset+page.RowsPerPage(), len(usrs))
+
+	return usrs[offset:end], nil
+}
+
+// QueryWithCount retrieves a page of users along with the total number of
+// users matching the filter.
+func (s *Store) QueryWithCount(ctx context.Context, filter userbus.QueryFilter, orderBy order.By, page page.Page) ([]userbus.User, int, error) {
+	usrs, err := s.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return usrs, total, nil
+}
+
+// QueryEach passes every user matching the filter to fn in the specified
+// order. The users are copied first so fn can call back into the store.
+func (s *Store) QueryEach(ctx context.Context, filter userbus.QueryFilter, orderBy order.By, fn func(userbus.User) error) error {
+	less, err := lessFunc(orderBy)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	usrs, err := s.filter(filter)
+	s.mu.RUnlock()
+
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(usrs, func(i, j int) bool {
+		return less(usrs[i], usrs[j])
+	})
+
+	for _, usr := range usrs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := fn(usr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Count returns the total number of users in memory.
+func (s *Store) Count(ctx context.Context, filter userbus.QueryFilter) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	usrs, err := s.filter(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(usrs), nil
+}
+
+// QueryByID gets the specified user from memory.
+func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	usr, exists := s.users[userID]
+	if !exists {
+		return userbus.User{}, fmt.Errorf("db: %w", userbus.ErrNotFound)
+	}
+
+	return clone(usr), nil
+}
+
+// Exists reports whether the specified user is in memory.
+func (s *Store) Exists(ctx context.Context, userID uuid.UUID) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, exists := s.users[userID]
+
+	return exists, nil
+}
+
+// QueryByEmail gets the specified user from memory by email.
+func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, usr := range s.users {
+		if usr.Email.Address == email.Address {
+			return clone(usr), nil
+		}
+	}
+
+	return userbus.User{}, fmt.Errorf("db: %w", userbus.ErrNotFound)
+}
+
+// QueryLoginAttempts gets the failed login state for the user from memory.
+func (s *Store) QueryLoginAttempts(ctx context.Context, userID uuid.UUID) (userbus.LoginAttempts, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	la, exists := s.attempts[userID]
+	if !exists {
+		return userbus.LoginAttempts{UserID: userID}, nil
+	}
+
+	return la, nil
+}
+
+// RecordLoginFailure counts a failed login for the user in memory. The
+// count starts over once a previous lock has expired.
+func (s *Store) RecordLoginFailure(ctx context.Context, userID uuid.UUID, maxAttempts int, lockUntil time.Time, now time.Time) (userbus.LoginAttempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	la := s.attempts[userID]
+	la.UserID = userID
+
+	if !la.LockedUntil.IsZero() && !la.Locked(now) {
+		la.Failed = 0
+	}
+	la.Failed++
+
+	la.LockedUntil = time.Time{}
+	if la.Failed >= maxAttempts {
+		la.LockedUntil = lockUntil
+	}
+
+	s.attempts[userID] = la
+
+	return la, nil
+}
+
+// ResetLoginAttempts clears the failed login state for the user in memory.
+func (s *Store) ResetLoginAttempts(ctx context.Context, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, userID)
+
+	return nil
+}
+
+// CreatePasswordReset inserts a password reset into memory.
+func (s *Store) CreatePasswordReset(ctx context.Context, pr userbus.PasswordReset) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.resets[pr.TokenHash] = pr
+
+	return nil
+}
+
+// ConsumePasswordReset removes the password reset for the token hash from
+// memory and returns it.
+func (s *Store) ConsumePasswordReset(ctx context.Context, tokenHash string) (userbus.PasswordReset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pr, exists := s.resets[tokenHash]
+	if !exists {
+		return userbus.PasswordReset{}, fmt.Errorf("db: %w", userbus.ErrNotFound)
+	}
+
+	delete(s.resets, tokenHash)
+
+	return pr, nil
+}
+
+// DeletePasswordResets removes all the password resets for the user from
+// memory.
+func (s *Store) DeletePasswordResets(ctx context.Context, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deletePasswordResets(userID)
+
+	return nil
+}
+
+// =============================================================================
+
+// deletePasswordResets removes the password resets for the user. The caller
+// must hold the lock.
+func (s *Store) deletePasswordResets(userID uuid.UUID) {
+	for hash, pr := range s.resets {
+		if pr.UserID == userID {
+			delete(s.resets, hash)
+		}
+	}
+}
+
+// emailTaken checks if a different user is already using the email address.
+func (s *Store) emailTaken(usr userbus.User) bool {
+	for _, u := range s.users {
+		if u.ID != usr.ID && u.Email.Address == usr.Email.Address {
+			return true
+		}
+	}
+
+	return false
+}
+
+// filter returns a copy of the users that match the specified filter.
+func (s *Store) filter(filter userbus.QueryFilter) ([]userbus.User, error) {
+	var usrs []userbus.User
+
+	for _, usr := range s.users {
+		if filter.ID != nil && usr.ID != *filter.ID {
+			continue
+		}
+
+		if filter.Name != nil && !strings.Contains(usr.Name.String(), filter.Name.String()) {
+			continue
+		}
+
+		if filter.Email != nil && usr.Email.Address != filter.Email.Address {
+			continue
+		}
+
+		if filter.StartCreatedDate != nil && usr.DateCreated.Before(*filter.StartCreatedDate) {
+			continue
+		}
+
+		if filter.EndCreatedDate != nil && usr.DateCreated.After(*filter.EndCreatedDate) {
+			continue
+		}
+
+		if filter.Metadata != nil && !usr.Metadata.Contains(filter.Metadata) {
+			continue
+		}
+
+		if filter.Role != nil && !slices.Contains(usr.Roles, *filter.Role) {
+			continue
+		}
+
+		if filter.Tags != nil && !containsAll(usr.Tags, filter.Tags) {
+			continue
+		}
+
+		match, err := sdkfilter.Match(filter.Expr, filterValues(usr))
+		if err != nil {
+			return nil, fmt.Errorf("filter: %w", err)
+		}
+
+		if !match {
+			continue
+		}
+
+		usrs = append(usrs, clone(usr))
+	}
+
+	return usrs, nil
+}
+
+// filterValues returns the values of the user that filter expressions can
+// use.
+func filterValues(usr userbus.User) map[string]any {
+	return map[string]any{
+		userbus.FilterByName:        usr.Name.String(),
+		userbus.FilterByEmail:       usr.Email.Address,
+		userbus.FilterByDepartment:  usr.Department,
+		userbus.FilterByEnabled:     usr.Enabled,
+		userbus.FilterByDateCreated: usr.DateCreated.UTC(),
+		userbus.FilterByDateUpdated: usr.DateUpdated.UTC(),
+	}
+}
+
+// lessFunc returns the function used to sort users for the specified order.
+func lessFunc(orderBy order.By) (func(a, b userbus.User) bool, error) {
+	var cmp func(a, b userbus.User) int
+
+	switch orderBy.Field {
+	case userbus.OrderByID:
+		cmp = func(a, b userbus.User) int {
+			return strings.Compare(a.ID.String(), b.ID.String())
+		}
+
+	case userbus.OrderByName:
+		cmp = func(a, b userbus.User) int {
+			return strings.Compare(a.Name.String(), b.Name.String())
+		}
+
+	case userbus.OrderByEmail:
+		cmp = func(a, b userbus.User) int {
+			return strings.Compare(a.Email.Address, b.Email.Address)
+		}
+
+	case userbus.OrderByRoles:
+		cmp = func(a, b userbus.User) int {
+			return slices.Compare(userbus.ParseRolesToString(a.Roles), userbus.ParseRolesToString(b.Roles))
+		}
+
+	case userbus.OrderByEnabled:
+		cmp = func(a, b userbus.User) int {
+			switch {
+			case a.Enabled == b.Enabled:
+				return 0
+			case !a.Enabled:
+				return -1
+			default:
+				return 1
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	if orderBy.Direction == order.DESC {
+		return func(a, b userbus.User) bool { return cmp(a, b) > 0 }, nil
+	}
+
+	return func(a, b userbus.User) bool { return cmp(a, b) < 0 }, nil
+}
+
+// clone returns a copy of the user that does not share memory with the
+// original so callers can't modify what is stored.
+func clone(usr userbus.User) userbus.User {
+	usr.Roles = slices.Clone(usr.Roles)
+	usr.PasswordHash = slices.Clone(usr.PasswordHash)
+	usr.Metadata = usr.Metadata.Clone()
+	usr.Tags = slices.Clone(usr.Tags)
+
+	// The database stores a user without metadata or tags as an empty object
+	// and an empty array.
+	if len(usr.Metadata) == 0 {
+		usr.Metadata = nil
+	}
+
+	if len(usr.Tags) == 0 {
+		usr.Tags = nil
+	}
+
+	return usr
+}
+
+// containsAll reports whether the tags contain every one of the other tags,
+// like the @> operator on an array column.
+func containsAll(tags []string, other []string) bool {
+	for _, tag := range other {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,496 @@
+package usermem_test
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	sdkfilter "github.com/ardanlabs/service/business/sdk/filter"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+func Test_Memory(t *testing.T) {
+	t.Parallel()
+
+	t.Run("store", testStore(usermem.NewStore()))
+}
+
+func Test_CompareToDB(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.NewDatabase(t, "Test_CompareToDB")
+
+	t.Run("mem", testStore(usermem.NewStore()))
+	t.Run("db", testStore(userdb.NewStore(db.Log, db.DB)))
+}
+
+// testStore runs the same set of operations against any userbus store so
+// the behavior of the memory store can be compared with the database store.
+func testStore(store userbus.Storer) func(t *testing.T) {
+	f := func(t *testing.T) {
+		ctx := context.Background()
+
+		now := time.Now().Truncate(time.Second)
+
+		usrs := []userbus.User{
+			newUser("Bill Kennedy", "bill@example.com", now.Add(-3*time.Hour)),
+			newUser("Ale Kennedy", "ale@example.com", now.Add(-2*time.Hour)),
+			newUser("Jack Smith", "jack@example.com", now.Add(-1*time.Hour)),
+		}
+
+		for _, usr := range usrs {
+			if _, err := store.Create(ctx, usr); err != nil {
+				t.Fatalf("Should be able to create a user : %s", err)
+			}
+		}
+
+		// ---------------------------------------------------------------------
+
+		dup := newUser("Other Name", "bill@example.com", now)
+		if _, err := store.Create(ctx, dup); !errors.Is(err, userbus.ErrUniqueEmail) {
+			t.Errorf("Should get a unique email error on create : %v", err)
+		}
+
+		upd := usrs[1]
+		upd.Email = usrs[0].Email
+		if _, err := store.Update(ctx, upd, upd.DateUpdated); !errors.Is(err, userbus.ErrUniqueEmail) {
+			t.Errorf("Should get a unique email error on update : %v", err)
+		}
+
+		if _, err := store.QueryByID(ctx, uuid.New()); !errors.Is(err, userbus.ErrNotFound) {
+			t.Errorf("Should get a not found error by id : %v", err)
+		}
+
+		if _, err := store.QueryByEmail(ctx, mail.Address{Address: "none@example.com"}); !errors.Is(err, userbus.ErrNotFound) {
+			t.Errorf("Should get a not found error by email : %v", err)
+		}
+
+		// ---------------------------------------------------------------------
+
+		got, err := store.QueryByEmail(ctx, usrs[2].Email)
+		if err != nil {
+			t.Fatalf("Should be able to query by email : %s", err)
+		}
+
+		if got.ID != usrs[2].ID {
+			t.Errorf("Should get the user by email : got[%s] exp[%s]", got.ID, usrs[2].ID)
+		}
+
+		// ---------------------------------------------------------------------
+
+		exists, err := store.Exists(ctx, usrs[1].ID)
+		if err != nil {
+			t.Fatalf("Should be able to check a user exists : %s", err)
+		}
+
+		if !exists {
+			t.Errorf("Should find an existing user")
+		}
+
+		exists, err = store.Exists(ctx, uuid.New())
+		if err != nil {
+			t.Fatalf("Should be able to check a user exists : %s", err)
+		}
+
+		if exists {
+			t.Errorf("Should not find an unknown user")
+		}
+
+		filter := userbus.QueryFilter{
+			Name: dbtest.UserNamePointer("Kennedy"),
+		}
+
+		n, err := store.Count(ctx, filter)
+		if err != nil {
+			t.Fatalf("Should be able to count users : %s", err)
+		}
+
+		if n != 2 {
+			t.Errorf("Should get two users named Kennedy : %d", n)
+		}
+
+		orderBy := order.NewBy(userbus.OrderByName, order.DESC)
+
+		resp, err := store.Query(ctx, filter, orderBy, page.MustParse("1", "1"))
+		if err != nil {
+			t.Fatalf("Should be able to query users : %s", err)
+		}
+
+		if len(resp) != 1 || resp[0].ID != usrs[0].ID {
+			t.Errorf("Should get Bill first when ordering by name desc : %v", names(resp))
+		}
+
+		resp, err = store.Query(ctx, filter, orderBy, page.MustParse("2", "1"))
+		if err != nil {
+			t.Fatalf("Should be able to query users : %s", err)
+		}
+
+		if len(resp) != 1 || resp[0].ID != usrs[1].ID {
+			t.Errorf("Should get Ale on the second page : %v", names(resp))
+		}
+
+		resp, err = store.Query(ctx, filter, orderBy, page.MustParse("3", "1"))
+		if err != nil {
+			t.Fatalf("Should be able to query users : %s", err)
+		}
+
+		if len(resp) != 0 {
+			t.Errorf("Should get no users past the last page : %v", names(resp))
+		}
+
+		for _, pg := range []string{"1", "2", "3"} {
+			withCount, total, err := store.QueryWithCount(ctx, filter, orderBy, page.MustParse(pg, "1"))
+			if err != nil {
+				t.Fatalf("Should be able to query users with the count : %s", err)
+			}
+
+			if total != n {
+				t.Errorf("Should get the same total as the count on page %s : got[%d] exp[%d]", pg, total, n)
+			}
+
+			resp, err = store.Query(ctx, filter, orderBy, page.MustParse(pg, "1"))
+			if err != nil {
+				t.Fatalf("Should be able to query users : %s", err)
+			}
+
+			if diff := cmp.Diff(names(withCount), names(resp)); diff != "" {
+				t.Errorf("Should get the same users as the query on page %s : %s", pg, diff)
+			}
+		}
+
+		start := now.Add(-90 * time.Minute)
+		filter = userbus.QueryFilter{
+			StartCreatedDate: &start,
+		}
+
+		resp, err = store.Query(ctx, filter, userbus.DefaultOrderBy, page.MustParse("1", "10"))
+		if err != nil {
+			t.Fatalf("Should be able to query users : %s", err)
+		}
+
+		if len(resp) != 1 || resp[0].ID != usrs[2].ID {
+			t.Errorf("Should get only Jack created after the start date : %v", names(resp))
+		}
+
+		// ---------------------------------------------------------------------
+
+		md := userbus.Metadata{
+			"team":    "red",
+			"level":   float64(3),
+			"tags":    []any{"go", "sql"},
+			"manager": map[string]any{"name": "Ale"},
+		}
+
+		withMD := usrs[0]
+		withMD.Metadata = md
+		if _, err := store.Update(ctx, withMD, withMD.DateUpdated); err != nil {
+			t.Fatalf("Should be able to update the metadata : %s", err)
+		}
+
+		md["team"] = "blue"
+
+		got, err = store.QueryByID(ctx, withMD.ID)
+		if err != nil {
+			t.Fatalf("Should be able to query by id : %s", err)
+		}
+
+		expMD := userbus.Metadata{
+			"team":    "red",
+			"level":   float64(3),
+			"tags":    []any{"go", "sql"},
+			"manager": map[string]any{"name": "Ale"},
+		}
+
+		if diff := cmp.Diff(got.Metadata, expMD); diff != "" {
+			t.Errorf("Should get the stored metadata back : %s", diff)
+		}
+
+		got, err = store.QueryByID(ctx, usrs[1].ID)
+		if err != nil {
+			t.Fatalf("Should be able to query by id : %s", err)
+		}
+
+		if got.Metadata != nil {
+			t.Errorf("Should get no metadata for a user without it : %v", got.Metadata)
+		}
+
+		mdFilters := []struct {
+			metadata userbus.Metadata
+			matches  int
+		}{
+			{metadata: userbus.Metadata{"team": "red"}, matches: 1},
+			{metadata: userbus.Metadata{"team": "red", "level": float64(3)}, matches: 1},
+			{metadata: userbus.Metadata{"tags": []any{"sql"}}, matches: 1},
+			{metadata: userbus.Metadata{"manager": map[string]any{"name": "Ale"}}, matches: 1},
+			{metadata: userbus.Metadata{"team": "blue"}, matches: 0},
+			{metadata: userbus.Metadata{"level": "3"}, matches: 0},
+			{metadata: userbus.Metadata{"tags": []any{"rust"}}, matches: 0},
+		}
+
+		for _, mf := range mdFilters {
+			resp, err = store.Query(ctx, userbus.QueryFilter{Metadata: mf.metadata}, userbus.DefaultOrderBy, page.MustParse("1", "10"))
+			if err != nil {
+				t.Fatalf("Should be able to query users by metadata : %s", err)
+			}
+
+			if len(resp) != mf.matches {
+				t.Errorf("Should get %d users for metadata %v : %v", mf.matches, mf.metadata, names(resp))
+			}
+		}
+
+		// ---------------------------------------------------------------------
+
+		withTags := usrs[1]
+		withTags.Tags = []string{"go", "sql"}
+		if _, err := store.Update(ctx, withTags, withTags.DateUpdated); err != nil {
+			t.Fatalf("Should be able to update the tags : %s", err)
+		}
+
+		withTags = usrs[2]
+		withTags.Tags = []string{"go"}
+		withTags.Roles = []userbus.Role{userbus.Roles.Admin, userbus.Roles.User}
+		if _, err := store.Update(ctx, withTags, withTags.DateUpdated); err != nil {
+			t.Fatalf("Should be able to update the tags : %s", err)
+		}
+
+		got, err = store.QueryByID(ctx, usrs[1].ID)
+		if err != nil {
+			t.Fatalf("Should be able to query by id : %s", err)
+		}
+
+		if diff := cmp.Diff(got.Tags, []string{"go", "sql"}); diff != "" {
+			t.Errorf("Should get the stored tags back : %s", diff)
+		}
+
+		got, err = store.QueryByID(ctx, usrs[0].ID)
+		if err != nil {
+			t.Fatalf("Should be able to query by id : %s", err)
+		}
+
+		if got.Tags != nil {
+			t.Errorf("Should get no tags for a user without them : %v", got.Tags)
+		}
+
+		tagFilters := []struct {
+			filter userbus.QueryFilter
+			exp    []string
+		}{
+			{filter: userbus.QueryFilter{Tags: []string{"go"}}, exp: []string{"Ale Kennedy", "Jack Smith"}},
+			{filter: userbus.QueryFilter{Tags: []string{"sql", "go"}}, exp: []string{"Ale Kennedy"}},
+			{filter: userbus.QueryFilter{Tags: []string{"rust"}}, exp: []string{}},
+			{filter: userbus.QueryFilter{Role: &userbus.Roles.Admin}, exp: []string{"Jack Smith"}},
+			{filter: userbus.QueryFilter{Role: &userbus.Roles.User, Tags: []string{"sql"}}, exp: []string{"Ale Kennedy"}},
+			{filter: exprFilter(sdkfilter.Condition{Field: userbus.FilterByName, Op: sdkfilter.Like, Values: []any{"Kennedy"}}), exp: []string{"Ale Kennedy", "Bill Kennedy"}},
+			{filter: exprFilter(sdkfilter.Condition{Field: userbus.FilterByName, Op: sdkfilter.Like, Values: []any{"%"}}), exp: []string{}},
+			{filter: exprFilter(sdkfilter.Condition{Field: userbus.FilterByEmail, Op: sdkfilter.In, Values: []any{"bill@example.com", "jack@example.com"}}), exp: []string{"Bill Kennedy", "Jack Smith"}},
+			{
+				filter: exprFilter(
+					sdkfilter.Condition{Field: userbus.FilterByDateCreated, Op: sdkfilter.GT, Values: []any{now.Add(-150 * time.Minute).UTC()}},
+					sdkfilter.Condition{Field: userbus.FilterByEmail, Op: sdkfilter.NE, Values: []any{"ale@example.com"}},
+				),
+				exp: []string{"Jack Smith"},
+			},
+		}
+
+		for _, tf := range tagFilters {
+			resp, err = store.Query(ctx, tf.filter, order.NewBy(userbus.OrderByName, order.ASC), page.MustParse("1", "10"))
+			if err != nil {
+				t.Fatalf("Should be able to query users by tags : %s", err)
+			}
+
+			if diff := cmp.Diff(names(resp), tf.exp); diff != "" {
+				t.Errorf("Should get the users for the filter %+v : %s", tf.filter, diff)
+			}
+		}
+
+		// ---------------------------------------------------------------------
+
+		userID := usrs[1].ID
+		lockUntil := now.Add(time.Hour)
+
+		for i := 1; i <= 3; i++ {
+			la, err := store.RecordLoginFailure(ctx, userID, 3, lockUntil, now)
+			if err != nil {
+				t.Fatalf("Should be able to record a login failure : %s", err)
+			}
+
+			if la.Failed != i || la.Locked(now) != (i == 3) {
+				t.Errorf("Should get the expected attempts after %d failures : %+v", i, la)
+			}
+		}
+
+		la, err := store.QueryLoginAttempts(ctx, userID)
+		if err != nil {
+			t.Fatalf("Should be able to query login attempts : %s", err)
+		}
+
+		if la.Failed != 3 || !la.LockedUntil.Equal(lockUntil) {
+			t.Errorf("Should get the account locked until %v : %+v", lockUntil, la)
+		}
+
+		// Once the lock expires the count starts over.
+		later := lockUntil.Add(time.Minute)
+		la, err = store.RecordLoginFailure(ctx, userID, 3, later.Add(time.Hour), later)
+		if err != nil {
+			t.Fatalf("Should be able to record a login failure : %s", err)
+		}
+
+		if la.Failed != 1 || la.Locked(later) {
+			t.Errorf("Should start over after the lock expired : %+v", la)
+		}
+
+		if err := store.ResetLoginAttempts(ctx, userID); err != nil {
+			t.Fatalf("Should be able to reset login attempts : %s", err)
+		}
+
+		la, err = store.QueryLoginAttempts(ctx, userID)
+		if err != nil {
+			t.Fatalf("Should be able to query login attempts : %s", err)
+		}
+
+		if la.Failed != 0 || la.Locked(now) {
+			t.Errorf("Should have no failed attempts after a reset : %+v", la)
+		}
+
+		// ---------------------------------------------------------------------
+
+		ins := newUser("Sam Jones", "sam@example.com", now)
+		got, inserted, err := store.Upsert(ctx, ins)
+		if err != nil {
+			t.Fatalf("Should be able to upsert a new user : %s", err)
+		}
+
+		if !inserted || got.ID != ins.ID {
+			t.Errorf("Should insert a user with a new email : inserted[%t] got[%s] exp[%s]", inserted, got.ID, ins.ID)
+		}
+
+		conflict := newUser("Samuel Jones", "sam@example.com", now.Add(time.Minute))
+		conflict.Department = "Sales"
+		conflict.Tags = []string{"go"}
+		conflict.PasswordHash = []byte("other")
+		conflict.Enabled = false
+
+		got, inserted, err = store.Upsert(ctx, conflict)
+		if err != nil {
+			t.Fatalf("Should be able to upsert an existing user : %s", err)
+		}
+
+		if inserted {
+			t.Errorf("Should update the user with the same email")
+		}
+
+		exp := ins
+		exp.Name = conflict.Name
+		exp.Department = conflict.Department
+		exp.Tags = conflict.Tags
+		exp.DateUpdated = conflict.DateUpdated
+
+		if diff := cmp.Diff(got, exp); diff != "" {
+			t.Errorf("Should only update the upserted fields : %s", diff)
+		}
+
+		stored, err := store.QueryByEmail(ctx, ins.Email)
+		if err != nil {
+			t.Fatalf("Should be able to query by email : %s", err)
+		}
+
+		if diff := cmp.Diff(stored, exp); diff != "" {
+			t.Errorf("Should store the upserted fields : %s", diff)
+		}
+
+		// ---------------------------------------------------------------------
+
+		// Create and update return the user as it was stored, like the
+		// precision of the timestamps, so it matches what is read back.
+		pat := newUser("Pat Lee", "pat@example.com", time.Now())
+		created, err := store.Create(ctx, pat)
+		if err != nil {
+			t.Fatalf("Should be able to create a user : %s", err)
+		}
+
+		got, err = store.QueryByID(ctx, pat.ID)
+		if err != nil {
+			t.Fatalf("Should be able to query by id : %s", err)
+		}
+
+		if diff := cmp.Diff(created, got); diff != "" {
+			t.Errorf("Should return the created user as it was stored : %s", diff)
+		}
+
+		pat.Department = "Sales"
+		pat.DateUpdated = time.Now()
+		updated, err := store.Update(ctx, pat, created.DateUpdated)
+		if err != nil {
+			t.Fatalf("Should be able to update a user : %s", err)
+		}
+
+		if _, err := store.Update(ctx, pat, created.DateUpdated); !errors.Is(err, userbus.ErrModified) {
+			t.Errorf("Should get a modified error updating with a stale update time : %v", err)
+		}
+
+		got, err = store.QueryByID(ctx, pat.ID)
+		if err != nil {
+			t.Fatalf("Should be able to query by id : %s", err)
+		}
+
+		if diff := cmp.Diff(updated, got); diff != "" {
+			t.Errorf("Should return the updated user as it was stored : %s", diff)
+		}
+
+		if updated.Department != "Sales" || !updated.DateCreated.Equal(created.DateCreated) {
+			t.Errorf("Should only change the updated fields : %+v", updated)
+		}
+
+		if _, err := store.Update(ctx, newUser("Nobody", "nobody@example.com", now), now); !errors.Is(err, userbus.ErrNotFound) {
+			t.Errorf("Should get a not found error updating an unknown user : %v", err)
+		}
+
+		// ---------------------------------------------------------------------
+
+		if err := store.Delete(ctx, usrs[0]); err != nil {
+			t.Fatalf("Should be able to delete a user : %s", err)
+		}
+
+		if _, err := store.QueryByID(ctx, usrs[0].ID); !errors.Is(err, userbus.ErrNotFound) {
+			t.Errorf("Should get a not found error after delete : %v", err)
+		}
+	}
+
+	return f
+}
+
+func newUser(name string, email string, created time.Time) userbus.User {
+	return userbus.User{
+		ID:           uuid.New(),
+		Name:         userbus.MustParseName(name),
+		Email:        mail.Address{Address: email},
+		Roles:        []userbus.Role{userbus.Roles.User},
+		PasswordHash: []byte("hash"),
+		Department:   "IT",
+		Enabled:      true,
+		DateCreated:  created,
+		DateUpdated:  created,
+	}
+}
+
+func names(usrs []userbus.User) []string {
+	names := make([]string, len(usrs))
+	for i, usr := range usrs {
+		names[i] = usr.Name.String()
+	}
+
+	return names
+}
+
+func exprFilter(conditions ...sdkfilter.Condition) userbus.QueryFilter {
+	return userbus.QueryFilter{Expr: sdkfilter.Filter{Conditions: conditions}}
+}
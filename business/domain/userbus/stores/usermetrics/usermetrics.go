@@ -0,0 +1,268 @@
+// Package usermetrics contains user related CRUD functionality that records
+// the number and latency of the operations performed on another store.
+package usermetrics
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/google/uuid"
+)
+
+// Set of outcomes recorded for an operation.
+const (
+	OutcomeSuccess  = "success"
+	OutcomeNotFound = "not_found"
+	OutcomeConflict = "conflict"
+	OutcomeError    = "error"
+)
+
+// Metrics represents the set of OpenTelemetry metrics recorded for the user
+// store operations. They are registered once and shared by every store.
+type Metrics struct {
+	Operations *otel.Counter
+	Duration   *otel.Histogram
+}
+
+// NewMetrics registers the store metrics with the exporter.
+func NewMetrics(exp *otel.Exporter) *Metrics {
+	return &Metrics{
+		Operations: exp.Counter("userbus.operations", "Number of user store operations.", "{operation}"),
+		Duration: exp.Histogram("userbus.operation.duration", "Duration of user store operations.", "s",
+			[]float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}),
+	}
+}
+
+// Attrs returns the attributes a measurement is recorded with for the
+// operation, like Create, and the outcome.
+func Attrs(operation string, outcome string) []otel.Attr {
+	return []otel.Attr{
+		{Key: "operation", Value: "userbus." + operation},
+		{Key: "outcome", Value: outcome},
+	}
+}
+
+// Store manages the set of APIs for recording metrics of user data access.
+type Store struct {
+	storer  userbus.Storer
+	metrics *Metrics
+}
+
+// NewStore constructs the api for recording metrics of the storer.
+func NewStore(metrics *Metrics, storer userbus.Storer) *Store {
+	return &Store{
+		storer:  storer,
+		metrics: metrics,
+	}
+}
+
+// NewWithTx constructs a new Store value replacing the sqlx DB
+// value with a sqlx DB value that is currently inside a transaction.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (userbus.Storer, error) {
+	storer, err := s.storer.NewWithTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewStore(s.metrics, storer), nil
+}
+
+// Create inserts a new user into the database.
+func (s *Store) Create(ctx context.Context, usr userbus.User) (userbus.User, error) {
+	start := time.Now()
+
+	usr, err := s.storer.Create(ctx, usr)
+	s.record("Create", start, err)
+
+	return usr, err
+}
+
+// Update replaces a user document in the database.
+func (s *Store) Update(ctx context.Context, usr userbus.User, lastUpdated time.Time) (userbus.User, error) {
+	start := time.Now()
+
+	usr, err := s.storer.Update(ctx, usr, lastUpdated)
+	s.record("Update", start, err)
+
+	return usr, err
+}
+
+// Upsert inserts or updates a user in the database.
+func (s *Store) Upsert(ctx context.Context, usr userbus.User) (userbus.User, bool, error) {
+	start := time.Now()
+
+	usr, inserted, err := s.storer.Upsert(ctx, usr)
+	s.record("Upsert", start, err)
+
+	return usr, inserted, err
+}
+
+// Delete removes a user from the database.
+func (s *Store) Delete(ctx context.Context, usr userbus.User) error {
+	start := time.Now()
+
+	err := s.storer.Delete(ctx, usr)
+	s.record("Delete", start, err)
+
+	return err
+}
+
+// Query retrieves a list of existing users from the database.
+func (s *Store) Query(ctx context.Context, filter userbus.QueryFilter, orderBy order.By, page page.Page) ([]userbus.User, error) {
+	start := time.Now()
+
+	usrs, err := s.storer.Query(ctx, filter, orderBy, page)
+	s.record("Query", start, err)
+
+	return usrs, err
+}
+
+// QueryWithCount retrieves a page of users along with the total number of
+// users matching the filter.
+func (s *Store) QueryWithCount(ctx context.Context, filter userbus.QueryFilter, orderBy order.By, page page.Page) ([]userbus.User, int, error) {
+	start := time.Now()
+
+	usrs, total, err := s.storer.QueryWithCount(ctx, filter, orderBy, page)
+	s.record("QueryWithCount", start, err)
+
+	return usrs, total, err
+}
+
+// QueryEach passes every user matching the filter to fn in the specified order.
+func (s *Store) QueryEach(ctx context.Context, filter userbus.QueryFilter, orderBy order.By, fn func(userbus.User) error) error {
+	start := time.Now()
+
+	err := s.storer.QueryEach(ctx, filter, orderBy, fn)
+	s.record("QueryEach", start, err)
+
+	return err
+}
+
+// Count returns the total number of users in the DB.
+func (s *Store) Count(ctx context.Context, filter userbus.QueryFilter) (int, error) {
+	start := time.Now()
+
+	n, err := s.storer.Count(ctx, filter)
+	s.record("Count", start, err)
+
+	return n, err
+}
+
+// QueryByID gets the specified user from the database.
+func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
+	start := time.Now()
+
+	usr, err := s.storer.QueryByID(ctx, userID)
+	s.record("QueryByID", start, err)
+
+	return usr, err
+}
+
+// Exists reports whether the specified user is in the database.
+func (s *Store) Exists(ctx context.Context, userID uuid.UUID) (bool, error) {
+	start := time.Now()
+
+	exists, err := s.storer.Exists(ctx, userID)
+	s.record("Exists", start, err)
+
+	return exists, err
+}
+
+// QueryByEmail gets the specified user from the database by email.
+func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.User, error) {
+	start := time.Now()
+
+	usr, err := s.storer.QueryByEmail(ctx, email)
+	s.record("QueryByEmail", start, err)
+
+	return usr, err
+}
+
+// QueryLoginAttempts gets the failed login state for the user.
+func (s *Store) QueryLoginAttempts(ctx context.Context, userID uuid.UUID) (userbus.LoginAttempts, error) {
+	start := time.Now()
+
+	la, err := s.storer.QueryLoginAttempts(ctx, userID)
+	s.record("QueryLoginAttempts", start, err)
+
+	return la, err
+}
+
+// RecordLoginFailure counts a failed login for the user.
+func (s *Store) RecordLoginFailure(ctx context.Context, userID uuid.UUID, maxAttempts int, lockUntil time.Time, now time.Time) (userbus.LoginAttempts, error) {
+	start := time.Now()
+
+	la, err := s.storer.RecordLoginFailure(ctx, userID, maxAttempts, lockUntil, now)
+	s.record("RecordLoginFailure", start, err)
+
+	return la, err
+}
+
+// ResetLoginAttempts clears the failed login state for the user.
+func (s *Store) ResetLoginAttempts(ctx context.Context, userID uuid.UUID) error {
+	start := time.Now()
+
+	err := s.storer.ResetLoginAttempts(ctx, userID)
+	s.record("ResetLoginAttempts", start, err)
+
+	return err
+}
+
+// CreatePasswordReset inserts a password reset.
+func (s *Store) CreatePasswordReset(ctx context.Context, pr userbus.PasswordReset) error {
+	start := time.Now()
+
+	err := s.storer.CreatePasswordReset(ctx, pr)
+	s.record("CreatePasswordReset", start, err)
+
+	return err
+}
+
+// ConsumePasswordReset removes the password reset for the token hash and returns it.
+func (s *Store) ConsumePasswordReset(ctx context.Context, tokenHash string) (userbus.PasswordReset, error) {
+	start := time.Now()
+
+	pr, err := s.storer.ConsumePasswordReset(ctx, tokenHash)
+	s.record("ConsumePasswordReset", start, err)
+
+	return pr, err
+}
+
+// DeletePasswordResets removes all the password resets for the user.
+func (s *Store) DeletePasswordResets(ctx context.Context, userID uuid.UUID) error {
+	start := time.Now()
+
+	err := s.storer.DeletePasswordResets(ctx, userID)
+	s.record("DeletePasswordResets", start, err)
+
+	return err
+}
+
+// record counts the operation and its latency with the outcome of err.
+func (s *Store) record(operation string, start time.Time, err error) {
+	attrs := Attrs(operation, outcome(err))
+
+	s.metrics.Operations.Add(1, attrs...)
+	s.metrics.Duration.Record(time.Since(start).Seconds(), attrs...)
+}
+
+// outcome classifies the error returned by an operation.
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return OutcomeSuccess
+	case errors.Is(err, userbus.ErrNotFound):
+		return OutcomeNotFound
+	case errors.Is(err, userbus.ErrUniqueEmail):
+		return OutcomeConflict
+	}
+
+	return OutcomeError
+}
@@ -0,0 +1,96 @@
+package usermetrics_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermetrics"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/google/uuid"
+)
+
+func Test_Metrics(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	exp, err := otel.NewExporter(otel.Config{Endpoint: srv.URL, Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("Should be able to construct the exporter : %s", err)
+	}
+	defer exp.Shutdown(context.Background())
+
+	metrics := usermetrics.NewMetrics(exp)
+	store := usermetrics.NewStore(metrics, usermem.NewStore())
+
+	ctx := context.Background()
+
+	usr := userbus.User{
+		ID:           uuid.New(),
+		Name:         userbus.MustParseName("Bill Kennedy"),
+		Email:        mail.Address{Address: "bill@example.com"},
+		Roles:        []userbus.Role{userbus.Roles.User},
+		PasswordHash: []byte("hash"),
+		Enabled:      true,
+	}
+
+	if _, err := store.Create(ctx, usr); err != nil {
+		t.Fatalf("Should be able to create the user : %s", err)
+	}
+
+	dup := usr
+	dup.ID = uuid.New()
+
+	if _, err := store.Create(ctx, dup); !errors.Is(err, userbus.ErrUniqueEmail) {
+		t.Fatalf("Should get a unique email error : %v", err)
+	}
+
+	for range 2 {
+		if _, err := store.QueryByID(ctx, usr.ID); err != nil {
+			t.Fatalf("Should be able to query the user : %s", err)
+		}
+	}
+
+	if _, err := store.QueryByID(ctx, uuid.New()); !errors.Is(err, userbus.ErrNotFound) {
+		t.Fatalf("Should get a not found error : %v", err)
+	}
+
+	if _, err := store.Query(ctx, userbus.QueryFilter{}, order.NewBy("unknown", order.ASC), page.MustParse("1", "10")); err == nil {
+		t.Fatalf("Should get an error for an unknown order field")
+	}
+
+	table := []struct {
+		operation string
+		outcome   string
+		count     int64
+	}{
+		{operation: "Create", outcome: usermetrics.OutcomeSuccess, count: 1},
+		{operation: "Create", outcome: usermetrics.OutcomeConflict, count: 1},
+		{operation: "QueryByID", outcome: usermetrics.OutcomeSuccess, count: 2},
+		{operation: "QueryByID", outcome: usermetrics.OutcomeNotFound, count: 1},
+		{operation: "Query", outcome: usermetrics.OutcomeError, count: 1},
+		{operation: "Delete", outcome: usermetrics.OutcomeSuccess, count: 0},
+	}
+
+	for _, tt := range table {
+		attrs := usermetrics.Attrs(tt.operation, tt.outcome)
+
+		if got := metrics.Operations.Value(attrs...); got != tt.count {
+			t.Errorf("Should count %s/%s : got[%d] exp[%d]", tt.operation, tt.outcome, got, tt.count)
+		}
+
+		if got := metrics.Duration.Count(attrs...); got != uint64(tt.count) {
+			t.Errorf("Should record the latency of %s/%s : got[%d] exp[%d]", tt.operation, tt.outcome, got, tt.count)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package userbus_test
+
+import (
+	"context"
+	"io"
+	"net/mail"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/business/sdk/hasher"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_Upsert(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	h, err := hasher.New(hasher.Config{Algorithm: hasher.AlgBcrypt, BcryptCost: 4})
+	if err != nil {
+		t.Fatalf("Should be able to construct the hasher : %s", err)
+	}
+
+	bus := userbus.NewBusiness(log, delegate.New(log), usermem.NewStore(), userbus.WithHasher(h))
+
+	nu := userbus.NewUser{
+		Name:       userbus.MustParseName("Bill Kennedy"),
+		Email:      mail.Address{Address: "bill@example.com"},
+		Roles:      []userbus.Role{userbus.Roles.User},
+		Department: "IT",
+		Password:   "gophers",
+	}
+
+	usr, inserted, err := bus.Upsert(ctx, nu)
+	if err != nil {
+		t.Fatalf("Should be able to upsert a new user : %s", err)
+	}
+
+	if !inserted {
+		t.Fatalf("Should insert a user with a new email")
+	}
+
+	if _, err := bus.Authenticate(ctx, nu.Email, "gophers"); err != nil {
+		t.Fatalf("Should be able to authenticate the inserted user : %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	nu.Name = userbus.MustParseName("William Kennedy")
+	nu.Roles = []userbus.Role{userbus.Roles.Admin}
+	nu.Department = "Sales"
+	nu.Password = "changed"
+
+	upd, inserted, err := bus.Upsert(ctx, nu)
+	if err != nil {
+		t.Fatalf("Should be able to upsert an existing user : %s", err)
+	}
+
+	if inserted {
+		t.Fatalf("Should update the user with the same email")
+	}
+
+	if upd.ID != usr.ID {
+		t.Errorf("Should keep the id of the existing user : got[%s] exp[%s]", upd.ID, usr.ID)
+	}
+
+	if upd.Name != nu.Name || upd.Department != "Sales" || len(upd.Roles) != 1 || upd.Roles[0] != userbus.Roles.Admin {
+		t.Errorf("Should update the name, roles and department : %+v", upd)
+	}
+
+	if !upd.DateCreated.Equal(usr.DateCreated) {
+		t.Errorf("Should keep the created date : got[%v] exp[%v]", upd.DateCreated, usr.DateCreated)
+	}
+
+	if _, err := bus.Authenticate(ctx, nu.Email, "gophers"); err != nil {
+		t.Errorf("Should keep the password of the existing user : %s", err)
+	}
+
+	n, err := bus.Count(ctx, userbus.QueryFilter{})
+	if err != nil {
+		t.Fatalf("Should be able to count users : %s", err)
+	}
+
+	if n != 1 {
+		t.Errorf("Should have a single user : %d", n)
+	}
+}
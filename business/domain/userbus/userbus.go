@@ -3,18 +3,23 @@ package userbus
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/mail"
+	"sync"
 	"time"
 
 	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/business/sdk/hasher"
 	"github.com/ardanlabs/service/business/sdk/order"
 	"github.com/ardanlabs/service/business/sdk/page"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 )
 
 // Set of error variables for CRUD operations.
@@ -22,19 +27,37 @@ var (
 	ErrNotFound              = errors.New("user not found")
 	ErrUniqueEmail           = errors.New("email is not unique")
 	ErrAuthenticationFailure = errors.New("authentication failed")
+	ErrAccountLocked         = errors.New("account is locked")
+	ErrInvalidResetToken     = errors.New("invalid password reset token")
+	ErrResetTokenExpired     = errors.New("password reset token has expired")
+	ErrModified              = errors.New("user has been modified")
 )
 
+// defaultResetTTL is how long a password reset token is valid for when a
+// different duration isn't configured.
+const defaultResetTTL = time.Hour
+
 // Storer interface declares the behavior this package needs to perists and
 // retrieve data.
 type Storer interface {
 	NewWithTx(tx sqldb.CommitRollbacker) (Storer, error)
-	Create(ctx context.Context, usr User) error
-	Update(ctx context.Context, usr User) error
+	Create(ctx context.Context, usr User) (User, error)
+	Update(ctx context.Context, usr User, lastUpdated time.Time) (User, error)
+	Upsert(ctx context.Context, usr User) (User, bool, error)
 	Delete(ctx context.Context, usr User) error
 	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]User, error)
+	QueryWithCount(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]User, int, error)
+	QueryEach(ctx context.Context, filter QueryFilter, orderBy order.By, fn func(User) error) error
 	Count(ctx context.Context, filter QueryFilter) (int, error)
 	QueryByID(ctx context.Context, userID uuid.UUID) (User, error)
+	Exists(ctx context.Context, userID uuid.UUID) (bool, error)
 	QueryByEmail(ctx context.Context, email mail.Address) (User, error)
+	QueryLoginAttempts(ctx context.Context, userID uuid.UUID) (LoginAttempts, error)
+	RecordLoginFailure(ctx context.Context, userID uuid.UUID, maxAttempts int, lockUntil time.Time, now time.Time) (LoginAttempts, error)
+	ResetLoginAttempts(ctx context.Context, userID uuid.UUID) error
+	CreatePasswordReset(ctx context.Context, pr PasswordReset) error
+	ConsumePasswordReset(ctx context.Context, tokenHash string) (PasswordReset, error)
+	DeletePasswordResets(ctx context.Context, userID uuid.UUID) error
 }
 
 // Business manages the set of APIs for user access.
@@ -42,15 +65,68 @@ type Business struct {
 	log      *logger.Logger
 	storer   Storer
 	delegate *delegate.Delegate
+	hasher   hasher.Hasher
+	lockout  lockout
+	resetTTL time.Duration
+	decoy    *decoyHash
+}
+
+type lockout struct {
+	maxAttempts int
+	duration    time.Duration
+}
+
+// WithLockout locks an account for the specified duration after the number
+// of consecutive failed logins is reached. A successful login resets the
+// count. By default accounts are never locked.
+func WithLockout(maxAttempts int, duration time.Duration) func(b *Business) {
+	return func(b *Business) {
+		if maxAttempts > 0 && duration > 0 {
+			b.lockout = lockout{
+				maxAttempts: maxAttempts,
+				duration:    duration,
+			}
+		}
+	}
+}
+
+// WithPasswordResetTTL sets how long a password reset token is valid for. By
+// default tokens are valid for an hour.
+func WithPasswordResetTTL(ttl time.Duration) func(b *Business) {
+	return func(b *Business) {
+		if ttl > 0 {
+			b.resetTTL = ttl
+		}
+	}
+}
+
+// WithHasher sets the hasher used for passwords. By default passwords are
+// hashed with bcrypt using the default cost.
+func WithHasher(h hasher.Hasher) func(b *Business) {
+	return func(b *Business) {
+		if h != nil {
+			b.hasher = h
+		}
+	}
 }
 
 // NewBusiness constructs a user business API for use.
-func NewBusiness(log *logger.Logger, delegate *delegate.Delegate, storer Storer) *Business {
-	return &Business{
+func NewBusiness(log *logger.Logger, delegate *delegate.Delegate, storer Storer, options ...func(b *Business)) *Business {
+	b := Business{
 		log:      log,
 		delegate: delegate,
 		storer:   storer,
+		hasher:   hasher.Default(),
+		resetTTL: defaultResetTTL,
+	}
+
+	for _, option := range options {
+		option(&b)
 	}
+
+	b.decoy = &decoyHash{hasher: b.hasher}
+
+	return &b
 }
 
 // NewWithTx constructs a new business value that will use the
@@ -65,6 +141,10 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 		log:      b.log,
 		delegate: b.delegate,
 		storer:   storer,
+		hasher:   b.hasher,
+		lockout:  b.lockout,
+		resetTTL: b.resetTTL,
+		decoy:    b.decoy,
 	}
 
 	return &bus, nil
@@ -72,9 +152,9 @@ func (b *Business) NewWithTx(tx sqldb.CommitRollbacker) (*Business, error) {
 
 // Create adds a new user to the system.
 func (b *Business) Create(ctx context.Context, nu NewUser) (User, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(nu.Password), bcrypt.DefaultCost)
+	hash, err := b.hasher.Hash(nu.Password)
 	if err != nil {
-		return User{}, fmt.Errorf("generatefrompassword: %w", err)
+		return User{}, fmt.Errorf("hash: %w", err)
 	}
 
 	now := time.Now()
@@ -86,25 +166,88 @@ func (b *Business) Create(ctx context.Context, nu NewUser) (User, error) {
 		PasswordHash: hash,
 		Roles:        nu.Roles,
 		Department:   nu.Department,
+		Metadata:     nu.Metadata,
+		Tags:         nu.Tags,
 		Enabled:      true,
 		DateCreated:  now,
 		DateUpdated:  now,
 	}
 
-	if err := b.storer.Create(ctx, usr); err != nil {
+	usr, err = b.storer.Create(ctx, usr)
+	if err != nil {
 		return User{}, fmt.Errorf("create: %w", err)
 	}
 
 	return usr, nil
 }
 
-// Update modifies information about a user.
+// Upsert adds a new user to the system or, when a user with the same email
+// already exists, replaces the name, roles, department, metadata and tags of
+// that user. The password and the enabled and verified state of an existing
+// user are left alone. It reports whether the user was added.
+func (b *Business) Upsert(ctx context.Context, nu NewUser) (User, bool, error) {
+	hash, err := b.hasher.Hash(nu.Password)
+	if err != nil {
+		return User{}, false, fmt.Errorf("hash: %w", err)
+	}
+
+	now := time.Now()
+
+	usr := User{
+		ID:           uuid.New(),
+		Name:         nu.Name,
+		Email:        nu.Email,
+		PasswordHash: hash,
+		Roles:        nu.Roles,
+		Department:   nu.Department,
+		Metadata:     nu.Metadata,
+		Tags:         nu.Tags,
+		Enabled:      true,
+		DateCreated:  now,
+		DateUpdated:  now,
+	}
+
+	usr, inserted, err := b.storer.Upsert(ctx, usr)
+	if err != nil {
+		return User{}, false, fmt.Errorf("upsert: %w", err)
+	}
+
+	if inserted {
+		return usr, true, nil
+	}
+
+	uu := UpdateUser{
+		Name:       &nu.Name,
+		Roles:      nu.Roles,
+		Department: &nu.Department,
+		Metadata:   nu.Metadata,
+		Tags:       nu.Tags,
+	}
+
+	// Other domains may need to know when a user is updated so business
+	// logic can be applied. This represents a delegate call to other domains.
+	if err := b.delegate.Call(ctx, ActionUpdatedData(uu, usr.ID)); err != nil {
+		return User{}, false, fmt.Errorf("failed to execute `%s` action: %w", ActionUpdated, err)
+	}
+
+	return usr, false, nil
+}
+
+// Update modifies information about a user. The update fails with
+// ErrModified when the stored user has changed since usr was read.
 func (b *Business) Update(ctx context.Context, usr User, uu UpdateUser) (User, error) {
+	lastUpdated := usr.DateUpdated
+
 	if uu.Name != nil {
 		usr.Name = *uu.Name
 	}
 
 	if uu.Email != nil {
+
+		// A new email address needs to be verified again.
+		if uu.Email.Address != usr.Email.Address {
+			usr.EmailVerified = false
+		}
 		usr.Email = *uu.Email
 	}
 
@@ -113,9 +256,9 @@ func (b *Business) Update(ctx context.Context, usr User, uu UpdateUser) (User, e
 	}
 
 	if uu.Password != nil {
-		pw, err := bcrypt.GenerateFromPassword([]byte(*uu.Password), bcrypt.DefaultCost)
+		pw, err := b.hasher.Hash(*uu.Password)
 		if err != nil {
-			return User{}, fmt.Errorf("generatefrompassword: %w", err)
+			return User{}, fmt.Errorf("hash: %w", err)
 		}
 		usr.PasswordHash = pw
 	}
@@ -127,9 +270,18 @@ func (b *Business) Update(ctx context.Context, usr User, uu UpdateUser) (User, e
 	if uu.Enabled != nil {
 		usr.Enabled = *uu.Enabled
 	}
+
+	if uu.Metadata != nil {
+		usr.Metadata = uu.Metadata
+	}
+
+	if uu.Tags != nil {
+		usr.Tags = uu.Tags
+	}
 	usr.DateUpdated = time.Now()
 
-	if err := b.storer.Update(ctx, usr); err != nil {
+	usr, err := b.storer.Update(ctx, usr, lastUpdated)
+	if err != nil {
 		return User{}, fmt.Errorf("update: %w", err)
 	}
 
@@ -142,6 +294,120 @@ func (b *Business) Update(ctx context.Context, usr User, uu UpdateUser) (User, e
 	return usr, nil
 }
 
+// VerifyEmail marks the email address of the user as verified.
+func (b *Business) VerifyEmail(ctx context.Context, usr User) (User, error) {
+	if usr.EmailVerified {
+		return usr, nil
+	}
+
+	lastUpdated := usr.DateUpdated
+
+	usr.EmailVerified = true
+	usr.DateUpdated = time.Now()
+
+	usr, err := b.storer.Update(ctx, usr, lastUpdated)
+	if err != nil {
+		return User{}, fmt.Errorf("update: %w", err)
+	}
+
+	return usr, nil
+}
+
+// RequestPasswordReset generates a single use token that can be used to reset
+// the password of the user with the specified email. Only a hash of the token
+// is stored and any token previously requested for the user is discarded.
+// Disabled users can't reset their password and are reported as not found.
+func (b *Business) RequestPasswordReset(ctx context.Context, email mail.Address) (string, PasswordReset, error) {
+	usr, err := b.QueryByEmail(ctx, email)
+	if err != nil {
+		return "", PasswordReset{}, fmt.Errorf("query: email[%s]: %w", email, err)
+	}
+
+	if !usr.Enabled {
+		return "", PasswordReset{}, fmt.Errorf("userID[%s] disabled: %w", usr.ID, ErrNotFound)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", PasswordReset{}, fmt.Errorf("generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now()
+
+	pr := PasswordReset{
+		TokenHash:   hashResetToken(token),
+		UserID:      usr.ID,
+		ExpiresAt:   now.Add(b.resetTTL),
+		DateCreated: now,
+	}
+
+	if err := b.storer.DeletePasswordResets(ctx, usr.ID); err != nil {
+		return "", PasswordReset{}, fmt.Errorf("delete password resets: userID[%s]: %w", usr.ID, err)
+	}
+
+	if err := b.storer.CreatePasswordReset(ctx, pr); err != nil {
+		return "", PasswordReset{}, fmt.Errorf("create password reset: userID[%s]: %w", usr.ID, err)
+	}
+
+	return token, pr, nil
+}
+
+// ResetPassword consumes the password reset token and sets the new password
+// for the user. The token can't be used again, even if the reset fails. The
+// date the password changed is recorded so tokens issued before the reset
+// are no longer accepted.
+func (b *Business) ResetPassword(ctx context.Context, token string, password string) (User, error) {
+	pr, err := b.storer.ConsumePasswordReset(ctx, hashResetToken(token))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return User{}, ErrInvalidResetToken
+		}
+		return User{}, fmt.Errorf("consume password reset: %w", err)
+	}
+
+	now := time.Now()
+
+	if !pr.ExpiresAt.After(now) {
+		return User{}, ErrResetTokenExpired
+	}
+
+	usr, err := b.QueryByID(ctx, pr.UserID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return User{}, ErrInvalidResetToken
+		}
+		return User{}, fmt.Errorf("query: userID[%s]: %w", pr.UserID, err)
+	}
+
+	hash, err := b.hasher.Hash(password)
+	if err != nil {
+		return User{}, fmt.Errorf("hash: %w", err)
+	}
+
+	lastUpdated := usr.DateUpdated
+
+	usr.PasswordHash = hash
+	usr.DatePasswordChanged = now
+	usr.DateUpdated = now
+
+	usr, err = b.storer.Update(ctx, usr, lastUpdated)
+	if err != nil {
+		return User{}, fmt.Errorf("update: %w", err)
+	}
+
+	if err := b.storer.DeletePasswordResets(ctx, usr.ID); err != nil {
+		return User{}, fmt.Errorf("delete password resets: userID[%s]: %w", usr.ID, err)
+	}
+
+	// Proving access to the email address is enough to unlock the account.
+	if err := b.storer.ResetLoginAttempts(ctx, usr.ID); err != nil {
+		b.log.Error(ctx, "reset login attempts", "userID", usr.ID, "err", err)
+	}
+
+	return usr, nil
+}
+
 // Delete removes the specified user.
 func (b *Business) Delete(ctx context.Context, usr User) error {
 	if err := b.storer.Delete(ctx, usr); err != nil {
@@ -161,6 +427,29 @@ func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.
 	return users, nil
 }
 
+// QueryWithCount retrieves a list of existing users along with the total
+// number of users matching the filter, in a single round-trip to the
+// database when possible.
+func (b *Business) QueryWithCount(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]User, int, error) {
+	users, total, err := b.storer.QueryWithCount(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, 0, fmt.Errorf("querywithcount: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// QueryEach retrieves every user matching the filter in the specified order
+// and passes each one to fn without holding the full set in memory.
+// Iteration stops at the first error returned by fn.
+func (b *Business) QueryEach(ctx context.Context, filter QueryFilter, orderBy order.By, fn func(User) error) error {
+	if err := b.storer.QueryEach(ctx, filter, orderBy, fn); err != nil {
+		return fmt.Errorf("queryeach: %w", err)
+	}
+
+	return nil
+}
+
 // Count returns the total number of users.
 func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
 	return b.storer.Count(ctx, filter)
@@ -176,6 +465,17 @@ func (b *Business) QueryByID(ctx context.Context, userID uuid.UUID) (User, error
 	return user, nil
 }
 
+// Exists reports whether the user with the specified ID exists without
+// loading the user.
+func (b *Business) Exists(ctx context.Context, userID uuid.UUID) (bool, error) {
+	exists, err := b.storer.Exists(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("exists: userID[%s]: %w", userID, err)
+	}
+
+	return exists, nil
+}
+
 // QueryByEmail finds the user by a specified user email.
 func (b *Business) QueryByEmail(ctx context.Context, email mail.Address) (User, error) {
 	user, err := b.storer.QueryByEmail(ctx, email)
@@ -188,16 +488,117 @@ func (b *Business) QueryByEmail(ctx context.Context, email mail.Address) (User,
 
 // Authenticate finds a user by their email and verifies their passworb. On
 // success it returns a Claims User representing this user. The claims can be
-// used to generate a token for future authentication.
+// used to generate a token for future authentication. If the stored hash was
+// created with an outdated algorithm or parameters, the password is rehashed
+// with the current settings. When a lockout is configured, a locked account
+// is rejected even if the password is correct. A password is verified on
+// every path so the time taken doesn't reveal whether the email exists or
+// the account is locked.
 func (b *Business) Authenticate(ctx context.Context, email mail.Address, password string) (User, error) {
+
+	// A replica may still have a password or an account state that has
+	// since been changed.
+	ctx = sqldb.WithPrimary(ctx)
+
 	usr, err := b.QueryByEmail(ctx, email)
 	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			b.decoy.verify(password)
+		}
 		return User{}, fmt.Errorf("query: email[%s]: %w", email, err)
 	}
 
-	if err := bcrypt.CompareHashAndPassword(usr.PasswordHash, []byte(password)); err != nil {
-		return User{}, fmt.Errorf("comparehashandpassword: %w", ErrAuthenticationFailure)
+	var la LoginAttempts
+	if b.lockout.maxAttempts > 0 {
+		la, err = b.storer.QueryLoginAttempts(ctx, usr.ID)
+		if err != nil {
+			return User{}, fmt.Errorf("query login attempts: userID[%s]: %w", usr.ID, err)
+		}
+
+		if la.Locked(time.Now()) {
+			b.hasher.Verify(usr.PasswordHash, password)
+			return User{}, fmt.Errorf("userID[%s]: %w", usr.ID, ErrAccountLocked)
+		}
+	}
+
+	if err := b.hasher.Verify(usr.PasswordHash, password); err != nil {
+		if b.lockout.maxAttempts > 0 {
+			b.recordLoginFailure(ctx, usr)
+		}
+		return User{}, fmt.Errorf("verify: %w", ErrAuthenticationFailure)
+	}
+
+	if la.Failed > 0 {
+		if err := b.storer.ResetLoginAttempts(ctx, usr.ID); err != nil {
+			b.log.Error(ctx, "reset login attempts", "userID", usr.ID, "err", err)
+		}
+	}
+
+	if b.hasher.NeedsRehash(usr.PasswordHash) {
+		usr = b.rehash(ctx, usr, password)
 	}
 
 	return usr, nil
 }
+
+// recordLoginFailure counts a failed login and locks the account once the
+// maximum number of attempts is reached. The store performs this
+// atomically so concurrent logins across instances are counted correctly.
+func (b *Business) recordLoginFailure(ctx context.Context, usr User) {
+	now := time.Now()
+
+	la, err := b.storer.RecordLoginFailure(ctx, usr.ID, b.lockout.maxAttempts, now.Add(b.lockout.duration), now)
+	if err != nil {
+		b.log.Error(ctx, "record login failure", "userID", usr.ID, "err", err)
+		return
+	}
+
+	if la.Locked(now) {
+		b.log.Info(ctx, "account locked", "userID", usr.ID, "failed", la.Failed, "lockedUntil", la.LockedUntil)
+	}
+}
+
+// rehash upgrades the stored password hash. A failure is logged and doesn't
+// fail the authentication since the existing hash is still valid.
+func (b *Business) rehash(ctx context.Context, usr User, password string) User {
+	hash, err := b.hasher.Hash(password)
+	if err != nil {
+		b.log.Error(ctx, "rehash password", "userID", usr.ID, "err", err)
+		return usr
+	}
+
+	upd := usr
+	upd.PasswordHash = hash
+
+	upd, err = b.storer.Update(ctx, upd, usr.DateUpdated)
+	if err != nil {
+		b.log.Error(ctx, "rehash password", "userID", usr.ID, "err", err)
+		return usr
+	}
+
+	return upd
+}
+
+// hashResetToken produces the value stored for a password reset token. The
+// token is random and long enough that a fast hash is sufficient.
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// decoyHash is verified against when a user doesn't exist so the attempt
+// takes as long as it would for a real user. The hash is generated with the
+// configured hasher the first time it's needed.
+type decoyHash struct {
+	hasher hasher.Hasher
+	once   sync.Once
+	hash   []byte
+}
+
+func (d *decoyHash) verify(password string) {
+	d.once.Do(func() {
+		d.hash, _ = d.hasher.Hash("decoy-password")
+	})
+
+	d.hasher.Verify(d.hash, password)
+}
@@ -3,6 +3,7 @@ package vproductbus
 import (
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/money"
 	"github.com/google/uuid"
 )
 
@@ -11,7 +12,7 @@ import (
 type QueryFilter struct {
 	ID       *uuid.UUID
 	Name     *productbus.Name
-	Cost     *float64
+	Cost     *money.Money
 	Quantity *int
 	UserName *userbus.Name
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/money"
 	"github.com/google/uuid"
 )
 
@@ -13,7 +14,7 @@ type Product struct {
 	ID          uuid.UUID
 	UserID      uuid.UUID
 	Name        productbus.Name
-	Cost        float64
+	Cost        money.Money
 	Quantity    int
 	DateCreated time.Time
 	DateUpdated time.Time
@@ -7,18 +7,19 @@ import (
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/vproductbus"
+	"github.com/ardanlabs/service/business/sdk/money"
 	"github.com/google/uuid"
 )
 
 type product struct {
-	ID          uuid.UUID `db:"product_id"`
-	UserID      uuid.UUID `db:"user_id"`
-	Name        string    `db:"name"`
-	Cost        float64   `db:"cost"`
-	Quantity    int       `db:"quantity"`
-	DateCreated time.Time `db:"date_created"`
-	DateUpdated time.Time `db:"date_updated"`
-	UserName    string    `db:"user_name"`
+	ID          uuid.UUID   `db:"product_id"`
+	UserID      uuid.UUID   `db:"user_id"`
+	Name        string      `db:"name"`
+	Cost        money.Money `db:"cost"`
+	Quantity    int         `db:"quantity"`
+	DateCreated time.Time   `db:"date_created"`
+	DateUpdated time.Time   `db:"date_updated"`
+	UserName    string      `db:"user_name"`
 }
 
 func toBusProduct(db product) (vproductbus.Product, error) {
@@ -19,6 +19,7 @@ import (
 	"github.com/ardanlabs/service/business/domain/vproductbus/stores/vproductdb"
 	"github.com/ardanlabs/service/business/sdk/delegate"
 	"github.com/ardanlabs/service/business/sdk/migrate"
+	"github.com/ardanlabs/service/business/sdk/money"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/docker"
 	"github.com/ardanlabs/service/foundation/logger"
@@ -56,6 +57,7 @@ func newBusDomains(log *logger.Logger, db *sqlx.DB) BusDomain {
 // Database owns state for running and shutting down tests.
 type Database struct {
 	DB        *sqlx.DB
+	Config    sqldb.Config
 	Log       *logger.Logger
 	BusDomain BusDomain
 }
@@ -63,7 +65,7 @@ type Database struct {
 // NewDatabase creates a new test database inside the database that was started
 // to handle testing. The database is migrated to the current version and
 // a connection pool is provided with business domain packages.
-func NewDatabase(t *testing.T, testName string) *Database {
+func NewDatabase(t testing.TB, testName string) *Database {
 	image := "postgres:16.3"
 	name := "servicetest"
 	port := "5432"
@@ -112,13 +114,15 @@ func NewDatabase(t *testing.T, testName string) *Database {
 
 	// -------------------------------------------------------------------------
 
-	db, err := sqldb.Open(sqldb.Config{
+	cfg := sqldb.Config{
 		User:       "postgres",
 		Password:   "postgres",
 		Host:       c.HostPort,
 		Name:       dbName,
 		DisableTLS: true,
-	})
+	}
+
+	db, err := sqldb.Open(cfg)
 	if err != nil {
 		t.Fatalf("Opening database connection: %v", err)
 	}
@@ -154,6 +158,7 @@ func NewDatabase(t *testing.T, testName string) *Database {
 
 	return &Database{
 		DB:        db,
+		Config:    cfg,
 		Log:       log,
 		BusDomain: newBusDomains(log, db),
 	}
@@ -204,3 +209,11 @@ func ProductNamePointer(value string) *productbus.Name {
 	name := productbus.MustParseName(value)
 	return &name
 }
+
+// MoneyPointer is a helper to get a *Money from a string. It's in the tests
+// package because we normally don't want to deal with pointers to basic types
+// but it's useful in some tests.
+func MoneyPointer(value string) *money.Money {
+	m := money.MustParse(value)
+	return &m
+}
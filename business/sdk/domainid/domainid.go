@@ -0,0 +1,130 @@
+// Package domainid provides support for the UUID based identifiers of the
+// domain entities.
+package domainid
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"github.com/go-json-experiment/json"
+	"github.com/go-json-experiment/json/jsontext"
+	"github.com/google/uuid"
+)
+
+// ErrZero is returned when an identifier is the nil UUID, which is never
+// assigned to an entity.
+var ErrZero = errors.New("id must not be the nil uuid")
+
+// ID represents the identifier of a domain entity.
+type ID uuid.UUID
+
+// New generates a new identifier.
+func New() ID {
+	return ID(uuid.New())
+}
+
+// Parse parses the string form of an identifier. The nil UUID is rejected.
+func Parse(value string) (ID, error) {
+	u, err := uuid.Parse(value)
+	if err != nil {
+		return ID{}, fmt.Errorf("invalid id %q: %w", value, err)
+	}
+
+	if u == uuid.Nil {
+		return ID{}, ErrZero
+	}
+
+	return ID(u), nil
+}
+
+// MustParse parses the identifier and panics if it's invalid.
+func MustParse(value string) ID {
+	id, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return id
+}
+
+// UUID returns the identifier as a UUID.
+func (id ID) UUID() uuid.UUID {
+	return uuid.UUID(id)
+}
+
+// String returns the string form of the identifier.
+func (id ID) String() string {
+	return uuid.UUID(id).String()
+}
+
+// IsZero reports whether the identifier is the nil UUID.
+func (id ID) IsZero() bool {
+	return uuid.UUID(id) == uuid.Nil
+}
+
+// =============================================================================
+
+// MarshalJSONV2 implements the json.MarshalerV2 interface. The identifier
+// is written straight to the encoder as a string.
+func (id ID) MarshalJSONV2(enc *jsontext.Encoder, opts json.Options) error {
+	return enc.WriteToken(jsontext.String(id.String()))
+}
+
+// UnmarshalJSONV2 implements the json.UnmarshalerV2 interface. The token is
+// read straight from the decoder and anything other than a string holding
+// a valid, non nil UUID is rejected.
+func (id *ID) UnmarshalJSONV2(dec *jsontext.Decoder, opts json.Options) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+
+	if tok.Kind() != '"' {
+		return &json.SemanticError{JSONKind: tok.Kind(), Err: errors.New("id must be a string")}
+	}
+
+	v, err := Parse(tok.String())
+	if err != nil {
+		return &json.SemanticError{JSONKind: tok.Kind(), Err: err}
+	}
+
+	*id = v
+
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface so the
+// identifier is also a string for the standard library.
+func (id ID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (id *ID) UnmarshalText(data []byte) error {
+	v, err := Parse(string(data))
+	if err != nil {
+		return err
+	}
+
+	*id = v
+
+	return nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (id *ID) Scan(src any) error {
+	var u uuid.UUID
+	if err := u.Scan(src); err != nil {
+		return err
+	}
+
+	*id = ID(u)
+
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (id ID) Value() (driver.Value, error) {
+	return id.String(), nil
+}
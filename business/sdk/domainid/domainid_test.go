@@ -0,0 +1,82 @@
+package domainid_test
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ardanlabs/service/business/sdk/domainid"
+	"github.com/go-json-experiment/json"
+)
+
+type product struct {
+	ID     domainid.ID `json:"id"`
+	UserID domainid.ID `json:"userID"`
+}
+
+func Test_RoundTrip(t *testing.T) {
+	in := product{
+		ID:     domainid.MustParse("45b5fbd3-755f-4379-8f07-a58d4a30fa2f"),
+		UserID: domainid.New(),
+	}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("Should be able to marshal : %s", err)
+	}
+
+	exp := `{"id":"45b5fbd3-755f-4379-8f07-a58d4a30fa2f","userID":"` + in.UserID.String() + `"}`
+	if string(data) != exp {
+		t.Errorf("Should marshal the ids as strings :\ngot: %s\nexp: %s", data, exp)
+	}
+
+	var out product
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Should be able to unmarshal : %s", err)
+	}
+
+	if out != in {
+		t.Errorf("Should get the same ids back : got %v, exp %v", out, in)
+	}
+
+	var std product
+	if err := stdjson.Unmarshal(data, &std); err != nil {
+		t.Fatalf("Should be able to unmarshal with the standard library : %s", err)
+	}
+
+	if std != in {
+		t.Errorf("Should get the same ids back with the standard library : got %v, exp %v", std, in)
+	}
+}
+
+func Test_Reject(t *testing.T) {
+	table := []struct {
+		name string
+		doc  string
+		zero bool
+	}{
+		{name: "empty", doc: `{"id":""}`},
+		{name: "malformed", doc: `{"id":"45b5fbd3-755f"}`},
+		{name: "number", doc: `{"id":42}`},
+		{name: "nil", doc: `{"id":"00000000-0000-0000-0000-000000000000"}`, zero: true},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			var out product
+			err := json.Unmarshal([]byte(tt.doc), &out)
+			if err == nil {
+				t.Fatalf("Should fail to unmarshal %s", tt.doc)
+			}
+
+			var serr *json.SemanticError
+			if !errors.As(err, &serr) {
+				t.Errorf("Should get a semantic error : got %T", err)
+			}
+
+			if tt.zero && !errors.Is(err, domainid.ErrZero) {
+				t.Errorf("Should get ErrZero : got %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Package enum provides support for types that can only hold one of a fixed
+// set of values, like the roles of a user.
+package enum
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Value represents the behavior of a value of an enumeration. The string is
+// its canonical name, which is how it's marshaled and stored. The zero value
+// isn't part of the enumeration, which is why these types are usually a
+// struct with an unexported name that can't be constructed outside of the
+// package declaring it.
+type Value interface {
+	comparable
+	String() string
+}
+
+// Set represents the allowed values of an enumeration.
+type Set[E Value] struct {
+	kind   string
+	values map[string]E
+	order  []E
+}
+
+// NewSet constructs an empty set for the enumeration. The kind names the
+// enumeration in error messages.
+func NewSet[E Value](kind string) *Set[E] {
+	return &Set[E]{
+		kind:   kind,
+		values: make(map[string]E),
+	}
+}
+
+// Add adds the value to the set and returns it, so a value can be declared
+// and added at the same time. It panics if a value with the same name has
+// already been added since that's a programming error.
+func (s *Set[E]) Add(e E) E {
+	name := e.String()
+	if _, exists := s.values[name]; exists {
+		panic(fmt.Sprintf("enum: duplicate %s %q", s.kind, name))
+	}
+
+	s.values[name] = e
+	s.order = append(s.order, e)
+
+	return e
+}
+
+// Values returns the values in the order they were added.
+func (s *Set[E]) Values() []E {
+	return append([]E(nil), s.order...)
+}
+
+// Names returns the canonical names of the values in the order they were
+// added.
+func (s *Set[E]) Names() []string {
+	names := make([]string, len(s.order))
+	for i, e := range s.order {
+		names[i] = e.String()
+	}
+
+	return names
+}
+
+// Contains reports whether the value is part of the set.
+func (s *Set[E]) Contains(e E) bool {
+	v, exists := s.values[e.String()]
+	return exists && v == e
+}
+
+// Parse returns the value with the canonical name.
+func (s *Set[E]) Parse(name string) (E, error) {
+	e, exists := s.values[name]
+	if !exists {
+		var zero E
+		return zero, fmt.Errorf("invalid %s %q, must be one of %s", s.kind, name, strings.Join(s.Names(), ", "))
+	}
+
+	return e, nil
+}
+
+// MustParse returns the value with the canonical name. It panics if the name
+// isn't part of the set.
+func (s *Set[E]) MustParse(name string) E {
+	e, err := s.Parse(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return e
+}
+
+// MarshalText returns the canonical name of the value. It's meant to be
+// called from the MarshalText method of the enumeration type. The zero value
+// is marshaled as an empty string so a struct holding an unset value can
+// still be marshaled.
+func (s *Set[E]) MarshalText(e E) ([]byte, error) {
+	var zero E
+	if e == zero {
+		return []byte{}, nil
+	}
+
+	if !s.Contains(e) {
+		return nil, fmt.Errorf("invalid %s %q, must be one of %s", s.kind, e.String(), strings.Join(s.Names(), ", "))
+	}
+
+	return []byte(e.String()), nil
+}
+
+// UnmarshalText sets the value from its canonical name. It's meant to be
+// called from the UnmarshalText method of the enumeration type.
+func (s *Set[E]) UnmarshalText(data []byte, e *E) error {
+	v, err := s.Parse(string(data))
+	if err != nil {
+		return err
+	}
+
+	*e = v
+
+	return nil
+}
+
+// Scan sets the value from its canonical name read from the database. It's
+// meant to be called from the Scan method of the enumeration type.
+func (s *Set[E]) Scan(src any, e *E) error {
+	switch src := src.(type) {
+	case string:
+		return s.UnmarshalText([]byte(src), e)
+	case []byte:
+		return s.UnmarshalText(src, e)
+	default:
+		return fmt.Errorf("enum: cannot convert %T to a %s", src, s.kind)
+	}
+}
+
+// Value returns the canonical name of the value to store in the database.
+// It's meant to be called from the Value method of the enumeration type.
+// Unlike marshaling, the zero value can't be stored.
+func (s *Set[E]) Value(e E) (driver.Value, error) {
+	if !s.Contains(e) {
+		return nil, fmt.Errorf("invalid %s %q, must be one of %s", s.kind, e.String(), strings.Join(s.Names(), ", "))
+	}
+
+	return e.String(), nil
+}
@@ -0,0 +1,148 @@
+package enum_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ardanlabs/service/business/sdk/enum"
+	"github.com/google/go-cmp/cmp"
+)
+
+var statuses = enum.NewSet[status]("status")
+
+var (
+	active   = statuses.Add(status{"ACTIVE"})
+	disabled = statuses.Add(status{"DISABLED"})
+)
+
+type status struct {
+	name string
+}
+
+func (s status) String() string {
+	return s.name
+}
+
+func (s status) MarshalText() ([]byte, error) {
+	return statuses.MarshalText(s)
+}
+
+func (s *status) UnmarshalText(data []byte) error {
+	return statuses.UnmarshalText(data, s)
+}
+
+type account struct {
+	Status status `json:"status"`
+}
+
+func Test_Parse(t *testing.T) {
+	for _, name := range []string{"ACTIVE", "DISABLED"} {
+		s, err := statuses.Parse(name)
+		if err != nil {
+			t.Fatalf("Should be able to parse %q : %s", name, err)
+		}
+
+		if s.String() != name {
+			t.Errorf("Should get the %q status : got %q", name, s)
+		}
+	}
+
+	_, err := statuses.Parse("active")
+	if err == nil {
+		t.Fatalf("Should fail to parse an unknown status")
+	}
+
+	if exp := `invalid status "active", must be one of ACTIVE, DISABLED`; err.Error() != exp {
+		t.Errorf("Should get a clear message :\ngot: %s\nexp: %s", err, exp)
+	}
+
+	if diff := cmp.Diff(statuses.Names(), []string{"ACTIVE", "DISABLED"}); diff != "" {
+		t.Errorf("Should get the names in order : %s", diff)
+	}
+}
+
+func Test_JSON(t *testing.T) {
+	data, err := json.Marshal(account{Status: disabled})
+	if err != nil {
+		t.Fatalf("Should be able to marshal : %s", err)
+	}
+
+	if string(data) != `{"status":"DISABLED"}` {
+		t.Errorf("Should marshal the canonical name : %s", data)
+	}
+
+	var acc account
+	if err := json.Unmarshal(data, &acc); err != nil {
+		t.Fatalf("Should be able to unmarshal : %s", err)
+	}
+
+	if acc.Status != disabled {
+		t.Errorf("Should unmarshal the status : %s", acc.Status)
+	}
+
+	if err := json.Unmarshal([]byte(`{"status":"DELETED"}`), &acc); err == nil {
+		t.Errorf("Should fail to unmarshal an unknown status")
+	}
+
+	if err := json.Unmarshal([]byte(`{"status":""}`), &acc); err == nil {
+		t.Errorf("Should fail to unmarshal an empty status")
+	}
+
+	data, err = json.Marshal(account{})
+	if err != nil {
+		t.Fatalf("Should be able to marshal an unset status : %s", err)
+	}
+
+	if string(data) != `{"status":""}` {
+		t.Errorf("Should marshal an unset status as empty : %s", data)
+	}
+
+	if _, err := json.Marshal(account{Status: status{"DELETED"}}); err == nil {
+		t.Errorf("Should fail to marshal an unknown status")
+	}
+}
+
+func Test_Store(t *testing.T) {
+	v, err := statuses.Value(active)
+	if err != nil {
+		t.Fatalf("Should be able to get the value : %s", err)
+	}
+
+	if v != "ACTIVE" {
+		t.Errorf("Should store the canonical name : %v", v)
+	}
+
+	if _, err := statuses.Value(status{}); err == nil {
+		t.Errorf("Should fail to store an unset status")
+	}
+
+	for _, src := range []any{"DISABLED", []byte("DISABLED")} {
+		var s status
+		if err := statuses.Scan(src, &s); err != nil {
+			t.Fatalf("Should be able to scan a %T : %s", src, err)
+		}
+
+		if s != disabled {
+			t.Errorf("Should scan the status from a %T : %s", src, s)
+		}
+	}
+
+	var s status
+	if err := statuses.Scan("DELETED", &s); err == nil {
+		t.Errorf("Should fail to scan an unknown status")
+	}
+
+	if err := statuses.Scan(nil, &s); err == nil {
+		t.Errorf("Should fail to scan a null status")
+	}
+}
+
+func Test_Add(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Should panic when adding a duplicate status")
+		}
+	}()
+
+	statuses.Add(status{"ACTIVE"})
+}
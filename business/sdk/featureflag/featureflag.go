@@ -0,0 +1,154 @@
+// Package featureflag provides support for gradually rolling out features by
+// evaluating flags against the user and tenant of a request.
+package featureflag
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"slices"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Definition represents the rules for enabling a flag. A flag is enabled when
+// any of the rules match. Percentage enables the flag for that percentage of
+// users, chosen by a stable hash of the user so each user keeps the same
+// result as the percentage grows.
+type Definition struct {
+	Enabled    bool     `json:"enabled"`
+	Percentage int      `json:"percentage"`
+	Users      []string `json:"users"`
+	Tenants    []string `json:"tenants"`
+}
+
+// Provider represents behavior for looking up flag definitions. It can be
+// implemented with static configuration or a remote flag service.
+type Provider interface {
+	Lookup(ctx context.Context, key string) (Definition, bool, error)
+}
+
+// Attributes represents the values a flag is evaluated against.
+type Attributes struct {
+	UserID   string
+	TenantID string
+}
+
+// Flags evaluates feature flags using the definitions from a provider.
+type Flags struct {
+	provider Provider
+	userID   func(ctx context.Context) string
+	tenantID func(ctx context.Context) string
+}
+
+// New constructs flags that are evaluated with the definitions from the
+// provider.
+func New(provider Provider, options ...func(f *Flags)) *Flags {
+	f := Flags{
+		provider: provider,
+		userID:   func(context.Context) string { return "" },
+		tenantID: func(context.Context) string { return "" },
+	}
+
+	for _, option := range options {
+		option(&f)
+	}
+
+	return &f
+}
+
+// WithUserID sets the function used to find the user a flag is evaluated
+// for. Without it, flags are only evaluated against the tenant.
+func WithUserID(fn func(ctx context.Context) string) func(f *Flags) {
+	return func(f *Flags) {
+		f.userID = fn
+	}
+}
+
+// WithTenantID sets the function used to find the tenant a flag is evaluated
+// for. The tenant must come from an authenticated source, since any client
+// could otherwise enable the flags of another tenant. Without it, flags are
+// only evaluated against the user.
+func WithTenantID(fn func(ctx context.Context) string) func(f *Flags) {
+	return func(f *Flags) {
+		f.tenantID = fn
+	}
+}
+
+// Flag reports whether the flag is enabled for the user and tenant in the
+// context. Unknown flags and
+// flags that can't be looked up are disabled. Every evaluation is recorded
+// as an event on the current span.
+func (f *Flags) Flag(ctx context.Context, key string) bool {
+	if f == nil {
+		return false
+	}
+
+	attrs := Attributes{
+		UserID:   f.userID(ctx),
+		TenantID: f.tenantID(ctx),
+	}
+
+	enabled, reason := f.evaluate(ctx, key, attrs)
+
+	trace.SpanFromContext(ctx).AddEvent("feature_flag.evaluation", trace.WithAttributes(
+		attribute.String("feature_flag.key", key),
+		attribute.Bool("feature_flag.enabled", enabled),
+		attribute.String("feature_flag.reason", reason),
+	))
+
+	return enabled
+}
+
+func (f *Flags) evaluate(ctx context.Context, key string, attrs Attributes) (bool, string) {
+	def, exists, err := f.provider.Lookup(ctx, key)
+	switch {
+	case err != nil:
+		return false, "error"
+	case !exists:
+		return false, "unknown"
+	}
+
+	switch {
+	case def.Enabled:
+		return true, "enabled"
+
+	case attrs.UserID != "" && slices.Contains(def.Users, attrs.UserID):
+		return true, "user"
+
+	case attrs.TenantID != "" && slices.Contains(def.Tenants, attrs.TenantID):
+		return true, "tenant"
+
+	case attrs.UserID != "" && bucket(key, attrs.UserID) < def.Percentage:
+		return true, "percentage"
+	}
+
+	return false, "default"
+}
+
+// bucket places the user in one of 100 buckets. The key is part of the hash
+// so the same users aren't always the first to get every feature.
+func bucket(key string, userID string) int {
+	sum := sha256.Sum256([]byte(key + ":" + userID))
+	return int(binary.BigEndian.Uint64(sum[:8]) % 100)
+}
+
+// =============================================================================
+
+type ctxKey int
+
+const flagsKey ctxKey = 1
+
+// Set returns a context with the flags so they can be evaluated by any code
+// handling the request.
+func Set(ctx context.Context, f *Flags) context.Context {
+	return context.WithValue(ctx, flagsKey, f)
+}
+
+// Flag reports whether the flag is enabled using the flags in the context.
+// The flag is disabled when the context has no flags.
+func Flag(ctx context.Context, key string) bool {
+	f, _ := ctx.Value(flagsKey).(*Flags)
+	return f.Flag(ctx, key)
+}
@@ -0,0 +1,190 @@
+package featureflag_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ardanlabs/service/business/sdk/featureflag"
+	"github.com/ardanlabs/service/foundation/tracer"
+	"github.com/google/uuid"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type userKey struct{}
+
+func userID(ctx context.Context) string {
+	id, _ := ctx.Value(userKey{}).(string)
+	return id
+}
+
+func Test_Percentage(t *testing.T) {
+	t.Parallel()
+
+	flags := featureflag.New(featureflag.Static{
+		"new-checkout": {Percentage: 30},
+		"everyone":     {Percentage: 100},
+		"nobody":       {Percentage: 0},
+	}, featureflag.WithUserID(userID))
+
+	const users = 10_000
+
+	counts := make(map[string]int)
+	for range users {
+		ctx := context.WithValue(context.Background(), userKey{}, uuid.NewString())
+
+		for _, key := range []string{"new-checkout", "everyone", "nobody"} {
+			enabled := flags.Flag(ctx, key)
+			if enabled {
+				counts[key]++
+			}
+
+			if flags.Flag(ctx, key) != enabled {
+				t.Fatalf("Should get the same result for the same user : %s", key)
+			}
+		}
+	}
+
+	if n := counts["new-checkout"]; n < users*27/100 || n > users*33/100 {
+		t.Errorf("Should enable the flag for about 30%% of users, got %d of %d", n, users)
+	}
+
+	if n := counts["everyone"]; n != users {
+		t.Errorf("Should enable the flag for every user, got %d of %d", n, users)
+	}
+
+	if n := counts["nobody"]; n != 0 {
+		t.Errorf("Should not enable the flag for any user, got %d of %d", n, users)
+	}
+
+	// Requests without a user aren't part of a percentage rollout.
+	if flags.Flag(context.Background(), "everyone") {
+		t.Errorf("Should not enable a percentage flag without a user")
+	}
+}
+
+func Test_Tenant(t *testing.T) {
+	t.Parallel()
+
+	provider, err := featureflag.ParseStatic(strings.NewReader(`{"reports": {"tenants": ["acme"]}}`))
+	if err != nil {
+		t.Fatalf("Should be able to parse the flags : %s", err)
+	}
+
+	type tenantKey struct{}
+
+	tenantID := func(ctx context.Context) string {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return tenant
+	}
+
+	flags := featureflag.New(provider, featureflag.WithTenantID(tenantID))
+
+	table := []struct {
+		name   string
+		tenant string
+		key    string
+		exp    bool
+	}{
+		{name: "tenant", tenant: "acme", key: "reports", exp: true},
+		{name: "other-tenant", tenant: "globex", key: "reports", exp: false},
+		{name: "no-tenant", tenant: "", key: "reports", exp: false},
+		{name: "unknown-flag", tenant: "acme", key: "billing", exp: false},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), tenantKey{}, tt.tenant)
+
+			// A tenant sent by the client in the baggage is never trusted.
+			ctx, err := tracer.SetBaggage(ctx, tracer.BaggageTenantID, "acme")
+			if err != nil {
+				t.Fatalf("Should be able to set the baggage : %s", err)
+			}
+
+			ctx = featureflag.Set(ctx, flags)
+
+			if got := featureflag.Flag(ctx, tt.key); got != tt.exp {
+				t.Errorf("Should evaluate the flag for the tenant, got %t, exp %t", got, tt.exp)
+			}
+		})
+	}
+
+	// Code that runs without flags in the context sees every flag disabled.
+	if featureflag.Flag(context.Background(), "reports") {
+		t.Errorf("Should not enable a flag without flags in the context")
+	}
+}
+
+func Test_ParseStatic(t *testing.T) {
+	t.Parallel()
+
+	table := map[string]string{
+		"invalid-json": `{"reports": `,
+		"percentage":   `{"reports": {"percentage": 101}}`,
+	}
+
+	for name, data := range table {
+		t.Run(name, func(t *testing.T) {
+			if _, err := featureflag.ParseStatic(strings.NewReader(data)); err == nil {
+				t.Errorf("Should not be able to parse the flags")
+			}
+		})
+	}
+}
+
+// recorder keeps the spans that ended so their events can be checked.
+type recorder struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (r *recorder) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+func (r *recorder) Shutdown(context.Context) error                  { return nil }
+func (r *recorder) ForceFlush(context.Context) error                { return nil }
+
+func (r *recorder) OnEnd(s sdktrace.ReadOnlySpan) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, s)
+}
+
+func Test_Trace(t *testing.T) {
+	t.Parallel()
+
+	var rec recorder
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(&rec))
+
+	flags := featureflag.New(featureflag.Static{"reports": {Enabled: true}})
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+	flags.Flag(ctx, "reports")
+	span.End()
+
+	if len(rec.spans) != 1 {
+		t.Fatalf("Should record the span : %d", len(rec.spans))
+	}
+
+	events := rec.spans[0].Events()
+	if len(events) != 1 || events[0].Name != "feature_flag.evaluation" {
+		t.Fatalf("Should record the evaluation as an event : %+v", events)
+	}
+
+	got := make(map[string]string)
+	for _, kv := range events[0].Attributes {
+		got[string(kv.Key)] = kv.Value.Emit()
+	}
+
+	exp := map[string]string{
+		"feature_flag.key":     "reports",
+		"feature_flag.enabled": "true",
+		"feature_flag.reason":  "enabled",
+	}
+
+	for k, v := range exp {
+		if got[k] != v {
+			t.Errorf("Should record the %s attribute, got %q, exp %q", k, got[k], v)
+		}
+	}
+}
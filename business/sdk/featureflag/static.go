@@ -0,0 +1,35 @@
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Static is a provider backed by a fixed set of definitions, keyed by the
+// name of the flag.
+type Static map[string]Definition
+
+// ParseStatic reads a JSON object of flag definitions, keyed by the name of
+// the flag.
+func ParseStatic(r io.Reader) (Static, error) {
+	var s Static
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	for key, def := range s {
+		if def.Percentage < 0 || def.Percentage > 100 {
+			return nil, fmt.Errorf("flag %q: percentage %d must be between 0 and 100", key, def.Percentage)
+		}
+	}
+
+	return s, nil
+}
+
+// Lookup implements the Provider interface.
+func (s Static) Lookup(ctx context.Context, key string) (Definition, bool, error) {
+	def, exists := s[key]
+	return def, exists, nil
+}
@@ -0,0 +1,162 @@
+// Package filter provides support for describing filters on data using
+// expressions in the form of "field:op:value" ie "name:like:bill".
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of operators that can be used in a filter expression.
+const (
+	EQ   = "eq"
+	NE   = "ne"
+	GT   = "gt"
+	LT   = "lt"
+	Like = "like"
+	In   = "in"
+)
+
+var operators = map[string]string{
+	EQ:   "=",
+	NE:   "<>",
+	GT:   ">",
+	LT:   "<",
+	Like: "LIKE",
+	In:   "IN",
+}
+
+// Type represents the type of value a field holds.
+type Type int
+
+// Set of types a field can hold.
+const (
+	String Type = iota
+	Int
+	Float
+	Bool
+	Time
+	UUID
+)
+
+// Field describes a field that can be filtered on. Ops restricts the set of
+// operators that can be used with the field. When Ops is empty, every
+// operator that makes sense for the type is allowed.
+type Field struct {
+	Name string
+	Type Type
+	Ops  []string
+}
+
+func (f Field) allows(op string) bool {
+	if len(f.Ops) > 0 {
+		for _, o := range f.Ops {
+			if o == op {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch op {
+	case Like:
+		return f.Type == String
+	case GT, LT:
+		return f.Type != Bool && f.Type != UUID
+	}
+
+	return true
+}
+
+// Condition represents a single validated filter expression. Values holds
+// one value for every operator except In, which can hold several.
+type Condition struct {
+	Field  string
+	Op     string
+	Values []any
+}
+
+// Filter represents the set of conditions that must all be met.
+type Filter struct {
+	Conditions []Condition
+}
+
+// Parse constructs a Filter by parsing a set of expressions in the form of
+// "field:op:value". The fieldMappings key is the field name used in the
+// expression. The value for the In operator is a comma separated list.
+func Parse(fieldMappings map[string]Field, exprs []string) (Filter, error) {
+	var f Filter
+
+	for _, expr := range exprs {
+		parts := strings.SplitN(expr, ":", 3)
+		if len(parts) != 3 {
+			return Filter{}, fmt.Errorf("invalid filter: %s", expr)
+		}
+
+		orgFieldName := strings.TrimSpace(parts[0])
+		field, exists := fieldMappings[orgFieldName]
+		if !exists {
+			return Filter{}, fmt.Errorf("unknown filter field: %s", orgFieldName)
+		}
+
+		op := strings.TrimSpace(parts[1])
+		if _, exists := operators[op]; !exists {
+			return Filter{}, fmt.Errorf("unknown filter operator: %s", op)
+		}
+
+		if !field.allows(op) {
+			return Filter{}, fmt.Errorf("operator %q not allowed for field %s", op, orgFieldName)
+		}
+
+		raw := []string{parts[2]}
+		if op == In {
+			raw = strings.Split(parts[2], ",")
+		}
+
+		values := make([]any, len(raw))
+		for i, r := range raw {
+			v, err := parseValue(field.Type, r)
+			if err != nil {
+				return Filter{}, fmt.Errorf("invalid value for field %s: %w", orgFieldName, err)
+			}
+			values[i] = v
+		}
+
+		f.Conditions = append(f.Conditions, Condition{
+			Field:  field.Name,
+			Op:     op,
+			Values: values,
+		})
+	}
+
+	return f, nil
+}
+
+func parseValue(typ Type, value string) (any, error) {
+	switch typ {
+	case Int:
+		return strconv.ParseInt(value, 10, 64)
+
+	case Float:
+		return strconv.ParseFloat(value, 64)
+
+	case Bool:
+		return strconv.ParseBool(value)
+
+	case Time:
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, err
+		}
+		return t.UTC(), nil
+
+	case UUID:
+		return uuid.Parse(value)
+	}
+
+	return value, nil
+}
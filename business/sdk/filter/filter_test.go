@@ -0,0 +1,202 @@
+package filter_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/filter"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+var fields = map[string]filter.Field{
+	"user_id": {Name: "ID", Type: filter.UUID},
+	"name":    {Name: "Name", Type: filter.String},
+	"age":     {Name: "Age", Type: filter.Int},
+	"enabled": {Name: "Enabled", Type: filter.Bool},
+	"created": {Name: "Created", Type: filter.Time},
+	"role":    {Name: "Role", Type: filter.String, Ops: []string{filter.EQ, filter.In}},
+}
+
+var columns = map[string]string{
+	"ID":      "user_id",
+	"Name":    "name",
+	"Age":     "age",
+	"Enabled": "enabled",
+	"Created": "date_created",
+	"Role":    "role",
+}
+
+func Test_Parse(t *testing.T) {
+	id := uuid.New()
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	table := []struct {
+		name  string
+		exprs []string
+		where string
+		data  map[string]any
+	}{
+		{
+			name:  "eq",
+			exprs: []string{"user_id:eq:" + id.String()},
+			where: "user_id = :filter_0",
+			data:  map[string]any{"filter_0": id},
+		},
+		{
+			name:  "ne-gt-lt",
+			exprs: []string{"enabled:ne:false", "age:gt:18", "age:lt:65"},
+			where: "enabled <> :filter_0 AND age > :filter_1 AND age < :filter_2",
+			data:  map[string]any{"filter_0": false, "filter_1": int64(18), "filter_2": int64(65)},
+		},
+		{
+			name:  "like-time",
+			exprs: []string{"name:like:bill", "created:gt:2024-01-02T03:04:05Z"},
+			where: `name LIKE :filter_0 ESCAPE '\' AND date_created > :filter_1`,
+			data:  map[string]any{"filter_0": "%bill%", "filter_1": created},
+		},
+		{
+			name:  "like-escaped",
+			exprs: []string{`name:like:50%_off\`},
+			where: `name LIKE :filter_0 ESCAPE '\'`,
+			data:  map[string]any{"filter_0": `%50\%\_off\\%`},
+		},
+		{
+			name:  "in",
+			exprs: []string{"role:in:ADMIN,USER"},
+			where: "role IN (:filter_0_0, :filter_0_1)",
+			data:  map[string]any{"filter_0_0": "ADMIN", "filter_0_1": "USER"},
+		},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			flt, err := filter.Parse(fields, tt.exprs)
+			if err != nil {
+				t.Fatalf("Should be able to parse the filter : %s", err)
+			}
+
+			data := make(map[string]any)
+			where, err := filter.WhereClause(flt, columns, data)
+			if err != nil {
+				t.Fatalf("Should be able to build the where clause : %s", err)
+			}
+
+			if where != tt.where {
+				t.Errorf("Should get the expected where clause")
+				t.Errorf("GOT: %s", where)
+				t.Errorf("EXP: %s", tt.where)
+			}
+
+			if diff := cmp.Diff(data, tt.data); diff != "" {
+				t.Errorf("Should get the expected data, diff:\n%s", diff)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
+
+func Test_ParseInvalid(t *testing.T) {
+	table := []struct {
+		name string
+		expr string
+	}{
+		{name: "format", expr: "name:bill"},
+		{name: "field", expr: "password:eq:secret"},
+		{name: "op", expr: "name:regex:bill"},
+		{name: "op-type", expr: "enabled:like:true"},
+		{name: "op-restricted", expr: "role:ne:ADMIN"},
+		{name: "int", expr: "age:eq:18 OR 1=1"},
+		{name: "uuid", expr: "user_id:eq:1' OR '1'='1"},
+		{name: "time", expr: "created:gt:yesterday"},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			if _, err := filter.Parse(fields, []string{tt.expr}); err == nil {
+				t.Fatalf("Should not be able to parse the filter %q", tt.expr)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
+
+func Test_Injection(t *testing.T) {
+	inputs := []string{
+		"bill'; DROP TABLE users; --",
+		"x' OR '1'='1",
+		"a:b:c) OR (1=1",
+	}
+
+	for i, input := range inputs {
+		flt, err := filter.Parse(fields, []string{"name:eq:" + input, "role:in:" + input})
+		if err != nil {
+			t.Fatalf("Should be able to parse input %d : %s", i, err)
+		}
+
+		data := make(map[string]any)
+		where, err := filter.WhereClause(flt, columns, data)
+		if err != nil {
+			t.Fatalf("Should be able to build the where clause for input %d : %s", i, err)
+		}
+
+		if strings.ContainsAny(where, "';") || strings.Contains(where, "DROP") || strings.Contains(where, "1=1") {
+			t.Errorf("Should not place input %d in the where clause : %s", i, where)
+		}
+
+		if data["filter_0"] != input {
+			t.Errorf("Should bind input %d as a parameter : got %v", i, data["filter_0"])
+		}
+	}
+}
+
+func Test_Match(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	values := map[string]any{
+		"ID":      uuid.New(),
+		"Name":    "Bill Kennedy",
+		"Age":     int64(40),
+		"Enabled": true,
+		"Created": created,
+		"Role":    "ADMIN",
+	}
+
+	table := []struct {
+		name  string
+		exprs []string
+		exp   bool
+	}{
+		{name: "eq", exprs: []string{"name:eq:Bill Kennedy"}, exp: true},
+		{name: "ne", exprs: []string{"enabled:ne:true"}, exp: false},
+		{name: "gt-lt", exprs: []string{"age:gt:18", "age:lt:65"}, exp: true},
+		{name: "time", exprs: []string{"created:lt:2024-01-02T03:04:05Z"}, exp: false},
+		{name: "like", exprs: []string{"name:like:Kenn"}, exp: true},
+		{name: "like-literal", exprs: []string{"name:like:B%"}, exp: false},
+		{name: "in", exprs: []string{"role:in:USER,ADMIN"}, exp: true},
+		{name: "in-miss", exprs: []string{"role:in:USER"}, exp: false},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			flt, err := filter.Parse(fields, tt.exprs)
+			if err != nil {
+				t.Fatalf("Should be able to parse the filter : %s", err)
+			}
+
+			got, err := filter.Match(flt, values)
+			if err != nil {
+				t.Fatalf("Should be able to match the filter : %s", err)
+			}
+
+			if got != tt.exp {
+				t.Errorf("Should get the expected match : got[%v] exp[%v]", got, tt.exp)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
@@ -0,0 +1,122 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Match reports whether the values meet every condition of the filter, the
+// same way WhereClause would select them in the database. The values map
+// the field names to the values held by the data being filtered.
+func Match(f Filter, values map[string]any) (bool, error) {
+	for _, c := range f.Conditions {
+		value, exists := values[c.Field]
+		if !exists {
+			return false, fmt.Errorf("field %q does not exist", c.Field)
+		}
+
+		if len(c.Values) == 0 {
+			return false, fmt.Errorf("no value for field %q", c.Field)
+		}
+
+		ok, err := matchCondition(c, value)
+		if err != nil {
+			return false, fmt.Errorf("field %q: %w", c.Field, err)
+		}
+
+		if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func matchCondition(c Condition, value any) (bool, error) {
+	switch c.Op {
+	case In:
+		for _, v := range c.Values {
+			n, err := compare(value, v)
+			if err != nil {
+				return false, err
+			}
+			if n == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+
+	case Like:
+		return strings.Contains(fmt.Sprint(value), fmt.Sprint(c.Values[0])), nil
+	}
+
+	n, err := compare(value, c.Values[0])
+	if err != nil {
+		return false, err
+	}
+
+	switch c.Op {
+	case EQ:
+		return n == 0, nil
+	case NE:
+		return n != 0, nil
+	case GT:
+		return n > 0, nil
+	case LT:
+		return n < 0, nil
+	}
+
+	return false, fmt.Errorf("unknown operator: %s", c.Op)
+}
+
+// compare returns -1, 0 or 1 when a is less than, equal to or greater than
+// b. Both values must be of the same type.
+func compare(a any, b any) (int, error) {
+	switch a := a.(type) {
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), nil
+		}
+
+	case int64:
+		if b, ok := b.(int64); ok {
+			return order(a < b, a > b), nil
+		}
+
+	case float64:
+		if b, ok := b.(float64); ok {
+			return order(a < b, a > b), nil
+		}
+
+	case bool:
+		if b, ok := b.(bool); ok {
+			return order(!a && b, a && !b), nil
+		}
+
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b), nil
+		}
+
+	case uuid.UUID:
+		if b, ok := b.(uuid.UUID); ok {
+			return strings.Compare(a.String(), b.String()), nil
+		}
+	}
+
+	return 0, fmt.Errorf("can't compare %T with %T", a, b)
+}
+
+func order(less bool, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+
+	return 0
+}
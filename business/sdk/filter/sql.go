@@ -0,0 +1,63 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WhereClause translates the filter into a set of conditions joined by AND
+// that can be used in a WHERE clause. The columns map the field names to the
+// database columns. Values are never placed in the clause, they are added to
+// the data map and referenced as named parameters.
+func WhereClause(f Filter, columns map[string]string, data map[string]any) (string, error) {
+	wc := make([]string, 0, len(f.Conditions))
+
+	for i, c := range f.Conditions {
+		column, exists := columns[c.Field]
+		if !exists {
+			return "", fmt.Errorf("field %q does not exist", c.Field)
+		}
+
+		operator, exists := operators[c.Op]
+		if !exists {
+			return "", fmt.Errorf("unknown operator: %s", c.Op)
+		}
+
+		if len(c.Values) == 0 {
+			return "", fmt.Errorf("no value for field %q", c.Field)
+		}
+
+		switch c.Op {
+		case In:
+			names := make([]string, len(c.Values))
+			for j, v := range c.Values {
+				name := fmt.Sprintf("filter_%d_%d", i, j)
+				data[name] = v
+				names[j] = ":" + name
+			}
+			wc = append(wc, fmt.Sprintf("%s IN (%s)", column, strings.Join(names, ", ")))
+
+		case Like:
+			name := fmt.Sprintf("filter_%d", i)
+			data[name] = Contains(fmt.Sprint(c.Values[0]))
+			wc = append(wc, fmt.Sprintf("%s LIKE :%s ESCAPE '\\'", column, name))
+
+		default:
+			name := fmt.Sprintf("filter_%d", i)
+			data[name] = c.Values[0]
+			wc = append(wc, fmt.Sprintf("%s %s :%s", column, operator, name))
+		}
+	}
+
+	return strings.Join(wc, " AND "), nil
+}
+
+// likeEscaper escapes the characters LIKE treats as special so user input
+// is matched literally. It is used with ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Contains returns a LIKE pattern that matches values containing the
+// specified value. The pattern must be used with ESCAPE '\'.
+func Contains(value string) string {
+	return "%" + likeEscaper.Replace(value) + "%"
+}
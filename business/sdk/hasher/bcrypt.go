@@ -0,0 +1,49 @@
+package hasher
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Bcrypt hashes passwords using bcrypt.
+type Bcrypt struct {
+	cost int
+}
+
+// NewBcrypt constructs a bcrypt hasher with the specified cost. A cost
+// outside of the range bcrypt supports is replaced with the default.
+func NewBcrypt(cost int) *Bcrypt {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = bcrypt.DefaultCost
+	}
+
+	return &Bcrypt{
+		cost: cost,
+	}
+}
+
+// Hash implements the Hasher interface.
+func (b *Bcrypt) Hash(password string) ([]byte, error) {
+	return bcrypt.GenerateFromPassword([]byte(password), b.cost)
+}
+
+// Verify implements the Hasher interface.
+func (b *Bcrypt) Verify(hash []byte, password string) error {
+	err := bcrypt.CompareHashAndPassword(hash, []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrMismatch
+	}
+
+	return err
+}
+
+// NeedsRehash implements the Hasher interface.
+func (b *Bcrypt) NeedsRehash(hash []byte) bool {
+	cost, err := bcrypt.Cost(hash)
+	if err != nil {
+		return true
+	}
+
+	return cost != b.cost
+}
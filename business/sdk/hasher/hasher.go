@@ -0,0 +1,116 @@
+// Package hasher provides support for hashing and verifying passwords with
+// a configurable algorithm. The algorithm and its parameters are encoded
+// into the hash so old hashes can be verified and upgraded after the
+// configuration changes.
+package hasher
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Set of supported algorithms.
+const (
+	AlgBcrypt = "bcrypt"
+	AlgPBKDF2 = "pbkdf2-sha256"
+)
+
+// Set of error variables for hashing and verifying passwords.
+var (
+	ErrMismatch         = errors.New("password does not match")
+	ErrUnknownAlgorithm = errors.New("unknown hash algorithm")
+)
+
+// Hasher declares the behavior required to hash and verify passwords.
+type Hasher interface {
+	Hash(password string) ([]byte, error)
+	Verify(hash []byte, password string) error
+	NeedsRehash(hash []byte) bool
+}
+
+// Config represents the settings for hashing new passwords.
+type Config struct {
+	Algorithm        string
+	BcryptCost       int
+	PBKDF2Iterations int
+}
+
+// New constructs a hasher that hashes new passwords with the configured
+// algorithm and verifies passwords hashed by any supported algorithm.
+// Hashes created by a different algorithm or with outdated parameters are
+// reported as needing a rehash.
+func New(cfg Config) (Hasher, error) {
+	algs := map[string]Hasher{
+		AlgBcrypt: NewBcrypt(cfg.BcryptCost),
+		AlgPBKDF2: NewPBKDF2(cfg.PBKDF2Iterations),
+	}
+
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = AlgBcrypt
+	}
+
+	current, exists := algs[cfg.Algorithm]
+	if !exists {
+		return nil, fmt.Errorf("algorithm %q: %w", cfg.Algorithm, ErrUnknownAlgorithm)
+	}
+
+	h := set{
+		name:    cfg.Algorithm,
+		current: current,
+		algs:    algs,
+	}
+
+	return &h, nil
+}
+
+// Default returns a hasher using bcrypt with the default cost.
+func Default() Hasher {
+	h, _ := New(Config{})
+	return h
+}
+
+// Algorithm returns the name of the algorithm used to create the hash.
+func Algorithm(hash []byte) (string, error) {
+	switch {
+	case strings.HasPrefix(string(hash), "$2"):
+		return AlgBcrypt, nil
+	case strings.HasPrefix(string(hash), "$"+AlgPBKDF2+"$"):
+		return AlgPBKDF2, nil
+	}
+
+	return "", ErrUnknownAlgorithm
+}
+
+// =============================================================================
+
+type set struct {
+	name    string
+	current Hasher
+	algs    map[string]Hasher
+}
+
+// Hash implements the Hasher interface.
+func (s *set) Hash(password string) ([]byte, error) {
+	return s.current.Hash(password)
+}
+
+// Verify implements the Hasher interface.
+func (s *set) Verify(hash []byte, password string) error {
+	name, err := Algorithm(hash)
+	if err != nil {
+		return err
+	}
+
+	return s.algs[name].Verify(hash, password)
+}
+
+// NeedsRehash implements the Hasher interface.
+func (s *set) NeedsRehash(hash []byte) bool {
+	name, err := Algorithm(hash)
+	if err != nil || name != s.name {
+		return true
+	}
+
+	return s.current.NeedsRehash(hash)
+}
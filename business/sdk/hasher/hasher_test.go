@@ -0,0 +1,152 @@
+package hasher_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ardanlabs/service/business/sdk/hasher"
+)
+
+func Test_HashVerify(t *testing.T) {
+	type table struct {
+		name string
+		cfg  hasher.Config
+		alg  string
+	}
+
+	tt := []table{
+		{
+			name: "default",
+			cfg:  hasher.Config{},
+			alg:  hasher.AlgBcrypt,
+		},
+		{
+			name: "bcrypt",
+			cfg:  hasher.Config{Algorithm: hasher.AlgBcrypt, BcryptCost: 5},
+			alg:  hasher.AlgBcrypt,
+		},
+		{
+			name: "pbkdf2",
+			cfg:  hasher.Config{Algorithm: hasher.AlgPBKDF2, PBKDF2Iterations: 1000},
+			alg:  hasher.AlgPBKDF2,
+		},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			h, err := hasher.New(tst.cfg)
+			if err != nil {
+				t.Fatalf("Should be able to construct the hasher : %s", err)
+			}
+
+			hash, err := h.Hash("gophers")
+			if err != nil {
+				t.Fatalf("Should be able to hash the password : %s", err)
+			}
+
+			alg, err := hasher.Algorithm(hash)
+			if err != nil {
+				t.Fatalf("Should be able to identify the algorithm : %s", err)
+			}
+
+			if alg != tst.alg {
+				t.Errorf("Should get the expected algorithm, got %q, exp %q", alg, tst.alg)
+			}
+
+			if err := h.Verify(hash, "gophers"); err != nil {
+				t.Errorf("Should be able to verify the password : %s", err)
+			}
+
+			if err := h.Verify(hash, "gopher"); !errors.Is(err, hasher.ErrMismatch) {
+				t.Errorf("Should get a mismatch for the wrong password : %v", err)
+			}
+
+			if h.NeedsRehash(hash) {
+				t.Errorf("Should not need a rehash for a current hash")
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_NeedsRehash(t *testing.T) {
+	type table struct {
+		name   string
+		old    hasher.Config
+		new    hasher.Config
+		rehash bool
+	}
+
+	tt := []table{
+		{
+			name:   "same",
+			old:    hasher.Config{Algorithm: hasher.AlgBcrypt, BcryptCost: 5},
+			new:    hasher.Config{Algorithm: hasher.AlgBcrypt, BcryptCost: 5},
+			rehash: false,
+		},
+		{
+			name:   "bcrypt-cost",
+			old:    hasher.Config{Algorithm: hasher.AlgBcrypt, BcryptCost: 4},
+			new:    hasher.Config{Algorithm: hasher.AlgBcrypt, BcryptCost: 5},
+			rehash: true,
+		},
+		{
+			name:   "pbkdf2-iterations",
+			old:    hasher.Config{Algorithm: hasher.AlgPBKDF2, PBKDF2Iterations: 1000},
+			new:    hasher.Config{Algorithm: hasher.AlgPBKDF2, PBKDF2Iterations: 2000},
+			rehash: true,
+		},
+		{
+			name:   "algorithm",
+			old:    hasher.Config{Algorithm: hasher.AlgBcrypt, BcryptCost: 4},
+			new:    hasher.Config{Algorithm: hasher.AlgPBKDF2, PBKDF2Iterations: 1000},
+			rehash: true,
+		},
+	}
+
+	for _, tst := range tt {
+		f := func(t *testing.T) {
+			oldH, err := hasher.New(tst.old)
+			if err != nil {
+				t.Fatalf("Should be able to construct the old hasher : %s", err)
+			}
+
+			newH, err := hasher.New(tst.new)
+			if err != nil {
+				t.Fatalf("Should be able to construct the new hasher : %s", err)
+			}
+
+			hash, err := oldH.Hash("gophers")
+			if err != nil {
+				t.Fatalf("Should be able to hash the password : %s", err)
+			}
+
+			if err := newH.Verify(hash, "gophers"); err != nil {
+				t.Errorf("Should be able to verify an old hash : %s", err)
+			}
+
+			if got := newH.NeedsRehash(hash); got != tst.rehash {
+				t.Errorf("Should get the expected rehash, got %t, exp %t", got, tst.rehash)
+			}
+		}
+
+		t.Run(tst.name, f)
+	}
+}
+
+func Test_Errors(t *testing.T) {
+	if _, err := hasher.New(hasher.Config{Algorithm: "md5"}); !errors.Is(err, hasher.ErrUnknownAlgorithm) {
+		t.Errorf("Should get an unknown algorithm error : %v", err)
+	}
+
+	h := hasher.Default()
+
+	if err := h.Verify([]byte("plain"), "plain"); !errors.Is(err, hasher.ErrUnknownAlgorithm) {
+		t.Errorf("Should get an unknown algorithm error for an unknown hash : %v", err)
+	}
+
+	if err := h.Verify([]byte("$pbkdf2-sha256$i=x$salt$key"), "gophers"); err == nil {
+		t.Errorf("Should not be able to verify a malformed hash")
+	}
+}
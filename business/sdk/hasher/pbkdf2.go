@@ -0,0 +1,105 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	pbkdf2DefaultIterations = 600_000
+	pbkdf2SaltLen           = 16
+	pbkdf2KeyLen            = 32
+)
+
+// PBKDF2 hashes passwords using PBKDF2 with SHA-256. Hashes are encoded as
+// $pbkdf2-sha256$i=<iterations>$<salt>$<key>.
+type PBKDF2 struct {
+	iterations int
+}
+
+// NewPBKDF2 constructs a PBKDF2 hasher with the specified number of
+// iterations. A value less than one is replaced with the default.
+func NewPBKDF2(iterations int) *PBKDF2 {
+	if iterations < 1 {
+		iterations = pbkdf2DefaultIterations
+	}
+
+	return &PBKDF2{
+		iterations: iterations,
+	}
+}
+
+// Hash implements the Hasher interface.
+func (p *PBKDF2) Hash(password string) ([]byte, error) {
+	salt := make([]byte, pbkdf2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("salt: %w", err)
+	}
+
+	key := pbkdf2.Key([]byte(password), salt, p.iterations, pbkdf2KeyLen, sha256.New)
+
+	hash := fmt.Sprintf("$%s$i=%d$%s$%s",
+		AlgPBKDF2,
+		p.iterations,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	)
+
+	return []byte(hash), nil
+}
+
+// Verify implements the Hasher interface.
+func (p *PBKDF2) Verify(hash []byte, password string) error {
+	iterations, salt, key, err := parsePBKDF2(hash)
+	if err != nil {
+		return err
+	}
+
+	got := pbkdf2.Key([]byte(password), salt, iterations, len(key), sha256.New)
+	if subtle.ConstantTimeCompare(got, key) != 1 {
+		return ErrMismatch
+	}
+
+	return nil
+}
+
+// NeedsRehash implements the Hasher interface.
+func (p *PBKDF2) NeedsRehash(hash []byte) bool {
+	iterations, _, _, err := parsePBKDF2(hash)
+	if err != nil {
+		return true
+	}
+
+	return iterations != p.iterations
+}
+
+func parsePBKDF2(hash []byte) (int, []byte, []byte, error) {
+	parts := strings.Split(string(hash), "$")
+	if len(parts) != 5 || parts[0] != "" || parts[1] != AlgPBKDF2 {
+		return 0, nil, nil, fmt.Errorf("parse: %w", ErrUnknownAlgorithm)
+	}
+
+	iterations, err := strconv.Atoi(strings.TrimPrefix(parts[2], "i="))
+	if err != nil || iterations < 1 {
+		return 0, nil, nil, fmt.Errorf("parse: invalid iterations %q", parts[2])
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("parse: salt: %w", err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil || len(key) == 0 {
+		return 0, nil, nil, fmt.Errorf("parse: invalid key")
+	}
+
+	return iterations, salt, key, nil
+}
@@ -0,0 +1,256 @@
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+
+	"github.com/ardanlabs/darwin/v3"
+	"github.com/ardanlabs/darwin/v3/dialects/postgres"
+	"github.com/ardanlabs/darwin/v3/drivers/generic"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/jmoiron/sqlx"
+)
+
+// Set of kinds of differences between the expected and live schemas.
+const (
+	MissingTable     = "missing table"
+	UnexpectedTable  = "unexpected table"
+	MissingColumn    = "missing column"
+	UnexpectedColumn = "unexpected column"
+	TypeMismatch     = "type mismatch"
+	NullableMismatch = "nullable mismatch"
+)
+
+// migrationsTable is the table darwin uses to track the applied migrations.
+const migrationsTable = "darwin_migrations"
+
+// Column represents a column of a table or view in a schema.
+type Column struct {
+	Table    string `db:"table_name"`
+	Name     string `db:"column_name"`
+	Type     string `db:"data_type"`
+	Nullable bool   `db:"nullable"`
+}
+
+// Difference represents a way the live schema differs from the schema the
+// migrations are expected to produce.
+type Difference struct {
+	Kind     string
+	Table    string
+	Column   string
+	Expected string
+	Actual   string
+}
+
+// String implements the stringer interface.
+func (d Difference) String() string {
+	switch d.Kind {
+	case MissingTable, UnexpectedTable:
+		return fmt.Sprintf("%s: %s", d.Kind, d.Table)
+	case MissingColumn, UnexpectedColumn:
+		return fmt.Sprintf("%s: %s.%s", d.Kind, d.Table, d.Column)
+	default:
+		return fmt.Sprintf("%s: %s.%s: expected %s, got %s", d.Kind, d.Table, d.Column, d.Expected, d.Actual)
+	}
+}
+
+// Drift compares the schema of the database against the schema produced by
+// the migrations that have been applied to it. The expected schema is built
+// by applying the same migrations to a scratch schema in the database, which
+// is dropped afterwards, so the user needs permission to create schemas.
+func Drift(ctx context.Context, cfg sqldb.Config) ([]Difference, error) {
+	db, err := sqldb.Open(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+
+	if err := sqldb.StatusCheck(ctx, db); err != nil {
+		return nil, fmt.Errorf("status check database: %w", err)
+	}
+
+	var schema string
+	if err := db.QueryRowContext(ctx, "SELECT current_schema()").Scan(&schema); err != nil {
+		return nil, fmt.Errorf("current schema: %w", err)
+	}
+
+	var applied float32
+	q := fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %s", migrationsTable)
+	if err := db.QueryRowContext(ctx, q).Scan(&applied); err != nil {
+		return nil, fmt.Errorf("applied version: %w", err)
+	}
+
+	actual, err := Columns(ctx, db, schema)
+	if err != nil {
+		return nil, fmt.Errorf("live columns: %w", err)
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("scratch name: %w", err)
+	}
+	scratch := "drift_" + hex.EncodeToString(b)
+
+	if _, err := db.ExecContext(ctx, "CREATE SCHEMA "+scratch); err != nil {
+		return nil, fmt.Errorf("create scratch schema: %w", err)
+	}
+
+	defer func() {
+		db.ExecContext(context.WithoutCancel(ctx), "DROP SCHEMA "+scratch+" CASCADE")
+	}()
+
+	expected, err := expectedColumns(ctx, cfg, scratch, applied)
+	if err != nil {
+		return nil, fmt.Errorf("expected columns: %w", err)
+	}
+
+	return Compare(expected, actual), nil
+}
+
+// expectedColumns applies the migrations up to the version to the scratch
+// schema and returns its columns.
+func expectedColumns(ctx context.Context, cfg sqldb.Config, scratch string, version float32) ([]Column, error) {
+	cfg.Schema = scratch
+	cfg.MaxOpenConns = 1
+
+	db, err := sqldb.Open(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("connect scratch schema: %w", err)
+	}
+	defer db.Close()
+
+	var migs []darwin.Migration
+	for _, mig := range darwin.ParseMigrations(migrateDoc) {
+		if float32(mig.Version) <= version {
+			migs = append(migs, mig)
+		}
+	}
+
+	driver, err := generic.New(db.DB, postgres.Dialect{})
+	if err != nil {
+		return nil, fmt.Errorf("construct darwin driver: %w", err)
+	}
+
+	d := darwin.New(driver, migs)
+	if err := d.Migrate(); err != nil {
+		return nil, fmt.Errorf("migrate scratch schema: %w", err)
+	}
+
+	return Columns(ctx, db, scratch)
+}
+
+// Columns returns the columns of the tables and views in the schema, other
+// than the table that tracks the migrations.
+func Columns(ctx context.Context, db *sqlx.DB, schema string) ([]Column, error) {
+	const q = `
+	SELECT
+		c.relname AS table_name,
+		a.attname AS column_name,
+		format_type(a.atttypid, a.atttypmod) AS data_type,
+		NOT a.attnotnull AS nullable
+	FROM
+		pg_attribute a
+	JOIN
+		pg_class c ON c.oid = a.attrelid
+	JOIN
+		pg_namespace n ON n.oid = c.relnamespace
+	WHERE
+		n.nspname = $1 AND
+		c.relkind IN ('r', 'p', 'v', 'm') AND
+		c.relname <> $2 AND
+		a.attnum > 0 AND
+		NOT a.attisdropped
+	ORDER BY
+		c.relname, a.attnum`
+
+	var cols []Column
+	if err := db.SelectContext(ctx, &cols, q, schema, migrationsTable); err != nil {
+		return nil, err
+	}
+
+	return cols, nil
+}
+
+// Compare returns the differences between the expected and actual columns,
+// ordered by table and column.
+func Compare(expected []Column, actual []Column) []Difference {
+	type key struct {
+		table  string
+		column string
+	}
+
+	expTables := make(map[string]bool)
+	expCols := make(map[key]Column)
+	for _, col := range expected {
+		expTables[col.Table] = true
+		expCols[key{col.Table, col.Name}] = col
+	}
+
+	actTables := make(map[string]bool)
+	actCols := make(map[key]Column)
+	for _, col := range actual {
+		actTables[col.Table] = true
+		actCols[key{col.Table, col.Name}] = col
+	}
+
+	var diffs []Difference
+
+	for _, exp := range expected {
+		if !actTables[exp.Table] {
+			continue
+		}
+
+		act, exists := actCols[key{exp.Table, exp.Name}]
+		switch {
+		case !exists:
+			diffs = append(diffs, Difference{Kind: MissingColumn, Table: exp.Table, Column: exp.Name})
+
+		case act.Type != exp.Type:
+			diffs = append(diffs, Difference{Kind: TypeMismatch, Table: exp.Table, Column: exp.Name, Expected: exp.Type, Actual: act.Type})
+
+		case act.Nullable != exp.Nullable:
+			diffs = append(diffs, Difference{Kind: NullableMismatch, Table: exp.Table, Column: exp.Name, Expected: nullable(exp.Nullable), Actual: nullable(act.Nullable)})
+		}
+	}
+
+	for _, act := range actual {
+		if expTables[act.Table] {
+			if _, exists := expCols[key{act.Table, act.Name}]; !exists {
+				diffs = append(diffs, Difference{Kind: UnexpectedColumn, Table: act.Table, Column: act.Name})
+			}
+		}
+	}
+
+	for table := range expTables {
+		if !actTables[table] {
+			diffs = append(diffs, Difference{Kind: MissingTable, Table: table})
+		}
+	}
+
+	for table := range actTables {
+		if !expTables[table] {
+			diffs = append(diffs, Difference{Kind: UnexpectedTable, Table: table})
+		}
+	}
+
+	slices.SortStableFunc(diffs, func(a, b Difference) int {
+		if c := cmp.Compare(a.Table, b.Table); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Column, b.Column)
+	})
+
+	return diffs
+}
+
+func nullable(v bool) string {
+	if v {
+		return "NULL"
+	}
+
+	return "NOT NULL"
+}
@@ -0,0 +1,83 @@
+package migrate_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/migrate"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Compare(t *testing.T) {
+	expected := []migrate.Column{
+		{Table: "users", Name: "user_id", Type: "uuid"},
+		{Table: "users", Name: "name", Type: "text"},
+		{Table: "users", Name: "department", Type: "text", Nullable: true},
+		{Table: "users", Name: "enabled", Type: "boolean"},
+		{Table: "products", Name: "product_id", Type: "uuid"},
+	}
+
+	actual := []migrate.Column{
+		{Table: "users", Name: "user_id", Type: "uuid"},
+		{Table: "users", Name: "name", Type: "character varying(50)"},
+		{Table: "users", Name: "department", Type: "text"},
+		{Table: "users", Name: "nickname", Type: "text", Nullable: true},
+		{Table: "hotfix", Name: "id", Type: "integer"},
+	}
+
+	exp := []migrate.Difference{
+		{Kind: migrate.UnexpectedTable, Table: "hotfix"},
+		{Kind: migrate.MissingTable, Table: "products"},
+		{Kind: migrate.NullableMismatch, Table: "users", Column: "department", Expected: "NULL", Actual: "NOT NULL"},
+		{Kind: migrate.MissingColumn, Table: "users", Column: "enabled"},
+		{Kind: migrate.TypeMismatch, Table: "users", Column: "name", Expected: "text", Actual: "character varying(50)"},
+		{Kind: migrate.UnexpectedColumn, Table: "users", Column: "nickname"},
+	}
+
+	if diff := cmp.Diff(migrate.Compare(expected, actual), exp); diff != "" {
+		t.Errorf("Should get the differences : %s", diff)
+	}
+
+	if diffs := migrate.Compare(expected, expected); len(diffs) != 0 {
+		t.Errorf("Should get no differences for the same schema : %v", diffs)
+	}
+}
+
+func Test_Drift(t *testing.T) {
+	db := dbtest.NewDatabase(t, "Test_Drift")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	diffs, err := migrate.Drift(ctx, db.Config)
+	if err != nil {
+		t.Fatalf("Should be able to detect drift : %s", err)
+	}
+
+	if len(diffs) != 0 {
+		t.Fatalf("Should get no drift for a migrated database : %v", diffs)
+	}
+
+	const alter = `ALTER TABLE users ALTER COLUMN department TYPE VARCHAR(50);
+	ALTER TABLE homes DROP COLUMN address_2;`
+
+	if _, err := db.DB.ExecContext(ctx, alter); err != nil {
+		t.Fatalf("Should be able to alter the schema : %s", err)
+	}
+
+	diffs, err = migrate.Drift(ctx, db.Config)
+	if err != nil {
+		t.Fatalf("Should be able to detect drift : %s", err)
+	}
+
+	exp := []migrate.Difference{
+		{Kind: migrate.MissingColumn, Table: "homes", Column: "address_2"},
+		{Kind: migrate.TypeMismatch, Table: "users", Column: "department", Expected: "text", Actual: "character varying(50)"},
+	}
+
+	if diff := cmp.Diff(diffs, exp); diff != "" {
+		t.Errorf("Should get the drift : %s", diff)
+	}
+}
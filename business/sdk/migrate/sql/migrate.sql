@@ -64,3 +64,47 @@ CREATE TABLE homes (
     PRIMARY KEY (home_id),
     FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
 );
+
+-- Version: 1.05
+-- Description: Create table user_login_attempts
+CREATE TABLE user_login_attempts (
+    user_id          UUID       NOT NULL,
+    failed_attempts  INT        NOT NULL,
+    locked_until     TIMESTAMP  NULL,
+    date_updated     TIMESTAMP  NOT NULL,
+
+    PRIMARY KEY (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.06
+-- Description: Add email verification to users. Existing users are treated
+-- as verified.
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Version: 1.07
+-- Description: Create table user_password_resets and track when a user's
+-- password was last changed so older tokens can be rejected.
+ALTER TABLE users ADD COLUMN date_password_changed TIMESTAMP NULL;
+
+CREATE TABLE user_password_resets (
+    token_hash    TEXT       NOT NULL,
+    user_id       UUID       NOT NULL,
+    expires_at    TIMESTAMP  NOT NULL,
+    date_created  TIMESTAMP  NOT NULL,
+
+    PRIMARY KEY (token_hash),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+-- Version: 1.08
+-- Description: Add metadata to users.
+ALTER TABLE users ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX users_metadata_idx ON users USING GIN (metadata jsonb_path_ops);
+
+-- Version: 1.09
+-- Description: Add tags to users.
+ALTER TABLE users ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX users_tags_idx ON users USING GIN (tags);
@@ -0,0 +1,269 @@
+// Package money provides support for amounts of money that are exact to the
+// cent. Amounts are held as an integer number of cents so they never pick up
+// the rounding errors of a float64.
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Set of formats for marshaling an amount as JSON.
+const (
+	FormatNumber = iota
+	FormatString
+)
+
+var format atomic.Int32
+
+// SetJSONFormat sets whether amounts are marshaled as a JSON number, like
+// 12.34, or a string, like "12.34". Clients whose JSON decoder turns numbers
+// into floats can use strings to keep the exact amount. Amounts are always
+// unmarshaled from either. It's meant to be called once at startup.
+func SetJSONFormat(f int) error {
+	if f != FormatNumber && f != FormatString {
+		return fmt.Errorf("unknown money format %d", f)
+	}
+
+	format.Store(int32(f))
+
+	return nil
+}
+
+// JSONFormat returns the format amounts are marshaled with.
+func JSONFormat() int {
+	return int(format.Load())
+}
+
+// =============================================================================
+
+// Money represents an amount of money in cents.
+type Money struct {
+	cents int64
+}
+
+// FromCents constructs an amount from a number of cents.
+func FromCents(cents int64) Money {
+	return Money{cents: cents}
+}
+
+// Parse parses a decimal amount like 12.34, -5 or 0.5. Digits past the cents
+// must be zero since the amount can't be represented otherwise.
+func Parse(value string) (Money, error) {
+	s := value
+
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+
+	units, frac, hasFrac := strings.Cut(s, ".")
+	if units == "" || (hasFrac && frac == "") || !isDigits(units) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+
+	if len(frac) > 2 {
+		if strings.Trim(frac[2:], "0") != "" {
+			return Money{}, fmt.Errorf("invalid amount %q, must not be more precise than cents", value)
+		}
+		frac = frac[:2]
+	}
+
+	frac += strings.Repeat("0", 2-len(frac))
+
+	digits := units + frac
+	if neg {
+		digits = "-" + digits
+	}
+
+	cents, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q, out of range", value)
+	}
+
+	return Money{cents: cents}, nil
+}
+
+// MustParse parses the amount and panics if it's invalid.
+func MustParse(value string) Money {
+	m, err := Parse(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return m
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Cents returns the amount as a number of cents.
+func (m Money) Cents() int64 {
+	return m.cents
+}
+
+// String returns the amount with two decimal places, like 12.30.
+func (m Money) String() string {
+	cents := m.cents
+
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+	}
+
+	// The absolute value of the smallest int64 doesn't fit in an int64, so
+	// the units and cents are made positive separately.
+	units, rem := cents/100, cents%100
+	if units < 0 {
+		units = -units
+	}
+	if rem < 0 {
+		rem = -rem
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, units, rem)
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m.cents == 0
+}
+
+// IsNegative reports whether the amount is less than zero.
+func (m Money) IsNegative() bool {
+	return m.cents < 0
+}
+
+// Compare returns -1, 0 or 1 when the amount is less than, equal to or more
+// than the other amount.
+func (m Money) Compare(m2 Money) int {
+	switch {
+	case m.cents < m2.cents:
+		return -1
+	case m.cents > m2.cents:
+		return 1
+	}
+
+	return 0
+}
+
+// Equal provides support for the go-cmp package and testing.
+func (m Money) Equal(m2 Money) bool {
+	return m.cents == m2.cents
+}
+
+// =============================================================================
+
+// ErrOverflow is returned when the result of arithmetic doesn't fit.
+var ErrOverflow = errors.New("money overflow")
+
+// Add returns the sum of the amounts.
+func (m Money) Add(m2 Money) (Money, error) {
+	sum := m.cents + m2.cents
+	if (m2.cents > 0 && sum < m.cents) || (m2.cents < 0 && sum > m.cents) {
+		return Money{}, ErrOverflow
+	}
+
+	return Money{cents: sum}, nil
+}
+
+// Sub returns the difference of the amounts.
+func (m Money) Sub(m2 Money) (Money, error) {
+	if m2.cents == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+
+	return m.Add(Money{cents: -m2.cents})
+}
+
+// Mul returns the amount multiplied by the quantity.
+func (m Money) Mul(quantity int64) (Money, error) {
+	if m.cents == 0 || quantity == 0 {
+		return Money{}, nil
+	}
+
+	product := m.cents * quantity
+	if product/quantity != m.cents || (m.cents == -1 && quantity == math.MinInt64) || (quantity == -1 && m.cents == math.MinInt64) {
+		return Money{}, ErrOverflow
+	}
+
+	return Money{cents: product}, nil
+}
+
+// =============================================================================
+
+// MarshalJSON implements the json.Marshaler interface.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if JSONFormat() == FormatString {
+		return []byte(`"` + m.String() + `"`), nil
+	}
+
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The amount can
+// be a number or a string.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	s := string(data)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+
+	*m = v
+
+	return nil
+}
+
+// Scan implements the sql.Scanner interface for a NUMERIC column.
+func (m *Money) Scan(src any) error {
+	var s string
+
+	switch src := src.(type) {
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	case int64:
+		s = strconv.FormatInt(src, 10)
+	case float64:
+		s = strconv.FormatFloat(src, 'f', -1, 64)
+	default:
+		return fmt.Errorf("money: cannot convert %T to Money", src)
+	}
+
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+
+	*m = v
+
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
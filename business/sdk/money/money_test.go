@@ -0,0 +1,196 @@
+package money_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/ardanlabs/service/business/sdk/money"
+)
+
+type item struct {
+	Cost money.Money `json:"cost"`
+}
+
+func Test_Parse(t *testing.T) {
+	table := []struct {
+		value string
+		cents int64
+		str   string
+	}{
+		{"12.34", 1234, "12.34"},
+		{"12.3", 1230, "12.30"},
+		{"12", 1200, "12.00"},
+		{"0.05", 5, "0.05"},
+		{"-0.05", -5, "-0.05"},
+		{"-12.34", -1234, "-12.34"},
+		{"10.3400", 1034, "10.34"},
+		{"92233720368547758.07", math.MaxInt64, "92233720368547758.07"},
+		{"-92233720368547758.08", math.MinInt64, "-92233720368547758.08"},
+	}
+
+	for _, tt := range table {
+		m, err := money.Parse(tt.value)
+		if err != nil {
+			t.Fatalf("Should be able to parse %q : %s", tt.value, err)
+		}
+
+		if m.Cents() != tt.cents {
+			t.Errorf("Should get %d cents for %q : got %d", tt.cents, tt.value, m.Cents())
+		}
+
+		if m.String() != tt.str {
+			t.Errorf("Should format %q as %q : got %q", tt.value, tt.str, m.String())
+		}
+	}
+
+	for _, value := range []string{"", "-", ".5", "5.", "1.234", "1e2", "abc", "1,000.00", "92233720368547758.08"} {
+		if _, err := money.Parse(value); err == nil {
+			t.Errorf("Should fail to parse %q", value)
+		}
+	}
+}
+
+func Test_Arithmetic(t *testing.T) {
+	sum, err := money.MustParse("0.10").Add(money.MustParse("0.20"))
+	if err != nil {
+		t.Fatalf("Should be able to add : %s", err)
+	}
+
+	if !sum.Equal(money.MustParse("0.30")) {
+		t.Errorf("Should get exactly 0.30 : got %s", sum)
+	}
+
+	diff, err := money.MustParse("10.00").Sub(money.MustParse("10.01"))
+	if err != nil {
+		t.Fatalf("Should be able to subtract : %s", err)
+	}
+
+	if diff.String() != "-0.01" {
+		t.Errorf("Should get -0.01 : got %s", diff)
+	}
+
+	total, err := money.MustParse("19.99").Mul(3)
+	if err != nil {
+		t.Fatalf("Should be able to multiply : %s", err)
+	}
+
+	if total.String() != "59.97" {
+		t.Errorf("Should get 59.97 : got %s", total)
+	}
+
+	maxAmount := money.FromCents(math.MaxInt64)
+
+	if _, err := maxAmount.Add(money.FromCents(1)); !errors.Is(err, money.ErrOverflow) {
+		t.Errorf("Should get an overflow adding past the max : got %v", err)
+	}
+
+	if _, err := money.FromCents(math.MinInt64).Sub(money.FromCents(1)); !errors.Is(err, money.ErrOverflow) {
+		t.Errorf("Should get an overflow subtracting past the min : got %v", err)
+	}
+
+	if _, err := maxAmount.Mul(2); !errors.Is(err, money.ErrOverflow) {
+		t.Errorf("Should get an overflow multiplying past the max : got %v", err)
+	}
+}
+
+func Test_JSON(t *testing.T) {
+	defer money.SetJSONFormat(money.FormatNumber)
+
+	table := []struct {
+		name   string
+		format int
+		exp    string
+	}{
+		{"number", money.FormatNumber, `{"cost":92233720368547758.07}`},
+		{"string", money.FormatString, `{"cost":"92233720368547758.07"}`},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := money.SetJSONFormat(tt.format); err != nil {
+				t.Fatalf("Should be able to set the format : %s", err)
+			}
+
+			in := item{Cost: money.FromCents(math.MaxInt64)}
+
+			data, err := json.Marshal(in)
+			if err != nil {
+				t.Fatalf("Should be able to marshal : %s", err)
+			}
+
+			if string(data) != tt.exp {
+				t.Errorf("Should marshal the exact amount :\ngot: %s\nexp: %s", data, tt.exp)
+			}
+
+			var out item
+			if err := json.Unmarshal(data, &out); err != nil {
+				t.Fatalf("Should be able to unmarshal : %s", err)
+			}
+
+			if !out.Cost.Equal(in.Cost) {
+				t.Errorf("Should not lose precision : got %s, exp %s", out.Cost, in.Cost)
+			}
+		})
+	}
+
+	if err := money.SetJSONFormat(42); err == nil {
+		t.Errorf("Should fail to set an unknown format")
+	}
+
+	var out item
+	if err := json.Unmarshal([]byte(`{"cost":0.1}`), &out); err != nil {
+		t.Fatalf("Should be able to unmarshal a number : %s", err)
+	}
+
+	if out.Cost.Cents() != 10 {
+		t.Errorf("Should get 10 cents : got %d", out.Cost.Cents())
+	}
+
+	if err := json.Unmarshal([]byte(`{"cost":0.001}`), &out); err == nil {
+		t.Errorf("Should fail to unmarshal an amount more precise than cents")
+	}
+}
+
+func Test_DB(t *testing.T) {
+	amounts := []money.Money{
+		money.MustParse("0.01"),
+		money.MustParse("-12.34"),
+		money.MustParse("99999999.99"),
+		money.FromCents(math.MaxInt64),
+	}
+
+	for _, m := range amounts {
+		v, err := m.Value()
+		if err != nil {
+			t.Fatalf("Should be able to get the value of %s : %s", m, err)
+		}
+
+		s, ok := v.(string)
+		if !ok {
+			t.Fatalf("Should get a string value for %s : got %T", m, v)
+		}
+
+		// Postgres returns NUMERIC columns as text.
+		for _, src := range []any{s, []byte(s)} {
+			var got money.Money
+			if err := got.Scan(src); err != nil {
+				t.Fatalf("Should be able to scan %T %q : %s", src, s, err)
+			}
+
+			if !got.Equal(m) {
+				t.Errorf("Should not lose precision : got %s, exp %s", got, m)
+			}
+		}
+	}
+
+	var got money.Money
+	if err := got.Scan(int64(5)); err != nil || got.String() != "5.00" {
+		t.Errorf("Should be able to scan an integer : got %s, %v", got, err)
+	}
+
+	if err := got.Scan(true); err == nil {
+		t.Errorf("Should fail to scan a bool")
+	}
+}
@@ -0,0 +1,104 @@
+package order
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Keyset represents the position of the last row of a page, given by the
+// values of the columns the rows are ordered by. It's used to find the next
+// page without the database counting past the skipped rows, which keeps deep
+// pages as fast as the first one. The last column should be unique, like the
+// primary key, so every row has a distinct position, and the columns must
+// not be nullable.
+type Keyset struct {
+	Columns []By
+	Values  []any
+}
+
+// NewKeyset constructs a keyset for the columns and the values of the last
+// row of the previous page.
+func NewKeyset(columns []By, values ...any) Keyset {
+	return Keyset{
+		Columns: columns,
+		Values:  values,
+	}
+}
+
+// Where returns the predicate that selects the rows after the last row and
+// the named parameters it uses, which are keyset_0, keyset_1 and so on. When
+// every column has the same direction the predicate compares the columns as
+// a row, like (date_created, user_id) > (:keyset_0, :keyset_1), which the
+// database can match to an index. Otherwise, each column is compared in turn.
+func (k Keyset) Where() (string, map[string]any, error) {
+	if len(k.Columns) == 0 {
+		return "", nil, fmt.Errorf("keyset requires at least one column")
+	}
+
+	if len(k.Columns) != len(k.Values) {
+		return "", nil, fmt.Errorf("keyset has %d columns but %d values", len(k.Columns), len(k.Values))
+	}
+
+	columns := make([]string, len(k.Columns))
+	params := make([]string, len(k.Columns))
+	data := make(map[string]any, len(k.Columns))
+	mixed := false
+
+	for i, by := range k.Columns {
+		if by.Field == "" {
+			return "", nil, fmt.Errorf("keyset column %d has no field", i)
+		}
+
+		if _, exists := directions[by.Direction]; !exists {
+			return "", nil, fmt.Errorf("unknown direction: %s", by.Direction)
+		}
+
+		if by.Direction != k.Columns[0].Direction {
+			mixed = true
+		}
+
+		name := fmt.Sprintf("keyset_%d", i)
+
+		columns[i] = by.Field
+		params[i] = ":" + name
+		data[name] = k.Values[i]
+	}
+
+	if !mixed {
+		op := comparison(k.Columns[0].Direction)
+
+		if len(columns) == 1 {
+			return fmt.Sprintf("%s %s %s", columns[0], op, params[0]), data, nil
+		}
+
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), op, strings.Join(params, ", ")), data, nil
+	}
+
+	// A row comes after the last row when it's past it on a column and equal
+	// on every column before that one.
+	terms := make([]string, len(columns))
+	for i := range columns {
+		conds := make([]string, 0, i+1)
+		for j := range i {
+			conds = append(conds, fmt.Sprintf("%s = %s", columns[j], params[j]))
+		}
+		conds = append(conds, fmt.Sprintf("%s %s %s", columns[i], comparison(k.Columns[i].Direction), params[i]))
+
+		terms[i] = strings.Join(conds, " AND ")
+		if len(conds) > 1 {
+			terms[i] = "(" + terms[i] + ")"
+		}
+	}
+
+	return "(" + strings.Join(terms, " OR ") + ")", data, nil
+}
+
+// comparison returns the operator for the rows that come after a value in
+// the direction.
+func comparison(direction string) string {
+	if direction == DESC {
+		return "<"
+	}
+
+	return ">"
+}
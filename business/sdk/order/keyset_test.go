@@ -0,0 +1,99 @@
+package order_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Keyset(t *testing.T) {
+	table := []struct {
+		name    string
+		columns []order.By
+		values  []any
+		where   string
+	}{
+		{
+			name:    "single",
+			columns: []order.By{order.NewBy("user_id", order.ASC)},
+			values:  []any{"a"},
+			where:   "user_id > :keyset_0",
+		},
+		{
+			name: "ascending",
+			columns: []order.By{
+				order.NewBy("date_created", order.ASC),
+				order.NewBy("user_id", order.ASC),
+			},
+			values: []any{"2024-01-01", "a"},
+			where:  "(date_created, user_id) > (:keyset_0, :keyset_1)",
+		},
+		{
+			name: "descending",
+			columns: []order.By{
+				order.NewBy("date_created", order.DESC),
+				order.NewBy("user_id", order.DESC),
+			},
+			values: []any{"2024-01-01", "a"},
+			where:  "(date_created, user_id) < (:keyset_0, :keyset_1)",
+		},
+		{
+			name: "mixed",
+			columns: []order.By{
+				order.NewBy("name", order.ASC),
+				order.NewBy("date_created", order.DESC),
+				order.NewBy("user_id", order.ASC),
+			},
+			values: []any{"Bill", "2024-01-01", "a"},
+			where:  "(name > :keyset_0 OR (name = :keyset_0 AND date_created < :keyset_1) OR (name = :keyset_0 AND date_created = :keyset_1 AND user_id > :keyset_2))",
+		},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			where, data, err := order.NewKeyset(tt.columns, tt.values...).Where()
+			if err != nil {
+				t.Fatalf("Should be able to build the predicate : %s", err)
+			}
+
+			if where != tt.where {
+				t.Errorf("Should get the predicate :\ngot: %s\nexp: %s", where, tt.where)
+			}
+
+			exp := make(map[string]any, len(tt.values))
+			for i, v := range tt.values {
+				exp[fmt.Sprintf("keyset_%d", i)] = v
+			}
+
+			if diff := cmp.Diff(data, exp); diff != "" {
+				t.Errorf("Should get the parameters : %s", diff)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
+
+func Test_KeysetInvalid(t *testing.T) {
+	table := []struct {
+		name   string
+		keyset order.Keyset
+	}{
+		{name: "empty", keyset: order.NewKeyset(nil)},
+		{name: "values", keyset: order.NewKeyset([]order.By{order.NewBy("user_id", order.ASC)})},
+		{name: "field", keyset: order.NewKeyset([]order.By{order.NewBy("", order.ASC)}, "a")},
+		{name: "direction", keyset: order.NewKeyset([]order.By{{Field: "user_id", Direction: "UP"}}, "a")},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			if _, _, err := tt.keyset.Where(); err == nil {
+				t.Errorf("Should fail to build the predicate")
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
@@ -4,16 +4,69 @@ package page
 import (
 	"fmt"
 	"strconv"
+	"sync/atomic"
 )
 
+// Limits represents the rows per page used when a request doesn't ask for
+// a number of rows and the most rows a request can ask for. Requests for
+// more rows are clamped to the maximum unless Reject is set.
+type Limits struct {
+	DefaultRows int
+	MaxRows     int
+	Reject      bool
+}
+
+// DefaultLimits are the limits used until SetLimits is called.
+var DefaultLimits = Limits{
+	DefaultRows: 10,
+	MaxRows:     100,
+}
+
+var limits atomic.Pointer[Limits]
+
+// SetLimits sets the limits used by Parse. It's meant to be called once at
+// startup. Values that aren't positive keep their defaults.
+func SetLimits(l Limits) error {
+	if l.DefaultRows <= 0 {
+		l.DefaultRows = DefaultLimits.DefaultRows
+	}
+
+	if l.MaxRows <= 0 {
+		l.MaxRows = DefaultLimits.MaxRows
+	}
+
+	if l.DefaultRows > l.MaxRows {
+		return fmt.Errorf("default rows %d is larger than the max rows %d", l.DefaultRows, l.MaxRows)
+	}
+
+	limits.Store(&l)
+
+	return nil
+}
+
+// GetLimits returns the limits used by Parse.
+func GetLimits() Limits {
+	if l := limits.Load(); l != nil {
+		return *l
+	}
+
+	return DefaultLimits
+}
+
+// =============================================================================
+
 // Page represents the requested page and rows per page.
 type Page struct {
 	number int
 	rows   int
 }
 
-// Parse parses the strings and validates the values are in reason.
+// Parse parses the strings and validates the values are in reason. A page
+// that isn't positive is the first page and rows that aren't positive are
+// the default rows per page.
 func Parse(page string, rowsPerPage string) (Page, error) {
+	l := GetLimits()
+
 	number := 1
 	if page != "" {
 		var err error
@@ -23,7 +76,7 @@ func Parse(page string, rowsPerPage string) (Page, error) {
 		}
 	}
 
-	rows := 10
+	rows := l.DefaultRows
 	if rowsPerPage != "" {
 		var err error
 		rows, err = strconv.Atoi(rowsPerPage)
@@ -33,15 +86,18 @@ func Parse(page string, rowsPerPage string) (Page, error) {
 	}
 
 	if number <= 0 {
-		return Page{}, fmt.Errorf("page value too small, must be larger than 0")
+		number = 1
 	}
 
 	if rows <= 0 {
-		return Page{}, fmt.Errorf("rows value too small, must be larger than 0")
+		rows = l.DefaultRows
 	}
 
-	if rows > 100 {
-		return Page{}, fmt.Errorf("rows value too large, must be less than 100")
+	if rows > l.MaxRows {
+		if l.Reject {
+			return Page{}, fmt.Errorf("rows value too large, must be at most %d", l.MaxRows)
+		}
+		rows = l.MaxRows
 	}
 
 	p := Page{
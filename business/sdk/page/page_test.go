@@ -0,0 +1,103 @@
+package page_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/service/business/sdk/page"
+)
+
+// The tests share the package limits so they can't run in parallel.
+
+func Test_Parse(t *testing.T) {
+	if err := page.SetLimits(page.Limits{DefaultRows: 20, MaxRows: 50}); err != nil {
+		t.Fatalf("Should be able to set the limits : %s", err)
+	}
+	defer page.SetLimits(page.DefaultLimits)
+
+	table := []struct {
+		name   string
+		page   string
+		rows   string
+		number int
+		per    int
+	}{
+		{name: "default", page: "", rows: "", number: 1, per: 20},
+		{name: "requested", page: "3", rows: "25", number: 3, per: 25},
+		{name: "max", page: "1", rows: "50", number: 1, per: 50},
+		{name: "clamped", page: "1", rows: "5000", number: 1, per: 50},
+		{name: "zero", page: "0", rows: "0", number: 1, per: 20},
+		{name: "negative", page: "-2", rows: "-10", number: 1, per: 20},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			pg, err := page.Parse(tt.page, tt.rows)
+			if err != nil {
+				t.Fatalf("Should be able to parse the page : %s", err)
+			}
+
+			if pg.Number() != tt.number {
+				t.Errorf("Should get page %d : got %d", tt.number, pg.Number())
+			}
+
+			if pg.RowsPerPage() != tt.per {
+				t.Errorf("Should get %d rows per page : got %d", tt.per, pg.RowsPerPage())
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
+
+func Test_ParseReject(t *testing.T) {
+	if err := page.SetLimits(page.Limits{MaxRows: 50, Reject: true}); err != nil {
+		t.Fatalf("Should be able to set the limits : %s", err)
+	}
+	defer page.SetLimits(page.DefaultLimits)
+
+	if _, err := page.Parse("1", "51"); err == nil {
+		t.Errorf("Should reject rows over the max")
+	}
+
+	if _, err := page.Parse("1", "50"); err != nil {
+		t.Errorf("Should allow rows at the max : %s", err)
+	}
+}
+
+func Test_ParseInvalid(t *testing.T) {
+	table := []struct {
+		name string
+		page string
+		rows string
+	}{
+		{name: "page", page: "one", rows: "10"},
+		{name: "rows", page: "1", rows: "ten"},
+		{name: "float", page: "1", rows: "1.5"},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			if _, err := page.Parse(tt.page, tt.rows); err == nil {
+				t.Errorf("Should fail to parse page %q rows %q", tt.page, tt.rows)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
+
+func Test_SetLimits(t *testing.T) {
+	defer page.SetLimits(page.DefaultLimits)
+
+	if err := page.SetLimits(page.Limits{DefaultRows: 200, MaxRows: 100}); err == nil {
+		t.Errorf("Should reject a default larger than the max")
+	}
+
+	if err := page.SetLimits(page.Limits{}); err != nil {
+		t.Fatalf("Should be able to set empty limits : %s", err)
+	}
+
+	if l := page.GetLimits(); l != page.DefaultLimits {
+		t.Errorf("Should get the default limits : got %+v", l)
+	}
+}
@@ -0,0 +1,166 @@
+// Package purge provides support for removing old rows from tables that
+// would otherwise grow without bound, like soft-deleted records and expired
+// tokens.
+package purge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// Purger deletes up to limit rows that are older than the cutoff and returns
+// the number of rows deleted.
+type Purger interface {
+	Purge(ctx context.Context, cutoff time.Time, limit int) (int, error)
+}
+
+// Target is a set of rows to purge. Rows are purged once they are older than
+// the retention window.
+type Target struct {
+	Name      string
+	Purger    Purger
+	Retention time.Duration
+}
+
+// Job purges the targets in batches so no single delete holds locks on a
+// table for long.
+type Job struct {
+	log       *logger.Logger
+	batchSize int
+	targets   []Target
+	now       func() time.Time
+}
+
+// NewJob constructs a job that purges the targets, deleting up to batchSize
+// rows at a time.
+func NewJob(log *logger.Logger, batchSize int, targets ...Target) (*Job, error) {
+	if batchSize <= 0 {
+		return nil, errors.New("batch size must be greater than 0")
+	}
+
+	j := Job{
+		log:       log,
+		batchSize: batchSize,
+		targets:   targets,
+		now:       time.Now,
+	}
+
+	return &j, nil
+}
+
+// Run purges every target. It can be registered with the scheduler. A target
+// that fails doesn't stop the others from being purged.
+func (j *Job) Run(ctx context.Context) error {
+	var errs []error
+
+	for _, target := range j.targets {
+		n, err := j.purge(ctx, target)
+
+		j.log.Info(ctx, "purge", "target", target.Name, "deleted", n)
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("purge %s: %w", target.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (j *Job) purge(ctx context.Context, target Target) (int, error) {
+	cutoff := j.now().Add(-target.Retention)
+
+	var total int
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		n, err := target.Purger.Purge(ctx, cutoff, j.batchSize)
+		total += n
+
+		if err != nil {
+			return total, err
+		}
+
+		if n < j.batchSize {
+			return total, nil
+		}
+	}
+}
+
+// =============================================================================
+
+// identifier matches the table and column names that can be used in a query.
+var identifier = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// Table purges the rows of a database table whose timestamp column is older
+// than the cutoff. For soft-deleted rows this is the deletion time and for
+// tokens it's the expiration time.
+type Table struct {
+	log   *logger.Logger
+	db    sqlx.ExtContext
+	query string
+	name  string
+}
+
+// NewTable constructs a purger for the named table using the timestamp
+// column.
+func NewTable(log *logger.Logger, db sqlx.ExtContext, name string, column string) (*Table, error) {
+	if !identifier.MatchString(name) {
+		return nil, fmt.Errorf("invalid table name %q", name)
+	}
+
+	if !identifier.MatchString(column) {
+		return nil, fmt.Errorf("invalid column name %q", column)
+	}
+
+	// The rows are selected by ctid so a batch can be deleted from any
+	// table, regardless of its primary key.
+	q := fmt.Sprintf(`
+	DELETE FROM
+		%[1]s
+	WHERE
+		ctid IN (
+			SELECT
+				ctid
+			FROM
+				%[1]s
+			WHERE
+				%[2]s < :cutoff
+			LIMIT :limit
+		)`, name, column)
+
+	t := Table{
+		log:   log,
+		db:    db,
+		query: q,
+		name:  name,
+	}
+
+	return &t, nil
+}
+
+// Purge implements the Purger interface.
+func (t *Table) Purge(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	data := struct {
+		Cutoff time.Time `db:"cutoff"`
+		Limit  int       `db:"limit"`
+	}{
+		Cutoff: cutoff,
+		Limit:  limit,
+	}
+
+	n, err := sqldb.NamedExecContextRows(ctx, t.log, t.db, t.query, data)
+	if err != nil {
+		return 0, fmt.Errorf("namedexeccontextrows: table[%s]: %w", t.name, err)
+	}
+
+	return int(n), nil
+}
@@ -0,0 +1,157 @@
+package purge_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/purge"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// memPurger purges rows held in memory and records the size of each batch.
+type memPurger struct {
+	rows    []time.Time
+	batches []int
+	err     error
+}
+
+func (p *memPurger) Purge(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+
+	var n int
+	kept := p.rows[:0]
+	for _, row := range p.rows {
+		if n < limit && row.Before(cutoff) {
+			n++
+			continue
+		}
+		kept = append(kept, row)
+	}
+	p.rows = kept
+
+	p.batches = append(p.batches, n)
+
+	return n, nil
+}
+
+func Test_Purge(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	now := time.Now()
+
+	deleted := &memPurger{}
+	for range 7 {
+		deleted.rows = append(deleted.rows, now.Add(-40*24*time.Hour))
+	}
+	deleted.rows = append(deleted.rows, now.Add(-20*24*time.Hour), now.Add(-time.Hour))
+
+	tokens := &memPurger{
+		rows: []time.Time{now.Add(-time.Minute), now.Add(time.Hour)},
+	}
+
+	failed := &memPurger{err: errors.New("table is locked")}
+
+	job, err := purge.NewJob(log, 3,
+		purge.Target{Name: "deleted", Purger: deleted, Retention: 30 * 24 * time.Hour},
+		purge.Target{Name: "failed", Purger: failed},
+		purge.Target{Name: "tokens", Purger: tokens},
+	)
+	if err != nil {
+		t.Fatalf("Should be able to construct the job : %s", err)
+	}
+
+	err = job.Run(context.Background())
+	if !errors.Is(err, failed.err) {
+		t.Fatalf("Should report the failed target : %v", err)
+	}
+
+	if len(deleted.rows) != 2 {
+		t.Errorf("Should purge the rows older than the retention window, got %d rows left, exp 2", len(deleted.rows))
+	}
+
+	exp := []int{3, 3, 1}
+	if len(deleted.batches) != len(exp) || deleted.batches[0] != exp[0] || deleted.batches[1] != exp[1] || deleted.batches[2] != exp[2] {
+		t.Errorf("Should purge in batches, got %v, exp %v", deleted.batches, exp)
+	}
+
+	if len(tokens.rows) != 1 || !tokens.rows[0].After(now) {
+		t.Errorf("Should purge the expired tokens after a failed target : %v", tokens.rows)
+	}
+}
+
+func Test_PurgeTableName(t *testing.T) {
+	t.Parallel()
+
+	if _, err := purge.NewTable(nil, nil, "users; DROP TABLE users", "expires_at"); err == nil {
+		t.Errorf("Should not accept an invalid table name")
+	}
+
+	if _, err := purge.NewTable(nil, nil, "user_password_resets", "expires_at < now() OR true"); err == nil {
+		t.Errorf("Should not accept an invalid column name")
+	}
+}
+
+func Test_PurgeDB(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.NewDatabase(t, "Test_PurgeDB")
+
+	ctx := context.Background()
+
+	usr, err := db.BusDomain.User.Create(ctx, userbus.TestNewUsers(1, userbus.Roles.User)[0])
+	if err != nil {
+		t.Fatalf("Should be able to create a user : %s", err)
+	}
+
+	now := time.Now().UTC()
+
+	resets := map[string]time.Time{
+		"old":     now.Add(-48 * time.Hour),
+		"recent":  now.Add(-time.Hour),
+		"current": now.Add(time.Hour),
+	}
+
+	const q = `
+	INSERT INTO user_password_resets
+		(token_hash, user_id, expires_at, date_created)
+	VALUES
+		($1, $2, $3, $4)`
+
+	for hash, expiresAt := range resets {
+		if _, err := db.DB.ExecContext(ctx, q, hash, usr.ID, expiresAt, now); err != nil {
+			t.Fatalf("Should be able to insert a password reset : %s", err)
+		}
+	}
+
+	table, err := purge.NewTable(db.Log, db.DB, "user_password_resets", "expires_at")
+	if err != nil {
+		t.Fatalf("Should be able to construct the table purger : %s", err)
+	}
+
+	job, err := purge.NewJob(db.Log, 1, purge.Target{Name: "password resets", Purger: table, Retention: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("Should be able to construct the job : %s", err)
+	}
+
+	if err := job.Run(ctx); err != nil {
+		t.Fatalf("Should be able to purge : %s", err)
+	}
+
+	var left []string
+	if err := db.DB.SelectContext(ctx, &left, `SELECT token_hash FROM user_password_resets ORDER BY token_hash`); err != nil {
+		t.Fatalf("Should be able to query the password resets : %s", err)
+	}
+
+	if len(left) != 2 || left[0] != "current" || left[1] != "recent" {
+		t.Errorf("Should only purge the rows older than the retention window : %v", left)
+	}
+}
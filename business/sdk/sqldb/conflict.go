@@ -0,0 +1,80 @@
+package sqldb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniques holds the columns of the registered unique constraints, keyed by
+// table and then by constraint name.
+var uniques = struct {
+	mu sync.RWMutex
+	m  map[string]map[string][]string
+}{
+	m: make(map[string]map[string][]string),
+}
+
+// RegisterUnique registers the columns of a unique constraint on the table
+// so a violation can report the fields that conflicted. Stores register the
+// constraints of their tables when the package is initialized.
+func RegisterUnique(table string, constraint string, fields ...string) {
+	uniques.mu.Lock()
+	defer uniques.mu.Unlock()
+
+	constraints, exists := uniques.m[table]
+	if !exists {
+		constraints = make(map[string][]string)
+		uniques.m[table] = constraints
+	}
+
+	constraints[constraint] = fields
+}
+
+// ConflictError is returned when a write violates a unique constraint. The
+// fields are only known for constraints that were registered. It matches
+// ErrDBDuplicatedEntry with errors.Is.
+type ConflictError struct {
+	Table      string
+	Constraint string
+	Fields     []string
+}
+
+// Error implements the error interface.
+func (e *ConflictError) Error() string {
+	if len(e.Fields) == 0 {
+		return fmt.Sprintf("%s: table[%s] constraint[%s]", ErrDBDuplicatedEntry, e.Table, e.Constraint)
+	}
+
+	return fmt.Sprintf("%s: %s", ErrDBDuplicatedEntry, strings.Join(e.Fields, ", "))
+}
+
+// Unwrap returns ErrDBDuplicatedEntry so existing checks keep working.
+func (e *ConflictError) Unwrap() error {
+	return ErrDBDuplicatedEntry
+}
+
+// ConflictFields returns the fields that conflicted when the error is a
+// ConflictError.
+func ConflictFields(err error) []string {
+	var cerr *ConflictError
+	if !errors.As(err, &cerr) {
+		return nil
+	}
+
+	return cerr.Fields
+}
+
+func newConflictError(pqerr *pgconn.PgError) *ConflictError {
+	uniques.mu.RLock()
+	defer uniques.mu.RUnlock()
+
+	return &ConflictError{
+		Table:      pqerr.TableName,
+		Constraint: pqerr.ConstraintName,
+		Fields:     uniques.m[pqerr.TableName][pqerr.ConstraintName],
+	}
+}
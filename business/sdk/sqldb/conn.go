@@ -0,0 +1,98 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync/atomic"
+
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// connector opens the connections of the pool through the driver and keeps
+// count of the broken connections the pool discarded, so the connections
+// that replace them can be logged.
+//
+// The driver checks a pooled connection before it's reused. A connection
+// that was closed or that has been idle for more than a second and fails a
+// ping is reported as bad, so the pool discards it and retries the query on
+// another connection.
+type connector struct {
+	driver.Connector
+	log    *logger.Logger
+	broken atomic.Int64
+}
+
+func newConnector(log *logger.Logger, c driver.Connector) *connector {
+	return &connector{
+		Connector: c,
+		log:       log,
+	}
+}
+
+// Connect implements the driver connector interface.
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dc, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for n := c.broken.Load(); n > 0; n = c.broken.Load() {
+		if c.broken.CompareAndSwap(n, n-1) {
+			c.info(ctx, "RECONNECTED")
+			break
+		}
+	}
+
+	sc, ok := dc.(*stdlib.Conn)
+	if !ok {
+		return dc, nil
+	}
+
+	return &conn{Conn: sc, connector: c}, nil
+}
+
+func (c *connector) discard(ctx context.Context, err error) {
+	c.broken.Add(1)
+	c.info(ctx, "DISCARD BROKEN CONNECTION", "ERROR", err)
+}
+
+func (c *connector) info(ctx context.Context, msg string, args ...any) {
+	if c.log == nil {
+		return
+	}
+
+	c.log.Info(ctx, msg, args...)
+}
+
+// =============================================================================
+
+// conn is a connection of the pool that reports when it's found broken.
+type conn struct {
+	*stdlib.Conn
+	connector *connector
+}
+
+// ResetSession implements the driver session resetter interface. It's called
+// before the connection is reused.
+func (c *conn) ResetSession(ctx context.Context) error {
+	err := c.Conn.ResetSession(ctx)
+	if errors.Is(err, driver.ErrBadConn) {
+		c.connector.discard(ctx, err)
+	}
+
+	return err
+}
+
+// IsValid implements the driver validator interface. It's called before the
+// connection is put back into the pool, so a connection that broke while it
+// was used is discarded right away.
+func (c *conn) IsValid() bool {
+	if c.Conn.Conn().IsClosed() {
+		c.connector.discard(context.Background(), driver.ErrBadConn)
+		return false
+	}
+
+	return true
+}
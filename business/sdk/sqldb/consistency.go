@@ -0,0 +1,89 @@
+package sqldb
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// consistencyVersion prefixes the token so its format can change.
+const consistencyVersion = 1
+
+// maxClockSkew is how far in the future a token can be before it's ignored.
+const maxClockSkew = time.Second
+
+// ErrInvalidConsistencyToken is returned when a consistency token can't be
+// parsed.
+var ErrInvalidConsistencyToken = errors.New("invalid consistency token")
+
+// NewConsistencyToken returns an opaque token for a write made at the
+// specified time. Clients pass it back on later reads so they see their own
+// write.
+func NewConsistencyToken(written time.Time) string {
+	buf := make([]byte, 9)
+	buf[0] = consistencyVersion
+	binary.BigEndian.PutUint64(buf[1:], uint64(written.UnixNano()))
+
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// ParseConsistencyToken returns the time of the write the token was issued
+// for.
+func ParseConsistencyToken(token string) (time.Time, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(buf) != 9 || buf[0] != consistencyVersion {
+		return time.Time{}, ErrInvalidConsistencyToken
+	}
+
+	return time.Unix(0, int64(binary.BigEndian.Uint64(buf[1:]))), nil
+}
+
+// WithConsistencyToken stores the time of the write the caller needs to see
+// in the context.
+func WithConsistencyToken(ctx context.Context, written time.Time) context.Context {
+	return context.WithValue(ctx, writeKey, written)
+}
+
+// WithPrimary marks the context so reads are sent to the primary. It's used
+// for reads that decide what is written or who is authenticated, which
+// can't act on a row the replica hasn't caught up with.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey, true)
+}
+
+// Reader returns the database to read from. Reads are sent to the replica
+// unless the context is marked for the primary or has a consistency token
+// for a write that the replica may not have applied yet, given its maximum
+// replication lag. A nil replica always reads from the primary.
+func Reader(ctx context.Context, primary sqlx.ExtContext, replica sqlx.ExtContext, maxLag time.Duration) sqlx.ExtContext {
+	if replica == nil {
+		return primary
+	}
+
+	if usePrimary, _ := ctx.Value(primaryKey).(bool); usePrimary {
+		return primary
+	}
+
+	written, ok := ctx.Value(writeKey).(time.Time)
+	if !ok {
+		return replica
+	}
+
+	now := time.Now()
+
+	// A token from the future wasn't issued by us and shouldn't be able to
+	// pin the client to the primary.
+	if written.After(now.Add(maxClockSkew)) {
+		return replica
+	}
+
+	if now.Sub(written) < maxLag {
+		return primary
+	}
+
+	return replica
+}
@@ -0,0 +1,91 @@
+package sqldb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/jmoiron/sqlx"
+)
+
+func Test_ConsistencyToken(t *testing.T) {
+	t.Parallel()
+
+	written := time.Now()
+
+	got, err := sqldb.ParseConsistencyToken(sqldb.NewConsistencyToken(written))
+	if err != nil {
+		t.Fatalf("Should be able to parse the token : %s", err)
+	}
+
+	if !got.Equal(written.Round(0)) {
+		t.Errorf("Should get the time of the write back, got %s, exp %s", got, written)
+	}
+
+	for _, token := range []string{"", "not a token", "AQ", "AgAAAAAAAAAA"} {
+		if _, err := sqldb.ParseConsistencyToken(token); err == nil {
+			t.Errorf("Should not be able to parse %q", token)
+		}
+	}
+}
+
+func Test_Reader(t *testing.T) {
+	t.Parallel()
+
+	primary := &sqlx.DB{}
+	replica := &sqlx.DB{}
+
+	const maxLag = time.Second
+
+	table := []struct {
+		name    string
+		ctx     context.Context
+		replica sqlx.ExtContext
+		exp     sqlx.ExtContext
+	}{
+		{"no token", context.Background(), replica, replica},
+		{"fresh write", sqldb.WithConsistencyToken(context.Background(), time.Now()), replica, primary},
+		{"old write", sqldb.WithConsistencyToken(context.Background(), time.Now().Add(-time.Minute)), replica, replica},
+		{"future write", sqldb.WithConsistencyToken(context.Background(), time.Now().Add(time.Hour)), replica, replica},
+		{"no replica", context.Background(), nil, primary},
+		{"primary", sqldb.WithPrimary(context.Background()), replica, primary},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sqldb.Reader(tt.ctx, primary, tt.replica, maxLag); got != tt.exp {
+				t.Errorf("Should read from the expected database : primary[%v]", got == primary)
+			}
+		})
+	}
+}
+
+func Test_ContextValues(t *testing.T) {
+	t.Parallel()
+
+	primary := &sqlx.DB{}
+	replica := &sqlx.DB{}
+
+	const timeout = 5 * time.Second
+
+	table := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"primary-timeout", sqldb.WithStatementTimeout(sqldb.WithPrimary(context.Background()), timeout)},
+		{"timeout-primary", sqldb.WithPrimary(sqldb.WithStatementTimeout(context.Background(), timeout))},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sqldb.Reader(tt.ctx, primary, replica, time.Second); got != primary {
+				t.Errorf("Should keep reading from the primary")
+			}
+
+			if got := sqldb.StatementTimeout(tt.ctx); got != timeout {
+				t.Errorf("Should keep the statement timeout : got[%s] exp[%s]", got, timeout)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// Package dbjson provides support for database JSONB types.
+package dbjson
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/go-json-experiment/json"
+)
+
+// Object represents a JSON object stored in a JSONB column. Values are the
+// types produced by decoding JSON, so numbers are float64.
+type Object map[string]any
+
+// Value implements the driver.Valuer interface. A nil object is stored as
+// an empty object.
+func (o Object) Value() (driver.Value, error) {
+	if o == nil {
+		return "{}", nil
+	}
+
+	data, err := json.Marshal(map[string]any(o))
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (o *Object) Scan(src any) error {
+	var data []byte
+
+	switch src := src.(type) {
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	case nil:
+		*o = nil
+		return nil
+	default:
+		return fmt.Errorf("dbjson: cannot convert %T to Object", src)
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("unmarshal: %w", err)
+	}
+
+	*o = m
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package dbjson_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/service/business/sdk/sqldb/dbjson"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Object(t *testing.T) {
+	obj := dbjson.Object{
+		"team":  "red",
+		"level": float64(3),
+		"tags":  []any{"go", "sql"},
+	}
+
+	v, err := obj.Value()
+	if err != nil {
+		t.Fatalf("Should be able to get the value : %s", err)
+	}
+
+	for _, src := range []any{v, []byte(v.(string))} {
+		var got dbjson.Object
+		if err := got.Scan(src); err != nil {
+			t.Fatalf("Should be able to scan a %T : %s", src, err)
+		}
+
+		if diff := cmp.Diff(got, obj); diff != "" {
+			t.Errorf("Should get the object back from a %T : %s", src, diff)
+		}
+	}
+
+	v, err = dbjson.Object(nil).Value()
+	if err != nil {
+		t.Fatalf("Should be able to get the value : %s", err)
+	}
+
+	if v != "{}" {
+		t.Errorf("Should store a nil object as an empty object : %v", v)
+	}
+
+	var got dbjson.Object
+	if err := got.Scan(`[1, 2]`); err == nil {
+		t.Errorf("Should fail to scan an array")
+	}
+
+	if err := got.Scan(42); err == nil {
+		t.Errorf("Should fail to scan an unsupported type")
+	}
+}
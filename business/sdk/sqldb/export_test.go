@@ -0,0 +1,3 @@
+package sqldb
+
+var StatementTimeout = statementTimeout
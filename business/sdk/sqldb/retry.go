@@ -0,0 +1,102 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/retry"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Postgres error codes for transactions that can succeed when retried.
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// IsRetryable reports whether the error is a serialization failure or a
+// deadlock, which abort the transaction but can succeed when it's run again.
+func IsRetryable(err error) bool {
+	var pqerr *pgconn.PgError
+	if !errors.As(err, &pqerr) {
+		return false
+	}
+
+	switch pqerr.Code {
+	case serializationFailure, deadlockDetected:
+		return true
+	}
+
+	return false
+}
+
+// RetryTransactor executes functions with another executor and runs the
+// whole transaction again when it fails with a retryable error. The function
+// must be safe to call more than once, so it shouldn't have side effects
+// outside of the transaction.
+type RetryTransactor struct {
+	log         *logger.Logger
+	exec        Executor
+	maxAttempts int
+	backoff     time.Duration
+	budget      *retry.Budget
+}
+
+// NewRetryTransactor constructs a transactor that makes up to maxAttempts
+// attempts. The wait between attempts starts at backoff and doubles each
+// time, with jitter so competing transactions don't retry in lockstep. The
+// retries are taken from the budget shared with the other components of the
+// service, which can be nil to not limit them.
+func NewRetryTransactor(log *logger.Logger, exec Executor, maxAttempts int, backoff time.Duration, budget *retry.Budget) *RetryTransactor {
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	return &RetryTransactor{
+		log:         log,
+		exec:        exec,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		budget:      budget,
+	}
+}
+
+// Execute implements the Executor interface. The error of the last attempt
+// is returned when every attempt fails or the retry budget is exhausted.
+func (r *RetryTransactor) Execute(ctx context.Context, fn TxFunc) error {
+	wait := r.backoff
+
+	for attempt := 1; ; attempt++ {
+		err := r.exec.Execute(ctx, fn)
+		if err == nil || !IsRetryable(err) || attempt == r.maxAttempts {
+			return err
+		}
+
+		if !r.budget.Allow() {
+			r.log.Info(ctx, "RETRY TRANSACTION", "status", "retry budget exhausted", "attempt", attempt, "ERROR", err)
+			return err
+		}
+
+		delay := wait
+		if wait > 0 {
+			delay += rand.N(wait/2 + 1)
+		}
+
+		r.log.Info(ctx, "RETRY TRANSACTION", "attempt", attempt, "delay", delay, "ERROR", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+
+		case <-timer.C:
+		}
+
+		wait *= 2
+	}
+}
@@ -7,13 +7,15 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/tracer"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
 	"go.opentelemetry.io/otel/attribute"
 )
@@ -32,6 +34,15 @@ var (
 	ErrUndefinedTable    = errors.New("undefined table")
 )
 
+type ctxKey int
+
+// Set of keys for the values stored in the context.
+const (
+	writeKey ctxKey = iota + 1
+	primaryKey
+	timeoutKey
+)
+
 // Config is the required properties to use the database.
 type Config struct {
 	User         string
@@ -42,9 +53,24 @@ type Config struct {
 	MaxIdleConns int
 	MaxOpenConns int
 	DisableTLS   bool
+
+	// StatementTimeout limits how long any statement can run. A zero value
+	// doesn't limit statements.
+	StatementTimeout time.Duration
+
+	// ConnMaxIdleTime and ConnMaxLifetime recycle the connections of the
+	// pool once they have been idle or open for that long. A zero value
+	// keeps connections open.
+	ConnMaxIdleTime time.Duration
+	ConnMaxLifetime time.Duration
+
+	// Log is used to log broken connections and the connections that replace
+	// them. Nothing is logged when it's nil.
+	Log *logger.Logger
 }
 
 // Open knows how to open a database connection based on the configuration.
+// Broken connections are discarded by the pool and replaced as needed.
 func Open(cfg Config) (*sqlx.DB, error) {
 	sslMode := "require"
 	if cfg.DisableTLS {
@@ -57,6 +83,9 @@ func Open(cfg Config) (*sqlx.DB, error) {
 	if cfg.Schema != "" {
 		q.Set("search_path", cfg.Schema)
 	}
+	if cfg.StatementTimeout > 0 {
+		q.Set("statement_timeout", strconv.FormatInt(max(1, cfg.StatementTimeout.Milliseconds()), 10))
+	}
 
 	u := url.URL{
 		Scheme:   "postgres",
@@ -66,12 +95,16 @@ func Open(cfg Config) (*sqlx.DB, error) {
 		RawQuery: q.Encode(),
 	}
 
-	db, err := sqlx.Open("pgx", u.String())
+	pgxCfg, err := pgx.ParseConfig(u.String())
 	if err != nil {
 		return nil, err
 	}
+
+	db := sqlx.NewDb(sql.OpenDB(newConnector(cfg.Log, stdlib.GetConnector(*pgxCfg))), "pgx")
 	db.SetMaxIdleConns(cfg.MaxIdleConns)
 	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	return db, nil
 }
@@ -118,16 +151,28 @@ func ExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, qu
 
 // NamedExecContext is a helper function to execute a CUD operation with
 // logging and tracing where field replacement is necessary.
-func NamedExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any) (err error) {
+func NamedExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any) error {
+	_, err := namedExecContext(ctx, log, db, query, data)
+	return err
+}
+
+// NamedExecContextRows is a helper function to execute a CUD operation with
+// logging and tracing where field replacement is necessary. It returns the
+// number of rows affected.
+func NamedExecContextRows(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any) (int64, error) {
+	return namedExecContext(ctx, log, db, query, data)
+}
+
+func namedExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any) (rows int64, err error) {
 	q := queryString(query, data)
 
 	defer func() {
 		if err != nil {
 			switch data.(type) {
 			case struct{}:
-				log.Infoc(ctx, 6, "database.NamedExecContext", "query", q, "ERROR", err)
+				log.Infoc(ctx, 7, "database.NamedExecContext", "query", q, "ERROR", err)
 			default:
-				log.Infoc(ctx, 5, "database.NamedExecContext", "query", q, "ERROR", err)
+				log.Infoc(ctx, 6, "database.NamedExecContext", "query", q, "ERROR", err)
 			}
 		}
 	}()
@@ -135,20 +180,23 @@ func NamedExecContext(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 	ctx, span := tracer.AddSpan(ctx, "business.api.sqldb.exec", attribute.String("query", q))
 	defer span.End()
 
-	if _, err := sqlx.NamedExecContext(ctx, db, query, data); err != nil {
-		var pqerr *pgconn.PgError
-		if errors.As(err, &pqerr) {
-			switch pqerr.Code {
-			case undefinedTable:
-				return ErrUndefinedTable
-			case uniqueViolation:
-				return ErrDBDuplicatedEntry
-			}
-		}
-		return err
+	ctx, cancel, err := applyStatementTimeout(ctx, db)
+	if err != nil {
+		return 0, err
 	}
+	defer cancel()
 
-	return nil
+	result, err := sqlx.NamedExecContext(ctx, db, query, data)
+	if err != nil {
+		return 0, queryError(ctx, err)
+	}
+
+	rows, err = result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+
+	return rows, nil
 }
 
 // QuerySlice is a helper function for executing queries that return a
@@ -183,6 +231,12 @@ func namedQuerySlice[T any](ctx context.Context, log *logger.Logger, db sqlx.Ext
 	ctx, span := tracer.AddSpan(ctx, "business.api.sqldb.queryslice", attribute.String("query", q))
 	defer span.End()
 
+	ctx, cancel, err := applyStatementTimeout(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
 	var rows *sqlx.Rows
 
 	switch withIn {
@@ -207,27 +261,96 @@ func namedQuerySlice[T any](ctx context.Context, log *logger.Logger, db sqlx.Ext
 	}
 
 	if err != nil {
-		var pqerr *pgconn.PgError
-		if errors.As(err, &pqerr) && pqerr.Code == undefinedTable {
-			return ErrUndefinedTable
-		}
-		return err
+		return queryError(ctx, err)
 	}
 	defer rows.Close()
 
 	var slice []T
 	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return queryError(ctx, err)
+		}
+
 		v := new(T)
 		if err := rows.StructScan(v); err != nil {
 			return err
 		}
 		slice = append(slice, *v)
 	}
+
+	if err := rows.Err(); err != nil {
+		return queryError(ctx, err)
+	}
+
 	*dest = slice
 
 	return nil
 }
 
+// NamedQueryEach is a helper function for executing queries that return a
+// collection of data where each row is unmarshalled and passed to fn as it's
+// read, instead of being collected into a slice. Iteration stops at the first
+// error returned by fn or when the context is canceled. Outside of a
+// transaction, the query is canceled when iteration stops early, so the
+// database doesn't keep producing rows nobody reads.
+func NamedQueryEach[T any](ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, data any, fn func(T) error) (err error) {
+	q := queryString(query, data)
+
+	defer func() {
+		if err != nil {
+			log.Infoc(ctx, 6, "database.NamedQueryEach", "query", q, "ERROR", err)
+		}
+	}()
+
+	ctx, span := tracer.AddSpan(ctx, "business.api.sqldb.queryeach", attribute.String("query", q))
+	defer span.End()
+
+	ctx, cancelTimeout, err := applyStatementTimeout(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer cancelTimeout()
+
+	queryCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rows, err := sqlx.NamedQueryContext(queryCtx, db, query, data)
+	if err != nil {
+		return queryError(ctx, err)
+	}
+
+	// Closing rows that weren't read to the end makes the driver read the
+	// rest of the result first. Canceling the query stops it right away,
+	// but it also closes the connection, which would break a transaction.
+	defer func() {
+		if _, inTx := db.(*sqlx.Tx); !inTx {
+			cancel()
+		}
+		rows.Close()
+	}()
+
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return queryError(ctx, err)
+		}
+
+		var v T
+		if err := rows.StructScan(&v); err != nil {
+			return err
+		}
+
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return queryError(ctx, err)
+	}
+
+	return nil
+}
+
 // QueryStruct is a helper function for executing queries that return a
 // single value to be unmarshalled into a struct type where field replacement is necessary.
 func QueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContext, query string, dest any) error {
@@ -259,6 +382,12 @@ func namedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 	ctx, span := tracer.AddSpan(ctx, "business.api.sqldb.query", attribute.String("query", q))
 	defer span.End()
 
+	ctx, cancel, err := applyStatementTimeout(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
 	var rows *sqlx.Rows
 
 	switch withIn {
@@ -283,15 +412,16 @@ func namedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 	}
 
 	if err != nil {
-		var pqerr *pgconn.PgError
-		if errors.As(err, &pqerr) && pqerr.Code == undefinedTable {
-			return ErrUndefinedTable
-		}
-		return err
+		return queryError(ctx, err)
 	}
 	defer rows.Close()
 
+	// A statement that writes, like an INSERT with a RETURNING clause, can
+	// fail while the first row is read.
 	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return queryError(ctx, err)
+		}
 		return ErrDBNotFound
 	}
 
@@ -302,6 +432,32 @@ func namedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 	return nil
 }
 
+// queryError converts the postgres errors a query can report into the
+// errors of this package. A statement that was canceled is reported as a
+// timeout when its statement timeout passed, but not when the caller's own
+// context was canceled.
+func queryError(ctx context.Context, err error) error {
+	if errors.Is(context.Cause(ctx), ErrDBTimeout) {
+		return &TimeoutError{Timeout: statementTimeout(ctx)}
+	}
+
+	var pqerr *pgconn.PgError
+	if errors.As(err, &pqerr) {
+		switch pqerr.Code {
+		case undefinedTable:
+			return ErrUndefinedTable
+		case uniqueViolation:
+			return newConflictError(pqerr)
+		case queryCanceled:
+			if ctx.Err() == nil {
+				return &TimeoutError{Timeout: statementTimeout(ctx)}
+			}
+		}
+	}
+
+	return err
+}
+
 // queryString provides a pretty print version of the query and parameters.
 func queryString(query string, args any) string {
 	query, params, err := sqlx.Named(query, args)
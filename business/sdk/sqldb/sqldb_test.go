@@ -0,0 +1,502 @@
+package sqldb_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/homebus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/retry"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func Test_Transactor(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	t.Run("commit", func(t *testing.T) {
+		var tx tran
+		trn := sqldb.NewTransactor(log, &beginner{tx: &tx})
+
+		err := trn.Execute(context.Background(), func(ctx context.Context, tx sqldb.CommitRollbacker) error {
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Should be able to execute the transaction : %s", err)
+		}
+
+		if !tx.committed || tx.rolledBack {
+			t.Errorf("Should commit and not rollback : committed[%v] rolledBack[%v]", tx.committed, tx.rolledBack)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var tx tran
+		trn := sqldb.NewTransactor(log, &beginner{tx: &tx})
+
+		expErr := errors.New("failed midway")
+
+		err := trn.Execute(context.Background(), func(ctx context.Context, tx sqldb.CommitRollbacker) error {
+			return expErr
+		})
+		if !errors.Is(err, expErr) {
+			t.Fatalf("Should get back the function error : %v", err)
+		}
+
+		if tx.committed || !tx.rolledBack {
+			t.Errorf("Should rollback and not commit : committed[%v] rolledBack[%v]", tx.committed, tx.rolledBack)
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		var tx tran
+		trn := sqldb.NewTransactor(log, &beginner{tx: &tx})
+
+		func() {
+			defer func() {
+				if rec := recover(); rec == nil {
+					t.Errorf("Should propagate the panic")
+				}
+			}()
+
+			trn.Execute(context.Background(), func(ctx context.Context, tx sqldb.CommitRollbacker) error {
+				panic("failed midway")
+			})
+		}()
+
+		if tx.committed || !tx.rolledBack {
+			t.Errorf("Should rollback and not commit : committed[%v] rolledBack[%v]", tx.committed, tx.rolledBack)
+		}
+	})
+}
+
+func Test_TransactorDB(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.NewDatabase(t, "Test_TransactorDB")
+
+	ctx := context.Background()
+
+	trn := sqldb.NewTransactor(db.Log, sqldb.NewBeginner(db.DB))
+
+	nu := userbus.TestNewUsers(1, userbus.Roles.User)[0]
+
+	var usr userbus.User
+	var hme homebus.Home
+
+	err := trn.Execute(ctx, func(ctx context.Context, tx sqldb.CommitRollbacker) error {
+		userBus, err := db.BusDomain.User.NewWithTx(tx)
+		if err != nil {
+			return err
+		}
+
+		homeBus, err := db.BusDomain.Home.NewWithTx(tx)
+		if err != nil {
+			return err
+		}
+
+		usr, err = userBus.Create(ctx, nu)
+		if err != nil {
+			return fmt.Errorf("create user: %w", err)
+		}
+
+		hme, err = homeBus.Create(ctx, homebus.TestGenerateNewHomes(1, usr.ID)[0])
+		if err != nil {
+			return fmt.Errorf("create home: %w", err)
+		}
+
+		return errors.New("failed midway")
+	})
+	if err == nil {
+		t.Fatalf("Should get an error from the transaction")
+	}
+
+	if _, err := db.BusDomain.User.QueryByID(ctx, usr.ID); !errors.Is(err, userbus.ErrNotFound) {
+		t.Errorf("Should not find the user after the rollback : %v", err)
+	}
+
+	if _, err := db.BusDomain.Home.QueryByID(ctx, hme.ID); !errors.Is(err, homebus.ErrNotFound) {
+		t.Errorf("Should not find the home after the rollback : %v", err)
+	}
+}
+
+func Test_QueryEachCancel(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.NewDatabase(t, "Test_QueryEachCancel")
+
+	// The query produces far more rows than are read, so it's still running
+	// when the scan stops.
+	const q = `SELECT g AS n FROM generate_series(1, 50000000) AS g`
+
+	type row struct {
+		N int `db:"n"`
+	}
+
+	errStop := errors.New("stop")
+
+	table := []struct {
+		name   string
+		stop   func(cancel context.CancelFunc) error
+		expErr error
+	}{
+		{
+			name:   "cancel",
+			stop:   func(cancel context.CancelFunc) error { cancel(); return nil },
+			expErr: context.Canceled,
+		},
+		{
+			name:   "error",
+			stop:   func(cancel context.CancelFunc) error { return errStop },
+			expErr: errStop,
+		},
+	}
+
+	for _, tt := range table {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var n int
+		fn := func(r row) error {
+			n++
+			if n == 100 {
+				return tt.stop(cancel)
+			}
+			return nil
+		}
+
+		start := time.Now()
+		err := sqldb.NamedQueryEach(ctx, db.Log, db.DB, q, struct{}{}, fn)
+		cancel()
+
+		if !errors.Is(err, tt.expErr) {
+			t.Fatalf("%s: Should stop with %v : got %v", tt.name, tt.expErr, err)
+		}
+
+		if n != 100 {
+			t.Errorf("%s: Should not read rows after the scan stops : read %d", tt.name, n)
+		}
+
+		if d := time.Since(start); d > 5*time.Second {
+			t.Errorf("%s: Should stop promptly : took %v", tt.name, d)
+		}
+
+		if !eventually(func() bool { return db.DB.Stats().InUse == 0 }) {
+			t.Errorf("%s: Should release the connection : in use %d", tt.name, db.DB.Stats().InUse)
+		}
+
+		running := func() bool {
+			const active = `SELECT count(*) FROM pg_stat_activity WHERE state = 'active' AND query LIKE '%generate_series(1, 50000000)%' AND pid <> pg_backend_pid()`
+
+			var count int
+			if err := db.DB.GetContext(context.Background(), &count, active); err != nil {
+				t.Fatalf("%s: Should be able to query the activity : %s", tt.name, err)
+			}
+
+			return count == 0
+		}
+
+		if !eventually(running) {
+			t.Errorf("%s: Should stop the query in the database", tt.name)
+		}
+	}
+}
+
+func Test_StatementTimeout(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.NewDatabase(t, "Test_StatementTimeout")
+
+	const timeout = 200 * time.Millisecond
+
+	// The query sleeps far longer than the timeout allows.
+	const q = `SELECT pg_sleep(10) IS NULL AS slept`
+
+	var dest struct {
+		Slept bool `db:"slept"`
+	}
+
+	table := []struct {
+		name string
+		run  func(ctx context.Context) error
+	}{
+		{
+			name: "query",
+			run: func(ctx context.Context) error {
+				return sqldb.QueryStruct(ctx, db.Log, db.DB, q, &dest)
+			},
+		},
+		{
+			name: "exec",
+			run: func(ctx context.Context) error {
+				return sqldb.ExecContext(ctx, db.Log, db.DB, `SELECT pg_sleep(10)`)
+			},
+		},
+		{
+			name: "tran",
+			run: func(ctx context.Context) error {
+				tx, err := db.DB.Beginx()
+				if err != nil {
+					return fmt.Errorf("begin: %w", err)
+				}
+				defer tx.Rollback()
+
+				return sqldb.QueryStruct(ctx, db.Log, tx, q, &dest)
+			},
+		},
+	}
+
+	for _, tt := range table {
+		ctx := sqldb.WithStatementTimeout(context.Background(), timeout)
+
+		start := time.Now()
+		err := tt.run(ctx)
+
+		var timeoutErr *sqldb.TimeoutError
+		if !errors.As(err, &timeoutErr) || !errors.Is(err, sqldb.ErrDBTimeout) {
+			t.Fatalf("%s: Should get a timeout error : %v", tt.name, err)
+		}
+
+		if timeoutErr.Timeout != timeout {
+			t.Errorf("%s: Should report the timeout : got %v, exp %v", tt.name, timeoutErr.Timeout, timeout)
+		}
+
+		if d := time.Since(start); d > 5*time.Second {
+			t.Errorf("%s: Should stop at the timeout : took %v", tt.name, d)
+		}
+	}
+
+	// -------------------------------------------------------------------------
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(timeout)
+		cancel()
+	}()
+
+	err := sqldb.QueryStruct(sqldb.WithStatementTimeout(ctx, time.Minute), db.Log, db.DB, q, &dest)
+	if errors.Is(err, sqldb.ErrDBTimeout) {
+		t.Errorf("Should not report a timeout when the caller cancels : %v", err)
+	}
+
+	if err := sqldb.QueryStruct(sqldb.WithStatementTimeout(context.Background(), time.Minute), db.Log, db.DB, `SELECT TRUE AS slept`, &dest); err != nil {
+		t.Errorf("Should be able to run a query within the timeout : %s", err)
+	}
+}
+
+func Test_Reconnect(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.NewDatabase(t, "Test_Reconnect")
+
+	var buf bytes.Buffer
+
+	// A single connection makes every query use the connection that is
+	// dropped.
+	cfg := db.Config
+	cfg.MaxOpenConns = 1
+	cfg.Log = logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	pool, err := sqldb.Open(cfg)
+	if err != nil {
+		t.Fatalf("Should be able to open the database : %s", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+
+	backend := func() (int, error) {
+		var pid int
+		err := pool.GetContext(ctx, &pid, `SELECT pg_backend_pid()`)
+		return pid, err
+	}
+
+	table := []struct {
+		name string
+		idle time.Duration
+	}{
+		// An idle connection is pinged before it's reused, so the query
+		// is run on a new connection.
+		{name: "idle", idle: 1100 * time.Millisecond},
+
+		// A connection that was just used isn't pinged, so the first query
+		// can fail, but the broken connection isn't reused.
+		{name: "recent", idle: 0},
+	}
+
+	for _, tt := range table {
+		before, err := backend()
+		if err != nil {
+			t.Fatalf("%s: Should be able to query : %s", tt.name, err)
+		}
+
+		// Drop the connection from the database side, as a network failure
+		// would.
+		if _, err := db.DB.ExecContext(ctx, `SELECT pg_terminate_backend($1)`, before); err != nil {
+			t.Fatalf("%s: Should be able to terminate the connection : %s", tt.name, err)
+		}
+
+		time.Sleep(tt.idle)
+
+		after, err := backend()
+		if err != nil {
+			if tt.idle > 0 {
+				t.Fatalf("%s: Should recover the connection before the query : %s", tt.name, err)
+			}
+
+			after, err = backend()
+			if err != nil {
+				t.Fatalf("%s: Should recover on the next query : %s", tt.name, err)
+			}
+		}
+
+		if after == before {
+			t.Errorf("%s: Should use a new connection : pid %d", tt.name, after)
+		}
+	}
+
+	for _, msg := range []string{"DISCARD BROKEN CONNECTION", "RECONNECTED"} {
+		if !strings.Contains(buf.String(), msg) {
+			t.Errorf("Should log %q :\n%s", msg, buf.String())
+		}
+	}
+}
+
+// eventually polls the condition for a couple of seconds, since the database
+// stops a canceled query asynchronously.
+func eventually(cond func() bool) bool {
+	for range 20 {
+		if cond() {
+			return true
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return false
+}
+
+// =============================================================================
+
+type tran struct {
+	committed  bool
+	rolledBack bool
+}
+
+func (tx *tran) Commit() error {
+	tx.committed = true
+	return nil
+}
+
+func (tx *tran) Rollback() error {
+	tx.rolledBack = true
+	return nil
+}
+
+type beginner struct {
+	tx *tran
+}
+
+func (b *beginner) Begin() (sqldb.CommitRollbacker, error) {
+	return b.tx, nil
+}
+
+func Test_RetryTransactor(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	serialization := &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+
+	t.Run("retry", func(t *testing.T) {
+		var tx tran
+		trn := sqldb.NewRetryTransactor(log, sqldb.NewTransactor(log, &beginner{tx: &tx}), 3, time.Millisecond, nil)
+
+		var attempts int
+		err := trn.Execute(context.Background(), func(ctx context.Context, tx sqldb.CommitRollbacker) error {
+			attempts++
+			if attempts == 1 {
+				return fmt.Errorf("update: %w", serialization)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Should succeed on the retry : %s", err)
+		}
+
+		if attempts != 2 {
+			t.Errorf("Should run the transaction twice : got[%d]", attempts)
+		}
+
+		if !tx.committed {
+			t.Errorf("Should commit the retried transaction")
+		}
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		var tx tran
+		trn := sqldb.NewRetryTransactor(log, sqldb.NewTransactor(log, &beginner{tx: &tx}), 3, time.Millisecond, nil)
+
+		var attempts int
+		err := trn.Execute(context.Background(), func(ctx context.Context, tx sqldb.CommitRollbacker) error {
+			attempts++
+			return &pgconn.PgError{Code: "40P01", Message: "deadlock detected"}
+		})
+		if !sqldb.IsRetryable(err) {
+			t.Fatalf("Should get back the deadlock error : %v", err)
+		}
+
+		if attempts != 3 {
+			t.Errorf("Should stop after the max attempts : got[%d]", attempts)
+		}
+
+		if tx.committed || !tx.rolledBack {
+			t.Errorf("Should rollback and not commit : committed[%v] rolledBack[%v]", tx.committed, tx.rolledBack)
+		}
+	})
+
+	t.Run("budget", func(t *testing.T) {
+		var tx tran
+		trn := sqldb.NewRetryTransactor(log, sqldb.NewTransactor(log, &beginner{tx: &tx}), 3, time.Millisecond, retry.NewBudget(0, 1))
+
+		var attempts int
+		err := trn.Execute(context.Background(), func(ctx context.Context, tx sqldb.CommitRollbacker) error {
+			attempts++
+			return serialization
+		})
+		if !sqldb.IsRetryable(err) {
+			t.Fatalf("Should get back the serialization error : %v", err)
+		}
+
+		if attempts != 2 {
+			t.Errorf("Should stop retrying once the budget is exhausted : got[%d]", attempts)
+		}
+	})
+
+	t.Run("not-retryable", func(t *testing.T) {
+		var tx tran
+		trn := sqldb.NewRetryTransactor(log, sqldb.NewTransactor(log, &beginner{tx: &tx}), 3, time.Millisecond, nil)
+
+		var attempts int
+		err := trn.Execute(context.Background(), func(ctx context.Context, tx sqldb.CommitRollbacker) error {
+			attempts++
+			return &pgconn.PgError{Code: "23505", Message: "duplicate key"}
+		})
+		if err == nil {
+			t.Fatalf("Should get back the error")
+		}
+
+		if attempts != 1 {
+			t.Errorf("Should not retry the error : got[%d]", attempts)
+		}
+	})
+}
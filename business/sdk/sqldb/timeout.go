@@ -0,0 +1,77 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// queryCanceled is the postgres error code for a statement that was canceled,
+// either for running past the statement timeout or by the client.
+const queryCanceled = "57014"
+
+// ErrDBTimeout is matched by a TimeoutError with errors.Is.
+var ErrDBTimeout = errors.New("statement timeout")
+
+// TimeoutError is returned when a statement runs longer than its statement
+// timeout. The timeout is only known when it was set for the call. It
+// matches ErrDBTimeout with errors.Is.
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+// Error implements the error interface.
+func (e *TimeoutError) Error() string {
+	if e.Timeout <= 0 {
+		return ErrDBTimeout.Error()
+	}
+
+	return fmt.Sprintf("%s: %s", ErrDBTimeout, e.Timeout)
+}
+
+// Unwrap returns ErrDBTimeout so the error can be matched with errors.Is.
+func (e *TimeoutError) Unwrap() error {
+	return ErrDBTimeout
+}
+
+// WithStatementTimeout returns a context that limits how long the statements
+// run with it can take, overriding the statement timeout of the database
+// connection. A zero timeout leaves the connection's timeout in place.
+func WithStatementTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey, timeout)
+}
+
+// statementTimeout returns the statement timeout set for the call.
+func statementTimeout(ctx context.Context) time.Duration {
+	timeout, _ := ctx.Value(timeoutKey).(time.Duration)
+	return timeout
+}
+
+// applyStatementTimeout applies the statement timeout set for the call.
+// Inside a transaction, the timeout is set on the database with SET LOCAL,
+// so it holds for the rest of the transaction, because canceling a statement
+// through its context would close the connection and break the transaction.
+// Otherwise, the context is given a deadline and the driver cancels the
+// statement once it passes.
+func applyStatementTimeout(ctx context.Context, db sqlx.ExtContext) (context.Context, context.CancelFunc, error) {
+	timeout := statementTimeout(ctx)
+	if timeout <= 0 {
+		return ctx, func() {}, nil
+	}
+
+	if _, inTx := db.(*sqlx.Tx); inTx {
+		q := fmt.Sprintf("SET LOCAL statement_timeout = %d", max(1, timeout.Milliseconds()))
+		if _, err := db.ExecContext(ctx, q); err != nil {
+			return ctx, func() {}, fmt.Errorf("set statement timeout: %w", err)
+		}
+
+		return ctx, func() {}, nil
+	}
+
+	ctx, cancel := context.WithTimeoutCause(ctx, timeout, ErrDBTimeout)
+
+	return ctx, cancel, nil
+}
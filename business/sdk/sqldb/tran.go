@@ -1,8 +1,12 @@
 package sqldb
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
+	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -47,3 +51,74 @@ func GetExtContext(tx CommitRollbacker) (sqlx.ExtContext, error) {
 
 	return ec, nil
 }
+
+// =============================================================================
+
+// TxFunc represents a function that performs work under a transaction. The
+// domain business values required by the function should be bound to the
+// transaction using their NewWithTx functions.
+type TxFunc func(ctx context.Context, tx CommitRollbacker) error
+
+// Executor represents a value that can execute a function under a
+// transaction.
+type Executor interface {
+	Execute(ctx context.Context, fn TxFunc) error
+}
+
+// Transactor executes functions under a transaction, committing on success
+// and rolling back on error or panic.
+type Transactor struct {
+	log *logger.Logger
+	bgn Beginner
+}
+
+// NewTransactor constructs a transactor that uses the beginner to start
+// new transactions.
+func NewTransactor(log *logger.Logger, bgn Beginner) *Transactor {
+	return &Transactor{
+		log: log,
+		bgn: bgn,
+	}
+}
+
+// Execute begins a transaction and calls the specified function. If the
+// function returns an error or panics, the transaction is rolled back. A
+// panic is propagated after the rollback.
+func (t *Transactor) Execute(ctx context.Context, fn TxFunc) (err error) {
+	t.log.Info(ctx, "BEGIN TRANSACTION")
+	tx, err := t.bgn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	hasCommitted := false
+
+	defer func() {
+		rec := recover()
+
+		if !hasCommitted {
+			t.log.Info(ctx, "ROLLBACK TRANSACTION")
+
+			if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+				t.log.Info(ctx, "ROLLBACK TRANSACTION", "ERROR", rbErr)
+			}
+		}
+
+		if rec != nil {
+			panic(rec)
+		}
+	}()
+
+	if err := fn(ctx, tx); err != nil {
+		return err
+	}
+
+	t.log.Info(ctx, "COMMIT TRANSACTION")
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	hasCommitted = true
+
+	return nil
+}
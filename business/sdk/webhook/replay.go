@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Replays tracks the signatures of the deliveries that were received, so a
+// delivery that is sent again within the tolerance of its timestamp can be
+// rejected. Verify rejects a delivery once its timestamp is outside the
+// tolerance, so a signature only has to be remembered for twice the
+// tolerance to cover clocks that are ahead or behind.
+type Replays struct {
+	ttl time.Duration
+
+	mu     sync.Mutex
+	seen   map[string]time.Time
+	pruned time.Time
+}
+
+// NewReplays constructs a tracker for deliveries verified with the tolerance.
+func NewReplays(tolerance time.Duration) *Replays {
+	return &Replays{
+		ttl:  2 * tolerance,
+		seen: make(map[string]time.Time),
+	}
+}
+
+// Check records the signature of the delivery and returns ErrReplayed when it
+// was already received. The signature must be verified first, so a forged
+// delivery can't block the real one.
+func (rp *Replays) Check(h http.Header, now time.Time) error {
+	sig := h.Get(HeaderSignature)
+	if sig == "" {
+		return ErrMissingSignature
+	}
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	if now.Sub(rp.pruned) > rp.ttl {
+		for s, expires := range rp.seen {
+			if now.After(expires) {
+				delete(rp.seen, s)
+			}
+		}
+		rp.pruned = now
+	}
+
+	if expires, exists := rp.seen[sig]; exists && !now.After(expires) {
+		return ErrReplayed
+	}
+
+	rp.seen[sig] = now.Add(rp.ttl)
+
+	return nil
+}
+
+// Forget removes the signature of the delivery, so the sender can retry a
+// delivery that failed to be processed.
+func (rp *Replays) Forget(h http.Header) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	delete(rp.seen, h.Get(HeaderSignature))
+}
@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Set of headers used to sign a delivery.
+const (
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// signatureVersion is the prefix applied to the signature so the scheme can
+// change in the future without breaking receivers.
+const signatureVersion = "v1="
+
+// Set of error variables for verifying a delivery.
+var (
+	ErrMissingSignature = errors.New("missing signature")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+	ErrTimestampExpired = errors.New("timestamp outside tolerance")
+	ErrReplayed         = errors.New("replayed signature")
+)
+
+// Sign adds the timestamp and signature headers for the body. The signature
+// is a HMAC-SHA256 of the timestamp and body so a captured request can't be
+// replayed with a different timestamp.
+func Sign(h http.Header, secret string, now time.Time, body []byte) {
+	ts := strconv.FormatInt(now.Unix(), 10)
+
+	h.Set(HeaderTimestamp, ts)
+	h.Set(HeaderSignature, signatureVersion+signature(secret, ts, body))
+}
+
+// Verify checks the signature headers against the body and rejects requests
+// with a timestamp outside the tolerance of now to prevent replay attacks.
+func Verify(h http.Header, secret string, now time.Time, tolerance time.Duration, body []byte) error {
+	ts := h.Get(HeaderTimestamp)
+	sig := h.Get(HeaderSignature)
+
+	if ts == "" || sig == "" {
+		return ErrMissingSignature
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+
+	if diff := now.Sub(time.Unix(unix, 0)).Abs(); diff > tolerance {
+		return ErrTimestampExpired
+	}
+
+	got, ok := strings.CutPrefix(sig, signatureVersion)
+	if !ok {
+		return ErrInvalidSignature
+	}
+
+	exp := signature(secret, ts, body)
+	if !hmac.Equal([]byte(got), []byte(exp)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+func signature(secret string, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,266 @@
+// Package webhook provides support for notifying external systems of domain
+// events by delivering signed payloads to registered endpoints.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/retry"
+	"github.com/google/uuid"
+)
+
+// ErrShutdown is returned when an event is sent after the webhook has
+// started to shut down.
+var ErrShutdown = errors.New("webhook is shut down")
+
+// Endpoint represents an external system that is notified of events.
+type Endpoint struct {
+	URL    string
+	Secret string
+}
+
+// Payload represents the JSON document that is delivered to an endpoint.
+type Payload struct {
+	ID        string          `json:"id"`
+	Domain    string          `json:"domain"`
+	Action    string          `json:"action"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+// DeadLetter represents a delivery that failed after all attempts.
+type DeadLetter struct {
+	Endpoint Endpoint
+	Payload  Payload
+	Attempts int
+	Err      error
+}
+
+// DeadLetterFunc is called when a delivery has exhausted its attempts.
+type DeadLetterFunc func(ctx context.Context, dl DeadLetter)
+
+// Config represents the settings for delivering webhooks.
+type Config struct {
+	Log         *logger.Logger
+	Client      *http.Client
+	MaxAttempts int
+	Backoff     time.Duration
+	DeadLetter  DeadLetterFunc
+
+	// RetryBudget is optional and limits the retries shared with the other
+	// components of the service. Deliveries are dead lettered without
+	// retrying when the budget is exhausted.
+	RetryBudget *retry.Budget
+}
+
+// Webhook manages the delivery of events to the registered endpoints.
+type Webhook struct {
+	log         *logger.Logger
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	deadLetter  DeadLetterFunc
+	budget      *retry.Budget
+	mu          sync.RWMutex
+	endpoints   []Endpoint
+	closed      bool
+	wg          sync.WaitGroup
+	shutdown    chan struct{}
+	once        sync.Once
+}
+
+// New constructs a webhook value for delivering events.
+func New(cfg Config) *Webhook {
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+
+	backoff := cfg.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	w := Webhook{
+		log:         cfg.Log,
+		client:      client,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		deadLetter:  cfg.DeadLetter,
+		budget:      cfg.RetryBudget,
+		shutdown:    make(chan struct{}),
+	}
+
+	if w.deadLetter == nil {
+		w.deadLetter = func(ctx context.Context, dl DeadLetter) {
+			w.log.Error(ctx, "webhook", "status", "dead letter", "url", dl.Endpoint.URL, "id", dl.Payload.ID, "attempts", dl.Attempts, "err", dl.Err)
+		}
+	}
+
+	return &w
+}
+
+// Register adds an endpoint to be notified of events.
+func (w *Webhook) Register(endpoint Endpoint) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.endpoints = append(w.endpoints, endpoint)
+}
+
+// Subscribe registers the webhook with the delegate so the endpoints are
+// notified when the specified domain action occurs.
+func (w *Webhook) Subscribe(d *delegate.Delegate, domain string, action string) {
+	d.Register(domain, action, w.Send)
+}
+
+// Send delivers the event to all the registered endpoints. Delivery happens
+// asynchronously so the caller is not blocked by retries. The delivery is not
+// canceled when the caller's context is canceled. Events sent after the
+// webhook started to shut down are rejected with ErrShutdown.
+func (w *Webhook) Send(ctx context.Context, data delegate.Data) error {
+	payload := Payload{
+		ID:        uuid.NewString(),
+		Domain:    data.Domain,
+		Action:    data.Action,
+		Params:    data.RawParams,
+		Timestamp: time.Now().Unix(),
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	// The deliveries are added to the wait group under the lock, so Shutdown
+	// can't start waiting while they're being added.
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return ErrShutdown
+	}
+
+	endpoints := make([]Endpoint, len(w.endpoints))
+	copy(endpoints, w.endpoints)
+	w.wg.Add(len(endpoints))
+	w.mu.RUnlock()
+
+	ctx = context.WithoutCancel(ctx)
+
+	for _, endpoint := range endpoints {
+		go func() {
+			defer w.wg.Done()
+			w.deliver(ctx, endpoint, payload, body)
+		}()
+	}
+
+	return nil
+}
+
+// Shutdown stops any retries that are waiting and waits for the in-flight
+// deliveries to complete or the context to be canceled. It's safe to call
+// more than once.
+func (w *Webhook) Shutdown(ctx context.Context) error {
+	w.once.Do(func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		w.closed = true
+		close(w.shutdown)
+	})
+
+	ch := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(ch)
+	}()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// deliver posts the payload to the endpoint, retrying with an exponential
+// backoff until the delivery succeeds or the attempts or the retry budget
+// are exhausted.
+func (w *Webhook) deliver(ctx context.Context, endpoint Endpoint, payload Payload, body []byte) {
+	var err error
+	var attempt int
+
+loop:
+	for attempt = 1; attempt <= w.maxAttempts; attempt++ {
+		if err = w.post(ctx, endpoint, body); err == nil {
+			w.log.Info(ctx, "webhook", "status", "delivered", "url", endpoint.URL, "id", payload.ID, "attempt", attempt)
+			return
+		}
+
+		w.log.Info(ctx, "webhook", "status", "delivery failed", "url", endpoint.URL, "id", payload.ID, "attempt", attempt, "err", err)
+
+		if attempt == w.maxAttempts {
+			break
+		}
+
+		if !w.budget.Allow() {
+			w.log.Info(ctx, "webhook", "status", "retry budget exhausted", "url", endpoint.URL, "id", payload.ID, "attempt", attempt)
+			break
+		}
+
+		t := time.NewTimer(w.backoff << (attempt - 1))
+		select {
+		case <-t.C:
+		case <-w.shutdown:
+			t.Stop()
+			break loop
+		}
+	}
+
+	w.deadLetter(ctx, DeadLetter{
+		Endpoint: endpoint,
+		Payload:  payload,
+		Attempts: min(attempt, w.maxAttempts),
+		Err:      err,
+	})
+}
+
+// post performs a single delivery attempt of the signed payload.
+func (w *Webhook) post(ctx context.Context, endpoint Endpoint, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	Sign(req.Header, endpoint.Secret, time.Now(), body)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New(resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,252 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/delegate"
+	"github.com/ardanlabs/service/business/sdk/webhook"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/retry"
+)
+
+const secret = "secret"
+
+func Test_Deliver(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	received := make(chan webhook.Payload, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if err := webhook.Verify(r.Header, secret, time.Now(), time.Minute, body); err != nil {
+			t.Errorf("Should be able to verify the signature : %s", err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var payload webhook.Payload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("Should be able to unmarshal the payload : %s", err)
+		}
+
+		received <- payload
+	}))
+	defer srv.Close()
+
+	wh := webhook.New(webhook.Config{
+		Log: log,
+		DeadLetter: func(ctx context.Context, dl webhook.DeadLetter) {
+			t.Errorf("Should not dead letter the delivery : %s", dl.Err)
+		},
+	})
+	wh.Register(webhook.Endpoint{URL: srv.URL, Secret: secret})
+
+	dlg := delegate.New(log)
+	wh.Subscribe(dlg, "user", "updated")
+
+	data := delegate.Data{
+		Domain:    "user",
+		Action:    "updated",
+		RawParams: []byte(`{"userID":"1234"}`),
+	}
+
+	if err := dlg.Call(context.Background(), data); err != nil {
+		t.Fatalf("Should be able to call the delegate : %s", err)
+	}
+
+	select {
+	case payload := <-received:
+		if payload.Domain != data.Domain || payload.Action != data.Action {
+			t.Errorf("Should get the domain and action : %s.%s", payload.Domain, payload.Action)
+		}
+
+		if string(payload.Params) != string(data.RawParams) {
+			t.Errorf("Should get the params : %s", payload.Params)
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Fatal("Should receive the delivery")
+	}
+
+	if err := wh.Shutdown(context.Background()); err != nil {
+		t.Errorf("Should be able to shutdown : %s", err)
+	}
+}
+
+func Test_Verify(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	body := []byte(`{"id":"1234"}`)
+
+	h := make(http.Header)
+	webhook.Sign(h, secret, now, body)
+
+	table := []struct {
+		name   string
+		secret string
+		now    time.Time
+		body   []byte
+		err    error
+	}{
+		{name: "valid", secret: secret, now: now, body: body, err: nil},
+		{name: "tampered", secret: secret, now: now, body: []byte(`{"id":"5678"}`), err: webhook.ErrInvalidSignature},
+		{name: "secret", secret: "other", now: now, body: body, err: webhook.ErrInvalidSignature},
+		{name: "replay", secret: secret, now: now.Add(10 * time.Minute), body: body, err: webhook.ErrTimestampExpired},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			err := webhook.Verify(h, tt.secret, tt.now, 5*time.Minute, tt.body)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Should get the expected error : got[%v] exp[%v]", err, tt.err)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+
+	if err := webhook.Verify(make(http.Header), secret, now, time.Minute, body); !errors.Is(err, webhook.ErrMissingSignature) {
+		t.Errorf("Should get a missing signature error : %v", err)
+	}
+}
+
+func Test_RetryExhausted(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	deadLetters := make(chan webhook.DeadLetter, 1)
+
+	wh := webhook.New(webhook.Config{
+		Log:         log,
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+		DeadLetter: func(ctx context.Context, dl webhook.DeadLetter) {
+			deadLetters <- dl
+		},
+	})
+	wh.Register(webhook.Endpoint{URL: srv.URL, Secret: secret})
+
+	if err := wh.Send(context.Background(), delegate.Data{Domain: "user", Action: "deleted"}); err != nil {
+		t.Fatalf("Should be able to send : %s", err)
+	}
+
+	select {
+	case dl := <-deadLetters:
+		if dl.Attempts != 3 {
+			t.Errorf("Should get three attempts : %d", dl.Attempts)
+		}
+
+		if dl.Err == nil {
+			t.Errorf("Should get the last delivery error")
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Fatal("Should dead letter the delivery")
+	}
+
+	if n := hits.Load(); n != 3 {
+		t.Errorf("Should hit the endpoint three times : %d", n)
+	}
+
+	if err := wh.Shutdown(context.Background()); err != nil {
+		t.Errorf("Should be able to shutdown : %s", err)
+	}
+}
+
+func Test_RetryBudget(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	deadLetters := make(chan webhook.DeadLetter, 2)
+
+	// The budget is shared by both endpoints and allows a single retry.
+	wh := webhook.New(webhook.Config{
+		Log:         log,
+		MaxAttempts: 5,
+		Backoff:     time.Millisecond,
+		RetryBudget: retry.NewBudget(0.001, 1),
+		DeadLetter: func(ctx context.Context, dl webhook.DeadLetter) {
+			deadLetters <- dl
+		},
+	})
+	wh.Register(webhook.Endpoint{URL: srv.URL, Secret: secret})
+	wh.Register(webhook.Endpoint{URL: srv.URL, Secret: secret})
+
+	if err := wh.Send(context.Background(), delegate.Data{Domain: "user", Action: "deleted"}); err != nil {
+		t.Fatalf("Should be able to send : %s", err)
+	}
+
+	var attempts int
+	for range 2 {
+		select {
+		case dl := <-deadLetters:
+			attempts += dl.Attempts
+
+		case <-time.After(5 * time.Second):
+			t.Fatal("Should dead letter the delivery")
+		}
+	}
+
+	if attempts != 3 {
+		t.Errorf("Should only retry once across the deliveries : %d attempts", attempts)
+	}
+
+	if n := hits.Load(); n != 3 {
+		t.Errorf("Should hit the endpoints three times : %d", n)
+	}
+
+	if err := wh.Shutdown(context.Background()); err != nil {
+		t.Errorf("Should be able to shutdown : %s", err)
+	}
+}
+
+func Test_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	wh := webhook.New(webhook.Config{Log: log})
+	wh.Register(webhook.Endpoint{URL: "http://localhost", Secret: secret})
+
+	for range 2 {
+		if err := wh.Shutdown(context.Background()); err != nil {
+			t.Errorf("Should be able to shutdown more than once : %s", err)
+		}
+	}
+
+	if err := wh.Send(context.Background(), delegate.Data{Domain: "user", Action: "updated"}); !errors.Is(err, webhook.ErrShutdown) {
+		t.Errorf("Should reject events sent after the shutdown : %v", err)
+	}
+}
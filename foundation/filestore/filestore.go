@@ -0,0 +1,68 @@
+// Package filestore implements the web.FileStorer interface. This implements
+// a store that writes uploaded files to the local disk.
+package filestore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// Local represents a file store that writes to a directory on disk.
+type Local struct {
+	root string
+}
+
+// NewLocal constructs a file store that writes to the specified directory,
+// creating it if it doesn't exist.
+func NewLocal(root string) (*Local, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("mkdir: %w", err)
+	}
+
+	return &Local{root: root}, nil
+}
+
+// Store writes the data to a new file. The client's filename is only used
+// for its extension so it can't be used to write outside the directory.
+// The location returned is the path to the file.
+func (l *Local) Store(ctx context.Context, filename string, contentType string, r io.Reader) (string, error) {
+	name := uuid.NewString() + filepath.Ext(filepath.Base(filename))
+	location := filepath.Join(l.root, name)
+
+	f, err := os.OpenFile(location, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("create: %w", err)
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(location)
+		return "", fmt.Errorf("copy: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(location)
+		return "", fmt.Errorf("close: %w", err)
+	}
+
+	return location, nil
+}
+
+// Delete removes the file at the location returned by Store. Only files in
+// the directory of the store can be removed.
+func (l *Local) Delete(ctx context.Context, location string) error {
+	if filepath.Dir(location) != filepath.Clean(l.root) {
+		return fmt.Errorf("location %q is not in the store", location)
+	}
+
+	if err := os.Remove(location); err != nil {
+		return fmt.Errorf("remove: %w", err)
+	}
+
+	return nil
+}
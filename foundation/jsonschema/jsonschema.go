@@ -0,0 +1,687 @@
+// Package jsonschema provides support for validating JSON documents against
+// a JSON Schema. Only the validation keywords needed to describe request
+// payloads are supported. A schema that uses any other keyword fails to
+// compile so a constraint is never silently ignored.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// annotations are keywords that describe the schema but don't take part in
+// validation.
+var annotations = map[string]bool{
+	"$schema":     true,
+	"$id":         true,
+	"$comment":    true,
+	"title":       true,
+	"description": true,
+	"default":     true,
+	"examples":    true,
+	"deprecated":  true,
+	"readOnly":    true,
+	"writeOnly":   true,
+}
+
+// formats are the supported values for the format keyword.
+var formats = map[string]func(s string) bool{
+	"email": func(s string) bool {
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	},
+	"uuid": regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`).MatchString,
+	"date-time": func(s string) bool {
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	},
+	"date": func(s string) bool {
+		_, err := time.Parse(time.DateOnly, s)
+		return err == nil
+	},
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != ""
+	},
+}
+
+// Violation describes a value in a document that doesn't satisfy the schema.
+// The pointer identifies the value as defined by RFC 6901.
+type Violation struct {
+	Pointer string
+	Message string
+}
+
+// Violations is the error returned when a document doesn't satisfy a schema.
+// It contains every violation found in the document.
+type Violations []Violation
+
+// Error implements the error interface.
+func (v Violations) Error() string {
+	msgs := make([]string, len(v))
+	for i, violation := range v {
+		msgs[i] = fmt.Sprintf("%s: %s", pointerName(violation.Pointer), violation.Message)
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Schema represents a compiled JSON Schema.
+type Schema struct {
+	reject        bool
+	types         []string
+	enum          []any
+	constant      any
+	hasConst      bool
+	properties    map[string]*Schema
+	required      []string
+	additional    *Schema
+	items         *Schema
+	minItems      *int
+	maxItems      *int
+	uniqueItems   bool
+	minLength     *int
+	maxLength     *int
+	pattern       *regexp.Regexp
+	format        string
+	minimum       *big.Rat
+	maximum       *big.Rat
+	exclusiveMin  *big.Rat
+	exclusiveMax  *big.Rat
+	allOf         []*Schema
+	anyOf         []*Schema
+	minProperties *int
+	maxProperties *int
+}
+
+// Compile parses the JSON Schema document. Patterns use the syntax of the
+// regexp package instead of ECMA 262.
+func Compile(data []byte) (*Schema, error) {
+	doc, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	return compile(doc, "")
+}
+
+// MustCompile parses the JSON Schema document and panics if it's invalid. It
+// simplifies the declaration of schemas known at compile time.
+func MustCompile(data []byte) *Schema {
+	s, err := Compile(data)
+	if err != nil {
+		panic(fmt.Sprintf("jsonschema: %s", err))
+	}
+
+	return s
+}
+
+// Validate checks the JSON document against the schema. A Violations error
+// is returned with every value that doesn't satisfy the schema. Any other
+// error means the document isn't valid JSON.
+func (s *Schema) Validate(data []byte) error {
+	doc, err := decode(data)
+	if err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	var violations Violations
+	s.validate(doc, "", &violations)
+
+	if len(violations) > 0 {
+		return violations
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+func compile(v any, path string) (*Schema, error) {
+	switch v := v.(type) {
+	case bool:
+		return &Schema{reject: !v}, nil
+
+	case map[string]any:
+		s := Schema{}
+		for key, value := range v {
+			if annotations[key] {
+				continue
+			}
+
+			if err := s.compileKeyword(key, value, path+"/"+key); err != nil {
+				var ce *compileError
+				if errors.As(err, &ce) {
+					return nil, err
+				}
+				return nil, &compileError{ptr: path + "/" + key, err: err}
+			}
+		}
+		return &s, nil
+	}
+
+	return nil, &compileError{ptr: path, err: errors.New("schema must be an object or a boolean")}
+}
+
+// compileError identifies the location in the schema that can't be compiled.
+type compileError struct {
+	ptr string
+	err error
+}
+
+func (e *compileError) Error() string {
+	return fmt.Sprintf("%s: %s", pointerName(e.ptr), e.err)
+}
+
+func (s *Schema) compileKeyword(key string, value any, path string) error {
+	var err error
+
+	switch key {
+	case "type":
+		s.types, err = compileTypes(value)
+
+	case "enum":
+		enum, ok := value.([]any)
+		if !ok || len(enum) == 0 {
+			return errors.New("must be a non-empty array")
+		}
+		s.enum = enum
+
+	case "const":
+		s.constant = value
+		s.hasConst = true
+
+	case "properties":
+		props, ok := value.(map[string]any)
+		if !ok {
+			return errors.New("must be an object")
+		}
+
+		s.properties = make(map[string]*Schema, len(props))
+		for name, prop := range props {
+			if s.properties[name], err = compile(prop, path+"/"+escape(name)); err != nil {
+				return err
+			}
+		}
+
+	case "required":
+		s.required, err = compileStrings(value)
+
+	case "additionalProperties":
+		s.additional, err = compile(value, path)
+
+	case "items":
+		s.items, err = compile(value, path)
+
+	case "allOf", "anyOf":
+		list, ok := value.([]any)
+		if !ok || len(list) == 0 {
+			return errors.New("must be a non-empty array")
+		}
+
+		schemas := make([]*Schema, len(list))
+		for i, item := range list {
+			if schemas[i], err = compile(item, fmt.Sprintf("%s/%d", path, i)); err != nil {
+				return err
+			}
+		}
+
+		if key == "allOf" {
+			s.allOf = schemas
+		} else {
+			s.anyOf = schemas
+		}
+
+	case "minItems":
+		s.minItems, err = compileCount(value)
+
+	case "maxItems":
+		s.maxItems, err = compileCount(value)
+
+	case "minLength":
+		s.minLength, err = compileCount(value)
+
+	case "maxLength":
+		s.maxLength, err = compileCount(value)
+
+	case "minProperties":
+		s.minProperties, err = compileCount(value)
+
+	case "maxProperties":
+		s.maxProperties, err = compileCount(value)
+
+	case "uniqueItems":
+		unique, ok := value.(bool)
+		if !ok {
+			return errors.New("must be a boolean")
+		}
+		s.uniqueItems = unique
+
+	case "pattern":
+		pattern, ok := value.(string)
+		if !ok {
+			return errors.New("must be a string")
+		}
+		s.pattern, err = regexp.Compile(pattern)
+
+	case "format":
+		format, ok := value.(string)
+		if !ok {
+			return errors.New("must be a string")
+		}
+		if _, exists := formats[format]; !exists {
+			return fmt.Errorf("unsupported format %q", format)
+		}
+		s.format = format
+
+	case "minimum":
+		s.minimum, err = compileNumber(value)
+
+	case "maximum":
+		s.maximum, err = compileNumber(value)
+
+	case "exclusiveMinimum":
+		s.exclusiveMin, err = compileNumber(value)
+
+	case "exclusiveMaximum":
+		s.exclusiveMax, err = compileNumber(value)
+
+	default:
+		return errors.New("unsupported keyword")
+	}
+
+	return err
+}
+
+func compileTypes(value any) ([]string, error) {
+	valid := func(t string) bool {
+		switch t {
+		case "null", "boolean", "object", "array", "number", "integer", "string":
+			return true
+		}
+		return false
+	}
+
+	if t, ok := value.(string); ok {
+		if !valid(t) {
+			return nil, fmt.Errorf("unknown type %q", t)
+		}
+		return []string{t}, nil
+	}
+
+	types, err := compileStrings(value)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range types {
+		if !valid(t) {
+			return nil, fmt.Errorf("unknown type %q", t)
+		}
+	}
+
+	return types, nil
+}
+
+func compileStrings(value any) ([]string, error) {
+	list, ok := value.([]any)
+	if !ok {
+		return nil, errors.New("must be an array of strings")
+	}
+
+	strs := make([]string, len(list))
+	for i, item := range list {
+		str, ok := item.(string)
+		if !ok {
+			return nil, errors.New("must be an array of strings")
+		}
+		strs[i] = str
+	}
+
+	return strs, nil
+}
+
+func compileCount(value any) (*int, error) {
+	n, ok := toRat(value)
+	if !ok || !n.IsInt() || n.Sign() < 0 || !n.Num().IsInt64() {
+		return nil, errors.New("must be a non-negative integer")
+	}
+
+	count := int(n.Num().Int64())
+	return &count, nil
+}
+
+func compileNumber(value any) (*big.Rat, error) {
+	n, ok := toRat(value)
+	if !ok {
+		return nil, errors.New("must be a number")
+	}
+
+	return n, nil
+}
+
+// =============================================================================
+
+func (s *Schema) validate(v any, ptr string, violations *Violations) {
+	add := func(format string, args ...any) {
+		*violations = append(*violations, Violation{Pointer: ptr, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.reject {
+		add("is not allowed")
+		return
+	}
+
+	// When the type is wrong the other keywords would only add noise.
+	if len(s.types) > 0 && !matchesType(v, s.types) {
+		add("must be of type %s", strings.Join(s.types, " or "))
+		return
+	}
+
+	if s.hasConst && !equal(v, s.constant) {
+		add("must be %s", encode(s.constant))
+	}
+
+	if len(s.enum) > 0 && !contains(s.enum, v) {
+		values := make([]string, len(s.enum))
+		for i, e := range s.enum {
+			values[i] = encode(e)
+		}
+		add("must be one of %s", strings.Join(values, ", "))
+	}
+
+	for _, sub := range s.allOf {
+		sub.validate(v, ptr, violations)
+	}
+
+	if len(s.anyOf) > 0 {
+		var matched bool
+		for _, sub := range s.anyOf {
+			var subViolations Violations
+			sub.validate(v, ptr, &subViolations)
+
+			if len(subViolations) == 0 {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			add("must match at least one of the allowed schemas")
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		s.validateString(v, add)
+
+	case json.Number:
+		s.validateNumber(v, add)
+
+	case []any:
+		s.validateArray(v, ptr, add, violations)
+
+	case map[string]any:
+		s.validateObject(v, ptr, add, violations)
+	}
+}
+
+func (s *Schema) validateString(v string, add func(string, ...any)) {
+	length := utf8.RuneCountInString(v)
+
+	if s.minLength != nil && length < *s.minLength {
+		add("must be at least %d characters", *s.minLength)
+	}
+
+	if s.maxLength != nil && length > *s.maxLength {
+		add("must be at most %d characters", *s.maxLength)
+	}
+
+	if s.pattern != nil && !s.pattern.MatchString(v) {
+		add("must match the pattern %s", s.pattern)
+	}
+
+	if s.format != "" && !formats[s.format](v) {
+		add("must be a valid %s", s.format)
+	}
+}
+
+func (s *Schema) validateNumber(v json.Number, add func(string, ...any)) {
+	n, ok := toRat(v)
+	if !ok {
+		return
+	}
+
+	if s.minimum != nil && n.Cmp(s.minimum) < 0 {
+		add("must be greater than or equal to %s", s.minimum.RatString())
+	}
+
+	if s.maximum != nil && n.Cmp(s.maximum) > 0 {
+		add("must be less than or equal to %s", s.maximum.RatString())
+	}
+
+	if s.exclusiveMin != nil && n.Cmp(s.exclusiveMin) <= 0 {
+		add("must be greater than %s", s.exclusiveMin.RatString())
+	}
+
+	if s.exclusiveMax != nil && n.Cmp(s.exclusiveMax) >= 0 {
+		add("must be less than %s", s.exclusiveMax.RatString())
+	}
+}
+
+func (s *Schema) validateArray(v []any, ptr string, add func(string, ...any), violations *Violations) {
+	if s.minItems != nil && len(v) < *s.minItems {
+		add("must contain at least %d items", *s.minItems)
+	}
+
+	if s.maxItems != nil && len(v) > *s.maxItems {
+		add("must contain at most %d items", *s.maxItems)
+	}
+
+	if s.uniqueItems {
+	unique:
+		for i := range v {
+			for j := i + 1; j < len(v); j++ {
+				if equal(v[i], v[j]) {
+					add("must contain unique items")
+					break unique
+				}
+			}
+		}
+	}
+
+	if s.items != nil {
+		for i, item := range v {
+			s.items.validate(item, fmt.Sprintf("%s/%d", ptr, i), violations)
+		}
+	}
+}
+
+func (s *Schema) validateObject(v map[string]any, ptr string, add func(string, ...any), violations *Violations) {
+	if s.minProperties != nil && len(v) < *s.minProperties {
+		add("must contain at least %d properties", *s.minProperties)
+	}
+
+	if s.maxProperties != nil && len(v) > *s.maxProperties {
+		add("must contain at most %d properties", *s.maxProperties)
+	}
+
+	for _, name := range s.required {
+		if _, exists := v[name]; !exists {
+			*violations = append(*violations, Violation{Pointer: ptr + "/" + escape(name), Message: "is required"})
+		}
+	}
+
+	// Validate the properties in order so the violations are reported in a
+	// stable order.
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propPtr := ptr + "/" + escape(name)
+
+		if prop, exists := s.properties[name]; exists {
+			prop.validate(v[name], propPtr, violations)
+			continue
+		}
+
+		if s.additional != nil {
+			s.additional.validate(v[name], propPtr, violations)
+		}
+	}
+}
+
+// =============================================================================
+
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after the document")
+	}
+
+	return v, nil
+}
+
+func matchesType(v any, types []string) bool {
+	for _, t := range types {
+		switch t {
+		case "null":
+			if v == nil {
+				return true
+			}
+
+		case "boolean":
+			if _, ok := v.(bool); ok {
+				return true
+			}
+
+		case "object":
+			if _, ok := v.(map[string]any); ok {
+				return true
+			}
+
+		case "array":
+			if _, ok := v.([]any); ok {
+				return true
+			}
+
+		case "string":
+			if _, ok := v.(string); ok {
+				return true
+			}
+
+		case "number":
+			if _, ok := v.(json.Number); ok {
+				return true
+			}
+
+		case "integer":
+			if n, ok := toRat(v); ok && n.IsInt() {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func toRat(v any) (*big.Rat, bool) {
+	num, ok := v.(json.Number)
+	if !ok {
+		return nil, false
+	}
+
+	return new(big.Rat).SetString(string(num))
+}
+
+func contains(list []any, v any) bool {
+	for _, item := range list {
+		if equal(item, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// equal compares two decoded values, treating numbers with the same value
+// as equal regardless of how they were written.
+func equal(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		ra, okA := toRat(a)
+		rb, okB := toRat(b)
+		return okA && okB && ra.Cmp(rb) == 0
+
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, value := range a {
+			other, exists := b[key]
+			if !exists || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return a == b
+}
+
+func encode(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(data)
+}
+
+var escaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func escape(name string) string {
+	return escaper.Replace(name)
+}
+
+func pointerName(ptr string) string {
+	if ptr == "" {
+		return "(root)"
+	}
+
+	return ptr
+}
@@ -0,0 +1,142 @@
+package jsonschema_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/jsonschema"
+	"github.com/google/go-cmp/cmp"
+)
+
+const userSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"title": "new user",
+	"type": "object",
+	"required": ["name", "email", "roles"],
+	"additionalProperties": false,
+	"properties": {
+		"name": {"type": "string", "minLength": 3, "maxLength": 50},
+		"email": {"type": "string", "format": "email"},
+		"roles": {"type": "array", "minItems": 1, "uniqueItems": true, "items": {"enum": ["ADMIN", "USER"]}},
+		"age": {"type": "integer", "minimum": 18, "exclusiveMaximum": 130},
+		"department": {"type": ["string", "null"], "pattern": "^[A-Z]+$"},
+		"id": {"type": "integer", "maximum": 9223372036854775807}
+	}
+}`
+
+func Test_Validate(t *testing.T) {
+	t.Parallel()
+
+	schema, err := jsonschema.Compile([]byte(userSchema))
+	if err != nil {
+		t.Fatalf("Should be able to compile the schema : %s", err)
+	}
+
+	table := []struct {
+		name string
+		doc  string
+		exp  jsonschema.Violations
+	}{
+		{
+			name: "valid",
+			doc:  `{"name":"Bill Kennedy","email":"bill@example.com","roles":["ADMIN","USER"],"age":45,"department":null,"id":9223372036854775807}`,
+		},
+		{
+			name: "multiple",
+			doc:  `{"name":"Bi","email":"bill","roles":["USER","USER","OWNER"],"age":17.5,"department":"it","id":9223372036854775808,"extra":true}`,
+			exp: jsonschema.Violations{
+				{Pointer: "/age", Message: "must be of type integer"},
+				{Pointer: "/department", Message: "must match the pattern ^[A-Z]+$"},
+				{Pointer: "/email", Message: "must be a valid email"},
+				{Pointer: "/extra", Message: "is not allowed"},
+				{Pointer: "/id", Message: "must be less than or equal to 9223372036854775807"},
+				{Pointer: "/name", Message: "must be at least 3 characters"},
+				{Pointer: "/roles", Message: "must contain unique items"},
+				{Pointer: "/roles/2", Message: `must be one of "ADMIN", "USER"`},
+			},
+		},
+		{
+			name: "required",
+			doc:  `{"email":"bill@example.com"}`,
+			exp: jsonschema.Violations{
+				{Pointer: "/name", Message: "is required"},
+				{Pointer: "/roles", Message: "is required"},
+			},
+		},
+		{
+			name: "type",
+			doc:  `["bill"]`,
+			exp: jsonschema.Violations{
+				{Pointer: "", Message: "must be of type object"},
+			},
+		},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			err := schema.Validate([]byte(tt.doc))
+
+			if tt.exp == nil {
+				if err != nil {
+					t.Fatalf("Should be able to validate the document : %s", err)
+				}
+				return
+			}
+
+			var got jsonschema.Violations
+			if !errors.As(err, &got) {
+				t.Fatalf("Should get the violations : %v", err)
+			}
+
+			if diff := cmp.Diff(got, tt.exp); diff != "" {
+				t.Errorf("Should get the expected violations, diff:\n%s", diff)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
+
+func Test_ValidateInvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	schema := jsonschema.MustCompile([]byte(`{"type":"object"}`))
+
+	err := schema.Validate([]byte(`{"name":`))
+
+	var violations jsonschema.Violations
+	if err == nil || errors.As(err, &violations) {
+		t.Fatalf("Should get a decode error : %v", err)
+	}
+}
+
+func Test_Compile(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name   string
+		schema string
+		exp    string
+	}{
+		{name: "keyword", schema: `{"properties":{"name":{"$ref":"#/defs/name"}}}`, exp: "/properties/name/$ref: unsupported keyword"},
+		{name: "type", schema: `{"type":"text"}`, exp: `/type: unknown type "text"`},
+		{name: "format", schema: `{"format":"ipv4"}`, exp: `/format: unsupported format "ipv4"`},
+		{name: "count", schema: `{"minLength":-1}`, exp: "/minLength: must be a non-negative integer"},
+		{name: "schema", schema: `{"items":"string"}`, exp: "/items: schema must be an object or a boolean"},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			_, err := jsonschema.Compile([]byte(tt.schema))
+			if err == nil {
+				t.Fatalf("Should not be able to compile the schema")
+			}
+
+			if err.Error() != tt.exp {
+				t.Errorf("Should identify the problem, got %q, exp %q", err, tt.exp)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
@@ -0,0 +1,94 @@
+// Package lifecycle provides support for the orderly shutdown of the
+// components of a service.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HookFn defines a function that releases the resources of a component. It
+// should return when the context is canceled.
+type HookFn func(ctx context.Context) error
+
+type hook struct {
+	name    string
+	fn      HookFn
+	timeout time.Duration
+}
+
+// Hooks is a registry of the shutdown hooks for the components of a service.
+// Components register a hook as they are started and the hooks are run in
+// reverse order, so a component is shut down before the components it
+// depends on.
+type Hooks struct {
+	mu      sync.Mutex
+	hooks   []hook
+	timeout time.Duration
+}
+
+// New constructs a registry where every hook is given up to the specified
+// timeout to finish, unless it's registered with its own.
+func New(timeout time.Duration) *Hooks {
+	return &Hooks{
+		timeout: timeout,
+	}
+}
+
+// Register adds a hook for the named component.
+func (h *Hooks) Register(name string, fn HookFn) {
+	h.RegisterWithTimeout(name, h.timeout, fn)
+}
+
+// RegisterWithTimeout adds a hook for the named component that is given up to
+// the specified timeout to finish.
+func (h *Hooks) RegisterWithTimeout(name string, timeout time.Duration, fn HookFn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.hooks = append(h.hooks, hook{name: name, fn: fn, timeout: timeout})
+}
+
+// Shutdown runs the hooks in the reverse order they were registered. A hook
+// that doesn't finish within its timeout is abandoned so it can't hold up
+// the hooks after it. Every hook is run once, even when an earlier one
+// fails, and the errors are returned together.
+func (h *Hooks) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	hooks := h.hooks
+	h.hooks = nil
+	h.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].run(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (hk hook) run(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, hk.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- hk.fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("%s: %w", hk.name, err)
+		}
+		return nil
+
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", hk.name, ctx.Err())
+	}
+}
@@ -0,0 +1,83 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/lifecycle"
+)
+
+func Test_ShutdownOrder(t *testing.T) {
+	t.Parallel()
+
+	hooks := lifecycle.New(time.Second)
+
+	var order []string
+	for _, name := range []string{"database", "tracer", "worker"} {
+		hooks.Register(name, func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	if err := hooks.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Should be able to shutdown : %s", err)
+	}
+
+	exp := []string{"worker", "tracer", "database"}
+	if !slices.Equal(order, exp) {
+		t.Errorf("Should run the hooks in reverse order, got %v, exp %v", order, exp)
+	}
+
+	// -------------------------------------------------------------------------
+
+	order = nil
+
+	if err := hooks.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Should be able to shutdown again : %s", err)
+	}
+
+	if len(order) != 0 {
+		t.Errorf("Should only run the hooks once, got %v", order)
+	}
+}
+
+func Test_ShutdownTimeout(t *testing.T) {
+	t.Parallel()
+
+	hooks := lifecycle.New(time.Second)
+
+	errFailed := errors.New("failed")
+
+	var ran bool
+	hooks.Register("database", func(ctx context.Context) error {
+		ran = true
+		return errFailed
+	})
+
+	hooks.RegisterWithTimeout("hung", 50*time.Millisecond, func(ctx context.Context) error {
+		select {}
+	})
+
+	start := time.Now()
+	err := hooks.Shutdown(context.Background())
+
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Fatalf("Should bound a hung hook by its timeout, took %v", d)
+	}
+
+	if !ran {
+		t.Errorf("Should run the hooks after a hung hook")
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Should report the hung hook : %v", err)
+	}
+
+	if !errors.Is(err, errFailed) {
+		t.Errorf("Should report the failed hook : %v", err)
+	}
+}
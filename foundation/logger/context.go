@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+type ctxKey int
+
+const (
+	valuesKey ctxKey = 1
+	fieldsKey ctxKey = 2
+)
+
+// WithValues returns a context holding the key/value pairs that are added
+// to every log line written with the context, like the id of a request.
+func WithValues(ctx context.Context, args ...any) context.Context {
+	values, _ := ctx.Value(valuesKey).([]any)
+
+	all := make([]any, 0, len(values)+len(args))
+	all = append(all, values...)
+	all = append(all, args...)
+
+	return context.WithValue(ctx, valuesKey, all)
+}
+
+// fields holds the key/value pairs added further down the call chain.
+type fields struct {
+	mu   sync.Mutex
+	args []any
+}
+
+// WithFields returns a context that collects the key/value pairs added with
+// AddFields by the functions it's passed to. Once added, they're on every
+// log line written with the context, so values only known deeper in the call
+// chain, like the authenticated user, are also on the lines logged by the
+// function that started it.
+func WithFields(ctx context.Context) context.Context {
+	return context.WithValue(ctx, fieldsKey, &fields{})
+}
+
+// AddFields adds the key/value pairs to the fields collected by the context.
+// When the context doesn't collect fields, the pairs are added as values of
+// the returned context instead.
+func AddFields(ctx context.Context, args ...any) context.Context {
+	f, ok := ctx.Value(fieldsKey).(*fields)
+	if !ok {
+		return WithValues(ctx, args...)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.args = append(f.args, args...)
+
+	return ctx
+}
+
+func getValues(ctx context.Context) []any {
+	values, _ := ctx.Value(valuesKey).([]any)
+
+	f, ok := ctx.Value(fieldsKey).(*fields)
+	if !ok {
+		return values
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.args) == 0 {
+		return values
+	}
+
+	all := make([]any, 0, len(values)+len(f.args))
+	all = append(all, values...)
+	all = append(all, f.args...)
+
+	return all
+}
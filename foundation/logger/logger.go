@@ -21,6 +21,8 @@ type TraceIDFn func(ctx context.Context) string
 type Logger struct {
 	handler   slog.Handler
 	traceIDFn TraceIDFn
+	level     *slog.LevelVar
+	sampler   *sampler
 }
 
 // New constructs a new log for application use.
@@ -34,9 +36,16 @@ func NewWithEvents(w io.Writer, minLevel Level, serviceName string, traceIDFn Tr
 }
 
 // NewWithHandler returns a new log for application use with the underlying
-// handler.
+// handler. The handler may still filter records below its own level after
+// the level of the logger is lowered.
 func NewWithHandler(h slog.Handler) *Logger {
-	return &Logger{handler: h}
+	var level slog.LevelVar
+	level.Set(slog.LevelDebug)
+
+	return &Logger{
+		handler: h,
+		level:   &level,
+	}
 }
 
 // NewStdLogger returns a standard library Logger that wraps the slog Logger.
@@ -44,6 +53,18 @@ func NewStdLogger(logger *Logger, level Level) *log.Logger {
 	return slog.NewLogLogger(logger.handler, slog.Level(level))
 }
 
+// SetLevel atomically changes the minimum level that is logged. It's safe to
+// call while the logger is in use, which allows the verbosity to be raised
+// temporarily without a restart.
+func (log *Logger) SetLevel(level Level) {
+	log.level.Set(slog.Level(level))
+}
+
+// Level returns the minimum level that is logged.
+func (log *Logger) Level() Level {
+	return Level(log.level.Level())
+}
+
 // Debug logs at LevelDebug with the given context.
 func (log *Logger) Debug(ctx context.Context, msg string, args ...any) {
 	log.write(ctx, LevelDebug, 3, msg, args...)
@@ -87,7 +108,7 @@ func (log *Logger) Errorc(ctx context.Context, caller int, msg string, args ...a
 func (log *Logger) write(ctx context.Context, level Level, caller int, msg string, args ...any) {
 	slogLevel := slog.Level(level)
 
-	if !log.handler.Enabled(ctx, slogLevel) {
+	if slogLevel < log.level.Level() || !log.handler.Enabled(ctx, slogLevel) {
 		return
 	}
 
@@ -100,6 +121,7 @@ func (log *Logger) write(ctx context.Context, level Level, caller int, msg strin
 		args = append(args, "trace_id", log.traceIDFn(ctx))
 	}
 	r.Add(args...)
+	r.Add(getValues(ctx)...)
 
 	log.handler.Handle(ctx, r)
 }
@@ -119,8 +141,12 @@ func new(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, e
 		return a
 	}
 
+	// Use a level variable so the level can be changed at runtime.
+	var level slog.LevelVar
+	level.Set(slog.Level(minLevel))
+
 	// Construct the slog JSON handler for use.
-	handler := slog.Handler(slog.NewJSONHandler(w, &slog.HandlerOptions{AddSource: true, Level: slog.Level(minLevel), ReplaceAttr: f}))
+	handler := slog.Handler(slog.NewJSONHandler(w, &slog.HandlerOptions{AddSource: true, Level: &level, ReplaceAttr: f}))
 
 	// If events are to be processed, wrap the JSON handler around the custom
 	// log handler.
@@ -139,5 +165,6 @@ func new(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, e
 	return &Logger{
 		handler:   handler,
 		traceIDFn: traceIDFn,
+		level:     &level,
 	}
 }
@@ -0,0 +1,143 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_SetLevel(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	ctx := context.Background()
+
+	log.Debug(ctx, "first")
+	if strings.Contains(buf.String(), "first") {
+		t.Fatalf("Should not log debug lines at the info level")
+	}
+
+	log.SetLevel(logger.LevelDebug)
+
+	if log.Level() != logger.LevelDebug {
+		t.Errorf("Should get the debug level : %v", log.Level())
+	}
+
+	log.Debug(ctx, "second")
+	if !strings.Contains(buf.String(), "second") {
+		t.Errorf("Should log debug lines after setting the debug level")
+	}
+
+	log.SetLevel(logger.LevelInfo)
+
+	log.Debug(ctx, "third")
+	if strings.Contains(buf.String(), "third") {
+		t.Errorf("Should not log debug lines after resetting the level")
+	}
+
+	log.Info(ctx, "fourth")
+	if !strings.Contains(buf.String(), "fourth") {
+		t.Errorf("Should still log info lines")
+	}
+}
+
+func Test_SetLevelWithHandler(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	h := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	log := logger.NewWithHandler(h)
+
+	ctx := context.Background()
+
+	log.Debug(ctx, "first")
+	if !strings.Contains(buf.String(), "first") {
+		t.Fatalf("Should log debug lines the handler allows")
+	}
+
+	log.SetLevel(logger.LevelWarn)
+
+	log.Info(ctx, "second")
+	if strings.Contains(buf.String(), "second") {
+		t.Errorf("Should not log info lines after raising the level")
+	}
+}
+
+func Test_Sampled(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	sampled := log.Sampled(logger.SampleConfig{
+		First:    3,
+		Interval: 200 * time.Millisecond,
+	})
+
+	ctx := context.Background()
+
+	for range 10 {
+		sampled.Info(ctx, "repeated")
+	}
+	sampled.Error(ctx, "repeated")
+	sampled.Info(ctx, "other")
+
+	if n := strings.Count(buf.String(), `"msg":"repeated"`); n != 4 {
+		t.Errorf("Should log the first 3 info and the error record : %d", n)
+	}
+
+	if n := strings.Count(buf.String(), `"msg":"other"`); n != 1 {
+		t.Errorf("Should log a different message : %d", n)
+	}
+
+	if n := sampled.Dropped(); n != 7 {
+		t.Errorf("Should count the dropped records : %d", n)
+	}
+
+	time.Sleep(250 * time.Millisecond)
+
+	sampled.Info(ctx, "repeated")
+
+	if n := strings.Count(buf.String(), `"msg":"repeated"`); n != 5 {
+		t.Errorf("Should log the message again in the next interval : %d", n)
+	}
+}
+
+func Test_Fields(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	ctx := logger.WithFields(context.Background())
+
+	inner := func(ctx context.Context) {
+		ctx = logger.AddFields(ctx, "user_id", "1234")
+		log.Info(ctx, "inner")
+	}
+	inner(ctx)
+
+	log.Info(ctx, "outer")
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if !strings.Contains(line, `"user_id":"1234"`) {
+			t.Errorf("Should log the field added down the call chain : %s", line)
+		}
+	}
+
+	buf.Reset()
+
+	ctx = logger.AddFields(context.Background(), "user_id", "5678")
+	log.Info(ctx, "values")
+
+	if !strings.Contains(buf.String(), `"user_id":"5678"`) {
+		t.Errorf("Should add the field as a value without a collecting context : %s", buf.String())
+	}
+}
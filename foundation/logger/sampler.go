@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SampleConfig represents the settings for sampling repeated log messages.
+// The first N records with the same message and level are logged in each
+// interval and the rest are dropped.
+type SampleConfig struct {
+	First    int
+	Interval time.Duration
+}
+
+type sampleKey struct {
+	level slog.Level
+	msg   string
+}
+
+type sampleCount struct {
+	start time.Time
+	n     int
+}
+
+// sampler tracks how many times a message has been logged in the current
+// interval. It's shared by all the handlers derived from the same logger.
+type sampler struct {
+	first    int
+	interval time.Duration
+	mu       sync.Mutex
+	counts   map[sampleKey]*sampleCount
+	dropped  atomic.Int64
+}
+
+func newSampler(cfg SampleConfig) *sampler {
+	return &sampler{
+		first:    cfg.First,
+		interval: cfg.Interval,
+		counts:   make(map[sampleKey]*sampleCount),
+	}
+}
+
+// allow reports if the record should be logged and counts it as dropped
+// when it's not.
+func (s *sampler) allow(r slog.Record) bool {
+	key := sampleKey{level: r.Level, msg: r.Message}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, exists := s.counts[key]
+	if !exists || r.Time.Sub(c.start) >= s.interval {
+
+		// Forget the messages from old intervals so the map doesn't grow
+		// with every unique message ever logged.
+		if !exists && len(s.counts) >= 10_000 {
+			s.counts = make(map[sampleKey]*sampleCount)
+		}
+
+		c = &sampleCount{start: r.Time}
+		s.counts[key] = c
+	}
+
+	c.n++
+	if c.n > s.first {
+		s.dropped.Add(1)
+		return false
+	}
+
+	return true
+}
+
+// sampleHandler provides a wrapper around the slog handler to drop records
+// that exceed the sample.
+type sampleHandler struct {
+	handler slog.Handler
+	sampler *sampler
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *sampleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// WithAttrs returns a new handler whose attributes consists of h's
+// attributes followed by attrs.
+func (h *sampleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &sampleHandler{handler: h.handler.WithAttrs(attrs), sampler: h.sampler}
+}
+
+// WithGroup returns a new handler with the given group appended to the
+// receiver's existing groups.
+func (h *sampleHandler) WithGroup(name string) slog.Handler {
+	return &sampleHandler{handler: h.handler.WithGroup(name), sampler: h.sampler}
+}
+
+// Handle drops the record if it's over the sample, otherwise the record is
+// passed to the wrapped handler.
+func (h *sampleHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.sampler.allow(r) {
+		return nil
+	}
+
+	return h.handler.Handle(ctx, r)
+}
+
+// =============================================================================
+
+// Sampled returns a logger that logs the first N records with the same
+// message and level in each interval and drops the rest. The new logger
+// shares the level of the original logger.
+func (log *Logger) Sampled(cfg SampleConfig) *Logger {
+	s := newSampler(cfg)
+
+	return &Logger{
+		handler:   &sampleHandler{handler: log.handler, sampler: s},
+		traceIDFn: log.traceIDFn,
+		level:     log.level,
+		sampler:   s,
+	}
+}
+
+// Dropped returns the number of records dropped by sampling.
+func (log *Logger) Dropped() int64 {
+	if log.sampler == nil {
+		return 0
+	}
+
+	return log.sampler.dropped.Load()
+}
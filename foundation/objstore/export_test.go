@@ -0,0 +1,8 @@
+package objstore
+
+import "time"
+
+// SetNow allows tests to control the time used to sign a URL.
+func SetNow(s3 *S3, now func() time.Time) {
+	s3.now = now
+}
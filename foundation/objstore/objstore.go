@@ -0,0 +1,225 @@
+// Package objstore provides support for generating presigned URLs so clients
+// can read and write objects directly with an S3 compatible object store.
+package objstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxExpiry is the longest time a presigned URL can be valid for.
+const maxExpiry = 7 * 24 * time.Hour
+
+const algorithm = "AWS4-HMAC-SHA256"
+
+// Presigner represents behavior for generating time-limited URLs that
+// allow a client to access an object without proxying through the service.
+type Presigner interface {
+	PresignGet(ctx context.Context, key string) (string, error)
+	PresignPut(ctx context.Context, key string, contentType string) (string, error)
+}
+
+// Signer represents behavior for signing the string to sign for a request
+// made on the specified date to the specified region. The region is the one
+// the presigner was configured with, so the signing key always matches the
+// credential scope. The signature is returned hex encoded.
+type Signer interface {
+	Sign(date time.Time, region string, stringToSign string) (string, error)
+}
+
+// Config represents the settings for generating presigned URLs.
+type Config struct {
+	Endpoint    string
+	Region      string
+	Bucket      string
+	AccessKeyID string
+	Expiry      time.Duration
+	PathStyle   bool
+	Signer      Signer
+}
+
+// S3 generates presigned URLs for an S3 compatible object store.
+type S3 struct {
+	endpoint    *url.URL
+	region      string
+	bucket      string
+	accessKeyID string
+	expiry      time.Duration
+	pathStyle   bool
+	signer      Signer
+	now         func() time.Time
+}
+
+// NewS3 constructs a presigner for an S3 compatible object store. Path style
+// URLs should be used for stores like MinIO that don't support buckets as
+// sub-domains.
+func NewS3(cfg Config) (*S3, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parse endpoint: %w", err)
+	}
+
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("endpoint %q must include a scheme and host", cfg.Endpoint)
+	}
+
+	if cfg.Bucket == "" {
+		return nil, errors.New("bucket is required")
+	}
+
+	if cfg.Expiry <= 0 || cfg.Expiry > maxExpiry {
+		return nil, fmt.Errorf("expiry %s must be between 1s and %s", cfg.Expiry, maxExpiry)
+	}
+
+	if cfg.Signer == nil {
+		return nil, errors.New("signer is required")
+	}
+
+	s3 := S3{
+		endpoint:    endpoint,
+		region:      cfg.Region,
+		bucket:      cfg.Bucket,
+		accessKeyID: cfg.AccessKeyID,
+		expiry:      cfg.Expiry,
+		pathStyle:   cfg.PathStyle,
+		signer:      cfg.Signer,
+		now:         time.Now,
+	}
+
+	return &s3, nil
+}
+
+// PresignGet returns a URL that allows the object to be downloaded until
+// the configured expiry.
+func (s3 *S3) PresignGet(ctx context.Context, key string) (string, error) {
+	return s3.presign("GET", key, "")
+}
+
+// PresignPut returns a URL that allows the object to be uploaded until the
+// configured expiry. The client must send the same content type.
+func (s3 *S3) PresignPut(ctx context.Context, key string, contentType string) (string, error) {
+	return s3.presign("PUT", key, contentType)
+}
+
+// presign implements the AWS Signature Version 4 query string authentication.
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sigv4-query-string-auth.html
+func (s3 *S3) presign(method string, key string, contentType string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" {
+		return "", errors.New("key is required")
+	}
+
+	now := s3.now().UTC()
+	date := now.Format("20060102")
+	amzDate := now.Format("20060102T150405Z")
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, s3.region)
+
+	host := s3.endpoint.Host
+	path := "/" + encodePath(key)
+
+	switch {
+	case s3.pathStyle:
+		path = "/" + s3.bucket + path
+
+	default:
+		host = s3.bucket + "." + host
+	}
+
+	headers := map[string]string{
+		"host": host,
+	}
+
+	if contentType != "" {
+		headers["content-type"] = contentType
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", algorithm)
+	query.Set("X-Amz-Credential", s3.accessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(s3.expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+
+	canonicalQuery := strings.ReplaceAll(query.Encode(), "+", "%20")
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		path,
+		canonicalQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+
+	hash := sha256.Sum256([]byte(canonicalRequest))
+
+	stringToSign := strings.Join([]string{
+		algorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	signature, err := s3.signer.Sign(now, s3.region, stringToSign)
+	if err != nil {
+		return "", fmt.Errorf("sign: %w", err)
+	}
+
+	u := fmt.Sprintf("%s://%s%s?%s&X-Amz-Signature=%s", s3.endpoint.Scheme, host, path, canonicalQuery, signature)
+
+	return u, nil
+}
+
+// encodePath escapes the key as required by the canonical request while
+// preserving the slashes. Signature Version 4 only leaves the unreserved
+// characters A-Z, a-z, 0-9, '-', '_', '.' and '~' as they are, which is
+// stricter than url.PathEscape.
+func encodePath(key string) string {
+	const upperhex = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+
+		switch {
+		case c == '/', unreserved(c):
+			b.WriteByte(c)
+
+		default:
+			b.WriteByte('%')
+			b.WriteByte(upperhex[c>>4])
+			b.WriteByte(upperhex[c&0xF])
+		}
+	}
+
+	return b.String()
+}
+
+func unreserved(c byte) bool {
+	switch {
+	case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9':
+		return true
+	}
+
+	return c == '-' || c == '_' || c == '.' || c == '~'
+}
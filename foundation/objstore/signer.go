@@ -0,0 +1,37 @@
+package objstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// V4Signer implements the Signer interface using the AWS Signature Version 4
+// signing key derived from a secret access key.
+type V4Signer struct {
+	secretKey string
+}
+
+// NewV4Signer constructs a signer for the specified secret key.
+func NewV4Signer(secretKey string) V4Signer {
+	return V4Signer{
+		secretKey: secretKey,
+	}
+}
+
+// Sign implements the Signer interface.
+func (s V4Signer) Sign(date time.Time, region string, stringToSign string) (string, error) {
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), date.UTC().Format("20060102"))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign)), nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,181 @@
+package otel
+
+import (
+	"strconv"
+	"time"
+)
+
+// These types represent the OTLP JSON encoding of the metrics request.
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+
+// aggregationTemporalityCumulative reports the values since the exporter
+// was started.
+const aggregationTemporalityCumulative = 2
+
+type exportRequest struct {
+	ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resource       `json:"resource"`
+	ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scopeMetrics struct {
+	Scope   scope    `json:"scope"`
+	Metrics []metric `json:"metrics"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type metric struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Unit        string     `json:"unit,omitempty"`
+	Sum         *sum       `json:"sum,omitempty"`
+	Histogram   *histogram `json:"histogram,omitempty"`
+}
+
+type sum struct {
+	DataPoints             []numberDataPoint `json:"dataPoints"`
+	AggregationTemporality int               `json:"aggregationTemporality"`
+	IsMonotonic            bool              `json:"isMonotonic"`
+}
+
+type numberDataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	AsInt             string     `json:"asInt"`
+}
+
+type histogram struct {
+	DataPoints             []histogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                  `json:"aggregationTemporality"`
+}
+
+type histogramDataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	Count             string     `json:"count"`
+	Sum               float64    `json:"sum"`
+	BucketCounts      []string   `json:"bucketCounts"`
+	ExplicitBounds    []float64  `json:"explicitBounds"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+// =============================================================================
+
+// collect takes a snapshot of the metrics in the OTLP format.
+func (e *Exporter) collect() exportRequest {
+	start := nanos(e.start)
+	now := nanos(time.Now())
+
+	e.mu.Lock()
+	counters := append([]*Counter(nil), e.counters...)
+	histograms := append([]*Histogram(nil), e.histograms...)
+	e.mu.Unlock()
+
+	var metrics []metric
+
+	for _, c := range counters {
+		c.mu.Lock()
+		s := sum{
+			AggregationTemporality: aggregationTemporalityCumulative,
+			IsMonotonic:            true,
+		}
+		for _, p := range c.values {
+			s.DataPoints = append(s.DataPoints, numberDataPoint{
+				Attributes:        toKeyValues(p.attrs),
+				StartTimeUnixNano: start,
+				TimeUnixNano:      now,
+				AsInt:             strconv.FormatInt(p.value, 10),
+			})
+		}
+		c.mu.Unlock()
+
+		metrics = append(metrics, metric{
+			Name:        c.name,
+			Description: c.description,
+			Unit:        c.unit,
+			Sum:         &s,
+		})
+	}
+
+	for _, h := range histograms {
+		h.mu.Lock()
+		hst := histogram{
+			AggregationTemporality: aggregationTemporalityCumulative,
+		}
+		for _, p := range h.values {
+			buckets := make([]string, len(p.buckets))
+			for i, n := range p.buckets {
+				buckets[i] = strconv.FormatUint(n, 10)
+			}
+
+			hst.DataPoints = append(hst.DataPoints, histogramDataPoint{
+				Attributes:        toKeyValues(p.attrs),
+				StartTimeUnixNano: start,
+				TimeUnixNano:      now,
+				Count:             strconv.FormatUint(p.count, 10),
+				Sum:               p.sum,
+				BucketCounts:      buckets,
+				ExplicitBounds:    h.bounds,
+			})
+		}
+		h.mu.Unlock()
+
+		metrics = append(metrics, metric{
+			Name:        h.name,
+			Description: h.description,
+			Unit:        h.unit,
+			Histogram:   &hst,
+		})
+	}
+
+	req := exportRequest{
+		ResourceMetrics: []resourceMetrics{
+			{
+				Resource: resource{
+					Attributes: toKeyValues([]Attr{{Key: "service.name", Value: e.serviceName}}),
+				},
+				ScopeMetrics: []scopeMetrics{
+					{
+						Scope:   scope{Name: "github.com/ardanlabs/service"},
+						Metrics: metrics,
+					},
+				},
+			},
+		},
+	}
+
+	return req
+}
+
+func toKeyValues(attrs []Attr) []keyValue {
+	kvs := make([]keyValue, len(attrs))
+	for i, a := range attrs {
+		kvs[i] = keyValue{Key: a.Key, Value: anyValue{StringValue: a.Value}}
+	}
+
+	return kvs
+}
+
+func nanos(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
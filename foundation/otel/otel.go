@@ -0,0 +1,301 @@
+// Package otel provides support for exporting metrics to an OpenTelemetry
+// collector using OTLP over HTTP with the JSON encoding. Only counters and
+// histograms with cumulative temporality are supported.
+package otel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// Attr represents an attribute that is recorded with a measurement.
+type Attr struct {
+	Key   string
+	Value string
+}
+
+// Config represents the settings for exporting metrics.
+type Config struct {
+	Log         *logger.Logger
+	Endpoint    string
+	ServiceName string
+	Interval    time.Duration
+	Client      *http.Client
+}
+
+// Exporter collects metrics and periodically pushes them to the collector.
+type Exporter struct {
+	log         *logger.Logger
+	url         string
+	serviceName string
+	client      *http.Client
+	start       time.Time
+	mu          sync.Mutex
+	counters    []*Counter
+	histograms  []*Histogram
+	shutdown    chan struct{}
+	wg          sync.WaitGroup
+	once        sync.Once
+}
+
+// NewExporter constructs an exporter that pushes the metrics to the
+// collector's OTLP HTTP endpoint, like http://collector:4318, on the
+// specified interval.
+func NewExporter(cfg Config) (*Exporter, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("endpoint is required")
+	}
+
+	if cfg.Interval <= 0 {
+		return nil, errors.New("interval must be greater than zero")
+	}
+
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	e := Exporter{
+		log:         cfg.Log,
+		url:         strings.TrimSuffix(cfg.Endpoint, "/") + "/v1/metrics",
+		serviceName: cfg.ServiceName,
+		client:      client,
+		start:       time.Now(),
+		shutdown:    make(chan struct{}),
+	}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.run(cfg.Interval)
+	}()
+
+	return &e, nil
+}
+
+// Counter registers a counter for tracking a value that only increases.
+func (e *Exporter) Counter(name string, description string, unit string) *Counter {
+	c := Counter{
+		name:        name,
+		description: description,
+		unit:        unit,
+		values:      make(map[string]*counterPoint),
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.counters = append(e.counters, &c)
+
+	return &c
+}
+
+// Histogram registers a histogram for tracking the distribution of a value
+// using the specified bucket bounds.
+func (e *Exporter) Histogram(name string, description string, unit string, bounds []float64) *Histogram {
+	bounds = append([]float64(nil), bounds...)
+	sort.Float64s(bounds)
+
+	h := Histogram{
+		name:        name,
+		description: description,
+		unit:        unit,
+		bounds:      bounds,
+		values:      make(map[string]*histogramPoint),
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.histograms = append(e.histograms, &h)
+
+	return &h
+}
+
+// Flush pushes the current metrics to the collector.
+func (e *Exporter) Flush(ctx context.Context) error {
+	body, err := json.Marshal(e.collect())
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("collector: %s", resp.Status)
+	}
+
+	return nil
+}
+
+// Shutdown stops the periodic push and flushes the pending metrics.
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	e.once.Do(func() {
+		close(e.shutdown)
+	})
+
+	e.wg.Wait()
+
+	return e.Flush(ctx)
+}
+
+func (e *Exporter) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if err := e.Flush(ctx); err != nil && e.log != nil {
+				e.log.Error(ctx, "otel metrics", "status", "flush failed", "err", err)
+			}
+			cancel()
+
+		case <-e.shutdown:
+			return
+		}
+	}
+}
+
+// =============================================================================
+
+// Counter represents a metric whose value only increases.
+type Counter struct {
+	name        string
+	description string
+	unit        string
+	mu          sync.Mutex
+	values      map[string]*counterPoint
+}
+
+type counterPoint struct {
+	attrs []Attr
+	value int64
+}
+
+// Add increases the counter for the specified attributes.
+func (c *Counter) Add(value int64, attrs ...Attr) {
+	key, attrs := attrKey(attrs)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, exists := c.values[key]
+	if !exists {
+		p = &counterPoint{attrs: attrs}
+		c.values[key] = p
+	}
+
+	p.value += value
+}
+
+// Value returns the current value of the counter for the specified
+// attributes.
+func (c *Counter) Value(attrs ...Attr) int64 {
+	key, _ := attrKey(attrs)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, exists := c.values[key]
+	if !exists {
+		return 0
+	}
+
+	return p.value
+}
+
+// Histogram represents a metric that tracks the distribution of a value.
+type Histogram struct {
+	name        string
+	description string
+	unit        string
+	bounds      []float64
+	mu          sync.Mutex
+	values      map[string]*histogramPoint
+}
+
+type histogramPoint struct {
+	attrs   []Attr
+	count   uint64
+	sum     float64
+	buckets []uint64
+}
+
+// Record adds the value to the histogram for the specified attributes.
+func (h *Histogram) Record(value float64, attrs ...Attr) {
+	key, attrs := attrKey(attrs)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	p, exists := h.values[key]
+	if !exists {
+		p = &histogramPoint{
+			attrs:   attrs,
+			buckets: make([]uint64, len(h.bounds)+1),
+		}
+		h.values[key] = p
+	}
+
+	p.count++
+	p.sum += value
+	p.buckets[sort.SearchFloat64s(h.bounds, value)]++
+}
+
+// Count returns the number of values recorded by the histogram for the
+// specified attributes.
+func (h *Histogram) Count(attrs ...Attr) uint64 {
+	key, _ := attrKey(attrs)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	p, exists := h.values[key]
+	if !exists {
+		return 0
+	}
+
+	return p.count
+}
+
+// attrKey sorts the attributes and returns a key that identifies the set.
+func attrKey(attrs []Attr) (string, []Attr) {
+	attrs = append([]Attr(nil), attrs...)
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Key < attrs[j].Key })
+
+	var b strings.Builder
+	for _, a := range attrs {
+		b.WriteString(strconv.Quote(a.Key))
+		b.WriteString("=")
+		b.WriteString(strconv.Quote(a.Value))
+		b.WriteString(",")
+	}
+
+	return b.String(), attrs
+}
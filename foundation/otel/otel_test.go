@@ -0,0 +1,188 @@
+package otel_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/otel"
+)
+
+// collector is a mock OTLP collector that keeps the last request.
+type collector struct {
+	mu       sync.Mutex
+	requests int
+	last     exportRequest
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/metrics" || r.Header.Get("Content-Type") != "application/json" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var req exportRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests++
+	c.last = req
+}
+
+// These types decode the parts of the OTLP JSON request being checked.
+type exportRequest struct {
+	ResourceMetrics []struct {
+		Resource struct {
+			Attributes []keyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeMetrics []struct {
+			Metrics []struct {
+				Name string `json:"name"`
+				Sum  *struct {
+					DataPoints []struct {
+						Attributes []keyValue `json:"attributes"`
+						AsInt      string     `json:"asInt"`
+					} `json:"dataPoints"`
+					IsMonotonic bool `json:"isMonotonic"`
+				} `json:"sum"`
+				Histogram *struct {
+					DataPoints []struct {
+						Count        string   `json:"count"`
+						Sum          float64  `json:"sum"`
+						BucketCounts []string `json:"bucketCounts"`
+					} `json:"dataPoints"`
+				} `json:"histogram"`
+			} `json:"metrics"`
+		} `json:"scopeMetrics"`
+	} `json:"resourceMetrics"`
+}
+
+type keyValue struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+func Test_Export(t *testing.T) {
+	t.Parallel()
+
+	var c collector
+	srv := httptest.NewServer(&c)
+	defer srv.Close()
+
+	exp, err := otel.NewExporter(otel.Config{
+		Endpoint:    srv.URL,
+		ServiceName: "sales",
+		Interval:    time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the exporter : %s", err)
+	}
+
+	requests := exp.Counter("http.server.requests", "", "{request}")
+	duration := exp.Histogram("http.server.request.duration", "", "s", []float64{0.1, 1})
+
+	requests.Add(1, otel.Attr{Key: "method", Value: "GET"})
+	requests.Add(2, otel.Attr{Key: "method", Value: "GET"})
+	duration.Record(0.05)
+	duration.Record(0.5)
+	duration.Record(5)
+
+	// The interval is an hour so the metrics can only be exported by the
+	// flush that happens on shutdown.
+	if err := exp.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Should be able to shutdown and flush : %s", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.requests != 1 {
+		t.Fatalf("Should get one export request : %d", c.requests)
+	}
+
+	rm := c.last.ResourceMetrics[0]
+
+	if len(rm.Resource.Attributes) != 1 || rm.Resource.Attributes[0].Value.StringValue != "sales" {
+		t.Errorf("Should get the service name : %+v", rm.Resource.Attributes)
+	}
+
+	metrics := rm.ScopeMetrics[0].Metrics
+	if len(metrics) != 2 {
+		t.Fatalf("Should get two metrics : %d", len(metrics))
+	}
+
+	sum := metrics[0].Sum
+	if metrics[0].Name != "http.server.requests" || sum == nil || !sum.IsMonotonic {
+		t.Fatalf("Should get the counter : %+v", metrics[0])
+	}
+
+	if len(sum.DataPoints) != 1 || sum.DataPoints[0].AsInt != "3" {
+		t.Errorf("Should get the counter value : %+v", sum.DataPoints)
+	}
+
+	hst := metrics[1].Histogram
+	if metrics[1].Name != "http.server.request.duration" || hst == nil || len(hst.DataPoints) != 1 {
+		t.Fatalf("Should get the histogram : %+v", metrics[1])
+	}
+
+	dp := hst.DataPoints[0]
+	if dp.Count != "3" || dp.Sum != 5.55 {
+		t.Errorf("Should get the histogram count and sum : %+v", dp)
+	}
+
+	if len(dp.BucketCounts) != 3 || dp.BucketCounts[0] != "1" || dp.BucketCounts[1] != "1" || dp.BucketCounts[2] != "1" {
+		t.Errorf("Should get one value in each bucket : %v", dp.BucketCounts)
+	}
+}
+
+func Test_ExportInterval(t *testing.T) {
+	t.Parallel()
+
+	var c collector
+	srv := httptest.NewServer(&c)
+	defer srv.Close()
+
+	exp, err := otel.NewExporter(otel.Config{
+		Endpoint: srv.URL,
+		Interval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the exporter : %s", err)
+	}
+	defer exp.Shutdown(context.Background())
+
+	exp.Counter("count", "", "").Add(1)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		n := c.requests
+		c.mu.Unlock()
+
+		if n > 0 {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Errorf("Should push the metrics on the interval")
+}
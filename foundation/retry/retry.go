@@ -0,0 +1,55 @@
+// Package retry provides support for limiting the rate of retries across
+// the components of a service.
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// Budget is a token bucket shared by every component that retries failed
+// calls. Each retry takes a token and the tokens are refilled at a fixed
+// rate, so during an outage the service can't retry faster than the rate no
+// matter how many calls are failing. When the budget is exhausted, calls
+// should fail fast instead of retrying. A nil budget allows every retry.
+type Budget struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewBudget constructs a budget that allows the specified number of retries
+// per second on average, with up to burst retries at once.
+func NewBudget(rate float64, burst int) *Budget {
+	return &Budget{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Allow reports whether a retry can be made and takes a token from the
+// budget when it can.
+func (b *Budget) Allow() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
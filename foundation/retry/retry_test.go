@@ -0,0 +1,54 @@
+package retry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/retry"
+)
+
+func Test_Budget(t *testing.T) {
+	t.Parallel()
+
+	b := retry.NewBudget(0.001, 3)
+
+	for i := range 3 {
+		if !b.Allow() {
+			t.Fatalf("Should allow retry %d within the burst", i)
+		}
+	}
+
+	if b.Allow() {
+		t.Fatalf("Should suppress the retry once the budget is depleted")
+	}
+}
+
+func Test_BudgetRefill(t *testing.T) {
+	t.Parallel()
+
+	b := retry.NewBudget(100, 1)
+
+	if !b.Allow() {
+		t.Fatalf("Should allow the first retry")
+	}
+
+	if b.Allow() {
+		t.Fatalf("Should suppress the retry once the budget is depleted")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if !b.Allow() {
+		t.Fatalf("Should allow a retry after the budget is refilled")
+	}
+}
+
+func Test_BudgetNil(t *testing.T) {
+	t.Parallel()
+
+	var b *retry.Budget
+
+	if !b.Allow() {
+		t.Fatalf("Should allow every retry without a budget")
+	}
+}
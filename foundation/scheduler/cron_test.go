@@ -0,0 +1,73 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/scheduler"
+)
+
+func Test_Parse(t *testing.T) {
+	t.Parallel()
+
+	// Wednesday.
+	from := time.Date(2024, time.May, 15, 10, 7, 30, 0, time.UTC)
+
+	table := []struct {
+		spec string
+		exp  time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.May, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.May, 15, 10, 15, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2024, time.May, 16, 2, 30, 0, 0, time.UTC)},
+		{"0 9-17 * * 1-5", time.Date(2024, time.May, 15, 11, 0, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2024, time.May, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.May, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 5", time.Date(2024, time.May, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.May, 15, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.May, 16, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := scheduler.Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Should be able to parse the spec : %s", err)
+			}
+
+			if got := schedule.Next(from); !got.Equal(tt.exp) {
+				t.Errorf("Should get the next run, got %s, exp %s", got, tt.exp)
+			}
+		})
+	}
+}
+
+func Test_ParseInvalid(t *testing.T) {
+	t.Parallel()
+
+	specs := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@every",
+		"@every -1s",
+		"@sometimes",
+	}
+
+	for _, spec := range specs {
+		if _, err := scheduler.Parse(spec); err == nil {
+			t.Errorf("Should not be able to parse %q", spec)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package scheduler provides support for running jobs on a schedule within
+// the service.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/tracer"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// JobFn defines a function that performs the work of a scheduled job. It
+// should return when the context is canceled.
+type JobFn func(ctx context.Context) error
+
+type job struct {
+	name     string
+	schedule Schedule
+	fn       JobFn
+	running  atomic.Bool
+}
+
+// Scheduler runs registered jobs on their schedules. A run of a job is
+// skipped while the previous run of the same job is still going.
+type Scheduler struct {
+	log     *logger.Logger
+	tracer  trace.Tracer
+	mu      sync.Mutex
+	jobs    []*job
+	started bool
+	wg      sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// New constructs a scheduler. The tracer can be nil.
+func New(log *logger.Logger, tracer trace.Tracer) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		log:    log,
+		tracer: tracer,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Register adds a job that runs on the schedule described by the cron
+// expression, see Parse. Jobs must be registered before the scheduler is
+// started.
+func (s *Scheduler) Register(name string, spec string, fn JobFn) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return fmt.Errorf("register %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return fmt.Errorf("register %s: scheduler already started", name)
+	}
+
+	s.jobs = append(s.jobs, &job{name: name, schedule: schedule, fn: fn})
+
+	return nil
+}
+
+// Start begins running the registered jobs on their schedules.
+func (s *Scheduler) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return errors.New("scheduler already started")
+	}
+	s.started = true
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.loop(j)
+		}()
+	}
+
+	return nil
+}
+
+// Shutdown stops scheduling jobs, cancels the running ones and waits for them
+// to return or the context to be canceled.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.cancel()
+
+	ch := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(ch)
+	}()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop(j *job) {
+	for {
+		now := time.Now()
+
+		next := j.schedule.Next(now)
+		if next.IsZero() {
+			s.log.Info(s.ctx, "scheduler", "status", "job has no next run", "job", j.name)
+			return
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+
+		case <-timer.C:
+		}
+
+		if !j.running.CompareAndSwap(false, true) {
+			s.log.Info(s.ctx, "scheduler", "status", "job skipped, previous run still going", "job", j.name)
+			continue
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer j.running.Store(false)
+
+			s.run(j)
+		}()
+	}
+}
+
+func (s *Scheduler) run(j *job) {
+	ctx, span := tracer.StartBackground(s.ctx, s.tracer, "scheduler.job", attribute.String("job", j.name))
+	defer span.End()
+
+	s.log.Info(ctx, "scheduler", "status", "job started", "job", j.name)
+
+	start := time.Now()
+
+	err := func() (err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = fmt.Errorf("panic: %v", rec)
+			}
+		}()
+
+		return j.fn(ctx)
+	}()
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		s.log.Error(ctx, "scheduler", "status", "job failed", "job", j.name, "duration", time.Since(start), "err", err)
+		return
+	}
+
+	s.log.Info(ctx, "scheduler", "status", "job completed", "job", j.name, "duration", time.Since(start))
+}
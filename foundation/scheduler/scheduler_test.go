@@ -0,0 +1,144 @@
+package scheduler_test
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/scheduler"
+)
+
+func newScheduler() *scheduler.Scheduler {
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	return scheduler.New(log, nil)
+}
+
+func Test_SchedulerFires(t *testing.T) {
+	t.Parallel()
+
+	s := newScheduler()
+
+	fired := make(chan time.Time, 10)
+	err := s.Register("tick", "@every 20ms", func(ctx context.Context) error {
+		select {
+		case fired <- time.Now():
+		default:
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Should be able to register the job : %s", err)
+	}
+
+	start := time.Now()
+	if err := s.Start(); err != nil {
+		t.Fatalf("Should be able to start the scheduler : %s", err)
+	}
+	defer s.Shutdown(context.Background())
+
+	for i := 0; i < 3; i++ {
+		select {
+		case at := <-fired:
+			if d := at.Sub(start); d < 20*time.Millisecond {
+				t.Errorf("Should not run before the schedule, ran after %v", d)
+			}
+
+		case <-time.After(time.Second):
+			t.Fatalf("Should run the job on its schedule, ran %d times", i)
+		}
+	}
+}
+
+func Test_SchedulerSkipsOverlap(t *testing.T) {
+	t.Parallel()
+
+	s := newScheduler()
+
+	var running, overlaps, runs atomic.Int64
+
+	err := s.Register("slow", "@every 10ms", func(ctx context.Context) error {
+		if running.Add(1) > 1 {
+			overlaps.Add(1)
+		}
+		defer running.Add(-1)
+
+		runs.Add(1)
+
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-ctx.Done():
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Should be able to register the job : %s", err)
+	}
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("Should be able to start the scheduler : %s", err)
+	}
+
+	time.Sleep(250 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("Should be able to shutdown : %s", err)
+	}
+
+	if n := overlaps.Load(); n != 0 {
+		t.Errorf("Should skip runs while the previous run is going, got %d overlaps", n)
+	}
+
+	// Without skipping, the job would have run about 25 times.
+	if n := runs.Load(); n < 2 || n > 4 {
+		t.Errorf("Should only run when the previous run finished, got %d runs", n)
+	}
+}
+
+func Test_SchedulerShutdown(t *testing.T) {
+	t.Parallel()
+
+	s := newScheduler()
+
+	started := make(chan struct{})
+	var once sync.Once
+	var canceled atomic.Bool
+
+	err := s.Register("long", "@every 10ms", func(ctx context.Context) error {
+		once.Do(func() { close(started) })
+		<-ctx.Done()
+		canceled.Store(true)
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("Should be able to register the job : %s", err)
+	}
+
+	if err := s.Start(); err != nil {
+		t.Fatalf("Should be able to start the scheduler : %s", err)
+	}
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("Should be able to shutdown : %s", err)
+	}
+
+	if !canceled.Load() {
+		t.Errorf("Should cancel the running job on shutdown")
+	}
+
+	if err := s.Register("late", "@daily", func(ctx context.Context) error { return nil }); err == nil {
+		t.Errorf("Should not register a job after the scheduler started")
+	}
+}
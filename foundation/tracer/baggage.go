@@ -0,0 +1,84 @@
+package tracer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Set of baggage keys carried across service boundaries.
+const (
+	BaggageTenantID  = "tenant.id"
+	BaggageRequestID = "request.id"
+)
+
+// propagator is used explicitly so baggage is propagated even when the
+// global propagator has not been configured.
+var propagator = propagation.NewCompositeTextMapPropagator(
+	propagation.TraceContext{},
+	propagation.Baggage{},
+)
+
+// SetBaggage returns a context with the baggage member added so it is
+// propagated to downstream services.
+func SetBaggage(ctx context.Context, key string, value string) (context.Context, error) {
+	m, err := baggage.NewMemberRaw(key, value)
+	if err != nil {
+		return ctx, fmt.Errorf("new member: %w", err)
+	}
+
+	b, err := baggage.FromContext(ctx).SetMember(m)
+	if err != nil {
+		return ctx, fmt.Errorf("set member: %w", err)
+	}
+
+	return baggage.ContextWithBaggage(ctx, b), nil
+}
+
+// DeleteBaggage returns a context without the baggage member, so it isn't
+// propagated to downstream services.
+func DeleteBaggage(ctx context.Context, key string) context.Context {
+	b := baggage.FromContext(ctx)
+	if b.Member(key).Key() == "" {
+		return ctx
+	}
+
+	return baggage.ContextWithBaggage(ctx, b.DeleteMember(key))
+}
+
+// GetBaggage returns the value of the baggage member from the context.
+func GetBaggage(ctx context.Context, key string) string {
+	return baggage.FromContext(ctx).Member(key).Value()
+}
+
+// InjectHeaders writes the trace and baggage information from the context
+// into the headers of an outbound request.
+func InjectHeaders(ctx context.Context, h http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(h))
+}
+
+// ExtractBaggage reads the baggage from the headers of an inbound request.
+// Only the members for the specified keys are kept so a client can't fill
+// the context with values that would be propagated to every downstream
+// service. The baggage is set by the caller, so a member that identifies the
+// caller, like the tenant, must only be kept for trusted upstream services.
+func ExtractBaggage(ctx context.Context, h http.Header, keys []string) context.Context {
+	in := propagation.Baggage{}.Extract(context.Background(), propagation.HeaderCarrier(h))
+
+	var b baggage.Baggage
+	for _, m := range baggage.FromContext(in).Members() {
+		if !slices.Contains(keys, m.Key()) {
+			continue
+		}
+
+		if nb, err := b.SetMember(m); err == nil {
+			b = nb
+		}
+	}
+
+	return baggage.ContextWithBaggage(ctx, b)
+}
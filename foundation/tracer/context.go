@@ -29,3 +29,16 @@ func AddSpan(ctx context.Context, spanName string, keyValues ...attribute.KeyVal
 
 	return ctx, span
 }
+
+// AddClientSpan adds an otel span for an outbound call to another service.
+// The span is a child of the span in the context. When the context doesn't
+// carry a tracer, the provider of that span is used so calls made outside
+// of a web request are still traced when they're part of a trace.
+func AddClientSpan(ctx context.Context, spanName string, keyValues ...attribute.KeyValue) (context.Context, trace.Span) {
+	v, ok := ctx.Value(key).(trace.Tracer)
+	if !ok || v == nil {
+		v = trace.SpanFromContext(ctx).TracerProvider().Tracer("github.com/ardanlabs/service/foundation/tracer")
+	}
+
+	return v.Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(keyValues...))
+}
@@ -0,0 +1,30 @@
+package tracer
+
+import (
+	"github.com/ardanlabs/service/foundation/logger"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// NewSampler allows tests to construct the sampler used by InitTracing.
+func NewSampler(log *logger.Logger, endpoints map[string]struct{}, routes map[string]float64, probability float64) trace.Sampler {
+	return newEndpointExcluder(log, endpoints, routes, probability, nil)
+}
+
+// NewErrorKeeper allows tests to construct the span processor used by
+// InitTracing.
+func NewErrorKeeper(next trace.SpanProcessor) trace.SpanProcessor {
+	return newErrorKeeper(next)
+}
+
+// MaxHeldSpans exposes the number of spans the error keeper can hold.
+const MaxHeldSpans = maxHeldSpans
+
+// HeldSpans returns the number of spans the error keeper is holding.
+func HeldSpans(sp trace.SpanProcessor) int {
+	ek := sp.(*errorKeeper)
+
+	ek.mu.Lock()
+	defer ek.mu.Unlock()
+
+	return ek.held
+}
@@ -1,39 +1,284 @@
 package tracer
 
 import (
+	"container/list"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
 	"github.com/ardanlabs/service/foundation/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
 type endpointExcluder struct {
-	log         *logger.Logger
-	endpoints   map[string]struct{}
-	probability float64
+	log           *logger.Logger
+	endpoints     map[string]struct{}
+	routes        map[string]float64
+	probability   float64
+	probabilityFn func() float64
 }
 
-func newEndpointExcluder(log *logger.Logger, endpoints map[string]struct{}, probability float64) endpointExcluder {
+func newEndpointExcluder(log *logger.Logger, endpoints map[string]struct{}, routes map[string]float64, probability float64, probabilityFn func() float64) endpointExcluder {
 	return endpointExcluder{
-		log:         log,
-		endpoints:   endpoints,
-		probability: probability,
+		log:           log,
+		endpoints:     endpoints,
+		routes:        routes,
+		probability:   probability,
+		probabilityFn: probabilityFn,
 	}
 }
 
 // ShouldSample implements the sampler interface. It prevents the specified
-// endpoints from being added to the trace.
+// endpoints and the routes with a probability of zero from being added to
+// the trace. Other traces are sampled with the probability for their route.
+// A trace that isn't sampled is still recorded, so the trace can be kept by
+// the error keeper if the request fails.
 func (ee endpointExcluder) ShouldSample(parameters trace.SamplingParameters) trace.SamplingResult {
+	psc := oteltrace.SpanContextFromContext(parameters.ParentContext)
+	if psc.IsValid() {
+		switch {
+		case psc.IsSampled():
+			return trace.SamplingResult{Decision: trace.RecordAndSample, Tracestate: psc.TraceState()}
+
+		// The children of a span that was dropped aren't recorded either.
+		case !psc.IsRemote() && !oteltrace.SpanFromContext(parameters.ParentContext).IsRecording():
+			return trace.SamplingResult{Decision: trace.Drop, Tracestate: psc.TraceState()}
+		}
+
+		return trace.SamplingResult{Decision: trace.RecordOnly, Tracestate: psc.TraceState()}
+	}
+
+	var endpoint string
 	for i := range parameters.Attributes {
-		if parameters.Attributes[i].Key == "http.target" {
-			if _, exists := ee.endpoints[parameters.Attributes[i].Value.AsString()]; exists {
-				return trace.SamplingResult{Decision: trace.Drop}
-			}
+		switch parameters.Attributes[i].Key {
+		case "http.target", "endpoint":
+			endpoint = parameters.Attributes[i].Value.AsString()
 		}
 	}
 
-	return trace.TraceIDRatioBased(ee.probability).ShouldSample(parameters)
+	path, _, _ := strings.Cut(endpoint, "?")
+	if _, exists := ee.endpoints[path]; exists {
+		return trace.SamplingResult{Decision: trace.Drop}
+	}
+
+	probability, exists := routeProbability(ee.routes, path)
+	switch {
+	case exists && probability <= 0:
+		return trace.SamplingResult{Decision: trace.Drop}
+
+	case !exists:
+		probability = ee.probability
+		if ee.probabilityFn != nil {
+			probability = ee.probabilityFn()
+		}
+	}
+
+	result := trace.TraceIDRatioBased(probability).ShouldSample(parameters)
+	if result.Decision == trace.Drop {
+		result.Decision = trace.RecordOnly
+	}
+
+	return result
 }
 
 // Description implements the sampler interface.
 func (endpointExcluder) Description() string {
 	return "customSampler"
 }
+
+// routeProbability returns the probability of the longest route that is the
+// path or a parent of it, so /v1/orders also covers /v1/orders/123.
+func routeProbability(routes map[string]float64, path string) (float64, bool) {
+	for {
+		if probability, exists := routes[path]; exists {
+			return probability, true
+		}
+
+		i := strings.LastIndex(path, "/")
+		if i <= 0 {
+			return 0, false
+		}
+
+		path = path[:i]
+	}
+}
+
+// ParseRouteProbability parses probabilities for routes in the form of
+// route=probability, like /v1/orders=0.5.
+func ParseRouteProbability(values []string) (map[string]float64, error) {
+	routes := make(map[string]float64, len(values))
+
+	for _, value := range values {
+		route, probability, ok := strings.Cut(value, "=")
+		if !ok || !strings.HasPrefix(route, "/") {
+			return nil, fmt.Errorf("route probability %q must be in the form /route=probability", value)
+		}
+
+		p, err := strconv.ParseFloat(probability, 64)
+		if err != nil || p < 0 || p > 1 {
+			return nil, fmt.Errorf("route probability %q must have a probability between 0 and 1", value)
+		}
+
+		routes[strings.TrimSuffix(route, "/")] = p
+	}
+
+	return routes, nil
+}
+
+// =============================================================================
+
+// Set of limits that keep the spans held by the error keeper bounded. A
+// trace holds at most maxTraceSpans and the oldest traces are evicted once
+// maxHeldSpans are held across all of them.
+const (
+	maxHeldSpans  = 50_000
+	maxTraceSpans = 1_000
+)
+
+// heldTrace represents the spans held for a trace that wasn't sampled.
+type heldTrace struct {
+	spans []trace.ReadOnlySpan
+	elem  *list.Element
+}
+
+// errorKeeper holds the spans of traces that weren't sampled until the trace
+// ends. When any span of the trace reports an error, the whole trace is
+// passed on to be exported as if it was sampled. Otherwise, it's dropped.
+type errorKeeper struct {
+	next trace.SpanProcessor
+
+	mu     sync.Mutex
+	traces map[oteltrace.TraceID]*heldTrace
+	order  *list.List
+	held   int
+}
+
+func newErrorKeeper(next trace.SpanProcessor) *errorKeeper {
+	return &errorKeeper{
+		next:   next,
+		traces: make(map[oteltrace.TraceID]*heldTrace),
+		order:  list.New(),
+	}
+}
+
+// OnStart implements the span processor interface.
+func (ek *errorKeeper) OnStart(parent context.Context, s trace.ReadWriteSpan) {
+	ek.next.OnStart(parent, s)
+}
+
+// OnEnd implements the span processor interface. The trace ends when its
+// local root span ends.
+func (ek *errorKeeper) OnEnd(s trace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		ek.next.OnEnd(s)
+		return
+	}
+
+	traceID := s.SpanContext().TraceID()
+	root := !s.Parent().IsValid() || s.Parent().IsRemote()
+
+	var spans []trace.ReadOnlySpan
+
+	ek.mu.Lock()
+	if root {
+		if ht, exists := ek.traces[traceID]; exists {
+			spans = ht.spans
+			ek.remove(traceID, ht)
+		}
+	} else {
+		ek.hold(traceID, s)
+	}
+	ek.mu.Unlock()
+
+	if !root {
+		return
+	}
+
+	spans = append(spans, s)
+
+	for _, s := range spans {
+		if !isError(s) {
+			continue
+		}
+
+		for _, s := range spans {
+			ek.next.OnEnd(sampledSpan{ReadOnlySpan: s})
+		}
+		return
+	}
+}
+
+// hold adds the span to its trace, evicting the oldest traces when the
+// total number of held spans reaches the limit. It must be called with the
+// lock held.
+func (ek *errorKeeper) hold(traceID oteltrace.TraceID, s trace.ReadOnlySpan) {
+	ht, exists := ek.traces[traceID]
+	if exists && len(ht.spans) >= maxTraceSpans {
+		return
+	}
+
+	for ek.held >= maxHeldSpans {
+		oldest := ek.order.Front().Value.(oteltrace.TraceID)
+		ek.remove(oldest, ek.traces[oldest])
+	}
+
+	// The trace may have been evicted to make room.
+	ht, exists = ek.traces[traceID]
+	if !exists {
+		ht = &heldTrace{elem: ek.order.PushBack(traceID)}
+		ek.traces[traceID] = ht
+	}
+
+	ht.spans = append(ht.spans, s)
+	ek.held++
+}
+
+// remove stops holding the spans of the trace. It must be called with the
+// lock held.
+func (ek *errorKeeper) remove(traceID oteltrace.TraceID, ht *heldTrace) {
+	ek.order.Remove(ht.elem)
+	delete(ek.traces, traceID)
+	ek.held -= len(ht.spans)
+}
+
+// Shutdown implements the span processor interface.
+func (ek *errorKeeper) Shutdown(ctx context.Context) error {
+	return ek.next.Shutdown(ctx)
+}
+
+// ForceFlush implements the span processor interface.
+func (ek *errorKeeper) ForceFlush(ctx context.Context) error {
+	return ek.next.ForceFlush(ctx)
+}
+
+// isError reports whether the span recorded an error or a server error
+// response. Client errors are expected and aren't kept.
+func isError(s trace.ReadOnlySpan) bool {
+	if s.Status().Code == codes.Error {
+		return true
+	}
+
+	for _, kv := range s.Attributes() {
+		if (kv.Key == "status" || kv.Key == "http.status_code") && kv.Value.Type() == attribute.INT64 && kv.Value.AsInt64() >= 500 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sampledSpan marks a span that wasn't sampled as sampled, so it's exported.
+type sampledSpan struct {
+	trace.ReadOnlySpan
+}
+
+// SpanContext returns the span context with the sampled flag set.
+func (s sampledSpan) SpanContext() oteltrace.SpanContext {
+	sc := s.ReadOnlySpan.SpanContext()
+	return sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))
+}
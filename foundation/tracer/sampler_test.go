@@ -0,0 +1,201 @@
+package tracer_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/tracer"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type exporter struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (e *exporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = append(e.spans, spans...)
+
+	return nil
+}
+
+func (e *exporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (e *exporter) traces() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var n int
+	for _, s := range e.spans {
+		if !s.Parent().IsValid() {
+			n++
+		}
+	}
+
+	return n
+}
+
+func newProvider(routes map[string]float64, probability float64) (*sdktrace.TracerProvider, *exporter) {
+	exp := exporter{}
+
+	excluded := map[string]struct{}{
+		"/v1/liveness": {},
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(tracer.NewSampler(nil, excluded, routes, probability)),
+		sdktrace.WithSpanProcessor(tracer.NewErrorKeeper(sdktrace.NewSimpleSpanProcessor(&exp))),
+	)
+
+	return tp, &exp
+}
+
+// request traces a request the way the web package does, with the status of
+// the response recorded on a child span.
+func request(tp *sdktrace.TracerProvider, endpoint string, status int) {
+	ctx, span := tracer.StartTrace(context.Background(), tp.Tracer("test"), "pkg.web.handle", endpoint, httptest.NewRecorder())
+	defer span.End()
+
+	_, child := tracer.AddSpan(ctx, "foundation.response", attribute.Int("status", status))
+	child.End()
+}
+
+func Test_SampleErrors(t *testing.T) {
+	t.Parallel()
+
+	tp, exp := newProvider(nil, 0)
+
+	for range 100 {
+		request(tp, "/v1/users", http.StatusInternalServerError)
+	}
+
+	if n := exp.traces(); n != 100 {
+		t.Errorf("Should sample every error response : got %d of 100", n)
+	}
+
+	if n := len(exp.spans); n != 200 {
+		t.Errorf("Should export every span of the error responses : got %d of 200", n)
+	}
+
+	for range 100 {
+		request(tp, "/v1/users", http.StatusNotFound)
+		request(tp, "/v1/users", http.StatusOK)
+	}
+
+	if n := exp.traces(); n != 100 {
+		t.Errorf("Should not sample the other responses : got %d", n-100)
+	}
+
+	request(tp, "/v1/liveness", http.StatusInternalServerError)
+
+	if n := exp.traces(); n != 100 {
+		t.Errorf("Should not sample excluded endpoints")
+	}
+}
+
+func Test_SampleRate(t *testing.T) {
+	t.Parallel()
+
+	const requests = 4000
+
+	tp, exp := newProvider(nil, 0.25)
+
+	for range requests {
+		request(tp, "/v1/users", http.StatusOK)
+	}
+
+	// The sample is random, so allow for some variance around 1000.
+	if n := exp.traces(); n < 850 || n > 1150 {
+		t.Errorf("Should sample about a quarter of the successful responses : got %d of %d", n, requests)
+	}
+}
+
+func Test_SampleRoutes(t *testing.T) {
+	t.Parallel()
+
+	tp, exp := newProvider(map[string]float64{"/v1/orders": 1}, 0)
+
+	for range 100 {
+		request(tp, "/v1/orders/123?expand=items", http.StatusOK)
+		request(tp, "/v1/users", http.StatusOK)
+	}
+
+	if n := exp.traces(); n != 100 {
+		t.Errorf("Should sample every request to the route and none to others : got %d of 100", n)
+	}
+}
+
+func Test_SampleExcludedRoutes(t *testing.T) {
+	t.Parallel()
+
+	tp, exp := newProvider(map[string]float64{"/v1/reports": 0}, 0)
+
+	ctx, span := tracer.StartTrace(context.Background(), tp.Tracer("test"), "pkg.web.handle", "/v1/reports", httptest.NewRecorder())
+	_, child := tracer.AddSpan(ctx, "foundation.response", attribute.Int("status", http.StatusInternalServerError))
+
+	if span.IsRecording() || child.IsRecording() {
+		t.Errorf("Should not record the spans of an excluded route")
+	}
+
+	child.End()
+	span.End()
+
+	if n := exp.traces(); n != 0 {
+		t.Errorf("Should not sample an excluded route : got %d", n)
+	}
+}
+
+func Test_ErrorKeeperLimit(t *testing.T) {
+	t.Parallel()
+
+	exp := exporter{}
+	keeper := tracer.NewErrorKeeper(sdktrace.NewSimpleSpanProcessor(&exp))
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(tracer.NewSampler(nil, nil, nil, 0)),
+		sdktrace.WithSpanProcessor(keeper),
+	)
+
+	// The root spans never end, so the spans of every trace are held until
+	// they're evicted.
+	for range tracer.MaxHeldSpans/2 + 100 {
+		ctx, _ := tracer.StartTrace(context.Background(), tp.Tracer("test"), "pkg.web.handle", "/v1/users", httptest.NewRecorder())
+
+		for range 2 {
+			_, child := tracer.AddSpan(ctx, "foundation.response")
+			child.End()
+		}
+	}
+
+	if n := tracer.HeldSpans(keeper); n > tracer.MaxHeldSpans {
+		t.Errorf("Should hold at most %d spans : got %d", tracer.MaxHeldSpans, n)
+	}
+}
+
+func Test_ParseRouteProbability(t *testing.T) {
+	t.Parallel()
+
+	routes, err := tracer.ParseRouteProbability([]string{"/v1/orders/=0.5", "/v1/checkout=1"})
+	if err != nil {
+		t.Fatalf("Should be able to parse the routes : %s", err)
+	}
+
+	if routes["/v1/orders"] != 0.5 || routes["/v1/checkout"] != 1 {
+		t.Errorf("Should get the probability for each route : got %v", routes)
+	}
+
+	for _, value := range []string{"/v1/orders", "v1/orders=0.5", "/v1/orders=2", "/v1/orders=half"} {
+		if _, err := tracer.ParseRouteProbability([]string{value}); err == nil {
+			t.Errorf("Should fail to parse %q", value)
+		}
+	}
+}
@@ -26,6 +26,15 @@ type Config struct {
 	Host           string
 	ExcludedRoutes map[string]struct{}
 	Probability    float64
+
+	// ProbabilityFn is optional and allows the probability to be changed
+	// while the service is running. When provided, Probability is ignored.
+	ProbabilityFn func() float64
+
+	// RouteProbability is optional and overrides the probability for the
+	// routes and everything under them, so high-value endpoints can be
+	// sampled more often. Traces that end with an error are always kept.
+	RouteProbability map[string]float64
 }
 
 // InitTracing configures open telemetry to be used with the service.
@@ -46,13 +55,15 @@ func InitTracing(cfg Config) (*sdktrace.TracerProvider, error) {
 		return nil, fmt.Errorf("creating new exporter: %w", err)
 	}
 
+	batcher := sdktrace.NewBatchSpanProcessor(exporter,
+		sdktrace.WithMaxExportBatchSize(sdktrace.DefaultMaxExportBatchSize),
+		sdktrace.WithBatchTimeout(sdktrace.DefaultScheduleDelay*time.Millisecond),
+		sdktrace.WithMaxExportBatchSize(sdktrace.DefaultMaxExportBatchSize),
+	)
+
 	traceProvider := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(newEndpointExcluder(cfg.Log, cfg.ExcludedRoutes, cfg.Probability)),
-		sdktrace.WithBatcher(exporter,
-			sdktrace.WithMaxExportBatchSize(sdktrace.DefaultMaxExportBatchSize),
-			sdktrace.WithBatchTimeout(sdktrace.DefaultScheduleDelay*time.Millisecond),
-			sdktrace.WithMaxExportBatchSize(sdktrace.DefaultMaxExportBatchSize),
-		),
+		sdktrace.WithSampler(newEndpointExcluder(cfg.Log, cfg.ExcludedRoutes, cfg.RouteProbability, cfg.Probability, cfg.ProbabilityFn)),
+		sdktrace.WithSpanProcessor(newErrorKeeper(batcher)),
 		sdktrace.WithResource(
 			resource.NewWithAttributes(
 				semconv.SchemaURL,
@@ -84,8 +95,7 @@ func StartTrace(ctx context.Context, tracer trace.Tracer, spanName string, endpo
 
 	switch {
 	case tracer != nil:
-		ctx, span = tracer.Start(ctx, spanName)
-		span.SetAttributes(attribute.String("endpoint", endpoint))
+		ctx, span = tracer.Start(ctx, spanName, trace.WithAttributes(attribute.String("endpoint", endpoint)))
 
 	default:
 		span = trace.SpanFromContext(ctx)
@@ -98,3 +108,22 @@ func StartTrace(ctx context.Context, tracer trace.Tracer, spanName string, endpo
 
 	return ctx, span
 }
+
+// StartBackground initializes a trace for work that isn't started by a
+// request, like a scheduled job. It also saves the tracer in the context for
+// later use.
+func StartBackground(ctx context.Context, tracer trace.Tracer, spanName string, keyValues ...attribute.KeyValue) (context.Context, trace.Span) {
+	var span trace.Span
+
+	switch {
+	case tracer != nil:
+		ctx, span = tracer.Start(ctx, spanName, trace.WithNewRoot(), trace.WithAttributes(keyValues...))
+
+	default:
+		span = trace.SpanFromContext(ctx)
+	}
+
+	ctx = setTracer(ctx, tracer)
+
+	return ctx, span
+}
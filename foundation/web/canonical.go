@@ -0,0 +1,79 @@
+package web
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/go-json-experiment/json/jsontext"
+)
+
+// Canonical wraps a JSON response so it's written in the canonical form
+// defined by RFC 8785: object names are sorted, insignificant whitespace is
+// removed and numbers and strings have a single representation. Semantically
+// equal responses are then byte-identical, which is required for signing
+// and caching them. The status and headers of the wrapped response are kept.
+//
+// JCS treats every number as a float64, so integers beyond 2^53 lose
+// precision and should be encoded as strings by the wrapped response.
+func Canonical(enc Encoder) Encoder {
+	if enc == nil {
+		return nil
+	}
+
+	return canonical{enc: enc}
+}
+
+type canonical struct {
+	enc Encoder
+}
+
+// Encode implements the encoder interface.
+func (c canonical) Encode() ([]byte, string, error) {
+	data, contentType, err := c.enc.Encode()
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !isJSON(contentType) {
+		return data, contentType, nil
+	}
+
+	v := jsontext.Value(data)
+	if err := v.Canonicalize(); err != nil {
+		return nil, "", fmt.Errorf("canonicalize: %w", err)
+	}
+
+	return v, contentType, nil
+}
+
+// HTTPStatus implements the httpStatus interface.
+func (c canonical) HTTPStatus() int {
+	switch v := c.enc.(type) {
+	case httpStatus:
+		return v.HTTPStatus()
+	case error:
+		return http.StatusInternalServerError
+	}
+
+	return http.StatusOK
+}
+
+// HTTPHeader implements the httpHeader interface.
+func (c canonical) HTTPHeader() http.Header {
+	if v, ok := c.enc.(httpHeader); ok {
+		return v.HTTPHeader()
+	}
+
+	return nil
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,76 @@
+package web_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type rawResponse struct {
+	data   string
+	status int
+}
+
+func (r rawResponse) Encode() ([]byte, string, error) {
+	return []byte(r.data), "application/json; charset=utf-8", nil
+}
+
+func (r rawResponse) HTTPStatus() int {
+	return r.status
+}
+
+func Test_Canonical(t *testing.T) {
+	t.Parallel()
+
+	responses := map[string]rawResponse{
+		"a": {data: `{"name":"Bill","age":30,"tags":["x","y"],"address":{"zip":"33101","city":"Miami"},"score":1.50}`, status: http.StatusCreated},
+		"b": {data: "{\n  \"score\": 15e-1,\n  \"address\": {\"city\": \"Miami\", \"zip\": \"33101\"},\n  \"tags\": [\"x\", \"y\"],\n  \"age\": 3.0E1,\n  \"name\": \"\\u0042ill\"\n}", status: http.StatusCreated},
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil)
+	app.HandlerFunc(http.MethodGet, "v1", "/doc/{id}", func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return web.Canonical(responses[web.Param(r, "id")]), nil
+	})
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	get := func(id string) string {
+		resp, err := http.Get(srv.URL + "/v1/doc/" + id)
+		if err != nil {
+			t.Fatalf("Should be able to make the request : %s", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusCreated {
+			t.Errorf("Should keep the status of the response : %d", resp.StatusCode)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("Should be able to read the response : %s", err)
+		}
+
+		return string(body)
+	}
+
+	a := get("a")
+	b := get("b")
+
+	if a != b {
+		t.Errorf("Should get byte-identical responses")
+		t.Errorf("A: %s", a)
+		t.Errorf("B: %s", b)
+	}
+
+	exp := `{"address":{"city":"Miami","zip":"33101"},"age":30,"name":"Bill","score":1.5,"tags":["x","y"]}`
+	if a != exp {
+		t.Errorf("Should get the canonical form")
+		t.Errorf("GOT: %s", a)
+		t.Errorf("EXP: %s", exp)
+	}
+}
@@ -0,0 +1,64 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+)
+
+// responseWriter keeps a copy of the start of the response body so
+// middleware can see what was sent to the client without encoding the
+// response a second time.
+type responseWriter struct {
+	http.ResponseWriter
+	captures []*capture
+}
+
+type capture struct {
+	max int
+	buf bytes.Buffer
+	fn  func(body []byte)
+}
+
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w}
+}
+
+// Write sends the data to the client and copies what fits into each capture.
+func (w *responseWriter) Write(data []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(data)
+
+	for _, c := range w.captures {
+		if room := c.max - c.buf.Len(); room > 0 {
+			c.buf.Write(data[:min(n, room)])
+		}
+	}
+
+	return n, err
+}
+
+// Unwrap returns the original writer so http.ResponseController can flush
+// streaming responses.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// done passes the captured bodies to the functions waiting for them once the
+// response has been written.
+func (w *responseWriter) done() {
+	for _, c := range w.captures {
+		c.fn(c.buf.Bytes())
+	}
+}
+
+// CaptureResponse calls the function with up to max bytes of the response
+// body once the response for the request has been written. Nothing is
+// captured for raw handlers that write to the client directly.
+func CaptureResponse(ctx context.Context, max int, fn func(body []byte)) {
+	w, ok := getWriter(ctx).(*responseWriter)
+	if !ok {
+		return
+	}
+
+	w.captures = append(w.captures, &capture{max: max, fn: fn})
+}
@@ -0,0 +1,51 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_CaptureResponse(t *testing.T) {
+	t.Parallel()
+
+	var captured []byte
+	capture := func(handler web.HandlerFunc) web.HandlerFunc {
+		return func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+			web.CaptureResponse(ctx, 5, func(body []byte) {
+				captured = append([]byte(nil), body...)
+			})
+
+			return handler(ctx, r)
+		}
+	}
+
+	stream := func(ctx context.Context, send func(row) error) error {
+		for i := range 3 {
+			if err := send(row{N: i}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil, capture)
+	app.HandlerFunc(http.MethodGet, "v1", "/export", func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return web.NDJSON[row]{Stream: stream, FlushEvery: 1}, nil
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/export", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if !w.Flushed {
+		t.Errorf("Should still flush the streamed response")
+	}
+
+	if exp := w.Body.String()[:5]; string(captured) != exp {
+		t.Errorf("Should capture the start of the written body : got[%q] exp[%q]", captured, exp)
+	}
+}
@@ -2,23 +2,58 @@ package web
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 )
 
-type ctxKey int
+// ContextKey is a typed key for a value stored in a context. Using a typed
+// key removes the type assertion from every call site and, since each key is
+// a distinct pointer, a key can't collide with one defined elsewhere.
+type ContextKey[T any] struct {
+	name string
+}
+
+// NewContextKey constructs a key for values of type T. The name is only used
+// to identify the key when debugging.
+func NewContextKey[T any](name string) *ContextKey[T] {
+	return &ContextKey[T]{name: name}
+}
+
+// Set returns a copy of the context that carries the value.
+func (k *ContextKey[T]) Set(ctx context.Context, v T) context.Context {
+	return context.WithValue(ctx, k, v)
+}
+
+// Get returns the value from the context. If the context doesn't carry a
+// value for the key, the zero value and false are returned.
+func (k *ContextKey[T]) Get(ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(k).(T)
+	return v, ok
+}
+
+// String returns the name of the key.
+func (k *ContextKey[T]) String() string {
+	return k.name
+}
+
+// =============================================================================
 
-const (
-	traceKey ctxKey = iota + 1
-	writer
+var (
+	traceKey  = NewContextKey[string]("trace_id")
+	writerKey = NewContextKey[http.ResponseWriter]("writer")
+	startKey  = NewContextKey[time.Time]("start_time")
 )
 
 func setTraceID(ctx context.Context, traceID string) context.Context {
-	return context.WithValue(ctx, traceKey, traceID)
+	return traceKey.Set(ctx, traceID)
 }
 
 // GetTraceID returns the trace id from the context.
 func GetTraceID(ctx context.Context) string {
-	v, ok := ctx.Value(traceKey).(string)
+	v, ok := traceKey.Get(ctx)
 	if !ok {
 		return "00000000-0000-0000-0000-000000000000"
 	}
@@ -26,15 +61,65 @@ func GetTraceID(ctx context.Context) string {
 	return v
 }
 
-func setWriter(ctx context.Context, w http.ResponseWriter) context.Context {
-	return context.WithValue(ctx, writer, w)
+func setStartTime(ctx context.Context, start time.Time) context.Context {
+	return startKey.Set(ctx, start)
 }
 
-func getWriter(ctx context.Context) http.ResponseWriter {
-	v, ok := ctx.Value(writer).(http.ResponseWriter)
+// GetStartTime returns the time the request was received. It's the zero time
+// when the context isn't for a request.
+func GetStartTime(ctx context.Context) time.Time {
+	v, _ := startKey.Get(ctx)
+	return v
+}
+
+// Elapsed returns the time since the request was received, so every layer
+// measures the request from the same point. It's zero when the context isn't
+// for a request.
+func Elapsed(ctx context.Context) time.Duration {
+	start, ok := startKey.Get(ctx)
 	if !ok {
-		return nil
+		return 0
 	}
 
+	return time.Since(start)
+}
+
+func setWriter(ctx context.Context, w http.ResponseWriter) context.Context {
+	return writerKey.Set(ctx, w)
+}
+
+func getWriter(ctx context.Context) http.ResponseWriter {
+	v, _ := writerKey.Get(ctx)
 	return v
 }
+
+// SetHeader sets a header on the response for the request. It's used by
+// middleware that needs to add a header regardless of the handler's
+// response.
+func SetHeader(ctx context.Context, key string, value string) {
+	if w := getWriter(ctx); w != nil {
+		w.Header().Set(key, value)
+	}
+}
+
+// warningText escapes the text so it's a valid quoted string.
+var warningText = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", " ", "\n", " ")
+
+// AddWarning attaches a non-fatal warning to the response for the request,
+// such as the use of a deprecated input. Warnings are sent as Warning headers
+// with the miscellaneous persistent warning code and don't change the status
+// of the response. They must be added before the response is written.
+func AddWarning(ctx context.Context, text string) {
+	w := getWriter(ctx)
+	if w == nil {
+		return
+	}
+
+	value := fmt.Sprintf(`299 - "%s"`, warningText.Replace(text))
+
+	if slices.Contains(w.Header().Values("Warning"), value) {
+		return
+	}
+
+	w.Header().Add("Warning", value)
+}
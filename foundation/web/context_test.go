@@ -0,0 +1,130 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type user struct {
+	Name string
+}
+
+func Test_ContextKey(t *testing.T) {
+	ctx := context.Background()
+
+	userKey := web.NewContextKey[user]("user")
+	otherKey := web.NewContextKey[user]("user")
+	countKey := web.NewContextKey[int]("count")
+
+	if v, ok := userKey.Get(ctx); ok || v != (user{}) {
+		t.Errorf("Should get the zero value and false for a missing value, got %+v %v", v, ok)
+	}
+
+	ctx = userKey.Set(ctx, user{Name: "bill"})
+	ctx = countKey.Set(ctx, 0)
+
+	if v, ok := userKey.Get(ctx); !ok || v.Name != "bill" {
+		t.Errorf("Should get the value back, got %+v %v", v, ok)
+	}
+
+	if v, ok := countKey.Get(ctx); !ok || v != 0 {
+		t.Errorf("Should tell a stored zero value from a missing one, got %d %v", v, ok)
+	}
+
+	if _, ok := otherKey.Get(ctx); ok {
+		t.Errorf("Should not collide with a different key of the same type and name")
+	}
+
+	if s := userKey.String(); s != "user" {
+		t.Errorf("Should get the name of the key, got %q", s)
+	}
+}
+
+func Test_ContextKeyInterface(t *testing.T) {
+	ctx := context.Background()
+
+	key := web.NewContextKey[error]("err")
+
+	if v, ok := key.Get(ctx); ok || v != nil {
+		t.Errorf("Should get nil and false for a missing value, got %v %v", v, ok)
+	}
+
+	ctx = key.Set(ctx, context.Canceled)
+
+	if v, ok := key.Get(ctx); !ok || v != context.Canceled {
+		t.Errorf("Should get the value back, got %v %v", v, ok)
+	}
+}
+
+func Test_GetTraceID(t *testing.T) {
+	if id := web.GetTraceID(context.Background()); id != "00000000-0000-0000-0000-000000000000" {
+		t.Errorf("Should get the zero trace id for a missing value, got %q", id)
+	}
+}
+
+func Test_Elapsed(t *testing.T) {
+	if d := web.Elapsed(context.Background()); d != 0 {
+		t.Errorf("Should get zero outside of a request, got %s", d)
+	}
+
+	var starts []time.Time
+	var elapsed []time.Duration
+
+	record := func(ctx context.Context) {
+		starts = append(starts, web.GetStartTime(ctx))
+		elapsed = append(elapsed, web.Elapsed(ctx))
+	}
+
+	layer := func(handler web.HandlerFunc) web.HandlerFunc {
+		return func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+			record(ctx)
+			time.Sleep(10 * time.Millisecond)
+
+			resp, err := handler(ctx, r)
+
+			record(ctx)
+
+			return resp, err
+		}
+	}
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		record(ctx)
+		return nil, nil
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil, layer, layer)
+	app.HandlerFunc(http.MethodGet, "", "/elapsed", handler)
+
+	before := time.Now()
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/elapsed", nil))
+
+	if len(starts) != 5 {
+		t.Fatalf("Should record every layer, got %d", len(starts))
+	}
+
+	for i, start := range starts {
+		if !start.Equal(starts[0]) {
+			t.Errorf("Should see the same start time in every layer, got %s at %d exp %s", start, i, starts[0])
+		}
+	}
+
+	if starts[0].Before(before) {
+		t.Errorf("Should start the request when it's received, got %s before %s", starts[0], before)
+	}
+
+	for i := 1; i < len(elapsed); i++ {
+		if elapsed[i] < elapsed[i-1] {
+			t.Errorf("Should never measure less time in a later layer, got %s after %s", elapsed[i], elapsed[i-1])
+		}
+	}
+
+	if elapsed[2] < 20*time.Millisecond {
+		t.Errorf("Should include the time spent in the outer layers, got %s", elapsed[2])
+	}
+}
@@ -0,0 +1,101 @@
+package web
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CSVRecorder is implemented by values that can be written as a CSV record.
+type CSVRecorder interface {
+	CSVRecord() []string
+}
+
+// CSV represents a response that streams comma-separated values. The Header
+// is written as the first record, then Stream is called once and every value
+// passed to send is written as a single record, so the full result set is
+// never held in memory. Values are quoted and escaped as defined by RFC 4180.
+// The response is flushed every FlushEvery values and when Stream returns.
+// Stream should stop and return the error when send fails.
+type CSV[T CSVRecorder] struct {
+	Header     []string
+	Stream     func(ctx context.Context, send func(T) error) error
+	FlushEvery int
+}
+
+// Encode implements the encoder interface. A CSV response can only be
+// streamed, so this is only called when it's used incorrectly.
+func (c CSV[T]) Encode() ([]byte, string, error) {
+	return nil, "", errors.New("csv: response can only be streamed")
+}
+
+func (c CSV[T]) stream(ctx context.Context, w http.ResponseWriter) error {
+	rc := http.NewResponseController(w)
+
+	// The stream is expected to outlive the server's write timeout.
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	flushEvery := c.FlushEvery
+	if flushEvery <= 0 {
+		flushEvery = 100
+	}
+
+	cw := csv.NewWriter(w)
+
+	// The csv writer buffers records, so write errors are reported when
+	// it's flushed.
+	flush := func() error {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return writeError("csv", err)
+		}
+
+		if err := rc.Flush(); err != nil {
+			return fmt.Errorf("csv: flush: %w", err)
+		}
+
+		return nil
+	}
+
+	if len(c.Header) > 0 {
+		if err := cw.Write(c.Header); err != nil {
+			return fmt.Errorf("csv: header: %w", err)
+		}
+	}
+
+	var pending int
+
+	send := func(v T) error {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("csv: %w", err)
+		}
+
+		if err := cw.Write(v.CSVRecord()); err != nil {
+			return fmt.Errorf("csv: record: %w", err)
+		}
+
+		pending++
+		if pending >= flushEvery {
+			pending = 0
+			return flush()
+		}
+
+		return nil
+	}
+
+	if err := c.Stream(ctx, send); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return fmt.Errorf("csv: %w: %w", errClientGone, err)
+		}
+		return err
+	}
+
+	return flush()
+}
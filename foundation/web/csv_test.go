@@ -0,0 +1,79 @@
+package web_test
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type record struct {
+	N    int
+	Name string
+}
+
+func (r record) CSVRecord() []string {
+	return []string{strconv.Itoa(r.N), r.Name}
+}
+
+func Test_CSV(t *testing.T) {
+	t.Parallel()
+
+	const rows = 1_000
+
+	// Every few names need quoting.
+	names := []string{"plain", "with, comma", "with \"quotes\"", "with\nnewline"}
+
+	stream := func(ctx context.Context, send func(record) error) error {
+		for i := range rows {
+			if err := send(record{N: i, Name: names[i%len(names)]}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil)
+	app.HandlerFunc(http.MethodGet, "v1", "/export", func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return web.CSV[record]{Header: []string{"n", "name"}, Stream: stream, FlushEvery: 250}, nil
+	})
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/v1/export")
+	if err != nil {
+		t.Fatalf("Should be able to make the request : %s", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Fatalf("Should get the csv content type : %s", ct)
+	}
+
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Should be able to parse the csv : %s", err)
+	}
+
+	if len(records) != rows+1 {
+		t.Fatalf("Should get the header and every row, got %d, exp %d", len(records), rows+1)
+	}
+
+	if !slices.Equal(records[0], []string{"n", "name"}) {
+		t.Fatalf("Should get the header row first : %q", records[0])
+	}
+
+	for i, rec := range records[1:] {
+		exp := []string{fmt.Sprint(i), names[i%len(names)]}
+		if !slices.Equal(rec, exp) {
+			t.Fatalf("Should get the rows in order, got %q, exp %q", rec, exp)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package web
+
+import (
+	"net/http"
+	"slices"
+)
+
+// Group represents a set of routes that share a path prefix and middleware.
+// The middleware of a group runs after the application middleware and before
+// the middleware of a route, in the order it was provided.
+type Group struct {
+	app    *App
+	group  string
+	prefix string
+	mw     []MidFunc
+}
+
+// Group constructs a group of routes for the version group whose paths start
+// with the prefix.
+func (a *App) Group(group string, prefix string, mw ...MidFunc) *Group {
+	return &Group{
+		app:    a,
+		group:  group,
+		prefix: prefix,
+		mw:     mw,
+	}
+}
+
+// Group constructs a nested group whose prefix and middleware are added to
+// those of the parent group. The parent middleware runs first.
+func (g *Group) Group(prefix string, mw ...MidFunc) *Group {
+	return &Group{
+		app:    g.app,
+		group:  g.group,
+		prefix: g.prefix + prefix,
+		mw:     slices.Concat(g.mw, mw),
+	}
+}
+
+// HandlerFunc sets a handler function for a given HTTP method and path pair
+// within the group.
+func (g *Group) HandlerFunc(method string, path string, handlerFunc HandlerFunc, mw ...MidFunc) {
+	g.app.HandlerFunc(method, g.group, g.prefix+path, handlerFunc, slices.Concat(g.mw, mw)...)
+}
+
+// RawHandlerFunc sets a raw handler function for a given HTTP method and
+// path pair within the group.
+func (g *Group) RawHandlerFunc(method string, path string, rawHandlerFunc http.HandlerFunc, mw ...MidFunc) {
+	g.app.RawHandlerFunc(method, g.group, g.prefix+path, rawHandlerFunc, slices.Concat(g.mw, mw)...)
+}
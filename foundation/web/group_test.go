@@ -0,0 +1,79 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_Group(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+
+	record := func(name string) web.MidFunc {
+		return func(next web.HandlerFunc) web.HandlerFunc {
+			return func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+				mu.Lock()
+				order = append(order, name)
+				mu.Unlock()
+
+				return next(ctx, r)
+			}
+		}
+	}
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return nil, nil
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil, record("global"))
+
+	admin := app.Group("v1", "/admin", record("admin-1"), record("admin-2"))
+	admin.HandlerFunc(http.MethodGet, "/config", handler, record("route"))
+
+	users := admin.Group("/users", record("users"))
+	users.HandlerFunc(http.MethodGet, "/{user_id}", handler)
+
+	app.HandlerFunc(http.MethodGet, "v1", "/public", handler)
+
+	table := []struct {
+		name string
+		path string
+		exp  []string
+	}{
+		{name: "group", path: "/v1/admin/config", exp: []string{"global", "admin-1", "admin-2", "route"}},
+		{name: "nested", path: "/v1/admin/users/123", exp: []string{"global", "admin-1", "admin-2", "users"}},
+		{name: "ungrouped", path: "/v1/public", exp: []string{"global"}},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			order = nil
+			mu.Unlock()
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("Should route the request : %d", w.Code)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if !slices.Equal(order, tt.exp) {
+				t.Errorf("Should run the middleware in order, got %v, exp %v", order, tt.exp)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package web
+
+import (
+	"context"
+	"net/http"
+)
+
+// EarlyHints sends a 103 Early Hints response with the specified Link header
+// values, such as `</app.css>; rel=preload; as=style`, so the client can start
+// fetching them while the handler prepares the final response. The links are
+// also sent with the final response. Browsers only act on early hints over
+// HTTP/2 and later, so nothing is sent for older protocols. It must be called
+// before the response is written and reports whether the hints were sent.
+func EarlyHints(ctx context.Context, r *http.Request, links ...string) bool {
+	if r.ProtoMajor < 2 || len(links) == 0 {
+		return false
+	}
+
+	w := getWriter(ctx)
+	if w == nil {
+		return false
+	}
+
+	for _, link := range links {
+		w.Header().Add("Link", link)
+	}
+
+	w.WriteHeader(http.StatusEarlyHints)
+
+	return true
+}
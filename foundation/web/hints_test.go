@@ -0,0 +1,93 @@
+package web_test
+
+import (
+	"context"
+	"io"
+	stdlog "log"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_EarlyHints(t *testing.T) {
+	t.Parallel()
+
+	const link = "</app.css>; rel=preload; as=style"
+
+	table := []struct {
+		name  string
+		http2 bool
+		sent  bool
+	}{
+		{name: "http2", http2: true, sent: true},
+		{name: "http1", http2: false, sent: false},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var sent bool
+
+			app := web.NewApp(func(context.Context, string, ...any) {}, nil)
+			app.HandlerFunc(http.MethodGet, "v1", "/page", func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+				sent = web.EarlyHints(ctx, r, link)
+				return nil, nil
+			})
+
+			srv := httptest.NewUnstartedServer(app)
+			srv.EnableHTTP2 = tt.http2
+			srv.Config.ErrorLog = stdlog.New(io.Discard, "", 0)
+			srv.StartTLS()
+			defer srv.Close()
+
+			var hints []string
+			trace := httptrace.ClientTrace{
+				Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+					if code == http.StatusEarlyHints {
+						hints = append(hints, header.Values("Link")...)
+					}
+					return nil
+				},
+			}
+
+			req, err := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), &trace), http.MethodGet, srv.URL+"/v1/page", nil)
+			if err != nil {
+				t.Fatalf("Should be able to create the request : %s", err)
+			}
+
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("Should be able to make the request : %s", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusNoContent {
+				t.Fatalf("Should get the final response, got %d", resp.StatusCode)
+			}
+
+			if sent != tt.sent {
+				t.Fatalf("Should report whether the hints were sent, got %v, exp %v", sent, tt.sent)
+			}
+
+			if !tt.sent {
+				if len(hints) != 0 {
+					t.Errorf("Should not send hints over %s : %v", resp.Proto, hints)
+				}
+				return
+			}
+
+			if len(hints) != 1 || hints[0] != link {
+				t.Errorf("Should get the link in the early hints : %v", hints)
+			}
+
+			if got := resp.Header.Get("Link"); got != link {
+				t.Errorf("Should get the link with the final response : %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// MergePatchContentType is the media type of a JSON Merge Patch document.
+const MergePatchContentType = "application/merge-patch+json"
+
+// MergePatch represents a JSON Merge Patch document as described by
+// RFC 7386. It implements the decoder interface so it can be used with
+// Decode.
+type MergePatch json.RawMessage
+
+// Decode implements the decoder interface.
+func (p *MergePatch) Decode(data []byte) error {
+	if !json.Valid(data) {
+		return fmt.Errorf("%w: malformed json", ErrInvalidPatch)
+	}
+
+	*p = MergePatch(data)
+
+	return nil
+}
+
+// Apply merges the patch into the JSON document and returns the patched
+// document. Members set to null in the patch are removed.
+func (p MergePatch) Apply(doc []byte) ([]byte, error) {
+	patch, err := decodeValue(p)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+	}
+
+	target, err := decodeValue(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: document: %w", ErrInvalidPatch, err)
+	}
+
+	return json.Marshal(mergeValue(target, patch))
+}
+
+func mergeValue(target any, patch any) any {
+	pm, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	tm, ok := target.(map[string]any)
+	if !ok {
+		tm = make(map[string]any)
+	}
+
+	for key, value := range pm {
+		if value == nil {
+			delete(tm, key)
+			continue
+		}
+
+		tm[key] = mergeValue(tm[key], value)
+	}
+
+	return tm
+}
+
+// =============================================================================
+
+// Patcher represents a patch document that can be applied to a JSON
+// document.
+type Patcher interface {
+	Apply(doc []byte) ([]byte, error)
+}
+
+// DecodePatch reads the patch document from the request body. The content
+// type selects between a JSON Patch and a JSON Merge Patch.
+func DecodePatch(r *http.Request) (Patcher, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: content type: %w", ErrInvalidPatch, err)
+	}
+
+	switch mediaType {
+	case PatchContentType:
+		var patch Patch
+		if err := Decode(r, &patch); err != nil {
+			return nil, err
+		}
+		return patch, nil
+
+	case MergePatchContentType:
+		var patch MergePatch
+		if err := Decode(r, &patch); err != nil {
+			return nil, err
+		}
+		return patch, nil
+	}
+
+	return nil, fmt.Errorf("%w: unsupported content type %q", ErrInvalidPatch, mediaType)
+}
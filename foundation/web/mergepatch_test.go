@@ -0,0 +1,94 @@
+package web_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_MergePatch(t *testing.T) {
+	t.Parallel()
+
+	doc := `{"name":"Bill","department":"IT","address":{"city":"Miami","zip":"33101"},"tags":["a","b"]}`
+
+	table := []struct {
+		name  string
+		patch string
+		exp   string
+	}{
+		{
+			name:  "update",
+			patch: `{"name":"Jill","tags":["c"]}`,
+			exp:   `{"name":"Jill","department":"IT","address":{"city":"Miami","zip":"33101"},"tags":["c"]}`,
+		},
+		{
+			name:  "delete",
+			patch: `{"department":null,"missing":null}`,
+			exp:   `{"name":"Bill","address":{"city":"Miami","zip":"33101"},"tags":["a","b"]}`,
+		},
+		{
+			name:  "nested",
+			patch: `{"address":{"city":"Austin","zip":null,"state":"TX"}}`,
+			exp:   `{"name":"Bill","department":"IT","address":{"city":"Austin","state":"TX"},"tags":["a","b"]}`,
+		},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(tt.patch))
+			r.Header.Set("Content-Type", web.MergePatchContentType)
+
+			patch, err := web.DecodePatch(r)
+			if err != nil {
+				t.Fatalf("Should be able to decode the patch : %s", err)
+			}
+
+			if _, ok := patch.(web.MergePatch); !ok {
+				t.Fatalf("Should get a merge patch for the content type : %T", patch)
+			}
+
+			got, err := patch.Apply([]byte(doc))
+			if err != nil {
+				t.Fatalf("Should be able to apply the patch : %s", err)
+			}
+
+			var gotDoc, expDoc any
+			json.Unmarshal(got, &gotDoc)
+			json.Unmarshal([]byte(tt.exp), &expDoc)
+
+			if diff := cmp.Diff(gotDoc, expDoc); diff != "" {
+				t.Errorf("Should get the expected document, diff:\n%s", diff)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
+
+func Test_DecodePatchContentType(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`[]`))
+	r.Header.Set("Content-Type", web.PatchContentType)
+
+	patch, err := web.DecodePatch(r)
+	if err != nil {
+		t.Fatalf("Should be able to decode the patch : %s", err)
+	}
+
+	if _, ok := patch.(web.Patch); !ok {
+		t.Fatalf("Should get a json patch for the content type : %T", patch)
+	}
+
+	r = httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	if _, err := web.DecodePatch(r); err == nil {
+		t.Fatalf("Should not decode a patch with an unsupported content type")
+	}
+}
@@ -0,0 +1,94 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// NDJSON represents a response that streams newline-delimited JSON. Stream is
+// called once and every value passed to send is encoded and written as a
+// single line, so the full result set is never held in memory. The response
+// is flushed every FlushEvery values and when Stream returns. Stream should
+// stop and return the error when send fails.
+type NDJSON[T Encoder] struct {
+	Stream     func(ctx context.Context, send func(T) error) error
+	FlushEvery int
+}
+
+// Encode implements the encoder interface. An NDJSON response can only be
+// streamed, so this is only called when it's used incorrectly.
+func (nd NDJSON[T]) Encode() ([]byte, string, error) {
+	return nil, "", errors.New("ndjson: response can only be streamed")
+}
+
+func (nd NDJSON[T]) stream(ctx context.Context, w http.ResponseWriter) error {
+	rc := http.NewResponseController(w)
+
+	// The stream is expected to outlive the server's write timeout.
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	flushEvery := nd.FlushEvery
+	if flushEvery <= 0 {
+		flushEvery = 100
+	}
+
+	var (
+		buf     bytes.Buffer
+		pending int
+	)
+
+	send := func(v T) error {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("ndjson: %w", err)
+		}
+
+		data, _, err := v.Encode()
+		if err != nil {
+			return fmt.Errorf("ndjson: encode: %w", err)
+		}
+
+		// Each value must be on a single line, regardless of how the
+		// encoder formatted it.
+		buf.Reset()
+		if err := json.Compact(&buf, data); err != nil {
+			return fmt.Errorf("ndjson: compact: %w", err)
+		}
+		buf.WriteByte('\n')
+
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return writeError("ndjson", err)
+		}
+
+		pending++
+		if pending >= flushEvery {
+			pending = 0
+			if err := rc.Flush(); err != nil {
+				return fmt.Errorf("ndjson: flush: %w", err)
+			}
+		}
+
+		return nil
+	}
+
+	if err := nd.Stream(ctx, send); err != nil {
+		if errors.Is(err, context.Canceled) {
+			return fmt.Errorf("ndjson: %w: %w", errClientGone, err)
+		}
+		return err
+	}
+
+	if err := rc.Flush(); err != nil {
+		return fmt.Errorf("ndjson: flush: %w", err)
+	}
+
+	return nil
+}
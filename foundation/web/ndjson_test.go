@@ -0,0 +1,125 @@
+package web_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type row struct {
+	N    int    `json:"n"`
+	Name string `json:"name"`
+}
+
+func (r row) Encode() ([]byte, string, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	return data, "application/json", err
+}
+
+func Test_NDJSON(t *testing.T) {
+	t.Parallel()
+
+	const rows = 10_000
+
+	stream := func(ctx context.Context, send func(row) error) error {
+		for i := range rows {
+			if err := send(row{N: i, Name: fmt.Sprintf("row %d", i)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil)
+	app.HandlerFunc(http.MethodGet, "v1", "/export", func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return web.NDJSON[row]{Stream: stream, FlushEvery: 250}, nil
+	})
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/v1/export")
+	if err != nil {
+		t.Fatalf("Should be able to make the request : %s", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf("Should get the ndjson content type : %s", ct)
+	}
+
+	var n int
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var got row
+		if err := json.Unmarshal(scanner.Bytes(), &got); err != nil {
+			t.Fatalf("Should be able to parse line %d : %s : %q", n, err, scanner.Text())
+		}
+
+		if got.N != n {
+			t.Fatalf("Should get the rows in order, got %d, exp %d", got.N, n)
+		}
+
+		n++
+	}
+
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Should be able to read the full stream : %s", err)
+	}
+
+	if n != rows {
+		t.Errorf("Should get every row, got %d, exp %d", n, rows)
+	}
+}
+
+func Test_NDJSONCanceled(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan error, 1)
+
+	stream := func(ctx context.Context, send func(row) error) error {
+		for i := 0; ; i++ {
+			if err := send(row{N: i}); err != nil {
+				done <- err
+				return err
+			}
+		}
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil)
+	app.HandlerFunc(http.MethodGet, "v1", "/export", func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return web.NDJSON[row]{Stream: stream, FlushEvery: 10}, nil
+	})
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/v1/export")
+	if err != nil {
+		t.Fatalf("Should be able to make the request : %s", err)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for i := 0; i < 100 && scanner.Scan(); i++ {
+	}
+
+	// Hang up in the middle of the stream.
+	resp.Body.Close()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatalf("Should stop the stream with an error")
+		}
+
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Should stop the stream after the client disconnects")
+	}
+}
@@ -0,0 +1,100 @@
+package web
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Negotiate returns the offered media type that best matches the Accept
+// header of the request. Offers are listed in order of preference, which
+// breaks ties between media types the client accepts equally. A request
+// without an Accept header gets the first offer and an empty string is
+// returned when the client doesn't accept any of the offers.
+func Negotiate(r *http.Request, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+
+	accept := r.Header.Values("Accept")
+	if len(accept) == 0 {
+		return offers[0]
+	}
+
+	var (
+		best            string
+		bestQ           float64
+		bestSpecificity int
+	)
+
+	for _, offer := range offers {
+		q, specificity := acceptQuality(accept, offer)
+		if q <= 0 {
+			continue
+		}
+
+		if q > bestQ || (q == bestQ && specificity > bestSpecificity) {
+			best, bestQ, bestSpecificity = offer, q, specificity
+		}
+	}
+
+	return best
+}
+
+// acceptQuality returns the quality the Accept header assigns to the media
+// type, using the most specific range that matches it. The specificity is
+// 0 for */*, 1 for type/* and 2 for an exact match.
+func acceptQuality(accept []string, mediaType string) (float64, int) {
+	q, specificity := 0.0, -1
+
+	for _, header := range accept {
+		for _, part := range strings.Split(header, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			rng, params, err := mime.ParseMediaType(part)
+			if err != nil {
+				continue
+			}
+
+			s := matchMediaRange(rng, mediaType)
+			if s <= specificity {
+				continue
+			}
+
+			rq := 1.0
+			if v, ok := params["q"]; ok {
+				rq, err = strconv.ParseFloat(v, 64)
+				if err != nil || rq < 0 || rq > 1 {
+					continue
+				}
+			}
+
+			q, specificity = rq, s
+		}
+	}
+
+	return q, specificity
+}
+
+// matchMediaRange returns the specificity of the media range when it
+// matches the media type and -1 when it doesn't.
+func matchMediaRange(rng string, mediaType string) int {
+	if rng == "*/*" {
+		return 0
+	}
+
+	if rng == mediaType {
+		return 2
+	}
+
+	prefix, ok := strings.CutSuffix(rng, "/*")
+	if ok && strings.HasPrefix(mediaType, prefix+"/") {
+		return 1
+	}
+
+	return -1
+}
@@ -0,0 +1,42 @@
+package web_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_Negotiate(t *testing.T) {
+	t.Parallel()
+
+	offers := []string{"application/x-ndjson", "text/csv"}
+
+	table := []struct {
+		name   string
+		accept string
+		exp    string
+	}{
+		{name: "missing", accept: "", exp: "application/x-ndjson"},
+		{name: "exact", accept: "text/csv", exp: "text/csv"},
+		{name: "wildcard", accept: "*/*", exp: "application/x-ndjson"},
+		{name: "type-wildcard", accept: "text/*", exp: "text/csv"},
+		{name: "quality", accept: "application/x-ndjson;q=0.5, text/csv", exp: "text/csv"},
+		{name: "specific-over-wildcard", accept: "*/*, text/csv", exp: "text/csv"},
+		{name: "excluded", accept: "text/csv;q=0, */*", exp: "application/x-ndjson"},
+		{name: "none", accept: "application/json", exp: ""},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+
+			if got := web.Negotiate(r, offers...); got != tt.exp {
+				t.Errorf("Should negotiate the media type, got %q, exp %q", got, tt.exp)
+			}
+		})
+	}
+}
@@ -0,0 +1,372 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPatch is returned when a JSON Patch document is malformed or
+// can't be applied to the resource.
+var ErrInvalidPatch = errors.New("invalid patch")
+
+// PatchContentType is the media type of a JSON Patch document.
+const PatchContentType = "application/json-patch+json"
+
+// PatchOp represents a single operation of a JSON Patch document.
+type PatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Patch represents a JSON Patch document as described by RFC 6902. It
+// implements the decoder interface so it can be used with Decode.
+type Patch []PatchOp
+
+// Decode implements the decoder interface.
+func (p *Patch) Decode(data []byte) error {
+	if err := json.Unmarshal(data, p); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+	}
+
+	return nil
+}
+
+// Validate checks the operations are well formed.
+func (p Patch) Validate() error {
+	for i, op := range p {
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				return fmt.Errorf("%w: op[%d] %s: missing value", ErrInvalidPatch, i, op.Op)
+			}
+
+		case "move", "copy":
+			if _, err := parsePointer(op.From); err != nil {
+				return fmt.Errorf("%w: op[%d] %s: from: %w", ErrInvalidPatch, i, op.Op, err)
+			}
+
+		case "remove":
+
+		default:
+			return fmt.Errorf("%w: op[%d]: unknown op %q", ErrInvalidPatch, i, op.Op)
+		}
+
+		if _, err := parsePointer(op.Path); err != nil {
+			return fmt.Errorf("%w: op[%d] %s: path: %w", ErrInvalidPatch, i, op.Op, err)
+		}
+	}
+
+	return nil
+}
+
+// Apply applies the operations in order to the JSON document and returns
+// the patched document. If any operation fails, none of them are applied.
+func (p Patch) Apply(doc []byte) ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	root, err := decodeValue(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: document: %w", ErrInvalidPatch, err)
+	}
+
+	for i, op := range p {
+		root, err = applyOp(root, op)
+		if err != nil {
+			return nil, fmt.Errorf("%w: op[%d] %s %s: %w", ErrInvalidPatch, i, op.Op, op.Path, err)
+		}
+	}
+
+	return json.Marshal(root)
+}
+
+func applyOp(root any, op PatchOp) (any, error) {
+	path, _ := parsePointer(op.Path)
+
+	switch op.Op {
+	case "add":
+		value, err := decodeValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(root, path, value)
+
+	case "remove":
+		return removeValue(root, path)
+
+	case "replace":
+		value, err := decodeValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := getValue(root, path); err != nil {
+			return nil, err
+		}
+		if root, err = removeValue(root, path); err != nil {
+			return nil, err
+		}
+		return addValue(root, path, value)
+
+	case "move":
+		from, _ := parsePointer(op.From)
+		if isPrefix(from, path) && len(from) < len(path) {
+			return nil, errors.New("can't move a value into itself")
+		}
+		value, err := getValue(root, from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if root, err = removeValue(root, from); err != nil {
+			return nil, err
+		}
+		return addValue(root, path, value)
+
+	case "copy":
+		from, _ := parsePointer(op.From)
+		value, err := getValue(root, from)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if value, err = deepCopy(value); err != nil {
+			return nil, err
+		}
+		return addValue(root, path, value)
+
+	case "test":
+		exp, err := decodeValue(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		got, err := getValue(root, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(got, exp) {
+			return nil, errors.New("test failed")
+		}
+		return root, nil
+	}
+
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+// =============================================================================
+
+// parsePointer parses a JSON Pointer as described by RFC 6901.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("pointer %q must start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		t = strings.ReplaceAll(t, "~1", "/")
+		tokens[i] = strings.ReplaceAll(t, "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+func isPrefix(prefix []string, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func getValue(node any, path []string) (any, error) {
+	for _, token := range path {
+		switch v := node.(type) {
+		case map[string]any:
+			child, exists := v[token]
+			if !exists {
+				return nil, fmt.Errorf("path not found at %q", token)
+			}
+			node = child
+
+		case []any:
+			i, err := arrayIndex(token, len(v)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = v[i]
+
+		default:
+			return nil, fmt.Errorf("path not found at %q", token)
+		}
+	}
+
+	return node, nil
+}
+
+// addValue adds the value at the path and returns the new root, since
+// adding to an array produces a new slice.
+func addValue(node any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	token := path[0]
+
+	switch v := node.(type) {
+	case map[string]any:
+		if len(path) == 1 {
+			v[token] = value
+			return v, nil
+		}
+
+		child, exists := v[token]
+		if !exists {
+			return nil, fmt.Errorf("path not found at %q", token)
+		}
+
+		child, err := addValue(child, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		v[token] = child
+
+		return v, nil
+
+	case []any:
+		if len(path) == 1 {
+			if token == "-" {
+				return append(v, value), nil
+			}
+
+			i, err := arrayIndex(token, len(v))
+			if err != nil {
+				return nil, err
+			}
+
+			v = append(v, nil)
+			copy(v[i+1:], v[i:])
+			v[i] = value
+
+			return v, nil
+		}
+
+		i, err := arrayIndex(token, len(v)-1)
+		if err != nil {
+			return nil, err
+		}
+
+		child, err := addValue(v[i], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		v[i] = child
+
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("path not found at %q", token)
+}
+
+// removeValue removes the value at the path and returns the new root.
+func removeValue(node any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, errors.New("can't remove the whole document")
+	}
+
+	token := path[0]
+
+	switch v := node.(type) {
+	case map[string]any:
+		child, exists := v[token]
+		if !exists {
+			return nil, fmt.Errorf("path not found at %q", token)
+		}
+
+		if len(path) == 1 {
+			delete(v, token)
+			return v, nil
+		}
+
+		child, err := removeValue(child, path[1:])
+		if err != nil {
+			return nil, err
+		}
+		v[token] = child
+
+		return v, nil
+
+	case []any:
+		i, err := arrayIndex(token, len(v)-1)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(path) == 1 {
+			return append(v[:i], v[i+1:]...), nil
+		}
+
+		child, err := removeValue(v[i], path[1:])
+		if err != nil {
+			return nil, err
+		}
+		v[i] = child
+
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("path not found at %q", token)
+}
+
+// arrayIndex parses the token as an index that must not be greater than max.
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	if i > max {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+
+	return i, nil
+}
+
+func decodeValue(data []byte) (any, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func deepCopy(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeValue(data)
+}
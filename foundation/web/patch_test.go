@@ -0,0 +1,102 @@
+package web_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Patch(t *testing.T) {
+	t.Parallel()
+
+	doc := `{"name":"Bill","email":"bill@example.com","tags":["a","b"],"address":{"city":"Miami"}}`
+
+	table := []struct {
+		name  string
+		patch string
+		exp   string
+	}{
+		{
+			name:  "add",
+			patch: `[{"op":"add","path":"/department","value":"IT"},{"op":"add","path":"/tags/1","value":"x"},{"op":"add","path":"/tags/-","value":"z"}]`,
+			exp:   `{"name":"Bill","email":"bill@example.com","department":"IT","tags":["a","x","b","z"],"address":{"city":"Miami"}}`,
+		},
+		{
+			name:  "remove",
+			patch: `[{"op":"remove","path":"/email"},{"op":"remove","path":"/tags/0"}]`,
+			exp:   `{"name":"Bill","tags":["b"],"address":{"city":"Miami"}}`,
+		},
+		{
+			name:  "replace",
+			patch: `[{"op":"replace","path":"/name","value":"Jill"},{"op":"replace","path":"/address/city","value":"Austin"}]`,
+			exp:   `{"name":"Jill","email":"bill@example.com","tags":["a","b"],"address":{"city":"Austin"}}`,
+		},
+		{
+			name:  "move-copy-test",
+			patch: `[{"op":"test","path":"/name","value":"Bill"},{"op":"copy","from":"/name","path":"/nick"},{"op":"move","from":"/address/city","path":"/city"}]`,
+			exp:   `{"name":"Bill","nick":"Bill","email":"bill@example.com","tags":["a","b"],"address":{},"city":"Miami"}`,
+		},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			var patch web.Patch
+			if err := patch.Decode([]byte(tt.patch)); err != nil {
+				t.Fatalf("Should be able to decode the patch : %s", err)
+			}
+
+			got, err := patch.Apply([]byte(doc))
+			if err != nil {
+				t.Fatalf("Should be able to apply the patch : %s", err)
+			}
+
+			var gotDoc, expDoc any
+			json.Unmarshal(got, &gotDoc)
+			json.Unmarshal([]byte(tt.exp), &expDoc)
+
+			if diff := cmp.Diff(gotDoc, expDoc); diff != "" {
+				t.Errorf("Should get the expected document, diff:\n%s", diff)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
+
+func Test_PatchInvalid(t *testing.T) {
+	t.Parallel()
+
+	doc := `{"name":"Bill","tags":["a"]}`
+
+	table := []struct {
+		name  string
+		patch string
+	}{
+		{name: "unknown-op", patch: `[{"op":"merge","path":"/name","value":"x"}]`},
+		{name: "missing-value", patch: `[{"op":"add","path":"/name"}]`},
+		{name: "bad-pointer", patch: `[{"op":"remove","path":"name"}]`},
+		{name: "remove-missing", patch: `[{"op":"remove","path":"/email"}]`},
+		{name: "replace-missing", patch: `[{"op":"replace","path":"/address/city","value":"Miami"}]`},
+		{name: "add-missing-parent", patch: `[{"op":"add","path":"/address/city","value":"Miami"}]`},
+		{name: "index-out-of-range", patch: `[{"op":"add","path":"/tags/5","value":"x"}]`},
+		{name: "test-failed", patch: `[{"op":"test","path":"/name","value":"Jill"}]`},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			var patch web.Patch
+			if err := patch.Decode([]byte(tt.patch)); err != nil {
+				t.Fatalf("Should be able to decode the patch : %s", err)
+			}
+
+			if _, err := patch.Apply([]byte(doc)); !errors.Is(err, web.ErrInvalidPatch) {
+				t.Errorf("Should get an invalid patch error : %v", err)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
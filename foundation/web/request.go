@@ -1,9 +1,17 @@
 package web
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-json-experiment/json/jsontext"
+	"github.com/google/uuid"
 )
 
 // Param returns the web call parameters from the request.
@@ -11,6 +19,43 @@ func Param(r *http.Request, key string) string {
 	return r.PathValue(key)
 }
 
+// ParamError is returned when a path parameter can't be parsed as the
+// expected type. The error middleware responds to it with a 400.
+type ParamError struct {
+	Name  string
+	Value string
+	Type  string
+}
+
+// Error implements the error interface.
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("path parameter %q with value %q is not a valid %s", e.Name, e.Value, e.Type)
+}
+
+// ParamUUID returns the path parameter as a UUID.
+func ParamUUID(r *http.Request, key string) (uuid.UUID, error) {
+	value := r.PathValue(key)
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.UUID{}, &ParamError{Name: key, Value: value, Type: "uuid"}
+	}
+
+	return id, nil
+}
+
+// ParamInt returns the path parameter as an integer.
+func ParamInt(r *http.Request, key string) (int, error) {
+	value := r.PathValue(key)
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, &ParamError{Name: key, Value: value, Type: "integer"}
+	}
+
+	return n, nil
+}
+
 // Decoder represents data that can be decoded.
 type Decoder interface {
 	Decode(data []byte) error
@@ -20,19 +65,91 @@ type validator interface {
 	Validate() error
 }
 
+// DecodeOption represents an option for decoding the body of a request.
+type DecodeOption func(opts *decodeOptions)
+
+type decodeOptions struct {
+	allowDuplicateNames  bool
+	rejectUnknownMembers bool
+}
+
+// AllowDuplicateNames accepts JSON objects that contain the same name more
+// than once. It exists for legacy endpoints whose clients already send such
+// documents, where the data model decides which value wins.
+func AllowDuplicateNames() DecodeOption {
+	return func(opts *decodeOptions) {
+		opts.allowDuplicateNames = true
+	}
+}
+
+// RejectUnknownMembers rejects JSON objects that contain a name the data
+// model has no field for. It's meant for endpoints where a misspelled or
+// unsupported field must not be silently ignored. Names are matched
+// case-insensitively, like the data models do, and the members of maps,
+// interfaces and types that unmarshal themselves aren't checked.
+func RejectUnknownMembers() DecodeOption {
+	return func(opts *decodeOptions) {
+		opts.rejectUnknownMembers = true
+	}
+}
+
+// DuplicateNameError is returned by Decode when a JSON object in the body
+// contains the same name more than once.
+type DuplicateNameError struct {
+	Name    string
+	Pointer string
+}
+
+// Error implements the error interface.
+func (e *DuplicateNameError) Error() string {
+	return fmt.Sprintf("duplicate name %q in JSON object at %q", e.Name, e.Pointer)
+}
+
+// UnknownMemberError is returned by Decode when a JSON object in the body
+// contains a name the data model doesn't have a field for.
+type UnknownMemberError struct {
+	Name    string
+	Pointer string
+}
+
+// Error implements the error interface.
+func (e *UnknownMemberError) Error() string {
+	return fmt.Sprintf("unknown name %q in JSON object at %q", e.Name, e.Pointer)
+}
+
 // Decode reads the body of an HTTP request and decodes the body into the
-// specified data model. If the data model implements the validator interface,
-// the method will be called.
-func Decode(r *http.Request, v Decoder) error {
+// specified data model. A JSON body with duplicate object names is rejected
+// with a DuplicateNameError unless AllowDuplicateNames is provided. A JSON
+// body with names the data model doesn't know is rejected with an
+// UnknownMemberError when RejectUnknownMembers is provided. If the data model
+// implements the validator interface, the method will be called.
+func Decode(r *http.Request, v Decoder, options ...DecodeOption) error {
+	var opts decodeOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
 	data, err := io.ReadAll(r.Body)
 	if err != nil {
 		return fmt.Errorf("request: unable to read payload: %w", err)
 	}
 
+	if !opts.allowDuplicateNames {
+		if err := checkDuplicateNames(data); err != nil {
+			return fmt.Errorf("request: %w", err)
+		}
+	}
+
 	if err := v.Decode(data); err != nil {
 		return fmt.Errorf("request: decode: %w", err)
 	}
 
+	if opts.rejectUnknownMembers {
+		if err := checkUnknownMembers(data, reflect.TypeOf(v)); err != nil {
+			return fmt.Errorf("request: %w", err)
+		}
+	}
+
 	if v, ok := v.(validator); ok {
 		if err := v.Validate(); err != nil {
 			return err
@@ -41,3 +158,204 @@ func Decode(r *http.Request, v Decoder) error {
 
 	return nil
 }
+
+// checkDuplicateNames looks for an object in a JSON document that contains
+// the same name more than once. The data models decode with a decoder that
+// silently keeps the last value, which can hide a conflicting field from
+// validation. A document that isn't valid JSON is left for the data model to
+// reject.
+func checkDuplicateNames(data []byte) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return nil
+	}
+
+	dec := jsontext.NewDecoder(bytes.NewReader(trimmed), jsontext.AllowInvalidUTF8(true))
+
+	for {
+		_, err := dec.ReadToken()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			// Only report the error when the document is otherwise valid.
+			if !validJSON(trimmed) {
+				return nil
+			}
+
+			// The decoder stops in front of the duplicate name and the stack
+			// still points at the previous member of the object.
+			name, err := duplicateName(trimmed[dec.InputOffset():])
+			if err != nil {
+				return nil
+			}
+
+			pointer := string(dec.StackPointer())
+			pointer = pointer[:strings.LastIndex(pointer, "/")+1] + pointerEscaper.Replace(name)
+
+			return &DuplicateNameError{Name: name, Pointer: pointer}
+		}
+	}
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func duplicateName(data []byte) (string, error) {
+	data = bytes.TrimLeft(data, " \t\r\n,")
+
+	tok, err := jsontext.NewDecoder(bytes.NewReader(data), jsontext.AllowInvalidUTF8(true)).ReadToken()
+	if err != nil {
+		return "", err
+	}
+
+	return tok.String(), nil
+}
+
+func validJSON(data []byte) bool {
+	dec := jsontext.NewDecoder(bytes.NewReader(data), jsontext.AllowDuplicateNames(true), jsontext.AllowInvalidUTF8(true))
+	if _, err := dec.ReadValue(); err != nil {
+		return false
+	}
+
+	_, err := dec.ReadToken()
+	return errors.Is(err, io.EOF)
+}
+
+// =============================================================================
+
+// checkUnknownMembers walks the JSON document alongside the type of the data
+// model looking for an object member that doesn't match a field. It runs
+// after the data model decoded the document, so the document is known to be
+// valid JSON.
+func checkUnknownMembers(data []byte, t reflect.Type) error {
+	dec := jsontext.NewDecoder(bytes.NewReader(data), jsontext.AllowDuplicateNames(true), jsontext.AllowInvalidUTF8(true))
+
+	return unknownMember(dec, t, "")
+}
+
+// unknownMember checks the next value in the document against the type. The
+// pointer locates the value in the document.
+func unknownMember(dec *jsontext.Decoder, t reflect.Type, pointer string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case unmarshalsItself(t):
+		return dec.SkipValue()
+
+	case t.Kind() == reflect.Struct && dec.PeekKind() == '{':
+		return unknownStructMember(dec, t, pointer)
+
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && dec.PeekKind() == '[':
+		if _, err := dec.ReadToken(); err != nil {
+			return err
+		}
+
+		for i := 0; dec.PeekKind() != ']'; i++ {
+			if err := unknownMember(dec, t.Elem(), pointer+"/"+strconv.Itoa(i)); err != nil {
+				return err
+			}
+		}
+
+		_, err := dec.ReadToken()
+		return err
+	}
+
+	return dec.SkipValue()
+}
+
+func unknownStructMember(dec *jsontext.Decoder, t reflect.Type, pointer string) error {
+	if _, err := dec.ReadToken(); err != nil {
+		return err
+	}
+
+	fields := jsonFields(t)
+
+	for dec.PeekKind() != '}' {
+		tok, err := dec.ReadToken()
+		if err != nil {
+			return err
+		}
+
+		name := tok.String()
+		member := pointer + "/" + pointerEscaper.Replace(name)
+
+		ft, exists := fields[strings.ToLower(name)]
+		if !exists {
+			return &UnknownMemberError{Name: name, Pointer: member}
+		}
+
+		if err := unknownMember(dec, ft, member); err != nil {
+			return err
+		}
+	}
+
+	_, err := dec.ReadToken()
+	return err
+}
+
+// jsonFields returns the types of the fields of the struct keyed by their
+// lowercase JSON name. The fields of embedded structs without a name are
+// promoted, like the standard library does.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+
+	for i := range t.NumField() {
+		fld := t.Field(i)
+
+		tag := fld.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+
+		if fld.Anonymous && name == "" {
+			ft := fld.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct && !unmarshalsItself(ft) {
+				for k, v := range jsonFields(ft) {
+					if _, exists := fields[k]; !exists {
+						fields[k] = v
+					}
+				}
+				continue
+			}
+		}
+
+		if !fld.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = fld.Name
+		}
+
+		fields[strings.ToLower(name)] = fld.Type
+	}
+
+	return fields
+}
+
+// unmarshalsItself reports whether the type decides how its JSON is decoded,
+// so its members can't be checked.
+func unmarshalsItself(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Map:
+		return true
+	}
+
+	pt := reflect.PointerTo(t)
+	for _, method := range []string{"UnmarshalJSON", "UnmarshalJSONV2", "UnmarshalText"} {
+		if _, exists := pt.MethodByName(method); exists {
+			return true
+		}
+	}
+
+	return false
+}
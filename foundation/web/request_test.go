@@ -0,0 +1,324 @@
+package web_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type payload struct {
+	Name string `json:"name"`
+	Tags []any  `json:"tags"`
+}
+
+func (p *payload) Decode(data []byte) error {
+	return json.Unmarshal(data, p)
+}
+
+type badRequest struct {
+	msg string
+}
+
+func (e badRequest) Error() string {
+	return e.msg
+}
+
+func (e badRequest) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(map[string]string{"message": e.msg})
+	return data, "application/json", err
+}
+
+func (e badRequest) HTTPStatus() int {
+	return http.StatusBadRequest
+}
+
+func Test_DecodeDuplicateNames(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name    string
+		body    string
+		name2   string
+		pointer string
+	}{
+		{name: "top", body: `{"name":"Bill","name":"Jill"}`, name2: "name", pointer: "/name"},
+		{name: "nested", body: `{"name":"Bill","tags":[{"a":1},{"b":1,"b":2}]}`, name2: "b", pointer: "/tags/1/b"},
+		{name: "escaped", body: `{"tags":[{"a/b":1,"a\/b":2}]}`, name2: "a/b", pointer: "/tags/0/a~1b"},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+
+			var p payload
+			err := web.Decode(r, &p)
+
+			var dupErr *web.DuplicateNameError
+			if !errors.As(err, &dupErr) {
+				t.Fatalf("Should get a duplicate name error : %v", err)
+			}
+
+			if dupErr.Name != tt.name2 || dupErr.Pointer != tt.pointer {
+				t.Errorf("Should identify the duplicate, got %q at %q, exp %q at %q", dupErr.Name, dupErr.Pointer, tt.name2, tt.pointer)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
+
+func Test_DecodeAllowDuplicateNames(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"Bill","name":"Jill"}`))
+
+	var p payload
+	if err := web.Decode(r, &p, web.AllowDuplicateNames()); err != nil {
+		t.Fatalf("Should be able to decode with duplicates allowed : %s", err)
+	}
+
+	if p.Name != "Jill" {
+		t.Errorf("Should let the data model pick the value : %s", p.Name)
+	}
+}
+
+func Test_DecodeInvalid(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"Bill","name":}`))
+
+	var p payload
+	err := web.Decode(r, &p)
+
+	var dupErr *web.DuplicateNameError
+	if err == nil || errors.As(err, &dupErr) {
+		t.Fatalf("Should get the decode error from the data model : %v", err)
+	}
+}
+
+func Test_DecodeDuplicateNamesResponse(t *testing.T) {
+	t.Parallel()
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		var p payload
+		if err := web.Decode(r, &p); err != nil {
+			return badRequest{msg: err.Error()}, nil
+		}
+
+		return nil, nil
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil)
+	app.HandlerFunc(http.MethodPost, "v1", "/payload", handler)
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/v1/payload", "application/json", strings.NewReader(`{"name":"Bill","tags":[],"name":"Jill"}`))
+	if err != nil {
+		t.Fatalf("Should be able to make the request : %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Should get a bad request, got %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Should be able to read the response : %s", err)
+	}
+
+	var got struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("Should be able to decode the response : %s", err)
+	}
+
+	exp := `request: duplicate name "name" in JSON object at "/name"`
+	if got.Message != exp {
+		t.Errorf("Should cite the duplicate name, got %q, exp %q", got.Message, exp)
+	}
+}
+
+type order struct {
+	Customer string      `json:"customer"`
+	Items    []orderItem `json:"items"`
+	Meta     map[string]any
+	internal string
+}
+
+type orderItem struct {
+	SKU   string `json:"sku"`
+	Skip  string `json:"-"`
+	Price int    `json:"price,omitempty"`
+}
+
+func (o *order) Decode(data []byte) error {
+	return json.Unmarshal(data, o)
+}
+
+func Test_DecodeRejectUnknownMembers(t *testing.T) {
+	t.Parallel()
+
+	table := []struct {
+		name    string
+		body    string
+		name2   string
+		pointer string
+	}{
+		{name: "known", body: `{"CUSTOMER":"Bill","items":[{"sku":"A1","price":10}],"Meta":{"any":1}}`},
+		{name: "top", body: `{"customer":"Bill","discount":10}`, name2: "discount", pointer: "/discount"},
+		{name: "nested", body: `{"customer":"Bill","items":[{"sku":"A1"},{"sku":"B2","qty":2}]}`, name2: "qty", pointer: "/items/1/qty"},
+		{name: "ignored", body: `{"items":[{"sku":"A1","Skip":"x"}]}`, name2: "Skip", pointer: "/items/0/Skip"},
+		{name: "unexported", body: `{"internal":"x"}`, name2: "internal", pointer: "/internal"},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+
+			var o order
+			err := web.Decode(r, &o, web.RejectUnknownMembers())
+
+			if tt.name2 == "" {
+				if err != nil {
+					t.Fatalf("Should be able to decode known members : %s", err)
+				}
+				return
+			}
+
+			var unkErr *web.UnknownMemberError
+			if !errors.As(err, &unkErr) {
+				t.Fatalf("Should get an unknown member error : %v", err)
+			}
+
+			if unkErr.Name != tt.name2 || unkErr.Pointer != tt.pointer {
+				t.Errorf("Should identify the unknown member, got %q at %q, exp %q at %q", unkErr.Name, unkErr.Pointer, tt.name2, tt.pointer)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
+
+func Test_DecodeUnknownMembersResponse(t *testing.T) {
+	t.Parallel()
+
+	handler := func(options ...web.DecodeOption) web.HandlerFunc {
+		return func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+			var o order
+			if err := web.Decode(r, &o, options...); err != nil {
+				return badRequest{msg: err.Error()}, nil
+			}
+
+			return nil, nil
+		}
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil)
+	app.HandlerFunc(http.MethodPost, "v1", "/strict", handler(web.RejectUnknownMembers()))
+	app.HandlerFunc(http.MethodPost, "v1", "/lenient", handler())
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	const body = `{"customer":"Bill","discount":10}`
+
+	resp, err := http.Post(srv.URL+"/v1/lenient", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Should be able to make the request : %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Should ignore the unknown member on the lenient route, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post(srv.URL+"/v1/strict", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Should be able to make the request : %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Should reject the unknown member on the strict route, got %d", resp.StatusCode)
+	}
+
+	var got struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("Should be able to decode the response : %s", err)
+	}
+
+	exp := `request: unknown name "discount" in JSON object at "/discount"`
+	if got.Message != exp {
+		t.Errorf("Should cite the unknown member, got %q, exp %q", got.Message, exp)
+	}
+}
+
+func Test_ParamUUID(t *testing.T) {
+	t.Parallel()
+
+	id := "45b5fbd3-755f-4379-8f07-a58d4a30fa2f"
+
+	r := httptest.NewRequest(http.MethodGet, "/users/"+id, nil)
+	r.SetPathValue("user_id", id)
+
+	got, err := web.ParamUUID(r, "user_id")
+	if err != nil {
+		t.Fatalf("Should be able to parse the param : %s", err)
+	}
+
+	if got.String() != id {
+		t.Errorf("Should get the expected id : got[%s] exp[%s]", got, id)
+	}
+
+	r.SetPathValue("user_id", "not-a-uuid")
+
+	_, err = web.ParamUUID(r, "user_id")
+
+	var paramErr *web.ParamError
+	if !errors.As(err, &paramErr) {
+		t.Fatalf("Should get a param error : %v", err)
+	}
+
+	exp := `path parameter "user_id" with value "not-a-uuid" is not a valid uuid`
+	if err.Error() != exp {
+		t.Errorf("Should get the expected message : got[%s] exp[%s]", err, exp)
+	}
+}
+
+func Test_ParamInt(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest(http.MethodGet, "/pages/12", nil)
+	r.SetPathValue("page", "12")
+
+	got, err := web.ParamInt(r, "page")
+	if err != nil {
+		t.Fatalf("Should be able to parse the param : %s", err)
+	}
+
+	if got != 12 {
+		t.Errorf("Should get the expected page : got[%d] exp[%d]", got, 12)
+	}
+
+	r.SetPathValue("page", "twelve")
+
+	_, err = web.ParamInt(r, "page")
+
+	var paramErr *web.ParamError
+	if !errors.As(err, &paramErr) {
+		t.Fatalf("Should get a param error : %v", err)
+	}
+}
@@ -5,15 +5,34 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"syscall"
 
 	"github.com/ardanlabs/service/foundation/tracer"
 	"go.opentelemetry.io/otel/attribute"
 )
 
+// errClientGone is returned when the client is no longer waiting for the
+// response. It's expected during normal operation and not a server error.
+var errClientGone = errors.New("client disconnected")
+
+// writeError wraps an error from writing the response. Errors that mean the
+// client closed the connection are marked as errClientGone.
+func writeError(op string, err error) error {
+	if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+		return fmt.Errorf("%s: write: %w: %w", op, errClientGone, err)
+	}
+
+	return fmt.Errorf("%s: write: %w", op, err)
+}
+
 type httpStatus interface {
 	HTTPStatus() int
 }
 
+type httpHeader interface {
+	HTTPHeader() http.Header
+}
+
 func respondError(ctx context.Context, w http.ResponseWriter, err error) error {
 	data, ok := err.(Encoder)
 	if !ok {
@@ -26,13 +45,17 @@ func respondError(ctx context.Context, w http.ResponseWriter, err error) error {
 func respond(ctx context.Context, w http.ResponseWriter, dataModel Encoder) error {
 
 	// If the context has been canceled, it means the client is no longer
-	// waiting for a response.
+	// waiting for a response. Don't waste time encoding the data.
 	if err := ctx.Err(); err != nil {
 		if errors.Is(err, context.Canceled) {
-			return errors.New("client disconnected, do not send response")
+			return fmt.Errorf("respond: do not send response: %w", errClientGone)
 		}
 	}
 
+	if v, ok := dataModel.(streamer); ok {
+		return v.stream(ctx, w)
+	}
+
 	var statusCode = http.StatusOK
 
 	switch v := dataModel.(type) {
@@ -51,7 +74,15 @@ func respond(ctx context.Context, w http.ResponseWriter, dataModel Encoder) erro
 	_, span := tracer.AddSpan(ctx, "foundation.response", attribute.Int("status", statusCode))
 	defer span.End()
 
-	if statusCode == http.StatusNoContent {
+	if v, ok := dataModel.(httpHeader); ok {
+		for key, values := range v.HTTPHeader() {
+			for _, value := range values {
+				w.Header().Add(key, value)
+			}
+		}
+	}
+
+	if statusCode == http.StatusNoContent || statusCode == http.StatusNotModified {
 		w.WriteHeader(statusCode)
 		return nil
 	}
@@ -65,7 +96,7 @@ func respond(ctx context.Context, w http.ResponseWriter, dataModel Encoder) erro
 	w.WriteHeader(statusCode)
 
 	if _, err := w.Write(data); err != nil {
-		return fmt.Errorf("respond: write: %w", err)
+		return writeError("respond", err)
 	}
 
 	return nil
@@ -0,0 +1,142 @@
+package web_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type encoder struct {
+	encoded *bool
+}
+
+func (e encoder) Encode() ([]byte, string, error) {
+	*e.encoded = true
+	data, err := json.Marshal(struct{ Status string }{Status: "OK"})
+	return data, "application/json", err
+}
+
+type recorder struct {
+	*httptest.ResponseRecorder
+	written bool
+}
+
+func (r *recorder) WriteHeader(statusCode int) {
+	r.written = true
+	r.ResponseRecorder.WriteHeader(statusCode)
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	r.written = true
+	return r.ResponseRecorder.Write(data)
+}
+
+func Test_RespondCanceled(t *testing.T) {
+	t.Parallel()
+
+	var errorLogs []any
+	log := func(ctx context.Context, msg string, args ...any) {
+		errorLogs = append(errorLogs, args...)
+	}
+
+	var debugLogs []any
+	debug := func(ctx context.Context, msg string, args ...any) {
+		debugLogs = append(debugLogs, args...)
+	}
+
+	var encoded bool
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return encoder{encoded: &encoded}, nil
+	}
+
+	app := web.NewApp(log, nil)
+	app.SetDebugLogger(debug)
+	app.HandlerFunc(http.MethodGet, "", "/test", handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := httptest.NewRequest(http.MethodGet, "/test", nil).WithContext(ctx)
+	w := recorder{ResponseRecorder: httptest.NewRecorder()}
+
+	app.ServeHTTP(&w, r)
+
+	if encoded {
+		t.Errorf("Should not encode the response when the client is gone")
+	}
+
+	if w.written {
+		t.Errorf("Should not write the response when the client is gone")
+	}
+
+	if len(errorLogs) != 0 {
+		t.Errorf("Should not log an error when the client is gone : %v", errorLogs)
+	}
+
+	if len(debugLogs) == 0 {
+		t.Errorf("Should log a debug line when the client is gone")
+	}
+}
+
+func Test_RespondWarnings(t *testing.T) {
+	t.Parallel()
+
+	log := func(ctx context.Context, msg string, args ...any) {}
+
+	var encoded bool
+
+	app := web.NewApp(log, nil)
+
+	app.HandlerFunc(http.MethodGet, "", "/ok", func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		web.AddWarning(ctx, `field "dept" is deprecated, use "department"`)
+		web.AddWarning(ctx, `field "dept" is deprecated, use "department"`)
+		web.AddWarning(ctx, "page size was capped\nat 100")
+		return encoder{encoded: &encoded}, nil
+	})
+
+	app.HandlerFunc(http.MethodGet, "", "/nocontent", func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		web.AddWarning(ctx, "sort field is deprecated")
+		return nil, nil
+	})
+
+	table := []struct {
+		url      string
+		status   int
+		warnings []string
+	}{
+		{
+			url:      "/ok",
+			status:   http.StatusOK,
+			warnings: []string{`299 - "field \"dept\" is deprecated, use \"department\""`, `299 - "page size was capped at 100"`},
+		},
+		{
+			url:      "/nocontent",
+			status:   http.StatusNoContent,
+			warnings: []string{`299 - "sort field is deprecated"`},
+		},
+	}
+
+	for _, tt := range table {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+		if w.Code != tt.status {
+			t.Errorf("%s: Should not change the status code, got %d, exp %d", tt.url, w.Code, tt.status)
+		}
+
+		got := w.Header().Values("Warning")
+		if len(got) != len(tt.warnings) {
+			t.Fatalf("%s: Should get the warnings once each, got %q", tt.url, got)
+		}
+
+		for i := range got {
+			if got[i] != tt.warnings[i] {
+				t.Errorf("%s: Should get the warning, got %s, exp %s", tt.url, got[i], tt.warnings[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// streamer is implemented by responses that write their data over time
+// instead of being encoded once.
+type streamer interface {
+	stream(ctx context.Context, w http.ResponseWriter) error
+}
+
+type eventNamer interface {
+	EventName() string
+}
+
+// SSE represents a response that sends server-sent events. Each value
+// received from Events is encoded and sent as the data of an event, using
+// the EventName method for the event name if the value provides one. The
+// stream ends when Events is closed or the client disconnects. A comment is
+// sent on the KeepAlive interval when no events are sent.
+type SSE[T Encoder] struct {
+	Events    <-chan T
+	KeepAlive time.Duration
+}
+
+// Encode implements the encoder interface. An SSE response can only be
+// streamed, so this is only called when it's used incorrectly.
+func (sse SSE[T]) Encode() ([]byte, string, error) {
+	return nil, "", errors.New("sse: response can only be streamed")
+}
+
+func (sse SSE[T]) stream(ctx context.Context, w http.ResponseWriter) error {
+	rc := http.NewResponseController(w)
+
+	// The stream is expected to outlive the server's write timeout.
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if err := rc.Flush(); err != nil {
+		return fmt.Errorf("sse: flush: %w", err)
+	}
+
+	keepAlive := sse.KeepAlive
+	if keepAlive <= 0 {
+		keepAlive = 15 * time.Second
+	}
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	for {
+		var msg []byte
+
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case evt, ok := <-sse.Events:
+			if !ok {
+				return nil
+			}
+
+			data, _, err := evt.Encode()
+			if err != nil {
+				return fmt.Errorf("sse: encode: %w", err)
+			}
+
+			msg = formatEvent(evt, data)
+			ticker.Reset(keepAlive)
+
+		case <-ticker.C:
+			msg = []byte(": keep-alive\n\n")
+		}
+
+		if _, err := w.Write(msg); err != nil {
+			return writeError("sse", err)
+		}
+
+		if err := rc.Flush(); err != nil {
+			return fmt.Errorf("sse: flush: %w", err)
+		}
+	}
+}
+
+func formatEvent(evt any, data []byte) []byte {
+	var b bytes.Buffer
+
+	if v, ok := evt.(eventNamer); ok {
+		b.WriteString("event: ")
+		b.WriteString(v.EventName())
+		b.WriteString("\n")
+	}
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		b.WriteString("data: ")
+		b.Write(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	return b.Bytes()
+}
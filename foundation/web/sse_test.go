@@ -0,0 +1,65 @@
+package web_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type event struct {
+	data string
+}
+
+func (e event) Encode() ([]byte, string, error) {
+	return []byte(e.data), "application/json", nil
+}
+
+func (e event) EventName() string {
+	return "test.event"
+}
+
+func Test_SSE(t *testing.T) {
+	t.Parallel()
+
+	events := make(chan event, 2)
+	events <- event{data: `{"n":1}`}
+	events <- event{data: "line1\nline2"}
+	close(events)
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil)
+	app.HandlerFunc(http.MethodGet, "v1", "/events", func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return web.SSE[event]{Events: events, KeepAlive: time.Minute}, nil
+	})
+
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/v1/events")
+	if err != nil {
+		t.Fatalf("Should be able to make the request : %s", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Should get the event stream content type : %s", ct)
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	exp := "event: test.event\ndata: {\"n\":1}\n\nevent: test.event\ndata: line1\ndata: line2\n"
+	if got := strings.Join(lines, "\n"); got != exp {
+		t.Errorf("Should get the expected stream")
+		t.Errorf("GOT: %q", got)
+		t.Errorf("EXP: %q", exp)
+	}
+}
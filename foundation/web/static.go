@@ -0,0 +1,153 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Files serves the files in a file system. Byte-range requests are supported
+// for media and conditional requests are answered with a 304 using the ETag
+// and Last-Modified headers. CacheControl is sent with every file and
+// defaults to no-cache, which lets clients keep a copy they must revalidate.
+//
+// The path of the request is the name of the file, so a handler bound to a
+// prefix must be wrapped with http.StripPrefix.
+type Files struct {
+	FS           fs.FS
+	CacheControl string
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (f Files) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, ok := fileName(r)
+	if !ok {
+		http.Error(w, "400 Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	cacheControl := f.CacheControl
+	if cacheControl == "" {
+		cacheControl = "no-cache"
+	}
+
+	respondFile(w, r, serveFile(w, r, f.FS, name, cacheControl))
+}
+
+// =============================================================================
+
+// Static serves the files of a single-page app. Requests for paths that
+// aren't a file are served the index file so the client can route them,
+// unless the path has an extension, since those are requests for assets
+// that don't exist.
+//
+// The index file must be revalidated on every request so a new release is
+// picked up right away. Other files are cached for MaxAge, which assumes the
+// build gives them a new name whenever their content changes.
+type Static struct {
+	FS     fs.FS
+	Index  string
+	MaxAge time.Duration
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (s Static) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, ok := fileName(r)
+	if !ok {
+		http.Error(w, "400 Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	index := s.Index
+	if index == "" {
+		index = "index.html"
+	}
+
+	if name == "." {
+		name = index
+	}
+
+	cacheControl := "no-cache"
+	if name != index && s.MaxAge > 0 {
+		cacheControl = fmt.Sprintf("public, max-age=%d", int(s.MaxAge.Seconds()))
+	}
+
+	err := serveFile(w, r, s.FS, name, cacheControl)
+	if errors.Is(err, fs.ErrNotExist) && path.Ext(name) == "" {
+		err = serveFile(w, r, s.FS, index, "no-cache")
+	}
+
+	respondFile(w, r, err)
+}
+
+// =============================================================================
+
+// fileName returns the name of the file for the request path. It reports
+// false when the path tries to leave the root of the file system.
+func fileName(r *http.Request) (string, bool) {
+	if slices.Contains(strings.Split(strings.ReplaceAll(r.URL.Path, `\`, "/"), "/"), "..") {
+		return "", false
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		name = "."
+	}
+
+	return name, fs.ValidPath(name)
+}
+
+// serveFile writes the file with the content type for its extension. A
+// directory is reported as not existing. Range and conditional requests are
+// handled by http.ServeContent.
+func serveFile(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string, cacheControl string) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat: %w", err)
+	}
+
+	if info.IsDir() {
+		return fs.ErrNotExist
+	}
+
+	rs, ok := f.(io.ReadSeeker)
+	if !ok {
+		return fmt.Errorf("file %q does not support seeking", name)
+	}
+
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), rs)
+
+	return nil
+}
+
+// respondFile responds with the error from serving a file, if any.
+func respondFile(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case err == nil:
+		return
+
+	case errors.Is(err, fs.ErrNotExist):
+		http.NotFound(w, r)
+
+	case errors.Is(err, fs.ErrPermission):
+		http.Error(w, "403 Forbidden", http.StatusForbidden)
+
+	default:
+		http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
+	}
+}
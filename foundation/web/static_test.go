@@ -0,0 +1,158 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type users struct{}
+
+func (users) Encode() ([]byte, string, error) {
+	return []byte(`[]`), "application/json", nil
+}
+
+func Test_Static(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"index.html":    {Data: []byte("<html>app</html>")},
+		"assets/app.js": {Data: []byte("console.log('app')")},
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil)
+	app.SetFallback(web.Static{FS: fsys, MaxAge: time.Hour}, "/v1/")
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return users{}, nil
+	}
+	app.HandlerFunc(http.MethodGet, "v1", "/users", handler)
+
+	table := []struct {
+		name        string
+		method      string
+		path        string
+		status      int
+		body        string
+		contentType string
+		cache       string
+	}{
+		{name: "api", method: http.MethodGet, path: "/v1/users", status: http.StatusOK, body: "[]", contentType: "application/json"},
+		{name: "api-unknown", method: http.MethodGet, path: "/v1/unknown", status: http.StatusNotFound},
+		{name: "root", method: http.MethodGet, path: "/", status: http.StatusOK, body: "<html>app</html>", contentType: "text/html; charset=utf-8", cache: "no-cache"},
+		{name: "app-route", method: http.MethodGet, path: "/orders/42", status: http.StatusOK, body: "<html>app</html>", contentType: "text/html; charset=utf-8", cache: "no-cache"},
+		{name: "asset", method: http.MethodGet, path: "/assets/app.js", status: http.StatusOK, body: "console.log('app')", contentType: "text/javascript; charset=utf-8", cache: "public, max-age=3600"},
+		{name: "asset-missing", method: http.MethodGet, path: "/assets/missing.js", status: http.StatusNotFound},
+		{name: "post", method: http.MethodPost, path: "/orders/42", status: http.StatusNotFound},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("Should get the expected status : got[%d] exp[%d]", w.Code, tt.status)
+			}
+
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("Should get the expected body : got[%s] exp[%s]", w.Body.String(), tt.body)
+			}
+
+			if got := w.Header().Get("Content-Type"); tt.contentType != "" && got != tt.contentType {
+				t.Errorf("Should get the expected content type : got[%s] exp[%s]", got, tt.contentType)
+			}
+
+			if got := w.Header().Get("Cache-Control"); got != tt.cache {
+				t.Errorf("Should get the expected cache control : got[%s] exp[%s]", got, tt.cache)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
+
+func Test_Files(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	public := filepath.Join(root, "public")
+
+	if err := os.Mkdir(public, 0o755); err != nil {
+		t.Fatalf("Should be able to create the directory : %s", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatalf("Should be able to write the secret : %s", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(public, "video.mp4"), []byte("0123456789"), 0o644); err != nil {
+		t.Fatalf("Should be able to write the video : %s", err)
+	}
+
+	files := web.Files{FS: os.DirFS(public), CacheControl: "public, max-age=60"}
+
+	w := httptest.NewRecorder()
+	files.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/video.mp4", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should get the file : got[%d] exp[%d]", w.Code, http.StatusOK)
+	}
+
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=60" {
+		t.Errorf("Should get the cache control : got[%s]", got)
+	}
+
+	etag := w.Header().Get("ETag")
+	lastModified := w.Header().Get("Last-Modified")
+
+	if etag == "" || lastModified == "" {
+		t.Fatalf("Should get the validators : etag[%s] last-modified[%s]", etag, lastModified)
+	}
+
+	table := []struct {
+		name   string
+		path   string
+		header map[string]string
+		status int
+		body   string
+	}{
+		{name: "range", path: "/video.mp4", header: map[string]string{"Range": "bytes=2-5"}, status: http.StatusPartialContent, body: "2345"},
+		{name: "if-none-match", path: "/video.mp4", header: map[string]string{"If-None-Match": etag}, status: http.StatusNotModified},
+		{name: "if-modified-since", path: "/video.mp4", header: map[string]string{"If-Modified-Since": lastModified}, status: http.StatusNotModified},
+		{name: "stale", path: "/video.mp4", header: map[string]string{"If-None-Match": `"stale"`}, status: http.StatusOK, body: "0123456789"},
+		{name: "traversal", path: "/../secret.txt", status: http.StatusBadRequest},
+		{name: "traversal-encoded", path: "/%2e%2e/secret.txt", status: http.StatusBadRequest},
+		{name: "missing", path: "/missing.mp4", status: http.StatusNotFound},
+	}
+
+	for _, tt := range table {
+		f := func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+
+			w := httptest.NewRecorder()
+			files.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("Should get the expected status : got[%d] exp[%d]", w.Code, tt.status)
+			}
+
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("Should get the expected body : got[%s] exp[%s]", w.Body.String(), tt.body)
+			}
+		}
+
+		t.Run(tt.name, f)
+	}
+}
@@ -0,0 +1,26 @@
+package web
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+)
+
+// MutualTLS returns a TLS configuration for a server that requires every
+// client to present a certificate signed by one of the certificate
+// authorities in the PEM encoded caPEM. Connections without a valid
+// certificate fail during the handshake.
+func MutualTLS(caPEM []byte) (*tls.Config, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("mutual tls: no certificates found in the client CA")
+	}
+
+	cfg := tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  pool,
+	}
+
+	return &cfg, nil
+}
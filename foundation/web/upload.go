@@ -0,0 +1,160 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+)
+
+// Set of error variables for handling file uploads.
+var (
+	ErrFileTooLarge    = errors.New("file too large")
+	ErrUnsupportedType = errors.New("unsupported file type")
+)
+
+// sniffLen is the number of bytes needed to detect the content type.
+const sniffLen = 512
+
+// FileStorer represents behavior for storing an uploaded file. It can be
+// implemented for the local disk or a service like S3. If reading from r
+// returns an error, the storer must discard what was written. Delete removes
+// a stored file by the location returned from Store.
+type FileStorer interface {
+	Store(ctx context.Context, filename string, contentType string, r io.Reader) (location string, err error)
+	Delete(ctx context.Context, location string) error
+}
+
+// UploadConfig represents the settings for handling file uploads.
+type UploadConfig struct {
+	Storer       FileStorer
+	MaxSize      int64
+	AllowedTypes []string
+}
+
+// FileInfo represents the metadata for a file that was stored.
+type FileInfo struct {
+	Field       string
+	Filename    string
+	ContentType string
+	Size        int64
+	Location    string
+}
+
+// DecodeFiles reads a multipart/form-data request and streams each file to
+// the storer without holding the entire file in memory. The content type is
+// detected from the file data, not trusted from the client. If any part
+// fails, the files already stored for the request are deleted.
+func DecodeFiles(ctx context.Context, r *http.Request, cfg UploadConfig) ([]FileInfo, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("request: multipart: %w", err)
+	}
+
+	var files []FileInfo
+
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, discardFiles(ctx, cfg.Storer, files, fmt.Errorf("request: next part: %w", err))
+		}
+
+		if part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		fi, err := storeFile(ctx, part.FormName(), part.FileName(), part, cfg)
+		part.Close()
+		if err != nil {
+			return nil, discardFiles(ctx, cfg.Storer, files, err)
+		}
+
+		files = append(files, fi)
+	}
+
+	return files, nil
+}
+
+// discardFiles deletes the files that were stored before the error so a
+// failed upload doesn't leave files behind. The files are deleted even when
+// the request was canceled.
+func discardFiles(ctx context.Context, storer FileStorer, files []FileInfo, err error) error {
+	ctx = context.WithoutCancel(ctx)
+
+	for _, fi := range files {
+		if delErr := storer.Delete(ctx, fi.Location); delErr != nil {
+			err = errors.Join(err, fmt.Errorf("request: delete: %s: %w", fi.Location, delErr))
+		}
+	}
+
+	return err
+}
+
+func storeFile(ctx context.Context, field string, filename string, r io.Reader, cfg UploadConfig) (FileInfo, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return FileInfo{}, fmt.Errorf("request: read file: %w", err)
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	if len(cfg.AllowedTypes) > 0 && !slices.Contains(cfg.AllowedTypes, contentType) {
+		return FileInfo{}, fmt.Errorf("request: %s: %w", contentType, ErrUnsupportedType)
+	}
+
+	lr := limitReader{
+		r:   io.MultiReader(bytes.NewReader(head), r),
+		max: cfg.MaxSize,
+	}
+
+	location, err := cfg.Storer.Store(ctx, filename, contentType, &lr)
+	if err != nil {
+		if lr.err != nil {
+			return FileInfo{}, fmt.Errorf("request: %s: %w", filename, lr.err)
+		}
+		return FileInfo{}, fmt.Errorf("request: store: %w", err)
+	}
+
+	fi := FileInfo{
+		Field:       field,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        lr.n,
+		Location:    location,
+	}
+
+	return fi, nil
+}
+
+// limitReader reads from r until more than max bytes have been read, then
+// fails with ErrFileTooLarge. A max of zero means there is no limit.
+type limitReader struct {
+	r   io.Reader
+	max int64
+	n   int64
+	err error
+}
+
+func (lr *limitReader) Read(p []byte) (int, error) {
+	if lr.err != nil {
+		return 0, lr.err
+	}
+
+	n, err := lr.r.Read(p)
+	lr.n += int64(n)
+
+	if lr.max > 0 && lr.n > lr.max {
+		lr.err = ErrFileTooLarge
+		return 0, lr.err
+	}
+
+	return n, err
+}
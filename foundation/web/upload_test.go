@@ -0,0 +1,168 @@
+package web_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/filestore"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+var png = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 100)...)
+
+func Test_DecodeFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	store, err := filestore.NewLocal(dir)
+	if err != nil {
+		t.Fatalf("Should be able to construct the store : %s", err)
+	}
+
+	cfg := web.UploadConfig{
+		Storer:       store,
+		MaxSize:      1024,
+		AllowedTypes: []string{"image/png", "image/jpeg"},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		r := newUploadRequest(t, "avatar", "../../me.png", png)
+
+		files, err := web.DecodeFiles(context.Background(), r, cfg)
+		if err != nil {
+			t.Fatalf("Should be able to decode the files : %s", err)
+		}
+
+		if len(files) != 1 {
+			t.Fatalf("Should get one file : %d", len(files))
+		}
+
+		fi := files[0]
+
+		if fi.Field != "avatar" || fi.ContentType != "image/png" || fi.Size != int64(len(png)) {
+			t.Errorf("Should get the file metadata : %+v", fi)
+		}
+
+		if filepath.Dir(fi.Location) != dir {
+			t.Errorf("Should store the file in the directory : %s", fi.Location)
+		}
+
+		data, err := os.ReadFile(fi.Location)
+		if err != nil {
+			t.Fatalf("Should be able to read the stored file : %s", err)
+		}
+
+		if !bytes.Equal(data, png) {
+			t.Errorf("Should store the full file")
+		}
+	})
+
+	t.Run("too-large", func(t *testing.T) {
+		dir := t.TempDir()
+
+		store, err := filestore.NewLocal(dir)
+		if err != nil {
+			t.Fatalf("Should be able to construct the store : %s", err)
+		}
+
+		cfg := cfg
+		cfg.Storer = store
+
+		data := append(png, bytes.Repeat([]byte{0}, 2048)...)
+		r := newUploadRequest(t, "avatar", "me.png", data)
+
+		if _, err := web.DecodeFiles(context.Background(), r, cfg); !errors.Is(err, web.ErrFileTooLarge) {
+			t.Fatalf("Should get a file too large error : %v", err)
+		}
+
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 0 {
+			t.Errorf("Should not keep a partial file : %d", len(entries))
+		}
+	})
+
+	t.Run("later-part", func(t *testing.T) {
+		dir := t.TempDir()
+
+		store, err := filestore.NewLocal(dir)
+		if err != nil {
+			t.Fatalf("Should be able to construct the store : %s", err)
+		}
+
+		cfg := cfg
+		cfg.Storer = store
+
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+
+		for _, data := range [][]byte{png, png, append(png, bytes.Repeat([]byte{0}, 2048)...)} {
+			fw, err := mw.CreateFormFile("avatar", "me.png")
+			if err != nil {
+				t.Fatalf("Should be able to create the file : %s", err)
+			}
+
+			if _, err := fw.Write(data); err != nil {
+				t.Fatalf("Should be able to write the file : %s", err)
+			}
+		}
+
+		if err := mw.Close(); err != nil {
+			t.Fatalf("Should be able to close the writer : %s", err)
+		}
+
+		r := httptest.NewRequest(http.MethodPost, "/upload", &body)
+		r.Header.Set("Content-Type", mw.FormDataContentType())
+
+		if _, err := web.DecodeFiles(context.Background(), r, cfg); !errors.Is(err, web.ErrFileTooLarge) {
+			t.Fatalf("Should get a file too large error : %v", err)
+		}
+
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 0 {
+			t.Errorf("Should delete the files stored before the failure : %d", len(entries))
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		r := newUploadRequest(t, "avatar", "me.png", []byte("#!/bin/sh\necho hello\n"))
+
+		if _, err := web.DecodeFiles(context.Background(), r, cfg); !errors.Is(err, web.ErrUnsupportedType) {
+			t.Fatalf("Should get an unsupported type error : %v", err)
+		}
+	})
+}
+
+func newUploadRequest(t *testing.T, field string, filename string, data []byte) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	if err := mw.WriteField("name", "Bill"); err != nil {
+		t.Fatalf("Should be able to write the field : %s", err)
+	}
+
+	fw, err := mw.CreateFormFile(field, filename)
+	if err != nil {
+		t.Fatalf("Should be able to create the file : %s", err)
+	}
+
+	if _, err := fw.Write(data); err != nil {
+		t.Fatalf("Should be able to write the file : %s", err)
+	}
+
+	if err := mw.Close(); err != nil {
+		t.Fatalf("Should be able to close the writer : %s", err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	return r
+}
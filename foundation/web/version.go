@@ -0,0 +1,95 @@
+package web
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// SetVersionNegotiation lets clients select the version of a route through
+// the Accept header instead of the path. The versions are the groups the
+// routes are registered under, like v1 and v2. A request whose path doesn't
+// start with a version is routed to the version named by the version
+// parameter of the Accept header, like application/json; version=v2, or to
+// the default version when the header doesn't name one. The version in the
+// path takes precedence over the header.
+func (a *App) SetVersionNegotiation(defaultVersion string) {
+	a.defaultVersion = defaultVersion
+}
+
+// addVersion records a group that has routes so requests can be routed to
+// it by version.
+func (a *App) addVersion(group string) {
+	if group == "" || slices.Contains(a.versions, group) {
+		return
+	}
+
+	a.versions = append(a.versions, group)
+}
+
+// negotiateVersion routes the request to the version selected by the Accept
+// header when its path doesn't name one. The request is left as is when the
+// selected version has no route for the path, so it can still be handled by
+// an unversioned route or the fallback. A version that doesn't exist is
+// rejected with a 406.
+func (a *App) negotiateVersion(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	first, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if slices.Contains(a.versions, first) {
+		return r, true
+	}
+
+	version, requested := acceptVersion(r.Header.Values("Accept"))
+	if !requested {
+		version = a.defaultVersion
+	}
+
+	switch {
+	case slices.Contains(a.versions, version):
+	case "v"+version != "v" && slices.Contains(a.versions, "v"+version):
+		version = "v" + version
+	case requested:
+		http.Error(w, "406 Not Acceptable: unknown version "+version, http.StatusNotAcceptable)
+		return nil, false
+	default:
+		return r, true
+	}
+
+	// The path is changed in every form the request carries it so handlers
+	// and logs see the same path the route matched.
+	vr := r.Clone(r.Context())
+	vr.URL.Path = "/" + version + r.URL.Path
+	if r.URL.RawPath != "" {
+		vr.URL.RawPath = "/" + version + r.URL.RawPath
+	}
+	if r.RequestURI != "" {
+		vr.RequestURI = vr.URL.RequestURI()
+	}
+
+	if _, pattern := a.mux.Handler(vr); pattern == "" && len(a.allowedMethods(vr)) == 0 {
+		return r, true
+	}
+
+	w.Header().Add("Vary", "Accept")
+
+	return vr, true
+}
+
+// acceptVersion returns the version named by the version parameter of the
+// Accept header and whether there was one.
+func acceptVersion(accept []string) (string, bool) {
+	for _, header := range accept {
+		for _, part := range strings.Split(header, ",") {
+			_, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+
+			if version, ok := params["version"]; ok {
+				return version, true
+			}
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,86 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_Version(t *testing.T) {
+	t.Parallel()
+
+	var shared atomic.Int64
+
+	mw := func(next web.HandlerFunc) web.HandlerFunc {
+		return func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+			shared.Add(1)
+			return next(ctx, r)
+		}
+	}
+
+	handler := func(version string) web.HandlerFunc {
+		return func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+			web.SetHeader(ctx, "X-Handler", version+" "+r.PathValue("user_id"))
+			web.SetHeader(ctx, "X-Request-URI", r.RequestURI)
+			return nil, nil
+		}
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil, mw)
+	app.SetVersionNegotiation("v1")
+
+	app.HandlerFunc(http.MethodGet, "v1", "/users/{user_id}", handler("v1"))
+	app.HandlerFunc(http.MethodGet, "v2", "/users/{user_id}", handler("v2"))
+	app.HandlerFunc(http.MethodGet, "v2", "/widgets", handler("v2"))
+
+	table := []struct {
+		name   string
+		path   string
+		accept string
+		status int
+		exp    string
+		uri    string
+	}{
+		{name: "path-v1", path: "/v1/users/123", status: http.StatusNoContent, exp: "v1 123"},
+		{name: "path-v2", path: "/v2/users/123", status: http.StatusNoContent, exp: "v2 123"},
+		{name: "path-wins", path: "/v1/users/123", accept: "application/json; version=v2", status: http.StatusNoContent, exp: "v1 123"},
+		{name: "default", path: "/users/123", accept: "application/json", status: http.StatusNoContent, exp: "v1 123"},
+		{name: "accept-v2", path: "/users/123", accept: "application/json; version=v2", status: http.StatusNoContent, exp: "v2 123"},
+		{name: "accept-number", path: "/users/123", accept: "text/plain, application/json; version=2", status: http.StatusNoContent, exp: "v2 123"},
+		{name: "accept-escaped", path: "/users/a%2Fb?x=1", accept: "application/json; version=v2", status: http.StatusNoContent, exp: "v2 a/b", uri: "/v2/users/a%2Fb?x=1"},
+		{name: "accept-unknown", path: "/users/123", accept: "application/json; version=v9", status: http.StatusNotAcceptable},
+		{name: "missing-in-version", path: "/widgets", accept: "application/json; version=v1", status: http.StatusNotFound},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("Should get status %d : got %d : %s", tt.status, w.Code, w.Body)
+			}
+
+			if got := w.Header().Get("X-Handler"); got != tt.exp {
+				t.Errorf("Should dispatch to the handler for the version, got %q, exp %q", got, tt.exp)
+			}
+
+			if got := w.Header().Get("X-Request-URI"); tt.uri != "" && got != tt.uri {
+				t.Errorf("Should rewrite the request uri, got %q, exp %q", got, tt.uri)
+			}
+		})
+	}
+
+	if got := shared.Load(); got != 7 {
+		t.Errorf("Should run the shared middleware for every version, got %d, exp 7", got)
+	}
+}
@@ -3,8 +3,12 @@ package web
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/ardanlabs/service/foundation/tracer"
 	"github.com/google/uuid"
@@ -30,12 +34,21 @@ type Logger func(ctx context.Context, msg string, args ...any)
 // object for each of our http handlers. Feel free to add any configuration
 // data/logic on this App struct.
 type App struct {
-	log     Logger
-	tracer  trace.Tracer
-	mux     *http.ServeMux
-	otmux   http.Handler
-	mw      []MidFunc
-	origins []string
+	log        Logger
+	debug      Logger
+	tracer     trace.Tracer
+	mux        *http.ServeMux
+	otmux      http.Handler
+	mw         []MidFunc
+	origins    []string
+	maxHeaders int
+	methods    []string
+	routes     []Route
+	fallback   http.Handler
+	excluded   []string
+
+	versions       []string
+	defaultVersion string
 }
 
 // NewApp creates an App value that handle a set of routes for the application.
@@ -64,9 +77,186 @@ func NewApp(log Logger, tracer trace.Tracer, mw ...MidFunc) *App {
 // tracing. The opentelemetry mux then calls the application mux to handle
 // application traffic. This was set up on line 44 in the NewApp function.
 func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r = r.WithContext(setStartTime(r.Context(), time.Now()))
+
+	if a.maxHeaders > 0 && headerCount(r.Header) > a.maxHeaders {
+		http.Error(w, "431 Request Header Fields Too Large", http.StatusRequestHeaderFieldsTooLarge)
+		return
+	}
+
+	if a.defaultVersion != "" {
+		var ok bool
+		if r, ok = a.negotiateVersion(w, r); !ok {
+			return
+		}
+	}
+
+	if r.Method == http.MethodOptions && !a.isPreflight(r) {
+		a.options(w, r)
+		return
+	}
+
+	if _, pattern := a.mux.Handler(r); pattern == "" {
+		a.unrouted(w, r)
+		return
+	}
+
 	a.otmux.ServeHTTP(w, r)
 }
 
+// unrouted responds to a request that has no handler. When the path has
+// handlers for other methods, the response is a 405 with those methods in
+// the Allow header, otherwise it's a 404.
+func (a *App) unrouted(w http.ResponseWriter, r *http.Request) {
+	allowed := a.allowedMethods(r)
+	if len(allowed) == 0 {
+		if a.isFallback(r) {
+			a.fallback.ServeHTTP(w, r)
+			return
+		}
+
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+}
+
+// SetFallback sets the handler for GET and HEAD requests that don't match
+// any route, like the pages of a single-page app that are routed by the
+// client. Paths starting with one of the excluded prefixes, like the prefix
+// of the API routes, still respond with a 404.
+func (a *App) SetFallback(handler http.Handler, excluded ...string) {
+	a.fallback = handler
+	a.excluded = excluded
+}
+
+// isFallback reports whether the unrouted request should be handled by the
+// fallback handler.
+func (a *App) isFallback(r *http.Request) bool {
+	if a.fallback == nil {
+		return false
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	for _, prefix := range a.excluded {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isPreflight reports whether the request is a CORS preflight request that
+// should be handled by the CORS handler.
+func (a *App) isPreflight(r *http.Request) bool {
+	return a.origins != nil && r.Header.Get("Origin") != "" && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// options responds to an OPTIONS request with the methods registered for the
+// path in the Allow header. A path with an OPTIONS handler of its own is
+// handled by it and an unknown path is not found.
+func (a *App) options(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := a.mux.Handler(r); pattern != "" && pattern != http.MethodOptions+" /" {
+		a.otmux.ServeHTTP(w, r)
+		return
+	}
+
+	allowed := a.allowedMethods(r)
+	if len(allowed) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// allowedMethods returns the methods that have a handler for the path of the
+// request, including HEAD when there is a GET handler since the mux routes
+// HEAD requests to it.
+func (a *App) allowedMethods(r *http.Request) []string {
+	var allowed []string
+
+	for _, method := range a.methods {
+		probe := *r
+		probe.Method = method
+
+		if _, pattern := a.mux.Handler(&probe); pattern != "" {
+			allowed = append(allowed, method)
+
+			if method == http.MethodGet && !slices.Contains(a.methods, http.MethodHead) {
+				allowed = append(allowed, http.MethodHead)
+			}
+		}
+	}
+
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	allowed = append(allowed, http.MethodOptions)
+	slices.Sort(allowed)
+
+	return slices.Compact(allowed)
+}
+
+// addMethod records a method that has a handler so OPTIONS requests can
+// report it.
+func (a *App) addMethod(method string) {
+	if method == http.MethodOptions || slices.Contains(a.methods, method) {
+		return
+	}
+
+	a.methods = append(a.methods, method)
+}
+
+// Route describes a route bound to the app.
+type Route struct {
+	Method string
+	Path   string
+}
+
+// addRoute records a route that has a handler so tooling can describe the
+// routes the app binds.
+func (a *App) addRoute(method string, path string) {
+	a.routes = append(a.routes, Route{Method: method, Path: path})
+}
+
+// Routes returns the routes bound to the app in the order they were added.
+func (a *App) Routes() []Route {
+	return slices.Clone(a.routes)
+}
+
+// SetMaxHeaderCount sets the maximum number of header fields a request can
+// have. Requests with more are rejected with a 431 before any handler runs.
+// The size of the headers is limited by the MaxHeaderBytes setting of the
+// http.Server, which also responds with a 431.
+func (a *App) SetMaxHeaderCount(max int) {
+	a.maxHeaders = max
+}
+
+func headerCount(h http.Header) int {
+	var n int
+	for _, values := range h {
+		n += len(values)
+	}
+
+	return n
+}
+
+// SetDebugLogger sets the function used to log information that is only
+// useful when debugging, like clients that disconnect before the response is
+// sent. These are not logged if a debug logger is not set.
+func (a *App) SetDebugLogger(log Logger) {
+	a.debug = log
+}
+
 // EnableCORS enables CORS preflight requests to work in the middleware. It
 // prevents the MethodNotAllowedHandler from being called. This must be enabled
 // for the CORS middleware to work.
@@ -93,7 +283,7 @@ func (a *App) corsHandler(webHandler HandlerFunc) HandlerFunc {
 			r.Header.Set("Access-Control-Allow-Origin", origin)
 		}
 
-		r.Header.Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+		r.Header.Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, PATCH, DELETE")
 		r.Header.Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
 		r.Header.Set("Access-Control-Max-Age", "86400")
 
@@ -108,18 +298,22 @@ func (a *App) corsHandler(webHandler HandlerFunc) HandlerFunc {
 // middleware or OTEL tracing.
 func (a *App) HandlerFuncNoMid(method string, group string, path string, handlerFunc HandlerFunc) {
 	h := func(w http.ResponseWriter, r *http.Request) {
+		rw := newResponseWriter(w)
+		defer rw.done()
+
 		ctx := setTraceID(r.Context(), uuid.NewString())
+		ctx = setWriter(ctx, rw)
 
 		resp, err := handlerFunc(ctx, r)
 		if err != nil {
-			if err := respondError(ctx, w, err); err != nil {
-				a.log(ctx, "web-responderror", "ERROR", err)
+			if err := respondError(ctx, rw, err); err != nil {
+				a.logRespondError(ctx, "web-responderror", err)
 			}
 			return
 		}
 
-		if err := respond(ctx, w, resp); err != nil {
-			a.log(ctx, "web-respond", "ERROR", err)
+		if err := respond(ctx, rw, resp); err != nil {
+			a.logRespondError(ctx, "web-respond", err)
 		}
 	}
 
@@ -127,9 +321,12 @@ func (a *App) HandlerFuncNoMid(method string, group string, path string, handler
 	if group != "" {
 		finalPath = "/" + group + path
 	}
+	a.addRoute(method, finalPath)
 	finalPath = fmt.Sprintf("%s %s", method, finalPath)
 
 	a.mux.HandleFunc(finalPath, h)
+	a.addMethod(method)
+	a.addVersion(group)
 }
 
 // HandlerFunc sets a handler function for a given HTTP method and path pair
@@ -146,18 +343,22 @@ func (a *App) HandlerFunc(method string, group string, path string, handlerFunc
 		ctx, span := tracer.StartTrace(r.Context(), a.tracer, "pkg.web.handle", r.RequestURI, w)
 		defer span.End()
 
+		rw := newResponseWriter(w)
+		defer rw.done()
+
 		ctx = setTraceID(ctx, span.SpanContext().TraceID().String())
+		ctx = setWriter(ctx, rw)
 
 		resp, err := handlerFunc(ctx, r)
 		if err != nil {
-			if err := respondError(ctx, w, err); err != nil {
-				a.log(ctx, "web-responderror", "ERROR", err)
+			if err := respondError(ctx, rw, err); err != nil {
+				a.logRespondError(ctx, "web-responderror", err)
 			}
 			return
 		}
 
-		if err := respond(ctx, w, resp); err != nil {
-			a.log(ctx, "web-respond", "ERROR", err)
+		if err := respond(ctx, rw, resp); err != nil {
+			a.logRespondError(ctx, "web-respond", err)
 		}
 	}
 
@@ -165,9 +366,12 @@ func (a *App) HandlerFunc(method string, group string, path string, handlerFunc
 	if group != "" {
 		finalPath = "/" + group + path
 	}
+	a.addRoute(method, finalPath)
 	finalPath = fmt.Sprintf("%s %s", method, finalPath)
 
 	a.mux.HandleFunc(finalPath, h)
+	a.addMethod(method)
+	a.addVersion(group)
 }
 
 // RawHandlerFunc sets a raw handler function for a given HTTP method and path
@@ -199,7 +403,23 @@ func (a *App) RawHandlerFunc(method string, group string, path string, rawHandle
 	if group != "" {
 		finalPath = "/" + group + path
 	}
+	a.addRoute(method, finalPath)
 	finalPath = fmt.Sprintf("%s %s", method, finalPath)
 
 	a.mux.HandleFunc(finalPath, h)
+	a.addMethod(method)
+	a.addVersion(group)
+}
+
+// logRespondError logs an error that occurred sending the response. A client
+// that disconnected is expected, so it's only logged for debugging.
+func (a *App) logRespondError(ctx context.Context, msg string, err error) {
+	if errors.Is(err, errClientGone) {
+		if a.debug != nil {
+			a.debug(ctx, msg, "status", "client disconnected", "err", err)
+		}
+		return
+	}
+
+	a.log(ctx, msg, "ERROR", err)
 }
@@ -0,0 +1,218 @@
+package web_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func newHeaderApp(maxHeaders int) *web.App {
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil)
+	app.SetMaxHeaderCount(maxHeaders)
+	app.HandlerFunc(http.MethodGet, "v1", "/headers", func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return nil, nil
+	})
+
+	return app
+}
+
+func Test_MaxHeaderCount(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(newHeaderApp(20))
+	defer srv.Close()
+
+	table := []struct {
+		name    string
+		headers int
+		status  int
+	}{
+		{name: "under", headers: 5, status: http.StatusNoContent},
+		{name: "over", headers: 50, status: http.StatusRequestHeaderFieldsTooLarge},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/v1/headers", nil)
+			if err != nil {
+				t.Fatalf("Should be able to create the request : %s", err)
+			}
+
+			for i := range tt.headers {
+				req.Header.Set(fmt.Sprintf("X-Test-%d", i), "value")
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Should be able to make the request : %s", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("Should get the expected status, got %d, exp %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func Test_MaxHeaderBytes(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewUnstartedServer(newHeaderApp(0))
+	srv.Config.MaxHeaderBytes = 1 << 10
+	srv.Start()
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/v1/headers", nil)
+	if err != nil {
+		t.Fatalf("Should be able to create the request : %s", err)
+	}
+
+	// The server allows 4KB of slack over MaxHeaderBytes.
+	req.Header.Set("X-Large", strings.Repeat("a", 8<<10))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Should be able to make the request : %s", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("Should get a 431, got %d", resp.StatusCode)
+	}
+}
+
+func Test_Options(t *testing.T) {
+	t.Parallel()
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return nil, nil
+	}
+
+	newApp := func(cors bool) *web.App {
+		app := web.NewApp(func(context.Context, string, ...any) {}, nil)
+		if cors {
+			app.EnableCORS([]string{"*"})
+		}
+
+		app.HandlerFunc(http.MethodGet, "v1", "/users/{user_id}", handler)
+		app.HandlerFunc(http.MethodPut, "v1", "/users/{user_id}", handler)
+		app.HandlerFunc(http.MethodDelete, "v1", "/users/{user_id}", handler)
+		app.HandlerFunc(http.MethodPost, "v1", "/users", handler)
+
+		return app
+	}
+
+	table := []struct {
+		name    string
+		cors    bool
+		path    string
+		headers map[string]string
+		status  int
+		allow   string
+	}{
+		{name: "multiple", path: "/v1/users/123", status: http.StatusNoContent, allow: "DELETE, GET, HEAD, OPTIONS, PUT"},
+		{name: "single", path: "/v1/users", status: http.StatusNoContent, allow: "OPTIONS, POST"},
+		{name: "unknown", path: "/v1/products", status: http.StatusNotFound},
+		{name: "cors", cors: true, path: "/v1/users/123", status: http.StatusNoContent, allow: "DELETE, GET, HEAD, OPTIONS, PUT"},
+		{name: "cors-unknown", cors: true, path: "/v1/products", status: http.StatusNotFound},
+		{
+			name:    "cors-preflight",
+			cors:    true,
+			path:    "/v1/users/123",
+			headers: map[string]string{"Origin": "http://example.com", "Access-Control-Request-Method": "PUT"},
+			status:  http.StatusOK,
+		},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+
+			w := httptest.NewRecorder()
+			newApp(tt.cors).ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("Should get the expected status, got %d, exp %d", w.Code, tt.status)
+			}
+
+			if got := w.Header().Get("Allow"); got != tt.allow {
+				t.Errorf("Should get the allowed methods, got %q, exp %q", got, tt.allow)
+			}
+		})
+	}
+}
+
+func Test_MethodNotAllowed(t *testing.T) {
+	t.Parallel()
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return nil, nil
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil)
+	app.EnableCORS([]string{"*"})
+	app.HandlerFunc(http.MethodGet, "v1", "/users/{user_id}", handler)
+	app.HandlerFunc(http.MethodPut, "v1", "/users/{user_id}", handler)
+	app.HandlerFunc(http.MethodPost, "v1", "/users", handler)
+
+	table := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		allow  string
+	}{
+		{name: "allowed", method: http.MethodPut, path: "/v1/users/123", status: http.StatusNoContent},
+		{name: "not-allowed", method: http.MethodPost, path: "/v1/users/123", status: http.StatusMethodNotAllowed, allow: "GET, HEAD, OPTIONS, PUT"},
+		{name: "not-allowed-single", method: http.MethodDelete, path: "/v1/users", status: http.StatusMethodNotAllowed, allow: "OPTIONS, POST"},
+		{name: "unknown", method: http.MethodGet, path: "/v1/products", status: http.StatusNotFound},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Fatalf("Should get the expected status, got %d, exp %d", w.Code, tt.status)
+			}
+
+			if got := w.Header().Get("Allow"); got != tt.allow {
+				t.Errorf("Should get the allowed methods, got %q, exp %q", got, tt.allow)
+			}
+		})
+	}
+}
+
+func Test_Routes(t *testing.T) {
+	t.Parallel()
+
+	handler := func(ctx context.Context, r *http.Request) (web.Encoder, error) {
+		return nil, nil
+	}
+
+	app := web.NewApp(func(context.Context, string, ...any) {}, nil)
+	app.HandlerFunc(http.MethodGet, "v1", "/users/{user_id}", handler)
+	app.HandlerFuncNoMid(http.MethodGet, "", "/liveness", handler)
+	app.Group("v1", "/admin").HandlerFunc(http.MethodPut, "/config", handler)
+
+	exp := []web.Route{
+		{Method: http.MethodGet, Path: "/v1/users/{user_id}"},
+		{Method: http.MethodGet, Path: "/liveness"},
+		{Method: http.MethodPut, Path: "/v1/admin/config"},
+	}
+
+	if got := app.Routes(); !slices.Equal(got, exp) {
+		t.Errorf("Should get the bound routes in order, got %v, exp %v", got, exp)
+	}
+}
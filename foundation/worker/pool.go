@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrPoolClosed is returned when a job is submitted to a pool that is
+// shutting down.
+var ErrPoolClosed = errors.New("pool is shutting down")
+
+// Pool executes submitted jobs on a fixed number of goroutines. Jobs wait in
+// a queue until a goroutine is free. Unlike a Worker, a Pool lets the jobs
+// that are queued and running finish when it's shut down.
+type Pool struct {
+	wg       sync.WaitGroup
+	mu       sync.RWMutex
+	jobs     chan JobFn
+	quit     chan struct{}
+	stopping atomic.Bool
+	closed   bool
+	ctx      context.Context
+	cancel   context.CancelFunc
+	dropped  atomic.Int64
+}
+
+// NewPool constructs a Pool that runs up to maxRunningJobs jobs at the same
+// time and queues up to queueSize more.
+func NewPool(maxRunningJobs int, queueSize int) (*Pool, error) {
+	if maxRunningJobs <= 0 {
+		return nil, errors.New("max running jobs must be greater than 0")
+	}
+
+	if queueSize < 0 {
+		return nil, errors.New("queue size can't be negative")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := Pool{
+		jobs:   make(chan JobFn, queueSize),
+		quit:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	p.wg.Add(maxRunningJobs)
+	for i := 0; i < maxRunningJobs; i++ {
+		go p.work()
+	}
+
+	return &p, nil
+}
+
+// Submit queues the job to be executed. It blocks while the queue is full
+// until there is room, the context is canceled or the pool is shut down.
+// The job is given a context that is canceled if the pool is forced to stop.
+func (p *Pool) Submit(ctx context.Context, jobFn JobFn) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	select {
+	case <-p.quit:
+		return ErrPoolClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	case p.jobs <- jobFn:
+		return nil
+	}
+}
+
+// Shutdown stops accepting jobs and waits for the queued and running jobs to
+// finish. When the context is canceled first, the running jobs are canceled
+// and the queued jobs are dropped. It returns the number of jobs that were
+// dropped. Jobs must return when their context is canceled.
+func (p *Pool) Shutdown(ctx context.Context) (int, error) {
+	if !p.stopping.CompareAndSwap(false, true) {
+		return 0, ErrPoolClosed
+	}
+
+	// Release the callers blocked in Submit so the queue can be closed.
+	close(p.quit)
+
+	p.mu.Lock()
+	p.closed = true
+	close(p.jobs)
+	p.mu.Unlock()
+
+	// Launch a goroutine to wait for the queue to drain.
+	ch := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(ch)
+	}()
+
+	select {
+	case <-ch:
+		p.cancel()
+		return 0, nil
+
+	case <-ctx.Done():
+		p.cancel()
+		<-ch
+		return int(p.dropped.Load()), ctx.Err()
+	}
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+
+	for jobFn := range p.jobs {
+		if p.ctx.Err() != nil {
+			p.dropped.Add(1)
+			continue
+		}
+
+		jobFn(p.ctx)
+	}
+}
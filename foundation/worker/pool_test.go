@@ -0,0 +1,154 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/worker"
+)
+
+func Test_PoolConcurrency(t *testing.T) {
+	t.Parallel()
+
+	const maxRunning = 3
+	const jobs = 12
+
+	p, err := worker.NewPool(maxRunning, jobs)
+	if err != nil {
+		t.Fatalf("Should be able to create a pool : %s", err)
+	}
+
+	var running, peak, done atomic.Int64
+
+	work := func(ctx context.Context) {
+		n := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		done.Add(1)
+	}
+
+	for i := 0; i < jobs; i++ {
+		if err := p.Submit(context.Background(), work); err != nil {
+			t.Fatalf("Should be able to submit a job : %s", err)
+		}
+	}
+
+	dropped, err := p.Shutdown(context.Background())
+	if err != nil {
+		t.Fatalf("Should be able to shutdown cleanly : %s", err)
+	}
+
+	if dropped != 0 {
+		t.Errorf("Should not drop any jobs, got %d", dropped)
+	}
+
+	if n := done.Load(); n != jobs {
+		t.Errorf("Should run every job, got %d, exp %d", n, jobs)
+	}
+
+	if n := peak.Load(); n > maxRunning {
+		t.Errorf("Should never run more than %d jobs at once, got %d", maxRunning, n)
+	}
+}
+
+func Test_PoolDrain(t *testing.T) {
+	t.Parallel()
+
+	p, err := worker.NewPool(2, 10)
+	if err != nil {
+		t.Fatalf("Should be able to create a pool : %s", err)
+	}
+
+	var done atomic.Int64
+
+	work := func(ctx context.Context) {
+		select {
+		case <-time.After(50 * time.Millisecond):
+			done.Add(1)
+		case <-ctx.Done():
+		}
+	}
+
+	for i := 0; i < 6; i++ {
+		if err := p.Submit(context.Background(), work); err != nil {
+			t.Fatalf("Should be able to submit a job : %s", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	dropped, err := p.Shutdown(ctx)
+	if err != nil {
+		t.Fatalf("Should drain the jobs within the deadline : %s", err)
+	}
+
+	if dropped != 0 || done.Load() != 6 {
+		t.Errorf("Should finish the queued and running jobs : dropped[%d] done[%d]", dropped, done.Load())
+	}
+
+	if err := p.Submit(context.Background(), work); !errors.Is(err, worker.ErrPoolClosed) {
+		t.Errorf("Should not accept jobs after shutdown : %v", err)
+	}
+}
+
+func Test_PoolForcedShutdown(t *testing.T) {
+	t.Parallel()
+
+	p, err := worker.NewPool(1, 10)
+	if err != nil {
+		t.Fatalf("Should be able to create a pool : %s", err)
+	}
+
+	var started sync.WaitGroup
+	started.Add(1)
+
+	var canceled atomic.Bool
+
+	// The first job holds the only goroutine until it's canceled.
+	work := func(ctx context.Context) {
+		started.Done()
+		<-ctx.Done()
+		canceled.Store(true)
+	}
+
+	if err := p.Submit(context.Background(), work); err != nil {
+		t.Fatalf("Should be able to submit a job : %s", err)
+	}
+
+	started.Wait()
+
+	for i := 0; i < 4; i++ {
+		if err := p.Submit(context.Background(), func(ctx context.Context) {}); err != nil {
+			t.Fatalf("Should be able to queue a job : %s", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	dropped, err := p.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Should report the deadline was reached : %v", err)
+	}
+
+	if !canceled.Load() {
+		t.Errorf("Should cancel the running job")
+	}
+
+	if dropped != 4 {
+		t.Errorf("Should report the queued jobs as dropped, got %d, exp 4", dropped)
+	}
+}